@@ -1,10 +1,16 @@
 package dash
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"slices"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"github.com/seaweedfs/seaweedfs/weed/credential"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 )
 
 // ShowLogin displays the login page
@@ -29,7 +35,8 @@ func (s *AdminServer) HandleLogin(username, password string) gin.HandlerFunc {
 		loginUsername := c.PostForm("username")
 		loginPassword := c.PostForm("password")
 
-		if loginUsername == username && loginPassword == password {
+		localLogin := password != "" && loginUsername == username && loginPassword == password
+		if localLogin || s.authenticateWithCredentialStore(c.Request.Context(), loginUsername, loginPassword) {
 			session := sessions.Default(c)
 			session.Set("authenticated", true)
 			session.Set("username", loginUsername)
@@ -44,6 +51,26 @@ func (s *AdminServer) HandleLogin(username, password string) gin.HandlerFunc {
 	}
 }
 
+// CredentialStoreLoginEnabled returns whether users of the credential store, such as LDAP, can log in
+func (s *AdminServer) CredentialStoreLoginEnabled() bool {
+	return s.credentialManager != nil && s.credentialManager.SupportsPasswordAuthentication()
+}
+
+// authenticateWithCredentialStore lets users with the Admin action log in with their own password
+func (s *AdminServer) authenticateWithCredentialStore(ctx context.Context, username, password string) bool {
+	if !s.CredentialStoreLoginEnabled() {
+		return false
+	}
+	identity, err := s.credentialManager.Authenticate(ctx, username, password)
+	if err != nil {
+		if !errors.Is(err, credential.ErrInvalidPassword) {
+			glog.Warningf("failed to authenticate %s with the credential store: %v", username, err)
+		}
+		return false
+	}
+	return slices.Contains(identity.Actions, s3_constants.ACTION_ADMIN)
+}
+
 // HandleLogout handles user logout
 func (s *AdminServer) HandleLogout(c *gin.Context) {
 	session := sessions.Default(c)
@@ -0,0 +1,73 @@
+package dash
+
+import (
+	"context"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// TrashEntry represents a deleted file or directory kept in the trash
+type TrashEntry struct {
+	Id          string    `json:"id"`
+	Path        string    `json:"path"`
+	IsDirectory bool      `json:"is_directory"`
+	Size        int64     `json:"size"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// TrashData contains all data needed for the trash view
+type TrashData struct {
+	Username    string       `json:"username"`
+	Entries     []TrashEntry `json:"entries"`
+	TotalItems  int          `json:"total_items"`
+	TotalSize   int64        `json:"total_size"`
+	LastUpdated time.Time    `json:"last_updated"`
+}
+
+// GetTrash lists the trash items, the most recently deleted first
+func (s *AdminServer) GetTrash() (*TrashData, error) {
+	data := &TrashData{LastUpdated: time.Now()}
+	err := s.WithFilerClient(func(client filer_pb.SeaweedFilerClient) error {
+		return filer.ListTrash(context.Background(), client, func(item *filer.TrashItem) error {
+			data.Entries = append(data.Entries, TrashEntry{
+				Id:          item.Id,
+				Path:        string(item.Path),
+				IsDirectory: item.IsDirectory,
+				Size:        int64(item.FileSize),
+				DeletedAt:   item.DeletedAt,
+				ExpiresAt:   item.ExpiresAt,
+			})
+			data.TotalSize += int64(item.FileSize)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(data.Entries)-1; i < j; i, j = i+1, j-1 {
+		data.Entries[i], data.Entries[j] = data.Entries[j], data.Entries[i]
+	}
+	data.TotalItems = len(data.Entries)
+	return data, nil
+}
+
+// RestoreTrashItem moves a trash item back to where it was deleted
+func (s *AdminServer) RestoreTrashItem(id string) (restored string, err error) {
+	err = s.WithFilerClient(func(client filer_pb.SeaweedFilerClient) error {
+		p, restoreErr := filer.RestoreTrash(context.Background(), client, id, util.FullPath(""))
+		restored = string(p)
+		return restoreErr
+	})
+	return
+}
+
+// PurgeTrashItem deletes a trash item and its data
+func (s *AdminServer) PurgeTrashItem(id string) error {
+	return s.WithFilerClient(func(client filer_pb.SeaweedFilerClient) error {
+		return filer.PurgeTrash(context.Background(), client, id)
+	})
+}
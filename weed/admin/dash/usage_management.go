@@ -0,0 +1,45 @@
+package dash
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3usage"
+)
+
+// GetS3Usage returns the usage of the buckets and identities on a day
+func (s *AdminServer) GetS3Usage(date string) (report *s3usage.Report, err error) {
+	err = s.WithFilerClient(func(client filer_pb.SeaweedFilerClient) error {
+		report, err = s3usage.LoadReport(client, date)
+		return err
+	})
+	return report, err
+}
+
+// GetS3UsageAPI returns the usage of a day as JSON or CSV, optionally of one bucket or identity
+func (s *AdminServer) GetS3UsageAPI(c *gin.Context) {
+	date := c.DefaultQuery("date", s3usage.Date(time.Now()))
+	if _, err := time.Parse(s3usage.DateLayout, date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date, expected yyyy-mm-dd"})
+		return
+	}
+
+	report, err := s.GetS3Usage(date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get usage: " + err.Error()})
+		return
+	}
+	report.Filter(c.Query("bucket"), c.Query("identity"))
+
+	if c.Query("format") == s3usage.FormatCSV {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=s3-usage-"+date+".csv")
+		if err := s3usage.WriteCSV(c.Writer, report); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write usage: " + err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
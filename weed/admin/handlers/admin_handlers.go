@@ -70,6 +70,7 @@ func (h *AdminHandlers) SetupRoutes(r *gin.Engine, authRequired bool, username,
 
 		// File browser routes
 		protected.GET("/files", h.fileBrowserHandlers.ShowFileBrowser)
+		protected.GET("/files/trash", h.fileBrowserHandlers.ShowTrash)
 
 		// Cluster management routes
 		protected.GET("/cluster/masters", h.clusterHandlers.ShowClusterMasters)
@@ -114,6 +115,7 @@ func (h *AdminHandlers) SetupRoutes(r *gin.Engine, authRequired bool, username,
 				s3Api.DELETE("/buckets/:bucket", h.adminServer.DeleteBucket)
 				s3Api.GET("/buckets/:bucket", h.adminServer.ShowBucketDetails)
 				s3Api.PUT("/buckets/:bucket/quota", h.adminServer.UpdateBucketQuota)
+				s3Api.GET("/usage", h.adminServer.GetS3UsageAPI)
 			}
 
 			// User management API routes
@@ -151,6 +153,8 @@ func (h *AdminHandlers) SetupRoutes(r *gin.Engine, authRequired bool, username,
 				filesApi.GET("/download", h.fileBrowserHandlers.DownloadFile)
 				filesApi.GET("/view", h.fileBrowserHandlers.ViewFile)
 				filesApi.GET("/properties", h.fileBrowserHandlers.GetFileProperties)
+				filesApi.POST("/trash/restore", h.fileBrowserHandlers.RestoreTrashItem)
+				filesApi.DELETE("/trash/purge", h.fileBrowserHandlers.PurgeTrashItem)
 			}
 
 			// Volume management API routes
@@ -197,6 +201,7 @@ func (h *AdminHandlers) SetupRoutes(r *gin.Engine, authRequired bool, username,
 
 		// File browser routes
 		r.GET("/files", h.fileBrowserHandlers.ShowFileBrowser)
+		r.GET("/files/trash", h.fileBrowserHandlers.ShowTrash)
 
 		// Cluster management routes
 		r.GET("/cluster/masters", h.clusterHandlers.ShowClusterMasters)
@@ -240,6 +245,7 @@ func (h *AdminHandlers) SetupRoutes(r *gin.Engine, authRequired bool, username,
 				s3Api.DELETE("/buckets/:bucket", h.adminServer.DeleteBucket)
 				s3Api.GET("/buckets/:bucket", h.adminServer.ShowBucketDetails)
 				s3Api.PUT("/buckets/:bucket/quota", h.adminServer.UpdateBucketQuota)
+				s3Api.GET("/usage", h.adminServer.GetS3UsageAPI)
 			}
 
 			// User management API routes
@@ -277,6 +283,8 @@ func (h *AdminHandlers) SetupRoutes(r *gin.Engine, authRequired bool, username,
 				filesApi.GET("/download", h.fileBrowserHandlers.DownloadFile)
 				filesApi.GET("/view", h.fileBrowserHandlers.ViewFile)
 				filesApi.GET("/properties", h.fileBrowserHandlers.GetFileProperties)
+				filesApi.POST("/trash/restore", h.fileBrowserHandlers.RestoreTrashItem)
+				filesApi.DELETE("/trash/purge", h.fileBrowserHandlers.PurgeTrashItem)
 			}
 
 			// Volume management API routes
@@ -62,6 +62,69 @@ func (h *FileBrowserHandlers) ShowFileBrowser(c *gin.Context) {
 	}
 }
 
+// ShowTrash renders the trash page
+func (h *FileBrowserHandlers) ShowTrash(c *gin.Context) {
+	trashData, err := h.adminServer.GetTrash()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get trash data: " + err.Error()})
+		return
+	}
+
+	username := c.GetString("username")
+	if username == "" {
+		username = "admin"
+	}
+	trashData.Username = username
+
+	c.Header("Content-Type", "text/html")
+	trashComponent := app.FileTrash(*trashData)
+	layoutComponent := layout.Layout(c, trashComponent)
+	err = layoutComponent.Render(c.Request.Context(), c.Writer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render template: " + err.Error()})
+		return
+	}
+}
+
+// RestoreTrashItem handles trash restore API requests
+func (h *FileBrowserHandlers) RestoreTrashItem(c *gin.Context) {
+	var request struct {
+		Id string `json:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	restored, err := h.adminServer.RestoreTrashItem(request.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Restored to " + restored, "path": restored})
+}
+
+// PurgeTrashItem handles trash purge API requests
+func (h *FileBrowserHandlers) PurgeTrashItem(c *gin.Context) {
+	var request struct {
+		Id string `json:"id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if err := h.adminServer.PurgeTrashItem(request.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trash item purged successfully"})
+}
+
 // DeleteFile handles file deletion API requests
 func (h *FileBrowserHandlers) DeleteFile(c *gin.Context) {
 	var request struct {
@@ -9,6 +9,9 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
 	"time"
 )
 
@@ -17,6 +20,7 @@ type LockClient struct {
 	maxLockDuration time.Duration
 	sleepDuration   time.Duration
 	seedFiler       pb.ServerAddress
+	seedFilerLock   sync.RWMutex // the client is shared by concurrent locks, which follow the moved lock host
 }
 
 func NewLockClient(grpcDialOption grpc.DialOption, seedFiler pb.ServerAddress) *LockClient {
@@ -41,26 +45,74 @@ type LiveLock struct {
 	owner          string
 }
 
-// NewShortLivedLock creates a lock with a 5-second duration
-func (lc *LockClient) NewShortLivedLock(key string, owner string) (lock *LiveLock) {
-	lock = &LiveLock{
+func (lc *LockClient) getSeedFiler() pb.ServerAddress {
+	lc.seedFilerLock.RLock()
+	defer lc.seedFilerLock.RUnlock()
+	return lc.seedFiler
+}
+
+func (lc *LockClient) setSeedFiler(seedFiler pb.ServerAddress) {
+	lc.seedFilerLock.Lock()
+	defer lc.seedFilerLock.Unlock()
+	lc.seedFiler = seedFiler
+}
+
+func (lc *LockClient) newShortLivedLock(key string, owner string) *LiveLock {
+	return &LiveLock{
 		key:            key,
-		hostFiler:      lc.seedFiler,
+		hostFiler:      lc.getSeedFiler(),
 		cancelCh:       make(chan struct{}),
 		expireAtNs:     time.Now().Add(5 * time.Second).UnixNano(),
 		grpcDialOption: lc.grpcDialOption,
 		self:           owner,
 		lc:             lc,
 	}
+}
+
+// NewShortLivedLock creates a lock with a 5-second duration
+func (lc *LockClient) NewShortLivedLock(key string, owner string) (lock *LiveLock) {
+	lock = lc.newShortLivedLock(key, owner)
 	lock.retryUntilLocked(5 * time.Second)
 	return
 }
 
+// NewShortLivedLockWithTimeout creates a lock with a 5-second duration, and gives up if it is not locked within the timeout
+func (lc *LockClient) NewShortLivedLockWithTimeout(key string, owner string, timeout time.Duration) (lock *LiveLock, err error) {
+	lock = lc.newShortLivedLock(key, owner)
+	deadline := time.Now().Add(timeout)
+	for {
+		if err = lock.AttemptToLock(5 * time.Second); err == nil {
+			return lock, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("lock %s within %v: %w", key, timeout, err)
+		}
+	}
+}
+
+// FindLockOwner returns the owner of the key, or an empty owner if the key is not locked
+func (lc *LockClient) FindLockOwner(key string) (owner string, err error) {
+	err = pb.WithFilerClient(false, 0, lc.getSeedFiler(), lc.grpcDialOption, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.FindLockOwner(context.Background(), &filer_pb.FindLockOwnerRequest{
+			Name: key,
+		})
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		owner = resp.Owner
+		return nil
+	})
+	return
+}
+
 // StartLongLivedLock starts a goroutine to lock the key and returns immediately.
 func (lc *LockClient) StartLongLivedLock(key string, owner string, onLockOwnerChange func(newLockOwner string)) (lock *LiveLock) {
 	lock = &LiveLock{
 		key:            key,
-		hostFiler:      lc.seedFiler,
+		hostFiler:      lc.getSeedFiler(),
 		cancelCh:       make(chan struct{}),
 		expireAtNs:     time.Now().Add(lock_manager.LiveLockTTL).UnixNano(),
 		grpcDialOption: lc.grpcDialOption,
@@ -157,7 +209,7 @@ func (lock *LiveLock) doLock(lockDuration time.Duration) (errorMessage string, e
 			errorMessage = resp.Error
 			if resp.LockHostMovedTo != "" {
 				lock.hostFiler = pb.ServerAddress(resp.LockHostMovedTo)
-				lock.lc.seedFiler = lock.hostFiler
+				lock.lc.setSeedFiler(lock.hostFiler)
 			}
 			if resp.LockOwner != "" {
 				lock.owner = resp.LockOwner
@@ -255,7 +255,11 @@ func startAdminServer(ctx context.Context, options AdminOptions) error {
 
 	// Create handlers and setup routes
 	adminHandlers := handlers.NewAdminHandlers(adminServer)
-	adminHandlers.SetupRoutes(r, *options.adminPassword != "", *options.adminUser, *options.adminPassword)
+	authRequired := *options.adminPassword != "" || adminServer.CredentialStoreLoginEnabled()
+	if adminServer.CredentialStoreLoginEnabled() {
+		fmt.Printf("Authentication: users with the Admin action in the credential store can log in\n")
+	}
+	adminHandlers.SetupRoutes(r, authRequired, *options.adminUser, *options.adminPassword)
 
 	// Server configuration
 	addr := fmt.Sprintf(":%d", *options.port)
@@ -73,6 +73,7 @@ type FilerOptions struct {
 	diskType                *string
 	allowedOrigins          *string
 	exposeDirectoryData     *bool
+	enforceBucketQuotas     *bool
 	certProvider            certprovider.Provider
 }
 
@@ -107,6 +108,7 @@ func init() {
 	f.diskType = cmdFiler.Flag.String("disk", "", "[hdd|ssd|<tag>] hard drive or solid state drive or any tag")
 	f.allowedOrigins = cmdFiler.Flag.String("allowedOrigins", "*", "comma separated list of allowed origins")
 	f.exposeDirectoryData = cmdFiler.Flag.Bool("exposeDirectoryData", true, "whether to return directory metadata and content in Filer UI")
+	f.enforceBucketQuotas = cmdFiler.Flag.Bool("quota.enforceBuckets", false, "reject the writes over the size limits set by s3.bucket.quota, instead of only making the buckets read only with s3.bucket.quota.enforce")
 
 	// start s3 on filer
 	filerStartS3 = cmdFiler.Flag.Bool("s3", false, "whether to start S3 gateway")
@@ -114,6 +116,7 @@ func init() {
 	filerS3Options.portHttps = cmdFiler.Flag.Int("s3.port.https", 0, "s3 server https listen port")
 	filerS3Options.portGrpc = cmdFiler.Flag.Int("s3.port.grpc", 0, "s3 server grpc listen port")
 	filerS3Options.domainName = cmdFiler.Flag.String("s3.domainName", "", "suffix of the host name in comma separated list, {bucket}.{domainName}")
+	filerS3Options.websiteDomainName = cmdFiler.Flag.String("s3.website.domainName", "", "suffix of the host name of the static website endpoints in comma separated list, {bucket}.{website.domainName}")
 	filerS3Options.allowedOrigins = cmdFiler.Flag.String("s3.allowedOrigins", "*", "comma separated list of allowed origins")
 	filerS3Options.dataCenter = cmdFiler.Flag.String("s3.dataCenter", "", "prefer to read and write to volumes in this data center")
 	filerS3Options.tlsPrivateKey = cmdFiler.Flag.String("s3.key.file", "", "path to the TLS private key file")
@@ -127,6 +130,12 @@ func init() {
 	filerS3Options.tlsVerifyClientCert = cmdFiler.Flag.Bool("s3.tlsVerifyClientCert", false, "whether to verify the client's certificate")
 	filerS3Options.bindIp = cmdFiler.Flag.String("s3.ip.bind", "", "ip address to bind to. If empty, default to same as -ip.bind option.")
 	filerS3Options.idleTimeout = cmdFiler.Flag.Int("s3.idleTimeout", 10, "connection idle seconds")
+	filerS3Options.lifecycleScanInterval = cmdFiler.Flag.Duration("s3.lifecycle.scanInterval", time.Hour, "how often to apply bucket lifecycle rules, 0 to disable")
+	filerS3Options.inventoryCheckInterval = cmdFiler.Flag.Duration("s3.inventory.checkInterval", time.Hour, "how often to check for due bucket inventory reports, 0 to disable")
+	filerS3Options.accessLogFlushInterval = cmdFiler.Flag.Duration("s3.accessLog.flushInterval", time.Minute, "how often to deliver the batched server access logs into their target buckets, 0 to disable bucket logging")
+	filerS3Options.usageFlushInterval = cmdFiler.Flag.Duration("s3.usage.flushInterval", time.Minute, "how often to save the request counts of the usage accounting, 0 to disable usage accounting")
+	filerS3Options.transformHttpEndpoints = cmdFiler.Flag.String("s3.transform.httpEndpoints", "", "comma separated URL prefixes that the http steps of bucket transformations may post to, http steps are refused if empty")
+	filerS3Options.trustedProxies = cmdFiler.Flag.String("s3.trustedProxies", "", "comma separated addresses or CIDR ranges of the proxies whose X-Forwarded-For header identifies the client for the source ip rate limits")
 
 	// start webdav on filer
 	filerStartWebDav = cmdFiler.Flag.Bool("webdav", false, "whether to start webdav gateway")
@@ -329,6 +338,7 @@ func (fo *FilerOptions) startFiler() {
 		DownloadMaxBytesPs:    int64(*fo.downloadMaxMBps) * 1024 * 1024,
 		DiskType:              *fo.diskType,
 		AllowedOrigins:        strings.Split(*fo.allowedOrigins, ","),
+		EnforceBucketQuotas:   *fo.enforceBucketQuotas,
 	})
 	if nfs_err != nil {
 		glog.Fatalf("Filer startup error: %v", nfs_err)
@@ -26,6 +26,7 @@ import (
 
 type SyncOptions struct {
 	isActivePassive *bool
+	multiSite       *bool
 	filerA          *string
 	filerB          *string
 	aPath           *string
@@ -69,6 +70,7 @@ var (
 func init() {
 	cmdFilerSynchronize.Run = runFilerSynchronize // break init cycle
 	syncOptions.isActivePassive = cmdFilerSynchronize.Flag.Bool("isActivePassive", false, "one directional follow from A to B if true")
+	syncOptions.multiSite = cmdFilerSynchronize.Flag.Bool("multiSite", false, "resolve concurrent changes of s3 buckets on both filers by their site clocks, needs filer.options.site_id on both filers")
 	syncOptions.filerA = cmdFilerSynchronize.Flag.String("a", "", "filer A in one SeaweedFS cluster")
 	syncOptions.filerB = cmdFilerSynchronize.Flag.String("b", "", "filer B in the other SeaweedFS cluster")
 	syncOptions.aPath = cmdFilerSynchronize.Flag.String("a.path", "/", "directory to sync on filer A")
@@ -114,6 +116,12 @@ var cmdFilerSynchronize = &Command{
 	If restarted, the synchronization will resume from the previous checkpoints, persisted every minute.
 	A fresh sync will start from the earliest metadata logs.
 
+	With -multiSite, both sites can write to the same s3 buckets. Changes carry a hybrid logical
+	clock of the site they were made on, set by the filers with "site_id" in filer.toml. Concurrent
+	changes are resolved the same way on both sites: the latest clock wins, the other change is
+	kept as an older version in versioned buckets, or as "<object>.conflict-<site>-<clock>" in other
+	buckets. Deletes do not remove objects changed concurrently. See "s3.conflicts.report" in weed shell.
+
 `,
 }
 
@@ -171,6 +179,7 @@ func runFilerSynchronize(cmd *Command, args []string) bool {
 				*syncOptions.bDebug,
 				*syncOptions.concurrency,
 				*syncOptions.bDoDeleteFiles,
+				*syncOptions.multiSite,
 				aFilerSignature,
 				bFilerSignature)
 			if err != nil {
@@ -209,6 +218,7 @@ func runFilerSynchronize(cmd *Command, args []string) bool {
 					*syncOptions.aDebug,
 					*syncOptions.concurrency,
 					*syncOptions.aDoDeleteFiles,
+					*syncOptions.multiSite,
 					bFilerSignature,
 					aFilerSignature)
 				if err != nil {
@@ -241,7 +251,7 @@ func initOffsetFromTsMs(grpcDialOption grpc.DialOption, targetFiler pb.ServerAdd
 }
 
 func doSubscribeFilerMetaChanges(clientId int32, clientEpoch int32, grpcDialOption grpc.DialOption, sourceFiler pb.ServerAddress, sourcePath string, sourceExcludePaths []string, sourceReadChunkFromFiler bool, targetFiler pb.ServerAddress, targetPath string,
-	replicationStr, collection string, ttlSec int, sinkWriteChunkByFiler bool, diskType string, debug bool, concurrency int, doDeleteFiles bool, sourceFilerSignature int32, targetFilerSignature int32) error {
+	replicationStr, collection string, ttlSec int, sinkWriteChunkByFiler bool, diskType string, debug bool, concurrency int, doDeleteFiles bool, multiSite bool, sourceFilerSignature int32, targetFilerSignature int32) error {
 
 	// if first time, start from now
 	// if has previously synced, resume from that point of time
@@ -258,6 +268,13 @@ func doSubscribeFilerMetaChanges(clientId int32, clientEpoch int32, grpcDialOpti
 	filerSink := &filersink.FilerSink{}
 	filerSink.DoInitialize(targetFiler.ToHttpAddress(), targetFiler.ToGrpcAddress(), targetPath, replicationStr, collection, ttlSec, diskType, grpcDialOption, sinkWriteChunkByFiler)
 	filerSink.SetSourceFiler(filerSource)
+	if multiSite {
+		_, _, _, dirBuckets, _, _, err := readFilerConfiguration(grpcDialOption, targetFiler)
+		if err != nil {
+			return err
+		}
+		filerSink.EnableMultiSite(dirBuckets)
+	}
 
 	persistEventFn := genProcessFunction(sourcePath, targetPath, sourceExcludePaths, nil, filerSink, doDeleteFiles, debug)
 
@@ -427,6 +444,9 @@ func genProcessFunction(sourcePath string, targetPath string, excludePaths []str
 				return nil
 			}
 			key := buildKey(dataSink, message, targetPath, sourceOldKey, sourcePath)
+			if conditionalSink, ok := dataSink.(sink.ConditionalDeleteSink); ok {
+				return conditionalSink.DeleteEntryIfUnchanged(key, message.OldEntry, message.OldEntry.IsDirectory, message.DeleteChunks, message.Signatures)
+			}
 			return dataSink.DeleteEntry(key, message.OldEntry.IsDirectory, message.DeleteChunks, message.Signatures)
 		}
 
@@ -41,6 +41,7 @@ type S3Options struct {
 	portGrpc                  *int
 	config                    *string
 	domainName                *string
+	websiteDomainName         *string
 	allowedOrigins            *string
 	tlsPrivateKey             *string
 	tlsCertificate            *string
@@ -56,6 +57,12 @@ type S3Options struct {
 	localSocket               *string
 	certProvider              certprovider.Provider
 	idleTimeout               *int
+	lifecycleScanInterval     *time.Duration
+	inventoryCheckInterval    *time.Duration
+	accessLogFlushInterval    *time.Duration
+	usageFlushInterval        *time.Duration
+	transformHttpEndpoints    *string
+	trustedProxies            *string
 }
 
 func init() {
@@ -66,6 +73,7 @@ func init() {
 	s3StandaloneOptions.portHttps = cmdS3.Flag.Int("port.https", 0, "s3 server https listen port")
 	s3StandaloneOptions.portGrpc = cmdS3.Flag.Int("port.grpc", 0, "s3 server grpc listen port")
 	s3StandaloneOptions.domainName = cmdS3.Flag.String("domainName", "", "suffix of the host name in comma separated list, {bucket}.{domainName}")
+	s3StandaloneOptions.websiteDomainName = cmdS3.Flag.String("website.domainName", "", "suffix of the host name of the static website endpoints in comma separated list, {bucket}.{website.domainName}")
 	s3StandaloneOptions.allowedOrigins = cmdS3.Flag.String("allowedOrigins", "*", "comma separated list of allowed origins")
 	s3StandaloneOptions.dataCenter = cmdS3.Flag.String("dataCenter", "", "prefer to read and write to volumes in this data center")
 	s3StandaloneOptions.config = cmdS3.Flag.String("config", "", "path to the config file")
@@ -81,6 +89,12 @@ func init() {
 	s3StandaloneOptions.localFilerSocket = cmdS3.Flag.String("localFilerSocket", "", "local filer socket path")
 	s3StandaloneOptions.localSocket = cmdS3.Flag.String("localSocket", "", "default to /tmp/seaweedfs-s3-<port>.sock")
 	s3StandaloneOptions.idleTimeout = cmdS3.Flag.Int("idleTimeout", 10, "connection idle seconds")
+	s3StandaloneOptions.lifecycleScanInterval = cmdS3.Flag.Duration("lifecycle.scanInterval", time.Hour, "how often to apply bucket lifecycle rules, 0 to disable")
+	s3StandaloneOptions.inventoryCheckInterval = cmdS3.Flag.Duration("inventory.checkInterval", time.Hour, "how often to check for due bucket inventory reports, 0 to disable")
+	s3StandaloneOptions.accessLogFlushInterval = cmdS3.Flag.Duration("accessLog.flushInterval", time.Minute, "how often to deliver the batched server access logs into their target buckets, 0 to disable bucket logging")
+	s3StandaloneOptions.usageFlushInterval = cmdS3.Flag.Duration("usage.flushInterval", time.Minute, "how often to save the request counts of the usage accounting, 0 to disable usage accounting")
+	s3StandaloneOptions.transformHttpEndpoints = cmdS3.Flag.String("transform.httpEndpoints", "", "comma separated URL prefixes that the http steps of bucket transformations may post to, http steps are refused if empty")
+	s3StandaloneOptions.trustedProxies = cmdS3.Flag.String("trustedProxies", "", "comma separated addresses or CIDR ranges of the proxies whose X-Forwarded-For header identifies the client for the source ip rate limits")
 }
 
 var cmdS3 = &Command{
@@ -242,6 +256,7 @@ func (s3opt *S3Options) startS3Server() bool {
 		Port:                      *s3opt.port,
 		Config:                    *s3opt.config,
 		DomainName:                *s3opt.domainName,
+		WebsiteDomainName:         *s3opt.websiteDomainName,
 		AllowedOrigins:            strings.Split(*s3opt.allowedOrigins, ","),
 		BucketsPath:               filerBucketsPath,
 		GrpcDialOption:            grpcDialOption,
@@ -250,6 +265,12 @@ func (s3opt *S3Options) startS3Server() bool {
 		LocalFilerSocket:          localFilerSocket,
 		DataCenter:                *s3opt.dataCenter,
 		FilerGroup:                filerGroup,
+		LifecycleScanInterval:     *s3opt.lifecycleScanInterval,
+		InventoryCheckInterval:    *s3opt.inventoryCheckInterval,
+		AccessLogFlushInterval:    *s3opt.accessLogFlushInterval,
+		UsageFlushInterval:        *s3opt.usageFlushInterval,
+		TransformHttpEndpoints:    util.StringSplit(*s3opt.transformHttpEndpoints, ","),
+		TrustedProxies:            util.StringSplit(*s3opt.trustedProxies, ","),
 	})
 	if s3ApiServer_err != nil {
 		glog.Fatalf("S3 API Server startup error: %v", s3ApiServer_err)
@@ -127,6 +127,7 @@ func init() {
 	filerOptions.downloadMaxMBps = cmdServer.Flag.Int("filer.downloadMaxMBps", 0, "download max speed for each download request, in MB per second")
 	filerOptions.diskType = cmdServer.Flag.String("filer.disk", "", "[hdd|ssd|<tag>] hard drive or solid state drive or any tag")
 	filerOptions.exposeDirectoryData = cmdServer.Flag.Bool("filer.exposeDirectoryData", true, "expose directory data via filer. If false, filer UI will be innaccessible.")
+	filerOptions.enforceBucketQuotas = cmdServer.Flag.Bool("filer.quota.enforceBuckets", false, "reject the writes over the size limits set by s3.bucket.quota, instead of only making the buckets read only with s3.bucket.quota.enforce")
 
 	serverOptions.v.port = cmdServer.Flag.Int("volume.port", 8080, "volume server http listen port")
 	serverOptions.v.portGrpc = cmdServer.Flag.Int("volume.port.grpc", 0, "volume server grpc listen port")
@@ -154,6 +155,7 @@ func init() {
 	s3Options.portHttps = cmdServer.Flag.Int("s3.port.https", 0, "s3 server https listen port")
 	s3Options.portGrpc = cmdServer.Flag.Int("s3.port.grpc", 0, "s3 server grpc listen port")
 	s3Options.domainName = cmdServer.Flag.String("s3.domainName", "", "suffix of the host name in comma separated list, {bucket}.{domainName}")
+	s3Options.websiteDomainName = cmdServer.Flag.String("s3.website.domainName", "", "suffix of the host name of the static website endpoints in comma separated list, {bucket}.{website.domainName}")
 	s3Options.allowedOrigins = cmdServer.Flag.String("s3.allowedOrigins", "*", "comma separated list of allowed origins")
 	s3Options.tlsPrivateKey = cmdServer.Flag.String("s3.key.file", "", "path to the TLS private key file")
 	s3Options.tlsCertificate = cmdServer.Flag.String("s3.cert.file", "", "path to the TLS certificate file")
@@ -166,6 +168,12 @@ func init() {
 	s3Options.localSocket = cmdServer.Flag.String("s3.localSocket", "", "default to /tmp/seaweedfs-s3-<port>.sock")
 	s3Options.bindIp = cmdServer.Flag.String("s3.ip.bind", "", "ip address to bind to. If empty, default to same as -ip.bind option.")
 	s3Options.idleTimeout = cmdServer.Flag.Int("s3.idleTimeout", 10, "connection idle seconds")
+	s3Options.lifecycleScanInterval = cmdServer.Flag.Duration("s3.lifecycle.scanInterval", time.Hour, "how often to apply bucket lifecycle rules, 0 to disable")
+	s3Options.inventoryCheckInterval = cmdServer.Flag.Duration("s3.inventory.checkInterval", time.Hour, "how often to check for due bucket inventory reports, 0 to disable")
+	s3Options.accessLogFlushInterval = cmdServer.Flag.Duration("s3.accessLog.flushInterval", time.Minute, "how often to deliver the batched server access logs into their target buckets, 0 to disable bucket logging")
+	s3Options.usageFlushInterval = cmdServer.Flag.Duration("s3.usage.flushInterval", time.Minute, "how often to save the request counts of the usage accounting, 0 to disable usage accounting")
+	s3Options.transformHttpEndpoints = cmdServer.Flag.String("s3.transform.httpEndpoints", "", "comma separated URL prefixes that the http steps of bucket transformations may post to, http steps are refused if empty")
+	s3Options.trustedProxies = cmdServer.Flag.String("s3.trustedProxies", "", "comma separated addresses or CIDR ranges of the proxies whose X-Forwarded-For header identifies the client for the source ip rate limits")
 
 	sftpOptions.port = cmdServer.Flag.Int("sftp.port", 2022, "SFTP server listen port")
 	sftpOptions.sshPrivateKey = cmdServer.Flag.String("sftp.sshPrivateKey", "", "path to the SSH private key file for host authentication")
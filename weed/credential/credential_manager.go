@@ -96,6 +96,21 @@ func (cm *CredentialManager) DeleteAccessKey(ctx context.Context, username strin
 	return cm.store.DeleteAccessKey(ctx, username, accessKey)
 }
 
+// Authenticate verifies the password of a user, if the store supports passwords
+func (cm *CredentialManager) Authenticate(ctx context.Context, username string, password string) (*iam_pb.Identity, error) {
+	authenticator, ok := cm.store.(PasswordAuthenticator)
+	if !ok {
+		return nil, fmt.Errorf("credential store '%s' does not support password authentication", cm.store.GetName())
+	}
+	return authenticator.Authenticate(ctx, username, password)
+}
+
+// SupportsPasswordAuthentication returns whether the store can verify user passwords
+func (cm *CredentialManager) SupportsPasswordAuthentication() bool {
+	_, ok := cm.store.(PasswordAuthenticator)
+	return ok
+}
+
 // Shutdown performs cleanup
 func (cm *CredentialManager) Shutdown() {
 	if cm.store != nil {
@@ -14,6 +14,7 @@ var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrAccessKeyNotFound = errors.New("access key not found")
+	ErrInvalidPassword   = errors.New("invalid username or password")
 )
 
 // CredentialStoreTypeName represents the type name of a credential store
@@ -24,6 +25,7 @@ const (
 	StoreTypeMemory   CredentialStoreTypeName = "memory"
 	StoreTypeFilerEtc CredentialStoreTypeName = "filer_etc"
 	StoreTypePostgres CredentialStoreTypeName = "postgres"
+	StoreTypeLdap     CredentialStoreTypeName = "ldap"
 )
 
 // CredentialStore defines the interface for user credential storage and retrieval
@@ -68,6 +70,20 @@ type CredentialStore interface {
 	Shutdown()
 }
 
+// PasswordAuthenticator is implemented by stores that can verify user passwords,
+// so the SFTP server and the admin UI can log in the same users as the S3 API
+type PasswordAuthenticator interface {
+	// Authenticate returns the identity of the user, or ErrInvalidPassword
+	Authenticate(ctx context.Context, username string, password string) (*iam_pb.Identity, error)
+}
+
+// ReloadingStore is implemented by stores whose users change outside of SeaweedFS,
+// such as a directory server, so servers reload the configuration periodically
+type ReloadingStore interface {
+	// ReloadInterval returns how often the configuration should be reloaded, 0 disables reloading
+	ReloadInterval() time.Duration
+}
+
 // AccessKeyInfo represents access key information with metadata
 type AccessKeyInfo struct {
 	AccessKey string    `json:"accessKey"`
@@ -0,0 +1,422 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/seaweedfs/seaweedfs/weed/credential"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+)
+
+var errManagedInDirectory = errors.New("users are managed in the LDAP directory")
+
+func (store *LdapStore) LoadConfiguration(ctx context.Context) (*iam_pb.S3ApiConfiguration, error) {
+	conn, err := store.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	users, err := store.searchUsers(conn, "")
+	if err != nil {
+		return nil, err
+	}
+	groups, err := store.searchGroups(conn, "")
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[string]string, len(users)) // normalized dn -> username
+	for _, user := range users {
+		usernames[normalizeDN(user.DN)] = user.GetAttributeValue(store.usernameAttribute)
+	}
+
+	config := &iam_pb.S3ApiConfiguration{}
+	memberships := make(map[string][]string) // username -> group names
+	for _, group := range groups {
+		iamGroup := &iam_pb.Group{Name: group.GetAttributeValue(store.groupNameAttribute)}
+		for _, member := range group.GetAttributeValues(store.memberAttribute) {
+			if username, found := usernames[normalizeDN(member)]; found {
+				iamGroup.Members = append(iamGroup.Members, username)
+				memberships[username] = append(memberships[username], iamGroup.Name)
+			}
+		}
+		config.Groups = append(config.Groups, iamGroup)
+	}
+
+	for _, user := range users {
+		identity := store.newIdentity(user, memberships[user.GetAttributeValue(store.usernameAttribute)])
+		config.Identities = append(config.Identities, identity)
+	}
+	sort.Slice(config.Identities, func(i, j int) bool {
+		return config.Identities[i].Name < config.Identities[j].Name
+	})
+
+	return config, nil
+}
+
+// SaveConfiguration only writes the S3 credentials of the users, everything else comes from the directory
+func (store *LdapStore) SaveConfiguration(ctx context.Context, config *iam_pb.S3ApiConfiguration) error {
+	if len(config.Roles) > 0 || len(config.Policies) > 0 {
+		return fmt.Errorf("roles and managed policies are not supported by the ldap credential store")
+	}
+
+	current, err := store.LoadConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+	for _, group := range config.Groups {
+		if len(group.PolicyNames) > 0 {
+			return fmt.Errorf("cannot attach policies to directory group %s, map it with group_actions", group.Name)
+		}
+	}
+	if len(config.Identities) != len(current.Identities) {
+		return errManagedInDirectory
+	}
+
+	conn, err := store.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, identity := range config.Identities {
+		i := slices.IndexFunc(current.Identities, func(c *iam_pb.Identity) bool { return c.Name == identity.Name })
+		if i < 0 {
+			return fmt.Errorf("%w: %s is not in the directory", errManagedInDirectory, identity.Name)
+		}
+		if err := store.updateIdentity(conn, current.Identities[i], identity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (store *LdapStore) CreateUser(ctx context.Context, identity *iam_pb.Identity) error {
+	return errManagedInDirectory
+}
+
+func (store *LdapStore) GetUser(ctx context.Context, username string) (*iam_pb.Identity, error) {
+	conn, err := store.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	user, err := store.findUser(conn, username)
+	if err != nil {
+		return nil, err
+	}
+	return store.identityWithGroups(conn, user)
+}
+
+// UpdateUser can only change the S3 credentials of a user
+func (store *LdapStore) UpdateUser(ctx context.Context, username string, identity *iam_pb.Identity) error {
+	if identity.Name != username {
+		return errManagedInDirectory
+	}
+
+	conn, err := store.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	user, err := store.findUser(conn, username)
+	if err != nil {
+		return err
+	}
+	current, err := store.identityWithGroups(conn, user)
+	if err != nil {
+		return err
+	}
+	return store.updateIdentity(conn, current, identity)
+}
+
+func (store *LdapStore) DeleteUser(ctx context.Context, username string) error {
+	return errManagedInDirectory
+}
+
+func (store *LdapStore) ListUsers(ctx context.Context) ([]string, error) {
+	conn, err := store.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	users, err := store.searchUsers(conn, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var usernames []string
+	for _, user := range users {
+		usernames = append(usernames, user.GetAttributeValue(store.usernameAttribute))
+	}
+	sort.Strings(usernames)
+	return usernames, nil
+}
+
+func (store *LdapStore) GetUserByAccessKey(ctx context.Context, accessKey string) (*iam_pb.Identity, error) {
+	if store.accessKeyAttribute == "" || accessKey == "" {
+		return nil, credential.ErrAccessKeyNotFound
+	}
+
+	conn, err := store.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// the secret key follows the access key in the attribute value
+	users, err := store.searchUsers(conn, fmt.Sprintf("(%s=%s*)", store.accessKeyAttribute, ldap.EscapeFilter(accessKey+":")))
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		identity, err := store.identityWithGroups(conn, user)
+		if err != nil {
+			return nil, err
+		}
+		for _, cred := range identity.Credentials {
+			if cred.AccessKey == accessKey {
+				return identity, nil
+			}
+		}
+	}
+	return nil, credential.ErrAccessKeyNotFound
+}
+
+func (store *LdapStore) CreateAccessKey(ctx context.Context, username string, cred *iam_pb.Credential) error {
+	if store.accessKeyAttribute == "" {
+		return fmt.Errorf("access_key_attribute is not configured for the ldap credential store")
+	}
+
+	conn, err := store.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	user, err := store.findUser(conn, username)
+	if err != nil {
+		return err
+	}
+	modify := ldap.NewModifyRequest(user.DN, nil)
+	modify.Add(store.accessKeyAttribute, []string{formatCredential(cred)})
+	if err := conn.Modify(modify); err != nil {
+		return fmt.Errorf("failed to add access key to %s: %w", user.DN, err)
+	}
+	return nil
+}
+
+func (store *LdapStore) DeleteAccessKey(ctx context.Context, username string, accessKey string) error {
+	if store.accessKeyAttribute == "" {
+		return credential.ErrAccessKeyNotFound
+	}
+
+	conn, err := store.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	user, err := store.findUser(conn, username)
+	if err != nil {
+		return err
+	}
+	for _, value := range user.GetAttributeValues(store.accessKeyAttribute) {
+		if cred := parseCredential(value); cred != nil && cred.AccessKey == accessKey {
+			modify := ldap.NewModifyRequest(user.DN, nil)
+			modify.Delete(store.accessKeyAttribute, []string{value})
+			if err := conn.Modify(modify); err != nil {
+				return fmt.Errorf("failed to delete access key from %s: %w", user.DN, err)
+			}
+			return nil
+		}
+	}
+	return credential.ErrAccessKeyNotFound
+}
+
+// Authenticate binds to the directory as the user to verify the password
+func (store *LdapStore) Authenticate(ctx context.Context, username string, password string) (*iam_pb.Identity, error) {
+	// an empty password would be an unauthenticated bind, which always succeeds
+	if username == "" || password == "" {
+		return nil, credential.ErrInvalidPassword
+	}
+
+	conn, err := store.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	user, err := store.findUser(conn, username)
+	if errors.Is(err, credential.ErrUserNotFound) {
+		return nil, credential.ErrInvalidPassword
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	userConn, err := store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer userConn.Close()
+	if err := userConn.Bind(user.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, credential.ErrInvalidPassword
+		}
+		return nil, fmt.Errorf("failed to bind as %s: %w", user.DN, err)
+	}
+
+	return store.identityWithGroups(conn, user)
+}
+
+func (store *LdapStore) searchUsers(conn *ldap.Conn, filter string) ([]*ldap.Entry, error) {
+	attributes := []string{store.usernameAttribute}
+	if store.accessKeyAttribute != "" {
+		attributes = append(attributes, store.accessKeyAttribute)
+	}
+	request := ldap.NewSearchRequest(store.userBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		"(&"+store.userFilter+filter+")", attributes, nil)
+	result, err := conn.SearchWithPaging(request, searchPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users in %s: %w", store.userBaseDN, err)
+	}
+	return result.Entries, nil
+}
+
+func (store *LdapStore) searchGroups(conn *ldap.Conn, filter string) ([]*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(store.groupBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		"(&"+store.groupFilter+filter+")", []string{store.groupNameAttribute, store.memberAttribute}, nil)
+	result, err := conn.SearchWithPaging(request, searchPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search groups in %s: %w", store.groupBaseDN, err)
+	}
+	return result.Entries, nil
+}
+
+func (store *LdapStore) findUser(conn *ldap.Conn, username string) (*ldap.Entry, error) {
+	users, err := store.searchUsers(conn, fmt.Sprintf("(%s=%s)", store.usernameAttribute, ldap.EscapeFilter(username)))
+	if err != nil {
+		return nil, err
+	}
+	switch len(users) {
+	case 0:
+		return nil, credential.ErrUserNotFound
+	case 1:
+		return users[0], nil
+	}
+	return nil, fmt.Errorf("%d directory entries match user %s", len(users), username)
+}
+
+func (store *LdapStore) identityWithGroups(conn *ldap.Conn, user *ldap.Entry) (*iam_pb.Identity, error) {
+	groups, err := store.searchGroups(conn, fmt.Sprintf("(%s=%s)", store.memberAttribute, ldap.EscapeFilter(user.DN)))
+	if err != nil {
+		return nil, err
+	}
+	var groupNames []string
+	for _, group := range groups {
+		groupNames = append(groupNames, group.GetAttributeValue(store.groupNameAttribute))
+	}
+	return store.newIdentity(user, groupNames), nil
+}
+
+// newIdentity grants the default actions plus the actions mapped to the groups of the user
+func (store *LdapStore) newIdentity(user *ldap.Entry, groups []string) *iam_pb.Identity {
+	identity := &iam_pb.Identity{
+		Name: user.GetAttributeValue(store.usernameAttribute),
+	}
+	if store.accessKeyAttribute != "" {
+		for _, value := range user.GetAttributeValues(store.accessKeyAttribute) {
+			if cred := parseCredential(value); cred != nil {
+				identity.Credentials = append(identity.Credentials, cred)
+			} else {
+				glog.Warningf("ignore malformed %s of %s", store.accessKeyAttribute, user.DN)
+			}
+		}
+	}
+	for _, action := range store.defaultActions {
+		if !slices.Contains(identity.Actions, action) {
+			identity.Actions = append(identity.Actions, action)
+		}
+	}
+	for _, group := range groups {
+		for _, action := range store.groupActions[group] {
+			if !slices.Contains(identity.Actions, action) {
+				identity.Actions = append(identity.Actions, action)
+			}
+		}
+	}
+	// the groups are in the order of the directory search results
+	slices.Sort(identity.Actions)
+	return identity
+}
+
+// updateIdentity writes changed S3 credentials, other attributes can only be changed in the directory
+func (store *LdapStore) updateIdentity(conn *ldap.Conn, current, updated *iam_pb.Identity) error {
+	if !sameActions(current.Actions, updated.Actions) {
+		return fmt.Errorf("%w: the actions of %s come from its directory groups", errManagedInDirectory, updated.Name)
+	}
+
+	var currentValues, updatedValues []string
+	for _, cred := range current.Credentials {
+		currentValues = append(currentValues, formatCredential(cred))
+	}
+	for _, cred := range updated.Credentials {
+		updatedValues = append(updatedValues, formatCredential(cred))
+	}
+	if slices.Equal(currentValues, updatedValues) {
+		return nil
+	}
+	if store.accessKeyAttribute == "" {
+		return fmt.Errorf("access_key_attribute is not configured for the ldap credential store")
+	}
+
+	user, err := store.findUser(conn, updated.Name)
+	if err != nil {
+		return err
+	}
+	modify := ldap.NewModifyRequest(user.DN, nil)
+	modify.Replace(store.accessKeyAttribute, updatedValues)
+	if err := conn.Modify(modify); err != nil {
+		return fmt.Errorf("failed to update access keys of %s: %w", user.DN, err)
+	}
+	return nil
+}
+
+func formatCredential(cred *iam_pb.Credential) string {
+	return cred.AccessKey + ":" + cred.SecretKey
+}
+
+func parseCredential(value string) *iam_pb.Credential {
+	accessKey, secretKey, found := strings.Cut(value, ":")
+	if !found || accessKey == "" || secretKey == "" {
+		return nil
+	}
+	return &iam_pb.Credential{AccessKey: accessKey, SecretKey: secretKey}
+}
+
+// normalizeDN makes member values comparable with entry DNs
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+	return strings.ToLower(parsed.String())
+}
+
+func sameActions(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
@@ -0,0 +1,256 @@
+package ldap
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// testDirectory is a minimal in-process LDAP server supporting simple bind,
+// search with the common filters, and modify, enough to exercise LdapStore
+type testDirectory struct {
+	mu        sync.Mutex
+	entries   map[string]map[string][]string // dn -> attribute -> values
+	passwords map[string]string              // dn -> password
+	listener  net.Listener
+}
+
+func newTestDirectory(t *testing.T) *testDirectory {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &testDirectory{
+		entries:   make(map[string]map[string][]string),
+		passwords: make(map[string]string),
+		listener:  listener,
+	}
+	go d.serve()
+	t.Cleanup(func() { listener.Close() })
+	return d
+}
+
+func (d *testDirectory) url() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *testDirectory) add(dn string, password string, attributes map[string][]string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries[dn] = attributes
+	if password != "" {
+		d.passwords[dn] = password
+	}
+}
+
+func (d *testDirectory) values(dn, attribute string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.entries[dn][attribute]
+}
+
+func (d *testDirectory) serve() {
+	for {
+		conn, err := d.listener.Accept()
+		if err != nil {
+			return
+		}
+		go d.handle(conn)
+	}
+}
+
+func (d *testDirectory) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+		messageID := request.Children[0].Value.(int64)
+		op := request.Children[1]
+		var responses []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = append(responses, d.bind(messageID, op))
+		case ldap.ApplicationSearchRequest:
+			responses = d.search(messageID, op)
+		case ldap.ApplicationModifyRequest:
+			responses = append(responses, d.modify(messageID, op))
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			responses = append(responses, newResult(messageID, op.Tag+1, ldap.LDAPResultUnwillingToPerform))
+		}
+		for _, response := range responses {
+			if _, err := conn.Write(response.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (d *testDirectory) bind(messageID int64, op *ber.Packet) *ber.Packet {
+	dn := op.Children[1].Data.String()
+	password := op.Children[2].Data.String()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if expected, found := d.passwords[dn]; !found || expected != password {
+		return newResult(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials)
+	}
+	return newResult(messageID, ldap.ApplicationBindResponse, ldap.LDAPResultSuccess)
+}
+
+func (d *testDirectory) search(messageID int64, op *ber.Packet) (responses []*ber.Packet) {
+	baseDN := strings.ToLower(op.Children[0].Data.String())
+	filter := op.Children[6]
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for dn, attributes := range d.entries {
+		if !strings.HasSuffix(strings.ToLower(dn), baseDN) || !matchFilter(filter, attributes) {
+			continue
+		}
+		entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+		entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+		list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+		for name, values := range attributes {
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+			}
+			attribute.AppendChild(set)
+			list.AppendChild(attribute)
+		}
+		entry.AppendChild(list)
+		responses = append(responses, newEnvelope(messageID, entry))
+	}
+	return append(responses, newResult(messageID, ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+}
+
+func (d *testDirectory) modify(messageID int64, op *ber.Packet) *ber.Packet {
+	dn := op.Children[0].Data.String()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	attributes, found := d.entries[dn]
+	if !found {
+		return newResult(messageID, ldap.ApplicationModifyResponse, ldap.LDAPResultNoSuchObject)
+	}
+	for _, change := range op.Children[1].Children {
+		operation := change.Children[0].Value.(int64)
+		name := change.Children[1].Children[0].Data.String()
+		var values []string
+		for _, value := range change.Children[1].Children[1].Children {
+			values = append(values, value.Data.String())
+		}
+		switch operation {
+		case ldap.AddAttribute:
+			attributes[name] = append(attributes[name], values...)
+		case ldap.DeleteAttribute:
+			var kept []string
+			for _, value := range attributes[name] {
+				if !containsFold(values, value) {
+					kept = append(kept, value)
+				}
+			}
+			attributes[name] = kept
+		case ldap.ReplaceAttribute:
+			attributes[name] = values
+		}
+	}
+	return newResult(messageID, ldap.ApplicationModifyResponse, ldap.LDAPResultSuccess)
+}
+
+func matchFilter(filter *ber.Packet, attributes map[string][]string) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matchFilter(child, attributes) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchFilter(child, attributes) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matchFilter(filter.Children[0], attributes)
+	case ldap.FilterEqualityMatch:
+		return containsFold(attributeValues(attributes, filter.Children[0].Data.String()), filter.Children[1].Data.String())
+	case ldap.FilterSubstrings:
+		for _, value := range attributeValues(attributes, filter.Children[0].Data.String()) {
+			if matchSubstrings(strings.ToLower(value), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(attributeValues(attributes, filter.Data.String())) > 0
+	}
+	return false
+}
+
+func matchSubstrings(value string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		substring := strings.ToLower(part.Data.String())
+		switch part.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, substring) {
+				return false
+			}
+			value = value[len(substring):]
+		case ldap.FilterSubstringsAny:
+			i := strings.Index(value, substring)
+			if i < 0 {
+				return false
+			}
+			value = value[i+len(substring):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, substring) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func attributeValues(attributes map[string][]string, name string) []string {
+	for attribute, values := range attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func newEnvelope(messageID int64, op *ber.Packet) *ber.Packet {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, ""))
+	envelope.AppendChild(op)
+	return envelope
+}
+
+func newResult(messageID int64, application ber.Tag, resultCode uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, application, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(resultCode), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return newEnvelope(messageID, op)
+}
@@ -0,0 +1,183 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/seaweedfs/seaweedfs/weed/credential"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	credential.Stores = append(credential.Stores, &LdapStore{})
+}
+
+const (
+	// searchPageSize stays below the default result limit of Active Directory
+	searchPageSize  = 500
+	dialTimeout     = 10 * time.Second
+	requestTimeout  = 30 * time.Second
+	defaultInterval = 60 * time.Second
+)
+
+// LdapStore implements CredentialStore on top of an LDAP or Active Directory server.
+// Users and their group memberships are read from the directory, the directory
+// groups are mapped to S3 actions, and passwords are checked by binding as the user.
+type LdapStore struct {
+	server             string
+	startTLS           bool
+	tlsConfig          *tls.Config
+	bindDN             string
+	bindPassword       string
+	userBaseDN         string
+	userFilter         string
+	usernameAttribute  string
+	groupBaseDN        string
+	groupFilter        string
+	groupNameAttribute string
+	memberAttribute    string
+	// accessKeyAttribute holds the S3 credentials of a user as "accessKey:secretKey" values
+	accessKeyAttribute string
+	defaultActions     []string
+	groupActions       map[string][]string // group_name -> actions
+	reloadInterval     time.Duration
+	configured         bool
+}
+
+func (store *LdapStore) GetName() credential.CredentialStoreTypeName {
+	return credential.StoreTypeLdap
+}
+
+func (store *LdapStore) Initialize(configuration util.Configuration, prefix string) error {
+	if store.configured {
+		return nil
+	}
+
+	store.server = configuration.GetString(prefix + "server")
+	store.startTLS = configuration.GetBool(prefix + "start_tls")
+	store.tlsConfig = &tls.Config{
+		InsecureSkipVerify: configuration.GetBool(prefix + "insecure_skip_verify"),
+	}
+	store.bindDN = configuration.GetString(prefix + "bind_dn")
+	store.bindPassword = configuration.GetString(prefix + "bind_password")
+	store.userBaseDN = configuration.GetString(prefix + "user_base_dn")
+	store.userFilter = configuration.GetString(prefix + "user_filter")
+	store.usernameAttribute = configuration.GetString(prefix + "username_attribute")
+	store.groupBaseDN = configuration.GetString(prefix + "group_base_dn")
+	store.groupFilter = configuration.GetString(prefix + "group_filter")
+	store.groupNameAttribute = configuration.GetString(prefix + "group_name_attribute")
+	store.memberAttribute = configuration.GetString(prefix + "group_member_attribute")
+	store.accessKeyAttribute = configuration.GetString(prefix + "access_key_attribute")
+	store.defaultActions = configuration.GetStringSlice(prefix + "default_actions")
+	store.reloadInterval = time.Duration(configuration.GetInt(prefix+"reload_interval_seconds")) * time.Second
+
+	// Set defaults
+	if store.server == "" {
+		store.server = "ldap://localhost:389"
+	}
+	if store.userFilter == "" {
+		store.userFilter = "(objectClass=person)"
+	}
+	if store.usernameAttribute == "" {
+		store.usernameAttribute = "uid"
+	}
+	if store.groupBaseDN == "" {
+		store.groupBaseDN = store.userBaseDN
+	}
+	if store.groupFilter == "" {
+		store.groupFilter = "(|(objectClass=groupOfNames)(objectClass=group))"
+	}
+	if store.groupNameAttribute == "" {
+		store.groupNameAttribute = "cn"
+	}
+	if store.memberAttribute == "" {
+		store.memberAttribute = "member"
+	}
+	if store.reloadInterval == 0 {
+		store.reloadInterval = defaultInterval
+	} else if store.reloadInterval < 0 {
+		store.reloadInterval = 0
+	}
+	if store.userBaseDN == "" {
+		return fmt.Errorf("user_base_dn is required")
+	}
+
+	groupActions, err := parseGroupActions(configuration.GetStringSlice(prefix + "group_actions"))
+	if err != nil {
+		return err
+	}
+	store.groupActions = groupActions
+
+	// Test connection
+	conn, err := store.connect()
+	if err != nil {
+		return err
+	}
+	conn.Close()
+
+	store.configured = true
+	return nil
+}
+
+// parseGroupActions reads the "group=action,action" mappings of group_actions
+func parseGroupActions(mappings []string) (map[string][]string, error) {
+	groupActions := make(map[string][]string)
+	for _, mapping := range mappings {
+		group, actions, found := strings.Cut(mapping, "=")
+		group = strings.TrimSpace(group)
+		if !found || group == "" {
+			return nil, fmt.Errorf("invalid group_actions entry %q, expected group=action,action", mapping)
+		}
+		for _, action := range strings.Split(actions, ",") {
+			if action = strings.TrimSpace(action); action != "" {
+				groupActions[group] = append(groupActions[group], action)
+			}
+		}
+	}
+	return groupActions, nil
+}
+
+// connect opens a connection bound as the service account
+func (store *LdapStore) connect() (*ldap.Conn, error) {
+	conn, err := store.dial()
+	if err != nil {
+		return nil, err
+	}
+	if store.bindDN != "" {
+		if err := conn.Bind(store.bindDN, store.bindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind as %s: %w", store.bindDN, err)
+		}
+	}
+	return conn, nil
+}
+
+func (store *LdapStore) dial() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(store.server,
+		ldap.DialWithDialer(&net.Dialer{Timeout: dialTimeout}),
+		ldap.DialWithTLSConfig(store.tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", store.server, err)
+	}
+	conn.SetTimeout(requestTimeout)
+	if store.startTLS {
+		if err := conn.StartTLS(store.tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with %s: %w", store.server, err)
+		}
+	}
+	return conn, nil
+}
+
+// ReloadInterval returns how often servers reload the users from the directory
+func (store *LdapStore) ReloadInterval() time.Duration {
+	return store.reloadInterval
+}
+
+func (store *LdapStore) Shutdown() {
+	store.configured = false
+}
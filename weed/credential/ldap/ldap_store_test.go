@@ -0,0 +1,239 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/credential"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+)
+
+const (
+	aliceDN = "uid=alice,ou=people,dc=example,dc=com"
+	bobDN   = "uid=bob,ou=people,dc=example,dc=com"
+)
+
+// testConfiguration implements util.Configuration on a map
+type testConfiguration map[string]interface{}
+
+func (c testConfiguration) GetString(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c testConfiguration) GetBool(key string) bool {
+	value, _ := c[key].(bool)
+	return value
+}
+
+func (c testConfiguration) GetInt(key string) int {
+	value, _ := c[key].(int)
+	return value
+}
+
+func (c testConfiguration) GetStringSlice(key string) []string {
+	value, _ := c[key].([]string)
+	return value
+}
+
+func (c testConfiguration) SetDefault(key string, value interface{}) {
+	if _, found := c[key]; !found {
+		c[key] = value
+	}
+}
+
+func newTestStore(t *testing.T) (*LdapStore, *testDirectory) {
+	directory := newTestDirectory(t)
+	directory.add("cn=seaweedfs,dc=example,dc=com", "service-secret", map[string][]string{
+		"objectClass": {"organizationalRole"},
+	})
+	directory.add(aliceDN, "alice-secret", map[string][]string{
+		"objectClass":         {"inetOrgPerson"},
+		"uid":                 {"alice"},
+		"seaweedfsCredential": {"AKIAALICE:alice-s3-secret"},
+	})
+	directory.add(bobDN, "bob-secret", map[string][]string{
+		"objectClass": {"inetOrgPerson"},
+		"uid":         {"bob"},
+	})
+	directory.add("cn=s3-admins,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {"s3-admins"},
+		"member":      {"UID=alice,ou=people,dc=example,dc=com"},
+	})
+	directory.add("cn=analytics,ou=groups,dc=example,dc=com", "", map[string][]string{
+		"objectClass": {"groupOfNames"},
+		"cn":          {"analytics"},
+		"member":      {aliceDN, bobDN},
+	})
+
+	store := &LdapStore{}
+	err := store.Initialize(testConfiguration{
+		"credential.ldap.server":               directory.url(),
+		"credential.ldap.bind_dn":              "cn=seaweedfs,dc=example,dc=com",
+		"credential.ldap.bind_password":        "service-secret",
+		"credential.ldap.user_base_dn":         "ou=people,dc=example,dc=com",
+		"credential.ldap.user_filter":          "(objectClass=inetOrgPerson)",
+		"credential.ldap.group_base_dn":        "ou=groups,dc=example,dc=com",
+		"credential.ldap.access_key_attribute": "seaweedfsCredential",
+		"credential.ldap.default_actions":      []string{"List"},
+		"credential.ldap.group_actions":        []string{"s3-admins=Admin", "analytics = Read:reports, List:reports"},
+	}, "credential.ldap.")
+	if err != nil {
+		t.Fatalf("Failed to initialize store: %v", err)
+	}
+	t.Cleanup(store.Shutdown)
+	return store, directory
+}
+
+func TestLdapStoreLoadConfiguration(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	config, err := store.LoadConfiguration(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if len(config.Identities) != 2 || config.Identities[0].Name != "alice" || config.Identities[1].Name != "bob" {
+		t.Fatalf("Unexpected identities: %+v", config.Identities)
+	}
+
+	alice := config.Identities[0]
+	if len(alice.Credentials) != 1 || alice.Credentials[0].AccessKey != "AKIAALICE" || alice.Credentials[0].SecretKey != "alice-s3-secret" {
+		t.Errorf("Unexpected credentials of alice: %+v", alice.Credentials)
+	}
+	for _, action := range []string{"List", "Admin", "Read:reports", "List:reports"} {
+		if !slices.Contains(alice.Actions, action) {
+			t.Errorf("Expected alice to have action %s, got %v", action, alice.Actions)
+		}
+	}
+	bob := config.Identities[1]
+	if slices.Contains(bob.Actions, "Admin") || !slices.Contains(bob.Actions, "Read:reports") {
+		t.Errorf("Unexpected actions of bob: %v", bob.Actions)
+	}
+
+	if len(config.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", config.Groups)
+	}
+	for _, group := range config.Groups {
+		if group.Name == "analytics" && len(group.Members) != 2 {
+			t.Errorf("Unexpected members of analytics: %v", group.Members)
+		}
+	}
+
+	usernames, err := store.ListUsers(ctx)
+	if err != nil || !slices.Equal(usernames, []string{"alice", "bob"}) {
+		t.Errorf("Unexpected users %v: %v", usernames, err)
+	}
+}
+
+func TestLdapStoreAuthenticate(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	identity, err := store.Authenticate(ctx, "alice", "alice-secret")
+	if err != nil {
+		t.Fatalf("Failed to authenticate alice: %v", err)
+	}
+	if identity.Name != "alice" || !slices.Contains(identity.Actions, "Admin") {
+		t.Errorf("Unexpected identity: %+v", identity)
+	}
+
+	for _, tt := range []struct{ username, password string }{
+		{"alice", "wrong"},
+		{"alice", ""},
+		{"mallory", "alice-secret"},
+		{"alice)(uid=*", "alice-secret"},
+	} {
+		if _, err := store.Authenticate(ctx, tt.username, tt.password); !errors.Is(err, credential.ErrInvalidPassword) {
+			t.Errorf("Authenticate(%q, %q): expected invalid password, got %v", tt.username, tt.password, err)
+		}
+	}
+}
+
+func TestLdapStoreAccessKeys(t *testing.T) {
+	store, directory := newTestStore(t)
+	ctx := context.Background()
+
+	identity, err := store.GetUserByAccessKey(ctx, "AKIAALICE")
+	if err != nil || identity.Name != "alice" {
+		t.Fatalf("Failed to get user by access key: %v %+v", err, identity)
+	}
+	if _, err := store.GetUserByAccessKey(ctx, "AKIA"); !errors.Is(err, credential.ErrAccessKeyNotFound) {
+		t.Errorf("Expected a prefix of an access key not to match, got %v", err)
+	}
+
+	if err := store.CreateAccessKey(ctx, "bob", &iam_pb.Credential{AccessKey: "AKIABOB", SecretKey: "bob-s3-secret"}); err != nil {
+		t.Fatalf("Failed to create access key: %v", err)
+	}
+	if identity, err := store.GetUserByAccessKey(ctx, "AKIABOB"); err != nil || identity.Name != "bob" {
+		t.Errorf("Failed to get bob by access key: %v", err)
+	}
+
+	if err := store.DeleteAccessKey(ctx, "bob", "AKIABOB"); err != nil {
+		t.Fatalf("Failed to delete access key: %v", err)
+	}
+	if values := directory.values(bobDN, "seaweedfsCredential"); len(values) != 0 {
+		t.Errorf("Expected the access key to be removed from the directory, got %v", values)
+	}
+	if err := store.DeleteAccessKey(ctx, "bob", "AKIABOB"); !errors.Is(err, credential.ErrAccessKeyNotFound) {
+		t.Errorf("Expected access key not found, got %v", err)
+	}
+
+	if err := store.CreateUser(ctx, &iam_pb.Identity{Name: "carol"}); err == nil {
+		t.Errorf("Expected users to be read-only")
+	}
+	if _, err := store.GetUser(ctx, "carol"); !errors.Is(err, credential.ErrUserNotFound) {
+		t.Errorf("Expected user not found, got %v", err)
+	}
+}
+
+func TestLdapStoreSaveConfiguration(t *testing.T) {
+	store, directory := newTestStore(t)
+	ctx := context.Background()
+
+	config, err := store.LoadConfiguration(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// the IAM API saves the whole configuration after creating an access key
+	bob := config.Identities[1]
+	bob.Credentials = append(bob.Credentials, &iam_pb.Credential{AccessKey: "AKIABOB", SecretKey: "bob-s3-secret"})
+	if err := store.SaveConfiguration(ctx, config); err != nil {
+		t.Fatalf("Failed to save configuration: %v", err)
+	}
+	if values := directory.values(bobDN, "seaweedfsCredential"); !slices.Equal(values, []string{"AKIABOB:bob-s3-secret"}) {
+		t.Errorf("Unexpected credentials in the directory: %v", values)
+	}
+
+	// alice is in two groups, returned by the directory in any order
+	for i := 0; i < 5; i++ {
+		config, err = store.LoadConfiguration(ctx)
+		if err != nil {
+			t.Fatalf("Failed to load configuration: %v", err)
+		}
+		alice := config.Identities[0]
+		alice.Credentials = append(alice.Credentials, &iam_pb.Credential{AccessKey: fmt.Sprintf("AKIAALICE%d", i), SecretKey: "alice-s3-secret"})
+		slices.Reverse(alice.Actions)
+		if err := store.SaveConfiguration(ctx, config); err != nil {
+			t.Fatalf("Failed to save configuration of alice: %v", err)
+		}
+	}
+	if values := directory.values(aliceDN, "seaweedfsCredential"); len(values) != 6 {
+		t.Errorf("Unexpected credentials of alice in the directory: %v", values)
+	}
+	bob = config.Identities[1]
+
+	bob.Actions = append(bob.Actions, "Write")
+	if err := store.SaveConfiguration(ctx, config); !errors.Is(err, errManagedInDirectory) {
+		t.Errorf("Expected actions to be read-only, got %v", err)
+	}
+	config.Identities = config.Identities[:1]
+	if err := store.SaveConfiguration(ctx, config); !errors.Is(err, errManagedInDirectory) {
+		t.Errorf("Expected users to be read-only, got %v", err)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/seaweedfs/seaweedfs/weed/credential"
 	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+	"google.golang.org/protobuf/proto"
 )
 
 func (store *MemoryStore) LoadConfiguration(ctx context.Context) (*iam_pb.S3ApiConfiguration, error) {
@@ -25,6 +27,15 @@ func (store *MemoryStore) LoadConfiguration(ctx context.Context) (*iam_pb.S3ApiC
 		identityCopy := store.deepCopyIdentity(user)
 		config.Identities = append(config.Identities, identityCopy)
 	}
+	for _, name := range sortedKeys(store.groups) {
+		config.Groups = append(config.Groups, proto.Clone(store.groups[name]).(*iam_pb.Group))
+	}
+	for _, name := range sortedKeys(store.roles) {
+		config.Roles = append(config.Roles, proto.Clone(store.roles[name]).(*iam_pb.Role))
+	}
+	for _, name := range sortedKeys(store.managedPolicies) {
+		config.Policies = append(config.Policies, proto.Clone(store.managedPolicies[name]).(*iam_pb.Policy))
+	}
 
 	return config, nil
 }
@@ -53,6 +64,19 @@ func (store *MemoryStore) SaveConfiguration(ctx context.Context, config *iam_pb.
 		}
 	}
 
+	store.groups = make(map[string]*iam_pb.Group)
+	for _, group := range config.Groups {
+		store.groups[group.Name] = proto.Clone(group).(*iam_pb.Group)
+	}
+	store.roles = make(map[string]*iam_pb.Role)
+	for _, role := range config.Roles {
+		store.roles[role.Name] = proto.Clone(role).(*iam_pb.Role)
+	}
+	store.managedPolicies = make(map[string]*iam_pb.Policy)
+	for _, policy := range config.Policies {
+		store.managedPolicies[policy.Name] = proto.Clone(policy).(*iam_pb.Policy)
+	}
+
 	return nil
 }
 
@@ -300,3 +324,12 @@ func (store *MemoryStore) deepCopyIdentity(identity *iam_pb.Identity) *iam_pb.Id
 
 	return &copy
 }
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
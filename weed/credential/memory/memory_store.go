@@ -21,6 +21,10 @@ type MemoryStore struct {
 	accessKeys  map[string]string                       // access_key -> username
 	policies    map[string]policy_engine.PolicyDocument // policy_name -> policy_document
 	initialized bool
+	// groups, roles and managed policies of the IAM API, saved with the configuration
+	groups          map[string]*iam_pb.Group  // group_name -> group
+	roles           map[string]*iam_pb.Role   // role_name -> role
+	managedPolicies map[string]*iam_pb.Policy // policy_name -> managed policy
 }
 
 func (store *MemoryStore) GetName() credential.CredentialStoreTypeName {
@@ -38,6 +42,9 @@ func (store *MemoryStore) Initialize(configuration util.Configuration, prefix st
 	store.users = make(map[string]*iam_pb.Identity)
 	store.accessKeys = make(map[string]string)
 	store.policies = make(map[string]policy_engine.PolicyDocument)
+	store.groups = make(map[string]*iam_pb.Group)
+	store.roles = make(map[string]*iam_pb.Role)
+	store.managedPolicies = make(map[string]*iam_pb.Policy)
 	store.initialized = true
 
 	return nil
@@ -50,6 +57,9 @@ func (store *MemoryStore) Shutdown() {
 	store.users = nil
 	store.accessKeys = nil
 	store.policies = nil
+	store.groups = nil
+	store.roles = nil
+	store.managedPolicies = nil
 	store.initialized = false
 }
 
@@ -61,6 +71,9 @@ func (store *MemoryStore) Reset() {
 	if store.initialized {
 		store.users = make(map[string]*iam_pb.Identity)
 		store.accessKeys = make(map[string]string)
+		store.groups = make(map[string]*iam_pb.Group)
+		store.roles = make(map[string]*iam_pb.Role)
+		store.managedPolicies = make(map[string]*iam_pb.Policy)
 	}
 }
 
@@ -313,3 +313,57 @@ func TestMemoryStoreConfigurationSaveLoad(t *testing.T) {
 		t.Errorf("User2 credentials not correct: %+v", user2.Credentials)
 	}
 }
+
+func TestMemoryStoreGroupsRolesAndPolicies(t *testing.T) {
+	store := &MemoryStore{}
+	config := util.GetViper()
+	if err := store.Initialize(config, "credential."); err != nil {
+		t.Fatalf("Failed to initialize store: %v", err)
+	}
+
+	ctx := context.Background()
+
+	originalConfig := &iam_pb.S3ApiConfiguration{
+		Identities: []*iam_pb.Identity{
+			{Name: "user1", PolicyNames: []string{"readonly"}},
+		},
+		Groups: []*iam_pb.Group{
+			{Name: "developers", Members: []string{"user1"}, PolicyNames: []string{"readonly"}},
+		},
+		Roles: []*iam_pb.Role{
+			{Name: "deployer", AssumeRolePolicyDocument: `{"Statement": []}`, PolicyNames: []string{"readonly"}},
+		},
+		Policies: []*iam_pb.Policy{
+			{
+				Name:             "readonly",
+				DefaultVersionId: "v1",
+				Versions:         []*iam_pb.PolicyVersion{{VersionId: "v1", Document: `{"Statement": []}`}},
+			},
+		},
+	}
+
+	if err := store.SaveConfiguration(ctx, originalConfig); err != nil {
+		t.Fatalf("Failed to save configuration: %v", err)
+	}
+
+	// Changes to the saved configuration must not leak into the store
+	originalConfig.Groups[0].Members = nil
+
+	loadedConfig, err := store.LoadConfiguration(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if len(loadedConfig.Groups) != 1 || len(loadedConfig.Groups[0].Members) != 1 {
+		t.Errorf("Groups not loaded correctly: %+v", loadedConfig.Groups)
+	}
+	if len(loadedConfig.Roles) != 1 || loadedConfig.Roles[0].Name != "deployer" {
+		t.Errorf("Roles not loaded correctly: %+v", loadedConfig.Roles)
+	}
+	if len(loadedConfig.Policies) != 1 || len(loadedConfig.Policies[0].Versions) != 1 {
+		t.Errorf("Policies not loaded correctly: %+v", loadedConfig.Policies)
+	}
+	if len(loadedConfig.Identities) != 1 || len(loadedConfig.Identities[0].PolicyNames) != 1 {
+		t.Errorf("Identity policy attachments not loaded correctly: %+v", loadedConfig.Identities)
+	}
+}
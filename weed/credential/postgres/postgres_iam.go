@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// marshalPolicyNamesAndTags returns the JSON of the managed policies attached to a user and of its tags
+func marshalPolicyNamesAndTags(identity *iam_pb.Identity) (policyNamesJSON, tagsJSON []byte, err error) {
+	if identity.PolicyNames != nil {
+		if policyNamesJSON, err = json.Marshal(identity.PolicyNames); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal policy names: %w", err)
+		}
+	}
+	if identity.Tags != nil {
+		if tagsJSON, err = json.Marshal(identity.Tags); err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal tags: %w", err)
+		}
+	}
+	return policyNamesJSON, tagsJSON, nil
+}
+
+func unmarshalPolicyNamesAndTags(identity *iam_pb.Identity, policyNamesJSON, tagsJSON []byte) error {
+	if len(policyNamesJSON) > 0 {
+		if err := json.Unmarshal(policyNamesJSON, &identity.PolicyNames); err != nil {
+			return fmt.Errorf("failed to unmarshal policy names: %w", err)
+		}
+	}
+	if len(tagsJSON) > 0 {
+		if err := json.Unmarshal(tagsJSON, &identity.Tags); err != nil {
+			return fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadIamEntities reads the groups, roles and managed policies into the configuration
+func (store *PostgresStore) loadIamEntities(ctx context.Context, config *iam_pb.S3ApiConfiguration) error {
+	if err := store.loadIamTable(ctx, "iam_groups", func(data []byte) error {
+		group := &iam_pb.Group{}
+		if err := protojson.Unmarshal(data, group); err != nil {
+			return err
+		}
+		config.Groups = append(config.Groups, group)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := store.loadIamTable(ctx, "iam_roles", func(data []byte) error {
+		role := &iam_pb.Role{}
+		if err := protojson.Unmarshal(data, role); err != nil {
+			return err
+		}
+		config.Roles = append(config.Roles, role)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return store.loadIamTable(ctx, "iam_managed_policies", func(data []byte) error {
+		policy := &iam_pb.Policy{}
+		if err := protojson.Unmarshal(data, policy); err != nil {
+			return err
+		}
+		config.Policies = append(config.Policies, policy)
+		return nil
+	})
+}
+
+func (store *PostgresStore) loadIamTable(ctx context.Context, table string, fn func(data []byte) error) error {
+	rows, err := store.db.QueryContext(ctx, "SELECT name, data FROM "+table+" ORDER BY name")
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		var data []byte
+		if err := rows.Scan(&name, &data); err != nil {
+			return fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+		if err := fn(data); err != nil {
+			return fmt.Errorf("failed to unmarshal %s %s: %w", table, name, err)
+		}
+	}
+	return rows.Err()
+}
+
+// saveIamEntities replaces the groups, roles and managed policies with the ones of the configuration
+func saveIamEntities(ctx context.Context, tx *sql.Tx, config *iam_pb.S3ApiConfiguration) error {
+	groups := make(map[string]proto.Message)
+	for _, group := range config.Groups {
+		groups[group.Name] = group
+	}
+	if err := saveIamTable(ctx, tx, "iam_groups", groups); err != nil {
+		return err
+	}
+	roles := make(map[string]proto.Message)
+	for _, role := range config.Roles {
+		roles[role.Name] = role
+	}
+	if err := saveIamTable(ctx, tx, "iam_roles", roles); err != nil {
+		return err
+	}
+	policies := make(map[string]proto.Message)
+	for _, policy := range config.Policies {
+		policies[policy.Name] = policy
+	}
+	return saveIamTable(ctx, tx, "iam_managed_policies", policies)
+}
+
+func saveIamTable(ctx context.Context, tx *sql.Tx, table string, messages map[string]proto.Message) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", table, err)
+	}
+	for name, message := range messages {
+		data, err := protojson.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s %s: %w", table, name, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+table+" (name, data) VALUES ($1, $2)", name, data); err != nil {
+			return fmt.Errorf("failed to insert %s %s: %w", table, name, err)
+		}
+	}
+	return nil
+}
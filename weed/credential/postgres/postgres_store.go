@@ -123,11 +123,41 @@ func (store *PostgresStore) createTables() error {
 		CREATE INDEX IF NOT EXISTS idx_policies_name ON policies(name);
 	`
 
+	// Add the columns of attached managed policies and tags to existing users tables
+	usersColumns := `
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS policy_names JSONB;
+		ALTER TABLE users ADD COLUMN IF NOT EXISTS tags JSONB;
+	`
+
+	// Create tables of IAM groups, roles and managed policies,
+	// each row holds the protobuf message as JSON
+	iamTables := `
+		CREATE TABLE IF NOT EXISTS iam_groups (
+			name VARCHAR(255) PRIMARY KEY,
+			data JSONB NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS iam_roles (
+			name VARCHAR(255) PRIMARY KEY,
+			data JSONB NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE IF NOT EXISTS iam_managed_policies (
+			name VARCHAR(255) PRIMARY KEY,
+			data JSONB NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`
+
 	// Execute table creation
 	if _, err := store.db.Exec(usersTable); err != nil {
 		return fmt.Errorf("failed to create users table: %w", err)
 	}
 
+	if _, err := store.db.Exec(usersColumns); err != nil {
+		return fmt.Errorf("failed to add users columns: %w", err)
+	}
+
 	if _, err := store.db.Exec(credentialsTable); err != nil {
 		return fmt.Errorf("failed to create credentials table: %w", err)
 	}
@@ -136,6 +166,10 @@ func (store *PostgresStore) createTables() error {
 		return fmt.Errorf("failed to create policies table: %w", err)
 	}
 
+	if _, err := store.db.Exec(iamTables); err != nil {
+		return fmt.Errorf("failed to create iam tables: %w", err)
+	}
+
 	return nil
 }
 
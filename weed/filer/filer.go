@@ -6,6 +6,8 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3bucket"
@@ -54,18 +56,33 @@ type Filer struct {
 	RemoteStorage       *FilerRemoteStorage
 	Dlm                 *lock_manager.DistributedLockManager
 	MaxFilenameLength   uint32
+	SiteId              string // identifies the site in multi-site replication
+	EnforceBucketQuotas bool   // the size limits of buckets are enforced like the directory quotas
+	siteClock           hybridLogicalClock
+	hasSnapshots        atomic.Bool         // chunks referenced by snapshots are not deleted
+	lockClient          *cluster.LockClient // locks the chunk references, nil to lock in process only
+	lockOwner           pb.ServerAddress
+	chunkRefLock        sync.Mutex
+	chunkRefPeers       chunkRefPeers
+	dedupReleaseQueue   *util.UnboundedQueue // deduplicated chunks to delete with their last reference
+	dedupReplacedQueue  *util.UnboundedQueue // references of deduplicated chunks replaced by new references
+	quotas              directoryQuotas
 }
 
 func NewFiler(masters pb.ServerDiscovery, grpcDialOption grpc.DialOption, filerHost pb.ServerAddress, filerGroup string, collection string, replication string, dataCenter string, maxFilenameLength uint32, notifyFn func()) *Filer {
 	f := &Filer{
 		MasterClient:        wdclient.NewMasterClient(grpcDialOption, filerGroup, cluster.FilerType, filerHost, dataCenter, "", masters),
 		fileIdDeletionQueue: util.NewUnboundedQueue(),
+		dedupReleaseQueue:   util.NewUnboundedQueue(),
+		dedupReplacedQueue:  util.NewUnboundedQueue(),
 		GrpcDialOption:      grpcDialOption,
 		FilerConf:           NewFilerConf(),
 		RemoteStorage:       NewFilerRemoteStorage(),
 		UniqueFilerId:       util.RandomInt32(),
 		Dlm:                 lock_manager.NewDistributedLockManager(filerHost),
 		MaxFilenameLength:   maxFilenameLength,
+		lockClient:          cluster.NewLockClient(grpcDialOption, filerHost),
+		lockOwner:           filerHost,
 	}
 	if f.UniqueFilerId < 0 {
 		f.UniqueFilerId = -f.UniqueFilerId
@@ -76,6 +93,7 @@ func NewFiler(masters pb.ServerDiscovery, grpcDialOption grpc.DialOption, filerH
 	f.metaLogReplication = replication
 
 	go f.loopProcessingDeletion()
+	go f.loopPurgingTrash()
 
 	return f
 }
@@ -197,10 +215,16 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry, o_excl bool, isFr
 		return fmt.Errorf("entry name too long")
 	}
 
+	if err := checkSnapshotWrite(ctx, entry.FullPath); err != nil {
+		return err
+	}
+
 	if entry.IsDirectory() {
 		entry.Attr.TtlSec = 0
 	}
 
+	f.StampSiteClock(entry, isFromOtherCluster)
+
 	oldEntry, _ := f.FindEntry(ctx, entry.FullPath)
 
 	/*
@@ -213,15 +237,22 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry, o_excl bool, isFr
 
 	if oldEntry == nil {
 
+		releaseQuota, err := f.reserveQuota(movedEntryOf(ctx), entry)
+		if err != nil {
+			return err
+		}
+
 		if !skipCreateParentDir {
 			dirParts := strings.Split(string(entry.FullPath), "/")
 			if err := f.ensureParentDirectoryEntry(ctx, entry, dirParts, len(dirParts)-1, isFromOtherCluster); err != nil {
+				releaseQuota()
 				return err
 			}
 		}
 
 		glog.V(4).InfofCtx(ctx, "InsertEntry %s: new entry: %v", entry.FullPath, entry.Name())
 		if err := f.Store.InsertEntry(ctx, entry); err != nil {
+			releaseQuota()
 			glog.ErrorfCtx(ctx, "insert entry %s: %v", entry.FullPath, err)
 			return fmt.Errorf("insert entry %s: %v", entry.FullPath, err)
 		}
@@ -233,7 +264,7 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry, o_excl bool, isFr
 		glog.V(4).InfofCtx(ctx, "UpdateEntry %s: old entry: %v", entry.FullPath, oldEntry.Name())
 		if err := f.UpdateEntry(ctx, oldEntry, entry); err != nil {
 			glog.ErrorfCtx(ctx, "update entry %s: %v", entry.FullPath, err)
-			return fmt.Errorf("update entry %s: %v", entry.FullPath, err)
+			return fmt.Errorf("update entry %s: %w", entry.FullPath, err)
 		}
 	}
 
@@ -313,6 +344,9 @@ func (f *Filer) ensureParentDirectoryEntry(ctx context.Context, entry *Entry, di
 }
 
 func (f *Filer) UpdateEntry(ctx context.Context, oldEntry, entry *Entry) (err error) {
+	if err := checkSnapshotWrite(ctx, entry.FullPath); err != nil {
+		return err
+	}
 	if oldEntry != nil {
 		entry.Attr.Crtime = oldEntry.Attr.Crtime
 		if oldEntry.IsDirectory() && !entry.IsDirectory() {
@@ -323,8 +357,20 @@ func (f *Filer) UpdateEntry(ctx context.Context, oldEntry, entry *Entry) (err er
 			glog.ErrorfCtx(ctx, "existing %s is a file", oldEntry.FullPath)
 			return fmt.Errorf("existing %s is a file", oldEntry.FullPath)
 		}
+		if err := checkObjectLockUpdate(ctx, oldEntry, entry); err != nil {
+			glog.V(1).InfofCtx(ctx, "update entry: %v", err)
+			return err
+		}
+	}
+	releaseQuota, err := f.reserveQuota(oldEntry, entry)
+	if err != nil {
+		glog.V(1).InfofCtx(ctx, "update entry: %v", err)
+		return err
+	}
+	if err = f.Store.UpdateEntry(ctx, entry); err != nil {
+		releaseQuota()
 	}
-	return f.Store.UpdateEntry(ctx, entry)
+	return err
 }
 
 var (
@@ -345,6 +391,12 @@ func (f *Filer) FindEntry(ctx context.Context, p util.FullPath) (entry *Entry, e
 	if string(p) == "/" {
 		return Root, nil
 	}
+	if realPath, isSnapshot := SnapshotRealPath(p); isSnapshot {
+		if entry, err = f.Store.FindEntry(ctx, realPath); entry != nil {
+			entry.FullPath = p
+		}
+		return
+	}
 	entry, err = f.Store.FindEntry(ctx, p)
 	if entry != nil && entry.TtlSec > 0 {
 		if entry.Crtime.Add(time.Duration(entry.TtlSec) * time.Second).Before(time.Now()) {
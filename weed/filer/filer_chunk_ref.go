@@ -0,0 +1,62 @@
+package filer
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+)
+
+// The references to the chunks shared by snapshots, or by deduplication, are counted in the filer store.
+// All filers need to share the same filer store, and update the counts under a distributed lock.
+
+const chunkRefLockPrefix = "chunk.ref."
+
+var ErrFilerStoreNotShared = errors.New("a peer filer uses a separate filer store")
+
+// chunkRefPeers tracks the peer filers with a separate filer store, which can not see the chunk references
+type chunkRefPeers struct {
+	sync.Mutex
+	separateStorePeers map[pb.ServerAddress]bool
+}
+
+func (f *Filer) onPeerStore(peer pb.ServerAddress, isSeparateStore bool) {
+	f.chunkRefPeers.Lock()
+	defer f.chunkRefPeers.Unlock()
+	if !isSeparateStore {
+		delete(f.chunkRefPeers.separateStorePeers, peer)
+		return
+	}
+	if f.chunkRefPeers.separateStorePeers == nil {
+		f.chunkRefPeers.separateStorePeers = make(map[pb.ServerAddress]bool)
+	}
+	if !f.chunkRefPeers.separateStorePeers[peer] {
+		glog.Warningf("peer filer %s uses a separate filer store, chunks are not shared by snapshots or deduplication", peer)
+	}
+	f.chunkRefPeers.separateStorePeers[peer] = true
+}
+
+// CanShareChunks fails if the chunk references can not be counted consistently across all filers
+func (f *Filer) CanShareChunks() error {
+	f.chunkRefPeers.Lock()
+	defer f.chunkRefPeers.Unlock()
+	if len(f.chunkRefPeers.separateStorePeers) > 0 {
+		return ErrFilerStoreNotShared
+	}
+	return nil
+}
+
+// lockChunkRef locks the references of a chunk against the other filers sharing the filer store
+func (f *Filer) lockChunkRef(fileId string) (unlock func()) {
+	if f.lockClient == nil {
+		f.chunkRefLock.Lock()
+		return f.chunkRefLock.Unlock
+	}
+	lock := f.lockClient.NewShortLivedLock(chunkRefLockPrefix+fileId, string(f.lockOwner))
+	return func() {
+		if err := lock.StopShortLivedLock(); err != nil {
+			glog.V(1).Infof("unlock chunk references of %s: %v", fileId, err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/seaweedfs/seaweedfs/weed/pb"
 	"github.com/seaweedfs/seaweedfs/weed/wdclient"
@@ -28,7 +29,8 @@ const (
 )
 
 type FilerConf struct {
-	rules ptrie.Trie[*filer_pb.FilerConf_PathConf]
+	rules          ptrie.Trie[*filer_pb.FilerConf_PathConf]
+	storageClasses map[string]*filer_pb.FilerConf_StorageClassConf
 }
 
 func ReadFilerConf(filerGrpcAddress pb.ServerAddress, grpcDialOption grpc.DialOption, masterClient *wdclient.MasterClient) (*FilerConf, error) {
@@ -56,7 +58,8 @@ func ReadFilerConf(filerGrpcAddress pb.ServerAddress, grpcDialOption grpc.DialOp
 
 func NewFilerConf() (fc *FilerConf) {
 	fc = &FilerConf{
-		rules: ptrie.New[*filer_pb.FilerConf_PathConf](),
+		rules:          ptrie.New[*filer_pb.FilerConf_PathConf](),
+		storageClasses: make(map[string]*filer_pb.FilerConf_StorageClassConf),
 	}
 	return fc
 }
@@ -109,6 +112,9 @@ func (fc *FilerConf) doLoadConf(conf *filer_pb.FilerConf) (err error) {
 			return nil
 		}
 	}
+	for _, storageClass := range conf.StorageClasses {
+		fc.SetStorageClassConf(storageClass)
+	}
 	return nil
 }
 
@@ -190,12 +196,30 @@ func mergePathConf(a, b *filer_pb.FilerConf_PathConf) {
 	a.DataNode = util.Nvl(b.DataNode, a.DataNode)
 	a.DisableChunkDeletion = b.DisableChunkDeletion || a.DisableChunkDeletion
 	a.Worm = b.Worm || a.Worm
+	a.Dedup = b.Dedup || a.Dedup
 	if b.WormRetentionTimeSeconds > 0 {
 		a.WormRetentionTimeSeconds = b.WormRetentionTimeSeconds
 	}
 	if b.WormGracePeriodSeconds > 0 {
 		a.WormGracePeriodSeconds = b.WormGracePeriodSeconds
 	}
+	if b.TrashRetentionSeconds > 0 {
+		a.TrashRetentionSeconds = b.TrashRetentionSeconds
+	}
+}
+
+// GetStorageClassConf returns the placement of the S3 storage class, if configured
+func (fc *FilerConf) GetStorageClassConf(storageClass string) (storageClassConf *filer_pb.FilerConf_StorageClassConf, found bool) {
+	storageClassConf, found = fc.storageClasses[storageClass]
+	return
+}
+
+func (fc *FilerConf) SetStorageClassConf(storageClassConf *filer_pb.FilerConf_StorageClassConf) {
+	fc.storageClasses[storageClassConf.StorageClass] = storageClassConf
+}
+
+func (fc *FilerConf) DeleteStorageClassConf(storageClass string) {
+	delete(fc.storageClasses, storageClass)
 }
 
 func (fc *FilerConf) ToProto() *filer_pb.FilerConf {
@@ -204,6 +228,12 @@ func (fc *FilerConf) ToProto() *filer_pb.FilerConf {
 		m.Locations = append(m.Locations, value)
 		return true
 	})
+	for _, storageClass := range fc.storageClasses {
+		m.StorageClasses = append(m.StorageClasses, storageClass)
+	}
+	sort.Slice(m.StorageClasses, func(i, j int) bool {
+		return m.StorageClasses[i].StorageClass < m.StorageClasses[j].StorageClass
+	})
 	return m
 }
 
@@ -47,3 +47,27 @@ func TestFilerConf(t *testing.T) {
 	assert.Equal(t, false, fc.MatchStorageRule("/buckets/other").ReadOnly)
 
 }
+
+func TestFilerConfStorageClasses(t *testing.T) {
+
+	fc := NewFilerConf()
+	fc.doLoadConf(&filer_pb.FilerConf{StorageClasses: []*filer_pb.FilerConf_StorageClassConf{
+		{StorageClass: "STANDARD_IA", DiskType: "hdd"},
+		{StorageClass: "STANDARD", DiskType: "ssd", Replication: "001"},
+	}})
+
+	standard, found := fc.GetStorageClassConf("STANDARD")
+	assert.True(t, found)
+	assert.Equal(t, "ssd", standard.DiskType)
+	assert.Equal(t, "001", standard.Replication)
+	_, found = fc.GetStorageClassConf("GLACIER")
+	assert.False(t, found)
+
+	fc.DeleteStorageClassConf("STANDARD_IA")
+	fc.SetStorageClassConf(&filer_pb.FilerConf_StorageClassConf{StorageClass: "GLACIER", DiskType: "archive"})
+	m := fc.ToProto()
+	assert.Equal(t, 2, len(m.StorageClasses))
+	assert.Equal(t, "GLACIER", m.StorageClasses[0].StorageClass)
+	assert.Equal(t, "STANDARD", m.StorageClasses[1].StorageClass)
+
+}
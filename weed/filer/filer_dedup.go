@@ -0,0 +1,240 @@
+package filer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	dedupChunkKeyPrefix = "dedup.chunk."
+	dedupRefKeyPrefix   = "dedup.ref."
+)
+
+// ChunkFingerprint identifies the content of a chunk.
+// Chunks are only shared by files of the same collection, replication and disk type.
+func ChunkFingerprint(collection, replication, diskType string, data []byte) string {
+	return fmt.Sprintf("%s,%s,%s,%x", collection, replication, diskType, sha256.Sum256(data))
+}
+
+func dedupChunkKey(fingerprint string) []byte {
+	return []byte(dedupChunkKeyPrefix + fingerprint)
+}
+
+func dedupRefKey(fileId string) []byte {
+	return []byte(dedupRefKeyPrefix + fileId)
+}
+
+// dedupRef counts the references to a chunk shared by deduplication.
+// The chunk is deleted when the last reference is released.
+type dedupRef struct {
+	count       uint32
+	fingerprint string
+}
+
+func (f *Filer) readDedupRef(ctx context.Context, fileId string) (ref dedupRef, err error) {
+	value, err := f.Store.KvGet(ctx, dedupRefKey(fileId))
+	if err == ErrKvNotFound {
+		return ref, nil
+	}
+	if err != nil {
+		return ref, err
+	}
+	if len(value) < 4 {
+		return ref, fmt.Errorf("invalid dedup reference of %s", fileId)
+	}
+	ref.count = binary.BigEndian.Uint32(value)
+	ref.fingerprint = string(value[4:])
+	return ref, nil
+}
+
+func (f *Filer) writeDedupRef(ctx context.Context, fileId string, ref dedupRef) error {
+	if ref.count == 0 {
+		return f.Store.KvDelete(ctx, dedupRefKey(fileId))
+	}
+	value := make([]byte, 4+len(ref.fingerprint))
+	binary.BigEndian.PutUint32(value, ref.count)
+	copy(value[4:], ref.fingerprint)
+	return f.Store.KvPut(ctx, dedupRefKey(fileId), value)
+}
+
+// FindDuplicateChunk returns a stored chunk with the same fingerprint, referenced once more by the caller.
+// The caller owns the reference, released by deleting the chunk as usual. The chunk is marked as
+// deduplicated, so that its deletion releases the reference.
+func (f *Filer) FindDuplicateChunk(ctx context.Context, fingerprint string) (chunk *filer_pb.FileChunk, found bool) {
+	if f.CanShareChunks() != nil {
+		return nil, false
+	}
+	value, err := f.Store.KvGet(ctx, dedupChunkKey(fingerprint))
+	if err != nil {
+		if err != ErrKvNotFound {
+			glog.ErrorfCtx(ctx, "find duplicate chunk %s: %v", fingerprint, err)
+		}
+		return nil, false
+	}
+	chunk = &filer_pb.FileChunk{}
+	if err := proto.Unmarshal(value, chunk); err != nil {
+		glog.ErrorfCtx(ctx, "decode duplicate chunk %s: %v", fingerprint, err)
+		return nil, false
+	}
+	fileId := chunk.GetFileIdString()
+	unlock := f.lockChunkRef(fileId)
+	defer unlock()
+	// the chunk may have been released since it was found
+	ref, err := f.readDedupRef(ctx, fileId)
+	if err != nil || ref.count == 0 {
+		glog.V(1).InfofCtx(ctx, "duplicate chunk %s of %s is not referenced: %v", fileId, fingerprint, err)
+		return nil, false
+	}
+	ref.count++
+	if err := f.writeDedupRef(ctx, fileId, ref); err != nil {
+		glog.ErrorfCtx(ctx, "reference duplicate chunk %s: %v", fileId, err)
+		return nil, false
+	}
+	chunk.IsDeduplicated = true
+	return chunk, true
+}
+
+// RegisterDedupChunk references a newly uploaded chunk, and indexes it by its fingerprint to be shared by later uploads.
+// The chunk is marked as deduplicated, so that its deletion releases the reference.
+func (f *Filer) RegisterDedupChunk(ctx context.Context, fingerprint string, chunk *filer_pb.FileChunk) error {
+	if err := f.CanShareChunks(); err != nil {
+		return err
+	}
+	fileId := chunk.GetFileIdString()
+	value, err := proto.Marshal(&filer_pb.FileChunk{
+		FileId:       fileId,
+		Size:         chunk.Size,
+		ETag:         chunk.ETag,
+		CipherKey:    chunk.CipherKey,
+		IsCompressed: chunk.IsCompressed,
+	})
+	if err != nil {
+		return err
+	}
+	// a release of the chunk, e.g. by a failed upload, unindexes it under the same lock
+	unlock := f.lockChunkRef(fileId)
+	defer unlock()
+	if err := f.writeDedupRef(ctx, fileId, dedupRef{count: 1, fingerprint: fingerprint}); err != nil {
+		return fmt.Errorf("reference chunk %s: %w", fileId, err)
+	}
+	chunk.IsDeduplicated = true
+	if _, err := f.Store.KvGet(ctx, dedupChunkKey(fingerprint)); err != ErrKvNotFound {
+		// already indexed by a concurrent upload of the same content
+		return err
+	}
+	return f.Store.KvPut(ctx, dedupChunkKey(fingerprint), value)
+}
+
+// releaseDedupRef is called before deleting a chunk, not from a snapshot.
+// It returns true if no other file references the chunk.
+func (f *Filer) releaseDedupRef(ctx context.Context, fileId string) bool {
+	unlock := f.lockChunkRef(fileId)
+	defer unlock()
+	ref, err := f.readDedupRef(ctx, fileId)
+	if err != nil {
+		// keeping a chunk is safer than losing the data of other files
+		glog.ErrorfCtx(ctx, "read dedup reference of %s: %v", fileId, err)
+		return false
+	}
+	if ref.count == 0 {
+		return true
+	}
+	ref.count--
+	if ref.count == 0 {
+		// stop sharing the chunk before it is deleted
+		if value, err := f.Store.KvGet(ctx, dedupChunkKey(ref.fingerprint)); err == nil {
+			indexed := &filer_pb.FileChunk{}
+			if proto.Unmarshal(value, indexed) == nil && indexed.GetFileIdString() == fileId {
+				if err := f.Store.KvDelete(ctx, dedupChunkKey(ref.fingerprint)); err != nil {
+					glog.ErrorfCtx(ctx, "unindex chunk %s: %v", fileId, err)
+					return false
+				}
+			}
+		} else if err != ErrKvNotFound {
+			glog.ErrorfCtx(ctx, "find indexed chunk %s: %v", ref.fingerprint, err)
+			return false
+		}
+	}
+	if err := f.writeDedupRef(ctx, fileId, ref); err != nil {
+		glog.ErrorfCtx(ctx, "write dedup reference of %s: %v", fileId, err)
+		return false
+	}
+	return ref.count == 0
+}
+
+// ReleaseReplacedDedupRefs releases the references of the old chunks replaced by new references to the same chunks,
+// e.g. when a file is overwritten with the same content, since only chunks missing from the new entry are deleted.
+// The references are released in the background, like the ones of deleted chunks.
+func (f *Filer) ReleaseReplacedDedupRefs(ctx context.Context, oldChunks, newChunks []*filer_pb.FileChunk) {
+	if !hasDeduplicatedChunk(oldChunks) || len(newChunks) == 0 || sameChunks(oldChunks, newChunks) {
+		return
+	}
+	oldData, _, err := ResolveChunkManifest(ctx, f.MasterClient.GetLookupFileIdFunction(), oldChunks, 0, math.MaxInt64)
+	if err != nil {
+		glog.ErrorfCtx(ctx, "resolve old chunks: %v", err)
+		return
+	}
+	newData, _, err := ResolveChunkManifest(ctx, f.MasterClient.GetLookupFileIdFunction(), newChunks, 0, math.MaxInt64)
+	if err != nil {
+		glog.ErrorfCtx(ctx, "resolve new chunks: %v", err)
+		return
+	}
+	for _, chunk := range replacedChunks(oldData, newData) {
+		if chunk.IsDeduplicated {
+			f.dedupReplacedQueue.EnQueue(chunk.GetFileIdString())
+		}
+	}
+}
+
+// hasDeduplicatedChunk is true if any chunk, or any manifest which may hold them, is shared by deduplication
+func hasDeduplicatedChunk(chunks []*filer_pb.FileChunk) bool {
+	for _, chunk := range chunks {
+		if chunk.IsDeduplicated || chunk.IsChunkManifest {
+			return true
+		}
+	}
+	return false
+}
+
+// sameChunks checks whether the entry is saved again with unchanged chunks, e.g. when only the attributes are updated
+func sameChunks(oldChunks, newChunks []*filer_pb.FileChunk) bool {
+	if len(oldChunks) != len(newChunks) {
+		return false
+	}
+	for i, chunk := range oldChunks {
+		other := newChunks[i]
+		if chunk.GetFileIdString() != other.GetFileIdString() || chunk.Offset != other.Offset ||
+			chunk.Size != other.Size || chunk.ModifiedTsNs != other.ModifiedTsNs {
+			return false
+		}
+	}
+	return true
+}
+
+// replacedChunks are the old chunks whose file id is kept in the new chunks, but referenced by a new chunk
+func replacedChunks(oldChunks, newChunks []*filer_pb.FileChunk) (replaced []*filer_pb.FileChunk) {
+	type reference struct {
+		fileId       string
+		offset       int64
+		modifiedTsNs int64
+	}
+	fileIds := make(map[string]bool)
+	references := make(map[reference]bool)
+	for _, chunk := range newChunks {
+		fileIds[chunk.GetFileIdString()] = true
+		references[reference{chunk.GetFileIdString(), chunk.Offset, chunk.ModifiedTsNs}] = true
+	}
+	for _, chunk := range oldChunks {
+		if fileIds[chunk.GetFileIdString()] && !references[reference{chunk.GetFileIdString(), chunk.Offset, chunk.ModifiedTsNs}] {
+			replaced = append(replaced, chunk)
+		}
+	}
+	return
+}
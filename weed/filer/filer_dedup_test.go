@@ -0,0 +1,137 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestChunkFingerprint(t *testing.T) {
+	data := []byte("some content")
+	if ChunkFingerprint("c1", "000", "", data) != ChunkFingerprint("c1", "000", "", []byte("some content")) {
+		t.Errorf("the same content should have the same fingerprint")
+	}
+	if ChunkFingerprint("c1", "000", "", data) == ChunkFingerprint("c2", "000", "", data) {
+		t.Errorf("chunks should not be shared across collections")
+	}
+	if ChunkFingerprint("c1", "000", "", data) == ChunkFingerprint("c1", "001", "", data) {
+		t.Errorf("chunks should not be shared across replications")
+	}
+}
+
+func TestReplacedChunks(t *testing.T) {
+	oldChunks := []*filer_pb.FileChunk{
+		{FileId: "1,a", Offset: 0, ModifiedTsNs: 1},
+		{FileId: "1,b", Offset: 10, ModifiedTsNs: 1},
+		{FileId: "1,c", Offset: 20, ModifiedTsNs: 1},
+	}
+
+	// metadata changes keep the same chunks
+	if replaced := replacedChunks(oldChunks, oldChunks); len(replaced) != 0 {
+		t.Errorf("unchanged chunks are replaced: %v", replaced)
+	}
+	if !sameChunks(oldChunks, oldChunks) {
+		t.Errorf("unchanged chunks are not the same")
+	}
+
+	// overwritten with the same content at 0, new content at 10, and truncated at 20
+	newChunks := []*filer_pb.FileChunk{
+		{FileId: "1,a", Offset: 0, ModifiedTsNs: 2},
+		{FileId: "1,d", Offset: 10, ModifiedTsNs: 2},
+	}
+	if sameChunks(oldChunks, newChunks) {
+		t.Errorf("changed chunks are the same")
+	}
+	replaced := replacedChunks(oldChunks, newChunks)
+	if len(replaced) != 1 || replaced[0].FileId != "1,a" {
+		t.Errorf("unexpected replaced chunks %v", replaced)
+	}
+}
+
+func TestDedupRefs(t *testing.T) {
+	ctx := context.Background()
+	f := newMemoryFiler(newMemoryStore())
+	fingerprint := ChunkFingerprint("c1", "000", "", []byte("some content"))
+
+	registered := &filer_pb.FileChunk{FileId: "1,a", Size: 12}
+	if err := f.RegisterDedupChunk(ctx, fingerprint, registered); err != nil {
+		t.Fatalf("register chunk: %v", err)
+	}
+	if !registered.IsDeduplicated {
+		t.Errorf("a registered chunk should be marked as deduplicated")
+	}
+	for i := 0; i < 2; i++ {
+		if chunk, found := f.FindDuplicateChunk(ctx, fingerprint); !found || chunk.FileId != "1,a" || !chunk.IsDeduplicated {
+			t.Fatalf("duplicate chunk not found: %v", chunk)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if f.releaseDedupRef(ctx, "1,a") {
+			t.Fatalf("chunk released while still referenced")
+		}
+	}
+	if !f.releaseDedupRef(ctx, "1,a") {
+		t.Fatalf("chunk should be released with the last reference")
+	}
+	if _, found := f.FindDuplicateChunk(ctx, fingerprint); found {
+		t.Errorf("a released chunk should not be shared")
+	}
+
+	// the references are not visible to a peer filer with a separate store
+	f.onPeerStore("peer:8888", true)
+	if err := f.RegisterDedupChunk(ctx, fingerprint, &filer_pb.FileChunk{FileId: "1,b", Size: 12}); !errors.Is(err, ErrFilerStoreNotShared) {
+		t.Errorf("chunks should not be shared with a separate peer store, got %v", err)
+	}
+	f.onPeerStore("peer:8888", false)
+	if err := f.CanShareChunks(); err != nil {
+		t.Errorf("can share chunks after the peer left: %v", err)
+	}
+}
+
+// consumeFileIds returns the queued file ids
+func consumeFileIds(q *util.UnboundedQueue) (fileIds []string) {
+	q.Consume(func(consumed []string) {
+		fileIds = append(fileIds, consumed...)
+	})
+	return
+}
+
+func TestDeleteDedupChunks(t *testing.T) {
+	ctx := context.Background()
+	f := newMemoryFiler(newMemoryStore())
+	fingerprint := ChunkFingerprint("c1", "000", "", []byte("some content"))
+
+	chunk := &filer_pb.FileChunk{FileId: "1,a", Size: 12}
+	if err := f.RegisterDedupChunk(ctx, fingerprint, chunk); err != nil {
+		t.Fatalf("register chunk: %v", err)
+	}
+	duplicate, found := f.FindDuplicateChunk(ctx, fingerprint)
+	if !found {
+		t.Fatalf("duplicate chunk not found")
+	}
+
+	// chunks not shared by deduplication are deleted without reading any reference
+	f.DeleteChunksNotRecursive([]*filer_pb.FileChunk{{FileId: "1,b", Size: 12}})
+	if released := consumeFileIds(f.dedupReleaseQueue); len(released) != 0 {
+		t.Errorf("unshared chunks should not be released: %v", released)
+	}
+	if deleted := consumeFileIds(f.fileIdDeletionQueue); len(deleted) != 1 || deleted[0] != "1,b" {
+		t.Errorf("unexpected deleted chunks %v", deleted)
+	}
+
+	// deduplicated chunks are released in the background, and deleted with the last reference
+	f.DeleteChunksNotRecursive([]*filer_pb.FileChunk{chunk})
+	if deleted := consumeFileIds(f.fileIdDeletionQueue); len(deleted) != 0 {
+		t.Errorf("a deduplicated chunk should be released before it is deleted: %v", deleted)
+	}
+	if released := consumeFileIds(f.dedupReleaseQueue); len(released) != 1 || f.releaseDedupRef(ctx, released[0]) {
+		t.Errorf("a chunk still referenced should be kept: %v", released)
+	}
+	f.DeleteChunksNotRecursive([]*filer_pb.FileChunk{duplicate})
+	if released := consumeFileIds(f.dedupReleaseQueue); len(released) != 1 || !f.releaseDedupRef(ctx, released[0]) {
+		t.Errorf("the last reference should delete the chunk: %v", released)
+	}
+}
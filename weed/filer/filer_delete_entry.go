@@ -3,6 +3,7 @@ package filer
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -29,7 +30,31 @@ func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p util.FullPath, isR
 	if ifNotModifiedAfter > 0 && entry.Attr.Mtime.Unix() > ifNotModifiedAfter {
 		return nil
 	}
+	// check the whole tree before deleting anything, since deleted entries are notified right away
+	if err = f.CheckObjectLockTree(ctx, entry); err != nil {
+		return err
+	}
 	isDeleteCollection := f.isBucket(entry)
+	if err = f.checkSnapshotDelete(ctx, p, isDeleteCollection); err != nil {
+		return err
+	}
+	if retention := f.trashRetention(p); retention > 0 && shouldDeleteChunks && !isDeleteCollection {
+		isEmptyFolder := false
+		if entry.IsDirectory() {
+			entries, _, listErr := f.ListDirectoryEntries(ctx, p, "", false, 1, "", "", "")
+			if listErr != nil {
+				return fmt.Errorf("list folder %s: %v", p, listErr)
+			}
+			if len(entries) > 0 && !isRecursive {
+				return fmt.Errorf("%s: %s", MsgFailDelNonEmptyFolder, p)
+			}
+			isEmptyFolder = len(entries) == 0
+		}
+		// an empty folder has nothing worth restoring
+		if !isEmptyFolder {
+			return f.moveToTrash(ctx, entry, retention, isFromOtherCluster, signatures)
+		}
+	}
 	if entry.IsDirectory() {
 		// delete the folder children, not including the folder itself
 		err = f.doBatchDeleteFolderMetaAndData(ctx, entry, isRecursive, ignoreRecursiveError, shouldDeleteChunks && !isDeleteCollection, isDeleteCollection, isFromOtherCluster, signatures, func(hardLinkIds []HardLinkId) error {
@@ -42,7 +67,7 @@ func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p util.FullPath, isR
 		})
 		if err != nil {
 			glog.V(2).InfofCtx(ctx, "delete directory %s: %v", p, err)
-			return fmt.Errorf("delete directory %s: %v", p, err)
+			return fmt.Errorf("delete directory %s: %w", p, err)
 		}
 	}
 
@@ -70,7 +95,12 @@ func (f *Filer) doBatchDeleteFolderMetaAndData(ctx context.Context, entry *Entry
 	var chunksToDelete []*filer_pb.FileChunk
 	lastFileName := ""
 	includeLastFile := false
-	if !isDeletingBucket || !f.Store.CanDropWholeBucket() {
+	var droppedBytes, droppedInodes int64
+	var isDroppedUsageCounted bool
+	if isDeletingBucket && f.Store.CanDropWholeBucket() {
+		// the entries of the bucket are dropped without events, which would update the quota usage
+		droppedBytes, droppedInodes, isDroppedUsageCounted = f.countDroppedQuotaUsage(ctx, entry.FullPath)
+	} else {
 		for {
 			entries, _, err := f.ListDirectoryEntries(ctx, entry.FullPath, lastFileName, includeLastFile, PaginationSize, "", "", "")
 			if err != nil {
@@ -115,6 +145,9 @@ func (f *Filer) doBatchDeleteFolderMetaAndData(ctx context.Context, entry *Entry
 	if storeDeletionErr := f.Store.DeleteFolderChildren(ctx, entry.FullPath); storeDeletionErr != nil {
 		return fmt.Errorf("filer store delete: %w", storeDeletionErr)
 	}
+	if isDroppedUsageCounted {
+		f.dropQuotaUsage(entry.FullPath, droppedBytes, droppedInodes, time.Now().UnixNano())
+	}
 
 	f.NotifyUpdateEvent(ctx, entry, nil, shouldDeleteChunks, isFromOtherCluster, signatures)
 	f.DeleteChunks(ctx, entry.FullPath, chunksToDelete)
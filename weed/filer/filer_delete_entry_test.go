@@ -0,0 +1,199 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/notification"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/util/log_buffer"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryStore keeps the entries in memory, for tests
+type memoryStore struct {
+	sync.Mutex
+	entries map[util.FullPath]*Entry
+	kv      map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: make(map[util.FullPath]*Entry), kv: make(map[string][]byte)}
+}
+
+func (s *memoryStore) GetName() string { return "memory" }
+func (s *memoryStore) Initialize(configuration util.Configuration, prefix string) error {
+	return nil
+}
+func (s *memoryStore) InsertEntry(ctx context.Context, entry *Entry) error {
+	s.Lock()
+	defer s.Unlock()
+	s.entries[entry.FullPath] = entry.ShallowClone()
+	return nil
+}
+func (s *memoryStore) UpdateEntry(ctx context.Context, entry *Entry) error {
+	return s.InsertEntry(ctx, entry)
+}
+func (s *memoryStore) FindEntry(ctx context.Context, p util.FullPath) (*Entry, error) {
+	s.Lock()
+	defer s.Unlock()
+	if entry, found := s.entries[p]; found {
+		return entry.ShallowClone(), nil
+	}
+	return nil, filer_pb.ErrNotFound
+}
+func (s *memoryStore) DeleteEntry(ctx context.Context, p util.FullPath) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.entries, p)
+	return nil
+}
+func (s *memoryStore) DeleteFolderChildren(ctx context.Context, dir util.FullPath) error {
+	s.Lock()
+	defer s.Unlock()
+	for p := range s.entries {
+		if strings.HasPrefix(string(p), string(dir)+"/") {
+			delete(s.entries, p)
+		}
+	}
+	return nil
+}
+func (s *memoryStore) ListDirectoryEntries(ctx context.Context, dir util.FullPath, startFileName string, includeStartFile bool, limit int64, eachEntryFunc ListEachEntryFunc) (string, error) {
+	return s.ListDirectoryPrefixedEntries(ctx, dir, startFileName, includeStartFile, limit, "", eachEntryFunc)
+}
+func (s *memoryStore) ListDirectoryPrefixedEntries(ctx context.Context, dir util.FullPath, startFileName string, includeStartFile bool, limit int64, prefix string, eachEntryFunc ListEachEntryFunc) (lastFileName string, err error) {
+	s.Lock()
+	var children []*Entry
+	for p, entry := range s.entries {
+		parent, name := p.DirAndName()
+		if util.FullPath(parent) == dir && strings.HasPrefix(name, prefix) &&
+			(name > startFileName || includeStartFile && name == startFileName) {
+			children = append(children, entry.ShallowClone())
+		}
+	}
+	s.Unlock()
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name() < children[j].Name()
+	})
+	for _, entry := range children {
+		if limit <= 0 || !eachEntryFunc(entry) {
+			break
+		}
+		limit--
+		lastFileName = entry.Name()
+	}
+	return lastFileName, nil
+}
+func (s *memoryStore) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+func (s *memoryStore) CommitTransaction(ctx context.Context) error   { return nil }
+func (s *memoryStore) RollbackTransaction(ctx context.Context) error { return nil }
+func (s *memoryStore) KvPut(ctx context.Context, key []byte, value []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	s.kv[string(key)] = value
+	return nil
+}
+func (s *memoryStore) KvGet(ctx context.Context, key []byte) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+	if value, found := s.kv[string(key)]; found {
+		return value, nil
+	}
+	return nil, ErrKvNotFound
+}
+func (s *memoryStore) KvDelete(ctx context.Context, key []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	delete(s.kv, string(key))
+	return nil
+}
+func (s *memoryStore) Shutdown() {}
+
+func newMemoryFiler(store *memoryStore) *Filer {
+	return &Filer{
+		Store:               NewFilerStoreWrapper(store),
+		FilerConf:           NewFilerConf(),
+		DirBucketsPath:      "/buckets",
+		fileIdDeletionQueue: util.NewUnboundedQueue(),
+		dedupReleaseQueue:   util.NewUnboundedQueue(),
+		dedupReplacedQueue:  util.NewUnboundedQueue(),
+		LocalMetaLogBuffer:  log_buffer.NewLogBuffer("test", time.Hour, nil, nil, func() {}),
+	}
+}
+
+// eventCounter counts the metadata change notifications
+type eventCounter struct {
+	sync.Mutex
+	keys []string
+}
+
+func (q *eventCounter) GetName() string { return "counter" }
+func (q *eventCounter) Initialize(configuration util.Configuration, prefix string) error {
+	return nil
+}
+func (q *eventCounter) SendMessage(key string, message proto.Message) error {
+	q.Lock()
+	defer q.Unlock()
+	q.keys = append(q.keys, key)
+	return nil
+}
+
+func TestDeleteFolderWithLockedObject(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	f := newMemoryFiler(store)
+	store.entries["/buckets"] = &Entry{FullPath: "/buckets", Attr: Attr{Mode: 0755 | 1<<31}}
+	store.entries["/buckets/locked"] = &Entry{
+		FullPath: "/buckets/locked",
+		Attr:     Attr{Mode: 0755 | 1<<31},
+		Extended: map[string][]byte{s3_constants.ExtObjectLockEnabledKey: []byte(s3_constants.ObjectLockEnabled)},
+	}
+	store.entries["/buckets/locked/dir"] = &Entry{FullPath: "/buckets/locked/dir", Attr: Attr{Mode: 0755 | 1<<31}}
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		store.entries[util.FullPath("/buckets/locked/dir/"+name)] = &Entry{
+			FullPath: util.FullPath("/buckets/locked/dir/" + name),
+			Attr:     Attr{Mode: 0644},
+			Extended: map[string][]byte{},
+		}
+	}
+	locked := store.entries["/buckets/locked/dir/b.txt"]
+	locked.Extended[s3_constants.ExtObjectLockModeKey] = []byte(s3_constants.RetentionModeCompliance)
+	locked.Extended[s3_constants.ExtRetentionUntilDateKey] = []byte(strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+	entryCount := len(store.entries)
+
+	events := &eventCounter{}
+	notification.Queue = events
+	defer func() {
+		notification.Queue = nil
+	}()
+
+	err := f.DeleteEntryMetaAndData(ctx, "/buckets/locked/dir", true, true, true, false, nil, 0)
+	if !errors.Is(err, ErrObjectLocked) {
+		t.Fatalf("deleting a folder with a locked object: %v", err)
+	}
+	if len(events.keys) != 0 {
+		t.Errorf("no deletion should be notified, got %v", events.keys)
+	}
+	if len(store.entries) != entryCount {
+		t.Errorf("no entry should be deleted, %d of %d left", len(store.entries), entryCount)
+	}
+
+	// without object lock, the folder is not walked and is deleted
+	delete(store.entries["/buckets/locked"].Extended, s3_constants.ExtObjectLockEnabledKey)
+	if err := f.DeleteEntryMetaAndData(ctx, "/buckets/locked/dir", true, true, false, false, nil, 0); err != nil {
+		t.Fatalf("delete folder: %v", err)
+	}
+	if _, found := store.entries["/buckets/locked/dir/a.txt"]; found {
+		t.Errorf("the folder should be deleted")
+	}
+}
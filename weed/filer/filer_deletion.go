@@ -45,6 +45,23 @@ func (f *Filer) loopProcessingDeletion() {
 	var deletionCount int
 	for {
 		deletionCount = 0
+		// deduplicated chunks are deleted with their last reference, which may take a cluster lock
+		f.dedupReleaseQueue.Consume(func(fileIds []string) {
+			deletionCount += len(fileIds)
+			for _, fileId := range fileIds {
+				if !f.releaseDedupRef(context.Background(), fileId) {
+					glog.V(3).Infof("keep chunk %s shared with other files", fileId)
+					continue
+				}
+				f.deleteUnsharedFileId(context.Background(), fileId, false)
+			}
+		})
+		f.dedupReplacedQueue.Consume(func(fileIds []string) {
+			deletionCount += len(fileIds)
+			for _, fileId := range fileIds {
+				f.releaseDedupRef(context.Background(), fileId)
+			}
+		})
 		f.fileIdDeletionQueue.Consume(func(fileIds []string) {
 			for len(fileIds) > 0 {
 				var toDeleteFileIds []string
@@ -55,11 +72,11 @@ func (f *Filer) loopProcessingDeletion() {
 					toDeleteFileIds = fileIds
 					fileIds = fileIds[:0]
 				}
-				deletionCount = len(toDeleteFileIds)
+				deletionCount += len(toDeleteFileIds)
 				_, err := operation.DeleteFileIdsWithLookupVolumeId(f.GrpcDialOption, toDeleteFileIds, lookupFunc)
 				if err != nil {
 					if !strings.Contains(err.Error(), storage.ErrorDeleted.Error()) {
-						glog.V(0).Infof("deleting fileIds len=%d error: %v", deletionCount, err)
+						glog.V(0).Infof("deleting fileIds len=%d error: %v", len(toDeleteFileIds), err)
 					}
 				} else {
 					glog.V(2).Infof("deleting fileIds %+v", toDeleteFileIds)
@@ -74,7 +91,7 @@ func (f *Filer) loopProcessingDeletion() {
 }
 
 func (f *Filer) DeleteUncommittedChunks(ctx context.Context, chunks []*filer_pb.FileChunk) {
-	f.doDeleteChunks(ctx, chunks)
+	f.doDeleteChunks(ctx, chunks, false)
 }
 
 func (f *Filer) DeleteChunks(ctx context.Context, fullpath util.FullPath, chunks []*filer_pb.FileChunk) {
@@ -82,13 +99,13 @@ func (f *Filer) DeleteChunks(ctx context.Context, fullpath util.FullPath, chunks
 	if rule.DisableChunkDeletion {
 		return
 	}
-	f.doDeleteChunks(ctx, chunks)
+	f.doDeleteChunks(ctx, chunks, IsInSnapshot(fullpath))
 }
 
-func (f *Filer) doDeleteChunks(ctx context.Context, chunks []*filer_pb.FileChunk) {
+func (f *Filer) doDeleteChunks(ctx context.Context, chunks []*filer_pb.FileChunk, fromSnapshot bool) {
 	for _, chunk := range chunks {
 		if !chunk.IsChunkManifest {
-			f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+			f.deleteFileId(ctx, chunk, fromSnapshot)
 			continue
 		}
 		dataChunks, manifestResolveErr := ResolveOneChunkManifest(ctx, f.MasterClient.LookupFileId, chunk)
@@ -96,16 +113,35 @@ func (f *Filer) doDeleteChunks(ctx context.Context, chunks []*filer_pb.FileChunk
 			glog.V(0).InfofCtx(ctx, "failed to resolve manifest %s: %v", chunk.FileId, manifestResolveErr)
 		}
 		for _, dChunk := range dataChunks {
-			f.fileIdDeletionQueue.EnQueue(dChunk.GetFileIdString())
+			f.deleteFileId(ctx, dChunk, fromSnapshot)
 		}
-		f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+		f.deleteFileId(ctx, chunk, fromSnapshot)
 	}
 }
 
 func (f *Filer) DeleteChunksNotRecursive(chunks []*filer_pb.FileChunk) {
 	for _, chunk := range chunks {
-		f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+		f.deleteFileId(context.Background(), chunk, false)
+	}
+}
+
+// deleteFileId queues the chunk for deletion, unless other files or a snapshot still reference it.
+// The references of deduplicated chunks are released in the background before deleting them.
+func (f *Filer) deleteFileId(ctx context.Context, chunk *filer_pb.FileChunk, fromSnapshot bool) {
+	if !fromSnapshot && chunk.IsDeduplicated {
+		f.dedupReleaseQueue.EnQueue(chunk.GetFileIdString())
+		return
+	}
+	f.deleteUnsharedFileId(ctx, chunk.GetFileIdString(), fromSnapshot)
+}
+
+// deleteUnsharedFileId queues the chunk for deletion, unless a snapshot still references it
+func (f *Filer) deleteUnsharedFileId(ctx context.Context, fileId string, fromSnapshot bool) {
+	if f.hasSnapshots.Load() && !f.releaseSnapshotRef(ctx, fileId, fromSnapshot) {
+		glog.V(3).InfofCtx(ctx, "keep chunk %s referenced by snapshots", fileId)
+		return
 	}
+	f.fileIdDeletionQueue.EnQueue(fileId)
 }
 
 func (f *Filer) deleteChunksIfNotNew(ctx context.Context, oldEntry, newEntry *Entry) {
@@ -123,4 +159,5 @@ func (f *Filer) deleteChunksIfNotNew(ctx context.Context, oldEntry, newEntry *En
 		return
 	}
 	f.DeleteChunksNotRecursive(toDelete)
+	f.ReleaseReplacedDedupRefs(ctx, oldChunks, newChunks)
 }
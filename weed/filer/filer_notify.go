@@ -32,6 +32,7 @@ func (f *Filer) NotifyUpdateEvent(ctx context.Context, oldEntry, newEntry *Entry
 	if strings.HasPrefix(fullpath, SystemLogDir) {
 		return
 	}
+	f.updateQuotaUsage(oldEntry, newEntry, time.Now().UnixNano(), true)
 	foundSelf := false
 	for _, sig := range signatures {
 		if sig == f.Signature {
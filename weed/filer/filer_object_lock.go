@@ -0,0 +1,188 @@
+package filer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// ErrObjectLocked is returned when an operation would remove or change data protected
+// by S3 Object Lock retention or a legal hold. It is enforced in the filer, so the
+// filer HTTP API, WebDAV, mount and SFTP honor the protections set through S3.
+var ErrObjectLocked = errors.New("operation not permitted: protected by object lock")
+
+// ObjectLockProtection describes the S3 Object Lock protection of an entry
+type ObjectLockProtection struct {
+	Mode           string // GOVERNANCE or COMPLIANCE, empty without retention
+	RetainUntil    time.Time
+	LegalHold      bool
+	RetentionValid bool // the retention date has not passed
+}
+
+// GetObjectLockProtection reads the S3 Object Lock retention and legal hold of an entry
+func GetObjectLockProtection(extended map[string][]byte, now time.Time) (p ObjectLockProtection) {
+	if extended == nil {
+		return
+	}
+	p.LegalHold = string(extended[s3_constants.ExtLegalHoldKey]) == s3_constants.LegalHoldOn
+	mode := string(extended[s3_constants.ExtObjectLockModeKey])
+	if mode != s3_constants.RetentionModeGovernance && mode != s3_constants.RetentionModeCompliance {
+		return
+	}
+	p.Mode = mode
+	if until, err := strconv.ParseInt(string(extended[s3_constants.ExtRetentionUntilDateKey]), 10, 64); err == nil {
+		p.RetainUntil = time.Unix(until, 0)
+		p.RetentionValid = p.RetainUntil.After(now)
+	}
+	return
+}
+
+// IsProtected is true if the entry can not be deleted, renamed or overwritten
+func (p ObjectLockProtection) IsProtected() bool {
+	return p.LegalHold || p.RetentionValid
+}
+
+// isProtectedFrom is like IsProtected, for operations which may bypass a governance mode retention
+func (p ObjectLockProtection) isProtectedFrom(ctx context.Context) bool {
+	if p.LegalHold {
+		return true
+	}
+	return p.RetentionValid && !(p.Mode == s3_constants.RetentionModeGovernance && isGovernanceBypassed(ctx))
+}
+
+type governanceBypassKey struct{}
+
+// WithGovernanceBypass lets the operations of the context delete or overwrite entries protected
+// only by a governance mode retention, after S3 permitted the request to bypass it.
+// The bypass is checked together with the change, so the retention can not be extended in between.
+func WithGovernanceBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, governanceBypassKey{}, true)
+}
+
+func isGovernanceBypassed(ctx context.Context) bool {
+	return ctx.Value(governanceBypassKey{}) != nil
+}
+
+func (p ObjectLockProtection) String() string {
+	if p.LegalHold {
+		return "legal hold"
+	}
+	return fmt.Sprintf("%s retention until %s", p.Mode, p.RetainUntil.UTC().Format(time.RFC3339))
+}
+
+// CheckObjectLock fails if the entry is protected by S3 Object Lock
+func CheckObjectLock(ctx context.Context, entry *Entry) error {
+	if entry == nil || entry.IsDirectory() {
+		return nil
+	}
+	if p := GetObjectLockProtection(entry.Extended, time.Now()); p.isProtectedFrom(ctx) {
+		return fmt.Errorf("%s: %w (%s)", entry.FullPath, ErrObjectLocked, p)
+	}
+	return nil
+}
+
+// checkObjectLockUpdate fails if an update changes the data of a protected entry,
+// or weakens a compliance mode retention. Metadata updates are allowed, so S3 can
+// extend retentions and release legal holds; governance retentions are left to S3.
+func checkObjectLockUpdate(ctx context.Context, oldEntry, entry *Entry) error {
+	if oldEntry == nil || oldEntry.IsDirectory() {
+		return nil
+	}
+	now := time.Now()
+	p := GetObjectLockProtection(oldEntry.Extended, now)
+	if !p.isProtectedFrom(ctx) {
+		return nil
+	}
+	if !sameEntryData(oldEntry, entry) {
+		return fmt.Errorf("%s: %w (%s)", oldEntry.FullPath, ErrObjectLocked, p)
+	}
+	if p.RetentionValid && p.Mode == s3_constants.RetentionModeCompliance {
+		newP := GetObjectLockProtection(entry.Extended, now)
+		if newP.Mode != s3_constants.RetentionModeCompliance || newP.RetainUntil.Before(p.RetainUntil) {
+			return fmt.Errorf("%s: %w (%s can not be shortened)", oldEntry.FullPath, ErrObjectLocked, p)
+		}
+	}
+	return nil
+}
+
+func sameEntryData(a, b *Entry) bool {
+	if a.Size() != b.Size() || !bytes.Equal(a.Content, b.Content) {
+		return false
+	}
+	aChunks, bChunks := a.GetChunks(), b.GetChunks()
+	if len(aChunks) != len(bChunks) {
+		return false
+	}
+	for i, chunk := range aChunks {
+		if chunk.GetFileIdString() != bChunks[i].GetFileIdString() || chunk.Offset != bChunks[i].Offset || chunk.Size != bChunks[i].Size {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckObjectLockTree fails if the entry, or any file under a directory entry,
+// is protected by S3 Object Lock. It is checked before deleting or moving entries,
+// which removes them from their current location. Only directories in buckets with
+// object lock enabled, or holding the buckets, are walked.
+func (f *Filer) CheckObjectLockTree(ctx context.Context, entry *Entry) error {
+	if !entry.IsDirectory() {
+		return CheckObjectLock(ctx, entry)
+	}
+	if bucket := f.DetectBucket(entry.FullPath); bucket != "" {
+		bucketPath := util.NewFullPath(f.DirBucketsPath, bucket)
+		bucketEntry := entry
+		if entry.FullPath != bucketPath {
+			found, err := f.FindEntry(ctx, bucketPath)
+			if err != nil {
+				return fmt.Errorf("find bucket %s: %w", bucket, err)
+			}
+			bucketEntry = found
+		}
+		if !isObjectLockEnabledBucket(bucketEntry) {
+			return nil
+		}
+		return f.checkObjectLockChildren(ctx, entry, true)
+	}
+	if entry.FullPath != util.FullPath(f.DirBucketsPath) && !isUnderDirectory(util.FullPath(f.DirBucketsPath), entry.FullPath) {
+		return nil
+	}
+	return f.checkObjectLockChildren(ctx, entry, false)
+}
+
+// checkObjectLockChildren checks the entries under a directory of a bucket with object lock enabled,
+// or under the buckets folder and its parents, where each bucket is checked separately
+func (f *Filer) checkObjectLockChildren(ctx context.Context, dir *Entry, inObjectLockBucket bool) error {
+	lastFileName := ""
+	for {
+		entries, hasMore, err := f.ListDirectoryEntries(ctx, dir.FullPath, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			return fmt.Errorf("list folder %s: %v", dir.FullPath, err)
+		}
+		for _, sub := range entries {
+			lastFileName = sub.Name()
+			if inObjectLockBucket && sub.IsDirectory() {
+				err = f.checkObjectLockChildren(ctx, sub, true)
+			} else {
+				err = f.CheckObjectLockTree(ctx, sub)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if !hasMore {
+			return nil
+		}
+	}
+}
+
+// isObjectLockEnabledBucket is true for buckets created with S3 Object Lock
+func isObjectLockEnabledBucket(entry *Entry) bool {
+	return entry.Extended != nil && string(entry.Extended[s3_constants.ExtObjectLockEnabledKey]) == s3_constants.ObjectLockEnabled
+}
@@ -0,0 +1,108 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+)
+
+func newObjectLockEntry(mode string, retainUntil time.Time, legalHold string) *Entry {
+	entry := &Entry{
+		FullPath: "/buckets/x/a.txt",
+		Attr:     Attr{Mode: 0644},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "1,0123", Size: 10}},
+		Extended: map[string][]byte{},
+	}
+	if mode != "" {
+		entry.Extended[s3_constants.ExtObjectLockModeKey] = []byte(mode)
+		entry.Extended[s3_constants.ExtRetentionUntilDateKey] = []byte(strconv.FormatInt(retainUntil.Unix(), 10))
+	}
+	if legalHold != "" {
+		entry.Extended[s3_constants.ExtLegalHoldKey] = []byte(legalHold)
+	}
+	return entry
+}
+
+func TestCheckObjectLock(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		entry     *Entry
+		protected bool
+	}{
+		{"no lock", newObjectLockEntry("", time.Time{}, ""), false},
+		{"governance", newObjectLockEntry(s3_constants.RetentionModeGovernance, now.Add(time.Hour), ""), true},
+		{"compliance", newObjectLockEntry(s3_constants.RetentionModeCompliance, now.Add(time.Hour), ""), true},
+		{"expired", newObjectLockEntry(s3_constants.RetentionModeCompliance, now.Add(-time.Hour), ""), false},
+		{"legal hold", newObjectLockEntry("", time.Time{}, s3_constants.LegalHoldOn), true},
+		{"released legal hold", newObjectLockEntry("", time.Time{}, s3_constants.LegalHoldOff), false},
+	}
+	for _, tt := range tests {
+		err := CheckObjectLock(context.Background(), tt.entry)
+		if tt.protected != errors.Is(err, ErrObjectLocked) {
+			t.Errorf("%s: expected protected %v, got %v", tt.name, tt.protected, err)
+		}
+	}
+
+	// a permitted bypass only lifts governance retentions
+	bypass := WithGovernanceBypass(context.Background())
+	if err := CheckObjectLock(bypass, newObjectLockEntry(s3_constants.RetentionModeGovernance, now.Add(time.Hour), "")); err != nil {
+		t.Errorf("expected bypassed governance retention to pass, got %v", err)
+	}
+	if err := CheckObjectLock(bypass, newObjectLockEntry(s3_constants.RetentionModeGovernance, now.Add(time.Hour), s3_constants.LegalHoldOn)); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("expected legal hold to be kept with a bypass, got %v", err)
+	}
+	if err := CheckObjectLock(bypass, newObjectLockEntry(s3_constants.RetentionModeCompliance, now.Add(time.Hour), "")); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("expected compliance retention to be kept with a bypass, got %v", err)
+	}
+}
+
+func TestCheckObjectLockUpdate(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	oldEntry := newObjectLockEntry(s3_constants.RetentionModeCompliance, until, s3_constants.LegalHoldOn)
+
+	// releasing the legal hold and extending the retention keeps the data
+	entry := newObjectLockEntry(s3_constants.RetentionModeCompliance, until.Add(time.Hour), s3_constants.LegalHoldOff)
+	if err := checkObjectLockUpdate(context.Background(), oldEntry, entry); err != nil {
+		t.Errorf("expected metadata update to pass, got %v", err)
+	}
+
+	// overwriting the data
+	entry = newObjectLockEntry(s3_constants.RetentionModeCompliance, until, s3_constants.LegalHoldOn)
+	entry.Chunks = []*filer_pb.FileChunk{{FileId: "2,0456", Size: 10}}
+	if err := checkObjectLockUpdate(context.Background(), oldEntry, entry); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("expected overwrite to fail, got %v", err)
+	}
+
+	// shortening or removing a compliance retention
+	entry = newObjectLockEntry(s3_constants.RetentionModeCompliance, until.Add(-time.Minute), s3_constants.LegalHoldOn)
+	if err := checkObjectLockUpdate(context.Background(), oldEntry, entry); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("expected shortened retention to fail, got %v", err)
+	}
+	entry = newObjectLockEntry("", time.Time{}, s3_constants.LegalHoldOn)
+	if err := checkObjectLockUpdate(context.Background(), oldEntry, entry); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("expected removed retention to fail, got %v", err)
+	}
+
+	// governance retentions can be lifted by s3 with a bypass
+	oldEntry = newObjectLockEntry(s3_constants.RetentionModeGovernance, until, "")
+	entry = newObjectLockEntry("", time.Time{}, "")
+	if err := checkObjectLockUpdate(context.Background(), oldEntry, entry); err != nil {
+		t.Errorf("expected lifted governance retention to pass, got %v", err)
+	}
+
+	// overwriting a governance retained object needs the bypass with the overwrite
+	entry = newObjectLockEntry("", time.Time{}, "")
+	entry.Chunks = []*filer_pb.FileChunk{{FileId: "2,0456", Size: 10}}
+	if err := checkObjectLockUpdate(context.Background(), oldEntry, entry); !errors.Is(err, ErrObjectLocked) {
+		t.Errorf("expected overwrite without bypass to fail, got %v", err)
+	}
+	if err := checkObjectLockUpdate(WithGovernanceBypass(context.Background()), oldEntry, entry); err != nil {
+		t.Errorf("expected overwrite with bypass to pass, got %v", err)
+	}
+}
@@ -0,0 +1,478 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	// the byte limit of a directory is kept in Entry.Quota, positive if enforced, same as for buckets
+	QuotaInodesKey = "Seaweed-X-Quota-Inodes" // the limit of files and folders under a directory
+
+	quotaDirectoriesKey = "quota.directories"
+	quotaRescanInterval = time.Hour
+	// a reservation of a change that is never counted, e.g. not notified, stops blocking other changes
+	quotaReservationTTL = time.Minute
+)
+
+var ErrQuotaExceeded = errors.New("directory quota exceeded")
+
+// DirectoryQuota is the limits of a directory, and the usage of all entries under it.
+//
+// The quotas are soft limits checked by each filer on its own: every filer counts the usage
+// from the metadata events, but only reserves the growth of its own changes. Changes made
+// at the same time through several filers can exceed a limit, until the usage is counted
+// on all filers.
+type DirectoryQuota struct {
+	Directory   util.FullPath
+	MaxBytes    int64 // 0 for no limit
+	MaxInodes   int64 // 0 for no limit
+	UsedBytes   int64
+	UsedInodes  int64
+	countedAtNs int64 // 0 until the usage is counted
+	// the growth of checked changes, until they are counted in the usage
+	reservedBytes  int64
+	reservedInodes int64
+}
+
+func (q *DirectoryQuota) IsCounted() bool {
+	return q.countedAtNs > 0
+}
+
+func (q *DirectoryQuota) contains(p util.FullPath) bool {
+	return isUnderDirectory(p, q.Directory)
+}
+
+func isUnderDirectory(p, dir util.FullPath) bool {
+	if dir == "/" {
+		return p != "/"
+	}
+	return strings.HasPrefix(string(p), string(dir)+"/")
+}
+
+type movedEntryKey struct{}
+
+// WithMovedEntry marks the entries created with the context as moved from the old entry,
+// which is deleted afterwards. Moving inside a directory does not change its quota usage.
+func WithMovedEntry(ctx context.Context, oldEntry *Entry) context.Context {
+	return context.WithValue(ctx, movedEntryKey{}, oldEntry)
+}
+
+func movedEntryOf(ctx context.Context) *Entry {
+	oldEntry, _ := ctx.Value(movedEntryKey{}).(*Entry)
+	return oldEntry
+}
+
+type directoryQuotas struct {
+	sync.RWMutex
+	quotas       map[util.FullPath]*DirectoryQuota
+	reservations map[util.FullPath][]*quotaReservation
+}
+
+// quotaReservation is the growth of a change checked against the quotas, held until the change is counted
+type quotaReservation struct {
+	quotas     []*DirectoryQuota
+	bytes      []int64
+	inodes     []int64
+	expireAtNs int64
+}
+
+// removeReservation drops a reservation of the path, and returns false if it is no longer held
+func (dq *directoryQuotas) removeReservation(p util.FullPath, r *quotaReservation) bool {
+	reservations := dq.reservations[p]
+	for i, reservation := range reservations {
+		if reservation != r {
+			continue
+		}
+		if len(reservations) == 1 {
+			delete(dq.reservations, p)
+		} else {
+			dq.reservations[p] = append(reservations[:i:i], reservations[i+1:]...)
+		}
+		for j, q := range r.quotas {
+			q.reservedBytes -= r.bytes[j]
+			q.reservedInodes -= r.inodes[j]
+		}
+		return true
+	}
+	return false
+}
+
+func (dq *directoryQuotas) removeExpiredReservations(nowNs int64) {
+	for p, reservations := range dq.reservations {
+		for _, r := range reservations {
+			if r.expireAtNs < nowNs {
+				dq.removeReservation(p, r)
+			}
+		}
+	}
+}
+
+// QuotaLimits reads the limits of a directory, 0 for no limit
+func QuotaLimits(entry *Entry) (maxBytes, maxInodes int64) {
+	if entry == nil || !entry.IsDirectory() {
+		return 0, 0
+	}
+	if entry.Quota > 0 {
+		maxBytes = entry.Quota
+	}
+	if value, found := entry.Extended[QuotaInodesKey]; found {
+		maxInodes, _ = strconv.ParseInt(string(value), 10, 64)
+	}
+	return maxBytes, max(maxInodes, 0)
+}
+
+// quotaLimits reads the limits of a directory that this filer enforces.
+// The size limits of buckets set by s3.bucket.quota are only enforced if enabled,
+// otherwise they are left to s3.bucket.quota.enforce.
+func (f *Filer) quotaLimits(entry *Entry) (maxBytes, maxInodes int64) {
+	maxBytes, maxInodes = QuotaLimits(entry)
+	if !f.EnforceBucketQuotas && entry != nil && f.isBucket(entry) {
+		maxBytes = 0
+	}
+	return
+}
+
+func quotaUsageOf(entry *Entry) (bytes, inodes int64) {
+	if entry == nil {
+		return 0, 0
+	}
+	if entry.IsDirectory() {
+		return 0, 1
+	}
+	return int64(entry.Size()), 1
+}
+
+// LoadQuotas finds the directories with quotas, and counts their usage in the background
+func (f *Filer) LoadQuotas() {
+	ctx := context.Background()
+	var dirs []util.FullPath
+	if value, err := f.Store.KvGet(ctx, []byte(quotaDirectoriesKey)); err == nil {
+		for _, dir := range strings.Split(string(value), "\n") {
+			if dir != "" {
+				dirs = append(dirs, util.FullPath(dir))
+			}
+		}
+	} else if err != ErrKvNotFound {
+		glog.Errorf("read quota directories: %v", err)
+	}
+	// quotas set on buckets by s3.bucket.quota
+	if f.EnforceBucketQuotas {
+		if _, err := f.StreamListDirectoryEntries(ctx, util.FullPath(f.DirBucketsPath), "", false, math.MaxInt32, "", "", "", func(bucket *Entry) bool {
+			if bucket.Quota > 0 {
+				dirs = append(dirs, bucket.FullPath)
+			}
+			return true
+		}); err != nil && err != filer_pb.ErrNotFound {
+			glog.Errorf("list buckets for quotas: %v", err)
+		}
+	}
+
+	for _, dir := range dirs {
+		entry, err := f.FindEntry(ctx, dir)
+		if err != nil {
+			if err != filer_pb.ErrNotFound {
+				glog.Errorf("find quota directory %s: %v", dir, err)
+			}
+			continue
+		}
+		f.watchQuota(entry)
+	}
+	f.saveQuotaDirectories()
+
+	go f.loopCountingQuotaUsage()
+}
+
+// watchQuota starts, updates or stops tracking the usage of a directory after its limits changed
+func (f *Filer) watchQuota(entry *Entry) {
+	maxBytes, maxInodes := f.quotaLimits(entry)
+	f.quotas.Lock()
+	if f.quotas.quotas == nil {
+		f.quotas.quotas = make(map[util.FullPath]*DirectoryQuota)
+	}
+	q, found := f.quotas.quotas[entry.FullPath]
+	if maxBytes == 0 && maxInodes == 0 {
+		delete(f.quotas.quotas, entry.FullPath)
+		f.quotas.Unlock()
+		if found {
+			glog.V(0).Infof("stop quota of %s", entry.FullPath)
+			f.saveQuotaDirectories()
+		}
+		return
+	}
+	if found {
+		q.MaxBytes, q.MaxInodes = maxBytes, maxInodes
+		f.quotas.Unlock()
+		return
+	}
+	f.quotas.quotas[entry.FullPath] = &DirectoryQuota{Directory: entry.FullPath, MaxBytes: maxBytes, MaxInodes: maxInodes}
+	f.quotas.Unlock()
+
+	glog.V(0).Infof("start quota of %s: %d bytes, %d inodes", entry.FullPath, maxBytes, maxInodes)
+	f.saveQuotaDirectories()
+	go f.countQuotaUsage(entry.FullPath)
+}
+
+// unwatchQuotas stops the quotas of the deleted directory, and of the directories under it
+func (f *Filer) unwatchQuotas(dir util.FullPath) {
+	f.quotas.Lock()
+	found := false
+	for p, q := range f.quotas.quotas {
+		if p == dir || isUnderDirectory(q.Directory, dir) {
+			delete(f.quotas.quotas, p)
+			found = true
+		}
+	}
+	f.quotas.Unlock()
+	if found {
+		f.saveQuotaDirectories()
+	}
+}
+
+func (f *Filer) saveQuotaDirectories() {
+	var dirs []string
+	f.quotas.RLock()
+	for p := range f.quotas.quotas {
+		dirs = append(dirs, string(p))
+	}
+	f.quotas.RUnlock()
+	sort.Strings(dirs)
+	if err := f.Store.KvPut(context.Background(), []byte(quotaDirectoriesKey), []byte(strings.Join(dirs, "\n"))); err != nil {
+		glog.Errorf("save quota directories: %v", err)
+	}
+}
+
+// GetDirectoryQuotas returns a copy of the quotas at or under the directory, all quotas if empty
+func (f *Filer) GetDirectoryQuotas(dir util.FullPath) (quotas []DirectoryQuota) {
+	f.quotas.RLock()
+	defer f.quotas.RUnlock()
+	for _, q := range f.quotas.quotas {
+		if dir == "" || q.Directory == dir || isUnderDirectory(q.Directory, dir) {
+			quotas = append(quotas, *q)
+		}
+	}
+	sort.Slice(quotas, func(i, j int) bool {
+		return quotas[i].Directory < quotas[j].Directory
+	})
+	return
+}
+
+func (f *Filer) loopCountingQuotaUsage() {
+	for {
+		time.Sleep(quotaRescanInterval)
+		// correct the drift from failed transactions and missed events
+		for _, q := range f.GetDirectoryQuotas("") {
+			f.countQuotaUsage(q.Directory)
+		}
+	}
+}
+
+// countQuotaUsage walks the directory tree to count the usage from scratch
+func (f *Filer) countQuotaUsage(dir util.FullPath) {
+	startNs := time.Now().UnixNano()
+	var bytes, inodes int64
+	if err := f.walkQuotaUsage(context.Background(), dir, &bytes, &inodes); err != nil {
+		glog.Errorf("count quota usage of %s: %v", dir, err)
+		return
+	}
+	f.quotas.Lock()
+	defer f.quotas.Unlock()
+	if q, found := f.quotas.quotas[dir]; found {
+		q.UsedBytes, q.UsedInodes, q.countedAtNs = bytes, inodes, startNs
+		glog.V(1).Infof("quota usage of %s: %d bytes, %d inodes", dir, bytes, inodes)
+	}
+}
+
+func (f *Filer) walkQuotaUsage(ctx context.Context, dir util.FullPath, bytes, inodes *int64) error {
+	lastFileName := ""
+	for {
+		entries, hasMore, err := f.ListDirectoryEntries(ctx, dir, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			return fmt.Errorf("list folder %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			lastFileName = entry.Name()
+			entryBytes, entryInodes := quotaUsageOf(entry)
+			*bytes += entryBytes
+			*inodes += entryInodes
+			if entry.IsDirectory() {
+				if err := f.walkQuotaUsage(ctx, entry.FullPath, bytes, inodes); err != nil {
+					return err
+				}
+			}
+		}
+		if !hasMore {
+			return nil
+		}
+	}
+}
+
+// reserveQuota fails a change that would grow the usage of a directory over its limits.
+// Changes that shrink the usage are allowed, even over the limits.
+// The growth is reserved until the change is counted by updateQuotaUsage, so concurrent changes
+// of this filer are checked against it. release drops the reservation of a change that is not made.
+// The changes in flight on other filers are not reserved, see DirectoryQuota.
+func (f *Filer) reserveQuota(oldEntry, newEntry *Entry) (release func(), err error) {
+	release = func() {}
+	f.quotas.Lock()
+	defer f.quotas.Unlock()
+	if len(f.quotas.quotas) == 0 || newEntry == nil {
+		return release, nil
+	}
+	newBytes, newInodes := quotaUsageOf(newEntry)
+	oldBytes, oldInodes := quotaUsageOf(oldEntry)
+	now := time.Now()
+	f.quotas.removeExpiredReservations(now.UnixNano())
+	reservation := &quotaReservation{expireAtNs: now.Add(quotaReservationTTL).UnixNano()}
+	for _, q := range f.quotas.quotas {
+		if !q.IsCounted() || !q.contains(newEntry.FullPath) {
+			continue
+		}
+		deltaBytes, deltaInodes := newBytes, newInodes
+		if oldEntry != nil && q.contains(oldEntry.FullPath) {
+			deltaBytes, deltaInodes = newBytes-oldBytes, newInodes-oldInodes
+		}
+		usedBytes, usedInodes := q.UsedBytes+q.reservedBytes, q.UsedInodes+q.reservedInodes
+		if q.MaxBytes > 0 && deltaBytes > 0 && usedBytes+deltaBytes > q.MaxBytes {
+			return release, fmt.Errorf("%s: %w (%s uses %d of %d bytes)", newEntry.FullPath, ErrQuotaExceeded, q.Directory, usedBytes, q.MaxBytes)
+		}
+		if q.MaxInodes > 0 && deltaInodes > 0 && usedInodes+deltaInodes > q.MaxInodes {
+			return release, fmt.Errorf("%s: %w (%s has %d of %d entries)", newEntry.FullPath, ErrQuotaExceeded, q.Directory, usedInodes, q.MaxInodes)
+		}
+		if deltaBytes > 0 || deltaInodes > 0 {
+			reservation.quotas = append(reservation.quotas, q)
+			reservation.bytes = append(reservation.bytes, max(deltaBytes, 0))
+			reservation.inodes = append(reservation.inodes, max(deltaInodes, 0))
+		}
+	}
+	if len(reservation.quotas) == 0 {
+		return release, nil
+	}
+	for i, q := range reservation.quotas {
+		q.reservedBytes += reservation.bytes[i]
+		q.reservedInodes += reservation.inodes[i]
+	}
+	if f.quotas.reservations == nil {
+		f.quotas.reservations = make(map[util.FullPath][]*quotaReservation)
+	}
+	f.quotas.reservations[newEntry.FullPath] = append(f.quotas.reservations[newEntry.FullPath], reservation)
+	return func() {
+		f.quotas.Lock()
+		defer f.quotas.Unlock()
+		f.quotas.removeReservation(newEntry.FullPath, reservation)
+	}, nil
+}
+
+// updateQuotaUsage counts a change to the usage of the directories with quotas,
+// and follows the changes to the quotas themselves.
+// A change made by this filer replaces its reservation.
+func (f *Filer) updateQuotaUsage(oldEntry, newEntry *Entry, tsNs int64, isLocal bool) {
+	if newEntry != nil && newEntry.IsDirectory() && !IsInTrash(newEntry.FullPath) && !IsInSnapshot(newEntry.FullPath) {
+		maxBytes, maxInodes := f.quotaLimits(newEntry)
+		f.quotas.RLock()
+		q, found := f.quotas.quotas[newEntry.FullPath]
+		changed := found != (maxBytes > 0 || maxInodes > 0) || (found && (q.MaxBytes != maxBytes || q.MaxInodes != maxInodes))
+		f.quotas.RUnlock()
+		if changed {
+			f.watchQuota(newEntry)
+		}
+	}
+	if oldEntry != nil && oldEntry.IsDirectory() && (newEntry == nil || newEntry.FullPath != oldEntry.FullPath) {
+		f.unwatchQuotas(oldEntry.FullPath)
+	}
+
+	f.quotas.Lock()
+	defer f.quotas.Unlock()
+	if isLocal && newEntry != nil {
+		if reservations := f.quotas.reservations[newEntry.FullPath]; len(reservations) > 0 {
+			f.quotas.removeReservation(newEntry.FullPath, reservations[0])
+		}
+	}
+	oldBytes, oldInodes := quotaUsageOf(oldEntry)
+	newBytes, newInodes := quotaUsageOf(newEntry)
+	for _, q := range f.quotas.quotas {
+		// the change is already in the counted usage
+		if !q.IsCounted() || tsNs <= q.countedAtNs {
+			continue
+		}
+		if oldEntry != nil && q.contains(oldEntry.FullPath) {
+			q.UsedBytes -= oldBytes
+			q.UsedInodes -= oldInodes
+		}
+		if newEntry != nil && q.contains(newEntry.FullPath) {
+			q.UsedBytes += newBytes
+			q.UsedInodes += newInodes
+		}
+	}
+}
+
+// onQuotaEvents counts the changes made by other filers
+func (f *Filer) onQuotaEvents(event *filer_pb.SubscribeMetadataResponse) {
+	message := event.EventNotification
+	for _, sig := range message.Signatures {
+		if sig == f.Signature {
+			// counted when the change was made by this filer
+			return
+		}
+	}
+	var oldEntry, newEntry *Entry
+	if message.OldEntry != nil {
+		oldEntry = FromPbEntry(event.Directory, message.OldEntry)
+	}
+	if message.NewEntry != nil {
+		newEntry = FromPbEntry(message.NewParentPath, message.NewEntry)
+	}
+	f.updateQuotaUsage(oldEntry, newEntry, event.TsNs, false)
+	if oldEntry != nil && newEntry == nil && f.isBucket(oldEntry) && f.Store.CanDropWholeBucket() {
+		// the entries of a dropped bucket are deleted without events
+		for _, q := range f.GetDirectoryQuotas("") {
+			if q.IsCounted() && q.contains(oldEntry.FullPath) {
+				go f.countQuotaUsage(q.Directory)
+			}
+		}
+	}
+}
+
+// countDroppedQuotaUsage counts the usage under a directory about to be deleted without events for its entries,
+// e.g. a dropped bucket, if any quota above counts it
+func (f *Filer) countDroppedQuotaUsage(ctx context.Context, dir util.FullPath) (bytes, inodes int64, counted bool) {
+	f.quotas.RLock()
+	for _, q := range f.quotas.quotas {
+		if q.IsCounted() && q.contains(dir) {
+			counted = true
+		}
+	}
+	f.quotas.RUnlock()
+	if !counted {
+		return 0, 0, false
+	}
+	if err := f.walkQuotaUsage(ctx, dir, &bytes, &inodes); err != nil {
+		// corrected by the next rescan
+		glog.Errorf("count quota usage of dropped %s: %v", dir, err)
+		return 0, 0, false
+	}
+	return bytes, inodes, true
+}
+
+// dropQuotaUsage subtracts the usage of the entries under a directory deleted without events
+func (f *Filer) dropQuotaUsage(dir util.FullPath, bytes, inodes int64, tsNs int64) {
+	f.quotas.Lock()
+	defer f.quotas.Unlock()
+	for _, q := range f.quotas.quotas {
+		if q.IsCounted() && tsNs > q.countedAtNs && q.contains(dir) {
+			q.UsedBytes -= bytes
+			q.UsedInodes -= inodes
+		}
+	}
+}
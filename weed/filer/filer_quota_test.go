@@ -0,0 +1,188 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func newQuotaTestFiler(quotas ...*DirectoryQuota) *Filer {
+	f := &Filer{}
+	f.quotas.quotas = make(map[util.FullPath]*DirectoryQuota)
+	for _, q := range quotas {
+		f.quotas.quotas[q.Directory] = q
+	}
+	return f
+}
+
+func quotaTestFile(p string, size uint64) *Entry {
+	return &Entry{
+		FullPath: util.FullPath(p),
+		Attr:     Attr{Mode: 0644, FileSize: size},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "1,01", Size: size}},
+	}
+}
+
+func TestQuotaLimits(t *testing.T) {
+	dir := &Entry{FullPath: "/data", Attr: Attr{Mode: os.ModeDir | 0755}, Quota: 1024, Extended: map[string][]byte{QuotaInodesKey: []byte("10")}}
+	if maxBytes, maxInodes := QuotaLimits(dir); maxBytes != 1024 || maxInodes != 10 {
+		t.Errorf("unexpected limits %d %d", maxBytes, maxInodes)
+	}
+	dir.Quota = -1024
+	if maxBytes, _ := QuotaLimits(dir); maxBytes != 0 {
+		t.Errorf("disabled quota should have no byte limit, got %d", maxBytes)
+	}
+	if maxBytes, maxInodes := QuotaLimits(quotaTestFile("/data/a", 1)); maxBytes != 0 || maxInodes != 0 {
+		t.Errorf("files have no quota")
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	f := newQuotaTestFiler(
+		&DirectoryQuota{Directory: "/data", MaxBytes: 100, UsedBytes: 90, countedAtNs: 1},
+		&DirectoryQuota{Directory: "/data/small", MaxInodes: 2, UsedInodes: 2, countedAtNs: 1},
+		&DirectoryQuota{Directory: "/uncounted", MaxBytes: 1},
+	)
+	for _, tc := range []struct {
+		name     string
+		oldEntry *Entry
+		newEntry *Entry
+		exceeded bool
+	}{
+		{"new file within quota", nil, quotaTestFile("/data/a", 10), false},
+		{"new file over quota", nil, quotaTestFile("/data/a", 11), true},
+		{"overwrite within quota", quotaTestFile("/data/a", 15), quotaTestFile("/data/a", 25), false},
+		{"shrink over quota", quotaTestFile("/data/a", 50), quotaTestFile("/data/a", 40), false},
+		{"move inside quota", quotaTestFile("/data/a", 50), quotaTestFile("/data/b", 50), false},
+		{"move into quota", quotaTestFile("/other/a", 50), quotaTestFile("/data/b", 50), true},
+		{"new file over inodes", nil, quotaTestFile("/data/small/c", 1), true},
+		{"overwrite within inodes", quotaTestFile("/data/small/a", 1), quotaTestFile("/data/small/a", 2), false},
+		{"the quota directory itself", nil, quotaTestFile("/data", 1000), false},
+		{"outside quotas", nil, quotaTestFile("/other/a", 1000), false},
+		{"not counted yet", nil, quotaTestFile("/uncounted/a", 1000), false},
+	} {
+		release, err := f.reserveQuota(tc.oldEntry, tc.newEntry)
+		if errors.Is(err, ErrQuotaExceeded) != tc.exceeded {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		release()
+	}
+}
+
+func TestReserveQuota(t *testing.T) {
+	data := &DirectoryQuota{Directory: "/data", MaxBytes: 100, UsedBytes: 80, countedAtNs: 1}
+	f := newQuotaTestFiler(data)
+
+	// concurrent writes are checked against the reserved growth
+	if _, err := f.reserveQuota(nil, quotaTestFile("/data/a", 15)); err != nil {
+		t.Fatalf("first write: %v", err)
+	}
+	if _, err := f.reserveQuota(nil, quotaTestFile("/data/b", 15)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("concurrent write over the reserved quota: %v", err)
+	}
+
+	// a failed write releases its reservation
+	release, err := f.reserveQuota(nil, quotaTestFile("/data/c", 5))
+	if err != nil {
+		t.Fatalf("write within the reserved quota: %v", err)
+	}
+	release()
+	release()
+	if data.reservedBytes != 15 {
+		t.Errorf("reserved %d bytes after a release", data.reservedBytes)
+	}
+
+	// a counted write replaces its reservation
+	f.updateQuotaUsage(nil, quotaTestFile("/data/a", 15), 2, true)
+	if data.UsedBytes != 95 || data.reservedBytes != 0 || len(f.quotas.reservations) != 0 {
+		t.Errorf("/data usage %d bytes, reserved %d bytes", data.UsedBytes, data.reservedBytes)
+	}
+	if _, err := f.reserveQuota(nil, quotaTestFile("/data/b", 6)); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("write over the counted quota: %v", err)
+	}
+}
+
+func TestBucketQuotaOptIn(t *testing.T) {
+	f := newQuotaTestFiler()
+	f.DirBucketsPath = "/buckets"
+	bucket := &Entry{FullPath: "/buckets/b", Attr: Attr{Mode: os.ModeDir | 0755}, Quota: 1024, Extended: map[string][]byte{QuotaInodesKey: []byte("10")}}
+	if maxBytes, maxInodes := f.quotaLimits(bucket); maxBytes != 0 || maxInodes != 10 {
+		t.Errorf("bucket size limit enforced without opt-in: %d %d", maxBytes, maxInodes)
+	}
+	dir := &Entry{FullPath: "/data", Attr: Attr{Mode: os.ModeDir | 0755}, Quota: 1024}
+	if maxBytes, _ := f.quotaLimits(dir); maxBytes != 1024 {
+		t.Errorf("directory size limit %d", maxBytes)
+	}
+	f.EnforceBucketQuotas = true
+	if maxBytes, _ := f.quotaLimits(bucket); maxBytes != 1024 {
+		t.Errorf("bucket size limit %d with opt-in", maxBytes)
+	}
+}
+
+// bucketMemoryStore drops the entries of a bucket at once
+type bucketMemoryStore struct {
+	*memoryStore
+}
+
+func (s *bucketMemoryStore) OnBucketCreation(bucket string) {}
+func (s *bucketMemoryStore) OnBucketDeletion(bucket string) {}
+func (s *bucketMemoryStore) CanDropWholeBucket() bool       { return true }
+
+func TestDropBucketQuotaUsage(t *testing.T) {
+	store := newMemoryStore()
+	f := newMemoryFiler(store)
+	f.Store = NewFilerStoreWrapper(&bucketMemoryStore{store})
+	buckets := &DirectoryQuota{Directory: "/buckets", MaxBytes: 1000, countedAtNs: 1}
+	f.quotas.quotas = map[util.FullPath]*DirectoryQuota{buckets.Directory: buckets}
+
+	ctx := context.Background()
+	bucket := &Entry{FullPath: "/buckets/b1", Attr: Attr{Mode: os.ModeDir | 0755}}
+	for _, entry := range []*Entry{
+		{FullPath: "/buckets", Attr: Attr{Mode: os.ModeDir | 0755}},
+		bucket,
+		{FullPath: "/buckets/b1/dir", Attr: Attr{Mode: os.ModeDir | 0755}},
+		quotaTestFile("/buckets/b1/dir/a", 100),
+		quotaTestFile("/buckets/b1/b", 50),
+	} {
+		if err := store.InsertEntry(ctx, entry); err != nil {
+			t.Fatalf("insert %s: %v", entry.FullPath, err)
+		}
+		if entry.FullPath != "/buckets" {
+			f.updateQuotaUsage(nil, entry, 2, true)
+		}
+	}
+	if buckets.UsedBytes != 150 || buckets.UsedInodes != 4 {
+		t.Fatalf("/buckets usage %d bytes %d inodes", buckets.UsedBytes, buckets.UsedInodes)
+	}
+
+	if err := f.doBatchDeleteFolderMetaAndData(ctx, bucket, true, false, false, true, false, nil, nil); err != nil {
+		t.Fatalf("drop bucket: %v", err)
+	}
+	if buckets.UsedBytes != 0 || buckets.UsedInodes != 0 {
+		t.Errorf("/buckets usage %d bytes %d inodes after dropping the bucket", buckets.UsedBytes, buckets.UsedInodes)
+	}
+}
+
+func TestUpdateQuotaUsage(t *testing.T) {
+	data := &DirectoryQuota{Directory: "/data", MaxBytes: 1000, countedAtNs: 10}
+	sub := &DirectoryQuota{Directory: "/data/sub", MaxBytes: 1000, countedAtNs: 10}
+	f := newQuotaTestFiler(data, sub)
+
+	f.updateQuotaUsage(nil, quotaTestFile("/data/sub/a", 100), 11, false)
+	f.updateQuotaUsage(quotaTestFile("/data/sub/a", 100), quotaTestFile("/data/sub/a", 150), 12, false)
+	f.updateQuotaUsage(quotaTestFile("/data/sub/a", 150), quotaTestFile("/data/b", 150), 13, false)
+	// already counted
+	f.updateQuotaUsage(nil, quotaTestFile("/data/c", 100), 9, false)
+	f.updateQuotaUsage(nil, quotaTestFile("/other/d", 100), 14, false)
+
+	if data.UsedBytes != 150 || data.UsedInodes != 1 {
+		t.Errorf("/data usage %d bytes %d inodes", data.UsedBytes, data.UsedInodes)
+	}
+	if sub.UsedBytes != 0 || sub.UsedInodes != 0 {
+		t.Errorf("/data/sub usage %d bytes %d inodes", sub.UsedBytes, sub.UsedInodes)
+	}
+}
@@ -1,6 +1,7 @@
 package filer
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -13,6 +14,23 @@ func (f *Filer) CanRename(source, target util.FullPath, oldName string) error {
 		return fmt.Errorf("mv: can not move directory to a subdirectory of itself")
 	}
 
+	if err := checkSnapshotWrite(context.Background(), sourcePath); err != nil {
+		return err
+	}
+	if err := checkSnapshotWrite(context.Background(), target); err != nil {
+		return err
+	}
+
+	// trash items stay in the collection of where they were deleted
+	if IsInTrash(source) {
+		originalPath, err := f.trashOriginalPath(context.Background(), sourcePath)
+		if err != nil {
+			return err
+		}
+		originalDir, _ := originalPath.DirAndName()
+		source = util.FullPath(originalDir)
+	}
+
 	sourceBucket := f.DetectBucket(source)
 	targetBucket := f.DetectBucket(target)
 	if sourceBucket != targetBucket {
@@ -52,6 +52,14 @@ func (f *Filer) StreamListDirectoryEntries(ctx context.Context, p util.FullPath,
 	}
 	var missedCount int64
 
+	if realPath, isSnapshot := SnapshotRealPath(p); isSnapshot {
+		virtualPath, listFn := p, eachEntryFunc
+		p, eachEntryFunc = realPath, func(entry *Entry) bool {
+			entry.FullPath = virtualPath.Child(entry.Name())
+			return listFn(entry)
+		}
+	}
+
 	missedCount, lastFileName, err = f.doListPatternMatchedEntries(ctx, p, startFileName, inclusive, limit, prefix, restNamePattern, namePatternExclude, eachEntryFunc)
 
 	for missedCount > 0 && err == nil {
@@ -0,0 +1,121 @@
+package filer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+)
+
+// SiteConflictsDir keeps a record of every conflict resolved by multi-site replication
+const SiteConflictsDir = "/etc/replication/conflicts"
+
+// SiteConflict records how concurrent changes of an entry on two sites were resolved
+type SiteConflict struct {
+	Path       string `json:"path"`
+	Winner     string `json:"winner"`               // site clock of the change kept at the path
+	Loser      string `json:"loser"`                // site clock of the other change
+	Resolution string `json:"resolution"`           // what happened to the other change
+	CopyPath   string `json:"copyPath,omitempty"`   // where the other change is kept
+	DetectedNs int64  `json:"detectedNs,omitempty"` // when the conflict was resolved
+}
+
+// SiteClock orders the changes of an entry made on different sites replicating
+// each other, e.g. by "weed filer.sync -multiSite". TsNs is a hybrid logical
+// timestamp: it is at least the physical time, and larger than any timestamp
+// the site has made or received. Changes are ordered by TsNs, then by site, so
+// all sites resolve conflicting changes the same way.
+type SiteClock struct {
+	TsNs int64
+	Site string
+}
+
+func (c SiteClock) String() string {
+	return fmt.Sprintf("%d@%s", c.TsNs, c.Site)
+}
+
+func (c SiteClock) IsZero() bool {
+	return c.TsNs == 0 && c.Site == ""
+}
+
+// Before is true if the change of c is older than the change of other
+func (c SiteClock) Before(other SiteClock) bool {
+	if c.TsNs != other.TsNs {
+		return c.TsNs < other.TsNs
+	}
+	return c.Site < other.Site
+}
+
+// ParseSiteClock parses a clock formatted by SiteClock.String
+func ParseSiteClock(value string) (c SiteClock, err error) {
+	ts, site, found := strings.Cut(value, "@")
+	if !found {
+		return c, fmt.Errorf("invalid site clock %q", value)
+	}
+	if c.TsNs, err = strconv.ParseInt(ts, 10, 64); err != nil {
+		return c, fmt.Errorf("invalid site clock %q: %w", value, err)
+	}
+	c.Site = site
+	return c, nil
+}
+
+// GetSiteClock returns the site clock of an entry. Entries changed before the
+// site had an id fall back to their modification time, without a site.
+func GetSiteClock(extended map[string][]byte, mtime int64) SiteClock {
+	if value, found := extended[s3_constants.ExtSiteClockKey]; found {
+		if c, err := ParseSiteClock(string(value)); err == nil {
+			return c
+		}
+	}
+	return SiteClock{TsNs: mtime * int64(time.Second)}
+}
+
+type hybridLogicalClock struct {
+	mu     sync.Mutex
+	lastNs int64
+}
+
+// tick returns a timestamp larger than all timestamps seen before
+func (h *hybridLogicalClock) tick() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now().UnixNano()
+	if now <= h.lastNs {
+		now = h.lastNs + 1
+	}
+	h.lastNs = now
+	return now
+}
+
+// observe moves the clock past a timestamp received from another site
+func (h *hybridLogicalClock) observe(tsNs int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if tsNs > h.lastNs {
+		h.lastNs = tsNs
+	}
+}
+
+// StampSiteClock sets the site clock of a local change under the buckets folder.
+// Replicated changes keep the clock of the site they were made on.
+func (f *Filer) StampSiteClock(entry *Entry, isFromOtherCluster bool) {
+	if f.SiteId == "" || f.DirBucketsPath == "" || !strings.HasPrefix(string(entry.FullPath), f.DirBucketsPath+"/") {
+		return
+	}
+	if isFromOtherCluster {
+		if value, found := entry.Extended[s3_constants.ExtSiteClockKey]; found {
+			if c, err := ParseSiteClock(string(value)); err == nil {
+				f.siteClock.observe(c.TsNs)
+			}
+		}
+		return
+	}
+	if entry.Extended == nil {
+		entry.Extended = make(map[string][]byte)
+	}
+	c := SiteClock{TsNs: f.siteClock.tick(), Site: f.SiteId}
+	entry.Extended[s3_constants.ExtSiteClockKey] = []byte(c.String())
+}
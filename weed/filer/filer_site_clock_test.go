@@ -0,0 +1,58 @@
+package filer
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestSiteClock(t *testing.T) {
+	c, err := ParseSiteClock(SiteClock{TsNs: 1700000000000000001, Site: "site1"}.String())
+	if err != nil || c.TsNs != 1700000000000000001 || c.Site != "site1" {
+		t.Fatalf("unexpected clock %+v: %v", c, err)
+	}
+	if _, err := ParseSiteClock("123"); err == nil {
+		t.Errorf("expected an error without site")
+	}
+
+	a := SiteClock{TsNs: 5, Site: "b"}
+	if !a.Before(SiteClock{TsNs: 6, Site: "a"}) || !a.Before(SiteClock{TsNs: 5, Site: "c"}) || a.Before(a) {
+		t.Errorf("unexpected order")
+	}
+
+	if c := GetSiteClock(nil, 3); c.TsNs != 3e9 || c.Site != "" {
+		t.Errorf("expected the mtime fallback, got %+v", c)
+	}
+}
+
+func TestStampSiteClock(t *testing.T) {
+	f := &Filer{DirBucketsPath: "/buckets", SiteId: "site1"}
+
+	entry := &Entry{FullPath: util.FullPath("/buckets/b/key")}
+	f.StampSiteClock(entry, false)
+	first := GetSiteClock(entry.Extended, 0)
+	if first.Site != "site1" || first.TsNs == 0 {
+		t.Fatalf("unexpected clock %+v", first)
+	}
+
+	// a replicated change from the future moves the clock forward
+	remote := SiteClock{TsNs: first.TsNs + 1e12, Site: "site2"}
+	replicated := &Entry{FullPath: util.FullPath("/buckets/b/key")}
+	replicated.Extended = map[string][]byte{s3_constants.ExtSiteClockKey: []byte(remote.String())}
+	f.StampSiteClock(replicated, true)
+	if got := GetSiteClock(replicated.Extended, 0); got != remote {
+		t.Errorf("replicated clock changed to %+v", got)
+	}
+
+	f.StampSiteClock(entry, false)
+	if second := GetSiteClock(entry.Extended, 0); !remote.Before(second) {
+		t.Errorf("expected %+v after %+v", second, remote)
+	}
+
+	other := &Entry{FullPath: util.FullPath("/data/key")}
+	f.StampSiteClock(other, false)
+	if other.Extended != nil {
+		t.Errorf("expected no clock outside of buckets")
+	}
+}
@@ -0,0 +1,506 @@
+package filer
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	// SnapshotsDir keeps the snapshots of each directory, in
+	// SnapshotsDir/{escaped directory path}/{snapshot name}
+	SnapshotsDir = "/etc/snapshots"
+	// SnapshotsDirName is the virtual directory to browse the snapshots of a directory,
+	// {directory}/.snapshots/{snapshot name}
+	SnapshotsDirName = ".snapshots"
+
+	SnapshotSourceKey = "Seaweed-X-Snapshot-Source" // the directory of a snapshot
+	SnapshotTimeKey   = "Seaweed-X-Snapshot-Time"   // when a snapshot was taken, in unix nanoseconds
+
+	snapshotRefKeyPrefix = "snapshot.ref."
+)
+
+var ErrSnapshotReadOnly = errors.New("snapshots are read-only")
+
+type snapshotCreationKey struct{}
+
+// SnapshotRoot is where the snapshot of the directory is kept
+func SnapshotRoot(dir util.FullPath, name string) util.FullPath {
+	return snapshotsOf(dir).Child(name)
+}
+
+// snapshotsOf is where the snapshots of the directory are kept
+func snapshotsOf(dir util.FullPath) util.FullPath {
+	return util.FullPath(SnapshotsDir + "/" + url.PathEscape(string(dir)))
+}
+
+// IsInSnapshot is true for entries kept in SnapshotsDir
+func IsInSnapshot(p util.FullPath) bool {
+	return strings.HasPrefix(string(p), SnapshotsDir+"/")
+}
+
+// ParseSnapshotRoot returns the directory and name of a snapshot from its root, as returned by SnapshotRoot
+func ParseSnapshotRoot(root util.FullPath) (dir util.FullPath, name string, ok bool) {
+	rest, found := strings.CutPrefix(string(root), SnapshotsDir+"/")
+	if !found {
+		return "", "", false
+	}
+	escapedDir, name, found := strings.Cut(rest, "/")
+	if !found || name == "" || strings.Contains(name, "/") {
+		return "", "", false
+	}
+	unescaped, err := url.PathUnescape(escapedDir)
+	if err != nil {
+		return "", "", false
+	}
+	return util.FullPath(unescaped), name, true
+}
+
+// snapshotVirtualPath splits {directory}/.snapshots/{rest} into the directory and the rest
+func snapshotVirtualPath(p util.FullPath) (dir util.FullPath, rest string, ok bool) {
+	s := string(p)
+	i := strings.Index(s, "/"+SnapshotsDirName)
+	for i >= 0 {
+		end := i + 1 + len(SnapshotsDirName)
+		if end == len(s) || s[end] == '/' {
+			dir = util.FullPath(s[:i])
+			if dir == "" {
+				dir = "/"
+			}
+			return dir, s[end:], true
+		}
+		next := strings.Index(s[end:], "/"+SnapshotsDirName)
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+	return "", "", false
+}
+
+// SnapshotRealPath maps a path in the virtual {directory}/.snapshots directory to where the snapshots are kept
+func SnapshotRealPath(p util.FullPath) (util.FullPath, bool) {
+	if IsInSnapshot(p) {
+		return "", false
+	}
+	dir, rest, ok := snapshotVirtualPath(p)
+	if !ok {
+		return "", false
+	}
+	return snapshotsOf(dir) + util.FullPath(rest), true
+}
+
+// checkSnapshotWrite fails changes to snapshots, other than creating them
+func checkSnapshotWrite(ctx context.Context, p util.FullPath) error {
+	if _, _, isVirtual := snapshotVirtualPath(p); isVirtual {
+		return ErrSnapshotReadOnly
+	}
+	if IsInSnapshot(p) && ctx.Value(snapshotCreationKey{}) == nil {
+		return ErrSnapshotReadOnly
+	}
+	return nil
+}
+
+// checkSnapshotDelete only allows deleting whole snapshots
+func (f *Filer) checkSnapshotDelete(ctx context.Context, p util.FullPath, isDeleteCollection bool) error {
+	if _, _, isVirtual := snapshotVirtualPath(p); isVirtual {
+		return ErrSnapshotReadOnly
+	}
+	// whole snapshots, or all snapshots of a directory, can be deleted
+	if IsInSnapshot(p) && strings.Count(strings.TrimPrefix(string(p), SnapshotsDir+"/"), "/") > 1 {
+		return ErrSnapshotReadOnly
+	}
+	if isDeleteCollection && f.hasSnapshots.Load() {
+		// the whole collection is dropped, including the chunks of the snapshots
+		hasSnapshots, err := f.hasSnapshotsUnder(ctx, p)
+		if err != nil {
+			return err
+		}
+		if hasSnapshots {
+			return fmt.Errorf("%s has snapshots, which need to be deleted first", p)
+		}
+	}
+	return nil
+}
+
+func (f *Filer) hasSnapshotsUnder(ctx context.Context, p util.FullPath) (found bool, err error) {
+	_, err = f.StreamListDirectoryEntries(ctx, SnapshotsDir, "", false, math.MaxInt32, "", "", "", func(entry *Entry) bool {
+		dir, unescapeErr := url.PathUnescape(entry.Name())
+		if unescapeErr == nil && (util.FullPath(dir) == p || strings.HasPrefix(dir, string(p)+"/")) {
+			found = true
+			return false
+		}
+		return true
+	})
+	if err == filer_pb.ErrNotFound {
+		return false, nil
+	}
+	return
+}
+
+// LoadSnapshots detects existing snapshots, whose chunks are protected from deletion
+func (f *Filer) LoadSnapshots() {
+	entries, _, err := f.ListDirectoryEntries(context.Background(), SnapshotsDir, "", false, 1, "", "", "")
+	if err != nil && err != filer_pb.ErrNotFound {
+		// protect the chunks, in case there are snapshots
+		glog.Errorf("list %s: %v", SnapshotsDir, err)
+		f.hasSnapshots.Store(true)
+		return
+	}
+	if len(entries) > 0 {
+		f.hasSnapshots.Store(true)
+	}
+}
+
+func (f *Filer) onSnapshotEvents(event *filer_pb.SubscribeMetadataResponse) {
+	dir := util.FullPath(event.Directory)
+	if (dir == SnapshotsDir || IsInSnapshot(dir)) && filer_pb.IsCreate(event) {
+		f.hasSnapshots.Store(true)
+	}
+}
+
+// CreateSnapshot copies the metadata of the directory tree into a read-only snapshot.
+// The snapshot shares the chunks of the directory tree, which are not deleted while a snapshot references them.
+func (f *Filer) CreateSnapshot(ctx context.Context, dir util.FullPath, name string) (snapshotTime time.Time, entryCount int64, err error) {
+	if name == "" || name == "." || name == ".." || name == SnapshotsDirName || strings.Contains(name, "/") {
+		return snapshotTime, 0, fmt.Errorf("invalid snapshot name %q", name)
+	}
+	if IsInSnapshot(dir) || IsInTrash(dir) {
+		return snapshotTime, 0, fmt.Errorf("can not snapshot %s", dir)
+	}
+	if _, _, isVirtual := snapshotVirtualPath(dir); isVirtual {
+		return snapshotTime, 0, fmt.Errorf("can not snapshot %s", dir)
+	}
+	entry, err := f.FindEntry(ctx, dir)
+	if err != nil {
+		return snapshotTime, 0, fmt.Errorf("find %s: %w", dir, err)
+	}
+	if !entry.IsDirectory() {
+		return snapshotTime, 0, fmt.Errorf("%s is not a directory", dir)
+	}
+	if err := f.CanShareChunks(); err != nil {
+		return snapshotTime, 0, fmt.Errorf("can not snapshot %s: %w", dir, err)
+	}
+	root := SnapshotRoot(dir, name)
+	if _, err := f.FindEntry(ctx, root); err == nil {
+		return snapshotTime, 0, fmt.Errorf("snapshot %s of %s already exists", name, dir)
+	} else if err != filer_pb.ErrNotFound {
+		return snapshotTime, 0, fmt.Errorf("find %s: %w", root, err)
+	}
+
+	// protect the chunks from now on
+	f.hasSnapshots.Store(true)
+	snapshotTime = time.Now()
+	extended := make(map[string][]byte, len(entry.Extended)+2)
+	for k, v := range entry.Extended {
+		extended[k] = v
+	}
+	extended[SnapshotSourceKey] = []byte(dir)
+	extended[SnapshotTimeKey] = []byte(strconv.FormatInt(snapshotTime.UnixNano(), 10))
+
+	ctx = context.WithValue(ctx, snapshotCreationKey{}, true)
+	c := &snapshotCopy{f: f, refCtx: ctx}
+	txCtx, err := f.BeginTransaction(ctx)
+	if err != nil {
+		return snapshotTime, 0, err
+	}
+	if err = c.copy(txCtx, entry, root, extended); err == nil {
+		err = f.CommitTransaction(txCtx)
+	}
+	if err != nil {
+		f.RollbackTransaction(txCtx)
+		c.undo(root)
+		return snapshotTime, 0, fmt.Errorf("snapshot %s: %w", dir, err)
+	}
+	glog.V(0).InfofCtx(ctx, "snapshot %s of %s: %d entries", name, dir, c.entryCount)
+	return snapshotTime, c.entryCount, nil
+}
+
+// snapshotCopy copies a directory tree into a snapshot. The chunk references
+// are counted outside of the transaction of the copy, so that deletions see
+// them right away, and are released again if the copy fails.
+type snapshotCopy struct {
+	f          *Filer
+	refCtx     context.Context
+	entryCount int64
+	created    []string // the chunks referenced by the copied entries
+	pending    []string // the chunks referenced by the entry being copied
+}
+
+// copy copies the entry, and the entries below it. The extended attributes of the
+// root of the snapshot are passed, the other entries keep their own.
+func (c *snapshotCopy) copy(ctx context.Context, entry *Entry, target util.FullPath, extended map[string][]byte) error {
+	if entry.FullPath == SnapshotsDir {
+		return nil
+	}
+	entry, err := c.reference(ctx, entry)
+	if err != nil || entry == nil {
+		return err
+	}
+	if extended == nil {
+		extended = entry.Extended
+	}
+	attr := entry.Attr
+	attr.TtlSec = 0
+	// hard links are copied as regular files, which do not change with the other links
+	copied := &Entry{
+		FullPath: target,
+		Attr:     attr,
+		Chunks:   entry.GetChunks(),
+		Extended: extended,
+		Content:  entry.Content,
+		Remote:   entry.Remote,
+	}
+	if err := c.f.CreateEntry(ctx, copied, true, false, nil, false, 0); err != nil {
+		return err
+	}
+	c.created = append(c.created, c.pending...)
+	c.pending = nil
+	c.entryCount++
+
+	if !entry.IsDirectory() {
+		return nil
+	}
+	lastFileName := ""
+	for {
+		entries, hasMore, err := c.f.ListDirectoryEntries(ctx, entry.FullPath, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			return fmt.Errorf("list folder %s: %v", entry.FullPath, err)
+		}
+		for _, sub := range entries {
+			lastFileName = sub.Name()
+			if err := c.copy(ctx, sub, target.Child(sub.Name()), nil); err != nil {
+				return err
+			}
+		}
+		if !hasMore {
+			return nil
+		}
+	}
+}
+
+// reference counts the snapshot references of the chunks of a listed entry, and
+// reads the entry again. If the chunks changed since the listing, they could
+// have been deleted before they were referenced, so the references are
+// released, and the current chunks are referenced. It returns nil if the entry
+// is deleted meanwhile.
+func (c *snapshotCopy) reference(ctx context.Context, entry *Entry) (*Entry, error) {
+	for len(entry.GetChunks()) > 0 {
+		fileIds, err := c.f.addSnapshotRefs(c.refCtx, entry.GetChunks())
+		c.pending = append(c.pending, fileIds...)
+		if err != nil {
+			return nil, err
+		}
+		current, err := c.f.Store.FindEntry(ctx, entry.FullPath)
+		if err != nil && err != filer_pb.ErrNotFound {
+			return nil, err
+		}
+		if current != nil && sameChunks(entry.GetChunks(), current.GetChunks()) {
+			return current, nil
+		}
+		c.f.releaseSnapshotRefs(c.refCtx, c.pending)
+		c.pending = nil
+		if current == nil {
+			return nil, nil
+		}
+		entry = current
+	}
+	return entry, nil
+}
+
+// undo releases the chunk references of a failed snapshot. Stores without
+// transactions keep the copied entries, which are deleted with their references.
+func (c *snapshotCopy) undo(root util.FullPath) {
+	c.f.releaseSnapshotRefs(c.refCtx, c.pending)
+	if _, err := c.f.Store.FindEntry(c.refCtx, root); err == filer_pb.ErrNotFound {
+		c.f.releaseSnapshotRefs(c.refCtx, c.created)
+		return
+	}
+	if err := c.f.DeleteEntryMetaAndData(c.refCtx, root, true, true, true, false, nil, 0); err != nil {
+		glog.ErrorfCtx(c.refCtx, "delete failed snapshot %s: %v", root, err)
+	}
+}
+
+// snapshotRef counts the snapshots referencing a chunk. An orphaned chunk is
+// no longer used outside of snapshots, and is deleted with the last snapshot.
+type snapshotRef struct {
+	count    uint32
+	orphaned bool
+}
+
+func snapshotRefKey(fileId string) []byte {
+	return []byte(snapshotRefKeyPrefix + fileId)
+}
+
+func (f *Filer) readSnapshotRef(ctx context.Context, fileId string) (ref snapshotRef, err error) {
+	value, err := f.Store.KvGet(ctx, snapshotRefKey(fileId))
+	if err == ErrKvNotFound {
+		return ref, nil
+	}
+	if err != nil {
+		return ref, err
+	}
+	if len(value) != 5 {
+		return ref, fmt.Errorf("invalid snapshot reference of %s", fileId)
+	}
+	ref.count = binary.BigEndian.Uint32(value)
+	ref.orphaned = value[4] == 1
+	return ref, nil
+}
+
+func (f *Filer) writeSnapshotRef(ctx context.Context, fileId string, ref snapshotRef) error {
+	if ref.count == 0 {
+		return f.Store.KvDelete(ctx, snapshotRefKey(fileId))
+	}
+	value := make([]byte, 5)
+	binary.BigEndian.PutUint32(value, ref.count)
+	if ref.orphaned {
+		value[4] = 1
+	}
+	return f.Store.KvPut(ctx, snapshotRefKey(fileId), value)
+}
+
+// addSnapshotRefs references the chunks, and the chunks of their manifests, from a new snapshot.
+// It returns the file ids referenced, also when it fails part way.
+func (f *Filer) addSnapshotRefs(ctx context.Context, chunks []*filer_pb.FileChunk) (referenced []string, err error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	fileIds := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		fileIds = append(fileIds, chunk.GetFileIdString())
+		if !chunk.IsChunkManifest {
+			continue
+		}
+		dataChunks, manifestChunks, err := ResolveChunkManifest(ctx, f.MasterClient.GetLookupFileIdFunction(), []*filer_pb.FileChunk{chunk}, 0, math.MaxInt64)
+		if err != nil {
+			return nil, fmt.Errorf("resolve manifest %s: %w", chunk.GetFileIdString(), err)
+		}
+		for _, c := range append(dataChunks, manifestChunks...) {
+			if c.GetFileIdString() != chunk.GetFileIdString() {
+				fileIds = append(fileIds, c.GetFileIdString())
+			}
+		}
+	}
+
+	for _, fileId := range fileIds {
+		if err := f.addSnapshotRef(ctx, fileId); err != nil {
+			return referenced, err
+		}
+		referenced = append(referenced, fileId)
+	}
+	return referenced, nil
+}
+
+func (f *Filer) addSnapshotRef(ctx context.Context, fileId string) error {
+	unlock := f.lockChunkRef(fileId)
+	defer unlock()
+	ref, err := f.readSnapshotRef(ctx, fileId)
+	if err != nil {
+		return err
+	}
+	ref.count++
+	return f.writeSnapshotRef(ctx, fileId, ref)
+}
+
+// releaseSnapshotRef is called before deleting a chunk, from a snapshot or not.
+// It returns true if no snapshot, or other entries, need the chunk any more.
+func (f *Filer) releaseSnapshotRef(ctx context.Context, fileId string, fromSnapshot bool) bool {
+	unlock := f.lockChunkRef(fileId)
+	defer unlock()
+	ref, err := f.readSnapshotRef(ctx, fileId)
+	if err != nil {
+		// keeping a chunk is safer than losing the data of a snapshot
+		glog.ErrorfCtx(ctx, "read snapshot reference of %s: %v", fileId, err)
+		return false
+	}
+	if ref.count == 0 {
+		return true
+	}
+	if fromSnapshot {
+		ref.count--
+	} else {
+		ref.orphaned = true
+	}
+	if err := f.writeSnapshotRef(ctx, fileId, ref); err != nil {
+		glog.ErrorfCtx(ctx, "write snapshot reference of %s: %v", fileId, err)
+		return false
+	}
+	return ref.count == 0 && ref.orphaned
+}
+
+// releaseSnapshotRefs releases the references of a snapshot that is not created.
+// The chunks deleted from their files meanwhile are deleted.
+func (f *Filer) releaseSnapshotRefs(ctx context.Context, fileIds []string) {
+	for _, fileId := range fileIds {
+		if f.releaseSnapshotRef(ctx, fileId, true) {
+			f.fileIdDeletionQueue.EnQueue(fileId)
+		}
+	}
+}
+
+// Snapshot is a read-only copy of a directory tree
+type Snapshot struct {
+	Directory util.FullPath
+	Name      string
+	CreatedAt time.Time
+}
+
+// BrowsePath is where the snapshot can be browsed, on mount, S3 and the filer http api
+func (s *Snapshot) BrowsePath() util.FullPath {
+	return s.Directory.Child(SnapshotsDirName).Child(s.Name)
+}
+
+// ListSnapshots lists the snapshots of all directories, or only the snapshots of the directory if not empty
+func ListSnapshots(ctx context.Context, client filer_pb.SeaweedFilerClient, dir util.FullPath, fn func(snapshot *Snapshot) error) error {
+	var snapshotDirs []util.FullPath
+	if dir != "" {
+		snapshotDirs = append(snapshotDirs, dir)
+	} else {
+		err := filer_pb.SeaweedList(ctx, client, SnapshotsDir, "", func(entry *filer_pb.Entry, isLast bool) error {
+			if unescaped, err := url.PathUnescape(entry.Name); err == nil && entry.IsDirectory {
+				snapshotDirs = append(snapshotDirs, util.FullPath(unescaped))
+			}
+			return nil
+		}, "", false, math.MaxUint32)
+		if err == filer_pb.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, snapshotDir := range snapshotDirs {
+		err := filer_pb.SeaweedList(ctx, client, string(snapshotsOf(snapshotDir)), "", func(entry *filer_pb.Entry, isLast bool) error {
+			createdAt, _ := strconv.ParseInt(string(entry.Extended[SnapshotTimeKey]), 10, 64)
+			return fn(&Snapshot{
+				Directory: snapshotDir,
+				Name:      entry.Name,
+				CreatedAt: time.Unix(0, createdAt),
+			})
+		}, "", false, math.MaxUint32)
+		if err != nil && err != filer_pb.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSnapshot deletes a snapshot, and the chunks no longer used by the directory or other snapshots
+func DeleteSnapshot(ctx context.Context, client filer_pb.SeaweedFilerClient, dir util.FullPath, name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return filer_pb.DoRemove(ctx, client, string(snapshotsOf(dir)), name, true, true, true, false, nil)
+}
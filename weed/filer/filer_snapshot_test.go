@@ -0,0 +1,167 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestSnapshotRealPath(t *testing.T) {
+	for p, expected := range map[string]string{
+		"/buckets/data/.snapshots":            "/etc/snapshots/%2Fbuckets%2Fdata",
+		"/buckets/data/.snapshots/s1":         "/etc/snapshots/%2Fbuckets%2Fdata/s1",
+		"/buckets/data/.snapshots/s1/a/b.txt": "/etc/snapshots/%2Fbuckets%2Fdata/s1/a/b.txt",
+		"/.snapshots/s1":                      "/etc/snapshots/%2F/s1",
+		"/buckets/data/.snapshotsx/s1":        "",
+		"/buckets/data/a.snapshots/s1":        "",
+		"/buckets/data":                       "",
+		"/etc/snapshots/%2Fbuckets%2Fdata/s1": "",
+	} {
+		realPath, ok := SnapshotRealPath(util.FullPath(p))
+		if ok != (expected != "") || string(realPath) != expected {
+			t.Errorf("SnapshotRealPath(%s) = %s, %v, expected %s", p, realPath, ok, expected)
+		}
+	}
+}
+
+func TestParseSnapshotRoot(t *testing.T) {
+	root := SnapshotRoot("/buckets/data", "s1")
+	if root != "/etc/snapshots/%2Fbuckets%2Fdata/s1" {
+		t.Fatalf("unexpected snapshot root %s", root)
+	}
+	dir, name, ok := ParseSnapshotRoot(root)
+	if !ok || dir != "/buckets/data" || name != "s1" {
+		t.Errorf("ParseSnapshotRoot(%s) = %s, %s, %v", root, dir, name, ok)
+	}
+	for _, p := range []string{"/etc/snapshots/%2Fbuckets%2Fdata", "/etc/snapshots/%2Fbuckets%2Fdata/s1/a.txt", "/buckets/data/s1"} {
+		if _, _, ok := ParseSnapshotRoot(util.FullPath(p)); ok {
+			t.Errorf("%s should not be a snapshot root", p)
+		}
+	}
+}
+
+func TestCheckSnapshotWrite(t *testing.T) {
+	ctx := context.Background()
+	creationCtx := context.WithValue(ctx, snapshotCreationKey{}, true)
+	for _, tc := range []struct {
+		ctx      context.Context
+		p        string
+		readOnly bool
+	}{
+		{ctx, "/buckets/data/a.txt", false},
+		{ctx, "/buckets/data/.snapshots/s1/a.txt", true},
+		{ctx, "/buckets/data/.snapshots", true},
+		{creationCtx, "/buckets/data/.snapshots/s1/a.txt", true},
+		{ctx, "/etc/snapshots/%2Fbuckets%2Fdata/s1/a.txt", true},
+		{creationCtx, "/etc/snapshots/%2Fbuckets%2Fdata/s1/a.txt", false},
+	} {
+		err := checkSnapshotWrite(tc.ctx, util.FullPath(tc.p))
+		if (err == ErrSnapshotReadOnly) != tc.readOnly {
+			t.Errorf("checkSnapshotWrite(%s): %v", tc.p, err)
+		}
+	}
+}
+
+// snapshotRaceStore changes the entries while a snapshot is copied
+type snapshotRaceStore struct {
+	*memoryStore
+	afterList  func()
+	failInsert util.FullPath
+}
+
+func (s *snapshotRaceStore) ListDirectoryPrefixedEntries(ctx context.Context, dir util.FullPath, startFileName string, includeStartFile bool, limit int64, prefix string, eachEntryFunc ListEachEntryFunc) (string, error) {
+	lastFileName, err := s.memoryStore.ListDirectoryPrefixedEntries(ctx, dir, startFileName, includeStartFile, limit, prefix, eachEntryFunc)
+	if s.afterList != nil {
+		afterList := s.afterList
+		s.afterList = nil
+		afterList()
+	}
+	return lastFileName, err
+}
+
+func (s *snapshotRaceStore) ListDirectoryEntries(ctx context.Context, dir util.FullPath, startFileName string, includeStartFile bool, limit int64, eachEntryFunc ListEachEntryFunc) (string, error) {
+	return s.ListDirectoryPrefixedEntries(ctx, dir, startFileName, includeStartFile, limit, "", eachEntryFunc)
+}
+
+func (s *snapshotRaceStore) InsertEntry(ctx context.Context, entry *Entry) error {
+	if entry.FullPath == s.failInsert {
+		return errors.New("insert failed")
+	}
+	return s.memoryStore.InsertEntry(ctx, entry)
+}
+
+func newSnapshotTestFiler(t *testing.T) (*Filer, *snapshotRaceStore) {
+	store := &snapshotRaceStore{memoryStore: newMemoryStore()}
+	f := newMemoryFiler(store.memoryStore)
+	f.Store = NewFilerStoreWrapper(store)
+	ctx := context.Background()
+	for _, entry := range []*Entry{
+		{FullPath: "/data", Attr: Attr{Mode: os.ModeDir | 0755}},
+		{FullPath: "/data/a", Chunks: []*filer_pb.FileChunk{{FileId: "1,a", Size: 1}}},
+		{FullPath: "/data/b", Chunks: []*filer_pb.FileChunk{{FileId: "1,b", Size: 1}}},
+	} {
+		if err := store.memoryStore.InsertEntry(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return f, store
+}
+
+func snapshotRefCount(t *testing.T, f *Filer, fileId string) uint32 {
+	ref, err := f.readSnapshotRef(context.Background(), fileId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref.count
+}
+
+func TestCreateSnapshotWithConcurrentOverwrite(t *testing.T) {
+	f, store := newSnapshotTestFiler(t)
+	ctx := context.Background()
+	// /data/a is overwritten after it is listed, and before its chunk is referenced
+	store.afterList = func() {
+		store.memoryStore.InsertEntry(ctx, &Entry{FullPath: "/data/a", Chunks: []*filer_pb.FileChunk{{FileId: "2,a", Size: 1}}})
+		f.DeleteChunksNotRecursive([]*filer_pb.FileChunk{{FileId: "1,a"}})
+	}
+
+	if _, count, err := f.CreateSnapshot(ctx, "/data", "s1"); err != nil || count != 3 {
+		t.Fatalf("create snapshot: %d entries, %v", count, err)
+	}
+	copied, err := f.Store.FindEntry(ctx, SnapshotRoot("/data", "s1").Child("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(copied.GetChunks()) != 1 || copied.GetChunks()[0].GetFileIdString() != "2,a" {
+		t.Errorf("the snapshot should copy the current chunks, got %v", copied.GetChunks())
+	}
+	for fileId, want := range map[string]uint32{"1,a": 0, "2,a": 1, "1,b": 1} {
+		if got := snapshotRefCount(t, f, fileId); got != want {
+			t.Errorf("references of %s: %d, want %d", fileId, got, want)
+		}
+	}
+}
+
+func TestCreateSnapshotReleasesReferencesOnFailure(t *testing.T) {
+	f, store := newSnapshotTestFiler(t)
+	ctx := context.Background()
+	store.failInsert = SnapshotRoot("/data", "s1").Child("b")
+
+	if _, _, err := f.CreateSnapshot(ctx, "/data", "s1"); err == nil {
+		t.Fatalf("the snapshot should fail")
+	}
+	for _, fileId := range []string{"1,a", "1,b"} {
+		if got := snapshotRefCount(t, f, fileId); got != 0 {
+			t.Errorf("references of %s: %d, want 0", fileId, got)
+		}
+	}
+	if _, err := f.Store.FindEntry(ctx, SnapshotRoot("/data", "s1")); err != filer_pb.ErrNotFound {
+		t.Errorf("the failed snapshot should be deleted, got %v", err)
+	}
+	f.fileIdDeletionQueue.Consume(func(fileIds []string) {
+		t.Errorf("the chunks of the files should be kept, deleted %v", fileIds)
+	})
+}
@@ -0,0 +1,285 @@
+package filer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	// TrashDir keeps the entries deleted under a path configured with a trash retention.
+	// Each deleted file or directory tree is kept as one item directly under TrashDir.
+	TrashDir = "/etc/trash"
+
+	TrashPathKey    = "Seaweed-X-Trash-Path"    // original path of a trash item
+	TrashDeletedKey = "Seaweed-X-Trash-Deleted" // deletion time of a trash item, in unix seconds
+	TrashExpiresKey = "Seaweed-X-Trash-Expires" // when a trash item is purged, in unix seconds
+
+	trashPurgeInterval = 10 * time.Minute
+)
+
+// TrashItem is a file or a directory tree in the trash
+type TrashItem struct {
+	Id          string // name of the item in TrashDir
+	Path        util.FullPath
+	DeletedAt   time.Time
+	ExpiresAt   time.Time
+	IsDirectory bool
+	FileSize    uint64
+}
+
+// IsInTrash is true for TrashDir and the entries in it
+func IsInTrash(p util.FullPath) bool {
+	return p == TrashDir || strings.HasPrefix(string(p), TrashDir+"/")
+}
+
+// ParseTrashItem reads a trash item from an entry listed in TrashDir
+func ParseTrashItem(entry *filer_pb.Entry) (*TrashItem, error) {
+	originalPath := entry.Extended[TrashPathKey]
+	if len(originalPath) == 0 {
+		return nil, fmt.Errorf("%s is not a trash item", entry.Name)
+	}
+	deleted, err := strconv.ParseInt(string(entry.Extended[TrashDeletedKey]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("trash item %s deletion time: %w", entry.Name, err)
+	}
+	expires, err := strconv.ParseInt(string(entry.Extended[TrashExpiresKey]), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("trash item %s expiration time: %w", entry.Name, err)
+	}
+	return &TrashItem{
+		Id:          entry.Name,
+		Path:        util.FullPath(originalPath),
+		DeletedAt:   time.Unix(deleted, 0),
+		ExpiresAt:   time.Unix(expires, 0),
+		IsDirectory: entry.IsDirectory,
+		FileSize:    FileSize(entry),
+	}, nil
+}
+
+// trashOriginalPath is where an entry in the trash was deleted from,
+// found by the original path of the trash item it is in
+func (f *Filer) trashOriginalPath(ctx context.Context, p util.FullPath) (util.FullPath, error) {
+	if !strings.HasPrefix(string(p), TrashDir+"/") {
+		return "", fmt.Errorf("%s is not in a trash item", p)
+	}
+	itemName, subPath, _ := strings.Cut(string(p)[len(TrashDir)+1:], "/")
+	item, err := f.FindEntry(ctx, util.NewFullPath(TrashDir, itemName))
+	if err != nil {
+		return "", fmt.Errorf("find trash item %s: %w", itemName, err)
+	}
+	originalPath := item.Extended[TrashPathKey]
+	if len(originalPath) == 0 {
+		return "", fmt.Errorf("%s is not a trash item", itemName)
+	}
+	if subPath == "" {
+		return util.FullPath(originalPath), nil
+	}
+	return util.FullPath(originalPath).Child(subPath), nil
+}
+
+// trashRetention is how long entries deleted at the path are kept in the trash, 0 to delete them immediately.
+// Snapshots are not moved to the trash, since their chunks are released when deleted.
+func (f *Filer) trashRetention(p util.FullPath) time.Duration {
+	if IsInTrash(p) || IsInSnapshot(p) {
+		return 0
+	}
+	rule := f.FilerConf.MatchStorageRule(string(p))
+	return time.Duration(rule.TrashRetentionSeconds) * time.Second
+}
+
+// moveToTrash moves the entry, and all entries under a directory, into the trash.
+// The chunks are kept until the item expires, or is purged. Object lock is checked by the caller.
+func (f *Filer) moveToTrash(ctx context.Context, entry *Entry, retention time.Duration, isFromOtherCluster bool, signatures []int32) (err error) {
+	now := time.Now()
+	itemPath := util.NewFullPath(TrashDir, fmt.Sprintf("%d-%s", now.UnixNano(), entry.Name()))
+	extended := make(map[string][]byte, len(entry.Extended)+3)
+	for k, v := range entry.Extended {
+		extended[k] = v
+	}
+	extended[TrashPathKey] = []byte(entry.FullPath)
+	extended[TrashDeletedKey] = []byte(strconv.FormatInt(now.Unix(), 10))
+	extended[TrashExpiresKey] = []byte(strconv.FormatInt(now.Add(retention).Unix(), 10))
+
+	ctx, err = f.BeginTransaction(ctx)
+	if err != nil {
+		return err
+	}
+	if err = f.moveEntryTree(ctx, entry, itemPath, extended, isFromOtherCluster, signatures); err != nil {
+		f.RollbackTransaction(ctx)
+		return fmt.Errorf("move %s to trash: %w", entry.FullPath, err)
+	}
+	if err = f.CommitTransaction(ctx); err != nil {
+		f.RollbackTransaction(ctx)
+		return fmt.Errorf("move %s to trash: %w", entry.FullPath, err)
+	}
+	glog.V(2).InfofCtx(ctx, "moved %s to trash %s", entry.FullPath, itemPath)
+	return nil
+}
+
+func (f *Filer) moveEntryTree(ctx context.Context, entry *Entry, newPath util.FullPath, extended map[string][]byte, isFromOtherCluster bool, signatures []int32) error {
+	newEntry := &Entry{
+		FullPath:        newPath,
+		Attr:            entry.Attr,
+		Chunks:          entry.GetChunks(),
+		Extended:        extended,
+		Content:         entry.Content,
+		HardLinkCounter: entry.HardLinkCounter,
+		HardLinkId:      entry.HardLinkId,
+		Remote:          entry.Remote,
+		Quota:           entry.Quota,
+	}
+	if err := f.CreateEntry(ctx, newEntry, true, isFromOtherCluster, signatures, false, 0); err != nil {
+		return err
+	}
+
+	if entry.IsDirectory() {
+		lastFileName := ""
+		for {
+			entries, hasMore, err := f.ListDirectoryEntries(ctx, entry.FullPath, lastFileName, false, PaginationSize, "", "", "")
+			if err != nil {
+				return fmt.Errorf("list folder %s: %v", entry.FullPath, err)
+			}
+			for _, sub := range entries {
+				lastFileName = sub.Name()
+				if err := f.moveEntryTree(ctx, sub, newPath.Child(sub.Name()), sub.Extended, isFromOtherCluster, signatures); err != nil {
+					return err
+				}
+			}
+			if !hasMore {
+				break
+			}
+		}
+	}
+
+	// the hard link, if any, is kept by the new entry
+	if err := f.Store.DeleteOneEntry(context.WithValue(ctx, "OP", "MV"), entry); err != nil {
+		return fmt.Errorf("filer store delete: %w", err)
+	}
+	f.NotifyUpdateEvent(ctx, entry, nil, false, isFromOtherCluster, signatures)
+	return nil
+}
+
+func (f *Filer) loopPurgingTrash() {
+	for {
+		time.Sleep(trashPurgeInterval)
+		if err := f.purgeExpiredTrash(context.Background(), time.Now()); err != nil {
+			glog.Errorf("purge trash: %v", err)
+		}
+	}
+}
+
+// purgeExpiredTrash deletes the trash items expired before now, with their chunks
+func (f *Filer) purgeExpiredTrash(ctx context.Context, now time.Time) error {
+	var expired []util.FullPath
+	lastFileName := ""
+	for {
+		entries, hasMore, err := f.ListDirectoryEntries(ctx, TrashDir, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			if err == filer_pb.ErrNotFound {
+				return nil
+			}
+			return fmt.Errorf("list %s: %w", TrashDir, err)
+		}
+		for _, entry := range entries {
+			lastFileName = entry.Name()
+			expires, err := strconv.ParseInt(string(entry.Extended[TrashExpiresKey]), 10, 64)
+			if err != nil {
+				glog.Warningf("skip trash item %s: no expiration time", entry.FullPath)
+				continue
+			}
+			if expires <= now.Unix() {
+				expired = append(expired, entry.FullPath)
+			}
+		}
+		if !hasMore {
+			break
+		}
+	}
+
+	for _, p := range expired {
+		if err := f.DeleteEntryMetaAndData(ctx, p, true, true, true, false, nil, 0); err != nil && err != filer_pb.ErrNotFound {
+			glog.Errorf("purge trash item %s: %v", p, err)
+			continue
+		}
+		glog.V(1).Infof("purged trash item %s", p)
+	}
+	return nil
+}
+
+// ListTrash lists the trash items, in the order they were deleted
+func ListTrash(ctx context.Context, client filer_pb.SeaweedFilerClient, fn func(item *TrashItem) error) error {
+	err := filer_pb.SeaweedList(ctx, client, TrashDir, "", func(entry *filer_pb.Entry, isLast bool) error {
+		item, err := ParseTrashItem(entry)
+		if err != nil {
+			glog.V(1).Infof("skip %s/%s: %v", TrashDir, entry.Name, err)
+			return nil
+		}
+		return fn(item)
+	}, "", false, math.MaxUint32)
+	if err == filer_pb.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// RestoreTrash moves a trash item back to where it was deleted, or to the target path if not empty
+func RestoreTrash(ctx context.Context, client filer_pb.SeaweedFilerClient, id string, target util.FullPath) (util.FullPath, error) {
+	if id == "" || strings.Contains(id, "/") {
+		return "", fmt.Errorf("invalid trash item %q", id)
+	}
+	resp, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: TrashDir, Name: id})
+	if err != nil {
+		return "", fmt.Errorf("find trash item %s: %w", id, err)
+	}
+	item, err := ParseTrashItem(resp.Entry)
+	if err != nil {
+		return "", err
+	}
+	if target == "" {
+		target = item.Path
+	}
+	dir, name := target.DirAndName()
+	if _, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: dir, Name: name}); err == nil {
+		return "", fmt.Errorf("%s already exists", target)
+	} else if err != filer_pb.ErrNotFound {
+		return "", fmt.Errorf("find %s: %w", target, err)
+	}
+
+	if _, err := client.AtomicRenameEntry(ctx, &filer_pb.AtomicRenameEntryRequest{
+		OldDirectory: TrashDir,
+		OldName:      id,
+		NewDirectory: dir,
+		NewName:      name,
+	}); err != nil {
+		return "", fmt.Errorf("restore %s to %s: %w", id, target, err)
+	}
+
+	// the restored entry is no longer a trash item
+	restored, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: dir, Name: name})
+	if err != nil {
+		return target, fmt.Errorf("find restored %s: %w", target, err)
+	}
+	for _, key := range []string{TrashPathKey, TrashDeletedKey, TrashExpiresKey} {
+		delete(restored.Entry.Extended, key)
+	}
+	if err := filer_pb.UpdateEntry(ctx, client, &filer_pb.UpdateEntryRequest{Directory: dir, Entry: restored.Entry}); err != nil {
+		return target, fmt.Errorf("update restored %s: %w", target, err)
+	}
+	return target, nil
+}
+
+// PurgeTrash deletes a trash item and its data
+func PurgeTrash(ctx context.Context, client filer_pb.SeaweedFilerClient, id string) error {
+	if id == "" || strings.Contains(id, "/") {
+		return fmt.Errorf("invalid trash item %q", id)
+	}
+	return filer_pb.DoRemove(ctx, client, TrashDir, id, true, true, true, false, nil)
+}
@@ -0,0 +1,94 @@
+package filer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestIsInTrash(t *testing.T) {
+	for p, expected := range map[string]bool{
+		"/etc/trash":           true,
+		"/etc/trash/1-a.txt":   true,
+		"/etc/trash/1-dir/b/c": true,
+		"/etc/trashcan":        false,
+		"/etc/seaweedfs":       false,
+		"/buckets/b/etc/trash": false,
+	} {
+		if IsInTrash(util.FullPath(p)) != expected {
+			t.Errorf("IsInTrash(%s) should be %v", p, expected)
+		}
+	}
+}
+
+func TestTrashRetention(t *testing.T) {
+	f := &Filer{FilerConf: NewFilerConf()}
+	f.FilerConf.AddLocationConf(&filer_pb.FilerConf_PathConf{LocationPrefix: "/", TrashRetentionSeconds: 3600})
+	f.FilerConf.AddLocationConf(&filer_pb.FilerConf_PathConf{LocationPrefix: "/data/", TrashRetentionSeconds: 7200})
+
+	if retention := f.trashRetention("/data/a.txt"); retention != 2*time.Hour {
+		t.Errorf("retention of /data/a.txt is %v", retention)
+	}
+	if retention := f.trashRetention("/home/a.txt"); retention != time.Hour {
+		t.Errorf("retention of /home/a.txt is %v", retention)
+	}
+	if retention := f.trashRetention("/etc/trash/1-a.txt"); retention != 0 {
+		t.Errorf("trash items should be deleted, not moved to trash again, retention %v", retention)
+	}
+}
+
+func TestParseTrashItem(t *testing.T) {
+	item, err := ParseTrashItem(&filer_pb.Entry{
+		Name: "1760680000000000000-a.txt",
+		Attributes: &filer_pb.FuseAttributes{
+			FileSize: 42,
+		},
+		Extended: map[string][]byte{
+			TrashPathKey:    []byte("/data/a.txt"),
+			TrashDeletedKey: []byte("1760680000"),
+			TrashExpiresKey: []byte("1760683600"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse trash item: %v", err)
+	}
+	if item.Path != "/data/a.txt" || item.FileSize != 42 || item.ExpiresAt.Sub(item.DeletedAt) != time.Hour {
+		t.Errorf("unexpected trash item %+v", item)
+	}
+
+	if _, err := ParseTrashItem(&filer_pb.Entry{Name: "a.txt"}); err == nil {
+		t.Errorf("an entry without the original path is not a trash item")
+	}
+}
+
+func TestCanRenameFromTrash(t *testing.T) {
+	store := newMemoryStore()
+	f := newMemoryFiler(store)
+	item := util.NewFullPath(TrashDir, "1760680000000000000-dir")
+	store.entries[item] = &Entry{
+		FullPath: item,
+		Attr:     Attr{Mode: 0755 | 1<<31},
+		Extended: map[string][]byte{TrashPathKey: []byte("/buckets/a/dir")},
+	}
+	store.entries[item.Child("obj")] = &Entry{FullPath: item.Child("obj"), Attr: Attr{Mode: 0644}}
+
+	for _, tc := range []struct {
+		source  util.FullPath
+		name    string
+		target  util.FullPath
+		allowed bool
+	}{
+		{TrashDir, item.Name(), "/buckets/a", true},
+		{TrashDir, item.Name(), "/buckets/b", false},
+		{TrashDir, item.Name(), "/data", false},
+		{item, "obj", "/buckets/a/other", true},
+		{item, "obj", "/buckets/b", false},
+		{TrashDir, "missing", "/buckets/a", false},
+	} {
+		if err := f.CanRename(tc.source, tc.target, tc.name); (err == nil) != tc.allowed {
+			t.Errorf("rename %s to %s: %v, want allowed %v", tc.source.Child(tc.name), tc.target, err, tc.allowed)
+		}
+	}
+}
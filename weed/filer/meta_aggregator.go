@@ -67,6 +67,7 @@ func (ma *MetaAggregator) OnPeerUpdate(update *master_pb.ClusterNodeUpdate, star
 			close(prevChan)
 			delete(ma.peerChans, address)
 		}
+		ma.filer.onPeerStore(address, false)
 	}
 }
 
@@ -119,6 +120,8 @@ func (ma *MetaAggregator) doSubscribeToOneFiler(f *Filer, self pb.ServerAddress,
 		return lastTsNs, fmt.Errorf("connecting to peer filer %s: %v", peer, err)
 	}
 
+	f.onPeerStore(peer, peerSignature != f.Signature)
+
 	// when filer store is not shared by multiple filers
 	if peerSignature != f.Signature {
 		if prevTsNs, err := ma.readOffset(f, peer, peerSignature); err == nil {
@@ -0,0 +1,154 @@
+package iamapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+)
+
+func findGroup(s3cfg *iam_pb.S3ApiConfiguration, groupName string) (*iam_pb.Group, *IamError) {
+	for _, group := range s3cfg.Groups {
+		if group.Name == groupName {
+			return group, nil
+		}
+	}
+	return nil, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf(GROUP_DOES_NOT_EXIST, groupName)}
+}
+
+func newGroup(group *iam_pb.Group) *iam.Group {
+	return &iam.Group{
+		GroupName:  aws.String(group.Name),
+		GroupId:    aws.String(newIamId("AGPA", group.Name)),
+		Arn:        aws.String("arn:aws:iam:::group/" + group.Name),
+		Path:       aws.String("/"),
+		CreateDate: iamTime(group.CreateDate),
+	}
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_CreateGroup.html
+func (iama *IamApiServer) CreateGroup(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp CreateGroupResponse, iamError *IamError) {
+	groupName := values.Get("GroupName")
+	if _, iamError = findGroup(s3cfg, groupName); iamError == nil {
+		return resp, &IamError{Code: iam.ErrCodeEntityAlreadyExistsException, Error: fmt.Errorf("the group with name %s already exists", groupName)}
+	}
+	group := &iam_pb.Group{Name: groupName, CreateDate: time.Now().Unix()}
+	s3cfg.Groups = append(s3cfg.Groups, group)
+	resp.CreateGroupResult.Group = *newGroup(group)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_GetGroup.html
+func (iama *IamApiServer) GetGroup(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp GetGroupResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.GetGroupResult.Group = *newGroup(group)
+	for _, member := range group.Members {
+		if ident, iamError := findIdentity(s3cfg, member); iamError == nil {
+			resp.GetGroupResult.Users = append(resp.GetGroupResult.Users, &newUser(ident).User)
+		}
+	}
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListGroups.html
+func (iama *IamApiServer) ListGroups(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListGroupsResponse) {
+	for _, group := range s3cfg.Groups {
+		resp.ListGroupsResult.Groups = append(resp.ListGroupsResult.Groups, newGroup(group))
+	}
+	return resp
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DeleteGroup.html
+func (iama *IamApiServer) DeleteGroup(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DeleteGroupResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	if len(group.Members) > 0 || len(group.PolicyNames) > 0 {
+		return resp, &IamError{Code: iam.ErrCodeDeleteConflictException, Error: fmt.Errorf("the group %s still has users or attached policies", group.Name)}
+	}
+	s3cfg.Groups = slices.DeleteFunc(s3cfg.Groups, func(g *iam_pb.Group) bool { return g == group })
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_AddUserToGroup.html
+func (iama *IamApiServer) AddUserToGroup(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp AddUserToGroupResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	if !slices.Contains(group.Members, ident.Name) {
+		group.Members = append(group.Members, ident.Name)
+	}
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_RemoveUserFromGroup.html
+func (iama *IamApiServer) RemoveUserFromGroup(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp RemoveUserFromGroupResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	userName := values.Get("UserName")
+	i := slices.Index(group.Members, userName)
+	if i < 0 {
+		return resp, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf("the user %s is not in the group %s", userName, group.Name)}
+	}
+	group.Members = slices.Delete(group.Members, i, i+1)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListGroupsForUser.html
+func (iama *IamApiServer) ListGroupsForUser(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListGroupsForUserResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	for _, group := range s3cfg.Groups {
+		if slices.Contains(group.Members, ident.Name) {
+			resp.ListGroupsForUserResult.Groups = append(resp.ListGroupsForUserResult.Groups, newGroup(group))
+		}
+	}
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_AttachGroupPolicy.html
+func (iama *IamApiServer) AttachGroupPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp AttachGroupPolicyResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	group.PolicyNames, iamError = attachPolicy(s3cfg, group.PolicyNames, values.Get("PolicyArn"))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DetachGroupPolicy.html
+func (iama *IamApiServer) DetachGroupPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DetachGroupPolicyResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	group.PolicyNames, iamError = detachPolicy(group.PolicyNames, values.Get("PolicyArn"))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListAttachedGroupPolicies.html
+func (iama *IamApiServer) ListAttachedGroupPolicies(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListAttachedGroupPoliciesResponse, iamError *IamError) {
+	group, iamError := findGroup(s3cfg, values.Get("GroupName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.ListAttachedGroupPoliciesResult.AttachedPolicies = attachedPolicies(group.PolicyNames)
+	return resp, nil
+}
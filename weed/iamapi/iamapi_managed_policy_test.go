@@ -0,0 +1,214 @@
+package iamapi
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const readBucketPolicy = `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["s3:GetObject"], "Resource": ["arn:aws:s3:::reports/*"]}]}`
+
+// doRequest executes an IAM request and decodes the response into out if it succeeded
+func doRequest(t *testing.T, req *request.Request, out interface{}) int {
+	require.NoError(t, req.Build())
+	response, _ := executeRequest(req.HTTPRequest, nil)
+	if out != nil && response.Code == http.StatusOK {
+		require.NoError(t, xml.Unmarshal(response.Body.Bytes(), out))
+	}
+	return response.Code
+}
+
+func TestGroupsAndAttachedPolicies(t *testing.T) {
+	svc := iam.New(session.New())
+	req, _ := svc.CreateUserRequest(&iam.CreateUserInput{UserName: aws.String("reader")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.CreatePolicyRequest(&iam.CreatePolicyInput{PolicyName: aws.String("read-reports"), PolicyDocument: aws.String(readBucketPolicy)})
+	createPolicy := CreatePolicyResponse{}
+	require.Equal(t, http.StatusOK, doRequest(t, req, &createPolicy))
+	policyArn := aws.StringValue(createPolicy.CreatePolicyResult.Policy.Arn)
+	assert.Equal(t, "arn:aws:iam:::policy/read-reports", policyArn)
+	assert.Equal(t, "v1", aws.StringValue(createPolicy.CreatePolicyResult.Policy.DefaultVersionId))
+
+	req, _ = svc.CreateGroupRequest(&iam.CreateGroupInput{GroupName: aws.String("readers")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.CreateGroupRequest(&iam.CreateGroupInput{GroupName: aws.String("readers")})
+	assert.Equal(t, http.StatusConflict, doRequest(t, req, nil))
+	req, _ = svc.AddUserToGroupRequest(&iam.AddUserToGroupInput{GroupName: aws.String("readers"), UserName: aws.String("reader")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.AddUserToGroupRequest(&iam.AddUserToGroupInput{GroupName: aws.String("readers"), UserName: aws.String("nobody")})
+	assert.Equal(t, http.StatusNotFound, doRequest(t, req, nil))
+	req, _ = svc.AttachGroupPolicyRequest(&iam.AttachGroupPolicyInput{GroupName: aws.String("readers"), PolicyArn: aws.String(policyArn)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+
+	getGroup := GetGroupResponse{}
+	req, _ = svc.GetGroupRequest(&iam.GetGroupInput{GroupName: aws.String("readers")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &getGroup))
+	require.Len(t, getGroup.GetGroupResult.Users, 1)
+	assert.Equal(t, "reader", aws.StringValue(getGroup.GetGroupResult.Users[0].UserName))
+
+	groupsForUser := ListGroupsForUserResponse{}
+	req, _ = svc.ListGroupsForUserRequest(&iam.ListGroupsForUserInput{UserName: aws.String("reader")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &groupsForUser))
+	require.Len(t, groupsForUser.ListGroupsForUserResult.Groups, 1)
+
+	attached := ListAttachedGroupPoliciesResponse{}
+	req, _ = svc.ListAttachedGroupPoliciesRequest(&iam.ListAttachedGroupPoliciesInput{GroupName: aws.String("readers")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &attached))
+	require.Len(t, attached.ListAttachedGroupPoliciesResult.AttachedPolicies, 1)
+	assert.Equal(t, policyArn, aws.StringValue(attached.ListAttachedGroupPoliciesResult.AttachedPolicies[0].PolicyArn))
+
+	req, _ = svc.AttachUserPolicyRequest(&iam.AttachUserPolicyInput{UserName: aws.String("reader"), PolicyArn: aws.String(policyArn)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	getPolicy := GetPolicyResponse{}
+	req, _ = svc.GetPolicyRequest(&iam.GetPolicyInput{PolicyArn: aws.String(policyArn)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &getPolicy))
+	assert.Equal(t, int64(2), aws.Int64Value(getPolicy.GetPolicyResult.Policy.AttachmentCount))
+
+	// attached policies and non-empty groups cannot be deleted
+	req, _ = svc.DeletePolicyRequest(&iam.DeletePolicyInput{PolicyArn: aws.String(policyArn)})
+	assert.Equal(t, http.StatusConflict, doRequest(t, req, nil))
+	req, _ = svc.DeleteGroupRequest(&iam.DeleteGroupInput{GroupName: aws.String("readers")})
+	assert.Equal(t, http.StatusConflict, doRequest(t, req, nil))
+
+	req, _ = svc.DetachUserPolicyRequest(&iam.DetachUserPolicyInput{UserName: aws.String("reader"), PolicyArn: aws.String(policyArn)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.DetachGroupPolicyRequest(&iam.DetachGroupPolicyInput{GroupName: aws.String("readers"), PolicyArn: aws.String(policyArn)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.RemoveUserFromGroupRequest(&iam.RemoveUserFromGroupInput{GroupName: aws.String("readers"), UserName: aws.String("reader")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.DeleteGroupRequest(&iam.DeleteGroupInput{GroupName: aws.String("readers")})
+	assert.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.DeletePolicyRequest(&iam.DeletePolicyInput{PolicyArn: aws.String(policyArn)})
+	assert.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.DeleteUserRequest(&iam.DeleteUserInput{UserName: aws.String("reader")})
+	assert.Equal(t, http.StatusOK, doRequest(t, req, nil))
+}
+
+func TestPolicyVersions(t *testing.T) {
+	svc := iam.New(session.New())
+	req, _ := svc.CreatePolicyRequest(&iam.CreatePolicyInput{PolicyName: aws.String("versioned"), PolicyDocument: aws.String(readBucketPolicy)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	policyArn := aws.String("arn:aws:iam:::policy/versioned")
+
+	writePolicy := `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["s3:PutObject"], "Resource": ["arn:aws:s3:::reports/*"]}]}`
+	createVersion := CreatePolicyVersionResponse{}
+	req, _ = svc.CreatePolicyVersionRequest(&iam.CreatePolicyVersionInput{PolicyArn: policyArn, PolicyDocument: aws.String(writePolicy), SetAsDefault: aws.Bool(true)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &createVersion))
+	assert.Equal(t, "v2", aws.StringValue(createVersion.CreatePolicyVersionResult.PolicyVersion.VersionId))
+	assert.True(t, aws.BoolValue(createVersion.CreatePolicyVersionResult.PolicyVersion.IsDefaultVersion))
+
+	req, _ = svc.CreatePolicyVersionRequest(&iam.CreatePolicyVersionInput{PolicyArn: policyArn, PolicyDocument: aws.String(`{"Statement": "nope"}`)})
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, req, nil))
+
+	getVersion := GetPolicyVersionResponse{}
+	req, _ = svc.GetPolicyVersionRequest(&iam.GetPolicyVersionInput{PolicyArn: policyArn, VersionId: aws.String("v2")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &getVersion))
+	document, err := url.QueryUnescape(aws.StringValue(getVersion.GetPolicyVersionResult.PolicyVersion.Document))
+	require.NoError(t, err)
+	assert.Equal(t, writePolicy, document)
+
+	// the default version cannot be deleted
+	req, _ = svc.DeletePolicyVersionRequest(&iam.DeletePolicyVersionInput{PolicyArn: policyArn, VersionId: aws.String("v2")})
+	assert.Equal(t, http.StatusConflict, doRequest(t, req, nil))
+	req, _ = svc.SetDefaultPolicyVersionRequest(&iam.SetDefaultPolicyVersionInput{PolicyArn: policyArn, VersionId: aws.String("v1")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.DeletePolicyVersionRequest(&iam.DeletePolicyVersionInput{PolicyArn: policyArn, VersionId: aws.String("v2")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+
+	listVersions := ListPolicyVersionsResponse{}
+	req, _ = svc.ListPolicyVersionsRequest(&iam.ListPolicyVersionsInput{PolicyArn: policyArn})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &listVersions))
+	require.Len(t, listVersions.ListPolicyVersionsResult.Versions, 1)
+	assert.Equal(t, "v1", aws.StringValue(listVersions.ListPolicyVersionsResult.Versions[0].VersionId))
+
+	// a policy has at most 5 versions
+	for i := 0; i < maxPolicyVersions-1; i++ {
+		req, _ = svc.CreatePolicyVersionRequest(&iam.CreatePolicyVersionInput{PolicyArn: policyArn, PolicyDocument: aws.String(writePolicy)})
+		require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	}
+	req, _ = svc.CreatePolicyVersionRequest(&iam.CreatePolicyVersionInput{PolicyArn: policyArn, PolicyDocument: aws.String(writePolicy)})
+	assert.Equal(t, http.StatusConflict, doRequest(t, req, nil))
+
+	listPolicies := ListPoliciesResponse{}
+	req, _ = svc.ListPoliciesRequest(&iam.ListPoliciesInput{Scope: aws.String(iam.PolicyScopeTypeLocal)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &listPolicies))
+	assert.NotEmpty(t, listPolicies.ListPoliciesResult.Policies)
+	req, _ = svc.DeletePolicyRequest(&iam.DeletePolicyInput{PolicyArn: policyArn})
+	assert.Equal(t, http.StatusOK, doRequest(t, req, nil))
+}
+
+func TestRolesAndTags(t *testing.T) {
+	svc := iam.New(session.New())
+	trustPolicy := `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::000000000000:user/ci"}, "Action": "sts:AssumeRole"}]}`
+	req, _ := svc.CreateRoleRequest(&iam.CreateRoleInput{
+		RoleName:                 aws.String("deployer"),
+		AssumeRolePolicyDocument: aws.String(trustPolicy),
+		Tags:                     []*iam.Tag{{Key: aws.String("team"), Value: aws.String("infra")}},
+	})
+	createRole := CreateRoleResponse{}
+	require.Equal(t, http.StatusOK, doRequest(t, req, &createRole))
+	assert.Equal(t, "arn:aws:iam:::role/deployer", aws.StringValue(createRole.CreateRoleResult.Role.Arn))
+	assert.Equal(t, int64(3600), aws.Int64Value(createRole.CreateRoleResult.Role.MaxSessionDuration))
+
+	req, _ = svc.CreateRoleRequest(&iam.CreateRoleInput{RoleName: aws.String("broken"), AssumeRolePolicyDocument: aws.String("{")})
+	assert.Equal(t, http.StatusBadRequest, doRequest(t, req, nil))
+
+	req, _ = svc.TagRoleRequest(&iam.TagRoleInput{RoleName: aws.String("deployer"), Tags: []*iam.Tag{
+		{Key: aws.String("team"), Value: aws.String("platform")},
+		{Key: aws.String("env"), Value: aws.String("prod")},
+	}})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.UntagRoleRequest(&iam.UntagRoleInput{RoleName: aws.String("deployer"), TagKeys: []*string{aws.String("env")}})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+
+	getRole := GetRoleResponse{}
+	req, _ = svc.GetRoleRequest(&iam.GetRoleInput{RoleName: aws.String("deployer")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &getRole))
+	require.Len(t, getRole.GetRoleResult.Role.Tags, 1)
+	assert.Equal(t, "platform", aws.StringValue(getRole.GetRoleResult.Role.Tags[0].Value))
+	document, err := url.QueryUnescape(aws.StringValue(getRole.GetRoleResult.Role.AssumeRolePolicyDocument))
+	require.NoError(t, err)
+	assert.Equal(t, trustPolicy, document)
+
+	req, _ = svc.CreatePolicyRequest(&iam.CreatePolicyInput{PolicyName: aws.String("deploy"), PolicyDocument: aws.String(readBucketPolicy)})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.AttachRolePolicyRequest(&iam.AttachRolePolicyInput{RoleName: aws.String("deployer"), PolicyArn: aws.String("arn:aws:iam:::policy/deploy")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	attached := ListAttachedRolePoliciesResponse{}
+	req, _ = svc.ListAttachedRolePoliciesRequest(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String("deployer")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &attached))
+	require.Len(t, attached.ListAttachedRolePoliciesResult.AttachedPolicies, 1)
+	req, _ = svc.DeleteRoleRequest(&iam.DeleteRoleInput{RoleName: aws.String("deployer")})
+	assert.Equal(t, http.StatusConflict, doRequest(t, req, nil))
+	req, _ = svc.DetachRolePolicyRequest(&iam.DetachRolePolicyInput{RoleName: aws.String("deployer"), PolicyArn: aws.String("arn:aws:iam:::policy/deploy")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.DeleteRoleRequest(&iam.DeleteRoleInput{RoleName: aws.String("deployer")})
+	assert.Equal(t, http.StatusOK, doRequest(t, req, nil))
+
+	req, _ = svc.TagPolicyRequest(&iam.TagPolicyInput{PolicyArn: aws.String("arn:aws:iam:::policy/deploy"), Tags: []*iam.Tag{{Key: aws.String("owner"), Value: aws.String("ops")}}})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	policyTags := ListPolicyTagsResponse{}
+	req, _ = svc.ListPolicyTagsRequest(&iam.ListPolicyTagsInput{PolicyArn: aws.String("arn:aws:iam:::policy/deploy")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &policyTags))
+	require.Len(t, policyTags.ListPolicyTagsResult.Tags, 1)
+
+	req, _ = svc.CreateUserRequest(&iam.CreateUserInput{UserName: aws.String("tagged")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	req, _ = svc.TagUserRequest(&iam.TagUserInput{UserName: aws.String("tagged"), Tags: []*iam.Tag{{Key: aws.String("cost-center"), Value: aws.String("42")}}})
+	require.Equal(t, http.StatusOK, doRequest(t, req, nil))
+	userTags := ListUserTagsResponse{}
+	req, _ = svc.ListUserTagsRequest(&iam.ListUserTagsInput{UserName: aws.String("tagged")})
+	require.Equal(t, http.StatusOK, doRequest(t, req, &userTags))
+	require.Len(t, userTags.ListUserTagsResult.Tags, 1)
+	assert.Equal(t, "cost-center", aws.StringValue(userTags.ListUserTagsResult.Tags[0].Key))
+	req, _ = svc.DeleteUserRequest(&iam.DeleteUserInput{UserName: aws.String("tagged")})
+	assert.Equal(t, http.StatusOK, doRequest(t, req, nil))
+}
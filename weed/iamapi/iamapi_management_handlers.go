@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +21,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
 )
 
@@ -91,7 +93,10 @@ func MapToIdentitiesAction(action string) string {
 }
 
 const (
-	USER_DOES_NOT_EXIST = "the user with name %s cannot be found."
+	USER_DOES_NOT_EXIST   = "the user with name %s cannot be found."
+	GROUP_DOES_NOT_EXIST  = "the group with name %s cannot be found."
+	ROLE_DOES_NOT_EXIST   = "the role with name %s cannot be found."
+	POLICY_DOES_NOT_EXIST = "the policy with arn %s cannot be found."
 )
 
 type Policies struct {
@@ -138,14 +143,38 @@ func (iama *IamApiServer) ListAccessKeys(s3cfg *iam_pb.S3ApiConfiguration, value
 func (iama *IamApiServer) CreateUser(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp CreateUserResponse) {
 	userName := values.Get("UserName")
 	resp.CreateUserResult.User.UserName = &userName
-	s3cfg.Identities = append(s3cfg.Identities, &iam_pb.Identity{Name: userName})
+	s3cfg.Identities = append(s3cfg.Identities, &iam_pb.Identity{Name: userName, Tags: parseTags(values)})
 	return resp
 }
 
+func findIdentity(s3cfg *iam_pb.S3ApiConfiguration, userName string) (*iam_pb.Identity, *IamError) {
+	for _, ident := range s3cfg.Identities {
+		if ident.Name == userName {
+			return ident, nil
+		}
+	}
+	return nil, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf(USER_DOES_NOT_EXIST, userName)}
+}
+
+func newUser(ident *iam_pb.Identity) *User {
+	return &User{
+		User: iam.User{
+			UserName: aws.String(ident.Name),
+			UserId:   aws.String(newIamId("AIDA", ident.Name)),
+			Arn:      aws.String("arn:aws:iam:::user/" + ident.Name),
+			Path:     aws.String("/"),
+		},
+		Tags: iamTags(ident.Tags),
+	}
+}
+
 func (iama *IamApiServer) DeleteUser(s3cfg *iam_pb.S3ApiConfiguration, userName string) (resp DeleteUserResponse, err *IamError) {
 	for i, ident := range s3cfg.Identities {
 		if userName == ident.Name {
 			s3cfg.Identities = append(s3cfg.Identities[:i], s3cfg.Identities[i+1:]...)
+			for _, group := range s3cfg.Groups {
+				group.Members = slices.DeleteFunc(group.Members, func(member string) bool { return member == userName })
+			}
 			return resp, nil
 		}
 	}
@@ -155,7 +184,7 @@ func (iama *IamApiServer) DeleteUser(s3cfg *iam_pb.S3ApiConfiguration, userName
 func (iama *IamApiServer) GetUser(s3cfg *iam_pb.S3ApiConfiguration, userName string) (resp GetUserResponse, err *IamError) {
 	for _, ident := range s3cfg.Identities {
 		if userName == ident.Name {
-			resp.GetUserResult.User = iam.User{UserName: &ident.Name}
+			resp.GetUserResult.User = *newUser(ident)
 			return resp, nil
 		}
 	}
@@ -169,6 +198,11 @@ func (iama *IamApiServer) UpdateUser(s3cfg *iam_pb.S3ApiConfiguration, values ur
 		for _, ident := range s3cfg.Identities {
 			if userName == ident.Name {
 				ident.Name = newUserName
+				for _, group := range s3cfg.Groups {
+					if i := slices.Index(group.Members, userName); i >= 0 {
+						group.Members[i] = newUserName
+					}
+				}
 				return resp, nil
 			}
 		}
@@ -193,20 +227,33 @@ func (iama *IamApiServer) CreatePolicy(s3cfg *iam_pb.S3ApiConfiguration, values
 	if err != nil {
 		return CreatePolicyResponse{}, &IamError{Code: iam.ErrCodeMalformedPolicyDocumentException, Error: err}
 	}
-	policyId := Hash(&policyDocumentString)
-	arn := fmt.Sprintf("arn:aws:iam:::policy/%s", policyName)
-	resp.CreatePolicyResult.Policy.PolicyName = &policyName
-	resp.CreatePolicyResult.Policy.Arn = &arn
-	resp.CreatePolicyResult.Policy.PolicyId = &policyId
-	policies := Policies{}
-	policyLock.Lock()
-	defer policyLock.Unlock()
-	if err = iama.s3ApiConfig.GetPolicies(&policies); err != nil {
-		return resp, &IamError{Code: iam.ErrCodeServiceFailureException, Error: err}
-	}
-	policies.Policies[policyName] = policyDocument
-	if err = iama.s3ApiConfig.PutPolicies(&policies); err != nil {
-		return resp, &IamError{Code: iam.ErrCodeServiceFailureException, Error: err}
+	if err = policy_engine.ValidatePolicy(&policyDocument); err != nil {
+		return CreatePolicyResponse{}, &IamError{Code: iam.ErrCodeMalformedPolicyDocumentException, Error: err}
+	}
+	for _, policy := range s3cfg.Policies {
+		if policy.Name == policyName {
+			return CreatePolicyResponse{}, &IamError{Code: iam.ErrCodeEntityAlreadyExistsException, Error: fmt.Errorf("the policy with name %s already exists", policyName)}
+		}
+	}
+	tags, iamError := mergeTags(nil, parseTags(values))
+	if iamError != nil {
+		return CreatePolicyResponse{}, iamError
+	}
+	now := time.Now().Unix()
+	policy := &iam_pb.Policy{
+		Name:             policyName,
+		PolicyId:         Hash(&policyDocumentString),
+		Description:      values.Get("Description"),
+		DefaultVersionId: "v1",
+		Tags:             tags,
+		Versions:         []*iam_pb.PolicyVersion{{VersionId: "v1", Document: policyDocumentString, CreateDate: now}},
+		CreateDate:       now,
+		UpdateDate:       now,
+	}
+	s3cfg.Policies = append(s3cfg.Policies, policy)
+	resp.CreatePolicyResult.Policy = *newPolicy(s3cfg, policy)
+	if iamError = iama.putPolicyDocument(policyName, &policyDocument); iamError != nil {
+		return resp, iamError
 	}
 	return resp, nil
 }
@@ -488,7 +535,7 @@ func (iama *IamApiServer) DoActions(w http.ResponseWriter, r *http.Request) {
 		response, iamError = iama.CreatePolicy(s3cfg, values)
 		if iamError != nil {
 			glog.Errorf("CreatePolicy:  %+v", iamError.Error)
-			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+			writeIamErrorResponse(w, r, iamError)
 			return
 		}
 	case "PutUserPolicy":
@@ -512,6 +559,207 @@ func (iama *IamApiServer) DoActions(w http.ResponseWriter, r *http.Request) {
 			writeIamErrorResponse(w, r, iamError)
 			return
 		}
+	case "CreateGroup":
+		if response, iamError = iama.CreateGroup(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "GetGroup":
+		if response, iamError = iama.GetGroup(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "ListGroups":
+		response = iama.ListGroups(s3cfg, values)
+		changed = false
+	case "DeleteGroup":
+		if response, iamError = iama.DeleteGroup(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "AddUserToGroup":
+		if response, iamError = iama.AddUserToGroup(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "RemoveUserFromGroup":
+		if response, iamError = iama.RemoveUserFromGroup(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListGroupsForUser":
+		if response, iamError = iama.ListGroupsForUser(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "AttachUserPolicy":
+		if response, iamError = iama.AttachUserPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "DetachUserPolicy":
+		if response, iamError = iama.DetachUserPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListAttachedUserPolicies":
+		if response, iamError = iama.ListAttachedUserPolicies(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "AttachGroupPolicy":
+		if response, iamError = iama.AttachGroupPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "DetachGroupPolicy":
+		if response, iamError = iama.DetachGroupPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListAttachedGroupPolicies":
+		if response, iamError = iama.ListAttachedGroupPolicies(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "CreateRole":
+		if response, iamError = iama.CreateRole(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "GetRole":
+		if response, iamError = iama.GetRole(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "ListRoles":
+		response = iama.ListRoles(s3cfg, values)
+		changed = false
+	case "UpdateRole":
+		if response, iamError = iama.UpdateRole(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "UpdateAssumeRolePolicy":
+		if response, iamError = iama.UpdateAssumeRolePolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "DeleteRole":
+		if response, iamError = iama.DeleteRole(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "AttachRolePolicy":
+		if response, iamError = iama.AttachRolePolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "DetachRolePolicy":
+		if response, iamError = iama.DetachRolePolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListAttachedRolePolicies":
+		if response, iamError = iama.ListAttachedRolePolicies(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "GetPolicy":
+		if response, iamError = iama.GetPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "ListPolicies":
+		response = iama.ListPolicies(s3cfg, values)
+		changed = false
+	case "DeletePolicy":
+		if response, iamError = iama.DeletePolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "CreatePolicyVersion":
+		if response, iamError = iama.CreatePolicyVersion(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "GetPolicyVersion":
+		if response, iamError = iama.GetPolicyVersion(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "ListPolicyVersions":
+		if response, iamError = iama.ListPolicyVersions(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "DeletePolicyVersion":
+		if response, iamError = iama.DeletePolicyVersion(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "SetDefaultPolicyVersion":
+		if response, iamError = iama.SetDefaultPolicyVersion(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "TagUser":
+		if response, iamError = iama.TagUser(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "UntagUser":
+		if response, iamError = iama.UntagUser(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListUserTags":
+		if response, iamError = iama.ListUserTags(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "TagRole":
+		if response, iamError = iama.TagRole(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "UntagRole":
+		if response, iamError = iama.UntagRole(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListRoleTags":
+		if response, iamError = iama.ListRoleTags(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
+	case "TagPolicy":
+		if response, iamError = iama.TagPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "UntagPolicy":
+		if response, iamError = iama.UntagPolicy(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+	case "ListPolicyTags":
+		if response, iamError = iama.ListPolicyTags(s3cfg, values); iamError != nil {
+			writeIamErrorResponse(w, r, iamError)
+			return
+		}
+		changed = false
 	default:
 		errNotImplemented := s3err.GetAPIError(s3err.ErrNotImplemented)
 		errorResponse := ErrorResponse{}
@@ -0,0 +1,355 @@
+package iamapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+)
+
+// maxPolicyVersions is the number of versions a managed policy can have
+const maxPolicyVersions = 5
+
+func policyArn(policyName string) string {
+	return "arn:aws:iam:::policy/" + policyName
+}
+
+// policyNameFromArn returns the last path element of arn:aws:iam::<account>:policy/<path>/<name>
+func policyNameFromArn(arn string) string {
+	_, resource, found := strings.Cut(arn, ":policy/")
+	if !found {
+		return ""
+	}
+	return resource[strings.LastIndex(resource, "/")+1:]
+}
+
+// newIamId derives a stable unique id of an IAM entity from its name
+func newIamId(prefix string, name string) string {
+	return prefix + strings.ToUpper(Hash(&name))[:17]
+}
+
+func iamTime(unixSeconds int64) *time.Time {
+	if unixSeconds == 0 {
+		return nil
+	}
+	t := time.Unix(unixSeconds, 0).UTC()
+	return &t
+}
+
+// encodeDocument URL-encodes a policy document as in the responses of AWS IAM
+func encodeDocument(document string) string {
+	return strings.ReplaceAll(url.QueryEscape(document), "+", "%20")
+}
+
+func findPolicy(s3cfg *iam_pb.S3ApiConfiguration, arn string) (*iam_pb.Policy, *IamError) {
+	policyName := policyNameFromArn(arn)
+	for _, policy := range s3cfg.Policies {
+		if policy.Name == policyName {
+			return policy, nil
+		}
+	}
+	return nil, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf(POLICY_DOES_NOT_EXIST, arn)}
+}
+
+func findPolicyVersion(policy *iam_pb.Policy, versionId string) (int, *IamError) {
+	for i, version := range policy.Versions {
+		if version.VersionId == versionId {
+			return i, nil
+		}
+	}
+	return -1, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf("the policy %s has no version %s", policy.Name, versionId)}
+}
+
+// policyAttachmentCount returns the number of users, groups and roles a managed policy is attached to
+func policyAttachmentCount(s3cfg *iam_pb.S3ApiConfiguration, policyName string) int64 {
+	var count int64
+	for _, ident := range s3cfg.Identities {
+		if slices.Contains(ident.PolicyNames, policyName) {
+			count++
+		}
+	}
+	for _, group := range s3cfg.Groups {
+		if slices.Contains(group.PolicyNames, policyName) {
+			count++
+		}
+	}
+	for _, role := range s3cfg.Roles {
+		if slices.Contains(role.PolicyNames, policyName) {
+			count++
+		}
+	}
+	return count
+}
+
+func newPolicy(s3cfg *iam_pb.S3ApiConfiguration, policy *iam_pb.Policy) *Policy {
+	resp := &Policy{
+		Policy: iam.Policy{
+			PolicyName:       aws.String(policy.Name),
+			PolicyId:         aws.String(policy.PolicyId),
+			Arn:              aws.String(policyArn(policy.Name)),
+			Path:             aws.String("/"),
+			DefaultVersionId: aws.String(policy.DefaultVersionId),
+			AttachmentCount:  aws.Int64(policyAttachmentCount(s3cfg, policy.Name)),
+			IsAttachable:     aws.Bool(true),
+			CreateDate:       iamTime(policy.CreateDate),
+			UpdateDate:       iamTime(policy.UpdateDate),
+		},
+		Tags: iamTags(policy.Tags),
+	}
+	if policy.Description != "" {
+		resp.Description = aws.String(policy.Description)
+	}
+	return resp
+}
+
+func newPolicyVersion(policy *iam_pb.Policy, version *iam_pb.PolicyVersion, withDocument bool) *iam.PolicyVersion {
+	policyVersion := &iam.PolicyVersion{
+		VersionId:        aws.String(version.VersionId),
+		IsDefaultVersion: aws.Bool(version.VersionId == policy.DefaultVersionId),
+		CreateDate:       iamTime(version.CreateDate),
+	}
+	if withDocument {
+		policyVersion.Document = aws.String(encodeDocument(version.Document))
+	}
+	return policyVersion
+}
+
+// parsePolicyDocument validates a policy document of a managed policy version
+func parsePolicyDocument(document string) (*policy_engine.PolicyDocument, *IamError) {
+	policyDocument, err := GetPolicyDocument(&document)
+	if err != nil {
+		return nil, &IamError{Code: iam.ErrCodeMalformedPolicyDocumentException, Error: err}
+	}
+	if err = policy_engine.ValidatePolicy(&policyDocument); err != nil {
+		return nil, &IamError{Code: iam.ErrCodeMalformedPolicyDocumentException, Error: err}
+	}
+	return &policyDocument, nil
+}
+
+// putPolicyDocument keeps the policies file of the admin UI in sync with the
+// default versions of the managed policies. A nil document deletes the policy.
+func (iama *IamApiServer) putPolicyDocument(policyName string, policyDocument *policy_engine.PolicyDocument) *IamError {
+	policies := Policies{}
+	policyLock.Lock()
+	defer policyLock.Unlock()
+	if err := iama.s3ApiConfig.GetPolicies(&policies); err != nil {
+		return &IamError{Code: iam.ErrCodeServiceFailureException, Error: err}
+	}
+	if policyDocument == nil {
+		delete(policies.Policies, policyName)
+	} else {
+		policies.Policies[policyName] = *policyDocument
+	}
+	if err := iama.s3ApiConfig.PutPolicies(&policies); err != nil {
+		return &IamError{Code: iam.ErrCodeServiceFailureException, Error: err}
+	}
+	return nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_GetPolicy.html
+func (iama *IamApiServer) GetPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp GetPolicyResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.GetPolicyResult.Policy = *newPolicy(s3cfg, policy)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListPolicies.html
+// All managed policies are customer managed, there are no AWS managed policies.
+func (iama *IamApiServer) ListPolicies(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListPoliciesResponse) {
+	if values.Get("Scope") == iam.PolicyScopeTypeAws {
+		return resp
+	}
+	onlyAttached := values.Get("OnlyAttached") == "true"
+	for _, policy := range s3cfg.Policies {
+		if onlyAttached && policyAttachmentCount(s3cfg, policy.Name) == 0 {
+			continue
+		}
+		resp.ListPoliciesResult.Policies = append(resp.ListPoliciesResult.Policies, newPolicy(s3cfg, policy))
+	}
+	return resp
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DeletePolicy.html
+func (iama *IamApiServer) DeletePolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DeletePolicyResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	if policyAttachmentCount(s3cfg, policy.Name) > 0 {
+		return resp, &IamError{Code: iam.ErrCodeDeleteConflictException, Error: fmt.Errorf("the policy %s is attached to entities", policy.Name)}
+	}
+	s3cfg.Policies = slices.DeleteFunc(s3cfg.Policies, func(p *iam_pb.Policy) bool { return p == policy })
+	return resp, iama.putPolicyDocument(policy.Name, nil)
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_CreatePolicyVersion.html
+func (iama *IamApiServer) CreatePolicyVersion(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp CreatePolicyVersionResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	document := values.Get("PolicyDocument")
+	policyDocument, iamError := parsePolicyDocument(document)
+	if iamError != nil {
+		return resp, iamError
+	}
+	if len(policy.Versions) >= maxPolicyVersions {
+		return resp, &IamError{Code: iam.ErrCodeLimitExceededException, Error: fmt.Errorf("the policy %s already has %d versions", policy.Name, maxPolicyVersions)}
+	}
+	lastVersion := 0
+	for _, version := range policy.Versions {
+		if n, err := strconv.Atoi(strings.TrimPrefix(version.VersionId, "v")); err == nil && n > lastVersion {
+			lastVersion = n
+		}
+	}
+	now := time.Now().Unix()
+	version := &iam_pb.PolicyVersion{
+		VersionId:  fmt.Sprintf("v%d", lastVersion+1),
+		Document:   document,
+		CreateDate: now,
+	}
+	policy.Versions = append(policy.Versions, version)
+	policy.UpdateDate = now
+	if values.Get("SetAsDefault") == "true" {
+		policy.DefaultVersionId = version.VersionId
+		if iamError = iama.putPolicyDocument(policy.Name, policyDocument); iamError != nil {
+			return resp, iamError
+		}
+	}
+	resp.CreatePolicyVersionResult.PolicyVersion = *newPolicyVersion(policy, version, false)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_GetPolicyVersion.html
+func (iama *IamApiServer) GetPolicyVersion(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp GetPolicyVersionResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	i, iamError := findPolicyVersion(policy, values.Get("VersionId"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.GetPolicyVersionResult.PolicyVersion = *newPolicyVersion(policy, policy.Versions[i], true)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListPolicyVersions.html
+func (iama *IamApiServer) ListPolicyVersions(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListPolicyVersionsResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	for _, version := range policy.Versions {
+		resp.ListPolicyVersionsResult.Versions = append(resp.ListPolicyVersionsResult.Versions, newPolicyVersion(policy, version, false))
+	}
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DeletePolicyVersion.html
+func (iama *IamApiServer) DeletePolicyVersion(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DeletePolicyVersionResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	versionId := values.Get("VersionId")
+	i, iamError := findPolicyVersion(policy, versionId)
+	if iamError != nil {
+		return resp, iamError
+	}
+	if versionId == policy.DefaultVersionId {
+		return resp, &IamError{Code: iam.ErrCodeDeleteConflictException, Error: fmt.Errorf("cannot delete the default version %s of the policy %s", versionId, policy.Name)}
+	}
+	policy.Versions = slices.Delete(policy.Versions, i, i+1)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_SetDefaultPolicyVersion.html
+func (iama *IamApiServer) SetDefaultPolicyVersion(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp SetDefaultPolicyVersionResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	i, iamError := findPolicyVersion(policy, values.Get("VersionId"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	policyDocument, iamError := parsePolicyDocument(policy.Versions[i].Document)
+	if iamError != nil {
+		return resp, iamError
+	}
+	policy.DefaultVersionId = policy.Versions[i].VersionId
+	policy.UpdateDate = time.Now().Unix()
+	return resp, iama.putPolicyDocument(policy.Name, policyDocument)
+}
+
+// attachPolicy adds a managed policy to the attached policies of a user, group or role
+func attachPolicy(s3cfg *iam_pb.S3ApiConfiguration, policyNames []string, arn string) ([]string, *IamError) {
+	policy, iamError := findPolicy(s3cfg, arn)
+	if iamError != nil {
+		return policyNames, iamError
+	}
+	if slices.Contains(policyNames, policy.Name) {
+		return policyNames, nil
+	}
+	return append(policyNames, policy.Name), nil
+}
+
+// detachPolicy removes a managed policy from the attached policies of a user, group or role
+func detachPolicy(policyNames []string, arn string) ([]string, *IamError) {
+	i := slices.Index(policyNames, policyNameFromArn(arn))
+	if i < 0 {
+		return policyNames, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf("the policy %s is not attached", arn)}
+	}
+	return slices.Delete(policyNames, i, i+1), nil
+}
+
+func attachedPolicies(policyNames []string) (attached []*iam.AttachedPolicy) {
+	for _, policyName := range policyNames {
+		attached = append(attached, &iam.AttachedPolicy{
+			PolicyName: aws.String(policyName),
+			PolicyArn:  aws.String(policyArn(policyName)),
+		})
+	}
+	return attached
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_AttachUserPolicy.html
+func (iama *IamApiServer) AttachUserPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp AttachUserPolicyResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	ident.PolicyNames, iamError = attachPolicy(s3cfg, ident.PolicyNames, values.Get("PolicyArn"))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DetachUserPolicy.html
+func (iama *IamApiServer) DetachUserPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DetachUserPolicyResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	ident.PolicyNames, iamError = detachPolicy(ident.PolicyNames, values.Get("PolicyArn"))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListAttachedUserPolicies.html
+func (iama *IamApiServer) ListAttachedUserPolicies(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListAttachedUserPoliciesResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.ListAttachedUserPoliciesResult.AttachedPolicies = attachedPolicies(ident.PolicyNames)
+	return resp, nil
+}
@@ -0,0 +1,191 @@
+package iamapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+)
+
+// limits of the maximum session duration of a role, in seconds
+const (
+	defaultRoleMaxSessionDuration = 3600
+	maxRoleMaxSessionDuration     = 43200
+)
+
+func findRole(s3cfg *iam_pb.S3ApiConfiguration, roleName string) (*iam_pb.Role, *IamError) {
+	for _, role := range s3cfg.Roles {
+		if role.Name == roleName {
+			return role, nil
+		}
+	}
+	return nil, &IamError{Code: iam.ErrCodeNoSuchEntityException, Error: fmt.Errorf(ROLE_DOES_NOT_EXIST, roleName)}
+}
+
+func newRole(role *iam_pb.Role) *Role {
+	resp := &Role{
+		Role: iam.Role{
+			RoleName:                 aws.String(role.Name),
+			RoleId:                   aws.String(newIamId("AROA", role.Name)),
+			Arn:                      aws.String("arn:aws:iam:::role/" + role.Name),
+			Path:                     aws.String("/"),
+			AssumeRolePolicyDocument: aws.String(encodeDocument(role.AssumeRolePolicyDocument)),
+			MaxSessionDuration:       aws.Int64(role.MaxSessionDuration),
+			CreateDate:               iamTime(role.CreateDate),
+		},
+		Tags: iamTags(role.Tags),
+	}
+	if role.Description != "" {
+		resp.Description = aws.String(role.Description)
+	}
+	return resp
+}
+
+// checkTrustPolicy only checks the syntax, trust policies are evaluated when the role is assumed
+func checkTrustPolicy(document string) *IamError {
+	if !json.Valid([]byte(document)) {
+		return &IamError{Code: iam.ErrCodeMalformedPolicyDocumentException, Error: errors.New("the trust policy is not valid JSON")}
+	}
+	return nil
+}
+
+func parseMaxSessionDuration(value string) (int64, *IamError) {
+	if value == "" {
+		return defaultRoleMaxSessionDuration, nil
+	}
+	duration, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || duration < defaultRoleMaxSessionDuration || duration > maxRoleMaxSessionDuration {
+		return 0, &IamError{Code: iam.ErrCodeInvalidInputException, Error: fmt.Errorf("MaxSessionDuration must be between %d and %d seconds", defaultRoleMaxSessionDuration, maxRoleMaxSessionDuration)}
+	}
+	return duration, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_CreateRole.html
+func (iama *IamApiServer) CreateRole(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp CreateRoleResponse, iamError *IamError) {
+	roleName := values.Get("RoleName")
+	if _, iamError = findRole(s3cfg, roleName); iamError == nil {
+		return resp, &IamError{Code: iam.ErrCodeEntityAlreadyExistsException, Error: fmt.Errorf("the role with name %s already exists", roleName)}
+	}
+	trustPolicy := values.Get("AssumeRolePolicyDocument")
+	if iamError = checkTrustPolicy(trustPolicy); iamError != nil {
+		return resp, iamError
+	}
+	maxSessionDuration, iamError := parseMaxSessionDuration(values.Get("MaxSessionDuration"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	tags, iamError := mergeTags(nil, parseTags(values))
+	if iamError != nil {
+		return resp, iamError
+	}
+	role := &iam_pb.Role{
+		Name:                     roleName,
+		Description:              values.Get("Description"),
+		AssumeRolePolicyDocument: trustPolicy,
+		MaxSessionDuration:       maxSessionDuration,
+		Tags:                     tags,
+		CreateDate:               time.Now().Unix(),
+	}
+	s3cfg.Roles = append(s3cfg.Roles, role)
+	resp.CreateRoleResult.Role = *newRole(role)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_GetRole.html
+func (iama *IamApiServer) GetRole(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp GetRoleResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.GetRoleResult.Role = *newRole(role)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListRoles.html
+func (iama *IamApiServer) ListRoles(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListRolesResponse) {
+	for _, role := range s3cfg.Roles {
+		resp.ListRolesResult.Roles = append(resp.ListRolesResult.Roles, newRole(role))
+	}
+	return resp
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_UpdateRole.html
+func (iama *IamApiServer) UpdateRole(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp UpdateRoleResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	if values.Has("MaxSessionDuration") {
+		if role.MaxSessionDuration, iamError = parseMaxSessionDuration(values.Get("MaxSessionDuration")); iamError != nil {
+			return resp, iamError
+		}
+	}
+	if values.Has("Description") {
+		role.Description = values.Get("Description")
+	}
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_UpdateAssumeRolePolicy.html
+func (iama *IamApiServer) UpdateAssumeRolePolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp UpdateAssumeRolePolicyResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	trustPolicy := values.Get("PolicyDocument")
+	if iamError = checkTrustPolicy(trustPolicy); iamError != nil {
+		return resp, iamError
+	}
+	role.AssumeRolePolicyDocument = trustPolicy
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DeleteRole.html
+func (iama *IamApiServer) DeleteRole(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DeleteRoleResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	if len(role.PolicyNames) > 0 {
+		return resp, &IamError{Code: iam.ErrCodeDeleteConflictException, Error: fmt.Errorf("the role %s still has attached policies", role.Name)}
+	}
+	s3cfg.Roles = slices.DeleteFunc(s3cfg.Roles, func(r *iam_pb.Role) bool { return r == role })
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_AttachRolePolicy.html
+func (iama *IamApiServer) AttachRolePolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp AttachRolePolicyResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	role.PolicyNames, iamError = attachPolicy(s3cfg, role.PolicyNames, values.Get("PolicyArn"))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_DetachRolePolicy.html
+func (iama *IamApiServer) DetachRolePolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp DetachRolePolicyResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	role.PolicyNames, iamError = detachPolicy(role.PolicyNames, values.Get("PolicyArn"))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListAttachedRolePolicies.html
+func (iama *IamApiServer) ListAttachedRolePolicies(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListAttachedRolePoliciesResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.ListAttachedRolePoliciesResult.AttachedPolicies = attachedPolicies(role.PolicyNames)
+	return resp, nil
+}
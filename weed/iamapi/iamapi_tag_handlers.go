@@ -0,0 +1,157 @@
+package iamapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+)
+
+// maxTags is the number of tags a user, role or policy can have
+const maxTags = 50
+
+// parseTags reads the Tags.member.N.Key and Tags.member.N.Value parameters
+func parseTags(values url.Values) (tags []*iam_pb.Tag) {
+	for i := 1; ; i++ {
+		prefix := "Tags.member." + strconv.Itoa(i) + "."
+		if !values.Has(prefix + "Key") {
+			return tags
+		}
+		tags = append(tags, &iam_pb.Tag{Key: values.Get(prefix + "Key"), Value: values.Get(prefix + "Value")})
+	}
+}
+
+// parseMembers reads the <name>.member.N parameters
+func parseMembers(values url.Values, name string) (members []string) {
+	for i := 1; ; i++ {
+		key := name + ".member." + strconv.Itoa(i)
+		if !values.Has(key) {
+			return members
+		}
+		members = append(members, values.Get(key))
+	}
+}
+
+// mergeTags adds tags, replacing the values of existing keys
+func mergeTags(tags []*iam_pb.Tag, newTags []*iam_pb.Tag) ([]*iam_pb.Tag, *IamError) {
+	for _, newTag := range newTags {
+		if newTag.Key == "" {
+			return tags, &IamError{Code: iam.ErrCodeInvalidInputException, Error: fmt.Errorf("tag key must not be empty")}
+		}
+		i := slices.IndexFunc(tags, func(tag *iam_pb.Tag) bool { return tag.Key == newTag.Key })
+		if i >= 0 {
+			tags[i].Value = newTag.Value
+			continue
+		}
+		tags = append(tags, newTag)
+	}
+	if len(tags) > maxTags {
+		return tags, &IamError{Code: iam.ErrCodeLimitExceededException, Error: fmt.Errorf("cannot have more than %d tags", maxTags)}
+	}
+	return tags, nil
+}
+
+func removeTags(tags []*iam_pb.Tag, keys []string) []*iam_pb.Tag {
+	return slices.DeleteFunc(tags, func(tag *iam_pb.Tag) bool { return slices.Contains(keys, tag.Key) })
+}
+
+func iamTags(tags []*iam_pb.Tag) (iamTags []*iam.Tag) {
+	for _, tag := range tags {
+		iamTags = append(iamTags, &iam.Tag{Key: aws.String(tag.Key), Value: aws.String(tag.Value)})
+	}
+	return iamTags
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_TagUser.html
+func (iama *IamApiServer) TagUser(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp TagUserResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	ident.Tags, iamError = mergeTags(ident.Tags, parseTags(values))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_UntagUser.html
+func (iama *IamApiServer) UntagUser(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp UntagUserResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	ident.Tags = removeTags(ident.Tags, parseMembers(values, "TagKeys"))
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListUserTags.html
+func (iama *IamApiServer) ListUserTags(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListUserTagsResponse, iamError *IamError) {
+	ident, iamError := findIdentity(s3cfg, values.Get("UserName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.ListUserTagsResult.Tags = iamTags(ident.Tags)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_TagRole.html
+func (iama *IamApiServer) TagRole(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp TagRoleResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	role.Tags, iamError = mergeTags(role.Tags, parseTags(values))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_UntagRole.html
+func (iama *IamApiServer) UntagRole(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp UntagRoleResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	role.Tags = removeTags(role.Tags, parseMembers(values, "TagKeys"))
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListRoleTags.html
+func (iama *IamApiServer) ListRoleTags(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListRoleTagsResponse, iamError *IamError) {
+	role, iamError := findRole(s3cfg, values.Get("RoleName"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.ListRoleTagsResult.Tags = iamTags(role.Tags)
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_TagPolicy.html
+func (iama *IamApiServer) TagPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp TagPolicyResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	policy.Tags, iamError = mergeTags(policy.Tags, parseTags(values))
+	return resp, iamError
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_UntagPolicy.html
+func (iama *IamApiServer) UntagPolicy(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp UntagPolicyResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	policy.Tags = removeTags(policy.Tags, parseMembers(values, "TagKeys"))
+	return resp, nil
+}
+
+// https://docs.aws.amazon.com/IAM/latest/APIReference/API_ListPolicyTags.html
+func (iama *IamApiServer) ListPolicyTags(s3cfg *iam_pb.S3ApiConfiguration, values url.Values) (resp ListPolicyTagsResponse, iamError *IamError) {
+	policy, iamError := findPolicy(s3cfg, values.Get("PolicyArn"))
+	if iamError != nil {
+		return resp, iamError
+	}
+	resp.ListPolicyTagsResult.Tags = iamTags(policy.Tags)
+	return resp, nil
+}
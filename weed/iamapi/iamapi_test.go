@@ -31,11 +31,17 @@ type iamS3ApiConfigureMock struct{}
 
 func (iam iamS3ApiConfigureMock) GetS3ApiConfiguration(s3cfg *iam_pb.S3ApiConfiguration) (err error) {
 	_ = copier.Copy(&s3cfg.Identities, &s3config.Identities)
+	_ = copier.Copy(&s3cfg.Groups, &s3config.Groups)
+	_ = copier.Copy(&s3cfg.Roles, &s3config.Roles)
+	_ = copier.Copy(&s3cfg.Policies, &s3config.Policies)
 	return nil
 }
 
 func (iam iamS3ApiConfigureMock) PutS3ApiConfiguration(s3cfg *iam_pb.S3ApiConfiguration) (err error) {
 	_ = copier.Copy(&s3config.Identities, &s3cfg.Identities)
+	_ = copier.Copy(&s3config.Groups, &s3cfg.Groups)
+	_ = copier.Copy(&s3config.Roles, &s3cfg.Roles)
+	_ = copier.Copy(&s3config.Policies, &s3cfg.Policies)
 	return nil
 }
 
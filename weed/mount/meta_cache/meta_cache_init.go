@@ -59,7 +59,8 @@ func doEnsureVisited(mc *MetaCache, client filer_pb.FilerClient, path util.FullP
 
 	if err != nil {
 		err = fmt.Errorf("list %s: %v", path, err)
-	} else {
+	} else if path.Name() != filer.SnapshotsDirName {
+		// snapshots are created without changing the virtual directory, so it is listed again
 		mc.markCachedFn(path)
 	}
 	return err
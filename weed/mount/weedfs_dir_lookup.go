@@ -35,7 +35,8 @@ func (wfs *WFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name strin
 		return fuse.EIO
 	}
 	localEntry, cacheErr := wfs.metaCache.FindEntry(context.Background(), fullFilePath)
-	if cacheErr == filer_pb.ErrNotFound {
+	if cacheErr == filer_pb.ErrNotFound && name != filer.SnapshotsDirName {
+		// the virtual snapshots directory is not listed in its parent directory
 		return fuse.ENOENT
 	}
 
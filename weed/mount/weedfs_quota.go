@@ -3,11 +3,24 @@ package mount
 import (
 	"context"
 	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
-	"time"
 )
 
+// writeErrorStatus reports writes rejected by a filer directory quota as no space left
+func writeErrorStatus(err error) fuse.Status {
+	if strings.Contains(err.Error(), filer.ErrQuotaExceeded.Error()) {
+		return fuse.Status(syscall.ENOSPC)
+	}
+	return fuse.EIO
+}
+
 func (wfs *WFS) loopCheckQuota() {
 
 	for {
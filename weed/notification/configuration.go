@@ -1,6 +1,8 @@
 package notification
 
 import (
+	"reflect"
+
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 	"google.golang.org/protobuf/proto"
@@ -14,6 +16,12 @@ type MessageQueue interface {
 	SendMessage(key string, message proto.Message) error
 }
 
+// RawMessageQueue is implemented by the message queues that can also send
+// an already serialized message, e.g. an S3 event notification in JSON
+type RawMessageQueue interface {
+	SendRawMessage(key string, body []byte) error
+}
+
 var (
 	MessageQueues []MessageQueue
 
@@ -54,3 +62,14 @@ func validateOneEnabledQueue(config *util.ViperProxy) {
 		}
 	}
 }
+
+// NewMessageQueue creates an uninitialized message queue of the named type,
+// for components that send to several queues of their own
+func NewMessageQueue(name string) MessageQueue {
+	for _, queue := range MessageQueues {
+		if queue.GetName() == name {
+			return reflect.New(reflect.ValueOf(queue).Elem().Type()).Interface().(MessageQueue)
+		}
+	}
+	return nil
+}
@@ -74,6 +74,11 @@ func (k *GooglePubSub) SendMessage(key string, message proto.Message) (err error
 		return
 	}
 
+	return k.SendRawMessage(key, bytes)
+}
+
+func (k *GooglePubSub) SendRawMessage(key string, bytes []byte) (err error) {
+
 	ctx := context.Background()
 	result := k.topic.Publish(ctx, &pubsub.Message{
 		Data:       bytes,
@@ -29,21 +29,24 @@ func newHTTPClient(cfg *config) (*httpClient, error) {
 }
 
 func (h *httpClient) sendMessage(message *webhookMessage) error {
-	// Serialize the protobuf message to JSON for HTTP payload
-	notificationData, err := json.Marshal(message.Notification)
-	if err != nil {
-		return fmt.Errorf("failed to marshal notification: %w", err)
-	}
+	jsonData := message.Raw
+	if jsonData == nil {
+		// Serialize the protobuf message to JSON for HTTP payload
+		notificationData, err := json.Marshal(message.Notification)
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification: %w", err)
+		}
 
-	payload := map[string]interface{}{
-		"key":        message.Key,
-		"event_type": message.EventType,
-		"message":    json.RawMessage(notificationData),
-	}
+		payload := map[string]interface{}{
+			"key":        message.Key,
+			"event_type": message.EventType,
+			"message":    json.RawMessage(notificationData),
+		}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		jsonData, err = json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %w", err)
+		}
 	}
 
 	req, err := http.NewRequest(http.MethodPost, h.endpoint, bytes.NewBuffer(jsonData))
@@ -106,6 +106,32 @@ func TestHttpClientSendMessageWithoutToken(t *testing.T) {
 	}
 }
 
+func TestHttpClientSendRawMessage(t *testing.T) {
+	var receivedBody string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := newHTTPClient(&config{endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create HTTP client: %v", err)
+	}
+
+	raw := `{"Records":[]}`
+	err = client.sendMessage(&webhookMessage{Key: "/test/path", Raw: []byte(raw)})
+	if err != nil {
+		t.Fatalf("Failed to send message: %v", err)
+	}
+
+	if receivedBody != raw {
+		t.Errorf("Expected the raw message to be posted as is, got %s", receivedBody)
+	}
+}
+
 func TestHttpClientSendMessageServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
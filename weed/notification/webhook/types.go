@@ -51,6 +51,8 @@ type webhookMessage struct {
 	Key          string                      `json:"key"`
 	EventType    string                      `json:"event_type"`
 	Notification *filer_pb.EventNotification `json:"message_data"`
+	// Raw is an already serialized message, which is posted as is
+	Raw []byte `json:"-"`
 }
 
 func newWebhookMessage(key string, message proto.Message) *webhookMessage {
@@ -60,6 +60,14 @@ func (w *Queue) SendMessage(key string, msg proto.Message) error {
 	return w.queueChannel.Publish(pubSubTopicName, wMsg)
 }
 
+func (w *Queue) SendRawMessage(key string, body []byte) error {
+	msg := message.NewMessage(watermill.NewUUID(), body)
+	msg.Metadata.Set("key", key)
+	msg.Metadata.Set("raw", "true")
+
+	return w.queueChannel.Publish(pubSubTopicName, msg)
+}
+
 func (w *webhookMessage) toWaterMillMessage() (*message.Message, error) {
 	payload, err := proto.Marshal(w.Notification)
 	if err != nil {
@@ -165,17 +173,22 @@ func (w *Queue) setupWatermillQueue(cfg *config) error {
 }
 
 func (w *Queue) handleWebhook(msg *message.Message) error {
-	var n filer_pb.EventNotification
-	if err := proto.Unmarshal(msg.Payload, &n); err != nil {
-		glog.Errorf("failed to unmarshal protobuf message: %v", err)
-		return err
+	webhookMsg := &webhookMessage{
+		Key: msg.Metadata.Get("key"),
 	}
 
-	// Reconstruct webhook message from metadata and payload
-	webhookMsg := &webhookMessage{
-		Key:          msg.Metadata.Get("key"),
-		EventType:    msg.Metadata.Get("event_type"),
-		Notification: &n,
+	if msg.Metadata.Get("raw") == "true" {
+		webhookMsg.Raw = msg.Payload
+	} else {
+		var n filer_pb.EventNotification
+		if err := proto.Unmarshal(msg.Payload, &n); err != nil {
+			glog.Errorf("failed to unmarshal protobuf message: %v", err)
+			return err
+		}
+
+		// Reconstruct webhook message from metadata and payload
+		webhookMsg.EventType = msg.Metadata.Get("event_type")
+		webhookMsg.Notification = &n
 	}
 
 	if err := w.client.sendMessage(webhookMsg); err != nil {
@@ -211,7 +224,9 @@ func (w *Queue) logDeadLetterMessages() error {
 					}
 				}
 				payload := ""
-				if msg.Payload != nil {
+				if msg.Metadata.Get("raw") == "true" {
+					payload = string(msg.Payload)
+				} else if msg.Payload != nil {
 					var n filer_pb.EventNotification
 					if err := proto.Unmarshal(msg.Payload, &n); err != nil {
 						payload = fmt.Sprintf("failed to unmarshal payload: %v", err)
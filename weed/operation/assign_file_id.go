@@ -245,6 +245,7 @@ type StorageOption struct {
 	MaxFileNameLength uint32
 	Fsync             bool
 	SaveInside        bool
+	Dedup             bool
 }
 
 func (so *StorageOption) TtlString() string {
@@ -105,6 +105,7 @@ type S3CircuitBreakerConfig struct {
 	state         protoimpl.MessageState              `protogen:"open.v1"`
 	Global        *S3CircuitBreakerOptions            `protobuf:"bytes,1,opt,name=global,proto3" json:"global,omitempty"`
 	Buckets       map[string]*S3CircuitBreakerOptions `protobuf:"bytes,2,rep,name=buckets,proto3" json:"buckets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RateLimits    *S3RateLimitConfig                  `protobuf:"bytes,3,opt,name=rate_limits,json=rateLimits,proto3" json:"rate_limits,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -153,6 +154,13 @@ func (x *S3CircuitBreakerConfig) GetBuckets() map[string]*S3CircuitBreakerOption
 	return nil
 }
 
+func (x *S3CircuitBreakerConfig) GetRateLimits() *S3RateLimitConfig {
+	if x != nil {
+		return x.RateLimits
+	}
+	return nil
+}
+
 type S3CircuitBreakerOptions struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
@@ -205,6 +213,144 @@ func (x *S3CircuitBreakerOptions) GetActions() map[string]int64 {
 	return nil
 }
 
+// token bucket rate limits, the key "*" applies to every access key, bucket or source ip without its own limit
+type S3RateLimitConfig struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Enabled       bool                    `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	AccessKeys    map[string]*S3RateLimit `protobuf:"bytes,2,rep,name=access_keys,json=accessKeys,proto3" json:"access_keys,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Buckets       map[string]*S3RateLimit `protobuf:"bytes,3,rep,name=buckets,proto3" json:"buckets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	SourceIps     map[string]*S3RateLimit `protobuf:"bytes,4,rep,name=source_ips,json=sourceIps,proto3" json:"source_ips,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *S3RateLimitConfig) Reset() {
+	*x = S3RateLimitConfig{}
+	mi := &file_s3_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *S3RateLimitConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*S3RateLimitConfig) ProtoMessage() {}
+
+func (x *S3RateLimitConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use S3RateLimitConfig.ProtoReflect.Descriptor instead.
+func (*S3RateLimitConfig) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *S3RateLimitConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *S3RateLimitConfig) GetAccessKeys() map[string]*S3RateLimit {
+	if x != nil {
+		return x.AccessKeys
+	}
+	return nil
+}
+
+func (x *S3RateLimitConfig) GetBuckets() map[string]*S3RateLimit {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *S3RateLimitConfig) GetSourceIps() map[string]*S3RateLimit {
+	if x != nil {
+		return x.SourceIps
+	}
+	return nil
+}
+
+type S3RateLimit struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	RequestsPerSecond float64                `protobuf:"fixed64,1,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`
+	BytesPerSecond    int64                  `protobuf:"varint,2,opt,name=bytes_per_second,json=bytesPerSecond,proto3" json:"bytes_per_second,omitempty"`
+	// bursts default to one second of the rate
+	BurstRequests int64 `protobuf:"varint,3,opt,name=burst_requests,json=burstRequests,proto3" json:"burst_requests,omitempty"`
+	BurstBytes    int64 `protobuf:"varint,4,opt,name=burst_bytes,json=burstBytes,proto3" json:"burst_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *S3RateLimit) Reset() {
+	*x = S3RateLimit{}
+	mi := &file_s3_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *S3RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*S3RateLimit) ProtoMessage() {}
+
+func (x *S3RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use S3RateLimit.ProtoReflect.Descriptor instead.
+func (*S3RateLimit) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *S3RateLimit) GetRequestsPerSecond() float64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *S3RateLimit) GetBytesPerSecond() int64 {
+	if x != nil {
+		return x.BytesPerSecond
+	}
+	return 0
+}
+
+func (x *S3RateLimit) GetBurstRequests() int64 {
+	if x != nil {
+		return x.BurstRequests
+	}
+	return 0
+}
+
+func (x *S3RateLimit) GetBurstBytes() int64 {
+	if x != nil {
+		return x.BurstBytes
+	}
+	return 0
+}
+
 type CORSRule struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	AllowedHeaders []string               `protobuf:"bytes,1,rep,name=allowed_headers,json=allowedHeaders,proto3" json:"allowed_headers,omitempty"`
@@ -219,7 +365,7 @@ type CORSRule struct {
 
 func (x *CORSRule) Reset() {
 	*x = CORSRule{}
-	mi := &file_s3_proto_msgTypes[4]
+	mi := &file_s3_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -231,7 +377,7 @@ func (x *CORSRule) String() string {
 func (*CORSRule) ProtoMessage() {}
 
 func (x *CORSRule) ProtoReflect() protoreflect.Message {
-	mi := &file_s3_proto_msgTypes[4]
+	mi := &file_s3_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -244,7 +390,7 @@ func (x *CORSRule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CORSRule.ProtoReflect.Descriptor instead.
 func (*CORSRule) Descriptor() ([]byte, []int) {
-	return file_s3_proto_rawDescGZIP(), []int{4}
+	return file_s3_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CORSRule) GetAllowedHeaders() []string {
@@ -298,7 +444,7 @@ type CORSConfiguration struct {
 
 func (x *CORSConfiguration) Reset() {
 	*x = CORSConfiguration{}
-	mi := &file_s3_proto_msgTypes[5]
+	mi := &file_s3_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -310,7 +456,7 @@ func (x *CORSConfiguration) String() string {
 func (*CORSConfiguration) ProtoMessage() {}
 
 func (x *CORSConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_s3_proto_msgTypes[5]
+	mi := &file_s3_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -323,7 +469,7 @@ func (x *CORSConfiguration) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CORSConfiguration.ProtoReflect.Descriptor instead.
 func (*CORSConfiguration) Descriptor() ([]byte, []int) {
-	return file_s3_proto_rawDescGZIP(), []int{5}
+	return file_s3_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CORSConfiguration) GetCorsRules() []*CORSRule {
@@ -334,17 +480,24 @@ func (x *CORSConfiguration) GetCorsRules() []*CORSRule {
 }
 
 type BucketMetadata struct {
-	state         protoimpl.MessageState   `protogen:"open.v1"`
-	Tags          map[string]string        `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Cors          *CORSConfiguration       `protobuf:"bytes,2,opt,name=cors,proto3" json:"cors,omitempty"`
-	Encryption    *EncryptionConfiguration `protobuf:"bytes,3,opt,name=encryption,proto3" json:"encryption,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState       `protogen:"open.v1"`
+	Tags           map[string]string            `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Cors           *CORSConfiguration           `protobuf:"bytes,2,opt,name=cors,proto3" json:"cors,omitempty"`
+	Encryption     *EncryptionConfiguration     `protobuf:"bytes,3,opt,name=encryption,proto3" json:"encryption,omitempty"`
+	Lifecycle      *LifecycleConfiguration      `protobuf:"bytes,4,opt,name=lifecycle,proto3" json:"lifecycle,omitempty"`
+	Replication    *ReplicationConfiguration    `protobuf:"bytes,5,opt,name=replication,proto3" json:"replication,omitempty"`
+	Notification   *NotificationConfiguration   `protobuf:"bytes,6,opt,name=notification,proto3" json:"notification,omitempty"`
+	Inventory      *InventoryConfigurations     `protobuf:"bytes,7,opt,name=inventory,proto3" json:"inventory,omitempty"`
+	Logging        *LoggingConfiguration        `protobuf:"bytes,8,opt,name=logging,proto3" json:"logging,omitempty"`
+	Website        *WebsiteConfiguration        `protobuf:"bytes,9,opt,name=website,proto3" json:"website,omitempty"`
+	Transformation *TransformationConfiguration `protobuf:"bytes,10,opt,name=transformation,proto3" json:"transformation,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *BucketMetadata) Reset() {
 	*x = BucketMetadata{}
-	mi := &file_s3_proto_msgTypes[6]
+	mi := &file_s3_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -356,7 +509,7 @@ func (x *BucketMetadata) String() string {
 func (*BucketMetadata) ProtoMessage() {}
 
 func (x *BucketMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_s3_proto_msgTypes[6]
+	mi := &file_s3_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -369,7 +522,7 @@ func (x *BucketMetadata) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BucketMetadata.ProtoReflect.Descriptor instead.
 func (*BucketMetadata) Descriptor() ([]byte, []int) {
-	return file_s3_proto_rawDescGZIP(), []int{6}
+	return file_s3_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *BucketMetadata) GetTags() map[string]string {
@@ -393,6 +546,55 @@ func (x *BucketMetadata) GetEncryption() *EncryptionConfiguration {
 	return nil
 }
 
+func (x *BucketMetadata) GetLifecycle() *LifecycleConfiguration {
+	if x != nil {
+		return x.Lifecycle
+	}
+	return nil
+}
+
+func (x *BucketMetadata) GetReplication() *ReplicationConfiguration {
+	if x != nil {
+		return x.Replication
+	}
+	return nil
+}
+
+func (x *BucketMetadata) GetNotification() *NotificationConfiguration {
+	if x != nil {
+		return x.Notification
+	}
+	return nil
+}
+
+func (x *BucketMetadata) GetInventory() *InventoryConfigurations {
+	if x != nil {
+		return x.Inventory
+	}
+	return nil
+}
+
+func (x *BucketMetadata) GetLogging() *LoggingConfiguration {
+	if x != nil {
+		return x.Logging
+	}
+	return nil
+}
+
+func (x *BucketMetadata) GetWebsite() *WebsiteConfiguration {
+	if x != nil {
+		return x.Website
+	}
+	return nil
+}
+
+func (x *BucketMetadata) GetTransformation() *TransformationConfiguration {
+	if x != nil {
+		return x.Transformation
+	}
+	return nil
+}
+
 type EncryptionConfiguration struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	SseAlgorithm     string                 `protobuf:"bytes,1,opt,name=sse_algorithm,json=sseAlgorithm,proto3" json:"sse_algorithm,omitempty"`                // "AES256" or "aws:kms"
@@ -404,7 +606,7 @@ type EncryptionConfiguration struct {
 
 func (x *EncryptionConfiguration) Reset() {
 	*x = EncryptionConfiguration{}
-	mi := &file_s3_proto_msgTypes[7]
+	mi := &file_s3_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -416,7 +618,7 @@ func (x *EncryptionConfiguration) String() string {
 func (*EncryptionConfiguration) ProtoMessage() {}
 
 func (x *EncryptionConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_s3_proto_msgTypes[7]
+	mi := &file_s3_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -429,7 +631,7 @@ func (x *EncryptionConfiguration) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EncryptionConfiguration.ProtoReflect.Descriptor instead.
 func (*EncryptionConfiguration) Descriptor() ([]byte, []int) {
-	return file_s3_proto_rawDescGZIP(), []int{7}
+	return file_s3_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *EncryptionConfiguration) GetSseAlgorithm() string {
@@ -453,50 +655,1384 @@ func (x *EncryptionConfiguration) GetBucketKeyEnabled() bool {
 	return false
 }
 
-var File_s3_proto protoreflect.FileDescriptor
+type LifecycleFilter struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Prefix                string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Tags                  map[string]string      `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ObjectSizeGreaterThan int64                  `protobuf:"varint,3,opt,name=object_size_greater_than,json=objectSizeGreaterThan,proto3" json:"object_size_greater_than,omitempty"`
+	ObjectSizeLessThan    int64                  `protobuf:"varint,4,opt,name=object_size_less_than,json=objectSizeLessThan,proto3" json:"object_size_less_than,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
 
-const file_s3_proto_rawDesc = "" +
-	"\n" +
-	"\bs3.proto\x12\fmessaging_pb\"W\n" +
-	"\x12S3ConfigureRequest\x12A\n" +
-	"\x1ds3_configuration_file_content\x18\x01 \x01(\fR\x1as3ConfigurationFileContent\"\x15\n" +
-	"\x13S3ConfigureResponse\"\x87\x02\n" +
-	"\x16S3CircuitBreakerConfig\x12=\n" +
-	"\x06global\x18\x01 \x01(\v2%.messaging_pb.S3CircuitBreakerOptionsR\x06global\x12K\n" +
-	"\abuckets\x18\x02 \x03(\v21.messaging_pb.S3CircuitBreakerConfig.BucketsEntryR\abuckets\x1aa\n" +
-	"\fBucketsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12;\n" +
-	"\x05value\x18\x02 \x01(\v2%.messaging_pb.S3CircuitBreakerOptionsR\x05value:\x028\x01\"\xbd\x01\n" +
-	"\x17S3CircuitBreakerOptions\x12\x18\n" +
-	"\aenabled\x18\x01 \x01(\bR\aenabled\x12L\n" +
-	"\aactions\x18\x02 \x03(\v22.messaging_pb.S3CircuitBreakerOptions.ActionsEntryR\aactions\x1a:\n" +
-	"\fActionsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xe4\x01\n" +
-	"\bCORSRule\x12'\n" +
-	"\x0fallowed_headers\x18\x01 \x03(\tR\x0eallowedHeaders\x12'\n" +
-	"\x0fallowed_methods\x18\x02 \x03(\tR\x0eallowedMethods\x12'\n" +
-	"\x0fallowed_origins\x18\x03 \x03(\tR\x0eallowedOrigins\x12%\n" +
-	"\x0eexpose_headers\x18\x04 \x03(\tR\rexposeHeaders\x12&\n" +
-	"\x0fmax_age_seconds\x18\x05 \x01(\x05R\rmaxAgeSeconds\x12\x0e\n" +
-	"\x02id\x18\x06 \x01(\tR\x02id\"J\n" +
-	"\x11CORSConfiguration\x125\n" +
-	"\n" +
-	"cors_rules\x18\x01 \x03(\v2\x16.messaging_pb.CORSRuleR\tcorsRules\"\x81\x02\n" +
-	"\x0eBucketMetadata\x12:\n" +
-	"\x04tags\x18\x01 \x03(\v2&.messaging_pb.BucketMetadata.TagsEntryR\x04tags\x123\n" +
-	"\x04cors\x18\x02 \x01(\v2\x1f.messaging_pb.CORSConfigurationR\x04cors\x12E\n" +
-	"\n" +
-	"encryption\x18\x03 \x01(\v2%.messaging_pb.EncryptionConfigurationR\n" +
-	"encryption\x1a7\n" +
-	"\tTagsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8a\x01\n" +
-	"\x17EncryptionConfiguration\x12#\n" +
-	"\rsse_algorithm\x18\x01 \x01(\tR\fsseAlgorithm\x12\x1c\n" +
-	"\n" +
-	"kms_key_id\x18\x02 \x01(\tR\bkmsKeyId\x12,\n" +
-	"\x12bucket_key_enabled\x18\x03 \x01(\bR\x10bucketKeyEnabled2_\n" +
+func (x *LifecycleFilter) Reset() {
+	*x = LifecycleFilter{}
+	mi := &file_s3_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LifecycleFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LifecycleFilter) ProtoMessage() {}
+
+func (x *LifecycleFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LifecycleFilter.ProtoReflect.Descriptor instead.
+func (*LifecycleFilter) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LifecycleFilter) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *LifecycleFilter) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *LifecycleFilter) GetObjectSizeGreaterThan() int64 {
+	if x != nil {
+		return x.ObjectSizeGreaterThan
+	}
+	return 0
+}
+
+func (x *LifecycleFilter) GetObjectSizeLessThan() int64 {
+	if x != nil {
+		return x.ObjectSizeLessThan
+	}
+	return 0
+}
+
+type LifecycleRule struct {
+	state                              protoimpl.MessageState `protogen:"open.v1"`
+	Id                                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Enabled                            bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Filter                             *LifecycleFilter       `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	ExpirationDays                     int32                  `protobuf:"varint,4,opt,name=expiration_days,json=expirationDays,proto3" json:"expiration_days,omitempty"`
+	ExpirationDate                     int64                  `protobuf:"varint,5,opt,name=expiration_date,json=expirationDate,proto3" json:"expiration_date,omitempty"` // unix seconds, 0 if not set
+	ExpiredObjectDeleteMarker          bool                   `protobuf:"varint,6,opt,name=expired_object_delete_marker,json=expiredObjectDeleteMarker,proto3" json:"expired_object_delete_marker,omitempty"`
+	NoncurrentVersionExpirationDays    int32                  `protobuf:"varint,7,opt,name=noncurrent_version_expiration_days,json=noncurrentVersionExpirationDays,proto3" json:"noncurrent_version_expiration_days,omitempty"`
+	NewerNoncurrentVersions            int32                  `protobuf:"varint,8,opt,name=newer_noncurrent_versions,json=newerNoncurrentVersions,proto3" json:"newer_noncurrent_versions,omitempty"`
+	AbortIncompleteMultipartUploadDays int32                  `protobuf:"varint,9,opt,name=abort_incomplete_multipart_upload_days,json=abortIncompleteMultipartUploadDays,proto3" json:"abort_incomplete_multipart_upload_days,omitempty"`
+	Transitions                        []*LifecycleTransition `protobuf:"bytes,10,rep,name=transitions,proto3" json:"transitions,omitempty"`
+	NoncurrentVersionTransitions       []*LifecycleTransition `protobuf:"bytes,11,rep,name=noncurrent_version_transitions,json=noncurrentVersionTransitions,proto3" json:"noncurrent_version_transitions,omitempty"`
+	unknownFields                      protoimpl.UnknownFields
+	sizeCache                          protoimpl.SizeCache
+}
+
+func (x *LifecycleRule) Reset() {
+	*x = LifecycleRule{}
+	mi := &file_s3_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LifecycleRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LifecycleRule) ProtoMessage() {}
+
+func (x *LifecycleRule) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LifecycleRule.ProtoReflect.Descriptor instead.
+func (*LifecycleRule) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LifecycleRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *LifecycleRule) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *LifecycleRule) GetFilter() *LifecycleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *LifecycleRule) GetExpirationDays() int32 {
+	if x != nil {
+		return x.ExpirationDays
+	}
+	return 0
+}
+
+func (x *LifecycleRule) GetExpirationDate() int64 {
+	if x != nil {
+		return x.ExpirationDate
+	}
+	return 0
+}
+
+func (x *LifecycleRule) GetExpiredObjectDeleteMarker() bool {
+	if x != nil {
+		return x.ExpiredObjectDeleteMarker
+	}
+	return false
+}
+
+func (x *LifecycleRule) GetNoncurrentVersionExpirationDays() int32 {
+	if x != nil {
+		return x.NoncurrentVersionExpirationDays
+	}
+	return 0
+}
+
+func (x *LifecycleRule) GetNewerNoncurrentVersions() int32 {
+	if x != nil {
+		return x.NewerNoncurrentVersions
+	}
+	return 0
+}
+
+func (x *LifecycleRule) GetAbortIncompleteMultipartUploadDays() int32 {
+	if x != nil {
+		return x.AbortIncompleteMultipartUploadDays
+	}
+	return 0
+}
+
+func (x *LifecycleRule) GetTransitions() []*LifecycleTransition {
+	if x != nil {
+		return x.Transitions
+	}
+	return nil
+}
+
+func (x *LifecycleRule) GetNoncurrentVersionTransitions() []*LifecycleTransition {
+	if x != nil {
+		return x.NoncurrentVersionTransitions
+	}
+	return nil
+}
+
+type LifecycleTransition struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Days                    int32                  `protobuf:"varint,1,opt,name=days,proto3" json:"days,omitempty"` // noncurrent days for noncurrent version transitions
+	Date                    int64                  `protobuf:"varint,2,opt,name=date,proto3" json:"date,omitempty"` // unix seconds, 0 if not set
+	StorageClass            string                 `protobuf:"bytes,3,opt,name=storage_class,json=storageClass,proto3" json:"storage_class,omitempty"`
+	NewerNoncurrentVersions int32                  `protobuf:"varint,4,opt,name=newer_noncurrent_versions,json=newerNoncurrentVersions,proto3" json:"newer_noncurrent_versions,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *LifecycleTransition) Reset() {
+	*x = LifecycleTransition{}
+	mi := &file_s3_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LifecycleTransition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LifecycleTransition) ProtoMessage() {}
+
+func (x *LifecycleTransition) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LifecycleTransition.ProtoReflect.Descriptor instead.
+func (*LifecycleTransition) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *LifecycleTransition) GetDays() int32 {
+	if x != nil {
+		return x.Days
+	}
+	return 0
+}
+
+func (x *LifecycleTransition) GetDate() int64 {
+	if x != nil {
+		return x.Date
+	}
+	return 0
+}
+
+func (x *LifecycleTransition) GetStorageClass() string {
+	if x != nil {
+		return x.StorageClass
+	}
+	return ""
+}
+
+func (x *LifecycleTransition) GetNewerNoncurrentVersions() int32 {
+	if x != nil {
+		return x.NewerNoncurrentVersions
+	}
+	return 0
+}
+
+type LifecycleConfiguration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*LifecycleRule       `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LifecycleConfiguration) Reset() {
+	*x = LifecycleConfiguration{}
+	mi := &file_s3_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LifecycleConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LifecycleConfiguration) ProtoMessage() {}
+
+func (x *LifecycleConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LifecycleConfiguration.ProtoReflect.Descriptor instead.
+func (*LifecycleConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *LifecycleConfiguration) GetRules() []*LifecycleRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type ReplicationFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prefix        string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Tags          map[string]string      `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationFilter) Reset() {
+	*x = ReplicationFilter{}
+	mi := &file_s3_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationFilter) ProtoMessage() {}
+
+func (x *ReplicationFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationFilter.ProtoReflect.Descriptor instead.
+func (*ReplicationFilter) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReplicationFilter) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ReplicationFilter) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ReplicationRule struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Id                      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Priority                int32                  `protobuf:"varint,2,opt,name=priority,proto3" json:"priority,omitempty"`
+	Enabled                 bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Filter                  *ReplicationFilter     `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	DeleteMarkerReplication bool                   `protobuf:"varint,5,opt,name=delete_marker_replication,json=deleteMarkerReplication,proto3" json:"delete_marker_replication,omitempty"`
+	DestinationBucket       string                 `protobuf:"bytes,6,opt,name=destination_bucket,json=destinationBucket,proto3" json:"destination_bucket,omitempty"` // bucket ARN, the region selects the replication target
+	DestinationStorageClass string                 `protobuf:"bytes,7,opt,name=destination_storage_class,json=destinationStorageClass,proto3" json:"destination_storage_class,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *ReplicationRule) Reset() {
+	*x = ReplicationRule{}
+	mi := &file_s3_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationRule) ProtoMessage() {}
+
+func (x *ReplicationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationRule.ProtoReflect.Descriptor instead.
+func (*ReplicationRule) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReplicationRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ReplicationRule) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *ReplicationRule) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ReplicationRule) GetFilter() *ReplicationFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ReplicationRule) GetDeleteMarkerReplication() bool {
+	if x != nil {
+		return x.DeleteMarkerReplication
+	}
+	return false
+}
+
+func (x *ReplicationRule) GetDestinationBucket() string {
+	if x != nil {
+		return x.DestinationBucket
+	}
+	return ""
+}
+
+func (x *ReplicationRule) GetDestinationStorageClass() string {
+	if x != nil {
+		return x.DestinationStorageClass
+	}
+	return ""
+}
+
+type ReplicationConfiguration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	Rules         []*ReplicationRule     `protobuf:"bytes,2,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationConfiguration) Reset() {
+	*x = ReplicationConfiguration{}
+	mi := &file_s3_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationConfiguration) ProtoMessage() {}
+
+func (x *ReplicationConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationConfiguration.ProtoReflect.Descriptor instead.
+func (*ReplicationConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ReplicationConfiguration) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ReplicationConfiguration) GetRules() []*ReplicationRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type NotificationRule struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DestinationArn string                 `protobuf:"bytes,2,opt,name=destination_arn,json=destinationArn,proto3" json:"destination_arn,omitempty"` // queue, topic or function ARN, the name selects the message queue
+	Events         []string               `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	Prefix         string                 `protobuf:"bytes,4,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Suffix         string                 `protobuf:"bytes,5,opt,name=suffix,proto3" json:"suffix,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *NotificationRule) Reset() {
+	*x = NotificationRule{}
+	mi := &file_s3_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationRule) ProtoMessage() {}
+
+func (x *NotificationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationRule.ProtoReflect.Descriptor instead.
+func (*NotificationRule) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *NotificationRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NotificationRule) GetDestinationArn() string {
+	if x != nil {
+		return x.DestinationArn
+	}
+	return ""
+}
+
+func (x *NotificationRule) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *NotificationRule) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *NotificationRule) GetSuffix() string {
+	if x != nil {
+		return x.Suffix
+	}
+	return ""
+}
+
+type NotificationConfiguration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*NotificationRule    `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationConfiguration) Reset() {
+	*x = NotificationConfiguration{}
+	mi := &file_s3_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationConfiguration) ProtoMessage() {}
+
+func (x *NotificationConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationConfiguration.ProtoReflect.Descriptor instead.
+func (*NotificationConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *NotificationConfiguration) GetRules() []*NotificationRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type InventoryConfiguration struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Enabled              bool                   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Prefix               string                 `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`                                                // filter prefix
+	DestinationBucket    string                 `protobuf:"bytes,4,opt,name=destination_bucket,json=destinationBucket,proto3" json:"destination_bucket,omitempty"` // bucket ARN
+	DestinationPrefix    string                 `protobuf:"bytes,5,opt,name=destination_prefix,json=destinationPrefix,proto3" json:"destination_prefix,omitempty"`
+	DestinationAccountId string                 `protobuf:"bytes,6,opt,name=destination_account_id,json=destinationAccountId,proto3" json:"destination_account_id,omitempty"`
+	Format               string                 `protobuf:"bytes,7,opt,name=format,proto3" json:"format,omitempty"` // "CSV" or "Parquet"
+	AllVersions          bool                   `protobuf:"varint,8,opt,name=all_versions,json=allVersions,proto3" json:"all_versions,omitempty"`
+	OptionalFields       []string               `protobuf:"bytes,9,rep,name=optional_fields,json=optionalFields,proto3" json:"optional_fields,omitempty"`
+	Frequency            string                 `protobuf:"bytes,10,opt,name=frequency,proto3" json:"frequency,omitempty"` // "Daily" or "Weekly"
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *InventoryConfiguration) Reset() {
+	*x = InventoryConfiguration{}
+	mi := &file_s3_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventoryConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryConfiguration) ProtoMessage() {}
+
+func (x *InventoryConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryConfiguration.ProtoReflect.Descriptor instead.
+func (*InventoryConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *InventoryConfiguration) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *InventoryConfiguration) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *InventoryConfiguration) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *InventoryConfiguration) GetDestinationBucket() string {
+	if x != nil {
+		return x.DestinationBucket
+	}
+	return ""
+}
+
+func (x *InventoryConfiguration) GetDestinationPrefix() string {
+	if x != nil {
+		return x.DestinationPrefix
+	}
+	return ""
+}
+
+func (x *InventoryConfiguration) GetDestinationAccountId() string {
+	if x != nil {
+		return x.DestinationAccountId
+	}
+	return ""
+}
+
+func (x *InventoryConfiguration) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *InventoryConfiguration) GetAllVersions() bool {
+	if x != nil {
+		return x.AllVersions
+	}
+	return false
+}
+
+func (x *InventoryConfiguration) GetOptionalFields() []string {
+	if x != nil {
+		return x.OptionalFields
+	}
+	return nil
+}
+
+func (x *InventoryConfiguration) GetFrequency() string {
+	if x != nil {
+		return x.Frequency
+	}
+	return ""
+}
+
+type InventoryConfigurations struct {
+	state          protoimpl.MessageState    `protogen:"open.v1"`
+	Configurations []*InventoryConfiguration `protobuf:"bytes,1,rep,name=configurations,proto3" json:"configurations,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *InventoryConfigurations) Reset() {
+	*x = InventoryConfigurations{}
+	mi := &file_s3_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InventoryConfigurations) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InventoryConfigurations) ProtoMessage() {}
+
+func (x *InventoryConfigurations) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InventoryConfigurations.ProtoReflect.Descriptor instead.
+func (*InventoryConfigurations) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *InventoryConfigurations) GetConfigurations() []*InventoryConfiguration {
+	if x != nil {
+		return x.Configurations
+	}
+	return nil
+}
+
+type LoggingConfiguration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetBucket  string                 `protobuf:"bytes,1,opt,name=target_bucket,json=targetBucket,proto3" json:"target_bucket,omitempty"`
+	TargetPrefix  string                 `protobuf:"bytes,2,opt,name=target_prefix,json=targetPrefix,proto3" json:"target_prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoggingConfiguration) Reset() {
+	*x = LoggingConfiguration{}
+	mi := &file_s3_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoggingConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoggingConfiguration) ProtoMessage() {}
+
+func (x *LoggingConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoggingConfiguration.ProtoReflect.Descriptor instead.
+func (*LoggingConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *LoggingConfiguration) GetTargetBucket() string {
+	if x != nil {
+		return x.TargetBucket
+	}
+	return ""
+}
+
+func (x *LoggingConfiguration) GetTargetPrefix() string {
+	if x != nil {
+		return x.TargetPrefix
+	}
+	return ""
+}
+
+type WebsiteRedirect struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	HostName             string                 `protobuf:"bytes,1,opt,name=host_name,json=hostName,proto3" json:"host_name,omitempty"`
+	Protocol             string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // "http" or "https", defaults to the protocol of the request
+	ReplaceKeyPrefixWith string                 `protobuf:"bytes,3,opt,name=replace_key_prefix_with,json=replaceKeyPrefixWith,proto3" json:"replace_key_prefix_with,omitempty"`
+	ReplaceKeyWith       string                 `protobuf:"bytes,4,opt,name=replace_key_with,json=replaceKeyWith,proto3" json:"replace_key_with,omitempty"`
+	HttpRedirectCode     string                 `protobuf:"bytes,5,opt,name=http_redirect_code,json=httpRedirectCode,proto3" json:"http_redirect_code,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *WebsiteRedirect) Reset() {
+	*x = WebsiteRedirect{}
+	mi := &file_s3_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebsiteRedirect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebsiteRedirect) ProtoMessage() {}
+
+func (x *WebsiteRedirect) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebsiteRedirect.ProtoReflect.Descriptor instead.
+func (*WebsiteRedirect) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *WebsiteRedirect) GetHostName() string {
+	if x != nil {
+		return x.HostName
+	}
+	return ""
+}
+
+func (x *WebsiteRedirect) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *WebsiteRedirect) GetReplaceKeyPrefixWith() string {
+	if x != nil {
+		return x.ReplaceKeyPrefixWith
+	}
+	return ""
+}
+
+func (x *WebsiteRedirect) GetReplaceKeyWith() string {
+	if x != nil {
+		return x.ReplaceKeyWith
+	}
+	return ""
+}
+
+func (x *WebsiteRedirect) GetHttpRedirectCode() string {
+	if x != nil {
+		return x.HttpRedirectCode
+	}
+	return ""
+}
+
+type WebsiteRoutingRule struct {
+	state                       protoimpl.MessageState `protogen:"open.v1"`
+	KeyPrefixEquals             string                 `protobuf:"bytes,1,opt,name=key_prefix_equals,json=keyPrefixEquals,proto3" json:"key_prefix_equals,omitempty"`
+	HttpErrorCodeReturnedEquals string                 `protobuf:"bytes,2,opt,name=http_error_code_returned_equals,json=httpErrorCodeReturnedEquals,proto3" json:"http_error_code_returned_equals,omitempty"`
+	Redirect                    *WebsiteRedirect       `protobuf:"bytes,3,opt,name=redirect,proto3" json:"redirect,omitempty"`
+	unknownFields               protoimpl.UnknownFields
+	sizeCache                   protoimpl.SizeCache
+}
+
+func (x *WebsiteRoutingRule) Reset() {
+	*x = WebsiteRoutingRule{}
+	mi := &file_s3_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebsiteRoutingRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebsiteRoutingRule) ProtoMessage() {}
+
+func (x *WebsiteRoutingRule) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebsiteRoutingRule.ProtoReflect.Descriptor instead.
+func (*WebsiteRoutingRule) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *WebsiteRoutingRule) GetKeyPrefixEquals() string {
+	if x != nil {
+		return x.KeyPrefixEquals
+	}
+	return ""
+}
+
+func (x *WebsiteRoutingRule) GetHttpErrorCodeReturnedEquals() string {
+	if x != nil {
+		return x.HttpErrorCodeReturnedEquals
+	}
+	return ""
+}
+
+func (x *WebsiteRoutingRule) GetRedirect() *WebsiteRedirect {
+	if x != nil {
+		return x.Redirect
+	}
+	return nil
+}
+
+type WebsiteConfiguration struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	IndexDocumentSuffix   string                 `protobuf:"bytes,1,opt,name=index_document_suffix,json=indexDocumentSuffix,proto3" json:"index_document_suffix,omitempty"`
+	ErrorDocumentKey      string                 `protobuf:"bytes,2,opt,name=error_document_key,json=errorDocumentKey,proto3" json:"error_document_key,omitempty"`
+	RedirectAllRequestsTo *WebsiteRedirect       `protobuf:"bytes,3,opt,name=redirect_all_requests_to,json=redirectAllRequestsTo,proto3" json:"redirect_all_requests_to,omitempty"`
+	RoutingRules          []*WebsiteRoutingRule  `protobuf:"bytes,4,rep,name=routing_rules,json=routingRules,proto3" json:"routing_rules,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *WebsiteConfiguration) Reset() {
+	*x = WebsiteConfiguration{}
+	mi := &file_s3_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebsiteConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebsiteConfiguration) ProtoMessage() {}
+
+func (x *WebsiteConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebsiteConfiguration.ProtoReflect.Descriptor instead.
+func (*WebsiteConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WebsiteConfiguration) GetIndexDocumentSuffix() string {
+	if x != nil {
+		return x.IndexDocumentSuffix
+	}
+	return ""
+}
+
+func (x *WebsiteConfiguration) GetErrorDocumentKey() string {
+	if x != nil {
+		return x.ErrorDocumentKey
+	}
+	return ""
+}
+
+func (x *WebsiteConfiguration) GetRedirectAllRequestsTo() *WebsiteRedirect {
+	if x != nil {
+		return x.RedirectAllRequestsTo
+	}
+	return nil
+}
+
+func (x *WebsiteConfiguration) GetRoutingRules() []*WebsiteRoutingRule {
+	if x != nil {
+		return x.RoutingRules
+	}
+	return nil
+}
+
+// a step of a transformation, "http", "resize", "crop", "decompress" or "redact"
+type TransformationStep struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Parameters    map[string]string      `protobuf:"bytes,2,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformationStep) Reset() {
+	*x = TransformationStep{}
+	mi := &file_s3_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformationStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformationStep) ProtoMessage() {}
+
+func (x *TransformationStep) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransformationStep.ProtoReflect.Descriptor instead.
+func (*TransformationStep) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *TransformationStep) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TransformationStep) GetParameters() map[string]string {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+type TransformationRule struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Prefix string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// only applies to GETs through the access point, otherwise to all GETs of the bucket
+	AccessPoint   string                `protobuf:"bytes,3,opt,name=access_point,json=accessPoint,proto3" json:"access_point,omitempty"`
+	Steps         []*TransformationStep `protobuf:"bytes,4,rep,name=steps,proto3" json:"steps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformationRule) Reset() {
+	*x = TransformationRule{}
+	mi := &file_s3_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformationRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformationRule) ProtoMessage() {}
+
+func (x *TransformationRule) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransformationRule.ProtoReflect.Descriptor instead.
+func (*TransformationRule) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TransformationRule) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TransformationRule) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *TransformationRule) GetAccessPoint() string {
+	if x != nil {
+		return x.AccessPoint
+	}
+	return ""
+}
+
+func (x *TransformationRule) GetSteps() []*TransformationStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+type TransformationConfiguration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rules         []*TransformationRule  `protobuf:"bytes,1,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformationConfiguration) Reset() {
+	*x = TransformationConfiguration{}
+	mi := &file_s3_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformationConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformationConfiguration) ProtoMessage() {}
+
+func (x *TransformationConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_s3_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransformationConfiguration.ProtoReflect.Descriptor instead.
+func (*TransformationConfiguration) Descriptor() ([]byte, []int) {
+	return file_s3_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TransformationConfiguration) GetRules() []*TransformationRule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+var File_s3_proto protoreflect.FileDescriptor
+
+const file_s3_proto_rawDesc = "" +
+	"\n" +
+	"\bs3.proto\x12\fmessaging_pb\"W\n" +
+	"\x12S3ConfigureRequest\x12A\n" +
+	"\x1ds3_configuration_file_content\x18\x01 \x01(\fR\x1as3ConfigurationFileContent\"\x15\n" +
+	"\x13S3ConfigureResponse\"\xc9\x02\n" +
+	"\x16S3CircuitBreakerConfig\x12=\n" +
+	"\x06global\x18\x01 \x01(\v2%.messaging_pb.S3CircuitBreakerOptionsR\x06global\x12K\n" +
+	"\abuckets\x18\x02 \x03(\v21.messaging_pb.S3CircuitBreakerConfig.BucketsEntryR\abuckets\x12@\n" +
+	"\vrate_limits\x18\x03 \x01(\v2\x1f.messaging_pb.S3RateLimitConfigR\n" +
+	"rateLimits\x1aa\n" +
+	"\fBucketsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12;\n" +
+	"\x05value\x18\x02 \x01(\v2%.messaging_pb.S3CircuitBreakerOptionsR\x05value:\x028\x01\"\xbd\x01\n" +
+	"\x17S3CircuitBreakerOptions\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12L\n" +
+	"\aactions\x18\x02 \x03(\v22.messaging_pb.S3CircuitBreakerOptions.ActionsEntryR\aactions\x1a:\n" +
+	"\fActionsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"\xa0\x04\n" +
+	"\x11S3RateLimitConfig\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12P\n" +
+	"\vaccess_keys\x18\x02 \x03(\v2/.messaging_pb.S3RateLimitConfig.AccessKeysEntryR\n" +
+	"accessKeys\x12F\n" +
+	"\abuckets\x18\x03 \x03(\v2,.messaging_pb.S3RateLimitConfig.BucketsEntryR\abuckets\x12M\n" +
+	"\n" +
+	"source_ips\x18\x04 \x03(\v2..messaging_pb.S3RateLimitConfig.SourceIpsEntryR\tsourceIps\x1aX\n" +
+	"\x0fAccessKeysEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.messaging_pb.S3RateLimitR\x05value:\x028\x01\x1aU\n" +
+	"\fBucketsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.messaging_pb.S3RateLimitR\x05value:\x028\x01\x1aW\n" +
+	"\x0eSourceIpsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12/\n" +
+	"\x05value\x18\x02 \x01(\v2\x19.messaging_pb.S3RateLimitR\x05value:\x028\x01\"\xaf\x01\n" +
+	"\vS3RateLimit\x12.\n" +
+	"\x13requests_per_second\x18\x01 \x01(\x01R\x11requestsPerSecond\x12(\n" +
+	"\x10bytes_per_second\x18\x02 \x01(\x03R\x0ebytesPerSecond\x12%\n" +
+	"\x0eburst_requests\x18\x03 \x01(\x03R\rburstRequests\x12\x1f\n" +
+	"\vburst_bytes\x18\x04 \x01(\x03R\n" +
+	"burstBytes\"\xe4\x01\n" +
+	"\bCORSRule\x12'\n" +
+	"\x0fallowed_headers\x18\x01 \x03(\tR\x0eallowedHeaders\x12'\n" +
+	"\x0fallowed_methods\x18\x02 \x03(\tR\x0eallowedMethods\x12'\n" +
+	"\x0fallowed_origins\x18\x03 \x03(\tR\x0eallowedOrigins\x12%\n" +
+	"\x0eexpose_headers\x18\x04 \x03(\tR\rexposeHeaders\x12&\n" +
+	"\x0fmax_age_seconds\x18\x05 \x01(\x05R\rmaxAgeSeconds\x12\x0e\n" +
+	"\x02id\x18\x06 \x01(\tR\x02id\"J\n" +
+	"\x11CORSConfiguration\x125\n" +
+	"\n" +
+	"cors_rules\x18\x01 \x03(\v2\x16.messaging_pb.CORSRuleR\tcorsRules\"\xf0\x05\n" +
+	"\x0eBucketMetadata\x12:\n" +
+	"\x04tags\x18\x01 \x03(\v2&.messaging_pb.BucketMetadata.TagsEntryR\x04tags\x123\n" +
+	"\x04cors\x18\x02 \x01(\v2\x1f.messaging_pb.CORSConfigurationR\x04cors\x12E\n" +
+	"\n" +
+	"encryption\x18\x03 \x01(\v2%.messaging_pb.EncryptionConfigurationR\n" +
+	"encryption\x12B\n" +
+	"\tlifecycle\x18\x04 \x01(\v2$.messaging_pb.LifecycleConfigurationR\tlifecycle\x12H\n" +
+	"\vreplication\x18\x05 \x01(\v2&.messaging_pb.ReplicationConfigurationR\vreplication\x12K\n" +
+	"\fnotification\x18\x06 \x01(\v2'.messaging_pb.NotificationConfigurationR\fnotification\x12C\n" +
+	"\tinventory\x18\a \x01(\v2%.messaging_pb.InventoryConfigurationsR\tinventory\x12<\n" +
+	"\alogging\x18\b \x01(\v2\".messaging_pb.LoggingConfigurationR\alogging\x12<\n" +
+	"\awebsite\x18\t \x01(\v2\".messaging_pb.WebsiteConfigurationR\awebsite\x12Q\n" +
+	"\x0etransformation\x18\n" +
+	" \x01(\v2).messaging_pb.TransformationConfigurationR\x0etransformation\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8a\x01\n" +
+	"\x17EncryptionConfiguration\x12#\n" +
+	"\rsse_algorithm\x18\x01 \x01(\tR\fsseAlgorithm\x12\x1c\n" +
+	"\n" +
+	"kms_key_id\x18\x02 \x01(\tR\bkmsKeyId\x12,\n" +
+	"\x12bucket_key_enabled\x18\x03 \x01(\bR\x10bucketKeyEnabled\"\x8b\x02\n" +
+	"\x0fLifecycleFilter\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12;\n" +
+	"\x04tags\x18\x02 \x03(\v2'.messaging_pb.LifecycleFilter.TagsEntryR\x04tags\x127\n" +
+	"\x18object_size_greater_than\x18\x03 \x01(\x03R\x15objectSizeGreaterThan\x121\n" +
+	"\x15object_size_less_than\x18\x04 \x01(\x03R\x12objectSizeLessThan\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8e\x05\n" +
+	"\rLifecycleRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x125\n" +
+	"\x06filter\x18\x03 \x01(\v2\x1d.messaging_pb.LifecycleFilterR\x06filter\x12'\n" +
+	"\x0fexpiration_days\x18\x04 \x01(\x05R\x0eexpirationDays\x12'\n" +
+	"\x0fexpiration_date\x18\x05 \x01(\x03R\x0eexpirationDate\x12?\n" +
+	"\x1cexpired_object_delete_marker\x18\x06 \x01(\bR\x19expiredObjectDeleteMarker\x12K\n" +
+	"\"noncurrent_version_expiration_days\x18\a \x01(\x05R\x1fnoncurrentVersionExpirationDays\x12:\n" +
+	"\x19newer_noncurrent_versions\x18\b \x01(\x05R\x17newerNoncurrentVersions\x12R\n" +
+	"&abort_incomplete_multipart_upload_days\x18\t \x01(\x05R\"abortIncompleteMultipartUploadDays\x12C\n" +
+	"\vtransitions\x18\n" +
+	" \x03(\v2!.messaging_pb.LifecycleTransitionR\vtransitions\x12g\n" +
+	"\x1enoncurrent_version_transitions\x18\v \x03(\v2!.messaging_pb.LifecycleTransitionR\x1cnoncurrentVersionTransitions\"\x9e\x01\n" +
+	"\x13LifecycleTransition\x12\x12\n" +
+	"\x04days\x18\x01 \x01(\x05R\x04days\x12\x12\n" +
+	"\x04date\x18\x02 \x01(\x03R\x04date\x12#\n" +
+	"\rstorage_class\x18\x03 \x01(\tR\fstorageClass\x12:\n" +
+	"\x19newer_noncurrent_versions\x18\x04 \x01(\x05R\x17newerNoncurrentVersions\"K\n" +
+	"\x16LifecycleConfiguration\x121\n" +
+	"\x05rules\x18\x01 \x03(\v2\x1b.messaging_pb.LifecycleRuleR\x05rules\"\xa3\x01\n" +
+	"\x11ReplicationFilter\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12=\n" +
+	"\x04tags\x18\x02 \x03(\v2).messaging_pb.ReplicationFilter.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb7\x02\n" +
+	"\x0fReplicationRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\bpriority\x18\x02 \x01(\x05R\bpriority\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\x127\n" +
+	"\x06filter\x18\x04 \x01(\v2\x1f.messaging_pb.ReplicationFilterR\x06filter\x12:\n" +
+	"\x19delete_marker_replication\x18\x05 \x01(\bR\x17deleteMarkerReplication\x12-\n" +
+	"\x12destination_bucket\x18\x06 \x01(\tR\x11destinationBucket\x12:\n" +
+	"\x19destination_storage_class\x18\a \x01(\tR\x17destinationStorageClass\"c\n" +
+	"\x18ReplicationConfiguration\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x123\n" +
+	"\x05rules\x18\x02 \x03(\v2\x1d.messaging_pb.ReplicationRuleR\x05rules\"\x93\x01\n" +
+	"\x10NotificationRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0fdestination_arn\x18\x02 \x01(\tR\x0edestinationArn\x12\x16\n" +
+	"\x06events\x18\x03 \x03(\tR\x06events\x12\x16\n" +
+	"\x06prefix\x18\x04 \x01(\tR\x06prefix\x12\x16\n" +
+	"\x06suffix\x18\x05 \x01(\tR\x06suffix\"Q\n" +
+	"\x19NotificationConfiguration\x124\n" +
+	"\x05rules\x18\x01 \x03(\v2\x1e.messaging_pb.NotificationRuleR\x05rules\"\xf0\x02\n" +
+	"\x16InventoryConfiguration\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\x12\x16\n" +
+	"\x06prefix\x18\x03 \x01(\tR\x06prefix\x12-\n" +
+	"\x12destination_bucket\x18\x04 \x01(\tR\x11destinationBucket\x12-\n" +
+	"\x12destination_prefix\x18\x05 \x01(\tR\x11destinationPrefix\x124\n" +
+	"\x16destination_account_id\x18\x06 \x01(\tR\x14destinationAccountId\x12\x16\n" +
+	"\x06format\x18\a \x01(\tR\x06format\x12!\n" +
+	"\fall_versions\x18\b \x01(\bR\vallVersions\x12'\n" +
+	"\x0foptional_fields\x18\t \x03(\tR\x0eoptionalFields\x12\x1c\n" +
+	"\tfrequency\x18\n" +
+	" \x01(\tR\tfrequency\"g\n" +
+	"\x17InventoryConfigurations\x12L\n" +
+	"\x0econfigurations\x18\x01 \x03(\v2$.messaging_pb.InventoryConfigurationR\x0econfigurations\"`\n" +
+	"\x14LoggingConfiguration\x12#\n" +
+	"\rtarget_bucket\x18\x01 \x01(\tR\ftargetBucket\x12#\n" +
+	"\rtarget_prefix\x18\x02 \x01(\tR\ftargetPrefix\"\xd9\x01\n" +
+	"\x0fWebsiteRedirect\x12\x1b\n" +
+	"\thost_name\x18\x01 \x01(\tR\bhostName\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x125\n" +
+	"\x17replace_key_prefix_with\x18\x03 \x01(\tR\x14replaceKeyPrefixWith\x12(\n" +
+	"\x10replace_key_with\x18\x04 \x01(\tR\x0ereplaceKeyWith\x12,\n" +
+	"\x12http_redirect_code\x18\x05 \x01(\tR\x10httpRedirectCode\"\xc1\x01\n" +
+	"\x12WebsiteRoutingRule\x12*\n" +
+	"\x11key_prefix_equals\x18\x01 \x01(\tR\x0fkeyPrefixEquals\x12D\n" +
+	"\x1fhttp_error_code_returned_equals\x18\x02 \x01(\tR\x1bhttpErrorCodeReturnedEquals\x129\n" +
+	"\bredirect\x18\x03 \x01(\v2\x1d.messaging_pb.WebsiteRedirectR\bredirect\"\x97\x02\n" +
+	"\x14WebsiteConfiguration\x122\n" +
+	"\x15index_document_suffix\x18\x01 \x01(\tR\x13indexDocumentSuffix\x12,\n" +
+	"\x12error_document_key\x18\x02 \x01(\tR\x10errorDocumentKey\x12V\n" +
+	"\x18redirect_all_requests_to\x18\x03 \x01(\v2\x1d.messaging_pb.WebsiteRedirectR\x15redirectAllRequestsTo\x12E\n" +
+	"\rrouting_rules\x18\x04 \x03(\v2 .messaging_pb.WebsiteRoutingRuleR\froutingRules\"\xb9\x01\n" +
+	"\x12TransformationStep\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12P\n" +
+	"\n" +
+	"parameters\x18\x02 \x03(\v20.messaging_pb.TransformationStep.ParametersEntryR\n" +
+	"parameters\x1a=\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x97\x01\n" +
+	"\x12TransformationRule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x12!\n" +
+	"\faccess_point\x18\x03 \x01(\tR\vaccessPoint\x126\n" +
+	"\x05steps\x18\x04 \x03(\v2 .messaging_pb.TransformationStepR\x05steps\"U\n" +
+	"\x1bTransformationConfiguration\x126\n" +
+	"\x05rules\x18\x01 \x03(\v2 .messaging_pb.TransformationRuleR\x05rules2_\n" +
 	"\tSeaweedS3\x12R\n" +
 	"\tConfigure\x12 .messaging_pb.S3ConfigureRequest\x1a!.messaging_pb.S3ConfigureResponse\"\x00BI\n" +
 	"\x10seaweedfs.clientB\aS3ProtoZ,github.com/seaweedfs/seaweedfs/weed/pb/s3_pbb\x06proto3"
@@ -513,36 +2049,92 @@ func file_s3_proto_rawDescGZIP() []byte {
 	return file_s3_proto_rawDescData
 }
 
-var file_s3_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_s3_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_s3_proto_goTypes = []any{
-	(*S3ConfigureRequest)(nil),      // 0: messaging_pb.S3ConfigureRequest
-	(*S3ConfigureResponse)(nil),     // 1: messaging_pb.S3ConfigureResponse
-	(*S3CircuitBreakerConfig)(nil),  // 2: messaging_pb.S3CircuitBreakerConfig
-	(*S3CircuitBreakerOptions)(nil), // 3: messaging_pb.S3CircuitBreakerOptions
-	(*CORSRule)(nil),                // 4: messaging_pb.CORSRule
-	(*CORSConfiguration)(nil),       // 5: messaging_pb.CORSConfiguration
-	(*BucketMetadata)(nil),          // 6: messaging_pb.BucketMetadata
-	(*EncryptionConfiguration)(nil), // 7: messaging_pb.EncryptionConfiguration
-	nil,                             // 8: messaging_pb.S3CircuitBreakerConfig.BucketsEntry
-	nil,                             // 9: messaging_pb.S3CircuitBreakerOptions.ActionsEntry
-	nil,                             // 10: messaging_pb.BucketMetadata.TagsEntry
+	(*S3ConfigureRequest)(nil),          // 0: messaging_pb.S3ConfigureRequest
+	(*S3ConfigureResponse)(nil),         // 1: messaging_pb.S3ConfigureResponse
+	(*S3CircuitBreakerConfig)(nil),      // 2: messaging_pb.S3CircuitBreakerConfig
+	(*S3CircuitBreakerOptions)(nil),     // 3: messaging_pb.S3CircuitBreakerOptions
+	(*S3RateLimitConfig)(nil),           // 4: messaging_pb.S3RateLimitConfig
+	(*S3RateLimit)(nil),                 // 5: messaging_pb.S3RateLimit
+	(*CORSRule)(nil),                    // 6: messaging_pb.CORSRule
+	(*CORSConfiguration)(nil),           // 7: messaging_pb.CORSConfiguration
+	(*BucketMetadata)(nil),              // 8: messaging_pb.BucketMetadata
+	(*EncryptionConfiguration)(nil),     // 9: messaging_pb.EncryptionConfiguration
+	(*LifecycleFilter)(nil),             // 10: messaging_pb.LifecycleFilter
+	(*LifecycleRule)(nil),               // 11: messaging_pb.LifecycleRule
+	(*LifecycleTransition)(nil),         // 12: messaging_pb.LifecycleTransition
+	(*LifecycleConfiguration)(nil),      // 13: messaging_pb.LifecycleConfiguration
+	(*ReplicationFilter)(nil),           // 14: messaging_pb.ReplicationFilter
+	(*ReplicationRule)(nil),             // 15: messaging_pb.ReplicationRule
+	(*ReplicationConfiguration)(nil),    // 16: messaging_pb.ReplicationConfiguration
+	(*NotificationRule)(nil),            // 17: messaging_pb.NotificationRule
+	(*NotificationConfiguration)(nil),   // 18: messaging_pb.NotificationConfiguration
+	(*InventoryConfiguration)(nil),      // 19: messaging_pb.InventoryConfiguration
+	(*InventoryConfigurations)(nil),     // 20: messaging_pb.InventoryConfigurations
+	(*LoggingConfiguration)(nil),        // 21: messaging_pb.LoggingConfiguration
+	(*WebsiteRedirect)(nil),             // 22: messaging_pb.WebsiteRedirect
+	(*WebsiteRoutingRule)(nil),          // 23: messaging_pb.WebsiteRoutingRule
+	(*WebsiteConfiguration)(nil),        // 24: messaging_pb.WebsiteConfiguration
+	(*TransformationStep)(nil),          // 25: messaging_pb.TransformationStep
+	(*TransformationRule)(nil),          // 26: messaging_pb.TransformationRule
+	(*TransformationConfiguration)(nil), // 27: messaging_pb.TransformationConfiguration
+	nil,                                 // 28: messaging_pb.S3CircuitBreakerConfig.BucketsEntry
+	nil,                                 // 29: messaging_pb.S3CircuitBreakerOptions.ActionsEntry
+	nil,                                 // 30: messaging_pb.S3RateLimitConfig.AccessKeysEntry
+	nil,                                 // 31: messaging_pb.S3RateLimitConfig.BucketsEntry
+	nil,                                 // 32: messaging_pb.S3RateLimitConfig.SourceIpsEntry
+	nil,                                 // 33: messaging_pb.BucketMetadata.TagsEntry
+	nil,                                 // 34: messaging_pb.LifecycleFilter.TagsEntry
+	nil,                                 // 35: messaging_pb.ReplicationFilter.TagsEntry
+	nil,                                 // 36: messaging_pb.TransformationStep.ParametersEntry
 }
 var file_s3_proto_depIdxs = []int32{
 	3,  // 0: messaging_pb.S3CircuitBreakerConfig.global:type_name -> messaging_pb.S3CircuitBreakerOptions
-	8,  // 1: messaging_pb.S3CircuitBreakerConfig.buckets:type_name -> messaging_pb.S3CircuitBreakerConfig.BucketsEntry
-	9,  // 2: messaging_pb.S3CircuitBreakerOptions.actions:type_name -> messaging_pb.S3CircuitBreakerOptions.ActionsEntry
-	4,  // 3: messaging_pb.CORSConfiguration.cors_rules:type_name -> messaging_pb.CORSRule
-	10, // 4: messaging_pb.BucketMetadata.tags:type_name -> messaging_pb.BucketMetadata.TagsEntry
-	5,  // 5: messaging_pb.BucketMetadata.cors:type_name -> messaging_pb.CORSConfiguration
-	7,  // 6: messaging_pb.BucketMetadata.encryption:type_name -> messaging_pb.EncryptionConfiguration
-	3,  // 7: messaging_pb.S3CircuitBreakerConfig.BucketsEntry.value:type_name -> messaging_pb.S3CircuitBreakerOptions
-	0,  // 8: messaging_pb.SeaweedS3.Configure:input_type -> messaging_pb.S3ConfigureRequest
-	1,  // 9: messaging_pb.SeaweedS3.Configure:output_type -> messaging_pb.S3ConfigureResponse
-	9,  // [9:10] is the sub-list for method output_type
-	8,  // [8:9] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	28, // 1: messaging_pb.S3CircuitBreakerConfig.buckets:type_name -> messaging_pb.S3CircuitBreakerConfig.BucketsEntry
+	4,  // 2: messaging_pb.S3CircuitBreakerConfig.rate_limits:type_name -> messaging_pb.S3RateLimitConfig
+	29, // 3: messaging_pb.S3CircuitBreakerOptions.actions:type_name -> messaging_pb.S3CircuitBreakerOptions.ActionsEntry
+	30, // 4: messaging_pb.S3RateLimitConfig.access_keys:type_name -> messaging_pb.S3RateLimitConfig.AccessKeysEntry
+	31, // 5: messaging_pb.S3RateLimitConfig.buckets:type_name -> messaging_pb.S3RateLimitConfig.BucketsEntry
+	32, // 6: messaging_pb.S3RateLimitConfig.source_ips:type_name -> messaging_pb.S3RateLimitConfig.SourceIpsEntry
+	6,  // 7: messaging_pb.CORSConfiguration.cors_rules:type_name -> messaging_pb.CORSRule
+	33, // 8: messaging_pb.BucketMetadata.tags:type_name -> messaging_pb.BucketMetadata.TagsEntry
+	7,  // 9: messaging_pb.BucketMetadata.cors:type_name -> messaging_pb.CORSConfiguration
+	9,  // 10: messaging_pb.BucketMetadata.encryption:type_name -> messaging_pb.EncryptionConfiguration
+	13, // 11: messaging_pb.BucketMetadata.lifecycle:type_name -> messaging_pb.LifecycleConfiguration
+	16, // 12: messaging_pb.BucketMetadata.replication:type_name -> messaging_pb.ReplicationConfiguration
+	18, // 13: messaging_pb.BucketMetadata.notification:type_name -> messaging_pb.NotificationConfiguration
+	20, // 14: messaging_pb.BucketMetadata.inventory:type_name -> messaging_pb.InventoryConfigurations
+	21, // 15: messaging_pb.BucketMetadata.logging:type_name -> messaging_pb.LoggingConfiguration
+	24, // 16: messaging_pb.BucketMetadata.website:type_name -> messaging_pb.WebsiteConfiguration
+	27, // 17: messaging_pb.BucketMetadata.transformation:type_name -> messaging_pb.TransformationConfiguration
+	34, // 18: messaging_pb.LifecycleFilter.tags:type_name -> messaging_pb.LifecycleFilter.TagsEntry
+	10, // 19: messaging_pb.LifecycleRule.filter:type_name -> messaging_pb.LifecycleFilter
+	12, // 20: messaging_pb.LifecycleRule.transitions:type_name -> messaging_pb.LifecycleTransition
+	12, // 21: messaging_pb.LifecycleRule.noncurrent_version_transitions:type_name -> messaging_pb.LifecycleTransition
+	11, // 22: messaging_pb.LifecycleConfiguration.rules:type_name -> messaging_pb.LifecycleRule
+	35, // 23: messaging_pb.ReplicationFilter.tags:type_name -> messaging_pb.ReplicationFilter.TagsEntry
+	14, // 24: messaging_pb.ReplicationRule.filter:type_name -> messaging_pb.ReplicationFilter
+	15, // 25: messaging_pb.ReplicationConfiguration.rules:type_name -> messaging_pb.ReplicationRule
+	17, // 26: messaging_pb.NotificationConfiguration.rules:type_name -> messaging_pb.NotificationRule
+	19, // 27: messaging_pb.InventoryConfigurations.configurations:type_name -> messaging_pb.InventoryConfiguration
+	22, // 28: messaging_pb.WebsiteRoutingRule.redirect:type_name -> messaging_pb.WebsiteRedirect
+	22, // 29: messaging_pb.WebsiteConfiguration.redirect_all_requests_to:type_name -> messaging_pb.WebsiteRedirect
+	23, // 30: messaging_pb.WebsiteConfiguration.routing_rules:type_name -> messaging_pb.WebsiteRoutingRule
+	36, // 31: messaging_pb.TransformationStep.parameters:type_name -> messaging_pb.TransformationStep.ParametersEntry
+	25, // 32: messaging_pb.TransformationRule.steps:type_name -> messaging_pb.TransformationStep
+	26, // 33: messaging_pb.TransformationConfiguration.rules:type_name -> messaging_pb.TransformationRule
+	3,  // 34: messaging_pb.S3CircuitBreakerConfig.BucketsEntry.value:type_name -> messaging_pb.S3CircuitBreakerOptions
+	5,  // 35: messaging_pb.S3RateLimitConfig.AccessKeysEntry.value:type_name -> messaging_pb.S3RateLimit
+	5,  // 36: messaging_pb.S3RateLimitConfig.BucketsEntry.value:type_name -> messaging_pb.S3RateLimit
+	5,  // 37: messaging_pb.S3RateLimitConfig.SourceIpsEntry.value:type_name -> messaging_pb.S3RateLimit
+	0,  // 38: messaging_pb.SeaweedS3.Configure:input_type -> messaging_pb.S3ConfigureRequest
+	1,  // 39: messaging_pb.SeaweedS3.Configure:output_type -> messaging_pb.S3ConfigureResponse
+	39, // [39:40] is the sub-list for method output_type
+	38, // [38:39] is the sub-list for method input_type
+	38, // [38:38] is the sub-list for extension type_name
+	38, // [38:38] is the sub-list for extension extendee
+	0,  // [0:38] is the sub-list for field type_name
 }
 
 func init() { file_s3_proto_init() }
@@ -556,7 +2148,7 @@ func file_s3_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_s3_proto_rawDesc), len(file_s3_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   11,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -5196,8 +5196,10 @@ type QueryRequest struct {
 	Filter              *QueryRequest_Filter              `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
 	InputSerialization  *QueryRequest_InputSerialization  `protobuf:"bytes,4,opt,name=input_serialization,json=inputSerialization,proto3" json:"input_serialization,omitempty"`
 	OutputSerialization *QueryRequest_OutputSerialization `protobuf:"bytes,5,opt,name=output_serialization,json=outputSerialization,proto3" json:"output_serialization,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+	// S3 Select SQL expression; if set, selections and filter are ignored
+	Expression    string `protobuf:"bytes,6,opt,name=expression,proto3" json:"expression,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *QueryRequest) Reset() {
@@ -5265,11 +5267,21 @@ func (x *QueryRequest) GetOutputSerialization() *QueryRequest_OutputSerializatio
 	return nil
 }
 
+func (x *QueryRequest) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
 type QueriedStripe struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Records       []byte                 `protobuf:"bytes,1,opt,name=records,proto3" json:"records,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Records []byte                 `protobuf:"bytes,1,opt,name=records,proto3" json:"records,omitempty"`
+	// only set on the last stripe of an expression query
+	BytesScanned   int64 `protobuf:"varint,2,opt,name=bytes_scanned,json=bytesScanned,proto3" json:"bytes_scanned,omitempty"`
+	BytesProcessed int64 `protobuf:"varint,3,opt,name=bytes_processed,json=bytesProcessed,proto3" json:"bytes_processed,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *QueriedStripe) Reset() {
@@ -5309,6 +5321,20 @@ func (x *QueriedStripe) GetRecords() []byte {
 	return nil
 }
 
+func (x *QueriedStripe) GetBytesScanned() int64 {
+	if x != nil {
+		return x.BytesScanned
+	}
+	return 0
+}
+
+func (x *QueriedStripe) GetBytesProcessed() int64 {
+	if x != nil {
+		return x.BytesProcessed
+	}
+	return 0
+}
+
 type VolumeNeedleStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	VolumeId      uint32                 `protobuf:"varint,1,opt,name=volume_id,json=volumeId,proto3" json:"volume_id,omitempty"`
@@ -6487,7 +6513,7 @@ const file_volume_server_proto_rawDesc = "" +
 	"public_url\x18\x02 \x01(\tR\tpublicUrl\x12\x1b\n" +
 	"\tgrpc_port\x18\x03 \x01(\x05R\bgrpcPort\"2\n" +
 	"\x1bFetchAndWriteNeedleResponse\x12\x13\n" +
-	"\x05e_tag\x18\x01 \x01(\tR\x04eTag\"\xf4\f\n" +
+	"\x05e_tag\x18\x01 \x01(\tR\x04eTag\"\x94\r\n" +
 	"\fQueryRequest\x12\x1e\n" +
 	"\n" +
 	"selections\x18\x01 \x03(\tR\n" +
@@ -6495,7 +6521,10 @@ const file_volume_server_proto_rawDesc = "" +
 	"\rfrom_file_ids\x18\x02 \x03(\tR\vfromFileIds\x12=\n" +
 	"\x06filter\x18\x03 \x01(\v2%.volume_server_pb.QueryRequest.FilterR\x06filter\x12b\n" +
 	"\x13input_serialization\x18\x04 \x01(\v21.volume_server_pb.QueryRequest.InputSerializationR\x12inputSerialization\x12e\n" +
-	"\x14output_serialization\x18\x05 \x01(\v22.volume_server_pb.QueryRequest.OutputSerializationR\x13outputSerialization\x1aN\n" +
+	"\x14output_serialization\x18\x05 \x01(\v22.volume_server_pb.QueryRequest.OutputSerializationR\x13outputSerialization\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x06 \x01(\tR\n" +
+	"expression\x1aN\n" +
 	"\x06Filter\x12\x14\n" +
 	"\x05field\x18\x01 \x01(\tR\x05field\x12\x18\n" +
 	"\aoperand\x18\x02 \x01(\tR\aoperand\x12\x14\n" +
@@ -6530,9 +6559,11 @@ const file_volume_server_proto_rawDesc = "" +
 	"\x16quote_escape_character\x18\x05 \x01(\tR\x14quoteEscapeCharacter\x1a7\n" +
 	"\n" +
 	"JSONOutput\x12)\n" +
-	"\x10record_delimiter\x18\x01 \x01(\tR\x0frecordDelimiter\")\n" +
+	"\x10record_delimiter\x18\x01 \x01(\tR\x0frecordDelimiter\"w\n" +
 	"\rQueriedStripe\x12\x18\n" +
-	"\arecords\x18\x01 \x01(\fR\arecords\"U\n" +
+	"\arecords\x18\x01 \x01(\fR\arecords\x12#\n" +
+	"\rbytes_scanned\x18\x02 \x01(\x03R\fbytesScanned\x12'\n" +
+	"\x0fbytes_processed\x18\x03 \x01(\x03R\x0ebytesProcessed\"U\n" +
 	"\x19VolumeNeedleStatusRequest\x12\x1b\n" +
 	"\tvolume_id\x18\x01 \x01(\rR\bvolumeId\x12\x1b\n" +
 	"\tneedle_id\x18\x02 \x01(\x04R\bneedleId\"\xae\x01\n" +
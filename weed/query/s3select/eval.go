@@ -0,0 +1,700 @@
+package s3select
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type expr interface {
+	eval(record Value) (Value, error)
+}
+
+type pathStep struct {
+	name     string
+	quoted   bool
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+type literalExpr struct {
+	value Value
+}
+
+type pathExpr struct {
+	steps []pathStep
+}
+
+type logicalExpr struct {
+	op          string
+	left, right expr
+}
+
+type notExpr struct {
+	expr expr
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+type arithmeticExpr struct {
+	op          string
+	left, right expr
+}
+
+type isExpr struct {
+	expr   expr
+	what   string
+	negate bool
+}
+
+type likeExpr struct {
+	expr, pattern, escape expr
+	negate                bool
+}
+
+type inExpr struct {
+	expr   expr
+	list   []expr
+	negate bool
+}
+
+type betweenExpr struct {
+	expr, low, high expr
+	negate          bool
+}
+
+type castExpr struct {
+	expr     expr
+	typeName string
+}
+
+type caseExpr struct {
+	operand  expr
+	whens    []expr
+	thens    []expr
+	elseExpr expr
+}
+
+type funcExpr struct {
+	name string
+	args []expr
+}
+
+// aggregateExpr accumulates over all matching records and evaluates to the aggregated result.
+type aggregateExpr struct {
+	name  string
+	arg   expr
+	star  bool
+	count int64
+	sum   Value
+	best  Value
+}
+
+var aggregateFuncs = map[string]bool{"COUNT": true, "SUM": true, "AVG": true, "MIN": true, "MAX": true}
+
+// scalarFuncs maps function names to their minimum and maximum number of arguments
+var scalarFuncs = map[string][2]int{
+	"LOWER":            {1, 1},
+	"UPPER":            {1, 1},
+	"TRIM":             {1, 1},
+	"CHAR_LENGTH":      {1, 1},
+	"CHARACTER_LENGTH": {1, 1},
+	"SUBSTRING":        {2, 3},
+	"COALESCE":         {1, math.MaxInt},
+	"NULLIF":           {2, 2},
+	"ABS":              {1, 1},
+	"TO_TIMESTAMP":     {1, 1},
+	"TO_STRING":        {1, 1},
+	"UTCNOW":           {0, 0},
+}
+
+func checkArity(f *funcExpr) error {
+	arity := scalarFuncs[f.name]
+	if len(f.args) < arity[0] || len(f.args) > arity[1] {
+		return fmt.Errorf("wrong number of arguments for %s", f.name)
+	}
+	return nil
+}
+
+// walk visits e and all its sub expressions.
+func walk(e expr, fn func(expr)) {
+	if e == nil {
+		return
+	}
+	fn(e)
+	switch x := e.(type) {
+	case *logicalExpr:
+		walk(x.left, fn)
+		walk(x.right, fn)
+	case *notExpr:
+		walk(x.expr, fn)
+	case *compareExpr:
+		walk(x.left, fn)
+		walk(x.right, fn)
+	case *arithmeticExpr:
+		walk(x.left, fn)
+		walk(x.right, fn)
+	case *isExpr:
+		walk(x.expr, fn)
+	case *likeExpr:
+		walk(x.expr, fn)
+		walk(x.pattern, fn)
+		walk(x.escape, fn)
+	case *inExpr:
+		walk(x.expr, fn)
+		for _, item := range x.list {
+			walk(item, fn)
+		}
+	case *betweenExpr:
+		walk(x.expr, fn)
+		walk(x.low, fn)
+		walk(x.high, fn)
+	case *castExpr:
+		walk(x.expr, fn)
+	case *caseExpr:
+		walk(x.operand, fn)
+		for i := range x.whens {
+			walk(x.whens[i], fn)
+			walk(x.thens[i], fn)
+		}
+		walk(x.elseExpr, fn)
+	case *funcExpr:
+		for _, arg := range x.args {
+			walk(arg, fn)
+		}
+	case *aggregateExpr:
+		walk(x.arg, fn)
+	}
+}
+
+// resolveAlias removes the table alias from column references, so "s.name" becomes "name"
+// and a bare "s" refers to the whole record.
+func resolveAlias(e expr, alias string) {
+	walk(e, func(e expr) {
+		path, ok := e.(*pathExpr)
+		if !ok || len(path.steps) == 0 || path.steps[0].quoted {
+			return
+		}
+		first := path.steps[0].name
+		if (alias != "" && strings.EqualFold(first, alias)) || strings.EqualFold(first, "S3Object") {
+			path.steps = path.steps[1:]
+		}
+	})
+}
+
+// columnName is the name used for a select item in JSON output.
+func (item selectItem) columnName(position int) string {
+	if item.alias != "" {
+		return item.alias
+	}
+	if path, ok := item.expr.(*pathExpr); ok && len(path.steps) > 0 {
+		if last := path.steps[len(path.steps)-1]; !last.isIndex {
+			return last.name
+		}
+	}
+	return fmt.Sprintf("_%d", position+1)
+}
+
+func (e *literalExpr) eval(record Value) (Value, error) {
+	return e.value, nil
+}
+
+func (e *pathExpr) eval(record Value) (Value, error) {
+	return navigate(record, e.steps), nil
+}
+
+func navigate(v Value, steps []pathStep) Value {
+	for _, step := range steps {
+		switch {
+		case step.isIndex:
+			if v.kind != KindList || step.index < 0 || step.index >= len(v.list) {
+				return Missing
+			}
+			v = v.list[step.index]
+		case v.kind == KindObject:
+			var found bool
+			if v, found = v.obj.Get(step.name, step.quoted); !found {
+				return Missing
+			}
+		default:
+			return Missing
+		}
+	}
+	return v
+}
+
+// truth evaluates a boolean expression with SQL three valued logic, returning ok=false for UNKNOWN.
+func truth(e expr, record Value) (value bool, ok bool, err error) {
+	v, err := e.eval(record)
+	if err != nil {
+		return false, false, err
+	}
+	if v.IsNull() {
+		return false, false, nil
+	}
+	b, ok := v.toBool()
+	if !ok {
+		return false, false, fmt.Errorf("expected a boolean, got %q", v.String())
+	}
+	return b, true, nil
+}
+
+func boolOrNull(b, ok bool) Value {
+	if !ok {
+		return Null
+	}
+	return NewBool(b)
+}
+
+func (e *logicalExpr) eval(record Value) (Value, error) {
+	l, lok, err := truth(e.left, record)
+	if err != nil {
+		return Null, err
+	}
+	if e.op == "AND" && lok && !l {
+		return NewBool(false), nil
+	}
+	if e.op == "OR" && lok && l {
+		return NewBool(true), nil
+	}
+	r, rok, err := truth(e.right, record)
+	if err != nil {
+		return Null, err
+	}
+	if e.op == "AND" {
+		if rok && !r {
+			return NewBool(false), nil
+		}
+		return boolOrNull(true, lok && rok), nil
+	}
+	if rok && r {
+		return NewBool(true), nil
+	}
+	return boolOrNull(false, lok && rok), nil
+}
+
+func (e *notExpr) eval(record Value) (Value, error) {
+	b, ok, err := truth(e.expr, record)
+	if err != nil {
+		return Null, err
+	}
+	return boolOrNull(!b, ok), nil
+}
+
+func (e *compareExpr) eval(record Value) (Value, error) {
+	l, err := e.left.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	r, err := e.right.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	c, ok := compare(l, r)
+	if !ok {
+		if l.IsNull() || r.IsNull() {
+			return Null, nil
+		}
+		// values of different kinds are never equal
+		return boolOrNull(e.op == "!=" || e.op == "<>", e.op == "=" || e.op == "!=" || e.op == "<>"), nil
+	}
+	switch e.op {
+	case "=":
+		return NewBool(c == 0), nil
+	case "!=", "<>":
+		return NewBool(c != 0), nil
+	case "<":
+		return NewBool(c < 0), nil
+	case "<=":
+		return NewBool(c <= 0), nil
+	case ">":
+		return NewBool(c > 0), nil
+	case ">=":
+		return NewBool(c >= 0), nil
+	}
+	return Null, fmt.Errorf("unknown operator %s", e.op)
+}
+
+func (e *arithmeticExpr) eval(record Value) (Value, error) {
+	l, err := e.left.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	r, err := e.right.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	if l.IsNull() || r.IsNull() {
+		return Null, nil
+	}
+	if e.op == "||" {
+		return NewString(l.String() + r.String()), nil
+	}
+	return arithmetic(e.op, l, r)
+}
+
+func arithmetic(op string, l, r Value) (Value, error) {
+	x, ok1 := l.toNumber()
+	y, ok2 := r.toNumber()
+	if !ok1 || !ok2 {
+		return Null, fmt.Errorf("cannot apply %s to %q and %q", op, l.String(), r.String())
+	}
+	if x.kind == KindInt && y.kind == KindInt {
+		switch op {
+		case "+":
+			return NewInt(x.i + y.i), nil
+		case "-":
+			return NewInt(x.i - y.i), nil
+		case "*":
+			return NewInt(x.i * y.i), nil
+		case "/", "%":
+			if y.i == 0 {
+				return Null, fmt.Errorf("division by zero")
+			}
+			if op == "/" {
+				return NewInt(x.i / y.i), nil
+			}
+			return NewInt(x.i % y.i), nil
+		}
+	}
+	a, _ := x.toFloat()
+	b, _ := y.toFloat()
+	switch op {
+	case "+":
+		return NewFloat(a + b), nil
+	case "-":
+		return NewFloat(a - b), nil
+	case "*":
+		return NewFloat(a * b), nil
+	case "/":
+		if b == 0 {
+			return Null, fmt.Errorf("division by zero")
+		}
+		return NewFloat(a / b), nil
+	case "%":
+		if b == 0 {
+			return Null, fmt.Errorf("division by zero")
+		}
+		return NewFloat(math.Mod(a, b)), nil
+	}
+	return Null, fmt.Errorf("unknown operator %s", op)
+}
+
+func (e *isExpr) eval(record Value) (Value, error) {
+	v, err := e.expr.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	var result bool
+	switch e.what {
+	case "NULL":
+		result = v.IsNull()
+	case "MISSING":
+		result = v.kind == KindMissing
+	case "TRUE", "FALSE":
+		b, ok := v.toBool()
+		result = ok && v.kind == KindBool && b == (e.what == "TRUE")
+	}
+	return NewBool(result != e.negate), nil
+}
+
+func (e *likeExpr) eval(record Value) (Value, error) {
+	v, err := e.expr.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	pattern, err := e.pattern.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	if v.IsNull() || pattern.IsNull() {
+		return Null, nil
+	}
+	escape := rune(0)
+	if e.escape != nil {
+		esc, err := e.escape.eval(record)
+		if err != nil {
+			return Null, err
+		}
+		if utf8.RuneCountInString(esc.String()) != 1 {
+			return Null, fmt.Errorf("ESCAPE must be a single character")
+		}
+		escape, _ = utf8.DecodeRuneInString(esc.String())
+	}
+	return NewBool(matchLike([]rune(v.String()), []rune(pattern.String()), escape) != e.negate), nil
+}
+
+// matchLike matches s against a SQL LIKE pattern, where % matches any sequence and _ any single character.
+func matchLike(s, pattern []rune, escape rune) bool {
+	for len(pattern) > 0 {
+		c := pattern[0]
+		switch {
+		case escape != 0 && c == escape && len(pattern) > 1:
+			if len(s) == 0 || s[0] != pattern[1] {
+				return false
+			}
+			s, pattern = s[1:], pattern[2:]
+		case c == '%':
+			for len(pattern) > 0 && pattern[0] == '%' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchLike(s[i:], pattern, escape) {
+					return true
+				}
+			}
+			return false
+		case c == '_':
+			if len(s) == 0 {
+				return false
+			}
+			s, pattern = s[1:], pattern[1:]
+		default:
+			if len(s) == 0 || s[0] != c {
+				return false
+			}
+			s, pattern = s[1:], pattern[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+func (e *inExpr) eval(record Value) (Value, error) {
+	v, err := e.expr.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	if v.IsNull() {
+		return Null, nil
+	}
+	for _, item := range e.list {
+		candidate, err := item.eval(record)
+		if err != nil {
+			return Null, err
+		}
+		if c, ok := compare(v, candidate); ok && c == 0 {
+			return NewBool(!e.negate), nil
+		}
+	}
+	return NewBool(e.negate), nil
+}
+
+func (e *betweenExpr) eval(record Value) (Value, error) {
+	v, err := e.expr.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	low, err := e.low.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	high, err := e.high.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	c1, ok1 := compare(v, low)
+	c2, ok2 := compare(v, high)
+	if !ok1 || !ok2 {
+		return Null, nil
+	}
+	return NewBool((c1 >= 0 && c2 <= 0) != e.negate), nil
+}
+
+func (e *castExpr) eval(record Value) (Value, error) {
+	v, err := e.expr.eval(record)
+	if err != nil {
+		return Null, err
+	}
+	return castValue(v, e.typeName)
+}
+
+func (e *caseExpr) eval(record Value) (Value, error) {
+	var operand Value
+	if e.operand != nil {
+		var err error
+		if operand, err = e.operand.eval(record); err != nil {
+			return Null, err
+		}
+	}
+	for i, when := range e.whens {
+		if e.operand != nil {
+			w, err := when.eval(record)
+			if err != nil {
+				return Null, err
+			}
+			if c, ok := compare(operand, w); !ok || c != 0 {
+				continue
+			}
+		} else if b, ok, err := truth(when, record); err != nil {
+			return Null, err
+		} else if !ok || !b {
+			continue
+		}
+		return e.thens[i].eval(record)
+	}
+	if e.elseExpr != nil {
+		return e.elseExpr.eval(record)
+	}
+	return Null, nil
+}
+
+func (e *funcExpr) eval(record Value) (Value, error) {
+	args := make([]Value, len(e.args))
+	for i, arg := range e.args {
+		v, err := arg.eval(record)
+		if err != nil {
+			return Null, err
+		}
+		args[i] = v
+	}
+
+	switch e.name {
+	case "COALESCE":
+		for _, arg := range args {
+			if !arg.IsNull() {
+				return arg, nil
+			}
+		}
+		return Null, nil
+	case "NULLIF":
+		if c, ok := compare(args[0], args[1]); ok && c == 0 {
+			return Null, nil
+		}
+		return args[0], nil
+	case "UTCNOW":
+		return NewTimestamp(time.Now().UTC()), nil
+	}
+
+	if args[0].IsNull() {
+		return Null, nil
+	}
+	switch e.name {
+	case "LOWER":
+		return NewString(strings.ToLower(args[0].String())), nil
+	case "UPPER":
+		return NewString(strings.ToUpper(args[0].String())), nil
+	case "TRIM":
+		return NewString(strings.TrimSpace(args[0].String())), nil
+	case "CHAR_LENGTH", "CHARACTER_LENGTH":
+		return NewInt(int64(utf8.RuneCountInString(args[0].String()))), nil
+	case "TO_STRING":
+		return NewString(args[0].String()), nil
+	case "TO_TIMESTAMP":
+		return castValue(args[0], "TIMESTAMP")
+	case "ABS":
+		n, ok := args[0].toNumber()
+		if !ok {
+			return Null, fmt.Errorf("ABS expects a number, got %q", args[0].String())
+		}
+		if n.kind == KindInt {
+			if n.i < 0 {
+				return NewInt(-n.i), nil
+			}
+			return n, nil
+		}
+		return NewFloat(math.Abs(n.f)), nil
+	case "SUBSTRING":
+		runes := []rune(args[0].String())
+		start, err := castValue(args[1], "INT")
+		if err != nil {
+			return Null, err
+		}
+		// SQL positions start at 1
+		from, to := start.i-1, int64(len(runes))
+		if len(args) == 3 {
+			length, err := castValue(args[2], "INT")
+			if err != nil {
+				return Null, err
+			}
+			if length.i < 0 {
+				return Null, fmt.Errorf("negative SUBSTRING length")
+			}
+			to = from + length.i
+		}
+		from = max(from, 0)
+		to = min(to, int64(len(runes)))
+		if from >= to {
+			return NewString(""), nil
+		}
+		return NewString(string(runes[from:to])), nil
+	}
+	return Null, fmt.Errorf("unsupported function %s", e.name)
+}
+
+func (e *aggregateExpr) reset() {
+	e.count, e.sum, e.best = 0, Null, Null
+}
+
+func (e *aggregateExpr) accumulate(record Value) error {
+	if e.star {
+		e.count++
+		return nil
+	}
+	v, err := e.arg.eval(record)
+	if err != nil {
+		return err
+	}
+	if v.IsNull() {
+		return nil
+	}
+	// empty CSV fields count as NULL
+	if v.kind == KindString && e.name != "COUNT" && strings.TrimSpace(v.s) == "" {
+		return nil
+	}
+	switch e.name {
+	case "COUNT":
+		e.count++
+	case "SUM", "AVG":
+		n, ok := v.toNumber()
+		if !ok {
+			return fmt.Errorf("%s expects numbers, got %q", e.name, v.String())
+		}
+		e.count++
+		if e.sum.IsNull() {
+			e.sum = n
+		} else if e.sum, err = arithmetic("+", e.sum, n); err != nil {
+			return err
+		}
+	case "MIN", "MAX":
+		if n, ok := v.toNumber(); ok && v.kind == KindString {
+			v = n
+		}
+		if e.best.IsNull() {
+			e.best = v
+			return nil
+		}
+		c, ok := compare(v, e.best)
+		if !ok {
+			return fmt.Errorf("%s cannot compare %q and %q", e.name, v.String(), e.best.String())
+		}
+		if (e.name == "MIN" && c < 0) || (e.name == "MAX" && c > 0) {
+			e.best = v
+		}
+	}
+	return nil
+}
+
+func (e *aggregateExpr) eval(record Value) (Value, error) {
+	switch e.name {
+	case "COUNT":
+		return NewInt(e.count), nil
+	case "SUM":
+		return e.sum, nil
+	case "AVG":
+		if e.count == 0 {
+			return Null, nil
+		}
+		sum, _ := e.sum.toFloat()
+		return NewFloat(sum / float64(e.count)), nil
+	}
+	return e.best, nil
+}
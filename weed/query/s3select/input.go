@@ -0,0 +1,412 @@
+package s3select
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+)
+
+type recordReader interface {
+	// Read returns the next record, or io.EOF after the last one
+	Read() (Value, error)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type countingReaderAt struct {
+	r io.ReaderAt
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += int64(n)
+	return n, err
+}
+
+func decompress(compressionType string, r io.Reader) (io.Reader, error) {
+	switch strings.ToUpper(compressionType) {
+	case "", "NONE":
+		return r, nil
+	case "GZIP":
+		return gzip.NewReader(r)
+	case "BZIP2":
+		return bzip2.NewReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported compression type %s", compressionType)
+}
+
+// csvReader reads delimiter separated records. Unlike encoding/csv it supports the
+// configurable record delimiters, quote and escape characters of S3 Select.
+type csvReader struct {
+	r                 *bufio.Reader
+	recordDelimiter   []byte
+	fieldDelimiter    []byte
+	quote             byte
+	escape            byte
+	comment           []byte
+	allowQuotedRecord bool
+	header            []string
+}
+
+func newCSVReader(r io.Reader, input *volume_server_pb.QueryRequest_InputSerialization_CSVInput) (*csvReader, error) {
+	c := &csvReader{
+		r:                 bufio.NewReaderSize(r, 64*1024),
+		recordDelimiter:   []byte(defaultString(input.RecordDelimiter, "\n")),
+		fieldDelimiter:    []byte(defaultString(input.FieldDelimiter, ",")),
+		quote:             '"',
+		escape:            '"',
+		comment:           []byte(input.Comments),
+		allowQuotedRecord: input.AllowQuotedRecordDelimiter,
+	}
+	if input.QuoteCharacter != "" {
+		c.quote = input.QuoteCharacter[0]
+	}
+	if input.QuoteEscapeCharacter != "" {
+		c.escape = input.QuoteEscapeCharacter[0]
+	}
+
+	switch strings.ToUpper(input.FileHeaderInfo) {
+	case "", "NONE":
+	case "USE", "IGNORE":
+		header, err := c.readFields()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if strings.ToUpper(input.FileHeaderInfo) == "USE" {
+			c.header = header
+		}
+	default:
+		return nil, fmt.Errorf("invalid FileHeaderInfo %s", input.FileHeaderInfo)
+	}
+	return c, nil
+}
+
+func (c *csvReader) Read() (Value, error) {
+	fields, err := c.readFields()
+	if err != nil {
+		return Null, err
+	}
+	obj := &Object{}
+	for i, field := range fields {
+		name := "_" + strconv.Itoa(i+1)
+		if i < len(c.header) {
+			name = c.header[i]
+		}
+		obj.Add(name, NewString(field))
+	}
+	return NewObject(obj), nil
+}
+
+func (c *csvReader) hasPrefix(delimiter []byte) bool {
+	if len(delimiter) == 0 {
+		return false
+	}
+	peek, _ := c.r.Peek(len(delimiter))
+	return bytes.Equal(peek, delimiter)
+}
+
+// readFields reads the fields of the next record, skipping comment lines
+func (c *csvReader) readFields() ([]string, error) {
+	for {
+		if len(c.comment) > 0 && c.hasPrefix(c.comment) {
+			if err := c.skipRecord(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fields, err := c.readRecord()
+		if err != nil {
+			return nil, err
+		}
+		// skip empty lines
+		if len(fields) == 1 && fields[0] == "" {
+			continue
+		}
+		return fields, nil
+	}
+}
+
+func (c *csvReader) skipRecord() error {
+	for {
+		if c.hasPrefix(c.recordDelimiter) {
+			_, err := c.r.Discard(len(c.recordDelimiter))
+			return err
+		}
+		if _, err := c.r.ReadByte(); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *csvReader) readRecord() ([]string, error) {
+	var fields []string
+	var field []byte
+	inQuotes, quoted, readAny := false, false, false
+
+	endField := func() {
+		if !quoted && bytes.Equal(c.recordDelimiter, []byte("\n")) {
+			field = bytes.TrimSuffix(field, []byte("\r"))
+		}
+		fields = append(fields, string(field))
+		field, quoted = field[:0], false
+	}
+
+	for {
+		if !inQuotes || !c.allowQuotedRecord {
+			if c.hasPrefix(c.recordDelimiter) {
+				c.r.Discard(len(c.recordDelimiter))
+				endField()
+				return fields, nil
+			}
+		}
+		b, err := c.r.ReadByte()
+		if err == io.EOF {
+			if !readAny {
+				return nil, io.EOF
+			}
+			endField()
+			return fields, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		readAny = true
+
+		if inQuotes {
+			if b == c.escape && c.escape != c.quote {
+				if next, err := c.r.ReadByte(); err == nil {
+					field = append(field, next)
+				}
+				continue
+			}
+			if b == c.quote {
+				if c.hasPrefix([]byte{c.quote}) {
+					c.r.ReadByte()
+					field = append(field, c.quote)
+					continue
+				}
+				inQuotes = false
+				continue
+			}
+			field = append(field, b)
+			continue
+		}
+
+		if b == c.quote && len(field) == 0 && !quoted {
+			inQuotes, quoted = true, true
+			continue
+		}
+		if b == c.fieldDelimiter[0] && (len(c.fieldDelimiter) == 1 || c.hasPrefix(c.fieldDelimiter[1:])) {
+			c.r.Discard(len(c.fieldDelimiter) - 1)
+			endField()
+			continue
+		}
+		field = append(field, b)
+	}
+}
+
+// jsonReader reads a stream of JSON values, one record per top level value
+type jsonReader struct {
+	dec *json.Decoder
+}
+
+func newJSONReader(r io.Reader, input *volume_server_pb.QueryRequest_InputSerialization_JSONInput) (*jsonReader, error) {
+	switch strings.ToUpper(input.Type) {
+	case "", "LINES", "DOCUMENT":
+	default:
+		return nil, fmt.Errorf("invalid JSON type %s", input.Type)
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return &jsonReader{dec: dec}, nil
+}
+
+func (j *jsonReader) Read() (Value, error) {
+	t, err := j.dec.Token()
+	if err != nil {
+		return Null, err
+	}
+	return j.decodeValue(t)
+}
+
+// decodeValue decodes the value starting with token t, keeping the key order of objects
+func (j *jsonReader) decodeValue(t json.Token) (Value, error) {
+	switch x := t.(type) {
+	case nil:
+		return Null, nil
+	case bool:
+		return NewBool(x), nil
+	case string:
+		return NewString(x), nil
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return NewInt(i), nil
+		}
+		f, err := x.Float64()
+		if err != nil {
+			return Null, err
+		}
+		return NewFloat(f), nil
+	case json.Delim:
+		switch x {
+		case '{':
+			obj := &Object{}
+			for j.dec.More() {
+				keyToken, err := j.dec.Token()
+				if err != nil {
+					return Null, err
+				}
+				valueToken, err := j.dec.Token()
+				if err != nil {
+					return Null, err
+				}
+				value, err := j.decodeValue(valueToken)
+				if err != nil {
+					return Null, err
+				}
+				obj.Add(keyToken.(string), value)
+			}
+			if _, err := j.dec.Token(); err != nil {
+				return Null, err
+			}
+			return NewObject(obj), nil
+		case '[':
+			var list []Value
+			for j.dec.More() {
+				itemToken, err := j.dec.Token()
+				if err != nil {
+					return Null, err
+				}
+				item, err := j.decodeValue(itemToken)
+				if err != nil {
+					return Null, err
+				}
+				list = append(list, item)
+			}
+			if _, err := j.dec.Token(); err != nil {
+				return Null, err
+			}
+			return NewList(list), nil
+		}
+	}
+	return Null, fmt.Errorf("unexpected JSON token %v", t)
+}
+
+// parquetReader reads the rows of a parquet file
+type parquetReader struct {
+	reader *parquet.Reader
+	schema *parquet.Schema
+}
+
+func newParquetReader(content io.ReaderAt, size int64) (*parquetReader, error) {
+	file, err := parquet.OpenFile(content, size)
+	if err != nil {
+		return nil, fmt.Errorf("open parquet: %w", err)
+	}
+	return &parquetReader{
+		reader: parquet.NewReader(file),
+		schema: file.Schema(),
+	}, nil
+}
+
+func (p *parquetReader) Read() (Value, error) {
+	row := make(map[string]any)
+	if err := p.reader.Read(&row); err != nil {
+		return Null, err
+	}
+	return fromGo(row, p.schema), nil
+}
+
+// fromGo converts a reconstructed parquet value, ordering object keys by the schema
+func fromGo(v any, node parquet.Node) Value {
+	switch x := v.(type) {
+	case nil:
+		return Null
+	case bool:
+		return NewBool(x)
+	case string:
+		return NewString(x)
+	case []byte:
+		return NewString(string(x))
+	case time.Time:
+		return NewTimestamp(x)
+	case float32:
+		return NewFloat(float64(x))
+	case float64:
+		return NewFloat(x)
+	case map[string]any:
+		obj := &Object{}
+		if node != nil && !node.Leaf() {
+			for _, field := range node.Fields() {
+				if value, found := x[field.Name()]; found {
+					obj.Add(field.Name(), fromGo(value, field))
+				}
+			}
+			return NewObject(obj)
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			obj.Add(k, fromGo(x[k], nil))
+		}
+		return NewObject(obj)
+	case []any:
+		list := make([]Value, len(x))
+		for i, item := range x {
+			list[i] = fromGo(item, nil)
+		}
+		return NewList(list)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NewInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return NewInt(int64(u))
+		}
+		return NewFloat(float64(rv.Uint()))
+	case reflect.Slice, reflect.Array:
+		list := make([]Value, rv.Len())
+		for i := range list {
+			list[i] = fromGo(rv.Index(i).Interface(), nil)
+		}
+		return NewList(list)
+	}
+	return NewString(fmt.Sprint(v))
+}
+
+func defaultString(s, defaultValue string) string {
+	if s == "" {
+		return defaultValue
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package s3select
+
+import (
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+)
+
+type recordWriter interface {
+	// appendRecord serializes one output row, with the column names used by JSON output
+	appendRecord(buf []byte, names []string, values []Value) []byte
+}
+
+type csvWriter struct {
+	recordDelimiter string
+	fieldDelimiter  string
+	quote           string
+	escape          string
+	quoteAlways     bool
+}
+
+func newCSVWriter(output *volume_server_pb.QueryRequest_OutputSerialization_CSVOutput) *csvWriter {
+	return &csvWriter{
+		recordDelimiter: defaultString(output.RecordDelimiter, "\n"),
+		fieldDelimiter:  defaultString(output.FieldDelimiter, ","),
+		quote:           defaultString(output.QuoteCharacter, `"`),
+		escape:          defaultString(output.QuoteEscapeCharacter, `"`),
+		quoteAlways:     strings.EqualFold(output.QuoteFields, "ALWAYS"),
+	}
+}
+
+func (c *csvWriter) appendRecord(buf []byte, names []string, values []Value) []byte {
+	for i, value := range values {
+		if i > 0 {
+			buf = append(buf, c.fieldDelimiter...)
+		}
+		s := value.String()
+		if c.quoteAlways || c.needsQuotes(s) {
+			buf = append(buf, c.quote...)
+			buf = append(buf, strings.ReplaceAll(s, c.quote, c.escape+c.quote)...)
+			buf = append(buf, c.quote...)
+		} else {
+			buf = append(buf, s...)
+		}
+	}
+	return append(buf, c.recordDelimiter...)
+}
+
+func (c *csvWriter) needsQuotes(s string) bool {
+	return strings.Contains(s, c.fieldDelimiter) || strings.Contains(s, c.quote) ||
+		strings.Contains(s, c.recordDelimiter) || strings.ContainsAny(s, "\r\n")
+}
+
+type jsonWriter struct {
+	recordDelimiter string
+}
+
+func newJSONWriter(output *volume_server_pb.QueryRequest_OutputSerialization_JSONOutput) *jsonWriter {
+	return &jsonWriter{
+		recordDelimiter: defaultString(output.RecordDelimiter, "\n"),
+	}
+}
+
+func (j *jsonWriter) appendRecord(buf []byte, names []string, values []Value) []byte {
+	obj := &Object{Keys: names, Values: values}
+	buf = obj.appendJSON(buf)
+	return append(buf, j.recordDelimiter...)
+}
@@ -0,0 +1,638 @@
+package s3select
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Statement is a parsed S3 Select SQL expression, e.g.
+//
+//	SELECT s.name, CAST(s.size AS INT) FROM S3Object s WHERE s.size > 1024 LIMIT 10
+type Statement struct {
+	Items      []selectItem // empty for SELECT *
+	From       []pathStep   // steps after S3Object, e.g. [*].records[*]
+	Alias      string
+	Where      expr
+	Limit      int64 // -1 if not set
+	aggregates []*aggregateExpr
+}
+
+type selectItem struct {
+	expr  expr
+	alias string
+}
+
+// IsAggregate returns true if the select list computes a single row of aggregates.
+func (s *Statement) IsAggregate() bool {
+	return len(s.aggregates) > 0
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	aggregates  []*aggregateExpr
+	inAggregate bool
+	sawColumn   bool
+}
+
+// Parse parses an S3 Select SQL expression.
+func Parse(sql string) (*Statement, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(words ...string) bool {
+	t := p.peek()
+	if t.kind != tokKeyword {
+		return false
+	}
+	for _, w := range words {
+		if t.text == w {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) isOperator(op string) bool {
+	t := p.peek()
+	return t.kind == tokOperator && t.text == op
+}
+
+func (p *parser) acceptKeyword(word string) bool {
+	if p.isKeyword(word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptOperator(op string) bool {
+	if p.isOperator(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(word string) error {
+	if !p.acceptKeyword(word) {
+		return p.errorf("expected %s", word)
+	}
+	return nil
+}
+
+func (p *parser) expectOperator(op string) error {
+	if !p.acceptOperator(op) {
+		return p.errorf("expected %q", op)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	near := t.text
+	if t.kind == tokEOF {
+		near = "end of expression"
+	}
+	return fmt.Errorf("%s near %q at position %d", fmt.Sprintf(format, args...), near, t.pos)
+}
+
+func (p *parser) parseStatement() (*Statement, error) {
+	stmt := &Statement{Limit: -1}
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	hasColumns := false
+	if p.acceptOperator("*") {
+		// SELECT *
+	} else {
+		for {
+			p.sawColumn = false
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			hasColumns = hasColumns || p.sawColumn
+			item := selectItem{expr: e}
+			if p.acceptKeyword("AS") {
+				t := p.next()
+				if t.kind != tokIdent && t.kind != tokQuotedIdent {
+					return nil, p.errorf("expected alias")
+				}
+				item.alias = t.text
+			} else if t := p.peek(); t.kind == tokIdent || t.kind == tokQuotedIdent {
+				item.alias = p.next().text
+			}
+			stmt.Items = append(stmt.Items, item)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+	}
+	stmt.aggregates = p.aggregates
+	if stmt.IsAggregate() && hasColumns {
+		return nil, fmt.Errorf("select list mixes aggregate functions with plain columns")
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if err := p.parseFrom(stmt); err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("WHERE") {
+		p.aggregates = nil
+		where, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if len(p.aggregates) > 0 {
+			return nil, fmt.Errorf("aggregate functions are not allowed in WHERE")
+		}
+		stmt.Where = where
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		t := p.next()
+		limit, err := strconv.ParseInt(t.text, 10, 64)
+		if t.kind != tokNumber || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid LIMIT %q", t.text)
+		}
+		stmt.Limit = limit
+	}
+
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorf("unexpected token")
+	}
+	for _, item := range stmt.Items {
+		resolveAlias(item.expr, stmt.Alias)
+	}
+	if stmt.Where != nil {
+		resolveAlias(stmt.Where, stmt.Alias)
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseFrom(stmt *Statement) error {
+	t := p.next()
+	if t.kind != tokIdent || !strings.EqualFold(t.text, "S3Object") {
+		return fmt.Errorf("only FROM S3Object is supported, got %q", t.text)
+	}
+	for {
+		if p.acceptOperator("[") {
+			if p.acceptOperator("*") {
+				stmt.From = append(stmt.From, pathStep{wildcard: true})
+			} else {
+				n := p.next()
+				index, err := strconv.Atoi(n.text)
+				if n.kind != tokNumber || err != nil {
+					return fmt.Errorf("invalid array index %q", n.text)
+				}
+				stmt.From = append(stmt.From, pathStep{index: index, isIndex: true})
+			}
+			if err := p.expectOperator("]"); err != nil {
+				return err
+			}
+			continue
+		}
+		if p.acceptOperator(".") {
+			n := p.next()
+			if n.kind != tokIdent && n.kind != tokQuotedIdent {
+				return fmt.Errorf("invalid path after S3Object")
+			}
+			stmt.From = append(stmt.From, pathStep{name: n.text, quoted: n.kind == tokQuotedIdent})
+			continue
+		}
+		break
+	}
+	// a leading [*] only means "each record", which is the default
+	if len(stmt.From) > 0 && stmt.From[0].wildcard {
+		stmt.From = stmt.From[1:]
+	}
+	if p.acceptKeyword("AS") {
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokQuotedIdent {
+			return p.errorf("expected alias")
+		}
+		stmt.Alias = t.text
+	} else if t := p.peek(); t.kind == tokIdent || t.kind == tokQuotedIdent {
+		stmt.Alias = p.next().text
+	}
+	return nil
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: e}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *parser) parsePredicate() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.kind == tokOperator {
+		switch t.text {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &compareExpr{op: t.text, left: left, right: right}, nil
+		}
+	}
+
+	if p.acceptKeyword("IS") {
+		negate := p.acceptKeyword("NOT")
+		t := p.next()
+		if t.kind != tokKeyword || (t.text != "NULL" && t.text != "MISSING" && t.text != "TRUE" && t.text != "FALSE") {
+			return nil, fmt.Errorf("expected NULL, MISSING, TRUE or FALSE after IS")
+		}
+		return &isExpr{expr: left, what: t.text, negate: negate}, nil
+	}
+
+	negate := false
+	if p.isKeyword("NOT") {
+		if next := p.tokens[p.pos+1]; next.kind == tokKeyword && (next.text == "LIKE" || next.text == "IN" || next.text == "BETWEEN") {
+			p.next()
+			negate = true
+		}
+	}
+	switch {
+	case p.acceptKeyword("LIKE"):
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		like := &likeExpr{expr: left, pattern: pattern, negate: negate}
+		if p.acceptKeyword("ESCAPE") {
+			if like.escape, err = p.parseAdditive(); err != nil {
+				return nil, err
+			}
+		}
+		return like, nil
+	case p.acceptKeyword("IN"):
+		if err := p.expectOperator("("); err != nil {
+			return nil, err
+		}
+		in := &inExpr{expr: left, negate: negate}
+		for {
+			item, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, item)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return in, nil
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &betweenExpr{expr: left, low: low, high: high, negate: negate}, nil
+	}
+	if negate {
+		return nil, p.errorf("unexpected NOT")
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+") || p.isOperator("-") || p.isOperator("||") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*") || p.isOperator("/") || p.isOperator("%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticExpr{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.acceptOperator("-") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &arithmeticExpr{op: "-", left: &literalExpr{value: NewInt(0)}, right: e}, nil
+	}
+	if p.acceptOperator("+") {
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokNumber:
+		p.next()
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return &literalExpr{value: NewInt(i)}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", t.text)
+		}
+		return &literalExpr{value: NewFloat(f)}, nil
+	case tokString:
+		p.next()
+		return &literalExpr{value: NewString(t.text)}, nil
+	case tokKeyword:
+		switch t.text {
+		case "NULL":
+			p.next()
+			return &literalExpr{value: Null}, nil
+		case "MISSING":
+			p.next()
+			return &literalExpr{value: Missing}, nil
+		case "TRUE", "FALSE":
+			p.next()
+			return &literalExpr{value: NewBool(t.text == "TRUE")}, nil
+		case "CAST":
+			return p.parseCast()
+		case "CASE":
+			return p.parseCase()
+		}
+	case tokOperator:
+		if t.text == "(" {
+			p.next()
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			return e, nil
+		}
+	case tokIdent:
+		if next := p.tokens[p.pos+1]; next.kind == tokOperator && next.text == "(" {
+			return p.parseFunction()
+		}
+		return p.parsePath()
+	case tokQuotedIdent:
+		return p.parsePath()
+	}
+	return nil, p.errorf("unexpected token")
+}
+
+func (p *parser) parseCast() (expr, error) {
+	p.next()
+	if err := p.expectOperator("("); err != nil {
+		return nil, err
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	if t.kind != tokIdent {
+		return nil, fmt.Errorf("expected type name in CAST")
+	}
+	typeName := strings.ToUpper(t.text)
+	if _, err := castValue(NewString("0"), typeName); err != nil && strings.HasPrefix(err.Error(), "unsupported") {
+		return nil, err
+	}
+	if err := p.expectOperator(")"); err != nil {
+		return nil, err
+	}
+	return &castExpr{expr: e, typeName: typeName}, nil
+}
+
+func (p *parser) parseCase() (expr, error) {
+	p.next()
+	c := &caseExpr{}
+	if !p.isKeyword("WHEN") {
+		operand, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.operand = operand
+	}
+	for p.acceptKeyword("WHEN") {
+		when, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		then, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, when)
+		c.thens = append(c.thens, then)
+	}
+	if len(c.whens) == 0 {
+		return nil, p.errorf("expected WHEN")
+	}
+	if p.acceptKeyword("ELSE") {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		c.elseExpr = e
+	}
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *parser) parseFunction() (expr, error) {
+	name := strings.ToUpper(p.next().text)
+	p.next() // (
+
+	if aggregateFuncs[name] {
+		if p.inAggregate {
+			return nil, fmt.Errorf("nested aggregate function %s", name)
+		}
+		agg := &aggregateExpr{name: name}
+		if name == "COUNT" && p.acceptOperator("*") {
+			agg.star = true
+		} else {
+			p.inAggregate = true
+			arg, err := p.parseExpr()
+			p.inAggregate = false
+			if err != nil {
+				return nil, err
+			}
+			agg.arg = arg
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		p.aggregates = append(p.aggregates, agg)
+		return agg, nil
+	}
+
+	if _, found := scalarFuncs[name]; !found {
+		return nil, fmt.Errorf("unsupported function %s", name)
+	}
+	f := &funcExpr{name: name}
+	if p.acceptOperator(")") {
+		return f, checkArity(f)
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		f.args = append(f.args, arg)
+		// SUBSTRING(s FROM start FOR length)
+		if name == "SUBSTRING" && p.acceptKeyword("FROM") {
+			continue
+		}
+		if name == "SUBSTRING" && p.acceptKeyword("FOR") {
+			continue
+		}
+		if !p.acceptOperator(",") {
+			break
+		}
+	}
+	if err := p.expectOperator(")"); err != nil {
+		return nil, err
+	}
+	return f, checkArity(f)
+}
+
+func (p *parser) parsePath() (expr, error) {
+	t := p.next()
+	p.sawColumn = p.sawColumn || !p.inAggregate
+	path := &pathExpr{steps: []pathStep{{name: t.text, quoted: t.kind == tokQuotedIdent}}}
+	for {
+		if p.acceptOperator(".") {
+			n := p.next()
+			if n.kind != tokIdent && n.kind != tokQuotedIdent && n.kind != tokKeyword {
+				return nil, fmt.Errorf("invalid path component %q", n.text)
+			}
+			path.steps = append(path.steps, pathStep{name: n.text, quoted: n.kind == tokQuotedIdent})
+			continue
+		}
+		if p.acceptOperator("[") {
+			n := p.next()
+			switch n.kind {
+			case tokNumber:
+				index, err := strconv.Atoi(n.text)
+				if err != nil {
+					return nil, fmt.Errorf("invalid array index %q", n.text)
+				}
+				path.steps = append(path.steps, pathStep{index: index, isIndex: true})
+			case tokString:
+				path.steps = append(path.steps, pathStep{name: n.text, quoted: true})
+			default:
+				return nil, fmt.Errorf("invalid path index %q", n.text)
+			}
+			if err := p.expectOperator("]"); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return path, nil
+	}
+}
@@ -0,0 +1,253 @@
+package s3select
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+)
+
+const outputBufferSize = 64 * 1024
+
+// Stats reports the number of bytes a query has read and returned.
+type Stats struct {
+	BytesScanned   int64
+	BytesProcessed int64
+	BytesReturned  int64
+}
+
+// EvaluationError is returned by Run if the expression fails on a record, e.g. a failed CAST,
+// as opposed to errors reading or parsing the object content.
+type EvaluationError struct {
+	Err error
+}
+
+func (e *EvaluationError) Error() string { return e.Err.Error() }
+func (e *EvaluationError) Unwrap() error { return e.Err }
+
+// Select is a prepared S3 Select query. It is not safe for concurrent use.
+type Select struct {
+	stmt   *Statement
+	input  *volume_server_pb.QueryRequest_InputSerialization
+	writer recordWriter
+}
+
+// NewSelect parses the SQL expression and validates the input and output serialization.
+func NewSelect(expression string, input *volume_server_pb.QueryRequest_InputSerialization, output *volume_server_pb.QueryRequest_OutputSerialization) (*Select, error) {
+	stmt, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	if input == nil {
+		return nil, fmt.Errorf("missing input serialization")
+	}
+	formats := 0
+	for _, set := range []bool{input.CsvInput != nil, input.JsonInput != nil, input.ParquetInput != nil} {
+		if set {
+			formats++
+		}
+	}
+	if formats != 1 {
+		return nil, fmt.Errorf("exactly one of CSV, JSON or Parquet input is required")
+	}
+	if input.ParquetInput != nil && input.CompressionType != "" && !strings.EqualFold(input.CompressionType, "NONE") {
+		return nil, fmt.Errorf("compression is not supported for Parquet input")
+	}
+	switch strings.ToUpper(input.CompressionType) {
+	case "", "NONE", "GZIP", "BZIP2":
+	default:
+		return nil, fmt.Errorf("unsupported compression type %s", input.CompressionType)
+	}
+
+	s := &Select{stmt: stmt, input: input}
+	switch {
+	case output == nil:
+		return nil, fmt.Errorf("missing output serialization")
+	case output.CsvOutput != nil && output.JsonOutput != nil:
+		return nil, fmt.Errorf("only one of CSV or JSON output is allowed")
+	case output.CsvOutput != nil:
+		s.writer = newCSVWriter(output.CsvOutput)
+	case output.JsonOutput != nil:
+		s.writer = newJSONWriter(output.JsonOutput)
+	default:
+		return nil, fmt.Errorf("missing output format")
+	}
+	return s, nil
+}
+
+// Run evaluates the query over the object content read sequentially from r, and writes
+// the serialized records to w. Parquet input is buffered in memory, use RunAt to avoid that.
+func (s *Select) Run(r io.Reader, w io.Writer) (Stats, error) {
+	if s.input.ParquetInput != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return Stats{}, err
+		}
+		return s.RunAt(bytes.NewReader(data), int64(len(data)), w)
+	}
+	scanned := &countingReader{r: r}
+	stats, err := s.runStream(scanned, w)
+	stats.BytesScanned = scanned.n
+	return stats, err
+}
+
+// RunAt evaluates the query over the object content and writes the serialized records to w.
+func (s *Select) RunAt(content io.ReaderAt, size int64, w io.Writer) (Stats, error) {
+	if s.input.ParquetInput == nil {
+		return s.Run(io.NewSectionReader(content, 0, size), w)
+	}
+	scanned := &countingReaderAt{r: content}
+	reader, err := newParquetReader(scanned, size)
+	if err != nil {
+		return Stats{}, err
+	}
+	stats, err := s.run(reader, w)
+	stats.BytesScanned, stats.BytesProcessed = scanned.n, scanned.n
+	return stats, err
+}
+
+func (s *Select) runStream(r io.Reader, w io.Writer) (stats Stats, err error) {
+	decompressed, err := decompress(s.input.CompressionType, r)
+	if err != nil {
+		return
+	}
+	processed := &countingReader{r: decompressed}
+	var reader recordReader
+	if s.input.CsvInput != nil {
+		reader, err = newCSVReader(processed, s.input.CsvInput)
+	} else {
+		reader, err = newJSONReader(processed, s.input.JsonInput)
+	}
+	if err != nil {
+		return
+	}
+	stats, err = s.run(reader, w)
+	stats.BytesProcessed = processed.n
+	return
+}
+
+func (s *Select) run(reader recordReader, w io.Writer) (stats Stats, err error) {
+	stmt := s.stmt
+	for _, agg := range stmt.aggregates {
+		agg.reset()
+	}
+
+	var buf []byte
+	flush := func() error {
+		if len(buf) == 0 {
+			return nil
+		}
+		n, writeErr := w.Write(buf)
+		stats.BytesReturned += int64(n)
+		buf = buf[:0]
+		return writeErr
+	}
+
+	var returned int64
+	done := stmt.Limit == 0 && !stmt.IsAggregate()
+	for !done {
+		record, readErr := reader.Read()
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return stats, readErr
+		}
+		for _, row := range expandFrom(record, stmt.From) {
+			if stmt.Where != nil {
+				matched, ok, whereErr := truth(stmt.Where, row)
+				if whereErr != nil {
+					return stats, &EvaluationError{whereErr}
+				}
+				if !ok || !matched {
+					continue
+				}
+			}
+			if stmt.IsAggregate() {
+				for _, agg := range stmt.aggregates {
+					if accumulateErr := agg.accumulate(row); accumulateErr != nil {
+						return stats, &EvaluationError{accumulateErr}
+					}
+				}
+				continue
+			}
+			if buf, err = s.appendRow(buf, row); err != nil {
+				return stats, &EvaluationError{err}
+			}
+			returned++
+			if stmt.Limit >= 0 && returned >= stmt.Limit {
+				done = true
+				break
+			}
+		}
+		if len(buf) >= outputBufferSize {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+	}
+
+	if stmt.IsAggregate() {
+		if buf, err = s.appendRow(buf, Null); err != nil {
+			return stats, &EvaluationError{err}
+		}
+	}
+	err = flush()
+	return
+}
+
+func (s *Select) appendRow(buf []byte, row Value) ([]byte, error) {
+	if len(s.stmt.Items) == 0 {
+		switch row.kind {
+		case KindObject:
+			return s.writer.appendRecord(buf, row.obj.Keys, row.obj.Values), nil
+		case KindList:
+			return s.writer.appendRecord(buf, positionalNames(len(row.list)), row.list), nil
+		}
+		return s.writer.appendRecord(buf, positionalNames(1), []Value{row}), nil
+	}
+
+	names := make([]string, len(s.stmt.Items))
+	values := make([]Value, len(s.stmt.Items))
+	for i, item := range s.stmt.Items {
+		v, err := item.expr.eval(row)
+		if err != nil {
+			return buf, err
+		}
+		names[i], values[i] = item.columnName(i), v
+	}
+	return s.writer.appendRecord(buf, names, values), nil
+}
+
+func positionalNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = "_" + strconv.Itoa(i+1)
+	}
+	return names
+}
+
+// expandFrom applies the FROM path, e.g. S3Object[*].items[*], to a record
+func expandFrom(record Value, steps []pathStep) []Value {
+	values := []Value{record}
+	for _, step := range steps {
+		var next []Value
+		for _, v := range values {
+			if step.wildcard {
+				if v.kind == KindList {
+					next = append(next, v.list...)
+				}
+				continue
+			}
+			if found := navigate(v, []pathStep{step}); found.kind != KindMissing {
+				next = append(next, found)
+			}
+		}
+		values = next
+	}
+	return values
+}
@@ -0,0 +1,234 @@
+package s3select
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+)
+
+const testCSV = `name,age,city
+alice,31,Berlin
+bob,25,"New York, NY"
+# a comment line
+carol,47,Paris
+dave,,Berlin
+`
+
+const testJSONLines = `{"name":"alice","age":31,"tags":["a","b"],"address":{"city":"Berlin"}}
+{"name":"bob","age":25,"tags":[],"address":{"city":"New York"}}
+{"name":"carol","age":47.5,"address":{"city":"Paris"}}
+`
+
+func csvInput(header string) *volume_server_pb.QueryRequest_InputSerialization {
+	return &volume_server_pb.QueryRequest_InputSerialization{
+		CsvInput: &volume_server_pb.QueryRequest_InputSerialization_CSVInput{FileHeaderInfo: header, Comments: "#"},
+	}
+}
+
+func jsonInput() *volume_server_pb.QueryRequest_InputSerialization {
+	return &volume_server_pb.QueryRequest_InputSerialization{
+		JsonInput: &volume_server_pb.QueryRequest_InputSerialization_JSONInput{Type: "LINES"},
+	}
+}
+
+var (
+	csvOutput  = &volume_server_pb.QueryRequest_OutputSerialization{CsvOutput: &volume_server_pb.QueryRequest_OutputSerialization_CSVOutput{}}
+	jsonOutput = &volume_server_pb.QueryRequest_OutputSerialization{JsonOutput: &volume_server_pb.QueryRequest_OutputSerialization_JSONOutput{}}
+)
+
+func runSelect(t *testing.T, sql string, input *volume_server_pb.QueryRequest_InputSerialization, output *volume_server_pb.QueryRequest_OutputSerialization, data []byte) string {
+	t.Helper()
+	s, err := NewSelect(sql, input, output)
+	if err != nil {
+		t.Fatalf("NewSelect(%q): %v", sql, err)
+	}
+	var out bytes.Buffer
+	stats, err := s.RunAt(bytes.NewReader(data), int64(len(data)), &out)
+	if err != nil {
+		t.Fatalf("Run(%q): %v", sql, err)
+	}
+	if stats.BytesReturned != int64(out.Len()) {
+		t.Errorf("BytesReturned = %d, want %d", stats.BytesReturned, out.Len())
+	}
+	return out.String()
+}
+
+func TestSelectCSV(t *testing.T) {
+	tests := []struct {
+		sql    string
+		header string
+		output *volume_server_pb.QueryRequest_OutputSerialization
+		want   string
+	}{
+		{"SELECT * FROM S3Object", "USE", csvOutput, "alice,31,Berlin\nbob,25,\"New York, NY\"\ncarol,47,Paris\ndave,,Berlin\n"},
+		{"SELECT s.name FROM S3Object s WHERE s.age > 30", "USE", csvOutput, "alice\ncarol\n"},
+		{"SELECT s._1, s._3 FROM S3Object s WHERE s._3 = 'Berlin' LIMIT 1", "IGNORE", csvOutput, "alice,Berlin\n"},
+		{"SELECT name, CAST(age AS INT) + 1 AS next FROM S3Object WHERE city LIKE 'New%'", "USE", jsonOutput, "{\"name\":\"bob\",\"next\":26}\n"},
+		{"SELECT s.name FROM S3Object s WHERE s.age = '' OR s.name IN ('bob', 'zed')", "USE", csvOutput, "bob\ndave\n"},
+		{"SELECT UPPER(name) FROM S3Object WHERE age BETWEEN 30 AND 40", "USE", csvOutput, "ALICE\n"},
+		{"SELECT COUNT(*), SUM(CAST(age AS INT)), MAX(age), MIN(name) FROM S3Object WHERE age <> ''", "USE", csvOutput, "3,103,47,alice\n"},
+		{"SELECT AVG(age) AS avg FROM S3Object s WHERE s.city = 'Berlin'", "USE", jsonOutput, "{\"avg\":31}\n"},
+		{"SELECT COUNT(*) FROM S3Object", "NONE", csvOutput, "5\n"},
+	}
+	for _, tt := range tests {
+		if got := runSelect(t, tt.sql, csvInput(tt.header), tt.output, []byte(testCSV)); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestSelectCSVDelimiters(t *testing.T) {
+	input := &volume_server_pb.QueryRequest_InputSerialization{
+		CsvInput: &volume_server_pb.QueryRequest_InputSerialization_CSVInput{
+			RecordDelimiter: ";",
+			FieldDelimiter:  "|",
+			QuoteCharacter:  "'",
+
+			AllowQuotedRecordDelimiter: true,
+		},
+	}
+	output := &volume_server_pb.QueryRequest_OutputSerialization{
+		CsvOutput: &volume_server_pb.QueryRequest_OutputSerialization_CSVOutput{QuoteFields: "ALWAYS", FieldDelimiter: "\t"},
+	}
+	got := runSelect(t, "SELECT _2, _1 FROM S3Object", input, output, []byte("a|'b;c';d|e"))
+	if want := "\"b;c\"\t\"a\"\n\"e\"\t\"d\"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSelectJSON(t *testing.T) {
+	tests := []struct {
+		sql    string
+		output *volume_server_pb.QueryRequest_OutputSerialization
+		want   string
+	}{
+		{"SELECT * FROM S3Object[*] s WHERE s.name = 'bob'", jsonOutput, "{\"name\":\"bob\",\"age\":25,\"tags\":[],\"address\":{\"city\":\"New York\"}}\n"},
+		{"SELECT s.name, s.address.city FROM S3Object s WHERE s.age >= 31", csvOutput, "alice,Berlin\ncarol,Paris\n"},
+		{"SELECT s.tags[1] AS second FROM S3Object s WHERE s.tags IS NOT MISSING", jsonOutput, "{\"second\":\"b\"}\n{}\n"},
+		{"SELECT s.name FROM S3Object s WHERE s.tags IS MISSING", csvOutput, "carol\n"},
+		{"SELECT MAX(s.age), COUNT(s.tags) FROM S3Object s", csvOutput, "47.5,2\n"},
+		{"SELECT CASE WHEN s.age > 30 THEN 'senior' ELSE 'junior' END AS level FROM S3Object s LIMIT 2", csvOutput, "senior\njunior\n"},
+	}
+	for _, tt := range tests {
+		if got := runSelect(t, tt.sql, jsonInput(), tt.output, []byte(testJSONLines)); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestSelectJSONDocumentPath(t *testing.T) {
+	doc := `{"records":[{"id":1,"ok":true},{"id":2,"ok":false},{"id":3,"ok":true}]}`
+	input := &volume_server_pb.QueryRequest_InputSerialization{
+		JsonInput: &volume_server_pb.QueryRequest_InputSerialization_JSONInput{Type: "DOCUMENT"},
+	}
+	got := runSelect(t, "SELECT r.id FROM S3Object[*].records[*] r WHERE r.ok = true", input, csvOutput, []byte(doc))
+	if want := "1\n3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSelectGzip(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(testJSONLines))
+	gz.Close()
+
+	input := jsonInput()
+	input.CompressionType = "GZIP"
+	s, err := NewSelect("SELECT s.name FROM S3Object s", input, csvOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	stats, err := s.Run(bytes.NewReader(compressed.Bytes()), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "alice\nbob\ncarol\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if stats.BytesScanned != int64(compressed.Len()) || stats.BytesProcessed != int64(len(testJSONLines)) {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSelectParquet(t *testing.T) {
+	type row struct {
+		Name  string  `parquet:"name"`
+		Score float64 `parquet:"score"`
+		Level int32   `parquet:"level"`
+	}
+	var data bytes.Buffer
+	if err := parquet.Write(&data, []row{{"alice", 9.5, 3}, {"bob", 4, 1}, {"carol", 7.25, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	input := &volume_server_pb.QueryRequest_InputSerialization{ParquetInput: &volume_server_pb.QueryRequest_InputSerialization_ParquetInput{}}
+
+	if got, want := runSelect(t, "SELECT * FROM S3Object s WHERE s.level > 1", input, jsonOutput, data.Bytes()),
+		"{\"name\":\"alice\",\"score\":9.5,\"level\":3}\n{\"name\":\"carol\",\"score\":7.25,\"level\":2}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := runSelect(t, "SELECT SUM(s.score) FROM S3Object s", input, csvOutput, data.Bytes()), "20.75\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, sql := range []string{
+		"",
+		"SELECT",
+		"SELECT * FROM table",
+		"SELECT name, COUNT(*) FROM S3Object",
+		"SELECT * FROM S3Object WHERE COUNT(*) > 1",
+		"SELECT * FROM S3Object LIMIT -1",
+		"SELECT * FROM S3Object WHERE name = 'unterminated",
+		"SELECT FOO(name) FROM S3Object",
+		"SELECT CAST(name AS BLOB) FROM S3Object",
+		"SELECT * FROM S3Object extra tokens",
+	} {
+		if _, err := Parse(sql); err == nil {
+			t.Errorf("expected error for %q", sql)
+		}
+	}
+}
+
+func TestMatchLike(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"error: disk full", "error%", true},
+		{"warning", "error%", false},
+		{"abc", "a_c", true},
+		{"abbc", "a_c", false},
+		{"100%", `100\%`, true},
+		{"1000", `100\%`, false},
+		{"", "%", true},
+	}
+	for _, tt := range tests {
+		if got := matchLike([]rune(tt.s), []rune(tt.pattern), '\\'); got != tt.want {
+			t.Errorf("matchLike(%q, %q) = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+	if !strings.Contains(NewFloat(1.5).String(), "1.5") {
+		t.Errorf("unexpected float formatting")
+	}
+}
+
+func TestSelectEvaluationError(t *testing.T) {
+	s, err := NewSelect("SELECT CAST(name AS INT) FROM S3Object", csvInput("USE"), csvOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	_, err = s.RunAt(strings.NewReader(testCSV), int64(len(testCSV)), &out)
+	var evalErr *EvaluationError
+	if !errors.As(err, &evalErr) {
+		t.Errorf("expected an evaluation error, got %v", err)
+	}
+}
@@ -0,0 +1,318 @@
+package s3select
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Kind int
+
+const (
+	KindMissing Kind = iota
+	KindNull
+	KindBool
+	KindInt
+	KindFloat
+	KindString
+	KindTimestamp
+	KindList
+	KindObject
+)
+
+// Value is a single SQL value. CSV fields are always strings, JSON and Parquet
+// records can carry any kind, including nested lists and objects.
+type Value struct {
+	kind Kind
+	b    bool
+	i    int64
+	f    float64
+	s    string
+	t    time.Time
+	list []Value
+	obj  *Object
+}
+
+// Object keeps the keys of a record in their original order.
+type Object struct {
+	Keys   []string
+	Values []Value
+}
+
+var (
+	Missing = Value{kind: KindMissing}
+	Null    = Value{kind: KindNull}
+)
+
+func NewBool(b bool) Value           { return Value{kind: KindBool, b: b} }
+func NewInt(i int64) Value           { return Value{kind: KindInt, i: i} }
+func NewFloat(f float64) Value       { return Value{kind: KindFloat, f: f} }
+func NewString(s string) Value       { return Value{kind: KindString, s: s} }
+func NewTimestamp(t time.Time) Value { return Value{kind: KindTimestamp, t: t} }
+func NewList(list []Value) Value     { return Value{kind: KindList, list: list} }
+func NewObject(obj *Object) Value    { return Value{kind: KindObject, obj: obj} }
+
+func (v Value) Kind() Kind { return v.kind }
+
+// IsNull is true for both NULL and MISSING values.
+func (v Value) IsNull() bool { return v.kind == KindNull || v.kind == KindMissing }
+
+func (o *Object) Add(key string, value Value) {
+	o.Keys = append(o.Keys, key)
+	o.Values = append(o.Values, value)
+}
+
+// Get looks up a key, exact match first and case-insensitively unless caseSensitive is set.
+// CSV style positional names _1, _2, ... are resolved by position.
+func (o *Object) Get(key string, caseSensitive bool) (Value, bool) {
+	for i, k := range o.Keys {
+		if k == key {
+			return o.Values[i], true
+		}
+	}
+	if !caseSensitive {
+		for i, k := range o.Keys {
+			if strings.EqualFold(k, key) {
+				return o.Values[i], true
+			}
+		}
+	}
+	if strings.HasPrefix(key, "_") {
+		if pos, err := strconv.Atoi(key[1:]); err == nil && pos >= 1 && pos <= len(o.Values) {
+			return o.Values[pos-1], true
+		}
+	}
+	return Missing, false
+}
+
+// String returns the value as it is written into CSV output.
+func (v Value) String() string {
+	switch v.kind {
+	case KindBool:
+		return strconv.FormatBool(v.b)
+	case KindInt:
+		return strconv.FormatInt(v.i, 10)
+	case KindFloat:
+		return strconv.FormatFloat(v.f, 'f', -1, 64)
+	case KindString:
+		return v.s
+	case KindTimestamp:
+		return v.t.Format(time.RFC3339Nano)
+	case KindList, KindObject:
+		return string(v.appendJSON(nil))
+	}
+	return ""
+}
+
+func (v Value) appendJSON(buf []byte) []byte {
+	switch v.kind {
+	case KindMissing, KindNull:
+		return append(buf, "null"...)
+	case KindBool, KindInt:
+		return append(buf, v.String()...)
+	case KindFloat:
+		if math.IsInf(v.f, 0) || math.IsNaN(v.f) {
+			return append(buf, "null"...)
+		}
+		return append(buf, v.String()...)
+	case KindString, KindTimestamp:
+		return strconv.AppendQuote(buf, v.String())
+	case KindList:
+		buf = append(buf, '[')
+		for i, item := range v.list {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = item.appendJSON(buf)
+		}
+		return append(buf, ']')
+	case KindObject:
+		return v.obj.appendJSON(buf)
+	}
+	return buf
+}
+
+func (o *Object) appendJSON(buf []byte) []byte {
+	buf = append(buf, '{')
+	n := 0
+	for i, key := range o.Keys {
+		if o.Values[i].kind == KindMissing {
+			continue
+		}
+		if n > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendQuote(buf, key)
+		buf = append(buf, ':')
+		buf = o.Values[i].appendJSON(buf)
+		n++
+	}
+	return append(buf, '}')
+}
+
+func (v Value) isNumber() bool {
+	return v.kind == KindInt || v.kind == KindFloat
+}
+
+func (v Value) toFloat() (float64, bool) {
+	switch v.kind {
+	case KindInt:
+		return float64(v.i), true
+	case KindFloat:
+		return v.f, true
+	case KindString:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v.s), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toNumber converts strings into numbers, so that unquoted CSV fields can be
+// compared with numeric literals without an explicit CAST.
+func (v Value) toNumber() (Value, bool) {
+	switch v.kind {
+	case KindInt, KindFloat:
+		return v, true
+	case KindString:
+		s := strings.TrimSpace(v.s)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return NewInt(i), true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return NewFloat(f), true
+		}
+	}
+	return Null, false
+}
+
+func (v Value) toBool() (bool, bool) {
+	switch v.kind {
+	case KindBool:
+		return v.b, true
+	case KindString:
+		b, err := strconv.ParseBool(strings.TrimSpace(v.s))
+		return b, err == nil
+	case KindInt:
+		return v.i != 0, true
+	}
+	return false, false
+}
+
+func (v Value) toTimestamp() (time.Time, bool) {
+	switch v.kind {
+	case KindTimestamp:
+		return v.t, true
+	case KindString:
+		return parseTimestamp(v.s)
+	}
+	return time.Time{}, false
+}
+
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04Z07:00", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compare returns -1, 0 or 1. ok is false if the two values are not comparable.
+func compare(a, b Value) (int, bool) {
+	if a.IsNull() || b.IsNull() {
+		return 0, false
+	}
+	if a.isNumber() || b.isNumber() {
+		x, ok1 := a.toNumber()
+		y, ok2 := b.toNumber()
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		if x.kind == KindInt && y.kind == KindInt {
+			return cmpOrdered(x.i, y.i), true
+		}
+		xf, _ := x.toFloat()
+		yf, _ := y.toFloat()
+		return cmpOrdered(xf, yf), true
+	}
+	if a.kind == KindTimestamp || b.kind == KindTimestamp {
+		x, ok1 := a.toTimestamp()
+		y, ok2 := b.toTimestamp()
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		return x.Compare(y), true
+	}
+	if a.kind == KindBool || b.kind == KindBool {
+		x, ok1 := a.toBool()
+		y, ok2 := b.toBool()
+		if !ok1 || !ok2 {
+			return 0, false
+		}
+		switch {
+		case x == y:
+			return 0, true
+		case !x:
+			return -1, true
+		}
+		return 1, true
+	}
+	if a.kind == KindString && b.kind == KindString {
+		return strings.Compare(a.s, b.s), true
+	}
+	return 0, false
+}
+
+func cmpOrdered[T int64 | float64](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+func castValue(v Value, typeName string) (Value, error) {
+	if v.IsNull() {
+		return v, nil
+	}
+	switch typeName {
+	case "INT", "INTEGER":
+		if v.kind == KindFloat {
+			return NewInt(int64(v.f)), nil
+		}
+		if n, ok := v.toNumber(); ok {
+			if n.kind == KindFloat {
+				return NewInt(int64(n.f)), nil
+			}
+			return n, nil
+		}
+		if b, ok := v.toBool(); ok && v.kind == KindBool {
+			if b {
+				return NewInt(1), nil
+			}
+			return NewInt(0), nil
+		}
+	case "FLOAT", "DECIMAL", "NUMERIC", "REAL", "DOUBLE":
+		if f, ok := v.toFloat(); ok {
+			return NewFloat(f), nil
+		}
+	case "STRING", "VARCHAR", "CHAR", "TEXT":
+		return NewString(v.String()), nil
+	case "BOOL", "BOOLEAN":
+		if b, ok := v.toBool(); ok {
+			return NewBool(b), nil
+		}
+	case "TIMESTAMP":
+		if t, ok := v.toTimestamp(); ok {
+			return NewTimestamp(t), nil
+		}
+	default:
+		return Null, fmt.Errorf("unsupported cast type %s", typeName)
+	}
+	return Null, fmt.Errorf("cannot cast %q to %s", v.String(), typeName)
+}
@@ -34,6 +34,8 @@ type FilerSink struct {
 	isIncremental     bool
 	executor          *util.LimitedConcurrentExecutor
 	signature         int32
+	// s3 buckets folder of the target with multi-site conflict resolution, see EnableMultiSite
+	multiSiteBucketsPath string
 }
 
 func init() {
@@ -104,6 +106,10 @@ func (fs *FilerSink) DeleteEntry(key string, isDirectory, deleteIncludeChunks bo
 }
 
 func (fs *FilerSink) CreateEntry(key string, entry *filer_pb.Entry, signatures []int32) error {
+	return fs.createEntry(key, entry, signatures, true)
+}
+
+func (fs *FilerSink) createEntry(key string, entry *filer_pb.Entry, signatures []int32, checkExisting bool) error {
 
 	return fs.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
 
@@ -115,12 +121,17 @@ func (fs *FilerSink) CreateEntry(key string, entry *filer_pb.Entry, signatures [
 			Name:      name,
 		}
 		// glog.V(1).Infof("lookup: %v", lookupRequest)
-		if resp, err := filer_pb.LookupEntry(context.Background(), client, lookupRequest); err == nil {
-			if filer.ETag(resp.Entry) == filer.ETag(entry) {
+		if !checkExisting {
+			// conflicts are resolved already
+		} else if resp, err := filer_pb.LookupEntry(context.Background(), client, lookupRequest); err == nil {
+			if fs.isMultiSite(key) {
+				if apply, err := fs.resolveConflict(key, nil, entry, resp.Entry, signatures); err != nil || !apply {
+					return err
+				}
+			} else if filer.ETag(resp.Entry) == filer.ETag(entry) {
 				glog.V(3).Infof("already replicated %s", key)
 				return nil
-			}
-			if resp.Entry.Attributes != nil && resp.Entry.Attributes.Mtime >= entry.Attributes.Mtime {
+			} else if resp.Entry.Attributes != nil && resp.Entry.Attributes.Mtime >= entry.Attributes.Mtime {
 				glog.V(3).Infof("skip overwriting %s", key)
 				return nil
 			}
@@ -192,7 +203,14 @@ func (fs *FilerSink) UpdateEntry(key string, oldEntry *filer_pb.Entry, newParent
 
 	glog.V(4).Infof("oldEntry %+v, newEntry %+v, existingEntry: %+v", oldEntry, newEntry, existingEntry)
 
-	if existingEntry.Attributes.Mtime > newEntry.Attributes.Mtime {
+	if fs.isMultiSite(key) && siteClockOf(existingEntry) != siteClockOf(oldEntry) {
+		// changed on the target since the source version the change was made on
+		apply, err := fs.resolveConflict(key, oldEntry, newEntry, existingEntry, signatures)
+		if err != nil || !apply {
+			return true, err
+		}
+		return true, fs.createEntry(key, newEntry, signatures, false)
+	} else if !fs.isMultiSite(key) && existingEntry.Attributes.Mtime > newEntry.Attributes.Mtime {
 		// skip if already changed
 		// this usually happens when the messages are not ordered
 		glog.V(2).Infof("late updates %s", key)
@@ -0,0 +1,229 @@
+package filersink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// EnableMultiSite resolves changes to the s3 buckets that conflict with changes
+// made on the target site, when both sites accept writes. Changes are ordered
+// by their site clocks, so both sites keep the same change. The other change is
+// kept as an older version in versioned buckets, or as a conflict copy next to
+// the object in other buckets. Deletes do not remove objects changed on the target.
+func (fs *FilerSink) EnableMultiSite(bucketsPath string) {
+	fs.multiSiteBucketsPath = strings.TrimSuffix(bucketsPath, "/")
+}
+
+func (fs *FilerSink) isMultiSite(key string) bool {
+	return fs.multiSiteBucketsPath != "" && strings.HasPrefix(key, fs.multiSiteBucketsPath+"/")
+}
+
+func siteClockOf(entry *filer_pb.Entry) filer.SiteClock {
+	var mtime int64
+	if entry.Attributes != nil {
+		mtime = entry.Attributes.Mtime
+	}
+	return filer.GetSiteClock(entry.Extended, mtime)
+}
+
+// conflictCopyKey names the copy of the losing change of a conflict,
+// the same on both sites
+func conflictCopyKey(key string, loser filer.SiteClock) string {
+	return fmt.Sprintf("%s.conflict-%s-%d", key, util.Nvl(loser.Site, "unknown"), loser.TsNs)
+}
+
+// resolveConflict checks a replicated change against the entry on the target.
+// base is the source entry the change was made on, nil for new entries.
+// It returns whether the change should be written over the existing entry.
+func (fs *FilerSink) resolveConflict(key string, base, incoming, existing *filer_pb.Entry, signatures []int32) (apply bool, err error) {
+	existingClock, incomingClock := siteClockOf(existing), siteClockOf(incoming)
+	if existingClock == incomingClock {
+		glog.V(3).Infof("already replicated %s", key)
+		return false, nil
+	}
+	if base != nil && siteClockOf(base) == existingClock {
+		// not changed on the target since the source version the change was made on
+		return true, nil
+	}
+	incomingWins := existingClock.Before(incomingClock)
+	winner, loser := existingClock, incomingClock
+	if incomingWins {
+		winner, loser = incomingClock, existingClock
+	}
+	conflict := &filer.SiteConflict{
+		Path:   key,
+		Winner: winner.String(),
+		Loser:  loser.String(),
+	}
+
+	if existing.IsDirectory || incoming.IsDirectory {
+		// a versions folder points to the latest version, all versions are kept
+		if existing.IsDirectory && incoming.IsDirectory && strings.HasSuffix(key, s3_constants.VersionsFolder) &&
+			string(existing.Extended[s3_constants.ExtLatestVersionIdKey]) != string(incoming.Extended[s3_constants.ExtLatestVersionIdKey]) {
+			conflict.Resolution = "latest version of the winner, both versions kept"
+			fs.recordConflict(conflict, signatures)
+		}
+		return incomingWins, nil
+	}
+	if base == nil && filer.ETag(existing) == filer.ETag(incoming) {
+		// the same data written on both sites
+		return incomingWins, nil
+	}
+
+	if fs.isVersionedBucket(key) {
+		conflict.Resolution = "both versions kept"
+		fs.recordConflict(conflict, signatures)
+		return incomingWins, nil
+	}
+
+	conflict.CopyPath = conflictCopyKey(key, loser)
+	if incomingWins {
+		if err := fs.moveToConflictCopy(key, conflict.CopyPath, existing, signatures); err != nil {
+			return false, err
+		}
+	} else {
+		copyDir, copyName := util.FullPath(conflict.CopyPath).DirAndName()
+		incomingCopy := &filer_pb.Entry{
+			Name:        copyName,
+			Attributes:  incoming.Attributes,
+			Extended:    incoming.Extended,
+			Chunks:      incoming.GetChunks(),
+			Content:     incoming.Content,
+			RemoteEntry: incoming.RemoteEntry,
+		}
+		if err := fs.createEntry(util.Join(copyDir, copyName), incomingCopy, signatures, false); err != nil {
+			return false, fmt.Errorf("conflict copy %s: %w", conflict.CopyPath, err)
+		}
+	}
+	conflict.Resolution = "conflict copy"
+	fs.recordConflict(conflict, signatures)
+	return incomingWins, nil
+}
+
+// moveToConflictCopy keeps the losing entry of the target under the conflict
+// copy key. The data is kept, and owned by the copy.
+func (fs *FilerSink) moveToConflictCopy(key, copyKey string, existing *filer_pb.Entry, signatures []int32) error {
+	dir, name := util.FullPath(key).DirAndName()
+	copyDir, copyName := util.FullPath(copyKey).DirAndName()
+	return fs.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		existingCopy := &filer_pb.Entry{
+			Name:        copyName,
+			Attributes:  existing.Attributes,
+			Extended:    existing.Extended,
+			Chunks:      existing.GetChunks(),
+			Content:     existing.Content,
+			RemoteEntry: existing.RemoteEntry,
+		}
+		if err := filer_pb.CreateEntry(context.Background(), client, &filer_pb.CreateEntryRequest{
+			Directory:          copyDir,
+			Entry:              existingCopy,
+			IsFromOtherCluster: true,
+			Signatures:         signatures,
+		}); err != nil {
+			return fmt.Errorf("conflict copy %s: %w", copyKey, err)
+		}
+		return filer_pb.DoRemove(context.Background(), client, dir, name, false, false, false, true, signatures)
+	})
+}
+
+// isVersionedBucket is true if versioning is or was enabled on the bucket of the key
+func (fs *FilerSink) isVersionedBucket(key string) bool {
+	bucket, _, _ := strings.Cut(strings.TrimPrefix(key, fs.multiSiteBucketsPath+"/"), "/")
+	var versioning string
+	err := fs.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := filer_pb.LookupEntry(context.Background(), client, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: fs.multiSiteBucketsPath,
+			Name:      bucket,
+		})
+		if err != nil {
+			return err
+		}
+		versioning = string(resp.Entry.Extended[s3_constants.ExtVersioningKey])
+		return nil
+	})
+	if err != nil {
+		glog.Warningf("lookup bucket %s: %v", bucket, err)
+	}
+	return versioning == s3_constants.VersioningEnabled || versioning == s3_constants.VersioningSuspended
+}
+
+// recordConflict saves the conflict on the target, for "s3.conflicts.report".
+// Both sites resolve the same conflict into a record of the same name.
+func (fs *FilerSink) recordConflict(conflict *filer.SiteConflict, signatures []int32) {
+	conflict.DetectedNs = time.Now().UnixNano()
+	glog.V(0).Infof("conflict %s: %s wins over %s, %s", conflict.Path, conflict.Winner, conflict.Loser, conflict.Resolution)
+	data, err := json.Marshal(conflict)
+	if err != nil {
+		glog.Errorf("marshal conflict %s: %v", conflict.Path, err)
+		return
+	}
+	name := fmt.Sprintf("%s-%x.json", strings.ReplaceAll(conflict.Loser, "@", "-"), util.HashStringToLong(conflict.Path))
+	err = fs.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer_pb.CreateEntry(context.Background(), client, &filer_pb.CreateEntryRequest{
+			Directory: filer.SiteConflictsDir,
+			Entry: &filer_pb.Entry{
+				Name: name,
+				Attributes: &filer_pb.FuseAttributes{
+					Mtime:    time.Now().Unix(),
+					Crtime:   time.Now().Unix(),
+					FileMode: uint32(0644),
+					FileSize: uint64(len(data)),
+				},
+				Content: data,
+			},
+			IsFromOtherCluster: true,
+			Signatures:         signatures,
+		})
+	})
+	if err != nil {
+		glog.Errorf("record conflict %s: %v", conflict.Path, err)
+	}
+}
+
+// DeleteEntryIfUnchanged deletes a replicated entry, unless it was changed
+// on the target since the deleted source version, which then is kept
+func (fs *FilerSink) DeleteEntryIfUnchanged(key string, oldEntry *filer_pb.Entry, isDirectory, deleteIncludeChunks bool, signatures []int32) error {
+	if !fs.isMultiSite(key) {
+		return fs.DeleteEntry(key, isDirectory, deleteIncludeChunks, signatures)
+	}
+	dir, name := util.FullPath(key).DirAndName()
+	return fs.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := filer_pb.LookupEntry(context.Background(), client, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: dir,
+			Name:      name,
+		})
+		if err == filer_pb.ErrNotFound {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("lookup %s: %w", key, err)
+		}
+		existing := resp.Entry
+		if existing.IsDirectory {
+			// changes kept inside the folder keep the folder
+			if err := filer_pb.DoRemove(context.Background(), client, dir, name, deleteIncludeChunks, false, false, true, signatures); err != nil {
+				glog.V(1).Infof("keep folder %s: %v", key, err)
+			}
+			return nil
+		}
+		if existingClock := siteClockOf(existing); existingClock != siteClockOf(oldEntry) {
+			fs.recordConflict(&filer.SiteConflict{
+				Path:       key,
+				Winner:     existingClock.String(),
+				Loser:      siteClockOf(oldEntry).String() + " deleted",
+				Resolution: "delete skipped, the concurrent change is kept",
+			}, signatures)
+			return nil
+		}
+		return filer_pb.DoRemove(context.Background(), client, dir, name, deleteIncludeChunks, false, false, true, signatures)
+	})
+}
@@ -0,0 +1,323 @@
+package filersink
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+// testFiler keeps the entries of a target site in memory
+type testFiler struct {
+	filer_pb.UnimplementedSeaweedFilerServer
+	sync.Mutex
+	entries map[string]*filer_pb.Entry
+}
+
+func (f *testFiler) LookupDirectoryEntry(ctx context.Context, req *filer_pb.LookupDirectoryEntryRequest) (*filer_pb.LookupDirectoryEntryResponse, error) {
+	entry := f.get(util.Join(req.Directory, req.Name))
+	if entry == nil {
+		return nil, filer_pb.ErrNotFound
+	}
+	return &filer_pb.LookupDirectoryEntryResponse{Entry: entry}, nil
+}
+
+func (f *testFiler) CreateEntry(ctx context.Context, req *filer_pb.CreateEntryRequest) (*filer_pb.CreateEntryResponse, error) {
+	f.put(util.Join(req.Directory, req.Entry.Name), req.Entry)
+	return &filer_pb.CreateEntryResponse{}, nil
+}
+
+func (f *testFiler) DeleteEntry(ctx context.Context, req *filer_pb.DeleteEntryRequest) (*filer_pb.DeleteEntryResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.entries, util.Join(req.Directory, req.Name))
+	return &filer_pb.DeleteEntryResponse{}, nil
+}
+
+func (f *testFiler) get(path string) *filer_pb.Entry {
+	f.Lock()
+	defer f.Unlock()
+	if entry, found := f.entries[path]; found {
+		return proto.Clone(entry).(*filer_pb.Entry)
+	}
+	return nil
+}
+
+func (f *testFiler) put(path string, entry *filer_pb.Entry) {
+	f.Lock()
+	defer f.Unlock()
+	f.entries[path] = proto.Clone(entry).(*filer_pb.Entry)
+}
+
+// conflicts lists the names of the recorded conflicts
+func (f *testFiler) conflicts() (names []string) {
+	f.Lock()
+	defer f.Unlock()
+	for path := range f.entries {
+		if strings.HasPrefix(path, filer.SiteConflictsDir+"/") {
+			names = append(names, path)
+		}
+	}
+	return
+}
+
+// newTestSite starts a target filer, with a bucket "versioned" of enabled versioning and a bucket "plain"
+func newTestSite(t *testing.T) (*FilerSink, *testFiler) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	target := &testFiler{entries: make(map[string]*filer_pb.Entry)}
+	target.put("/buckets/versioned", &filer_pb.Entry{
+		Name:        "versioned",
+		IsDirectory: true,
+		Extended:    map[string][]byte{s3_constants.ExtVersioningKey: []byte(s3_constants.VersioningEnabled)},
+	})
+	target.put("/buckets/plain", &filer_pb.Entry{Name: "plain", IsDirectory: true})
+	grpcServer := grpc.NewServer()
+	filer_pb.RegisterSeaweedFilerServer(grpcServer, target)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	sink := &FilerSink{
+		grpcAddress:    listener.Addr().String(),
+		grpcDialOption: grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	sink.EnableMultiSite("/buckets")
+	return sink, target
+}
+
+func testSiteEntry(name string, clock filer.SiteClock, content string) *filer_pb.Entry {
+	return &filer_pb.Entry{
+		Name: name,
+		Attributes: &filer_pb.FuseAttributes{
+			Mtime:    clock.TsNs / 1e9,
+			FileSize: uint64(len(content)),
+			Md5:      util.Md5([]byte(content)),
+		},
+		Extended: map[string][]byte{s3_constants.ExtSiteClockKey: []byte(clock.String())},
+		Content:  []byte(content),
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	base := filer.SiteClock{TsNs: 100e9, Site: "a"}
+	older := filer.SiteClock{TsNs: 200e9, Site: "a"}
+	newer := filer.SiteClock{TsNs: 300e9, Site: "b"}
+
+	tests := []struct {
+		name         string
+		key          string
+		base         *filer.SiteClock
+		existing     filer.SiteClock
+		incoming     filer.SiteClock
+		sameContent  bool
+		wantApply    bool
+		wantCopy     string // the content kept in the conflict copy, empty for no copy
+		wantExisting string // the content left at the key, empty if moved
+		wantConflict bool
+	}{
+		{
+			name:         "already replicated",
+			key:          "/buckets/plain/obj",
+			base:         &base,
+			existing:     newer,
+			incoming:     newer,
+			wantApply:    false,
+			wantExisting: "existing",
+		},
+		{
+			name:         "unchanged since base",
+			key:          "/buckets/plain/obj",
+			base:         &base,
+			existing:     base,
+			incoming:     newer,
+			wantApply:    true,
+			wantExisting: "existing",
+		},
+		{
+			name:         "concurrent update, incoming wins",
+			key:          "/buckets/plain/obj",
+			base:         &base,
+			existing:     older,
+			incoming:     newer,
+			wantApply:    true,
+			wantCopy:     "existing",
+			wantConflict: true,
+		},
+		{
+			name:         "concurrent update, incoming loses",
+			key:          "/buckets/plain/obj",
+			base:         &base,
+			existing:     newer,
+			incoming:     older,
+			wantApply:    false,
+			wantCopy:     "incoming",
+			wantExisting: "existing",
+			wantConflict: true,
+		},
+		{
+			name:         "versioned bucket keeps both versions",
+			key:          "/buckets/versioned/obj",
+			base:         &base,
+			existing:     older,
+			incoming:     newer,
+			wantApply:    true,
+			wantExisting: "existing",
+			wantConflict: true,
+		},
+		{
+			name:         "same content created on both sites",
+			key:          "/buckets/plain/obj",
+			existing:     older,
+			incoming:     newer,
+			sameContent:  true,
+			wantApply:    true,
+			wantExisting: "existing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, target := newTestSite(t)
+			_, name := util.FullPath(tt.key).DirAndName()
+			existing := testSiteEntry(name, tt.existing, "existing")
+			target.put(tt.key, existing)
+			incomingContent := "incoming"
+			if tt.sameContent {
+				incomingContent = "existing"
+			}
+			incoming := testSiteEntry(name, tt.incoming, incomingContent)
+			var baseEntry *filer_pb.Entry
+			if tt.base != nil {
+				baseEntry = testSiteEntry(name, *tt.base, "base")
+			}
+
+			apply, err := sink.resolveConflict(tt.key, baseEntry, incoming, existing, nil)
+			if err != nil {
+				t.Fatalf("resolve conflict: %v", err)
+			}
+			if apply != tt.wantApply {
+				t.Errorf("apply = %v, want %v", apply, tt.wantApply)
+			}
+
+			loser := tt.existing
+			if tt.incoming.Before(tt.existing) {
+				loser = tt.incoming
+			}
+			copyEntry := target.get(conflictCopyKey(tt.key, loser))
+			if tt.wantCopy == "" && copyEntry != nil {
+				t.Errorf("unexpected conflict copy %s", copyEntry.Content)
+			} else if tt.wantCopy != "" && (copyEntry == nil || string(copyEntry.Content) != tt.wantCopy) {
+				t.Errorf("conflict copy %v, want %s", copyEntry, tt.wantCopy)
+			}
+			if entry := target.get(tt.key); tt.wantExisting == "" && entry != nil {
+				t.Errorf("existing entry should be moved to the conflict copy")
+			} else if tt.wantExisting != "" && (entry == nil || string(entry.Content) != tt.wantExisting) {
+				t.Errorf("entry %v, want %s", entry, tt.wantExisting)
+			}
+			if conflicts := target.conflicts(); tt.wantConflict != (len(conflicts) == 1) {
+				t.Errorf("recorded conflicts %v, want conflict %v", conflicts, tt.wantConflict)
+			}
+		})
+	}
+}
+
+// TestResolveConflictOnBothSites replicates concurrent updates both ways, and both sites keep the same copy
+func TestResolveConflictOnBothSites(t *testing.T) {
+	key := "/buckets/plain/obj"
+	base := testSiteEntry("obj", filer.SiteClock{TsNs: 100e9, Site: "a"}, "base")
+	changeA := testSiteEntry("obj", filer.SiteClock{TsNs: 200e9, Site: "a"}, "change a")
+	changeB := testSiteEntry("obj", filer.SiteClock{TsNs: 200e9, Site: "b"}, "change b")
+
+	sinkA, siteA := newTestSite(t)
+	siteA.put(key, changeA)
+	sinkB, siteB := newTestSite(t)
+	siteB.put(key, changeB)
+
+	// the change of site b arrives at site a, and wins with the same time
+	if apply, err := sinkA.resolveConflict(key, base, changeB, changeA, nil); err != nil || !apply {
+		t.Fatalf("site a: apply %v, err %v", apply, err)
+	}
+	// the change of site a arrives at site b, and loses
+	if apply, err := sinkB.resolveConflict(key, base, changeA, changeB, nil); err != nil || apply {
+		t.Fatalf("site b: apply %v, err %v", apply, err)
+	}
+
+	copyKey := conflictCopyKey(key, filer.SiteClock{TsNs: 200e9, Site: "a"})
+	copyA, copyB := siteA.get(copyKey), siteB.get(copyKey)
+	if copyA == nil || copyB == nil {
+		t.Fatalf("conflict copy %s on site a %v, on site b %v", copyKey, copyA, copyB)
+	}
+	if string(copyA.Content) != "change a" || string(copyB.Content) != "change a" {
+		t.Errorf("conflict copies %q and %q, want the change of site a", copyA.Content, copyB.Content)
+	}
+	conflictsA, conflictsB := siteA.conflicts(), siteB.conflicts()
+	if len(conflictsA) != 1 || len(conflictsB) != 1 || conflictsA[0] != conflictsB[0] {
+		t.Errorf("conflict records %v on site a and %v on site b, want the same record", conflictsA, conflictsB)
+	}
+}
+
+func TestDeleteEntryIfUnchanged(t *testing.T) {
+	deleted := filer.SiteClock{TsNs: 200e9, Site: "a"}
+	changed := filer.SiteClock{TsNs: 300e9, Site: "b"}
+
+	tests := []struct {
+		name         string
+		key          string
+		existing     *filer.SiteClock
+		wantDeleted  bool
+		wantConflict bool
+	}{
+		{
+			name:        "unchanged since the deleted version",
+			key:         "/buckets/plain/obj",
+			existing:    &deleted,
+			wantDeleted: true,
+		},
+		{
+			name:         "changed concurrently",
+			key:          "/buckets/plain/obj",
+			existing:     &changed,
+			wantDeleted:  false,
+			wantConflict: true,
+		},
+		{
+			name:        "already deleted",
+			key:         "/buckets/plain/obj",
+			wantDeleted: true,
+		},
+		{
+			name:        "outside the buckets",
+			key:         "/data/obj",
+			existing:    &changed,
+			wantDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, target := newTestSite(t)
+			if tt.existing != nil {
+				target.put(tt.key, testSiteEntry("obj", *tt.existing, "existing"))
+			}
+
+			if err := sink.DeleteEntryIfUnchanged(tt.key, testSiteEntry("obj", deleted, "deleted"), false, true, nil); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if isDeleted := target.get(tt.key) == nil; isDeleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", isDeleted, tt.wantDeleted)
+			}
+			if conflicts := target.conflicts(); tt.wantConflict != (len(conflicts) == 1) {
+				t.Errorf("recorded conflicts %v, want conflict %v", conflicts, tt.wantConflict)
+			}
+		})
+	}
+}
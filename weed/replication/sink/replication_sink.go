@@ -17,6 +17,11 @@ type ReplicationSink interface {
 	IsIncremental() bool
 }
 
+// ConditionalDeleteSink deletes replicated entries only if unchanged on the sink since oldEntry
+type ConditionalDeleteSink interface {
+	DeleteEntryIfUnchanged(key string, oldEntry *filer_pb.Entry, isDirectory, deleteIncludeChunks bool, signatures []int32) error
+}
+
 var (
 	Sinks []ReplicationSink
 )
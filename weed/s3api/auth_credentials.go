@@ -9,6 +9,7 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/credential"
 	"github.com/seaweedfs/seaweedfs/weed/filer"
@@ -16,8 +17,10 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/kms"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/sts"
 
 	// Import KMS providers to register them
 	_ "github.com/seaweedfs/seaweedfs/weed/kms/aws"
@@ -50,6 +53,7 @@ type IdentityAccessManagement struct {
 	credentialManager *credential.CredentialManager
 	filerClient       filer_pb.SeaweedFilerClient
 	grpcDialOption    grpc.DialOption
+	stsService        *sts.Service
 }
 
 type Identity struct {
@@ -57,6 +61,10 @@ type Identity struct {
 	Account     *Account
 	Credentials []*Credential
 	Actions     []Action
+	// sessionPolicies are the role and session policies of temporary credentials
+	sessionPolicies []*policy_engine.CompiledPolicy
+	// managedPolicy merges the managed policies attached to the identity and its groups
+	managedPolicy *policy_engine.CompiledPolicy
 }
 
 // Account represents a system user, a system user can
@@ -165,6 +173,9 @@ func NewIdentityAccessManagementWithStore(option *S3ApiServerOption, explicitSto
 			}
 			iam.m.RUnlock()
 		}
+		if reloadingStore, ok := credentialManager.GetStore().(credential.ReloadingStore); ok && reloadingStore.ReloadInterval() > 0 {
+			go iam.reloadPeriodically(reloadingStore.ReloadInterval())
+		}
 	}
 
 	// Only use environment variables as fallback if no configuration was loaded
@@ -216,6 +227,15 @@ func (iam *IdentityAccessManagement) loadS3ApiConfigurationFromFiler(option *S3A
 	return iam.LoadS3ApiConfigurationFromCredentialManager()
 }
 
+// reloadPeriodically picks up users changed outside of SeaweedFS, such as in a directory server
+func (iam *IdentityAccessManagement) reloadPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := iam.LoadS3ApiConfigurationFromCredentialManager(); err != nil {
+			glog.Warningf("fail to reload config: %v", err)
+		}
+	}
+}
+
 func (iam *IdentityAccessManagement) loadS3ApiConfigurationFromFile(fileName string) error {
 	content, readErr := os.ReadFile(fileName)
 	if readErr != nil {
@@ -228,6 +248,11 @@ func (iam *IdentityAccessManagement) loadS3ApiConfigurationFromFile(fileName str
 		glog.Warningf("KMS initialization failed: %v", err)
 	}
 
+	// Initialize STS if configuration contains an sts section
+	if err := iam.initializeSTSFromJSON(content); err != nil {
+		glog.Warningf("STS initialization failed: %v", err)
+	}
+
 	return iam.LoadS3ApiConfigurationFromBytes(content)
 }
 
@@ -256,6 +281,8 @@ func (iam *IdentityAccessManagement) loadS3ApiConfiguration(config *iam_pb.S3Api
 	emailAccount := make(map[string]*Account)
 	foundAccountAdmin := false
 	foundAccountAnonymous := false
+	policies := newManagedPolicies(config)
+	identityPolicies := policies.compileIdentityPolicies(config)
 
 	for _, account := range config.Accounts {
 		glog.V(3).Infof("loading account  name=%s, id=%s", account.DisplayName, account.Id)
@@ -299,9 +326,10 @@ func (iam *IdentityAccessManagement) loadS3ApiConfiguration(config *iam_pb.S3Api
 	for _, ident := range config.Identities {
 		glog.V(3).Infof("loading identity %s", ident.Name)
 		t := &Identity{
-			Name:        ident.Name,
-			Credentials: nil,
-			Actions:     nil,
+			Name:          ident.Name,
+			Credentials:   nil,
+			Actions:       nil,
+			managedPolicy: identityPolicies[ident.Name],
 		}
 		switch {
 		case ident.Name == AccountAnonymous.Id:
@@ -341,8 +369,13 @@ func (iam *IdentityAccessManagement) loadS3ApiConfiguration(config *iam_pb.S3Api
 	if !iam.isAuthEnabled { // one-directional, no toggling
 		iam.isAuthEnabled = len(identities) > 0
 	}
+	stsService := iam.stsService
 	iam.m.Unlock()
 
+	if stsService != nil {
+		stsService.SetIAMRoles(policies.stsRoles(config))
+	}
+
 	return nil
 }
 
@@ -478,7 +511,7 @@ func (iam *IdentityAccessManagement) authRequest(r *http.Request, action Action)
 	if action == s3_constants.ACTION_LIST && bucket == "" {
 		// ListBuckets operation - authorization handled per-bucket in the handler
 	} else {
-		if !identity.canDo(action, bucket, object) {
+		if !identity.canDoRequest(r, action, bucket, object) {
 			return identity, s3err.ErrAccessDenied
 		}
 	}
@@ -490,6 +523,22 @@ func (iam *IdentityAccessManagement) authRequest(r *http.Request, action Action)
 }
 
 func (identity *Identity) canDo(action Action, bucket string, objectKey string) bool {
+	return identity.canDoRequest(nil, action, bucket, objectKey)
+}
+
+// canDoRequest checks the permission of an action. If the request is known,
+// it narrows down the S3 actions that session and managed policies are evaluated with.
+// Managed policies are evaluated before the actions of the identity, so that
+// their explicit denies win.
+func (identity *Identity) canDoRequest(r *http.Request, action Action, bucket string, objectKey string) bool {
+	if identity.isSession() {
+		return identity.canDoWithSessionPolicies(r, action, bucket, objectKey)
+	}
+	if identity.managedPolicy != nil {
+		if allowed, matched := identity.evaluateManagedPolicy(r, action, bucket, objectKey); matched {
+			return allowed
+		}
+	}
 	if identity.isAdmin() {
 		return true
 	}
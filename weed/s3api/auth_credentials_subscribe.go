@@ -151,6 +151,10 @@ func (s3a *S3ApiServer) updateBucketConfigCacheFromEntry(entry *filer_pb.Entry)
 		config.CORS = corsConfig
 		glog.V(2).Infof("updateBucketConfigCacheFromEntry: loaded CORS config for bucket %s", bucket)
 	}
+	protoMetadata := loadMetadataFromEntry(entry)
+	config.Logging = protoMetadata.Logging
+	config.Website = protoMetadata.Website
+	config.Transformation = protoMetadata.Transformation
 
 	// Update timestamp
 	config.LastModified = time.Now()
@@ -0,0 +1,199 @@
+package s3api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/sts"
+)
+
+// managedPolicies holds the default versions of the managed policies of the IAM API
+type managedPolicies map[string]*policy_engine.PolicyDocument
+
+func newManagedPolicies(config *iam_pb.S3ApiConfiguration) managedPolicies {
+	documents := make(managedPolicies)
+	for _, policy := range config.Policies {
+		document, err := defaultPolicyDocument(policy)
+		if err != nil {
+			glog.Warningf("managed policy %s: %v", policy.Name, err)
+			continue
+		}
+		documents[policy.Name] = document
+	}
+	return documents
+}
+
+func defaultPolicyDocument(policy *iam_pb.Policy) (*policy_engine.PolicyDocument, error) {
+	for _, version := range policy.Versions {
+		if version.VersionId == policy.DefaultVersionId {
+			return policy_engine.ParsePolicy(version.Document)
+		}
+	}
+	return nil, fmt.Errorf("default version %s not found", policy.DefaultVersionId)
+}
+
+// merge returns a policy document with the statements of all named policies
+func (documents managedPolicies) merge(owner string, policyNames []string) policy_engine.PolicyDocument {
+	merged := policy_engine.PolicyDocument{Version: policy_engine.PolicyVersion2012_10_17}
+	seen := make(map[string]bool)
+	for _, name := range policyNames {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		document, found := documents[name]
+		if !found {
+			glog.Warningf("%s is attached to missing managed policy %s", owner, name)
+			continue
+		}
+		merged.Statement = append(merged.Statement, document.Statement...)
+	}
+	return merged
+}
+
+// compileIdentityPolicies compiles the managed policies attached to each
+// identity, directly or through its groups, into one policy per identity
+func (documents managedPolicies) compileIdentityPolicies(config *iam_pb.S3ApiConfiguration) map[string]*policy_engine.CompiledPolicy {
+	groupPolicyNames := make(map[string][]string)
+	for _, group := range config.Groups {
+		for _, member := range group.Members {
+			groupPolicyNames[member] = append(groupPolicyNames[member], group.PolicyNames...)
+		}
+	}
+	compiled := make(map[string]*policy_engine.CompiledPolicy)
+	for _, ident := range config.Identities {
+		policyNames := append(append([]string{}, ident.PolicyNames...), groupPolicyNames[ident.Name]...)
+		if len(policyNames) == 0 {
+			continue
+		}
+		document := documents.merge("user "+ident.Name, policyNames)
+		policy, err := policy_engine.CompilePolicy(&document)
+		if err != nil {
+			glog.Warningf("compile managed policies of user %s: %v", ident.Name, err)
+			continue
+		}
+		compiled[ident.Name] = policy
+	}
+	return compiled
+}
+
+// stsRoles converts the roles of the IAM API into roles that can be assumed
+// with STS. The trust policy selects the identities allowed to call AssumeRole,
+// and the attached managed policies become the role policy.
+func (documents managedPolicies) stsRoles(config *iam_pb.S3ApiConfiguration) []*sts.Role {
+	var roles []*sts.Role
+	for _, role := range config.Roles {
+		trustedIdentities, err := trustedIdentities(role.AssumeRolePolicyDocument)
+		if err != nil {
+			glog.Warningf("trust policy of role %s: %v", role.Name, err)
+		}
+		roles = append(roles, &sts.Role{
+			Name:               role.Name,
+			TrustedIdentities:  trustedIdentities,
+			MaxSessionDuration: role.MaxSessionDuration,
+			Policy:             documents.merge("role "+role.Name, role.PolicyNames),
+		})
+	}
+	return roles
+}
+
+// trustedIdentities returns the identity names allowed by the "AWS" principals
+// of a trust policy to assume a role, "*" if any identity is allowed
+func trustedIdentities(trustPolicy string) ([]string, error) {
+	var document struct {
+		Statement []struct {
+			Effect    string
+			Action    policy_engine.StringOrStringSlice
+			Principal json.RawMessage
+		}
+	}
+	if err := json.Unmarshal([]byte(trustPolicy), &document); err != nil {
+		return nil, err
+	}
+	var identities []string
+	for _, statement := range document.Statement {
+		if statement.Effect != string(policy_engine.PolicyEffectAllow) || !allowsAssumeRole(statement.Action.Strings()) {
+			continue
+		}
+		var principal string
+		if err := json.Unmarshal(statement.Principal, &principal); err == nil {
+			if principal == "*" {
+				identities = append(identities, "*")
+			}
+			continue
+		}
+		var principals map[string]policy_engine.StringOrStringSlice
+		if err := json.Unmarshal(statement.Principal, &principals); err != nil {
+			return nil, fmt.Errorf("invalid principal %s", statement.Principal)
+		}
+		for _, aws := range principals["AWS"].Strings() {
+			identities = append(identities, principalIdentity(aws))
+		}
+	}
+	return identities, nil
+}
+
+func allowsAssumeRole(actions []string) bool {
+	for _, action := range actions {
+		if action == "*" || action == "sts:*" || action == "sts:AssumeRole" {
+			return true
+		}
+	}
+	return false
+}
+
+// principalIdentity maps arn:aws:iam::<account>:user/<path>/<name> to the
+// identity name, and the account root to all identities
+func principalIdentity(principal string) string {
+	if principal == "*" || strings.HasSuffix(principal, ":root") {
+		return "*"
+	}
+	if _, user, found := strings.Cut(principal, ":user/"); found {
+		return user[strings.LastIndex(user, "/")+1:]
+	}
+	return principal
+}
+
+// evaluateManagedPolicy evaluates the managed policies of the identity. It
+// returns whether the action is allowed, and whether any statement matched.
+// An explicit deny wins over an allow of the same S3 action. If the exact S3
+// action can not be told, nothing is allowed, but a deny of any of the S3
+// actions the gateway action stands for still applies.
+func (identity *Identity) evaluateManagedPolicy(r *http.Request, action Action, bucket string, objectKey string) (allowed bool, matched bool) {
+	resource := policyResource(action, bucket, objectKey)
+	s3Actions := sessionActions(r, action, objectKey)
+	exact := len(s3Actions) > 0
+	if !exact {
+		s3Actions = policy_engine.GetActionMappings()[string(action)]
+	}
+	for _, s3Action := range s3Actions {
+		args := &policy_engine.PolicyEvaluationArgs{
+			Action:    s3Action,
+			Resource:  resource,
+			Principal: identity.Name,
+		}
+		var isAllowed, isDenied bool
+		for _, statement := range identity.managedPolicy.Statements {
+			if !statement.EvaluateStatement(args) {
+				continue
+			}
+			if statement.Statement.Effect == policy_engine.PolicyEffectDeny {
+				isDenied = true
+			} else {
+				isAllowed = true
+			}
+		}
+		if isDenied {
+			return false, true
+		}
+		if isAllowed && exact {
+			return true, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,148 @@
+package s3api
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+)
+
+// routeAction is a route of the S3 api, and the S3 action that policies are
+// evaluated with for it. Routes are matched like the router does: in the order
+// they are registered, by the method and the presence of the query keys.
+type routeAction struct {
+	method   string
+	object   bool     // only matches requests to an object
+	keys     []string // the query keys selecting the route, none for the default route of the method
+	action   Action   // the gateway action the route is authorized with
+	s3Action string
+}
+
+// routeActions mirrors the registration order of the routes in registerRouter.
+// The header-matched routes for copies and post policies resolve to the same
+// S3 actions as the routes they precede, so they are not listed.
+var routeActions = []routeAction{
+	// objects with query
+	{http.MethodPost, true, []string{"select", "select-type"}, s3_constants.ACTION_READ, "s3:GetObject"},
+	{http.MethodPut, true, []string{"partNumber", "uploadId"}, s3_constants.ACTION_WRITE, "s3:PutObject"},
+	{http.MethodPost, true, []string{"uploadId"}, s3_constants.ACTION_WRITE, "s3:PutObject"},
+	{http.MethodPost, true, []string{"uploads"}, s3_constants.ACTION_WRITE, "s3:PutObject"},
+	{http.MethodDelete, true, []string{"uploadId"}, s3_constants.ACTION_WRITE, "s3:AbortMultipartUpload"},
+	{http.MethodGet, true, []string{"uploadId"}, s3_constants.ACTION_READ, "s3:ListMultipartUploadParts"},
+	{http.MethodGet, true, []string{"tagging"}, s3_constants.ACTION_READ, "s3:GetObjectTagging"},
+	{http.MethodPut, true, []string{"tagging"}, s3_constants.ACTION_TAGGING, "s3:PutObjectTagging"},
+	{http.MethodDelete, true, []string{"tagging"}, s3_constants.ACTION_TAGGING, "s3:DeleteObjectTagging"},
+	{http.MethodPut, true, []string{"acl"}, s3_constants.ACTION_WRITE_ACP, "s3:PutObjectAcl"},
+	{http.MethodPut, true, []string{"retention"}, s3_constants.ACTION_WRITE, "s3:PutObjectRetention"},
+	{http.MethodPut, true, []string{"legal-hold"}, s3_constants.ACTION_WRITE, "s3:PutObjectLegalHold"},
+	{http.MethodGet, true, []string{"acl"}, s3_constants.ACTION_READ_ACP, "s3:GetObjectAcl"},
+	{http.MethodGet, true, []string{"retention"}, s3_constants.ACTION_READ, "s3:GetObjectRetention"},
+	{http.MethodGet, true, []string{"legal-hold"}, s3_constants.ACTION_READ, "s3:GetObjectLegalHold"},
+	{http.MethodGet, true, []string{"attributes"}, s3_constants.ACTION_READ, "s3:GetObjectAttributes"},
+
+	// raw objects
+	{http.MethodHead, true, nil, s3_constants.ACTION_READ, "s3:GetObject"},
+	{http.MethodGet, true, nil, s3_constants.ACTION_READ, "s3:GetObject"},
+	{http.MethodPut, true, nil, s3_constants.ACTION_WRITE, "s3:PutObject"},
+	{http.MethodDelete, true, nil, s3_constants.ACTION_WRITE, "s3:DeleteObject"},
+
+	// buckets with query
+	{http.MethodGet, false, []string{"uploads"}, s3_constants.ACTION_READ, "s3:ListBucketMultipartUploads"},
+	{http.MethodGet, false, []string{"acl"}, s3_constants.ACTION_READ_ACP, "s3:GetBucketAcl"},
+	{http.MethodPut, false, []string{"acl"}, s3_constants.ACTION_WRITE_ACP, "s3:PutBucketAcl"},
+	{http.MethodGet, false, []string{"policy"}, s3_constants.ACTION_READ, "s3:GetBucketPolicy"},
+	{http.MethodPut, false, []string{"policy"}, s3_constants.ACTION_WRITE, "s3:PutBucketPolicy"},
+	{http.MethodDelete, false, []string{"policy"}, s3_constants.ACTION_WRITE, "s3:DeleteBucketPolicy"},
+	{http.MethodGet, false, []string{"cors"}, s3_constants.ACTION_READ, "s3:GetBucketCORS"},
+	{http.MethodPut, false, []string{"cors"}, s3_constants.ACTION_WRITE, "s3:PutBucketCORS"},
+	{http.MethodDelete, false, []string{"cors"}, s3_constants.ACTION_WRITE, "s3:PutBucketCORS"},
+	{http.MethodGet, false, []string{"lifecycle"}, s3_constants.ACTION_READ, "s3:GetLifecycleConfiguration"},
+	{http.MethodPut, false, []string{"lifecycle"}, s3_constants.ACTION_WRITE, "s3:PutLifecycleConfiguration"},
+	{http.MethodDelete, false, []string{"lifecycle"}, s3_constants.ACTION_WRITE, "s3:PutLifecycleConfiguration"},
+	{http.MethodGet, false, []string{"replication"}, s3_constants.ACTION_READ, "s3:GetReplicationConfiguration"},
+	{http.MethodPut, false, []string{"replication"}, s3_constants.ACTION_WRITE, "s3:PutReplicationConfiguration"},
+	{http.MethodDelete, false, []string{"replication"}, s3_constants.ACTION_WRITE, "s3:PutReplicationConfiguration"},
+	{http.MethodGet, false, []string{"notification"}, s3_constants.ACTION_READ, "s3:GetBucketNotification"},
+	{http.MethodPut, false, []string{"notification"}, s3_constants.ACTION_WRITE, "s3:PutBucketNotification"},
+	{http.MethodGet, false, []string{"inventory"}, s3_constants.ACTION_READ, "s3:GetInventoryConfiguration"},
+	{http.MethodPut, false, []string{"inventory"}, s3_constants.ACTION_WRITE, "s3:PutInventoryConfiguration"},
+	{http.MethodDelete, false, []string{"inventory"}, s3_constants.ACTION_WRITE, "s3:PutInventoryConfiguration"},
+	{http.MethodGet, false, []string{"logging"}, s3_constants.ACTION_READ, "s3:GetBucketLogging"},
+	{http.MethodPut, false, []string{"logging"}, s3_constants.ACTION_WRITE, "s3:PutBucketLogging"},
+	{http.MethodGet, false, []string{"website"}, s3_constants.ACTION_READ, "s3:GetBucketWebsite"},
+	{http.MethodPut, false, []string{"website"}, s3_constants.ACTION_WRITE, "s3:PutBucketWebsite"},
+	{http.MethodDelete, false, []string{"website"}, s3_constants.ACTION_WRITE, "s3:DeleteBucketWebsite"},
+	{http.MethodGet, false, []string{"transformation"}, s3_constants.ACTION_READ, "s3:GetBucketTransformation"},
+	{http.MethodPut, false, []string{"transformation"}, s3_constants.ACTION_WRITE, "s3:PutBucketTransformation"},
+	{http.MethodDelete, false, []string{"transformation"}, s3_constants.ACTION_WRITE, "s3:PutBucketTransformation"},
+	{http.MethodGet, false, []string{"location"}, s3_constants.ACTION_READ, "s3:GetBucketLocation"},
+	{http.MethodGet, false, []string{"requestPayment"}, s3_constants.ACTION_READ, "s3:GetBucketRequestPayment"},
+	{http.MethodGet, false, []string{"versioning"}, s3_constants.ACTION_READ, "s3:GetBucketVersioning"},
+	{http.MethodPut, false, []string{"versioning"}, s3_constants.ACTION_WRITE, "s3:PutBucketVersioning"},
+	{http.MethodGet, false, []string{"object-lock"}, s3_constants.ACTION_READ, "s3:GetBucketObjectLockConfiguration"},
+	{http.MethodPut, false, []string{"object-lock"}, s3_constants.ACTION_WRITE, "s3:PutBucketObjectLockConfiguration"},
+	{http.MethodGet, false, []string{"tagging"}, s3_constants.ACTION_TAGGING, "s3:GetBucketTagging"},
+	{http.MethodPut, false, []string{"tagging"}, s3_constants.ACTION_TAGGING, "s3:PutBucketTagging"},
+	{http.MethodDelete, false, []string{"tagging"}, s3_constants.ACTION_TAGGING, "s3:PutBucketTagging"},
+	{http.MethodGet, false, []string{"encryption"}, s3_constants.ACTION_ADMIN, "s3:GetEncryptionConfiguration"},
+	{http.MethodPut, false, []string{"encryption"}, s3_constants.ACTION_ADMIN, "s3:PutEncryptionConfiguration"},
+	{http.MethodDelete, false, []string{"encryption"}, s3_constants.ACTION_ADMIN, "s3:PutEncryptionConfiguration"},
+	{http.MethodGet, false, []string{"publicAccessBlock"}, s3_constants.ACTION_ADMIN, "s3:GetBucketPublicAccessBlock"},
+	{http.MethodPut, false, []string{"publicAccessBlock"}, s3_constants.ACTION_ADMIN, "s3:PutBucketPublicAccessBlock"},
+	{http.MethodDelete, false, []string{"publicAccessBlock"}, s3_constants.ACTION_ADMIN, "s3:PutBucketPublicAccessBlock"},
+	{http.MethodPost, false, []string{"delete"}, s3_constants.ACTION_WRITE, "s3:DeleteObject"},
+	{http.MethodGet, false, []string{"list-type"}, s3_constants.ACTION_LIST, "s3:ListBucket"},
+	{http.MethodGet, false, []string{"versions"}, s3_constants.ACTION_LIST, "s3:ListBucketVersions"},
+	{http.MethodPut, false, []string{"ownershipControls"}, s3_constants.ACTION_ADMIN, "s3:PutBucketOwnershipControls"},
+	{http.MethodGet, false, []string{"ownershipControls"}, s3_constants.ACTION_READ, "s3:GetBucketOwnershipControls"},
+	{http.MethodDelete, false, []string{"ownershipControls"}, s3_constants.ACTION_ADMIN, "s3:PutBucketOwnershipControls"},
+
+	// raw buckets
+	{http.MethodPost, false, nil, s3_constants.ACTION_WRITE, "s3:PutObject"},
+	{http.MethodHead, false, nil, s3_constants.ACTION_READ, "s3:ListBucket"},
+	{http.MethodPut, false, nil, s3_constants.ACTION_ADMIN, "s3:CreateBucket"},
+	{http.MethodDelete, false, nil, s3_constants.ACTION_DELETE_BUCKET, "s3:DeleteBucket"},
+	{http.MethodGet, false, nil, s3_constants.ACTION_LIST, "s3:ListBucket"},
+}
+
+// versionedS3Actions are the S3 actions of requests to a specific object version
+var versionedS3Actions = map[string]string{
+	"s3:GetObject":           "s3:GetObjectVersion",
+	"s3:DeleteObject":        "s3:DeleteObjectVersion",
+	"s3:GetObjectTagging":    "s3:GetObjectVersionTagging",
+	"s3:PutObjectTagging":    "s3:PutObjectVersionTagging",
+	"s3:DeleteObjectTagging": "s3:DeleteObjectVersionTagging",
+	"s3:GetObjectAcl":        "s3:GetObjectVersionAcl",
+	"s3:PutObjectAcl":        "s3:PutObjectVersionAcl",
+	"s3:GetObjectAttributes": "s3:GetObjectVersionAttributes",
+}
+
+// routeS3Action returns the S3 action of the route the request is routed to.
+// It is not found if the route is authorized with another gateway action,
+// e.g. for the extra checks some handlers do.
+func routeS3Action(r *http.Request, action Action, objectKey string) (string, bool) {
+	query := r.URL.Query()
+	isObject := objectKey != "" && objectKey != "/"
+	for _, route := range routeActions {
+		if route.method != r.Method || (route.object && !isObject) || !hasQueryKeys(query, route.keys) {
+			continue
+		}
+		if route.action != action {
+			return "", false
+		}
+		if versioned, found := versionedS3Actions[route.s3Action]; found && isObject && query.Has("versionId") {
+			return versioned, true
+		}
+		return route.s3Action, true
+	}
+	return "", false
+}
+
+func hasQueryKeys(query url.Values, keys []string) bool {
+	for _, key := range keys {
+		if !query.Has(key) {
+			return false
+		}
+	}
+	return true
+}
@@ -116,8 +116,9 @@ func (iam *IdentityAccessManagement) doesSignV2Match(r *http.Request) (*Identity
 		return nil, s3err.ErrInvalidAccessKeyID
 	}
 
+	// identities with policies are checked against them in authRequest
 	bucket, object := s3_constants.GetBucketAndObject(r)
-	if !identity.canDo(s3_constants.ACTION_WRITE, bucket, object) {
+	if !identity.hasPolicies() && !identity.canDo(s3_constants.ACTION_WRITE, bucket, object) {
 		return nil, s3err.ErrAccessDenied
 	}
 
@@ -163,8 +164,9 @@ func (iam *IdentityAccessManagement) doesPresignV2SignatureMatch(r *http.Request
 		return nil, s3err.ErrInvalidAccessKeyID
 	}
 
+	// identities with policies are checked against them in authRequest
 	bucket, object := s3_constants.GetBucketAndObject(r)
-	if !identity.canDo(s3_constants.ACTION_READ, bucket, object) {
+	if !identity.hasPolicies() && !identity.canDo(s3_constants.ACTION_READ, bucket, object) {
 		return nil, s3err.ErrAccessDenied
 	}
 
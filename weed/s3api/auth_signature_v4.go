@@ -50,10 +50,11 @@ func (iam *IdentityAccessManagement) reqSignatureV4Verify(r *http.Request) (*Ide
 
 // Constants specific to this file
 const (
-	emptySHA256              = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
-	streamingContentSHA256   = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
-	streamingUnsignedPayload = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
-	unsignedPayload          = "UNSIGNED-PAYLOAD"
+	emptySHA256                   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	streamingContentSHA256        = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingContentSHA256Trailer = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	streamingUnsignedPayload      = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+	unsignedPayload               = "UNSIGNED-PAYLOAD"
 	// Limit for IAM/STS request body size to prevent DoS attacks
 	iamRequestBodyLimit = 10 * (1 << 20) // 10 MiB
 )
@@ -185,15 +186,19 @@ func (iam *IdentityAccessManagement) doesSignatureMatch(hashedPayload string, r
 	}
 
 	cred := signV4Values.Credential
-	identity, foundCred, found := iam.lookupByAccessKey(cred.accessKey)
-	if !found {
-		return nil, s3err.ErrInvalidAccessKeyID
+	identity, foundCred, errCode := iam.lookupCredential(r, cred.accessKey)
+	if errCode != s3err.ErrNone {
+		return nil, errCode
 	}
 
-	bucket, object := s3_constants.GetBucketAndObject(r)
-	canDoResult := identity.canDo(s3_constants.ACTION_WRITE, bucket, object)
-	if !canDoResult {
-		return nil, s3err.ErrAccessDenied
+	// Identities with policies are checked against them in authRequest,
+	// and requests to other services such as STS are not bucket operations.
+	if cred.scope.service == "s3" && !identity.hasPolicies() {
+		bucket, object := s3_constants.GetBucketAndObject(r)
+		canDoResult := identity.canDo(s3_constants.ACTION_WRITE, bucket, object)
+		if !canDoResult {
+			return nil, s3err.ErrAccessDenied
+		}
 	}
 
 	// Extract date, if not present throw error.
@@ -312,15 +317,15 @@ func (iam *IdentityAccessManagement) doesPresignedSignatureMatch(hashedPayload s
 		return nil, err
 	}
 
-	// Look up identity by access key
-	identity, foundCred, found := iam.lookupByAccessKey(credHeader.accessKey)
-	if !found {
-		return nil, s3err.ErrInvalidAccessKeyID
+	// Look up identity by access key, or by the session token of temporary credentials
+	identity, foundCred, errCode := iam.lookupCredential(r, credHeader.accessKey)
+	if errCode != s3err.ErrNone {
+		return nil, errCode
 	}
 
-	// Check permissions
+	// Check permissions, identities with policies are checked against them in authRequest
 	bucket, object := s3_constants.GetBucketAndObject(r)
-	if !identity.canDo(s3_constants.ACTION_READ, bucket, object) {
+	if !identity.hasPolicies() && !identity.canDo(s3_constants.ACTION_READ, bucket, object) {
 		return nil, s3err.ErrAccessDenied
 	}
 
@@ -364,7 +369,6 @@ func (iam *IdentityAccessManagement) doesPresignedSignatureMatch(hashedPayload s
 	queryForCanonical.Del("X-Amz-Signature")
 	queryStr := strings.Replace(queryForCanonical.Encode(), "+", "%20", -1)
 
-	var errCode s3err.ErrorCode
 	// Check if reverse proxy is forwarding with prefix for presigned URLs
 	if forwardedPrefix := r.Header.Get("X-Forwarded-Prefix"); forwardedPrefix != "" {
 		// Try signature verification with the forwarded prefix first.
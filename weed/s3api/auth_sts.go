@@ -0,0 +1,189 @@
+package s3api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/sts"
+)
+
+// S3 actions of the gateway actions that checks outside of their routes are evaluated with
+var sessionActionMappings = map[string][]string{
+	s3_constants.ACTION_READ_ACP:      {"s3:GetObjectAcl", "s3:GetObjectVersionAcl", "s3:GetBucketAcl"},
+	s3_constants.ACTION_WRITE_ACP:     {"s3:PutObjectAcl", "s3:PutObjectVersionAcl", "s3:PutBucketAcl"},
+	s3_constants.ACTION_DELETE_BUCKET: {"s3:DeleteBucket"},
+}
+
+// initializeSTSFromJSON loads the optional "sts" block of the identity configuration
+func (iam *IdentityAccessManagement) initializeSTSFromJSON(configContent []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(configContent))), &m); err != nil {
+		return err
+	}
+	stsVal, ok := m["sts"]
+	if !ok {
+		iam.m.Lock()
+		iam.stsService = nil
+		iam.m.Unlock()
+		return nil
+	}
+	config := &sts.Config{}
+	if err := json.Unmarshal(stsVal, config); err != nil {
+		return fmt.Errorf("parse sts configuration: %w", err)
+	}
+	service, err := sts.NewService(config)
+	if err != nil {
+		return fmt.Errorf("sts configuration: %w", err)
+	}
+	iam.m.Lock()
+	iam.stsService = service
+	// temporary credentials are useless without authentication, even if
+	// no static identities are configured
+	iam.isAuthEnabled = true
+	iam.m.Unlock()
+	glog.V(0).Infof("STS enabled with %d roles", len(config.Roles))
+	return nil
+}
+
+func (iam *IdentityAccessManagement) getSTSService() *sts.Service {
+	iam.m.RLock()
+	defer iam.m.RUnlock()
+	return iam.stsService
+}
+
+// getSecurityToken returns the session token of temporary credentials,
+// sent as a header or as a query parameter of presigned URLs
+func getSecurityToken(r *http.Request) string {
+	if token := r.Header.Get(s3_constants.AmzSecurityToken); token != "" {
+		return token
+	}
+	return r.URL.Query().Get(s3_constants.AmzSecurityToken)
+}
+
+// lookupCredential finds the identity of a signed request, either by a
+// long-lived access key or by the session token of temporary credentials.
+func (iam *IdentityAccessManagement) lookupCredential(r *http.Request, accessKey string) (*Identity, *Credential, s3err.ErrorCode) {
+	token := getSecurityToken(r)
+	if token == "" {
+		identity, cred, found := iam.lookupByAccessKey(accessKey)
+		if !found {
+			return nil, nil, s3err.ErrInvalidAccessKeyID
+		}
+		return identity, cred, s3err.ErrNone
+	}
+
+	service := iam.getSTSService()
+	if service == nil {
+		return nil, nil, s3err.ErrInvalidToken
+	}
+	session, err := service.ParseSessionToken(token)
+	if err != nil {
+		glog.V(2).Infof("invalid session token for %s: %v", accessKey, err)
+		if errors.Is(err, sts.ErrExpiredToken) {
+			return nil, nil, s3err.ErrExpiredToken
+		}
+		return nil, nil, s3err.ErrInvalidToken
+	}
+	if session.AccessKeyId != accessKey {
+		return nil, nil, s3err.ErrInvalidAccessKeyID
+	}
+	identity, err := newSessionIdentity(session)
+	if err != nil {
+		glog.Warningf("session %s: %v", session.AssumedRoleUser(), err)
+		return nil, nil, s3err.ErrInvalidToken
+	}
+	return identity, identity.Credentials[0], s3err.ErrNone
+}
+
+func newSessionIdentity(session *sts.Session) (*Identity, error) {
+	identity := &Identity{
+		Name:    session.AssumedRoleUser(),
+		Account: &AccountAdmin,
+		Credentials: []*Credential{{
+			AccessKey: session.AccessKeyId,
+			SecretKey: session.SecretAccessKey,
+		}},
+	}
+	for _, policy := range []*policy_engine.PolicyDocument{session.Policy, session.SessionPolicy} {
+		if policy == nil {
+			continue
+		}
+		compiled, err := policy_engine.CompilePolicy(policy)
+		if err != nil {
+			return nil, err
+		}
+		identity.sessionPolicies = append(identity.sessionPolicies, compiled)
+	}
+	return identity, nil
+}
+
+func (identity *Identity) isSession() bool {
+	return len(identity.sessionPolicies) > 0
+}
+
+// hasPolicies tells whether the identity is checked against session or
+// managed policies, which need the request to tell the exact S3 action
+func (identity *Identity) hasPolicies() bool {
+	return identity.isSession() || identity.managedPolicy != nil
+}
+
+// sessionActions returns the S3 actions that policies are evaluated with for a
+// gateway action. The gateway actions are coarser than the S3 actions, so the
+// exact S3 action is taken from the route of the request. When it can not be
+// determined, no action is returned and the request is denied, except for the
+// ACL and bucket deletion actions that only stand for a few S3 actions.
+func sessionActions(r *http.Request, action Action, objectKey string) []string {
+	if r != nil {
+		if s3Action, found := routeS3Action(r, action, objectKey); found {
+			return []string{s3Action}
+		}
+	} else if action == s3_constants.ACTION_LIST {
+		// ListBuckets checks every bucket without a request of its own
+		return []string{"s3:ListBucket"}
+	}
+	return sessionActionMappings[string(action)]
+}
+
+// policyResource returns the resource ARN that policies are evaluated with
+func policyResource(action Action, bucket string, objectKey string) string {
+	if bucket == "" {
+		return "arn:aws:s3:::*"
+	}
+	if action == s3_constants.ACTION_LIST {
+		return policy_engine.BuildResourceArn(bucket, "")
+	}
+	return policy_engine.BuildResourceArn(bucket, strings.TrimPrefix(objectKey, "/"))
+}
+
+// canDoWithSessionPolicies evaluates the role policy, and the session policy
+// if one was passed when assuming the role. The action is allowed when any
+// of the S3 actions it stands for is allowed by all policies.
+func (identity *Identity) canDoWithSessionPolicies(r *http.Request, action Action, bucket string, objectKey string) bool {
+	resource := policyResource(action, bucket, objectKey)
+	for _, s3Action := range sessionActions(r, action, objectKey) {
+		args := &policy_engine.PolicyEvaluationArgs{
+			Action:    s3Action,
+			Resource:  resource,
+			Principal: identity.Name,
+		}
+		allowed := true
+		for _, policy := range identity.sessionPolicies {
+			if ok, _ := policy.EvaluatePolicy(args); !ok {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return true
+		}
+	}
+	glog.V(3).Infof("session %s is not allowed to perform action %s on %s", identity.Name, action, resource)
+	return false
+}
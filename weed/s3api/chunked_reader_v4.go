@@ -63,8 +63,9 @@ func (iam *IdentityAccessManagement) calculateSeedSignature(r *http.Request) (cr
 	contentSha256Header := req.Header.Get("X-Amz-Content-Sha256")
 
 	switch contentSha256Header {
-	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD'
-	case streamingContentSHA256:
+	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD',
+	// or 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER' with a signed trailer
+	case streamingContentSHA256, streamingContentSHA256Trailer:
 		glog.V(3).Infof("streaming content sha256")
 	case streamingUnsignedPayload:
 		glog.V(3).Infof("streaming unsigned payload")
@@ -81,13 +82,13 @@ func (iam *IdentityAccessManagement) calculateSeedSignature(r *http.Request) (cr
 		return nil, "", "", "", time.Time{}, errCode
 	}
 	// Verify if the access key id matches.
-	identity, cred, found := iam.lookupByAccessKey(signV4Values.Credential.accessKey)
-	if !found {
-		return nil, "", "", "", time.Time{}, s3err.ErrInvalidAccessKeyID
+	identity, cred, errCode := iam.lookupCredential(r, signV4Values.Credential.accessKey)
+	if errCode != s3err.ErrNone {
+		return nil, "", "", "", time.Time{}, errCode
 	}
 
 	bucket, object := s3_constants.GetBucketAndObject(r)
-	if !identity.canDo(s3_constants.ACTION_WRITE, bucket, object) {
+	if !identity.canDoRequest(r, s3_constants.ACTION_WRITE, bucket, object) {
 		errCode = s3err.ErrAccessDenied
 		return
 	}
@@ -155,8 +156,9 @@ func (iam *IdentityAccessManagement) newChunkedReader(req *http.Request) (io.Rea
 	var errCode s3err.ErrorCode
 
 	switch contentSha256Header {
-	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD'
-	case streamingContentSHA256:
+	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD',
+	// or 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER' with a signed trailer
+	case streamingContentSHA256, streamingContentSHA256Trailer:
 		glog.V(3).Infof("streaming content sha256")
 		ident, seedSignature, region, service, seedDate, errCode = iam.calculateSeedSignature(req)
 		if errCode != s3err.ErrNone {
@@ -366,11 +368,13 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 			// When using signed upload with an additional checksum algorithm, this would be the raw contents of the trailer chunk:
 			//
 			// x-amz-checksum-crc32:YABb/g==\n\r\n            // Trailer chunk (note optional \n character)
-			// trailer-signature\r\n
+			// x-amz-trailer-signature:<signature>\r\n
 			// \r\n                                           // CRLF
-			//
-			// This implementation currently only supports the first case.
-			// TODO: Implement the second case (signed upload with additional checksum computation for each chunk)
+			if cr.checkSumWriter == nil {
+				// No trailer was announced in the x-amz-trailer header.
+				cr.state = eofChunk
+				continue
+			}
 
 			extractedCheckSumAlgorithm, extractedChecksum := parseChunkChecksum(cr.reader)
 
@@ -389,8 +393,15 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 				return 0, cr.err
 			}
 
-			// TODO: Extract signature from trailer chunk and verify it.
-			// For now, we just read the trailer chunk and discard it.
+			// The trailer signature is chained to the signature of the last chunk.
+			trailerSignature := parseTrailerSignature(cr.reader)
+			if cr.cred != nil {
+				trailer := cr.checkSumAlgorithm + ":" + string(extractedChecksum) + "\n"
+				if trailerSignature == "" || !compareSignatureV4(trailerSignature, cr.getTrailerSignature(trailer)) {
+					cr.err = errors.New(s3err.ErrMsgChunkSignatureMismatch)
+					return 0, cr.err
+				}
+			}
 
 			// Reading remaining CRLF.
 			for i := 0; i < 2; i++ {
@@ -470,7 +481,9 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 
 			// Common cleanup and state transition for both signed and unsigned streaming
 			cr.chunkSHA256Writer.Reset()
-			if cr.lastChunk {
+			if cr.lastChunk && cr.checkSumWriter != nil {
+				cr.state = readTrailerChunk
+			} else if cr.lastChunk {
 				cr.state = eofChunk
 			} else {
 				cr.state = readChunkHeader
@@ -498,6 +511,23 @@ func (cr *s3ChunkedReader) getChunkSignature(hashedChunk string) string {
 	return getSignature(signingKey, stringToSign)
 }
 
+// getTrailerSignature - get the signature of the trailing headers.
+func (cr *s3ChunkedReader) getTrailerSignature(trailer string) string {
+	hashedTrailer := sha256.Sum256([]byte(trailer))
+	// Calculate string to sign.
+	stringToSign := signV4Algorithm + "-TRAILER" + "\n" +
+		cr.seedDate.Format(iso8601Format) + "\n" +
+		getScope(cr.seedDate, cr.region, cr.service) + "\n" +
+		cr.seedSignature + "\n" +
+		hex.EncodeToString(hashedTrailer[:])
+
+	// Get hmac signing key.
+	signingKey := getSigningKey(cr.cred.SecretKey, cr.seedDate.Format(yyyymmdd), cr.region, cr.service)
+
+	// Calculate and return signature.
+	return getSignature(signingKey, stringToSign)
+}
+
 func readCRLF(reader *bufio.Reader) error {
 	buf := make([]byte, 2)
 	_, err := io.ReadFull(reader, buf)
@@ -596,6 +626,9 @@ func parseChunkChecksum(b *bufio.Reader) (ChecksumAlgorithm, []byte) {
 
 	// Split on ':'
 	parts := bytes.SplitN(bytesRead, []byte(":"), 2)
+	if len(parts) != 2 {
+		return ChecksumAlgorithmNone, nil
+	}
 	checksumKey := string(parts[0])
 	checksumValue := parts[1]
 
@@ -612,6 +645,23 @@ func parseChunkChecksum(b *bufio.Reader) (ChecksumAlgorithm, []byte) {
 	return extractedAlgorithm, checksumValue
 }
 
+// Constant s3 trailer signature header.
+const s3TrailerSignatureStr = "x-amz-trailer-signature:"
+
+// parseTrailerSignature reads the x-amz-trailer-signature line following the trailing
+// headers of a signed upload, if there is one.
+func parseTrailerSignature(b *bufio.Reader) string {
+	prefix, err := b.Peek(len(s3TrailerSignatureStr))
+	if err != nil || string(prefix) != s3TrailerSignatureStr {
+		return ""
+	}
+	bytesRead, err := readChunkLine(b)
+	if err != nil {
+		return ""
+	}
+	return string(bytesRead[len(s3TrailerSignatureStr):])
+}
+
 func parseChunkSignature(chunk []byte) []byte {
 	chunkSplits := bytes.SplitN(chunk, []byte("="), 2)
 	return chunkSplits[1] // Keep only the signature.
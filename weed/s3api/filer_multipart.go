@@ -22,6 +22,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/google/uuid"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3parts"
 
 	"net/http"
 
@@ -113,6 +114,14 @@ type CompleteMultipartUploadResult struct {
 	Bucket   *string  `xml:"Bucket,omitempty"`
 	Key      *string  `xml:"Key,omitempty"`
 	ETag     *string  `xml:"ETag,omitempty"`
+
+	ChecksumCRC32     *string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    *string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME *string `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      *string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    *string `xml:"ChecksumSHA256,omitempty"`
+	ChecksumType      *string `xml:"ChecksumType,omitempty"`
+
 	// VersionId is NOT included in XML body - it should only be in x-amz-version-id HTTP header
 
 	// Store the VersionId internally for setting HTTP header, but don't marshal to XML
@@ -228,6 +237,8 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 	mime := pentry.Attributes.Mime
 	var finalParts []*filer_pb.FileChunk
 	var offset int64
+	completedEntries := make(map[int]*filer_pb.Entry, len(completedPartNumbers))
+	completedParts := make([]s3parts.Part, 0, len(completedPartNumbers))
 	for _, partNumber := range completedPartNumbers {
 		partEntriesByNumber, ok := partEntries[partNumber]
 		if !ok {
@@ -250,6 +261,7 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 
 			// Track within-part offset for SSE-KMS IV calculation
 			var withinPartOffset int64 = 0
+			partOffset := offset
 
 			for _, chunk := range entry.GetChunks() {
 				// Update SSE metadata with correct within-part offset (unified approach for KMS and SSE-C)
@@ -301,10 +313,46 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 				offset += int64(chunk.Size)
 				withinPartOffset += int64(chunk.Size)
 			}
+			completedEntries[partNumber] = entry
+			completedParts = append(completedParts, s3parts.Part{
+				PartNumber: partNumber,
+				Offset:     partOffset,
+				Size:       offset - partOffset,
+				ETag:       hex.EncodeToString(entry.Attributes.GetMd5()),
+			})
 			found = true
 		}
 	}
 
+	// The additional checksum of the object is computed from the part checksums
+	checksumAlgorithm, _ := checksumAlgorithmFromName(string(pentry.Extended[s3_constants.ExtChecksumAlgorithmKey]))
+	checksumType := string(pentry.Extended[s3_constants.ExtChecksumTypeKey])
+	var checksumValue string
+	if checksumAlgorithm != ChecksumAlgorithmNone {
+		var checksumErrCode s3err.ErrorCode
+		checksumValue, checksumErrCode = completedPartsChecksum(checksumAlgorithm, checksumType, completedPartNumbers, completedEntries, parts.Parts)
+		if checksumErrCode != s3err.ErrNone {
+			return nil, checksumErrCode
+		}
+	}
+	if checksumAlgorithm != ChecksumAlgorithmNone {
+		for i := range completedParts {
+			completedParts[i].Checksum = string(completedEntries[completedParts[i].PartNumber].Extended[checksumAlgorithm.Header()])
+		}
+	}
+	encodedParts, err := s3parts.Encode(completedParts)
+	if err != nil {
+		glog.Errorf("completeMultipartUpload: %v", err)
+		return nil, s3err.ErrInternalError
+	}
+	setObjectPartsAndChecksum := func(entry *filer_pb.Entry) {
+		if checksumValue != "" {
+			entry.Extended[checksumAlgorithm.Header()] = []byte(checksumValue)
+			entry.Extended[s3_constants.AmzChecksumType] = []byte(checksumType)
+		}
+		entry.Extended[s3_constants.SeaweedFSMultipartParts] = encodedParts
+	}
+
 	entryName, dirName := s3a.getEntryNameAndDir(input)
 
 	// Check if versioning is configured for this bucket BEFORE creating any files
@@ -353,6 +401,7 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 				versionEntry.Attributes.Mime = mime
 			}
 			versionEntry.Attributes.FileSize = uint64(offset)
+			setObjectPartsAndChecksum(versionEntry)
 		})
 
 		if err != nil {
@@ -415,6 +464,7 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 				entry.Attributes.Mime = mime
 			}
 			entry.Attributes.FileSize = uint64(offset)
+			setObjectPartsAndChecksum(entry)
 		})
 
 		if err != nil {
@@ -468,6 +518,7 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 				entry.Attributes.Mime = mime
 			}
 			entry.Attributes.FileSize = uint64(offset)
+			setObjectPartsAndChecksum(entry)
 		})
 
 		if err != nil {
@@ -484,6 +535,10 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 		}
 	}
 
+	if checksumValue != "" {
+		output.setChecksum(checksumAlgorithm, checksumValue, checksumType)
+	}
+
 	for _, deleteEntry := range deleteEntries {
 		//delete unused part data
 		glog.Infof("completeMultipartUpload cleanup %s upload %s unused %s", *input.Bucket, *input.UploadId, deleteEntry.Name)
@@ -498,6 +553,53 @@ func (s3a *S3ApiServer) completeMultipartUpload(r *http.Request, input *s3.Compl
 	return
 }
 
+func (output *CompleteMultipartUploadResult) setChecksum(algorithm ChecksumAlgorithm, value, checksumType string) {
+	switch algorithm {
+	case ChecksumAlgorithmCRC32:
+		output.ChecksumCRC32 = aws.String(value)
+	case ChecksumAlgorithmCRC32C:
+		output.ChecksumCRC32C = aws.String(value)
+	case ChecksumAlgorithmCRC64NVMe:
+		output.ChecksumCRC64NVME = aws.String(value)
+	case ChecksumAlgorithmSHA1:
+		output.ChecksumSHA1 = aws.String(value)
+	case ChecksumAlgorithmSHA256:
+		output.ChecksumSHA256 = aws.String(value)
+	}
+	output.ChecksumType = aws.String(checksumType)
+}
+
+// completedPartsChecksum computes the additional checksum of a completed multipart upload
+// from the checksums stored with its parts, which must match the ones listed by the client
+func completedPartsChecksum(algorithm ChecksumAlgorithm, checksumType string, partNumbers []int, partEntries map[int]*filer_pb.Entry, completedParts []CompletedPart) (string, s3err.ErrorCode) {
+	listedChecksums := make(map[int]string)
+	for _, part := range completedParts {
+		if value := part.checksum(algorithm); value != "" {
+			listedChecksums[part.PartNumber] = value
+		}
+	}
+	checksums := make([]partChecksum, 0, len(partNumbers))
+	for _, partNumber := range partNumbers {
+		entry := partEntries[partNumber]
+		value := string(entry.Extended[algorithm.Header()])
+		if value == "" {
+			glog.Errorf("completeMultipartUpload part %d has no %s checksum", partNumber, algorithm.Name())
+			return "", s3err.ErrInvalidPart
+		}
+		if listed, found := listedChecksums[partNumber]; found && listed != value {
+			glog.Errorf("completeMultipartUpload part %d %s checksum mismatch: %s, listed %s", partNumber, algorithm.Name(), value, listed)
+			return "", s3err.ErrInvalidPart
+		}
+		checksums = append(checksums, partChecksum{value: value, size: int64(filer.FileSize(entry))})
+	}
+	value, err := multipartChecksum(algorithm, checksumType, checksums)
+	if err != nil {
+		glog.Errorf("completeMultipartUpload: %v", err)
+		return "", s3err.ErrInvalidPart
+	}
+	return value, s3err.ErrNone
+}
+
 func (s3a *S3ApiServer) getEntryNameAndDir(input *s3.CompleteMultipartUploadInput) (string, string) {
 	entryName := filepath.Base(*input.Key)
 	dirName := filepath.ToSlash(filepath.Dir(*input.Key))
@@ -658,12 +760,24 @@ func (s3a *S3ApiServer) listObjectParts(input *s3.ListPartsInput) (output *ListP
 				glog.Errorf("listObjectParts %s %s parse %s: %v", *input.Bucket, *input.UploadId, entry.Name, err)
 				continue
 			}
-			output.Part = append(output.Part, &s3.Part{
+			part := &s3.Part{
 				PartNumber:   aws.Int64(int64(partNumber)),
 				LastModified: aws.Time(time.Unix(entry.Attributes.Mtime, 0).UTC()),
 				Size:         aws.Int64(int64(filer.FileSize(entry))),
 				ETag:         aws.String("\"" + filer.ETag(entry) + "\""),
-			})
+			}
+			// s3.Part has no CRC64NVME checksum
+			switch algorithm, value := storedChecksum(entry); algorithm {
+			case ChecksumAlgorithmCRC32:
+				part.ChecksumCRC32 = aws.String(value)
+			case ChecksumAlgorithmCRC32C:
+				part.ChecksumCRC32C = aws.String(value)
+			case ChecksumAlgorithmSHA1:
+				part.ChecksumSHA1 = aws.String(value)
+			case ChecksumAlgorithmSHA256:
+				part.ChecksumSHA256 = aws.String(value)
+			}
+			output.Part = append(output.Part, part)
 			if !isLast {
 				output.NextPartNumberMarker = aws.Int64(int64(partNumber))
 			}
@@ -1,13 +1,16 @@
 package s3api
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	util_http "github.com/seaweedfs/seaweedfs/weed/util/http"
 )
 
 func (s3a *S3ApiServer) mkdir(parentDirectoryPath string, dirName string, fn func(entry *filer_pb.Entry)) error {
@@ -54,15 +57,39 @@ func (s3a *S3ApiServer) rm(parentDirectoryPath, entryName string, isDeleteData,
 
 }
 
+// rmObject deletes an object, bypassing its governance mode retention if the request is permitted to
+func (s3a *S3ApiServer) rmObject(parentDirectoryPath, entryName string, bypassGovernance bool) error {
+
+	return s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return doDeleteObjectEntry(client, parentDirectoryPath, entryName, bypassGovernance)
+	})
+
+}
+
 func doDeleteEntry(client filer_pb.SeaweedFilerClient, parentDirectoryPath string, entryName string, isDeleteData bool, isRecursive bool) error {
-	request := &filer_pb.DeleteEntryRequest{
+	return doDeleteEntryRequest(client, &filer_pb.DeleteEntryRequest{
 		Directory:            parentDirectoryPath,
 		Name:                 entryName,
 		IsDeleteData:         isDeleteData,
 		IsRecursive:          isRecursive,
 		IgnoreRecursiveError: true,
-	}
+	})
+}
 
+// doDeleteObjectEntry deletes an object with its data. The filer checks the object lock
+// together with the delete, bypassing a governance mode retention if permitted.
+func doDeleteObjectEntry(client filer_pb.SeaweedFilerClient, parentDirectoryPath string, entryName string, bypassGovernance bool) error {
+	return doDeleteEntryRequest(client, &filer_pb.DeleteEntryRequest{
+		Directory:                 parentDirectoryPath,
+		Name:                      entryName,
+		IsDeleteData:              true,
+		IgnoreRecursiveError:      true,
+		BypassGovernanceRetention: bypassGovernance,
+	})
+}
+
+func doDeleteEntryRequest(client filer_pb.SeaweedFilerClient, request *filer_pb.DeleteEntryRequest) error {
+	parentDirectoryPath, entryName := request.Directory, request.Name
 	glog.V(1).Infof("delete entry %v/%v: %v", parentDirectoryPath, entryName, request)
 	if resp, err := client.DeleteEntry(context.Background(), request); err != nil {
 		glog.V(0).Infof("delete entry %v: %v", request, err)
@@ -108,6 +135,25 @@ func (s3a *S3ApiServer) updateEntry(parentDirectoryPath string, newEntry *filer_
 	return err
 }
 
+// putFilerObject writes a small object, e.g. a report or a log file, into a bucket through the filer
+func (s3a *S3ApiServer) putFilerObject(bucket, key string, data []byte, contentType string) error {
+	req, err := http.NewRequest(http.MethodPut, s3a.toFilerUrl(bucket, "/"+key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	s3a.maybeAddFilerJwtAuthorization(req, true)
+	resp, err := s3a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload %s/%s: %w", bucket, key, err)
+	}
+	defer util_http.CloseResponse(resp)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("upload %s/%s: %s", bucket, key, resp.Status)
+	}
+	return nil
+}
+
 func (s3a *S3ApiServer) getCollectionName(bucket string) string {
 	if s3a.option.FilerGroup != "" {
 		return fmt.Sprintf("%s_%s", s3a.option.FilerGroup, bucket)
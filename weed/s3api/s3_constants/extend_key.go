@@ -12,6 +12,10 @@ const (
 	ExtLatestVersionIdKey       = "Seaweed-X-Amz-Latest-Version-Id"
 	ExtLatestVersionFileNameKey = "Seaweed-X-Amz-Latest-Version-File-Name"
 
+	// Additional checksum of a multipart upload, chosen when the upload is created
+	ExtChecksumAlgorithmKey = "Seaweed-X-Amz-Checksum-Algorithm"
+	ExtChecksumTypeKey      = "Seaweed-X-Amz-Checksum-Type"
+
 	// Bucket Policy
 	ExtBucketPolicyKey = "Seaweed-X-Amz-Bucket-Policy"
 
@@ -25,6 +29,9 @@ const (
 	ExtObjectLockDefaultModeKey  = "Lock-Default-Mode"
 	ExtObjectLockDefaultDaysKey  = "Lock-Default-Days"
 	ExtObjectLockDefaultYearsKey = "Lock-Default-Years"
+
+	// Multi-site replication: the hybrid logical clock and site of the last local change
+	ExtSiteClockKey = "Seaweed-X-Site-Clock"
 )
 
 // Object Lock and Retention Constants
@@ -43,4 +50,15 @@ const (
 	// Bucket versioning status
 	VersioningEnabled   = "Enabled"
 	VersioningSuspended = "Suspended"
+
+	// Object replication status
+	ReplicationStatusPending   = "PENDING"
+	ReplicationStatusCompleted = "COMPLETED"
+	ReplicationStatusFailed    = "FAILED"
+	ReplicationStatusReplica   = "REPLICA"
+
+	// Additional checksum types
+	ChecksumTypeComposite  = "COMPOSITE"
+	ChecksumTypeFullObject = "FULL_OBJECT"
+	ChecksumModeEnabled    = "ENABLED"
 )
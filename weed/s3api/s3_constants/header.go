@@ -71,6 +71,30 @@ const (
 
 	AmzMpPartsCount = "X-Amz-Mp-Parts-Count"
 
+	// S3 GetObjectAttributes request headers
+	AmzObjectAttributes = "X-Amz-Object-Attributes"
+	AmzMaxParts         = "X-Amz-Max-Parts"
+	AmzPartNumberMarker = "X-Amz-Part-Number-Marker"
+
+	// S3 bucket replication status of an object, also stored as is in the entry extended attributes
+	AmzReplicationStatus = "X-Amz-Replication-Status"
+
+	// S3 additional checksums, the checksum values and type are also stored as is in the entry extended attributes
+	AmzChecksumPrefix       = "X-Amz-Checksum-"
+	AmzChecksumCRC32        = "X-Amz-Checksum-Crc32"
+	AmzChecksumCRC32C       = "X-Amz-Checksum-Crc32c"
+	AmzChecksumCRC64NVME    = "X-Amz-Checksum-Crc64nvme"
+	AmzChecksumSHA1         = "X-Amz-Checksum-Sha1"
+	AmzChecksumSHA256       = "X-Amz-Checksum-Sha256"
+	AmzChecksumType         = "X-Amz-Checksum-Type"
+	AmzChecksumMode         = "X-Amz-Checksum-Mode"
+	AmzChecksumAlgorithm    = "X-Amz-Checksum-Algorithm"
+	AmzSdkChecksumAlgorithm = "X-Amz-Sdk-Checksum-Algorithm"
+	AmzTrailer              = "X-Amz-Trailer"
+
+	// session token of temporary credentials issued by STS, a header or a presigned URL query parameter
+	AmzSecurityToken = "X-Amz-Security-Token"
+
 	// S3 Server-Side Encryption with Customer-provided Keys (SSE-C)
 	AmzServerSideEncryptionCustomerAlgorithm = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
 	AmzServerSideEncryptionCustomerKey       = "X-Amz-Server-Side-Encryption-Customer-Key"
@@ -110,15 +134,19 @@ const (
 	SeaweedFSSSES3Encryption = "x-seaweedfs-sse-s3-encryption" // Encryption type for multipart upload SSE-S3 inheritance
 	SeaweedFSSSES3BaseIV     = "x-seaweedfs-sse-s3-base-iv"    // Base IV for multipart upload SSE-S3 (for IV offset calculation)
 	SeaweedFSSSES3KeyData    = "x-seaweedfs-sse-s3-key-data"   // Encrypted key data for multipart upload SSE-S3 inheritance
+
+	// Part boundaries of a completed multipart upload, for GetObject?partNumber and GetObjectAttributes
+	SeaweedFSMultipartParts = "x-seaweedfs-multipart-parts"
 )
 
 // SeaweedFS internal headers for filer communication
 const (
-	SeaweedFSSSEKMSKeyHeader    = "X-SeaweedFS-SSE-KMS-Key"     // Header for passing SSE-KMS metadata to filer
-	SeaweedFSSSEIVHeader        = "X-SeaweedFS-SSE-IV"          // Header for passing SSE-C IV to filer (SSE-C only)
-	SeaweedFSSSEKMSBaseIVHeader = "X-SeaweedFS-SSE-KMS-Base-IV" // Header for passing base IV for multipart SSE-KMS
-	SeaweedFSSSES3BaseIVHeader  = "X-SeaweedFS-SSE-S3-Base-IV"  // Header for passing base IV for multipart SSE-S3
-	SeaweedFSSSES3KeyDataHeader = "X-SeaweedFS-SSE-S3-Key-Data" // Header for passing key data for multipart SSE-S3
+	SeaweedFSSSEKMSKeyHeader        = "X-SeaweedFS-SSE-KMS-Key"       // Header for passing SSE-KMS metadata to filer
+	SeaweedFSSSEIVHeader            = "X-SeaweedFS-SSE-IV"            // Header for passing SSE-C IV to filer (SSE-C only)
+	SeaweedFSSSEKMSBaseIVHeader     = "X-SeaweedFS-SSE-KMS-Base-IV"   // Header for passing base IV for multipart SSE-KMS
+	SeaweedFSSSES3BaseIVHeader      = "X-SeaweedFS-SSE-S3-Base-IV"    // Header for passing base IV for multipart SSE-S3
+	SeaweedFSSSES3KeyDataHeader     = "X-SeaweedFS-SSE-S3-Key-Data"   // Header for passing key data for multipart SSE-S3
+	SeaweedFSBypassGovernanceHeader = "X-SeaweedFS-Bypass-Governance" // Header for overwriting an object with a permitted governance retention bypass
 )
 
 // Non-Standard S3 HTTP request constants
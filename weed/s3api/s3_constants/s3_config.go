@@ -13,6 +13,14 @@ var (
 	Separator                = ":"
 )
 
+// rate limit scopes and the key applying to all of a scope
+const (
+	RateLimitAccessKey = "accessKey"
+	RateLimitBucket    = "bucket"
+	RateLimitSourceIp  = "sourceIp"
+	RateLimitAll       = "*"
+)
+
 func Concat(elements ...string) string {
 	return strings.Join(elements, Separator)
 }
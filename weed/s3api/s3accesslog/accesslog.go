@@ -0,0 +1,209 @@
+package s3accesslog
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeLayout is the time format of the log records, e.g. [06/Feb/2019:00:00:38 +0000]
+const timeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// Entry is one request to a bucket with server access logging enabled
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/LogFormat.html
+type Entry struct {
+	BucketOwner      string
+	Bucket           string
+	Time             time.Time
+	RemoteIP         string
+	Requester        string
+	RequestID        string
+	Operation        string
+	Key              string
+	RequestURI       string
+	Status           int
+	ErrorCode        string
+	BytesSent        int64
+	ObjectSize       int64
+	TotalTime        time.Duration
+	TurnAroundTime   time.Duration
+	Referer          string
+	UserAgent        string
+	VersionId        string
+	HostId           string
+	SignatureVersion string
+	CipherSuite      string
+	AuthType         string
+	HostHeader       string
+	TLSVersion       string
+}
+
+// AppendTo appends the entry as one log record, terminated by a newline
+func (e *Entry) AppendTo(buf []byte) []byte {
+	buf = appendField(buf, e.BucketOwner)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.Bucket)
+	buf = append(buf, " ["...)
+	buf = e.Time.UTC().AppendFormat(buf, timeLayout)
+	buf = append(buf, "] "...)
+	buf = appendField(buf, e.RemoteIP)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.Requester)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.RequestID)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.Operation)
+	buf = append(buf, ' ')
+	buf = appendField(buf, escapeKey(e.Key))
+	buf = append(buf, ' ')
+	buf = appendQuoted(buf, e.RequestURI)
+	buf = append(buf, ' ')
+	buf = appendNumber(buf, int64(e.Status))
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.ErrorCode)
+	buf = append(buf, ' ')
+	buf = appendNumber(buf, e.BytesSent)
+	buf = append(buf, ' ')
+	buf = appendNumber(buf, e.ObjectSize)
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, e.TotalTime.Milliseconds(), 10)
+	buf = append(buf, ' ')
+	buf = appendNumber(buf, e.TurnAroundTime.Milliseconds())
+	buf = append(buf, ' ')
+	buf = appendQuoted(buf, e.Referer)
+	buf = append(buf, ' ')
+	buf = appendQuoted(buf, e.UserAgent)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.VersionId)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.HostId)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.SignatureVersion)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.CipherSuite)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.AuthType)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.HostHeader)
+	buf = append(buf, ' ')
+	buf = appendField(buf, e.TLSVersion)
+	// access point arn and acl required are not supported
+	buf = append(buf, " - -\n"...)
+	return buf
+}
+
+func appendField(buf []byte, value string) []byte {
+	if value == "" {
+		return append(buf, '-')
+	}
+	return append(buf, strings.ReplaceAll(value, " ", "%20")...)
+}
+
+func appendQuoted(buf []byte, value string) []byte {
+	if value == "" {
+		return append(buf, `"-"`...)
+	}
+	buf = append(buf, '"')
+	buf = append(buf, strings.ReplaceAll(value, `"`, `\"`)...)
+	return append(buf, '"')
+}
+
+func appendNumber(buf []byte, value int64) []byte {
+	if value <= 0 {
+		return append(buf, '-')
+	}
+	return strconv.AppendInt(buf, value, 10)
+}
+
+// escapeKey url encodes the object key, keeping the slashes
+func escapeKey(key string) string {
+	return strings.ReplaceAll(url.PathEscape(key), "%2F", "/")
+}
+
+// bucketSubresources maps the bucket sub-resource query parameters to the
+// resource names used in the operation field
+var bucketSubresources = map[string]string{
+	"accelerate":        "ACCELERATE",
+	"acl":               "ACL",
+	"cors":              "CORS",
+	"encryption":        "ENCRYPTION",
+	"inventory":         "INVENTORY",
+	"lifecycle":         "LIFECYCLE",
+	"location":          "LOCATION",
+	"logging":           "LOGGING_STATUS",
+	"notification":      "NOTIFICATION",
+	"object-lock":       "OBJECT_LOCK_CONFIGURATION",
+	"ownershipControls": "OWNERSHIP_CONTROLS",
+	"policy":            "BUCKETPOLICY",
+	"policyStatus":      "POLICY_STATUS",
+	"publicAccessBlock": "PUBLIC_ACCESS_BLOCK",
+	"replication":       "REPLICATION",
+	"requestPayment":    "REQUEST_PAYMENT",
+	"tagging":           "TAGGING",
+	"uploads":           "UPLOADS",
+	"versioning":        "VERSIONING",
+	"versions":          "BUCKETVERSIONS",
+	"website":           "WEBSITE",
+}
+
+// objectSubresources maps the object sub-resource query parameters to the
+// resource names used in the operation field
+var objectSubresources = map[string]string{
+	"acl":        "ACL",
+	"attributes": "OBJECT_ATTRIBUTES",
+	"legal-hold": "LEGAL_HOLD",
+	"restore":    "RESTORE",
+	"retention":  "RETENTION",
+	"tagging":    "OBJECT_TAGGING",
+	"uploads":    "UPLOADS",
+}
+
+// Operation returns the operation field of a request, e.g. REST.GET.OBJECT
+// or REST.PUT.VERSIONING. The object is empty or "/" for bucket requests.
+func Operation(method, object string, query url.Values, header http.Header) string {
+	if object == "" || object == "/" {
+		if method == http.MethodPost && query.Has("delete") {
+			return "REST.POST.MULTI_OBJECT_DELETE"
+		}
+		return "REST." + method + "." + subresource(query, bucketSubresources, "BUCKET")
+	}
+	isCopy := header.Get("X-Amz-Copy-Source") != ""
+	if query.Has("uploadId") {
+		switch {
+		case method == http.MethodPut && isCopy:
+			return "REST.COPY.PART"
+		case method == http.MethodPut:
+			return "REST.PUT.PART"
+		default:
+			return "REST." + method + ".UPLOAD"
+		}
+	}
+	if method == http.MethodPut && isCopy {
+		return "REST.COPY.OBJECT"
+	}
+	return "REST." + method + "." + subresource(query, objectSubresources, "OBJECT")
+}
+
+// subresource picks the first known sub-resource in the query, in sorted
+// order so that the result does not depend on the map iteration
+func subresource(query url.Values, names map[string]string, defaultName string) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if _, found := names[key]; found {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return defaultName
+	}
+	sort.Strings(keys)
+	return names[keys[0]]
+}
+
+// ObjectKey returns the key of a log object, <prefix>YYYY-mm-DD-HH-MM-SS-<unique>
+func ObjectKey(prefix string, t time.Time, unique string) string {
+	return prefix + t.UTC().Format("2006-01-02-15-04-05") + "-" + unique
+}
@@ -0,0 +1,144 @@
+package s3accesslog
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppendTo(t *testing.T) {
+	entry := &Entry{
+		BucketOwner:      "admin",
+		Bucket:           "photos",
+		Time:             time.Date(2019, 2, 6, 0, 0, 38, 0, time.UTC),
+		RemoteIP:         "192.0.2.3",
+		Requester:        "alice",
+		RequestID:        "3E57427F33A59F07",
+		Operation:        "REST.GET.OBJECT",
+		Key:              "2019/08/puppy dog.jpg",
+		RequestURI:       `GET /photos/2019/08/puppy%20dog.jpg?x-foo="bar" HTTP/1.1`,
+		Status:           200,
+		BytesSent:        2662992,
+		ObjectSize:       3462992,
+		TotalTime:        70 * time.Millisecond,
+		TurnAroundTime:   10 * time.Millisecond,
+		UserAgent:        "aws-cli/2.0",
+		SignatureVersion: "SigV4",
+		AuthType:         "AuthHeader",
+		HostHeader:       "s3.example.com",
+	}
+	want := `admin photos [06/Feb/2019:00:00:38 +0000] 192.0.2.3 alice 3E57427F33A59F07 REST.GET.OBJECT 2019/08/puppy%20dog.jpg "GET /photos/2019/08/puppy%20dog.jpg?x-foo=\"bar\" HTTP/1.1" 200 - 2662992 3462992 70 10 "-" "aws-cli/2.0" - - SigV4 - AuthHeader s3.example.com - - -` + "\n"
+	if got := string(entry.AppendTo(nil)); got != want {
+		t.Errorf("unexpected record:\n%s\nwant:\n%s", got, want)
+	}
+
+	denied := &Entry{Bucket: "photos", Time: entry.Time, Operation: "REST.PUT.OBJECT", Status: 403, ErrorCode: "AccessDenied"}
+	want = `- photos [06/Feb/2019:00:00:38 +0000] - - - REST.PUT.OBJECT - "-" 403 AccessDenied - - 0 - "-" "-" - - - - - - - - -` + "\n"
+	if got := string(denied.AppendTo(nil)); got != want {
+		t.Errorf("unexpected record:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	copySource := http.Header{"X-Amz-Copy-Source": []string{"/photos/a.jpg"}}
+	tests := []struct {
+		method string
+		object string
+		query  string
+		header http.Header
+		want   string
+	}{
+		{http.MethodGet, "/", "", nil, "REST.GET.BUCKET"},
+		{http.MethodGet, "/", "list-type=2&prefix=a", nil, "REST.GET.BUCKET"},
+		{http.MethodPut, "/", "versioning", nil, "REST.PUT.VERSIONING"},
+		{http.MethodGet, "/", "logging", nil, "REST.GET.LOGGING_STATUS"},
+		{http.MethodGet, "/", "versions&prefix=a", nil, "REST.GET.BUCKETVERSIONS"},
+		{http.MethodPost, "/", "delete", nil, "REST.POST.MULTI_OBJECT_DELETE"},
+		{http.MethodGet, "/a.jpg", "", nil, "REST.GET.OBJECT"},
+		{http.MethodHead, "/a.jpg", "versionId=v1", nil, "REST.HEAD.OBJECT"},
+		{http.MethodPut, "/a.jpg", "tagging", nil, "REST.PUT.OBJECT_TAGGING"},
+		{http.MethodPut, "/b.jpg", "", copySource, "REST.COPY.OBJECT"},
+		{http.MethodPost, "/a.jpg", "uploads", nil, "REST.POST.UPLOADS"},
+		{http.MethodPut, "/a.jpg", "partNumber=1&uploadId=x", nil, "REST.PUT.PART"},
+		{http.MethodPut, "/a.jpg", "partNumber=1&uploadId=x", copySource, "REST.COPY.PART"},
+		{http.MethodPost, "/a.jpg", "uploadId=x", nil, "REST.POST.UPLOAD"},
+		{http.MethodDelete, "/a.jpg", "uploadId=x", nil, "REST.DELETE.UPLOAD"},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		header := tt.header
+		if header == nil {
+			header = http.Header{}
+		}
+		if got := Operation(tt.method, tt.object, query, header); got != tt.want {
+			t.Errorf("%s %s?%s: got %s, want %s", tt.method, tt.object, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLogger(t *testing.T) {
+	type delivery struct {
+		target Target
+		key    string
+		data   string
+	}
+	var mu sync.Mutex
+	var delivered []delivery
+	deliver := func(target Target, key string, data []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, delivery{target, key, string(data)})
+		return nil
+	}
+
+	start := time.Date(2024, 5, 8, 10, 20, 30, 0, time.UTC)
+	entry := &Entry{Bucket: "photos", Time: start, Operation: "REST.GET.OBJECT", Status: 200}
+	line := len(entry.AppendTo(nil))
+	logger := NewLogger(deliver, 2*line)
+	target := Target{Bucket: "logs", Prefix: "photos/"}
+	other := Target{Bucket: "logs", Prefix: "docs/"}
+
+	logger.Log(target, entry)
+	logger.Log(other, entry)
+	logger.Flush()
+	mu.Lock()
+	if len(delivered) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(delivered))
+	}
+	for _, d := range delivered {
+		if !strings.HasPrefix(d.key, d.target.Prefix+"2024-05-08-10-20-30-") || len(d.key) != len(d.target.Prefix)+len("2024-05-08-10-20-30-")+16 {
+			t.Errorf("unexpected key %s", d.key)
+		}
+		if len(d.data) != line {
+			t.Errorf("unexpected data %q", d.data)
+		}
+	}
+	delivered = nil
+	mu.Unlock()
+
+	// a full batch is delivered without waiting for the flush
+	logger.Log(target, entry)
+	logger.Log(target, entry)
+	for i := 0; ; i++ {
+		mu.Lock()
+		n := len(delivered)
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatal("full batch was not delivered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(delivered[0].data) != 2*line {
+		t.Errorf("unexpected batch size %d", len(delivered[0].data))
+	}
+	logger.Flush()
+	if len(delivered) != 1 {
+		t.Errorf("nothing should be left to flush, got %d deliveries", len(delivered))
+	}
+}
@@ -0,0 +1,101 @@
+package s3accesslog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// MaxBatchSize is the size of the buffered records of a target that
+// triggers a delivery before the next flush
+const MaxBatchSize = 4 * 1024 * 1024
+
+// Target is the bucket and key prefix the logs of a source bucket are written to
+type Target struct {
+	Bucket string
+	Prefix string
+}
+
+// DeliverFunc writes a batch of log records as one object into the target bucket
+type DeliverFunc func(target Target, key string, data []byte) error
+
+type batch struct {
+	start time.Time
+	data  []byte
+}
+
+// Logger buffers the log records per target, and delivers them in batches
+type Logger struct {
+	deliver      DeliverFunc
+	maxBatchSize int
+
+	mu      sync.Mutex
+	batches map[Target]*batch
+}
+
+func NewLogger(deliver DeliverFunc, maxBatchSize int) *Logger {
+	return &Logger{
+		deliver:      deliver,
+		maxBatchSize: maxBatchSize,
+		batches:      make(map[Target]*batch),
+	}
+}
+
+// Log buffers the entry. A batch reaching the maximum size is delivered in the background.
+func (l *Logger) Log(target Target, entry *Entry) {
+	l.mu.Lock()
+	b, found := l.batches[target]
+	if !found {
+		b = &batch{start: entry.Time}
+		l.batches[target] = b
+	}
+	b.data = entry.AppendTo(b.data)
+	full := len(b.data) >= l.maxBatchSize
+	if full {
+		delete(l.batches, target)
+	}
+	l.mu.Unlock()
+
+	if full {
+		go l.write(target, b)
+	}
+}
+
+// Flush delivers all buffered records
+func (l *Logger) Flush() {
+	l.mu.Lock()
+	batches := l.batches
+	l.batches = make(map[Target]*batch)
+	l.mu.Unlock()
+
+	for target, b := range batches {
+		l.write(target, b)
+	}
+}
+
+// Run flushes the buffered records every interval
+func (l *Logger) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		l.Flush()
+	}
+}
+
+func (l *Logger) write(target Target, b *batch) {
+	key := ObjectKey(target.Prefix, b.start, uniqueString())
+	if err := l.deliver(target, key, b.data); err != nil {
+		glog.Warningf("deliver access logs to %s/%s: %v", target.Bucket, key, err)
+	}
+}
+
+// uniqueString avoids collisions between the log objects written in the same second
+func uniqueString() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return strings.ToUpper(hex.EncodeToString(b[:]))
+}
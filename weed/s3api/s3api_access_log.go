@@ -0,0 +1,145 @@
+package s3api
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3accesslog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	stats_collect "github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+// accessLogWriter records what the server access log needs to know about a response
+type accessLogWriter struct {
+	*stats_collect.StatusRecorder
+	firstByte time.Time
+	bytesSent int64
+	errorCode string
+}
+
+func (w *accessLogWriter) WriteHeader(status int) {
+	if w.firstByte.IsZero() {
+		w.firstByte = time.Now()
+	}
+	w.StatusRecorder.WriteHeader(status)
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	if w.firstByte.IsZero() {
+		w.firstByte = time.Now()
+	}
+	n, err := w.StatusRecorder.Write(p)
+	w.bytesSent += int64(n)
+	return n, err
+}
+
+// RecordErrorCode implements s3err.ErrorCodeRecorder
+func (w *accessLogWriter) RecordErrorCode(code string) {
+	w.errorCode = code
+}
+
+// logAccess buffers the access log record of a request, if its bucket has logging enabled
+func (s3a *S3ApiServer) logAccess(r *http.Request, w *accessLogWriter, start time.Time) {
+	bucket, object := s3_constants.GetBucketAndObject(r)
+	if bucket == "" {
+		return
+	}
+	config, errCode := s3a.getBucketConfig(bucket)
+	if errCode != s3err.ErrNone || config.Logging == nil || config.Logging.TargetBucket == "" {
+		return
+	}
+	target := s3accesslog.Target{Bucket: config.Logging.TargetBucket, Prefix: config.Logging.TargetPrefix}
+	s3a.accessLogger.Log(target, newAccessLogEntry(r, w, start, config.Owner, bucket, object))
+}
+
+// deliverAccessLogs writes a batch of log records into the target bucket
+func (s3a *S3ApiServer) deliverAccessLogs(target s3accesslog.Target, key string, data []byte) error {
+	return s3a.putFilerObject(target.Bucket, key, data, "text/plain")
+}
+
+func newAccessLogEntry(r *http.Request, w *accessLogWriter, start time.Time, owner, bucket, object string) *s3accesslog.Entry {
+	entry := &s3accesslog.Entry{
+		BucketOwner: owner,
+		Bucket:      bucket,
+		Time:        start,
+		RemoteIP:    accessLogRemoteIP(r),
+		Requester:   r.Header.Get(s3_constants.AmzIdentityId),
+		RequestID:   w.Header().Get("x-amz-request-id"),
+		Operation:   s3accesslog.Operation(r.Method, object, r.URL.Query(), r.Header),
+		Key:         strings.TrimPrefix(object, "/"),
+		RequestURI:  r.Method + " " + r.RequestURI + " " + r.Proto,
+		Status:      w.Status,
+		ErrorCode:   w.errorCode,
+		BytesSent:   w.bytesSent,
+		TotalTime:   time.Since(start),
+		Referer:     r.Header.Get("Referer"),
+		UserAgent:   r.Header.Get("User-Agent"),
+		VersionId:   w.Header().Get("x-amz-version-id"),
+		HostHeader:  r.Host,
+	}
+	if entry.Key != "" {
+		entry.ObjectSize = accessLogObjectSize(r, w)
+	}
+	if !w.firstByte.IsZero() {
+		entry.TurnAroundTime = w.firstByte.Sub(start)
+	}
+	if entry.VersionId == "" {
+		entry.VersionId = r.URL.Query().Get("versionId")
+	}
+	switch authType := r.Header.Get(s3_constants.AmzAuthType); authType {
+	case "SigV2", "SigV4":
+		entry.SignatureVersion = authType
+	}
+	switch {
+	case r.Header.Get("Authorization") != "":
+		entry.AuthType = "AuthHeader"
+	case r.URL.Query().Has("X-Amz-Signature") || r.URL.Query().Has("Signature"):
+		entry.AuthType = "QueryString"
+	}
+	if r.TLS != nil {
+		entry.CipherSuite = tls.CipherSuiteName(r.TLS.CipherSuite)
+		entry.TLSVersion = strings.Replace(tls.VersionName(r.TLS.Version), "TLS ", "TLSv", 1)
+	}
+	return entry
+}
+
+func accessLogRemoteIP(r *http.Request) string {
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		ip, _, _ := strings.Cut(forwardedFor, ",")
+		return strings.TrimSpace(ip)
+	}
+	if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		return realIP
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// accessLogObjectSize is the size of the uploaded object, or the total size of the downloaded one
+func accessLogObjectSize(r *http.Request, w *accessLogWriter) int64 {
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		if decoded := r.Header.Get("X-Amz-Decoded-Content-Length"); decoded != "" {
+			size, _ := strconv.ParseInt(decoded, 10, 64)
+			return size
+		}
+		return r.ContentLength
+	case http.MethodGet, http.MethodHead:
+		if contentRange := w.Header().Get("Content-Range"); contentRange != "" {
+			if i := strings.LastIndexByte(contentRange, '/'); i >= 0 {
+				size, _ := strconv.ParseInt(contentRange[i+1:], 10, 64)
+				return size
+			}
+		}
+		size, _ := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64)
+		return size
+	}
+	return 0
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,6 +22,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/s3api/cors"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3inventory"
 )
 
 // BucketConfig represents cached bucket configuration
@@ -31,8 +34,11 @@ type BucketConfig struct {
 	Owner            string
 	IsPublicRead     bool // Cached flag to avoid JSON parsing on every request
 	CORS             *cors.CORSConfiguration
-	ObjectLockConfig *ObjectLockConfiguration // Cached parsed Object Lock configuration
-	KMSKeyCache      *BucketKMSCache          // Per-bucket KMS key cache for SSE-KMS operations
+	ObjectLockConfig *ObjectLockConfiguration           // Cached parsed Object Lock configuration
+	KMSKeyCache      *BucketKMSCache                    // Per-bucket KMS key cache for SSE-KMS operations
+	Logging          *s3_pb.LoggingConfiguration        // Cached server access logging target, checked on every request
+	Website          *s3_pb.WebsiteConfiguration        // Cached website configuration for the website endpoint
+	Transformation   *s3_pb.TransformationConfiguration // Cached transformations of GET responses
 	LastModified     time.Time
 	Entry            *filer_pb.Entry
 }
@@ -183,17 +189,19 @@ type BucketConfigCache struct {
 
 // BucketMetadata represents the complete metadata for a bucket
 type BucketMetadata struct {
-	Tags       map[string]string              `json:"tags,omitempty"`
-	CORS       *cors.CORSConfiguration        `json:"cors,omitempty"`
-	Encryption *s3_pb.EncryptionConfiguration `json:"encryption,omitempty"`
+	Tags           map[string]string                  `json:"tags,omitempty"`
+	CORS           *cors.CORSConfiguration            `json:"cors,omitempty"`
+	Encryption     *s3_pb.EncryptionConfiguration     `json:"encryption,omitempty"`
+	Lifecycle      *s3_pb.LifecycleConfiguration      `json:"lifecycle,omitempty"`
+	Replication    *s3_pb.ReplicationConfiguration    `json:"replication,omitempty"`
+	Notification   *s3_pb.NotificationConfiguration   `json:"notification,omitempty"`
+	Inventory      *s3_pb.InventoryConfigurations     `json:"inventory,omitempty"`
+	Logging        *s3_pb.LoggingConfiguration        `json:"logging,omitempty"`
+	Website        *s3_pb.WebsiteConfiguration        `json:"website,omitempty"`
+	Transformation *s3_pb.TransformationConfiguration `json:"transformation,omitempty"`
 	// Future extensions can be added here:
 	// Versioning    *s3_pb.VersioningConfiguration   `json:"versioning,omitempty"`
-	// Lifecycle     *s3_pb.LifecycleConfiguration    `json:"lifecycle,omitempty"`
-	// Notification  *s3_pb.NotificationConfiguration `json:"notification,omitempty"`
-	// Replication   *s3_pb.ReplicationConfiguration  `json:"replication,omitempty"`
 	// Analytics     *s3_pb.AnalyticsConfiguration    `json:"analytics,omitempty"`
-	// Logging       *s3_pb.LoggingConfiguration      `json:"logging,omitempty"`
-	// Website       *s3_pb.WebsiteConfiguration      `json:"website,omitempty"`
 	// RequestPayer  *s3_pb.RequestPayerConfiguration `json:"requestPayer,omitempty"`
 	// PublicAccess  *s3_pb.PublicAccessConfiguration `json:"publicAccess,omitempty"`
 }
@@ -207,7 +215,7 @@ func NewBucketMetadata() *BucketMetadata {
 
 // IsEmpty returns true if the metadata has no configuration set
 func (bm *BucketMetadata) IsEmpty() bool {
-	return len(bm.Tags) == 0 && bm.CORS == nil && bm.Encryption == nil
+	return len(bm.Tags) == 0 && bm.CORS == nil && bm.Encryption == nil && bm.Lifecycle == nil && bm.Replication == nil && bm.Notification == nil && bm.Inventory == nil && bm.Logging == nil && bm.Website == nil && bm.Transformation == nil
 }
 
 // HasEncryption returns true if bucket has encryption configuration
@@ -220,6 +228,41 @@ func (bm *BucketMetadata) HasCORS() bool {
 	return bm.CORS != nil
 }
 
+// HasLifecycle returns true if bucket has lifecycle configuration
+func (bm *BucketMetadata) HasLifecycle() bool {
+	return bm.Lifecycle != nil && len(bm.Lifecycle.Rules) > 0
+}
+
+// HasReplication returns true if bucket has replication configuration
+func (bm *BucketMetadata) HasReplication() bool {
+	return bm.Replication != nil && len(bm.Replication.Rules) > 0
+}
+
+// HasNotification returns true if bucket has notification configuration
+func (bm *BucketMetadata) HasNotification() bool {
+	return bm.Notification != nil && len(bm.Notification.Rules) > 0
+}
+
+// HasInventory returns true if bucket has inventory configurations
+func (bm *BucketMetadata) HasInventory() bool {
+	return bm.Inventory != nil && len(bm.Inventory.Configurations) > 0
+}
+
+// HasLogging returns true if bucket has server access logging enabled
+func (bm *BucketMetadata) HasLogging() bool {
+	return bm.Logging != nil && bm.Logging.TargetBucket != ""
+}
+
+// HasWebsite returns true if bucket has website configuration
+func (bm *BucketMetadata) HasWebsite() bool {
+	return bm.Website != nil
+}
+
+// HasTransformation returns true if bucket has transformation rules
+func (bm *BucketMetadata) HasTransformation() bool {
+	return bm.Transformation != nil && len(bm.Transformation.Rules) > 0
+}
+
 // HasTags returns true if bucket has tags
 func (bm *BucketMetadata) HasTags() bool {
 	return len(bm.Tags) > 0
@@ -386,6 +429,10 @@ func (s3a *S3ApiServer) getBucketConfig(bucket string) (*BucketConfig, s3err.Err
 	} else {
 		config.CORS = corsConfig
 	}
+	protoMetadata := loadMetadataFromEntry(entry)
+	config.Logging = protoMetadata.Logging
+	config.Website = protoMetadata.Website
+	config.Transformation = protoMetadata.Transformation
 
 	// Cache the result
 	s3a.bucketConfigCache.Set(bucket, config)
@@ -532,6 +579,20 @@ func (s3a *S3ApiServer) setBucketOwnership(bucket, ownership string) s3err.Error
 	})
 }
 
+// loadMetadataFromEntry reads the bucket metadata kept in the bucket entry content,
+// for the configurations cached in BucketConfig
+func loadMetadataFromEntry(entry *filer_pb.Entry) *s3_pb.BucketMetadata {
+	protoMetadata := &s3_pb.BucketMetadata{}
+	if len(entry.Content) == 0 {
+		return protoMetadata
+	}
+	if err := proto.Unmarshal(entry.Content, protoMetadata); err != nil {
+		glog.Errorf("loadMetadataFromEntry: failed to unmarshal protobuf metadata for bucket %s: %v", entry.Name, err)
+		return &s3_pb.BucketMetadata{}
+	}
+	return protoMetadata
+}
+
 // loadCORSFromBucketContent loads CORS configuration from bucket directory content
 func (s3a *S3ApiServer) loadCORSFromBucketContent(bucket string) (*cors.CORSConfiguration, error) {
 	metadata, err := s3a.GetBucketMetadata(bucket)
@@ -712,9 +773,16 @@ func (s3a *S3ApiServer) extractMetadataFromConfig(config *BucketConfig) (*Bucket
 		}
 		// Convert protobuf to structured metadata
 		metadata := &BucketMetadata{
-			Tags:       protoMetadata.Tags,
-			CORS:       corsConfigFromProto(protoMetadata.Cors),
-			Encryption: protoMetadata.Encryption,
+			Tags:           protoMetadata.Tags,
+			CORS:           corsConfigFromProto(protoMetadata.Cors),
+			Encryption:     protoMetadata.Encryption,
+			Lifecycle:      protoMetadata.Lifecycle,
+			Replication:    protoMetadata.Replication,
+			Notification:   protoMetadata.Notification,
+			Inventory:      protoMetadata.Inventory,
+			Logging:        protoMetadata.Logging,
+			Website:        protoMetadata.Website,
+			Transformation: protoMetadata.Transformation,
 		}
 		return metadata, nil
 	}
@@ -779,9 +847,16 @@ func (s3a *S3ApiServer) loadBucketMetadataFromFiler(bucket string) (*BucketMetad
 
 	// Create and return structured metadata
 	metadata := &BucketMetadata{
-		Tags:       protoMetadata.Tags,
-		CORS:       corsConfig,
-		Encryption: protoMetadata.Encryption,
+		Tags:           protoMetadata.Tags,
+		CORS:           corsConfig,
+		Encryption:     protoMetadata.Encryption,
+		Lifecycle:      protoMetadata.Lifecycle,
+		Replication:    protoMetadata.Replication,
+		Notification:   protoMetadata.Notification,
+		Inventory:      protoMetadata.Inventory,
+		Logging:        protoMetadata.Logging,
+		Website:        protoMetadata.Website,
+		Transformation: protoMetadata.Transformation,
 	}
 
 	return metadata, nil
@@ -808,9 +883,16 @@ func (s3a *S3ApiServer) setBucketMetadata(bucket string, metadata *BucketMetadat
 
 	// Create protobuf metadata
 	protoMetadata := &s3_pb.BucketMetadata{
-		Tags:       metadata.Tags,
-		Cors:       corsConfigToProto(metadata.CORS),
-		Encryption: metadata.Encryption,
+		Tags:           metadata.Tags,
+		Cors:           corsConfigToProto(metadata.CORS),
+		Encryption:     metadata.Encryption,
+		Lifecycle:      metadata.Lifecycle,
+		Replication:    metadata.Replication,
+		Notification:   metadata.Notification,
+		Inventory:      metadata.Inventory,
+		Logging:        metadata.Logging,
+		Website:        metadata.Website,
+		Transformation: metadata.Transformation,
 	}
 
 	// Marshal metadata to protobuf
@@ -922,6 +1004,98 @@ func (s3a *S3ApiServer) UpdateBucketEncryption(bucket string, encryptionConfig *
 	})
 }
 
+// UpdateBucketLifecycle sets bucket lifecycle configuration using the structured API
+func (s3a *S3ApiServer) UpdateBucketLifecycle(bucket string, lifecycleConfig *s3_pb.LifecycleConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Lifecycle = lifecycleConfig
+		return nil
+	})
+}
+
+// UpdateBucketReplication sets bucket replication configuration using the structured API
+func (s3a *S3ApiServer) UpdateBucketReplication(bucket string, replicationConfig *s3_pb.ReplicationConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Replication = replicationConfig
+		return nil
+	})
+}
+
+// UpdateBucketNotification sets bucket notification configuration using the structured API
+func (s3a *S3ApiServer) UpdateBucketNotification(bucket string, notificationConfig *s3_pb.NotificationConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Notification = notificationConfig
+		return nil
+	})
+}
+
+// UpdateBucketLogging sets bucket server access logging configuration using the structured API
+func (s3a *S3ApiServer) UpdateBucketLogging(bucket string, loggingConfig *s3_pb.LoggingConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Logging = loggingConfig
+		return nil
+	})
+}
+
+// UpdateBucketWebsite sets bucket website configuration using the structured API
+func (s3a *S3ApiServer) UpdateBucketWebsite(bucket string, websiteConfig *s3_pb.WebsiteConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Website = websiteConfig
+		return nil
+	})
+}
+
+// UpdateBucketTransformation sets the transformations of GET responses of a bucket
+func (s3a *S3ApiServer) UpdateBucketTransformation(bucket string, transformationConfig *s3_pb.TransformationConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Transformation = transformationConfig
+		return nil
+	})
+}
+
+// PutBucketInventory adds an inventory configuration, or replaces the one with the same id.
+// The configurations are kept sorted by id.
+func (s3a *S3ApiServer) PutBucketInventory(bucket string, inventoryConfig *s3_pb.InventoryConfiguration) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		inventory := &s3_pb.InventoryConfigurations{}
+		if metadata.Inventory != nil {
+			inventory.Configurations = append(inventory.Configurations, metadata.Inventory.Configurations...)
+		}
+		i := sort.Search(len(inventory.Configurations), func(i int) bool {
+			return inventory.Configurations[i].Id >= inventoryConfig.Id
+		})
+		switch {
+		case i < len(inventory.Configurations) && inventory.Configurations[i].Id == inventoryConfig.Id:
+			inventory.Configurations[i] = inventoryConfig
+		case len(inventory.Configurations) >= s3inventory.MaxConfigurations:
+			return errTooManyInventoryConfigurations
+		default:
+			inventory.Configurations = slices.Insert(inventory.Configurations, i, inventoryConfig)
+		}
+		metadata.Inventory = inventory
+		return nil
+	})
+}
+
+// DeleteBucketInventory removes the inventory configuration with the id
+func (s3a *S3ApiServer) DeleteBucketInventory(bucket string, id string) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		if findInventoryConfiguration(metadata, id) == nil {
+			return errNoSuchInventoryConfiguration
+		}
+		inventory := &s3_pb.InventoryConfigurations{}
+		for _, config := range metadata.Inventory.Configurations {
+			if config.Id != id {
+				inventory.Configurations = append(inventory.Configurations, config)
+			}
+		}
+		metadata.Inventory = inventory
+		if len(inventory.Configurations) == 0 {
+			metadata.Inventory = nil
+		}
+		return nil
+	})
+}
+
 // ClearBucketTags removes all bucket tags using the structured API
 func (s3a *S3ApiServer) ClearBucketTags(bucket string) error {
 	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
@@ -945,3 +1119,27 @@ func (s3a *S3ApiServer) ClearBucketEncryption(bucket string) error {
 		return nil
 	})
 }
+
+// ClearBucketLifecycle removes bucket lifecycle configuration using the structured API
+func (s3a *S3ApiServer) ClearBucketLifecycle(bucket string) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Lifecycle = nil
+		return nil
+	})
+}
+
+// ClearBucketReplication removes bucket replication configuration using the structured API
+func (s3a *S3ApiServer) ClearBucketReplication(bucket string) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Replication = nil
+		return nil
+	})
+}
+
+// ClearBucketNotification removes bucket notification configuration using the structured API
+func (s3a *S3ApiServer) ClearBucketNotification(bucket string) error {
+	return s3a.UpdateBucketMetadata(bucket, func(metadata *BucketMetadata) error {
+		metadata.Notification = nil
+		return nil
+	})
+}
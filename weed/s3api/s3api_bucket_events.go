@@ -0,0 +1,215 @@
+package s3api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/cluster"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	bucketEventOffsetInterval = 3 * time.Second
+	bucketEventRetryInterval  = 5 * time.Second
+)
+
+var errBucketEventLockLost = errors.New("lock lost")
+
+// bucketEventSignature is the signature of the filer updates made by a bucket
+// event follower. The filer does not send these updates back to the follower.
+func bucketEventSignature(name string) int32 {
+	return int32(util.HashStringToLong(name))
+}
+
+// bucketEventFollower processes the metadata events under the buckets folder,
+// resuming from the offset saved in the filer.
+// Only the gateway holding the distributed lock follows the events, so several
+// gateways can share one filer.
+type bucketEventFollower struct {
+	s3a          *S3ApiServer
+	name         string // names the lock, the subscription and the saved offset
+	clientId     int32
+	clientEpoch  int32
+	processEvent func(resp *filer_pb.SubscribeMetadataResponse)
+	// loadOffset and saveOffset replace the offset saved in the filer kv store,
+	// for followers that save the offset together with their own state
+	loadOffset func() (int64, error)
+	saveOffset func(offsetTsNs int64) error
+	// lock replaces the lock named by the follower, for followers working together with other tasks of the lock holder
+	lock *cluster.LiveLock
+}
+
+func newBucketEventFollower(s3a *S3ApiServer, name string, processEvent func(resp *filer_pb.SubscribeMetadataResponse)) *bucketEventFollower {
+	return &bucketEventFollower{
+		s3a:          s3a,
+		name:         name,
+		clientId:     util.RandomInt32(),
+		processEvent: processEvent,
+	}
+}
+
+func (f *bucketEventFollower) run() {
+	self := fmt.Sprintf("s3@%s:%d-%d", util.DetectedHostAddress(), f.s3a.option.Port, f.s3a.randomClientId)
+	lock := f.lock
+	if lock == nil {
+		lock = f.s3a.lockClient.StartLongLivedLock(f.name, self, func(newLockOwner string) {
+			glog.V(0).Infof("%s is now run by %s", f.name, newLockOwner)
+		})
+	}
+
+	isOwner := func() bool {
+		return lock.LockOwner() == self
+	}
+	for {
+		if isOwner() {
+			if err := f.follow(isOwner); err != nil && err != errBucketEventLockLost {
+				glog.V(0).Infof("%s: %v", f.name, err)
+			}
+		}
+		time.Sleep(bucketEventRetryInterval)
+	}
+}
+
+// follow processes the metadata events from the last saved offset until
+// an error occurs or the lock is taken over by another gateway
+func (f *bucketEventFollower) follow(isOwner func() bool) error {
+	startTsNs, err := f.readOffset()
+	if err != nil {
+		return fmt.Errorf("read offset: %w", err)
+	}
+	if startTsNs == 0 {
+		startTsNs = time.Now().UnixNano()
+	}
+	glog.V(0).Infof("%s resumes from %v", f.name, time.Unix(0, startTsNs))
+
+	f.clientEpoch++
+	return f.s3a.WithFilerClient(true, func(client filer_pb.SeaweedFilerClient) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := client.SubscribeMetadata(ctx, &filer_pb.SubscribeMetadataRequest{
+			ClientName:  f.name,
+			PathPrefix:  f.s3a.option.BucketsPath + "/",
+			SinceNs:     startTsNs,
+			Signature:   bucketEventSignature(f.name),
+			ClientId:    f.clientId,
+			ClientEpoch: f.clientEpoch,
+		})
+		if err != nil {
+			return fmt.Errorf("subscribe: %w", err)
+		}
+
+		var lastOffsetTime time.Time
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return err
+			}
+			if !isOwner() {
+				return errBucketEventLockLost
+			}
+			f.processEvent(resp)
+			if time.Since(lastOffsetTime) > bucketEventOffsetInterval {
+				if err := f.writeOffset(resp.TsNs); err != nil {
+					return fmt.Errorf("write offset: %w", err)
+				}
+				lastOffsetTime = time.Now()
+			}
+		}
+	})
+}
+
+func (f *bucketEventFollower) offsetKey() []byte {
+	return []byte(f.name + ".offset")
+}
+
+func (f *bucketEventFollower) readOffset() (offsetTsNs int64, err error) {
+	if f.loadOffset != nil {
+		return f.loadOffset()
+	}
+	err = f.s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.KvGet(context.Background(), &filer_pb.KvGetRequest{Key: f.offsetKey()})
+		if err != nil {
+			return err
+		}
+		if len(resp.Error) != 0 {
+			return errors.New(resp.Error)
+		}
+		if len(resp.Value) >= 8 {
+			offsetTsNs = int64(util.BytesToUint64(resp.Value))
+		}
+		return nil
+	})
+	return
+}
+
+func (f *bucketEventFollower) writeOffset(offsetTsNs int64) error {
+	if f.saveOffset != nil {
+		return f.saveOffset(offsetTsNs)
+	}
+	return f.s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		value := make([]byte, 8)
+		util.Uint64toBytes(value, uint64(offsetTsNs))
+		resp, err := client.KvPut(context.Background(), &filer_pb.KvPutRequest{Key: f.offsetKey(), Value: value})
+		if err != nil {
+			return err
+		}
+		if len(resp.Error) != 0 {
+			return errors.New(resp.Error)
+		}
+		return nil
+	})
+}
+
+// bucketObjectEvent locates the object of a metadata event under the buckets folder
+type bucketObjectEvent struct {
+	dir       string // the directory holding the entry
+	entry     *filer_pb.Entry
+	bucket    string
+	key       string
+	isVersion bool // the entry is a version file in <key>.versions/
+}
+
+// parseBucketObjectEvent returns false for events that are not about an object,
+// e.g. directories or in-progress multipart uploads
+func (s3a *S3ApiServer) parseBucketObjectEvent(resp *filer_pb.SubscribeMetadataResponse) (event bucketObjectEvent, ok bool) {
+	message := resp.EventNotification
+	dir, entry := resp.Directory, message.NewEntry
+	if entry == nil {
+		entry = message.OldEntry
+	} else if message.NewParentPath != "" {
+		dir = message.NewParentPath
+	}
+	return s3a.locateBucketObject(dir, entry)
+}
+
+// locateBucketObject returns false for entries that are not an object,
+// e.g. directories or in-progress multipart uploads
+func (s3a *S3ApiServer) locateBucketObject(dir string, entry *filer_pb.Entry) (event bucketObjectEvent, ok bool) {
+	event.dir, event.entry = dir, entry
+	if event.entry == nil || event.entry.IsDirectory {
+		return event, false
+	}
+
+	bucketsDir := s3a.option.BucketsPath + "/"
+	if !strings.HasPrefix(event.dir+"/", bucketsDir) {
+		return event, false
+	}
+	bucket, objectDir, _ := strings.Cut(strings.TrimPrefix(event.dir+"/", bucketsDir), "/")
+	if bucket == "" || objectDir == s3_constants.MultipartUploadsFolder+"/" || strings.HasPrefix(objectDir, s3_constants.MultipartUploadsFolder+"/") {
+		return event, false
+	}
+	event.bucket = bucket
+
+	// versions of an object are stored as <key>.versions/<version file>
+	event.key = objectDir + event.entry.Name
+	if strings.HasSuffix(objectDir, s3_constants.VersionsFolder+"/") {
+		event.key, event.isVersion = strings.TrimSuffix(objectDir, s3_constants.VersionsFolder+"/"), true
+	}
+	return event, true
+}
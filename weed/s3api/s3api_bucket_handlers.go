@@ -21,6 +21,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3lifecycle"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -437,6 +438,18 @@ func (s3a *S3ApiServer) GetBucketLifecycleConfigurationHandler(w http.ResponseWr
 		s3err.WriteErrorResponse(w, r, err)
 		return
 	}
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketLifecycleConfigurationHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if metadata.HasLifecycle() {
+		writeSuccessResponseXML(w, r, lifecycleConfigFromProto(metadata.Lifecycle))
+		return
+	}
+
+	// fall back to the collection TTLs configured before lifecycle rules were stored with the bucket
 	fc, err := filer.ReadFilerConf(s3a.option.Filer, s3a.option.GrpcDialOption, nil)
 	if err != nil {
 		glog.Errorf("GetBucketLifecycleConfigurationHandler: %s", err)
@@ -498,6 +511,26 @@ func (s3a *S3ApiServer) PutBucketLifecycleConfigurationHandler(w http.ResponseWr
 		return
 	}
 
+	lifecycleRules := lifecycleConfigToProto(&lifeCycleConfig)
+	for _, rule := range lifecycleRules.Rules {
+		for _, transition := range append(rule.Transitions, rule.NoncurrentVersionTransitions...) {
+			if transition.StorageClass != "" && !storageClasses[transition.StorageClass] {
+				s3err.WriteErrorResponse(w, r, s3err.ErrInvalidStorageClass)
+				return
+			}
+		}
+	}
+	if err := s3lifecycle.Validate(lifecycleRules); err != nil {
+		glog.Warningf("PutBucketLifecycleConfigurationHandler invalid configuration: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+	if err := s3a.UpdateBucketLifecycle(bucket, lifecycleRules); err != nil {
+		glog.Errorf("PutBucketLifecycleConfigurationHandler save lifecycle: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
 	fc, err := filer.ReadFilerConf(s3a.option.Filer, s3a.option.GrpcDialOption, nil)
 	if err != nil {
 		glog.Errorf("PutBucketLifecycleConfigurationHandler read filer config: %s", err)
@@ -508,8 +541,10 @@ func (s3a *S3ApiServer) PutBucketLifecycleConfigurationHandler(w http.ResponseWr
 	collectionTtls := fc.GetCollectionTtls(collectionName)
 	changed := false
 
+	// simple prefix expirations are also enforced as collection TTLs,
+	// everything else is handled by the lifecycle processor
 	for _, rule := range lifeCycleConfig.Rules {
-		if rule.Status != Enabled {
+		if rule.Status != Enabled || !isTtlCompatible(rule) {
 			continue
 		}
 		var rulePrefix string
@@ -518,13 +553,6 @@ func (s3a *S3ApiServer) PutBucketLifecycleConfigurationHandler(w http.ResponseWr
 			rulePrefix = rule.Filter.Prefix.val
 		case rule.Prefix.set:
 			rulePrefix = rule.Prefix.val
-		case !rule.Expiration.Date.IsZero() || rule.Transition.Days > 0 || !rule.Transition.Date.IsZero():
-			s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
-			return
-		}
-
-		if rule.Expiration.Days == 0 {
-			continue
 		}
 
 		locConf := &filer_pb.FilerConf_PathConf{
@@ -573,6 +601,12 @@ func (s3a *S3ApiServer) DeleteBucketLifecycleHandler(w http.ResponseWriter, r *h
 		return
 	}
 
+	if err := s3a.ClearBucketLifecycle(bucket); err != nil {
+		glog.Errorf("DeleteBucketLifecycleHandler clear lifecycle: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
 	fc, err := filer.ReadFilerConf(s3a.option.Filer, s3a.option.GrpcDialOption, nil)
 	if err != nil {
 		glog.Errorf("DeleteBucketLifecycleHandler read filer config: %s", err)
@@ -0,0 +1,270 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3inventory"
+)
+
+const maxInventoryConfigurationsPerPage = 100
+
+var (
+	errTooManyInventoryConfigurations = errors.New("too many inventory configurations")
+	errNoSuchInventoryConfiguration   = errors.New("no such inventory configuration")
+)
+
+// InventoryConfiguration is the XML model of a bucket inventory configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_InventoryConfiguration.html
+type InventoryConfiguration struct {
+	XMLName                xml.Name                 `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InventoryConfiguration"`
+	Destination            InventoryDestination     `xml:"Destination"`
+	IsEnabled              bool                     `xml:"IsEnabled"`
+	Filter                 *InventoryFilter         `xml:"Filter,omitempty"`
+	Id                     string                   `xml:"Id"`
+	IncludedObjectVersions string                   `xml:"IncludedObjectVersions"`
+	OptionalFields         *InventoryOptionalFields `xml:"OptionalFields,omitempty"`
+	Schedule               InventorySchedule        `xml:"Schedule"`
+}
+
+type InventoryDestination struct {
+	S3BucketDestination InventoryS3BucketDestination `xml:"S3BucketDestination"`
+}
+
+type InventoryS3BucketDestination struct {
+	AccountId  string    `xml:"AccountId,omitempty"`
+	Bucket     string    `xml:"Bucket"`
+	Format     string    `xml:"Format"`
+	Prefix     string    `xml:"Prefix,omitempty"`
+	Encryption *struct{} `xml:"Encryption,omitempty"`
+}
+
+type InventoryFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type InventoryOptionalFields struct {
+	Fields []string `xml:"Field"`
+}
+
+type InventorySchedule struct {
+	Frequency string `xml:"Frequency"`
+}
+
+// ListInventoryConfigurationsResult is the response of ListBucketInventoryConfigurations
+type ListInventoryConfigurationsResult struct {
+	XMLName                 xml.Name                  `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListInventoryConfigurationsResult"`
+	ContinuationToken       string                    `xml:"ContinuationToken,omitempty"`
+	InventoryConfigurations []*InventoryConfiguration `xml:"InventoryConfiguration"`
+	IsTruncated             bool                      `xml:"IsTruncated"`
+	NextContinuationToken   string                    `xml:"NextContinuationToken,omitempty"`
+}
+
+const (
+	inventoryVersionsAll     = "All"
+	inventoryVersionsCurrent = "Current"
+)
+
+// inventoryConfigToProto converts an inventory configuration from the XML model into its stored form
+func inventoryConfigToProto(config *InventoryConfiguration) (*s3_pb.InventoryConfiguration, s3err.ErrorCode) {
+	destination := config.Destination.S3BucketDestination
+	if destination.Encryption != nil {
+		return nil, s3err.ErrNotImplemented
+	}
+	protoConfig := &s3_pb.InventoryConfiguration{
+		Id:                   config.Id,
+		Enabled:              config.IsEnabled,
+		DestinationBucket:    destination.Bucket,
+		DestinationPrefix:    destination.Prefix,
+		DestinationAccountId: destination.AccountId,
+		Format:               destination.Format,
+		Frequency:            config.Schedule.Frequency,
+	}
+	switch config.IncludedObjectVersions {
+	case inventoryVersionsAll:
+		protoConfig.AllVersions = true
+	case inventoryVersionsCurrent:
+	default:
+		return nil, s3err.ErrMalformedXML
+	}
+	if config.Filter != nil {
+		protoConfig.Prefix = config.Filter.Prefix
+	}
+	if config.OptionalFields != nil {
+		protoConfig.OptionalFields = config.OptionalFields.Fields
+	}
+	return protoConfig, s3err.ErrNone
+}
+
+// inventoryConfigFromProto converts a stored inventory configuration back into the XML model
+func inventoryConfigFromProto(protoConfig *s3_pb.InventoryConfiguration) *InventoryConfiguration {
+	config := &InventoryConfiguration{
+		Destination: InventoryDestination{S3BucketDestination: InventoryS3BucketDestination{
+			AccountId: protoConfig.DestinationAccountId,
+			Bucket:    protoConfig.DestinationBucket,
+			Format:    protoConfig.Format,
+			Prefix:    protoConfig.DestinationPrefix,
+		}},
+		IsEnabled:              protoConfig.Enabled,
+		Id:                     protoConfig.Id,
+		IncludedObjectVersions: inventoryVersionsCurrent,
+		Schedule:               InventorySchedule{Frequency: protoConfig.Frequency},
+	}
+	if protoConfig.AllVersions {
+		config.IncludedObjectVersions = inventoryVersionsAll
+	}
+	if protoConfig.Prefix != "" {
+		config.Filter = &InventoryFilter{Prefix: protoConfig.Prefix}
+	}
+	if len(protoConfig.OptionalFields) > 0 {
+		config.OptionalFields = &InventoryOptionalFields{Fields: protoConfig.OptionalFields}
+	}
+	return config
+}
+
+func findInventoryConfiguration(metadata *BucketMetadata, id string) *s3_pb.InventoryConfiguration {
+	if !metadata.HasInventory() {
+		return nil
+	}
+	for _, config := range metadata.Inventory.Configurations {
+		if config.Id == id {
+			return config
+		}
+	}
+	return nil
+}
+
+// GetBucketInventoryConfigurationHandler Get bucket inventory configuration, or
+// list them if no id is given
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketInventoryConfiguration.html
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListBucketInventoryConfigurations.html
+func (s3a *S3ApiServer) GetBucketInventoryConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketInventoryConfigurationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketInventoryConfigurationHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeSuccessResponseXML(w, r, listInventoryConfigurations(metadata, r.URL.Query().Get("continuation-token")))
+		return
+	}
+	config := findInventoryConfiguration(metadata, id)
+	if config == nil {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchConfiguration)
+		return
+	}
+	writeSuccessResponseXML(w, r, inventoryConfigFromProto(config))
+}
+
+// listInventoryConfigurations returns a page of the configurations, which are sorted by id.
+// The continuation token is the id of the last configuration of the previous page.
+func listInventoryConfigurations(metadata *BucketMetadata, continuationToken string) *ListInventoryConfigurationsResult {
+	result := &ListInventoryConfigurationsResult{ContinuationToken: continuationToken}
+	if !metadata.HasInventory() {
+		return result
+	}
+	for _, config := range metadata.Inventory.Configurations {
+		if config.Id <= continuationToken {
+			continue
+		}
+		if len(result.InventoryConfigurations) == maxInventoryConfigurationsPerPage {
+			result.IsTruncated = true
+			result.NextContinuationToken = result.InventoryConfigurations[len(result.InventoryConfigurations)-1].Id
+			break
+		}
+		result.InventoryConfigurations = append(result.InventoryConfigurations, inventoryConfigFromProto(config))
+	}
+	return result
+}
+
+// PutBucketInventoryConfigurationHandler Put bucket inventory configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketInventoryConfiguration.html
+func (s3a *S3ApiServer) PutBucketInventoryConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketInventoryConfigurationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	inventoryConfig := InventoryConfiguration{}
+	if err := xmlDecoder(r.Body, &inventoryConfig, r.ContentLength); err != nil {
+		glog.Warningf("PutBucketInventoryConfigurationHandler xml decode: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+	if id := r.URL.Query().Get("id"); id == "" || id != inventoryConfig.Id {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+
+	protoConfig, errCode := inventoryConfigToProto(&inventoryConfig)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if err := s3inventory.Validate(protoConfig); err != nil {
+		glog.Warningf("PutBucketInventoryConfigurationHandler invalid configuration: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+	destinationBucket, _ := s3inventory.ParseDestination(protoConfig.DestinationBucket)
+	if _, err := s3a.getEntry(s3a.option.BucketsPath, destinationBucket); err != nil {
+		glog.Warningf("inventory destination bucket %s: %v", destinationBucket, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+
+	if err := s3a.PutBucketInventory(bucket, protoConfig); err != nil {
+		if errors.Is(err, errTooManyInventoryConfigurations) {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+			return
+		}
+		glog.Errorf("PutBucketInventoryConfigurationHandler save inventory: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseEmpty(w, r)
+}
+
+// DeleteBucketInventoryConfigurationHandler Delete bucket inventory configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketInventoryConfiguration.html
+func (s3a *S3ApiServer) DeleteBucketInventoryConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("DeleteBucketInventoryConfigurationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if err := s3a.DeleteBucketInventory(bucket, r.URL.Query().Get("id")); err != nil {
+		if errors.Is(err, errNoSuchInventoryConfiguration) {
+			s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchConfiguration)
+			return
+		}
+		glog.Errorf("DeleteBucketInventoryConfigurationHandler clear inventory: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	s3err.WriteEmptyResponse(w, r, http.StatusNoContent)
+}
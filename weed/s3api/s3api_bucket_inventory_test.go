@@ -0,0 +1,193 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3inventory"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInventoryConfigToProto(t *testing.T) {
+	body := `<InventoryConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Destination>
+    <S3BucketDestination>
+      <AccountId>123456789012</AccountId>
+      <Bucket>arn:aws:s3:::reports</Bucket>
+      <Format>CSV</Format>
+      <Prefix>inventory</Prefix>
+    </S3BucketDestination>
+  </Destination>
+  <IsEnabled>true</IsEnabled>
+  <Filter><Prefix>logs/</Prefix></Filter>
+  <Id>audit</Id>
+  <IncludedObjectVersions>All</IncludedObjectVersions>
+  <OptionalFields><Field>Size</Field><Field>ETag</Field></OptionalFields>
+  <Schedule><Frequency>Weekly</Frequency></Schedule>
+</InventoryConfiguration>`
+	var config InventoryConfiguration
+	if err := xml.Unmarshal([]byte(body), &config); err != nil {
+		t.Fatal(err)
+	}
+	protoConfig, errCode := inventoryConfigToProto(&config)
+	if errCode != s3err.ErrNone {
+		t.Fatalf("unexpected error %v", errCode)
+	}
+	want := &s3_pb.InventoryConfiguration{
+		Id:                   "audit",
+		Enabled:              true,
+		Prefix:               "logs/",
+		DestinationBucket:    "arn:aws:s3:::reports",
+		DestinationPrefix:    "inventory",
+		DestinationAccountId: "123456789012",
+		Format:               s3inventory.FormatCSV,
+		AllVersions:          true,
+		OptionalFields:       []string{"Size", "ETag"},
+		Frequency:            s3inventory.FrequencyWeekly,
+	}
+	if !proto.Equal(protoConfig, want) {
+		t.Errorf("unexpected configuration %v", protoConfig)
+	}
+	roundTrip, errCode := inventoryConfigToProto(inventoryConfigFromProto(protoConfig))
+	if errCode != s3err.ErrNone || !proto.Equal(roundTrip, protoConfig) {
+		t.Errorf("round trip changed the configuration: %v", roundTrip)
+	}
+
+	config.IncludedObjectVersions = "Some"
+	if _, errCode := inventoryConfigToProto(&config); errCode != s3err.ErrMalformedXML {
+		t.Errorf("expected malformed xml, got %v", errCode)
+	}
+	config.IncludedObjectVersions = "Current"
+	config.Destination.S3BucketDestination.Encryption = &struct{}{}
+	if _, errCode := inventoryConfigToProto(&config); errCode != s3err.ErrNotImplemented {
+		t.Errorf("expected encrypted reports to be rejected, got %v", errCode)
+	}
+}
+
+func TestListInventoryConfigurations(t *testing.T) {
+	metadata := &BucketMetadata{Inventory: &s3_pb.InventoryConfigurations{}}
+	for i := 0; i < maxInventoryConfigurationsPerPage+5; i++ {
+		metadata.Inventory.Configurations = append(metadata.Inventory.Configurations, &s3_pb.InventoryConfiguration{Id: fmt.Sprintf("c%03d", i)})
+	}
+	first := listInventoryConfigurations(metadata, "")
+	if !first.IsTruncated || len(first.InventoryConfigurations) != maxInventoryConfigurationsPerPage || first.NextContinuationToken != "c099" {
+		t.Fatalf("unexpected first page: truncated %v, %d configurations, next %q", first.IsTruncated, len(first.InventoryConfigurations), first.NextContinuationToken)
+	}
+	second := listInventoryConfigurations(metadata, first.NextContinuationToken)
+	if second.IsTruncated || len(second.InventoryConfigurations) != 5 || second.InventoryConfigurations[0].Id != "c100" {
+		t.Errorf("unexpected second page: truncated %v, %d configurations", second.IsTruncated, len(second.InventoryConfigurations))
+	}
+}
+
+func TestInventoryWalker(t *testing.T) {
+	file := func(name string, extended map[string]string) *filer_pb.Entry {
+		entry := &filer_pb.Entry{Name: name, Attributes: &filer_pb.FuseAttributes{FileSize: 1}, Extended: map[string][]byte{}}
+		for k, v := range extended {
+			entry.Extended[k] = []byte(v)
+		}
+		return entry
+	}
+	dir := func(name string, extended map[string]string) *filer_pb.Entry {
+		entry := file(name, extended)
+		entry.IsDirectory = true
+		return entry
+	}
+	version := func(id string, deleteMarker bool) *filer_pb.Entry {
+		extended := map[string]string{s3_constants.ExtVersionIdKey: id}
+		if deleteMarker {
+			extended[s3_constants.ExtDeleteMarkerKey] = "true"
+		}
+		return file("v_"+id, extended)
+	}
+	keepDir := dir("keep", nil)
+	keepDir.Attributes.Mime = "application/octet-stream"
+
+	// the order of a breadth first traversal of /buckets/photos
+	type visit struct {
+		dir   string
+		entry *filer_pb.Entry
+	}
+	traversal := []visit{
+		{"/buckets", dir("photos", nil)},
+		{"/buckets/photos", dir(".uploads", nil)},
+		{"/buckets/photos", file("a.txt", nil)},
+		{"/buckets/photos", file("a.txt-2", nil)},
+		{"/buckets/photos", dir("a.txt.versions", map[string]string{s3_constants.ExtLatestVersionIdKey: "v3"})},
+		{"/buckets/photos", file("b.txt", nil)},
+		{"/buckets/photos", dir("b.txt.versions", nil)},
+		{"/buckets/photos", dir("docs", nil)},
+		{"/buckets/photos", keepDir},
+		{"/buckets/photos", file("z.txt", nil)},
+		{"/buckets/photos/a.txt.versions", version("v1", false)},
+		{"/buckets/photos/a.txt.versions", version("v3", true)},
+		{"/buckets/photos/b.txt.versions", version("v0", false)},
+		{"/buckets/photos/docs", file("readme", nil)},
+		{"/buckets/photos/docs", file("readme.md", nil)},
+	}
+	walk := func(prefix string, allVersions bool) []string {
+		var listed []string
+		walker := &inventoryWalker{
+			bucket:      "photos",
+			bucketDir:   "/buckets/photos",
+			prefix:      prefix,
+			allVersions: allVersions,
+			emit: func(r *s3inventory.Record) error {
+				listed = append(listed, fmt.Sprintf("%s@%s latest=%v marker=%v", r.Key, r.VersionId, r.IsLatest, r.IsDeleteMarker))
+				return nil
+			},
+		}
+		for _, v := range traversal {
+			if err := walker.visit(v.dir, v.entry); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := walker.finish(); err != nil {
+			t.Fatal(err)
+		}
+		// inventories are not sorted
+		sort.Strings(listed)
+		return listed
+	}
+
+	all := walk("", true)
+	wantAll := []string{
+		"a.txt-2@ latest=true marker=false",
+		"a.txt@null latest=false marker=false",
+		"a.txt@v1 latest=false marker=false",
+		"a.txt@v3 latest=true marker=true",
+		"b.txt@null latest=true marker=false",
+		"b.txt@v0 latest=false marker=false",
+		"docs/readme.md@ latest=true marker=false",
+		"docs/readme@ latest=true marker=false",
+		"keep/@ latest=true marker=false",
+		"z.txt@ latest=true marker=false",
+	}
+	if !reflect.DeepEqual(all, wantAll) {
+		t.Errorf("all versions:\n%v\nwant:\n%v", all, wantAll)
+	}
+
+	current := walk("", false)
+	wantCurrent := []string{
+		"a.txt-2@ latest=true marker=false",
+		"b.txt@null latest=true marker=false",
+		"docs/readme.md@ latest=true marker=false",
+		"docs/readme@ latest=true marker=false",
+		"keep/@ latest=true marker=false",
+		"z.txt@ latest=true marker=false",
+	}
+	if !reflect.DeepEqual(current, wantCurrent) {
+		t.Errorf("current versions:\n%v\nwant:\n%v", current, wantCurrent)
+	}
+
+	prefixed := walk("docs/readme.", false)
+	if !reflect.DeepEqual(prefixed, []string{"docs/readme.md@ latest=true marker=false"}) {
+		t.Errorf("prefixed: %v", prefixed)
+	}
+}
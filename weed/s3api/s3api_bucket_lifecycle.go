@@ -0,0 +1,175 @@
+package s3api
+
+import (
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+// lifecycleConfigToProto converts a lifecycle configuration from the XML model into its stored form
+func lifecycleConfigToProto(lifecycle *Lifecycle) *s3_pb.LifecycleConfiguration {
+	config := &s3_pb.LifecycleConfiguration{}
+	for _, rule := range lifecycle.Rules {
+		config.Rules = append(config.Rules, lifecycleRuleToProto(rule))
+	}
+	return config
+}
+
+func lifecycleRuleToProto(rule Rule) *s3_pb.LifecycleRule {
+	protoRule := &s3_pb.LifecycleRule{
+		Id:      rule.ID,
+		Enabled: rule.Status == Enabled,
+		Filter:  &s3_pb.LifecycleFilter{},
+	}
+
+	filter := protoRule.Filter
+	switch {
+	case rule.Filter.andSet:
+		filter.Prefix = rule.Filter.And.Prefix.val
+		filter.ObjectSizeGreaterThan = rule.Filter.And.ObjectSizeGreaterThan
+		filter.ObjectSizeLessThan = rule.Filter.And.ObjectSizeLessThan
+		for _, tag := range rule.Filter.And.Tags {
+			if filter.Tags == nil {
+				filter.Tags = make(map[string]string)
+			}
+			filter.Tags[tag.Key] = tag.Value
+		}
+	case rule.Filter.set:
+		filter.Prefix = rule.Filter.Prefix.val
+		filter.ObjectSizeGreaterThan = rule.Filter.ObjectSizeGreaterThan
+		filter.ObjectSizeLessThan = rule.Filter.ObjectSizeLessThan
+		if rule.Filter.tagSet {
+			filter.Tags = map[string]string{rule.Filter.Tag.Key: rule.Filter.Tag.Value}
+		}
+	case rule.Prefix.set:
+		filter.Prefix = rule.Prefix.val
+	}
+
+	if rule.Expiration.set {
+		protoRule.ExpirationDays = int32(rule.Expiration.Days)
+		if !rule.Expiration.Date.IsZero() {
+			protoRule.ExpirationDate = rule.Expiration.Date.Unix()
+		}
+		protoRule.ExpiredObjectDeleteMarker = rule.Expiration.DeleteMarker.val
+	}
+	for _, transition := range rule.Transitions {
+		protoTransition := &s3_pb.LifecycleTransition{
+			Days:         int32(transition.Days),
+			StorageClass: transition.StorageClass,
+		}
+		if !transition.Date.IsZero() {
+			protoTransition.Date = transition.Date.Unix()
+		}
+		protoRule.Transitions = append(protoRule.Transitions, protoTransition)
+	}
+	for _, transition := range rule.NoncurrentVersionTransitions {
+		protoRule.NoncurrentVersionTransitions = append(protoRule.NoncurrentVersionTransitions, &s3_pb.LifecycleTransition{
+			Days:                    int32(transition.NoncurrentDays),
+			NewerNoncurrentVersions: int32(transition.NewerNoncurrentVersions),
+			StorageClass:            transition.StorageClass,
+		})
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		protoRule.NoncurrentVersionExpirationDays = int32(rule.NoncurrentVersionExpiration.NoncurrentDays)
+		protoRule.NewerNoncurrentVersions = int32(rule.NoncurrentVersionExpiration.NewerNoncurrentVersions)
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		protoRule.AbortIncompleteMultipartUploadDays = int32(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+	return protoRule
+}
+
+// lifecycleConfigFromProto converts a stored lifecycle configuration back into the XML model
+func lifecycleConfigFromProto(config *s3_pb.LifecycleConfiguration) *Lifecycle {
+	lifecycle := &Lifecycle{}
+	for _, protoRule := range config.Rules {
+		lifecycle.Rules = append(lifecycle.Rules, lifecycleRuleFromProto(protoRule))
+	}
+	return lifecycle
+}
+
+func lifecycleRuleFromProto(protoRule *s3_pb.LifecycleRule) Rule {
+	rule := Rule{
+		ID:     protoRule.Id,
+		Status: Disabled,
+		Filter: Filter{set: true, Prefix: Prefix{set: true}},
+	}
+	if protoRule.Enabled {
+		rule.Status = Enabled
+	}
+
+	if filter := protoRule.Filter; filter != nil {
+		criteria := len(filter.Tags)
+		for _, present := range []bool{filter.Prefix != "", filter.ObjectSizeGreaterThan > 0, filter.ObjectSizeLessThan > 0} {
+			if present {
+				criteria++
+			}
+		}
+		if criteria > 1 {
+			rule.Filter.andSet = true
+			rule.Filter.And = And{
+				Prefix:                Prefix{set: filter.Prefix != "", val: filter.Prefix},
+				ObjectSizeGreaterThan: filter.ObjectSizeGreaterThan,
+				ObjectSizeLessThan:    filter.ObjectSizeLessThan,
+			}
+			for k, v := range filter.Tags {
+				rule.Filter.And.Tags = append(rule.Filter.And.Tags, Tag{Key: k, Value: v})
+			}
+		} else {
+			rule.Filter.Prefix.val = filter.Prefix
+			rule.Filter.ObjectSizeGreaterThan = filter.ObjectSizeGreaterThan
+			rule.Filter.ObjectSizeLessThan = filter.ObjectSizeLessThan
+			for k, v := range filter.Tags {
+				rule.Filter.Tag, rule.Filter.tagSet = Tag{Key: k, Value: v}, true
+			}
+		}
+	}
+
+	if protoRule.ExpirationDays > 0 || protoRule.ExpirationDate > 0 || protoRule.ExpiredObjectDeleteMarker {
+		rule.Expiration = Expiration{Days: int(protoRule.ExpirationDays), set: true}
+		if protoRule.ExpirationDate > 0 {
+			rule.Expiration.Date = ExpirationDate{time.Unix(protoRule.ExpirationDate, 0).UTC()}
+		}
+		if protoRule.ExpiredObjectDeleteMarker {
+			rule.Expiration.DeleteMarker = ExpireDeleteMarker{val: true, set: true}
+		}
+	}
+	for _, protoTransition := range protoRule.Transitions {
+		transition := Transition{
+			Days:         int(protoTransition.Days),
+			StorageClass: protoTransition.StorageClass,
+		}
+		if protoTransition.Date > 0 {
+			transition.Date = ExpirationDate{time.Unix(protoTransition.Date, 0).UTC()}
+		}
+		rule.Transitions = append(rule.Transitions, transition)
+	}
+	for _, protoTransition := range protoRule.NoncurrentVersionTransitions {
+		rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, NoncurrentVersionTransition{
+			NoncurrentDays:          int(protoTransition.Days),
+			NewerNoncurrentVersions: int(protoTransition.NewerNoncurrentVersions),
+			StorageClass:            protoTransition.StorageClass,
+		})
+	}
+	if protoRule.NoncurrentVersionExpirationDays > 0 {
+		rule.NoncurrentVersionExpiration = &NoncurrentVersionExpiration{
+			NoncurrentDays:          int(protoRule.NoncurrentVersionExpirationDays),
+			NewerNoncurrentVersions: int(protoRule.NewerNoncurrentVersions),
+		}
+	}
+	if protoRule.AbortIncompleteMultipartUploadDays > 0 {
+		rule.AbortIncompleteMultipartUpload = &AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: int(protoRule.AbortIncompleteMultipartUploadDays),
+		}
+	}
+	return rule
+}
+
+// isTtlCompatible returns true if the rule can also be enforced as a collection TTL,
+// i.e. it only expires current objects by age under a plain prefix.
+func isTtlCompatible(rule Rule) bool {
+	if rule.Filter.andSet || rule.Filter.tagSet || rule.Filter.ObjectSizeGreaterThan > 0 || rule.Filter.ObjectSizeLessThan > 0 {
+		return false
+	}
+	return rule.Expiration.Days > 0
+}
@@ -0,0 +1,137 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3lifecycle"
+)
+
+func TestLifecycleConfigToProto(t *testing.T) {
+	input := `<LifecycleConfiguration>
+  <Rule>
+    <ID>logs</ID>
+    <Status>Enabled</Status>
+    <Filter>
+      <And>
+        <Prefix>logs/</Prefix>
+        <Tag><Key>class</Key><Value>tmp</Value></Tag>
+        <ObjectSizeGreaterThan>1024</ObjectSizeGreaterThan>
+      </And>
+    </Filter>
+    <Expiration><Date>2030-01-01T00:00:00Z</Date></Expiration>
+    <Transition><Days>30</Days><StorageClass>STANDARD_IA</StorageClass></Transition>
+    <Transition><Date>2029-01-01T00:00:00Z</Date><StorageClass>GLACIER</StorageClass></Transition>
+    <NoncurrentVersionTransition>
+      <NoncurrentDays>7</NoncurrentDays>
+      <StorageClass>GLACIER</StorageClass>
+    </NoncurrentVersionTransition>
+    <NoncurrentVersionExpiration>
+      <NoncurrentDays>30</NoncurrentDays>
+      <NewerNoncurrentVersions>2</NewerNoncurrentVersions>
+    </NoncurrentVersionExpiration>
+  </Rule>
+  <Rule>
+    <ID>markers</ID>
+    <Status>Enabled</Status>
+    <Filter><Prefix></Prefix></Filter>
+    <Expiration><ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker></Expiration>
+    <AbortIncompleteMultipartUpload><DaysAfterInitiation>7</DaysAfterInitiation></AbortIncompleteMultipartUpload>
+  </Rule>
+  <Rule>
+    <ID>legacy</ID>
+    <Status>Disabled</Status>
+    <Prefix>tmp/</Prefix>
+    <Expiration><Days>3</Days></Expiration>
+  </Rule>
+</LifecycleConfiguration>`
+
+	var lifecycle Lifecycle
+	if err := xml.Unmarshal([]byte(input), &lifecycle); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	config := lifecycleConfigToProto(&lifecycle)
+	if err := s3lifecycle.Validate(config); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(config.Rules) != 3 {
+		t.Fatalf("expected 3 rules, got %d", len(config.Rules))
+	}
+
+	logs := config.Rules[0]
+	if !logs.Enabled || logs.Filter.Prefix != "logs/" || logs.Filter.Tags["class"] != "tmp" || logs.Filter.ObjectSizeGreaterThan != 1024 {
+		t.Errorf("unexpected filter for logs rule: %+v", logs.Filter)
+	}
+	if logs.ExpirationDate != time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("unexpected expiration date %d", logs.ExpirationDate)
+	}
+	if len(logs.Transitions) != 2 || logs.Transitions[0].Days != 30 || logs.Transitions[0].StorageClass != "STANDARD_IA" ||
+		logs.Transitions[1].Date != time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC).Unix() || logs.Transitions[1].StorageClass != "GLACIER" {
+		t.Errorf("unexpected transitions %v", logs.Transitions)
+	}
+	if len(logs.NoncurrentVersionTransitions) != 1 || logs.NoncurrentVersionTransitions[0].Days != 7 {
+		t.Errorf("unexpected noncurrent transitions %v", logs.NoncurrentVersionTransitions)
+	}
+	if logs.NoncurrentVersionExpirationDays != 30 || logs.NewerNoncurrentVersions != 2 {
+		t.Errorf("unexpected noncurrent expiration %d/%d", logs.NoncurrentVersionExpirationDays, logs.NewerNoncurrentVersions)
+	}
+
+	markers := config.Rules[1]
+	if !markers.ExpiredObjectDeleteMarker || markers.AbortIncompleteMultipartUploadDays != 7 {
+		t.Errorf("unexpected markers rule: %+v", markers)
+	}
+
+	legacy := config.Rules[2]
+	if legacy.Enabled || legacy.Filter.Prefix != "tmp/" || legacy.ExpirationDays != 3 {
+		t.Errorf("unexpected legacy rule: %+v", legacy)
+	}
+	if !isTtlCompatible(lifecycle.Rules[2]) || isTtlCompatible(lifecycle.Rules[0]) {
+		t.Errorf("only the plain prefix rule should be enforceable as a TTL")
+	}
+
+	// stored rules are returned in the same shape
+	output, err := xml.Marshal(lifecycleConfigFromProto(config))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var roundTrip Lifecycle
+	if err := xml.Unmarshal(output, &roundTrip); err != nil {
+		t.Fatalf("unmarshal round trip: %v", err)
+	}
+	again := lifecycleConfigToProto(&roundTrip)
+	for i := range config.Rules {
+		if again.Rules[i].String() != config.Rules[i].String() {
+			t.Errorf("rule %d changed after round trip:\n%v\n%v", i, config.Rules[i], again.Rules[i])
+		}
+	}
+	if !strings.Contains(string(output), "<ExpiredObjectDeleteMarker>true</ExpiredObjectDeleteMarker>") {
+		t.Errorf("expected ExpiredObjectDeleteMarker in %s", output)
+	}
+	if strings.Count(string(output), "<Transition>") != 2 || strings.Contains(string(output), "<Transition></Transition>") {
+		t.Errorf("expected two transitions in %s", output)
+	}
+}
+
+func TestCheckStorageClass(t *testing.T) {
+	for storageClass, want := range map[string]s3err.ErrorCode{
+		"":            s3err.ErrNone,
+		"STANDARD":    s3err.ErrNone,
+		"STANDARD_IA": s3err.ErrNone,
+		"GLACIER":     s3err.ErrNone,
+		"standard":    s3err.ErrInvalidStorageClass,
+		"SSD":         s3err.ErrInvalidStorageClass,
+	} {
+		header := http.Header{}
+		if storageClass != "" {
+			header.Set(s3_constants.AmzStorageClass, storageClass)
+		}
+		if got := checkStorageClass(header); got != want {
+			t.Errorf("checkStorageClass(%q) = %v, want %v", storageClass, got, want)
+		}
+	}
+}
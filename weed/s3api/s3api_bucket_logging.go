@@ -0,0 +1,127 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// BucketLogging is the XML model of a bucket logging status. Unlike the generated
+// BucketLoggingStatus it can express a disabled status, without LoggingEnabled.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+type BucketLogging struct {
+	XMLName        xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ BucketLoggingStatus"`
+	LoggingEnabled *LoggingEnabled `xml:"LoggingEnabled,omitempty"`
+}
+
+type LoggingEnabled struct {
+	TargetBucket          string                 `xml:"TargetBucket"`
+	TargetPrefix          string                 `xml:"TargetPrefix"`
+	TargetGrants          *struct{}              `xml:"TargetGrants,omitempty"`
+	TargetObjectKeyFormat *TargetObjectKeyFormat `xml:"TargetObjectKeyFormat,omitempty"`
+}
+
+// TargetObjectKeyFormat only supports the simple prefix, [prefix]yyyy-mm-DD-HH-MM-SS-[unique]
+type TargetObjectKeyFormat struct {
+	SimplePrefix      *struct{} `xml:"SimplePrefix,omitempty"`
+	PartitionedPrefix *struct{} `xml:"PartitionedPrefix,omitempty"`
+}
+
+// loggingConfigToProto converts a bucket logging status from the XML model into its stored form.
+// It returns nil if logging is disabled.
+func loggingConfigToProto(status *BucketLogging) (*s3_pb.LoggingConfiguration, s3err.ErrorCode) {
+	enabled := status.LoggingEnabled
+	if enabled == nil {
+		return nil, s3err.ErrNone
+	}
+	if enabled.TargetGrants != nil {
+		return nil, s3err.ErrNotImplemented
+	}
+	if enabled.TargetObjectKeyFormat != nil && enabled.TargetObjectKeyFormat.PartitionedPrefix != nil {
+		return nil, s3err.ErrNotImplemented
+	}
+	if enabled.TargetBucket == "" {
+		return nil, s3err.ErrInvalidTargetBucketForLogging
+	}
+	return &s3_pb.LoggingConfiguration{
+		TargetBucket: enabled.TargetBucket,
+		TargetPrefix: enabled.TargetPrefix,
+	}, s3err.ErrNone
+}
+
+// loggingConfigFromProto converts a stored logging configuration back into the XML model
+func loggingConfigFromProto(protoConfig *s3_pb.LoggingConfiguration) *BucketLogging {
+	status := &BucketLogging{}
+	if protoConfig != nil && protoConfig.TargetBucket != "" {
+		status.LoggingEnabled = &LoggingEnabled{
+			TargetBucket: protoConfig.TargetBucket,
+			TargetPrefix: protoConfig.TargetPrefix,
+		}
+	}
+	return status
+}
+
+// GetBucketLoggingHandler Get bucket logging
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketLogging.html
+func (s3a *S3ApiServer) GetBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketLoggingHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketLoggingHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseXML(w, r, loggingConfigFromProto(metadata.Logging))
+}
+
+// PutBucketLoggingHandler Put bucket logging, an empty BucketLoggingStatus disables logging
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketLogging.html
+func (s3a *S3ApiServer) PutBucketLoggingHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketLoggingHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	loggingStatus := BucketLogging{}
+	if err := xmlDecoder(r.Body, &loggingStatus, r.ContentLength); err != nil {
+		glog.Warningf("PutBucketLoggingHandler xml decode: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+
+	protoConfig, errCode := loggingConfigToProto(&loggingStatus)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if protoConfig != nil {
+		if _, err := s3a.getEntry(s3a.option.BucketsPath, protoConfig.TargetBucket); err != nil {
+			glog.Warningf("logging target bucket %s: %v", protoConfig.TargetBucket, err)
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidTargetBucketForLogging)
+			return
+		}
+	}
+
+	if err := s3a.UpdateBucketLogging(bucket, protoConfig); err != nil {
+		glog.Errorf("PutBucketLoggingHandler save logging: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseEmpty(w, r)
+}
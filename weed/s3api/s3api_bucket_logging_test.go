@@ -0,0 +1,109 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3accesslog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLoggingConfigToProto(t *testing.T) {
+	body := `<BucketLoggingStatus xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <LoggingEnabled>
+    <TargetBucket>logs</TargetBucket>
+    <TargetPrefix>photos/</TargetPrefix>
+  </LoggingEnabled>
+</BucketLoggingStatus>`
+	var status BucketLogging
+	if err := xml.Unmarshal([]byte(body), &status); err != nil {
+		t.Fatal(err)
+	}
+	protoConfig, errCode := loggingConfigToProto(&status)
+	if errCode != s3err.ErrNone {
+		t.Fatalf("unexpected error %v", errCode)
+	}
+	if !proto.Equal(protoConfig, &s3_pb.LoggingConfiguration{TargetBucket: "logs", TargetPrefix: "photos/"}) {
+		t.Errorf("unexpected configuration %v", protoConfig)
+	}
+	roundTrip, _ := loggingConfigToProto(loggingConfigFromProto(protoConfig))
+	if !proto.Equal(roundTrip, protoConfig) {
+		t.Errorf("round trip changed the configuration: %v", roundTrip)
+	}
+
+	var disabled BucketLogging
+	if err := xml.Unmarshal([]byte(`<BucketLoggingStatus xmlns="http://s3.amazonaws.com/doc/2006-03-01/"/>`), &disabled); err != nil {
+		t.Fatal(err)
+	}
+	if protoConfig, errCode := loggingConfigToProto(&disabled); protoConfig != nil || errCode != s3err.ErrNone {
+		t.Errorf("expected logging to be disabled, got %v %v", protoConfig, errCode)
+	}
+	if data, _ := xml.Marshal(loggingConfigFromProto(nil)); string(data) != `<BucketLoggingStatus xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></BucketLoggingStatus>` {
+		t.Errorf("unexpected disabled status %s", data)
+	}
+
+	status.LoggingEnabled.TargetGrants = &struct{}{}
+	if _, errCode := loggingConfigToProto(&status); errCode != s3err.ErrNotImplemented {
+		t.Errorf("expected target grants to be rejected, got %v", errCode)
+	}
+}
+
+func TestTrackAccessLog(t *testing.T) {
+	var delivered []string
+	s3a := &S3ApiServer{
+		bucketConfigCache: NewBucketConfigCache(time.Minute),
+		accessLogger: s3accesslog.NewLogger(func(target s3accesslog.Target, key string, data []byte) error {
+			delivered = append(delivered, target.Bucket+"/"+key+" "+string(data))
+			return nil
+		}, s3accesslog.MaxBatchSize),
+	}
+	s3a.bucketConfigCache.Set("photos", &BucketConfig{
+		Name:    "photos",
+		Owner:   "admin",
+		Logging: &s3_pb.LoggingConfiguration{TargetBucket: "logs", TargetPrefix: "photos/"},
+	})
+	s3a.bucketConfigCache.Set("docs", &BucketConfig{Name: "docs"})
+
+	handler := s3a.track(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			s3err.WriteErrorResponse(w, r, s3err.ErrAccessDenied)
+			return
+		}
+		w.Write([]byte("hello"))
+	}, "GET")
+	request := func(method, bucket, object string) {
+		r := httptest.NewRequest(method, "/"+bucket+"/"+object, nil)
+		r.RemoteAddr = "192.0.2.3:1234"
+		r.Header.Set(s3_constants.AmzIdentityId, "alice")
+		r = mux.SetURLVars(r, map[string]string{"bucket": bucket, "object": object})
+		handler(httptest.NewRecorder(), r)
+	}
+	request(http.MethodGet, "photos", "a.txt")
+	request(http.MethodPut, "photos", "b.txt")
+	request(http.MethodGet, "docs", "a.txt")
+	s3a.accessLogger.Flush()
+
+	if len(delivered) != 1 {
+		t.Fatalf("expected one log object, got %d", len(delivered))
+	}
+	lines := strings.Split(strings.TrimSuffix(delivered[0], "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "logs/photos/") {
+		t.Fatalf("unexpected log object %q", delivered[0])
+	}
+	get := strings.Fields(lines[0][strings.Index(lines[0], " ")+1:])
+	if get[0] != "admin" || get[1] != "photos" || get[4] != "192.0.2.3" || get[5] != "alice" || get[7] != "REST.GET.OBJECT" || get[8] != "a.txt" || get[12] != "200" || get[13] != "-" || get[14] != "5" {
+		t.Errorf("unexpected record %s", lines[0])
+	}
+	put := strings.Fields(lines[1])
+	if put[7] != "REST.PUT.OBJECT" || put[12] != "403" || put[13] != "AccessDenied" {
+		t.Errorf("unexpected record %s", lines[1])
+	}
+}
@@ -0,0 +1,231 @@
+package s3api
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3notification"
+)
+
+// BucketNotificationConfiguration is the XML model of a bucket notification configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_NotificationConfiguration.html
+type BucketNotificationConfiguration struct {
+	XMLName                     xml.Name                    `xml:"http://s3.amazonaws.com/doc/2006-03-01/ NotificationConfiguration"`
+	TopicConfigurations         []NotificationConfigElement `xml:"TopicConfiguration"`
+	QueueConfigurations         []NotificationConfigElement `xml:"QueueConfiguration"`
+	CloudFunctionConfigurations []NotificationConfigElement `xml:"CloudFunctionConfiguration"`
+}
+
+// NotificationConfigElement is a topic, queue or cloud function configuration,
+// which only differ in the element holding the destination ARN
+type NotificationConfigElement struct {
+	ID            string              `xml:"Id,omitempty"`
+	Topic         string              `xml:"Topic,omitempty"`
+	Queue         string              `xml:"Queue,omitempty"`
+	CloudFunction string              `xml:"CloudFunction,omitempty"`
+	Events        []string            `xml:"Event"`
+	Filter        *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+type NotificationFilter struct {
+	Key NotificationKeyFilter `xml:"S3Key"`
+}
+
+type NotificationKeyFilter struct {
+	FilterRules []NotificationFilterRule `xml:"FilterRule"`
+}
+
+type NotificationFilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// notificationConfigToProto converts a notification configuration from the XML model into its stored form
+func notificationConfigToProto(config *BucketNotificationConfiguration) (*s3_pb.NotificationConfiguration, s3err.ErrorCode) {
+	protoConfig := &s3_pb.NotificationConfiguration{}
+	add := func(elements []NotificationConfigElement, service string, destination func(NotificationConfigElement) string) s3err.ErrorCode {
+		for _, element := range elements {
+			rule := &s3_pb.NotificationRule{
+				Id:             element.ID,
+				DestinationArn: destination(element),
+				Events:         element.Events,
+			}
+			if rule.Id == "" {
+				rule.Id = uuid.NewString()
+			}
+			if arnService, _, err := s3notification.ParseDestination(rule.DestinationArn); err != nil || arnService != service {
+				glog.Warningf("notification configuration %q: invalid destination %q", rule.Id, rule.DestinationArn)
+				return s3err.ErrInvalidRequest
+			}
+			if element.Filter != nil {
+				for _, filterRule := range element.Filter.Key.FilterRules {
+					var value *string
+					switch strings.ToLower(filterRule.Name) {
+					case "prefix":
+						value = &rule.Prefix
+					case "suffix":
+						value = &rule.Suffix
+					default:
+						return s3err.ErrInvalidRequest
+					}
+					if *value != "" {
+						return s3err.ErrInvalidRequest
+					}
+					*value = filterRule.Value
+				}
+			}
+			protoConfig.Rules = append(protoConfig.Rules, rule)
+		}
+		return s3err.ErrNone
+	}
+
+	if errCode := add(config.TopicConfigurations, s3notification.ServiceTopic, func(e NotificationConfigElement) string { return e.Topic }); errCode != s3err.ErrNone {
+		return nil, errCode
+	}
+	if errCode := add(config.QueueConfigurations, s3notification.ServiceQueue, func(e NotificationConfigElement) string { return e.Queue }); errCode != s3err.ErrNone {
+		return nil, errCode
+	}
+	if errCode := add(config.CloudFunctionConfigurations, s3notification.ServiceFunction, func(e NotificationConfigElement) string { return e.CloudFunction }); errCode != s3err.ErrNone {
+		return nil, errCode
+	}
+	return protoConfig, s3err.ErrNone
+}
+
+// notificationConfigFromProto converts a stored notification configuration back into the XML model
+func notificationConfigFromProto(protoConfig *s3_pb.NotificationConfiguration) *BucketNotificationConfiguration {
+	config := &BucketNotificationConfiguration{}
+	for _, rule := range protoConfig.GetRules() {
+		element := NotificationConfigElement{
+			ID:     rule.Id,
+			Events: rule.Events,
+		}
+		if rule.Prefix != "" || rule.Suffix != "" {
+			element.Filter = &NotificationFilter{}
+			if rule.Prefix != "" {
+				element.Filter.Key.FilterRules = append(element.Filter.Key.FilterRules, NotificationFilterRule{Name: "prefix", Value: rule.Prefix})
+			}
+			if rule.Suffix != "" {
+				element.Filter.Key.FilterRules = append(element.Filter.Key.FilterRules, NotificationFilterRule{Name: "suffix", Value: rule.Suffix})
+			}
+		}
+		service, _, _ := s3notification.ParseDestination(rule.DestinationArn)
+		switch service {
+		case s3notification.ServiceTopic:
+			element.Topic = rule.DestinationArn
+			config.TopicConfigurations = append(config.TopicConfigurations, element)
+		case s3notification.ServiceQueue:
+			element.Queue = rule.DestinationArn
+			config.QueueConfigurations = append(config.QueueConfigurations, element)
+		case s3notification.ServiceFunction:
+			element.CloudFunction = rule.DestinationArn
+			config.CloudFunctionConfigurations = append(config.CloudFunctionConfigurations, element)
+		}
+	}
+	return config
+}
+
+// GetBucketNotificationHandler Get bucket notification configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketNotificationConfiguration.html
+func (s3a *S3ApiServer) GetBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketNotificationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketNotificationHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	// a bucket without notification configuration has an empty one
+	writeSuccessResponseXML(w, r, notificationConfigFromProto(metadata.Notification))
+}
+
+// PutBucketNotificationHandler Put bucket notification configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketNotificationConfiguration.html
+func (s3a *S3ApiServer) PutBucketNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketNotificationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	notificationConfig := BucketNotificationConfiguration{}
+	if err := xmlDecoder(r.Body, &notificationConfig, r.ContentLength); err != nil {
+		glog.Warningf("PutBucketNotificationHandler xml decode: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+
+	protoConfig, errCode := notificationConfigToProto(&notificationConfig)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	// an empty configuration turns off the notifications
+	if len(protoConfig.Rules) == 0 {
+		if err := s3a.ClearBucketNotification(bucket); err != nil {
+			glog.Errorf("PutBucketNotificationHandler clear notification: %s", err)
+			s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+			return
+		}
+		writeSuccessResponseEmpty(w, r)
+		return
+	}
+
+	if err := s3notification.Validate(protoConfig); err != nil {
+		glog.Warningf("PutBucketNotificationHandler invalid configuration: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+	if errCode := s3a.sendNotificationTestEvents(bucket, protoConfig); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	if err := s3a.UpdateBucketNotification(bucket, protoConfig); err != nil {
+		glog.Errorf("PutBucketNotificationHandler save notification: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseEmpty(w, r)
+}
+
+// sendNotificationTestEvents validates the destinations of the configuration by
+// sending them an s3:TestEvent message, as S3 does
+func (s3a *S3ApiServer) sendNotificationTestEvents(bucket string, config *s3_pb.NotificationConfiguration) s3err.ErrorCode {
+	body, err := json.Marshal(s3notification.NewTestMessage(bucket, time.Now()))
+	if err != nil {
+		return s3err.ErrInternalError
+	}
+	for _, rule := range config.Rules {
+		_, name, _ := s3notification.ParseDestination(rule.DestinationArn)
+		queue, found := s3a.notificationTargets[name]
+		if !found {
+			glog.Warningf("notification target %q is not configured", name)
+			return s3err.ErrInvalidRequest
+		}
+		if err := queue.SendRawMessage(bucket, body); err != nil {
+			glog.Warningf("notification target %q: send test event: %v", name, err)
+			return s3err.ErrInvalidRequest
+		}
+	}
+	return s3err.ErrNone
+}
@@ -0,0 +1,93 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3notification"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNotificationConfigToProto(t *testing.T) {
+	body := `<NotificationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <QueueConfiguration>
+    <Id>images</Id>
+    <Queue>arn:aws:sqs:us-east-1:123456789012:uploads</Queue>
+    <Event>s3:ObjectCreated:*</Event>
+    <Filter><S3Key>
+      <FilterRule><Name>Prefix</Name><Value>images/</Value></FilterRule>
+      <FilterRule><Name>suffix</Name><Value>.jpg</Value></FilterRule>
+    </S3Key></Filter>
+  </QueueConfiguration>
+  <CloudFunctionConfiguration>
+    <Id>cleanup</Id>
+    <CloudFunction>arn:aws:lambda:us-east-1:123456789012:function:cleanup</CloudFunction>
+    <Event>s3:ObjectRemoved:*</Event>
+  </CloudFunctionConfiguration>
+</NotificationConfiguration>`
+	var config BucketNotificationConfiguration
+	if err := xml.Unmarshal([]byte(body), &config); err != nil {
+		t.Fatal(err)
+	}
+	protoConfig, errCode := notificationConfigToProto(&config)
+	if errCode != s3err.ErrNone {
+		t.Fatalf("unexpected error %v", errCode)
+	}
+	if len(protoConfig.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(protoConfig.Rules))
+	}
+	if images := protoConfig.Rules[0]; images.Id != "images" || images.Prefix != "images/" || images.Suffix != ".jpg" {
+		t.Errorf("unexpected rule %v", images)
+	}
+
+	roundTrip, errCode := notificationConfigToProto(notificationConfigFromProto(protoConfig))
+	if errCode != s3err.ErrNone {
+		t.Fatalf("unexpected error %v", errCode)
+	}
+	if !proto.Equal(roundTrip, protoConfig) {
+		t.Errorf("round trip changed the configuration:\n%v\n%v", roundTrip, protoConfig)
+	}
+
+	config.QueueConfigurations[0].Queue = "arn:aws:sns:us-east-1:123456789012:uploads"
+	if _, errCode := notificationConfigToProto(&config); errCode != s3err.ErrInvalidRequest {
+		t.Errorf("a topic ARN in a queue configuration should be rejected, got %v", errCode)
+	}
+}
+
+func TestDetectObjectEvent(t *testing.T) {
+	object := func(etag string, mtime int64, extended map[string][]byte) *filer_pb.Entry {
+		return &filer_pb.Entry{
+			Name:       "a.txt",
+			Attributes: &filer_pb.FuseAttributes{Md5: []byte(etag), Mtime: mtime},
+			Extended:   extended,
+		}
+	}
+	tagged := map[string][]byte{S3TAG_PREFIX + "k": []byte("v")}
+	versioned := map[string][]byte{s3_constants.ExtVersionIdKey: []byte("1")}
+
+	tests := []struct {
+		name      string
+		message   *filer_pb.EventNotification
+		isVersion bool
+		want      string
+	}{
+		{"put", &filer_pb.EventNotification{NewEntry: object("a", 1, nil)}, false, s3notification.ObjectCreatedPut},
+		{"overwrite", &filer_pb.EventNotification{OldEntry: object("a", 1, nil), NewEntry: object("b", 2, nil)}, false, s3notification.ObjectCreatedPut},
+		{"multipart", &filer_pb.EventNotification{NewEntry: object("a", 1, map[string][]byte{s3_constants.SeaweedFSUploadId: []byte("u")})}, false, s3notification.ObjectCreatedCompleteMultipartUpload},
+		{"delete", &filer_pb.EventNotification{OldEntry: object("a", 1, nil)}, false, s3notification.ObjectRemovedDelete},
+		{"tagging", &filer_pb.EventNotification{OldEntry: object("a", 1, nil), NewEntry: object("a", 1, tagged)}, false, s3notification.ObjectTaggingPut},
+		{"untagging", &filer_pb.EventNotification{OldEntry: object("a", 1, tagged), NewEntry: object("a", 1, nil)}, false, s3notification.ObjectTaggingDelete},
+		{"acl", &filer_pb.EventNotification{OldEntry: object("a", 1, tagged), NewEntry: object("a", 1, tagged)}, false, ""},
+		{"version upload", &filer_pb.EventNotification{NewEntry: object("a", 1, nil)}, true, ""},
+		{"version id", &filer_pb.EventNotification{OldEntry: object("a", 1, nil), NewEntry: object("a", 1, versioned)}, true, s3notification.ObjectCreatedPut},
+		{"delete marker", &filer_pb.EventNotification{NewEntry: object("", 1, map[string][]byte{s3_constants.ExtDeleteMarkerKey: []byte("true")})}, true, s3notification.ObjectRemovedDeleteMarkerCreated},
+	}
+	for _, tt := range tests {
+		if got := detectObjectEvent(tt.message, tt.isVersion); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3replication"
+)
+
+// ReplicationConfiguration is the XML model of a bucket replication configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ReplicationConfiguration.html
+type ReplicationConfiguration struct {
+	XMLName xml.Name          `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ReplicationConfiguration"`
+	Role    string            `xml:"Role,omitempty"`
+	Rules   []ReplicationRule `xml:"Rule"`
+}
+
+type ReplicationRule struct {
+	ID                      string                   `xml:"ID,omitempty"`
+	Priority                int32                    `xml:"Priority,omitempty"`
+	Status                  ruleStatus               `xml:"Status"`
+	Prefix                  *string                  `xml:"Prefix,omitempty"`
+	Filter                  *ReplicationRuleFilter   `xml:"Filter,omitempty"`
+	DeleteMarkerReplication *DeleteMarkerReplication `xml:"DeleteMarkerReplication,omitempty"`
+	Destination             ReplicationDestination   `xml:"Destination"`
+}
+
+type ReplicationRuleFilter struct {
+	Prefix *string                 `xml:"Prefix,omitempty"`
+	Tag    *Tag                    `xml:"Tag,omitempty"`
+	And    *ReplicationRuleAndTags `xml:"And,omitempty"`
+}
+
+type ReplicationRuleAndTags struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag"`
+}
+
+type DeleteMarkerReplication struct {
+	Status ruleStatus `xml:"Status"`
+}
+
+type ReplicationDestination struct {
+	Bucket       string `xml:"Bucket"`
+	StorageClass string `xml:"StorageClass,omitempty"`
+}
+
+// replicationConfigToProto converts a replication configuration from the XML model into its stored form
+func replicationConfigToProto(config *ReplicationConfiguration) (*s3_pb.ReplicationConfiguration, s3err.ErrorCode) {
+	protoConfig := &s3_pb.ReplicationConfiguration{Role: config.Role}
+	for _, rule := range config.Rules {
+		protoRule := &s3_pb.ReplicationRule{
+			Id:                      rule.ID,
+			Priority:                rule.Priority,
+			Filter:                  &s3_pb.ReplicationFilter{},
+			DestinationBucket:       rule.Destination.Bucket,
+			DestinationStorageClass: rule.Destination.StorageClass,
+		}
+		switch rule.Status {
+		case Enabled:
+			protoRule.Enabled = true
+		case Disabled:
+		default:
+			return nil, s3err.ErrMalformedXML
+		}
+		if rule.DeleteMarkerReplication != nil {
+			switch rule.DeleteMarkerReplication.Status {
+			case Enabled:
+				protoRule.DeleteMarkerReplication = true
+			case Disabled:
+			default:
+				return nil, s3err.ErrMalformedXML
+			}
+		}
+
+		switch {
+		case rule.Filter != nil && rule.Prefix != nil:
+			return nil, s3err.ErrMalformedXML
+		case rule.Filter != nil:
+			filter := rule.Filter
+			if filter.And != nil {
+				if filter.Prefix != nil || filter.Tag != nil {
+					return nil, s3err.ErrMalformedXML
+				}
+				protoRule.Filter.Prefix = filter.And.Prefix
+				for _, tag := range filter.And.Tags {
+					if protoRule.Filter.Tags == nil {
+						protoRule.Filter.Tags = make(map[string]string)
+					}
+					protoRule.Filter.Tags[tag.Key] = tag.Value
+				}
+				break
+			}
+			if filter.Prefix != nil && filter.Tag != nil {
+				return nil, s3err.ErrMalformedXML
+			}
+			if filter.Prefix != nil {
+				protoRule.Filter.Prefix = *filter.Prefix
+			}
+			if filter.Tag != nil {
+				protoRule.Filter.Tags = map[string]string{filter.Tag.Key: filter.Tag.Value}
+			}
+		case rule.Prefix != nil:
+			protoRule.Filter.Prefix = *rule.Prefix
+		}
+		protoConfig.Rules = append(protoConfig.Rules, protoRule)
+	}
+	return protoConfig, s3err.ErrNone
+}
+
+// replicationConfigFromProto converts a stored replication configuration back into the XML model
+func replicationConfigFromProto(protoConfig *s3_pb.ReplicationConfiguration) *ReplicationConfiguration {
+	config := &ReplicationConfiguration{Role: protoConfig.Role}
+	for _, protoRule := range protoConfig.Rules {
+		rule := ReplicationRule{
+			ID:                      protoRule.Id,
+			Priority:                protoRule.Priority,
+			Status:                  Disabled,
+			Filter:                  &ReplicationRuleFilter{},
+			DeleteMarkerReplication: &DeleteMarkerReplication{Status: Disabled},
+			Destination: ReplicationDestination{
+				Bucket:       protoRule.DestinationBucket,
+				StorageClass: protoRule.DestinationStorageClass,
+			},
+		}
+		if protoRule.Enabled {
+			rule.Status = Enabled
+		}
+		if protoRule.DeleteMarkerReplication {
+			rule.DeleteMarkerReplication.Status = Enabled
+		}
+		if filter := protoRule.Filter; filter != nil {
+			switch {
+			case len(filter.Tags) > 1 || len(filter.Tags) == 1 && filter.Prefix != "":
+				rule.Filter.And = &ReplicationRuleAndTags{Prefix: filter.Prefix}
+				for k, v := range filter.Tags {
+					rule.Filter.And.Tags = append(rule.Filter.And.Tags, Tag{Key: k, Value: v})
+				}
+			case len(filter.Tags) == 1:
+				for k, v := range filter.Tags {
+					rule.Filter.Tag = &Tag{Key: k, Value: v}
+				}
+			default:
+				prefix := filter.Prefix
+				rule.Filter.Prefix = &prefix
+			}
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+	return config
+}
+
+// GetBucketReplicationHandler Get bucket replication configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketReplication.html
+func (s3a *S3ApiServer) GetBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketReplicationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketReplicationHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if !metadata.HasReplication() {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchReplicationConfiguration)
+		return
+	}
+
+	writeSuccessResponseXML(w, r, replicationConfigFromProto(metadata.Replication))
+}
+
+// PutBucketReplicationHandler Put bucket replication configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketReplication.html
+func (s3a *S3ApiServer) PutBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketReplicationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	replicationConfig := ReplicationConfiguration{}
+	if err := xmlDecoder(r.Body, &replicationConfig, r.ContentLength); err != nil {
+		glog.Warningf("PutBucketReplicationHandler xml decode: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+
+	protoConfig, errCode := replicationConfigToProto(&replicationConfig)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if err := s3replication.Validate(protoConfig, bucket); err != nil {
+		glog.Warningf("PutBucketReplicationHandler invalid configuration: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+	if errCode := s3a.checkReplicationDestinations(protoConfig); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	if err := s3a.UpdateBucketReplication(bucket, protoConfig); err != nil {
+		glog.Errorf("PutBucketReplicationHandler save replication: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseEmpty(w, r)
+}
+
+// DeleteBucketReplicationHandler Delete bucket replication configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketReplication.html
+func (s3a *S3ApiServer) DeleteBucketReplicationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("DeleteBucketReplicationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if err := s3a.ClearBucketReplication(bucket); err != nil {
+		glog.Errorf("DeleteBucketReplicationHandler clear replication: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	s3err.WriteEmptyResponse(w, r, http.StatusNoContent)
+}
+
+// checkReplicationDestinations makes sure every destination can be replicated to,
+// i.e. its target is configured and a local destination bucket exists
+func (s3a *S3ApiServer) checkReplicationDestinations(config *s3_pb.ReplicationConfiguration) s3err.ErrorCode {
+	for _, rule := range config.Rules {
+		destination, _ := s3replication.ParseDestination(rule.DestinationBucket)
+		if destination.Target != "" {
+			if _, found := s3a.replicationTargets[destination.Target]; !found {
+				glog.Warningf("replication target %q is not configured", destination.Target)
+				return s3err.ErrInvalidRequest
+			}
+			continue
+		}
+		if _, err := s3a.getEntry(s3a.option.BucketsPath, destination.Bucket); err != nil {
+			glog.Warningf("replication destination bucket %s: %v", destination.Bucket, err)
+			return s3err.ErrInvalidRequest
+		}
+	}
+	return s3err.ErrNone
+}
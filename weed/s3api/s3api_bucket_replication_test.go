@@ -0,0 +1,96 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/replication/sink/filersink"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestReplicationConfigToProto(t *testing.T) {
+	body := `<ReplicationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Role>arn:aws:iam::123456789012:role/replication</Role>
+  <Rule>
+    <ID>v1</ID>
+    <Status>Enabled</Status>
+    <Prefix>logs/</Prefix>
+    <Destination><Bucket>arn:aws:s3:::backup</Bucket></Destination>
+  </Rule>
+  <Rule>
+    <ID>tagged</ID>
+    <Priority>2</Priority>
+    <Status>Enabled</Status>
+    <Filter><And><Prefix>data/</Prefix><Tag><Key>dr</Key><Value>yes</Value></Tag><Tag><Key>env</Key><Value>prod</Value></Tag></And></Filter>
+    <DeleteMarkerReplication><Status>Enabled</Status></DeleteMarkerReplication>
+    <Destination><Bucket>arn:aws:s3:dr::backup</Bucket><StorageClass>STANDARD_IA</StorageClass></Destination>
+  </Rule>
+</ReplicationConfiguration>`
+	var config ReplicationConfiguration
+	if err := xml.Unmarshal([]byte(body), &config); err != nil {
+		t.Fatal(err)
+	}
+	protoConfig, errCode := replicationConfigToProto(&config)
+	if errCode != s3err.ErrNone {
+		t.Fatalf("unexpected error %v", errCode)
+	}
+	if len(protoConfig.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(protoConfig.Rules))
+	}
+	v1, tagged := protoConfig.Rules[0], protoConfig.Rules[1]
+	if !v1.Enabled || v1.Filter.Prefix != "logs/" || v1.DeleteMarkerReplication {
+		t.Errorf("unexpected rule %v", v1)
+	}
+	if tagged.Priority != 2 || tagged.Filter.Prefix != "data/" || len(tagged.Filter.Tags) != 2 || tagged.Filter.Tags["env"] != "prod" ||
+		!tagged.DeleteMarkerReplication || tagged.DestinationStorageClass != "STANDARD_IA" {
+		t.Errorf("unexpected rule %v", tagged)
+	}
+
+	roundTrip, errCode := replicationConfigToProto(replicationConfigFromProto(protoConfig))
+	if errCode != s3err.ErrNone {
+		t.Fatalf("unexpected error %v", errCode)
+	}
+	if !proto.Equal(roundTrip, protoConfig) {
+		t.Errorf("round trip changed the configuration:\n%v\n%v", roundTrip, protoConfig)
+	}
+
+	config.Rules[1].Filter.Prefix = &config.Rules[1].Filter.And.Prefix
+	if _, errCode := replicationConfigToProto(&config); errCode != s3err.ErrMalformedXML {
+		t.Errorf("a filter with both And and Prefix should be rejected, got %v", errCode)
+	}
+	config.Rules[1].Filter.Prefix = nil
+	config.Rules[0].Status = "On"
+	if _, errCode := replicationConfigToProto(&config); errCode != s3err.ErrMalformedXML {
+		t.Errorf("an invalid status should be rejected, got %v", errCode)
+	}
+}
+
+func TestReplicaEntry(t *testing.T) {
+	entry := &filer_pb.Entry{
+		Name: "v_1",
+		Extended: map[string][]byte{
+			s3_constants.ExtVersionIdKey:      []byte("1"),
+			s3_constants.AmzReplicationStatus: []byte(s3_constants.ReplicationStatusPending),
+			S3TAG_PREFIX + "dr":               []byte("yes"),
+		},
+	}
+	rule := &s3_pb.ReplicationRule{DestinationStorageClass: "STANDARD_IA"}
+	replica := replicaEntry(entry, rule, &filersink.FilerSink{})
+
+	if _, found := replica.Extended[s3_constants.ExtVersionIdKey]; found {
+		t.Errorf("the version id should not be replicated")
+	}
+	if !isReplica(replica) || isReplica(entry) {
+		t.Errorf("only the copy should be marked as a replica")
+	}
+	if string(replica.Extended[s3_constants.AmzStorageClass]) != "STANDARD_IA" {
+		t.Errorf("unexpected storage class %q", replica.Extended[s3_constants.AmzStorageClass])
+	}
+	if tags := objectTags(replica); len(tags) != 1 || tags["dr"] != "yes" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
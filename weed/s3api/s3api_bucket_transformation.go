@@ -0,0 +1,173 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3transform"
+)
+
+// TransformationConfiguration is the XML model of the transformations of GET responses of a bucket,
+// set with PUT /{bucket}?transformation, e.g.
+//
+//	<TransformationConfiguration>
+//	  <Rule>
+//	    <ID>thumbnails</ID>
+//	    <Prefix>photos/</Prefix>
+//	    <AccessPoint>thumbs</AccessPoint>
+//	    <Step><Type>resize</Type><Parameter><Name>width</Name><Value>200</Value></Parameter></Step>
+//	  </Rule>
+//	</TransformationConfiguration>
+type TransformationConfiguration struct {
+	XMLName xml.Name             `xml:"TransformationConfiguration"`
+	Rules   []TransformationRule `xml:"Rule"`
+}
+
+type TransformationRule struct {
+	ID          string               `xml:"ID"`
+	Prefix      string               `xml:"Prefix"`
+	AccessPoint string               `xml:"AccessPoint,omitempty"`
+	Steps       []TransformationStep `xml:"Step"`
+}
+
+type TransformationStep struct {
+	Type       string                    `xml:"Type"`
+	Parameters []TransformationParameter `xml:"Parameter"`
+}
+
+type TransformationParameter struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// transformationConfigToProto converts a transformation configuration from the XML model into its stored form
+func transformationConfigToProto(config *TransformationConfiguration) *s3_pb.TransformationConfiguration {
+	protoConfig := &s3_pb.TransformationConfiguration{}
+	for _, rule := range config.Rules {
+		protoRule := &s3_pb.TransformationRule{
+			Id:          rule.ID,
+			Prefix:      rule.Prefix,
+			AccessPoint: rule.AccessPoint,
+		}
+		for _, step := range rule.Steps {
+			protoStep := &s3_pb.TransformationStep{Type: step.Type, Parameters: make(map[string]string)}
+			for _, parameter := range step.Parameters {
+				protoStep.Parameters[parameter.Name] = parameter.Value
+			}
+			protoRule.Steps = append(protoRule.Steps, protoStep)
+		}
+		protoConfig.Rules = append(protoConfig.Rules, protoRule)
+	}
+	return protoConfig
+}
+
+// transformationConfigFromProto converts a stored transformation configuration back into the XML model
+func transformationConfigFromProto(protoConfig *s3_pb.TransformationConfiguration) *TransformationConfiguration {
+	config := &TransformationConfiguration{}
+	for _, protoRule := range protoConfig.Rules {
+		rule := TransformationRule{
+			ID:          protoRule.Id,
+			Prefix:      protoRule.Prefix,
+			AccessPoint: protoRule.AccessPoint,
+		}
+		for _, protoStep := range protoRule.Steps {
+			step := TransformationStep{Type: protoStep.Type}
+			for name, value := range protoStep.Parameters {
+				step.Parameters = append(step.Parameters, TransformationParameter{Name: name, Value: value})
+			}
+			sort.Slice(step.Parameters, func(i, j int) bool {
+				return step.Parameters[i].Name < step.Parameters[j].Name
+			})
+			rule.Steps = append(rule.Steps, step)
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+	return config
+}
+
+// GetBucketTransformationHandler Get the transformations of GET responses of a bucket
+func (s3a *S3ApiServer) GetBucketTransformationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketTransformationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketTransformationHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if !metadata.HasTransformation() {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchTransformationConfiguration)
+		return
+	}
+
+	writeSuccessResponseXML(w, r, transformationConfigFromProto(metadata.Transformation))
+}
+
+// PutBucketTransformationHandler Put the transformations of GET responses of a bucket
+func (s3a *S3ApiServer) PutBucketTransformationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketTransformationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	transformationConfig := TransformationConfiguration{}
+	if err := xmlDecoder(r.Body, &transformationConfig, r.ContentLength); err != nil {
+		glog.Warningf("PutBucketTransformationHandler xml decode: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+
+	protoConfig := transformationConfigToProto(&transformationConfig)
+	// http steps make the gateway post to other hosts, so only admins may configure them
+	if s3transform.HasHttpStep(protoConfig) && s3a.iam.isEnabled() && !s3a.isUserAdmin(r) {
+		glog.Warningf("PutBucketTransformationHandler %s: http steps require an admin", bucket)
+		s3err.WriteErrorResponse(w, r, s3err.ErrAccessDenied)
+		return
+	}
+	if err := s3transform.Validate(protoConfig, s3a.transformOptions()); err != nil {
+		glog.Warningf("PutBucketTransformationHandler invalid configuration: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+
+	if err := s3a.UpdateBucketTransformation(bucket, protoConfig); err != nil {
+		glog.Errorf("PutBucketTransformationHandler save transformation: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseEmpty(w, r)
+}
+
+// DeleteBucketTransformationHandler Delete the transformations of GET responses of a bucket
+func (s3a *S3ApiServer) DeleteBucketTransformationHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("DeleteBucketTransformationHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if err := s3a.UpdateBucketTransformation(bucket, nil); err != nil {
+		glog.Errorf("DeleteBucketTransformationHandler clear transformation: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	s3err.WriteEmptyResponse(w, r, http.StatusNoContent)
+}
@@ -0,0 +1,211 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3website"
+)
+
+// WebsiteConfiguration is the XML model of a bucket website configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_WebsiteConfiguration.html
+type WebsiteConfiguration struct {
+	XMLName               xml.Name                      `xml:"http://s3.amazonaws.com/doc/2006-03-01/ WebsiteConfiguration"`
+	ErrorDocument         *WebsiteErrorDocument         `xml:"ErrorDocument,omitempty"`
+	IndexDocument         *WebsiteIndexDocument         `xml:"IndexDocument,omitempty"`
+	RedirectAllRequestsTo *WebsiteRedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	RoutingRules          *WebsiteRoutingRules          `xml:"RoutingRules,omitempty"`
+}
+
+type WebsiteErrorDocument struct {
+	Key string `xml:"Key"`
+}
+
+type WebsiteIndexDocument struct {
+	Suffix string `xml:"Suffix"`
+}
+
+type WebsiteRedirectAllRequestsTo struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+type WebsiteRoutingRules struct {
+	RoutingRules []WebsiteRoutingRule `xml:"RoutingRule"`
+}
+
+type WebsiteRoutingRule struct {
+	Condition *WebsiteCondition `xml:"Condition,omitempty"`
+	Redirect  WebsiteRedirect   `xml:"Redirect"`
+}
+
+type WebsiteCondition struct {
+	HttpErrorCodeReturnedEquals string `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty"`
+}
+
+type WebsiteRedirect struct {
+	HostName             string `xml:"HostName,omitempty"`
+	HttpRedirectCode     string `xml:"HttpRedirectCode,omitempty"`
+	Protocol             string `xml:"Protocol,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+}
+
+// websiteConfigToProto converts a website configuration from the XML model into its stored form
+func websiteConfigToProto(config *WebsiteConfiguration) *s3_pb.WebsiteConfiguration {
+	protoConfig := &s3_pb.WebsiteConfiguration{}
+	if config.IndexDocument != nil {
+		protoConfig.IndexDocumentSuffix = config.IndexDocument.Suffix
+	}
+	if config.ErrorDocument != nil {
+		protoConfig.ErrorDocumentKey = config.ErrorDocument.Key
+	}
+	if config.RedirectAllRequestsTo != nil {
+		protoConfig.RedirectAllRequestsTo = &s3_pb.WebsiteRedirect{
+			HostName: config.RedirectAllRequestsTo.HostName,
+			Protocol: config.RedirectAllRequestsTo.Protocol,
+		}
+	}
+	if config.RoutingRules != nil {
+		for _, rule := range config.RoutingRules.RoutingRules {
+			protoRule := &s3_pb.WebsiteRoutingRule{
+				Redirect: &s3_pb.WebsiteRedirect{
+					HostName:             rule.Redirect.HostName,
+					Protocol:             rule.Redirect.Protocol,
+					ReplaceKeyPrefixWith: rule.Redirect.ReplaceKeyPrefixWith,
+					ReplaceKeyWith:       rule.Redirect.ReplaceKeyWith,
+					HttpRedirectCode:     rule.Redirect.HttpRedirectCode,
+				},
+			}
+			if rule.Condition != nil {
+				protoRule.KeyPrefixEquals = rule.Condition.KeyPrefixEquals
+				protoRule.HttpErrorCodeReturnedEquals = rule.Condition.HttpErrorCodeReturnedEquals
+			}
+			protoConfig.RoutingRules = append(protoConfig.RoutingRules, protoRule)
+		}
+	}
+	return protoConfig
+}
+
+// websiteConfigFromProto converts a stored website configuration back into the XML model
+func websiteConfigFromProto(protoConfig *s3_pb.WebsiteConfiguration) *WebsiteConfiguration {
+	config := &WebsiteConfiguration{}
+	if protoConfig.IndexDocumentSuffix != "" {
+		config.IndexDocument = &WebsiteIndexDocument{Suffix: protoConfig.IndexDocumentSuffix}
+	}
+	if protoConfig.ErrorDocumentKey != "" {
+		config.ErrorDocument = &WebsiteErrorDocument{Key: protoConfig.ErrorDocumentKey}
+	}
+	if redirect := protoConfig.RedirectAllRequestsTo; redirect != nil {
+		config.RedirectAllRequestsTo = &WebsiteRedirectAllRequestsTo{
+			HostName: redirect.HostName,
+			Protocol: redirect.Protocol,
+		}
+	}
+	if len(protoConfig.RoutingRules) > 0 {
+		config.RoutingRules = &WebsiteRoutingRules{}
+		for _, protoRule := range protoConfig.RoutingRules {
+			rule := WebsiteRoutingRule{
+				Redirect: WebsiteRedirect{
+					HostName:             protoRule.Redirect.GetHostName(),
+					HttpRedirectCode:     protoRule.Redirect.GetHttpRedirectCode(),
+					Protocol:             protoRule.Redirect.GetProtocol(),
+					ReplaceKeyPrefixWith: protoRule.Redirect.GetReplaceKeyPrefixWith(),
+					ReplaceKeyWith:       protoRule.Redirect.GetReplaceKeyWith(),
+				},
+			}
+			if protoRule.KeyPrefixEquals != "" || protoRule.HttpErrorCodeReturnedEquals != "" {
+				rule.Condition = &WebsiteCondition{
+					HttpErrorCodeReturnedEquals: protoRule.HttpErrorCodeReturnedEquals,
+					KeyPrefixEquals:             protoRule.KeyPrefixEquals,
+				}
+			}
+			config.RoutingRules.RoutingRules = append(config.RoutingRules.RoutingRules, rule)
+		}
+	}
+	return config
+}
+
+// GetBucketWebsiteHandler Get bucket website configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketWebsite.html
+func (s3a *S3ApiServer) GetBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketWebsiteHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.Errorf("GetBucketWebsiteHandler read bucket metadata: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if !metadata.HasWebsite() {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchWebsiteConfiguration)
+		return
+	}
+
+	writeSuccessResponseXML(w, r, websiteConfigFromProto(metadata.Website))
+}
+
+// PutBucketWebsiteHandler Put bucket website configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
+func (s3a *S3ApiServer) PutBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketWebsiteHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	websiteConfig := WebsiteConfiguration{}
+	if err := xmlDecoder(r.Body, &websiteConfig, r.ContentLength); err != nil {
+		glog.Warningf("PutBucketWebsiteHandler xml decode: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+
+	protoConfig := websiteConfigToProto(&websiteConfig)
+	if err := s3website.Validate(protoConfig); err != nil {
+		glog.Warningf("PutBucketWebsiteHandler invalid configuration: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+
+	if err := s3a.UpdateBucketWebsite(bucket, protoConfig); err != nil {
+		glog.Errorf("PutBucketWebsiteHandler save website: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	writeSuccessResponseEmpty(w, r)
+}
+
+// DeleteBucketWebsiteHandler Delete bucket website configuration
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketWebsite.html
+func (s3a *S3ApiServer) DeleteBucketWebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("DeleteBucketWebsiteHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	if err := s3a.UpdateBucketWebsite(bucket, nil); err != nil {
+		glog.Errorf("DeleteBucketWebsiteHandler clear website: %s", err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	s3err.WriteEmptyResponse(w, r, http.StatusNoContent)
+}
@@ -0,0 +1,111 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWebsiteConfigToProto(t *testing.T) {
+	body := `<WebsiteConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <IndexDocument><Suffix>index.html</Suffix></IndexDocument>
+  <ErrorDocument><Key>error.html</Key></ErrorDocument>
+  <RoutingRules>
+    <RoutingRule>
+      <Condition><KeyPrefixEquals>docs/</KeyPrefixEquals></Condition>
+      <Redirect><ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith></Redirect>
+    </RoutingRule>
+    <RoutingRule>
+      <Condition><HttpErrorCodeReturnedEquals>404</HttpErrorCodeReturnedEquals></Condition>
+      <Redirect><HostName>example.com</HostName><HttpRedirectCode>302</HttpRedirectCode></Redirect>
+    </RoutingRule>
+  </RoutingRules>
+</WebsiteConfiguration>`
+	var config WebsiteConfiguration
+	if err := xml.Unmarshal([]byte(body), &config); err != nil {
+		t.Fatal(err)
+	}
+	protoConfig := websiteConfigToProto(&config)
+	want := &s3_pb.WebsiteConfiguration{
+		IndexDocumentSuffix: "index.html",
+		ErrorDocumentKey:    "error.html",
+		RoutingRules: []*s3_pb.WebsiteRoutingRule{
+			{KeyPrefixEquals: "docs/", Redirect: &s3_pb.WebsiteRedirect{ReplaceKeyPrefixWith: "documents/"}},
+			{HttpErrorCodeReturnedEquals: "404", Redirect: &s3_pb.WebsiteRedirect{HostName: "example.com", HttpRedirectCode: "302"}},
+		},
+	}
+	if !proto.Equal(protoConfig, want) {
+		t.Errorf("unexpected configuration %v", protoConfig)
+	}
+	if roundTrip := websiteConfigToProto(websiteConfigFromProto(protoConfig)); !proto.Equal(roundTrip, protoConfig) {
+		t.Errorf("round trip changed the configuration: %v", roundTrip)
+	}
+}
+
+func TestWebsiteHandlerRedirects(t *testing.T) {
+	s3a := &S3ApiServer{bucketConfigCache: NewBucketConfigCache(time.Minute)}
+	s3a.bucketConfigCache.Set("old", &BucketConfig{
+		Name:    "old",
+		Website: &s3_pb.WebsiteConfiguration{RedirectAllRequestsTo: &s3_pb.WebsiteRedirect{HostName: "www.example.com", Protocol: "https"}},
+	})
+	s3a.bucketConfigCache.Set("site", &BucketConfig{
+		Name: "site",
+		Website: &s3_pb.WebsiteConfiguration{
+			IndexDocumentSuffix: "index.html",
+			RoutingRules: []*s3_pb.WebsiteRoutingRule{
+				{KeyPrefixEquals: "docs/", Redirect: &s3_pb.WebsiteRedirect{ReplaceKeyPrefixWith: "documents/", HttpRedirectCode: "307"}},
+			},
+		},
+	})
+	s3a.bucketConfigCache.Set("private", &BucketConfig{Name: "private"})
+
+	request := func(method, bucket, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "http://"+bucket+".website.local/"+key, nil)
+		r = mux.SetURLVars(r, map[string]string{"bucket": bucket, "object": key})
+		w := httptest.NewRecorder()
+		s3a.WebsiteHandler(w, r)
+		return w
+	}
+
+	if w := request(http.MethodGet, "old", "a/b.html"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "https://www.example.com/a/b.html" {
+		t.Errorf("unexpected redirect all response %d %s", w.Code, w.Header().Get("Location"))
+	}
+	if w := request(http.MethodGet, "site", "docs/a.html"); w.Code != http.StatusTemporaryRedirect || w.Header().Get("Location") != "http://site.website.local/documents/a.html" {
+		t.Errorf("unexpected routing rule response %d %s", w.Code, w.Header().Get("Location"))
+	}
+	if w := request(http.MethodGet, "private", "index.html"); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "NoSuchWebsiteConfiguration") {
+		t.Errorf("unexpected response without website configuration %d %s", w.Code, w.Body.String())
+	}
+	if w := request(http.MethodPut, "site", "index.html"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected response to a put %d", w.Code)
+	}
+}
+
+func TestWebsiteObjectWriter(t *testing.T) {
+	// an error document is served with the status of the failed request
+	w := httptest.NewRecorder()
+	ww := &websiteObjectWriter{w: w, header: make(http.Header), status: http.StatusNotFound}
+	ww.Header().Set("Content-Type", "text/html")
+	ww.Write([]byte("<h1>not here</h1>"))
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != "text/html" || w.Body.String() != "<h1>not here</h1>" {
+		t.Errorf("unexpected error document response %d %v %q", w.Code, w.Header(), w.Body.String())
+	}
+
+	// failures are held back
+	w = httptest.NewRecorder()
+	ww = &websiteObjectWriter{w: w, header: make(http.Header), status: http.StatusOK}
+	ww.Header().Set("Content-Type", "application/xml")
+	ww.RecordErrorCode("NoSuchKey")
+	ww.WriteHeader(http.StatusNotFound)
+	ww.Write([]byte("<Error/>"))
+	if ww.failedStatus != http.StatusNotFound || ww.errorCode != "NoSuchKey" || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("failure was not held back: %d %q %q", ww.failedStatus, ww.errorCode, w.Body.String())
+	}
+}
@@ -21,12 +21,14 @@ type CircuitBreaker struct {
 	Enabled     bool
 	counters    map[string]*int64
 	limitations map[string]int64
+	rateLimiter *RateLimiter
 }
 
 func NewCircuitBreaker(option *S3ApiServerOption) *CircuitBreaker {
 	cb := &CircuitBreaker{
 		counters:    make(map[string]*int64),
 		limitations: make(map[string]int64),
+		rateLimiter: NewRateLimiter(option.TrustedProxies),
 	}
 
 	err := pb.WithFilerClient(false, 0, option.Filer, option.GrpcDialOption, func(client filer_pb.SeaweedFilerClient) error {
@@ -84,12 +86,19 @@ func (cb *CircuitBreaker) loadCircuitBreakerConfig(cfg *s3_pb.S3CircuitBreakerCo
 	}
 
 	cb.limitations = limitations
+
+	//rate limits
+	if cb.rateLimiter == nil {
+		cb.rateLimiter = NewRateLimiter(nil)
+	}
+	cb.rateLimiter.loadRateLimitConfig(cfg.RateLimits)
 	return nil
 }
 
 func (cb *CircuitBreaker) Limit(f func(w http.ResponseWriter, r *http.Request), action string) (http.HandlerFunc, Action) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !cb.Enabled {
+		rateLimited := cb.rateLimiter != nil && cb.rateLimiter.isEnabled()
+		if !cb.Enabled && !rateLimited {
 			f(w, r)
 			return
 		}
@@ -97,6 +106,28 @@ func (cb *CircuitBreaker) Limit(f func(w http.ResponseWriter, r *http.Request),
 		vars := mux.Vars(r)
 		bucket := vars["bucket"]
 
+		if rateLimited {
+			charges, errCode := cb.rateLimiter.take(r, bucket)
+			if errCode != s3err.ErrNone {
+				s3err.WriteErrorResponse(w, r, errCode)
+				return
+			}
+			// the content length is charged in advance, and corrected with the bytes actually read,
+			// e.g. of chunked uploads without a content length
+			chargedBytes := max(r.ContentLength, 0)
+			body := &rateLimitedBody{ReadCloser: r.Body}
+			if r.Body != nil {
+				r.Body = body
+			}
+			defer func() {
+				cb.rateLimiter.chargeBytes(charges, body.bytesRead.Load()-chargedBytes+responseBodySize(w, r))
+			}()
+		}
+		if !cb.Enabled {
+			f(w, r)
+			return
+		}
+
 		rollback, errCode := cb.limit(r, bucket, action)
 		defer func() {
 			for _, rf := range rollback {
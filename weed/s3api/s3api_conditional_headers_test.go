@@ -2,15 +2,26 @@ package s3api
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/seaweedfs/seaweedfs/weed/cluster"
+	"github.com/seaweedfs/seaweedfs/weed/cluster/lock_manager"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 // TestConditionalHeadersWithExistingObjects tests conditional headers against existing objects
@@ -847,3 +858,194 @@ func TestConditionalHeadersMultipartUpload(t *testing.T) {
 		}
 	})
 }
+
+// testLockServer serves the distributed lock requests of the filer
+type testLockServer struct {
+	filer_pb.UnimplementedSeaweedFilerServer
+	locks        *lock_manager.LockManager
+	failRenewals atomic.Bool
+}
+
+func (s *testLockServer) DistributedLock(ctx context.Context, req *filer_pb.LockRequest) (*filer_pb.LockResponse, error) {
+	resp := &filer_pb.LockResponse{}
+	if req.RenewToken != "" && s.failRenewals.Load() {
+		resp.Error = "renewal refused"
+		return resp, nil
+	}
+	expiredAtNs := time.Now().Add(time.Duration(req.SecondsToLock) * time.Second).UnixNano()
+	var err error
+	if resp.LockOwner, resp.RenewToken, err = s.locks.Lock(req.Name, expiredAtNs, req.RenewToken, req.Owner); err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
+
+func (s *testLockServer) DistributedUnlock(ctx context.Context, req *filer_pb.UnlockRequest) (*filer_pb.UnlockResponse, error) {
+	resp := &filer_pb.UnlockResponse{}
+	if _, err := s.locks.Unlock(req.Name, req.RenewToken); err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
+
+func (s *testLockServer) FindLockOwner(ctx context.Context, req *filer_pb.FindLockOwnerRequest) (*filer_pb.FindLockOwnerResponse, error) {
+	owner, err := s.locks.GetLockOwner(req.Name)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return &filer_pb.FindLockOwnerResponse{Owner: owner}, nil
+}
+
+// newS3ApiServerWithLocks creates a test server locking through a local lock server
+func newS3ApiServerWithLocks(t *testing.T) *S3ApiServer {
+	s3a, _ := newS3ApiServerWithLockServer(t)
+	return s3a
+}
+
+func newS3ApiServerWithLockServer(t *testing.T) (*S3ApiServer, *testLockServer) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	lockServer := &testLockServer{locks: lock_manager.NewLockManager()}
+	grpcServer := grpc.NewServer()
+	filer_pb.RegisterSeaweedFilerServer(grpcServer, lockServer)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	filerAddress := pb.NewServerAddress("127.0.0.1", 1, listener.Addr().(*net.TCPAddr).Port)
+	s3a := NewS3ApiServerForTest()
+	s3a.lockClient = cluster.NewLockClient(grpc.WithTransportCredentials(insecure.NewCredentials()), filerAddress)
+	return s3a, lockServer
+}
+
+// testObjectStore keeps the object written by concurrent writers
+type testObjectStore struct {
+	sync.Mutex
+	entry *filer_pb.Entry
+}
+
+func (s *testObjectStore) getEntry(parentDirectoryPath, entryName string) (*filer_pb.Entry, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.entry == nil {
+		return nil, filer_pb.ErrNotFound
+	}
+	return s.entry, nil
+}
+
+func (s *testObjectStore) putEntry(entry *filer_pb.Entry) {
+	s.Lock()
+	defer s.Unlock()
+	s.entry = entry
+}
+
+// TestConditionalWriteLock verifies that only one of concurrent If-None-Match: * writers creates the object
+func TestConditionalWriteLock(t *testing.T) {
+	s3a := newS3ApiServerWithLocks(t)
+	bucket, object := "test-bucket", "/test-object"
+	store := &testObjectStore{}
+
+	results := make([]s3err.ErrorCode, 2)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := createTestPutRequest(bucket, object, fmt.Sprintf("content %d", i))
+			req.Header.Set(s3_constants.IfNoneMatch, "*")
+
+			req, unlock, errCode := s3a.lockObjectWrite(req, bucket, object)
+			if errCode != s3err.ErrNone {
+				results[i] = errCode
+				return
+			}
+			defer unlock()
+			if results[i] = s3a.checkConditionalHeadersWithGetter(store, req, bucket, object); results[i] != s3err.ErrNone {
+				return
+			}
+			// a slow upload, while the other writer checks the conditional headers
+			time.Sleep(100 * time.Millisecond)
+			store.putEntry(&filer_pb.Entry{
+				Name:     "test-object",
+				Extended: map[string][]byte{s3_constants.ExtETagKey: []byte(fmt.Sprintf("\"etag%d\"", i))},
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded, failed := 0, 0
+	for _, errCode := range results {
+		switch errCode {
+		case s3err.ErrNone:
+			succeeded++
+		case s3err.ErrPreconditionFailed:
+			failed++
+		}
+	}
+	if succeeded != 1 || failed != 1 {
+		t.Errorf("Expected exactly one writer to succeed and one to fail the precondition, got %v", results)
+	}
+}
+
+// TestObjectWriteLockTimeout verifies that writes give up when the object stays locked
+func TestObjectWriteLockTimeout(t *testing.T) {
+	s3a := newS3ApiServerWithLocks(t)
+	defer func(timeout time.Duration) { objectWriteLockTimeout = timeout }(objectWriteLockTimeout)
+	objectWriteLockTimeout = time.Second
+
+	req := createTestPutRequest("test-bucket", "/test-object", "content")
+	req.Header.Set(s3_constants.IfNoneMatch, "*")
+	_, unlock, errCode := s3a.lockObjectWrite(req, "test-bucket", "/test-object")
+	if errCode != s3err.ErrNone {
+		t.Fatalf("Expected to lock the object, got %v", errCode)
+	}
+	defer unlock()
+
+	// an unconditional write waits for the conditional write in flight
+	if _, _, errCode := s3a.lockObjectWrite(createTestPutRequest("test-bucket", "test-object", "other"), "test-bucket", "test-object"); errCode != s3err.ErrSlowDown {
+		t.Errorf("Expected ErrSlowDown for a locked object, got %v", errCode)
+	}
+}
+
+// TestUnconditionalWriteLock verifies that unconditional writes only lock objects with a conditional write in flight
+func TestUnconditionalWriteLock(t *testing.T) {
+	s3a, lockServer := newS3ApiServerWithLockServer(t)
+
+	_, unlock, errCode := s3a.lockObjectWrite(createTestPutRequest("test-bucket", "/test-object", "content"), "test-bucket", "/test-object")
+	if errCode != s3err.ErrNone {
+		t.Fatalf("Expected to write the object, got %v", errCode)
+	}
+	if _, err := lockServer.locks.GetLockOwner(objectWriteLockPrefix + s3a.option.BucketsPath + "/test-bucket/test-object"); err == nil {
+		t.Errorf("An unconditional write should not lock an object without conditional writes")
+	}
+	unlock()
+}
+
+// TestObjectWriteLockLost verifies that a write which can not renew the object lock is cancelled
+func TestObjectWriteLockLost(t *testing.T) {
+	s3a, lockServer := newS3ApiServerWithLockServer(t)
+	defer func(renew time.Duration) { objectWriteLockRenew = renew }(objectWriteLockRenew)
+	objectWriteLockRenew = 100 * time.Millisecond
+
+	req := createTestPutRequest("test-bucket", "/test-object", "content")
+	req.Header.Set(s3_constants.IfNoneMatch, "*")
+	req, unlock, errCode := s3a.lockObjectWrite(req, "test-bucket", "/test-object")
+	if errCode != s3err.ErrNone {
+		t.Fatalf("Expected to lock the object, got %v", errCode)
+	}
+	defer unlock()
+	if objectWriteLockLost(req) {
+		t.Fatalf("The lock should be held")
+	}
+
+	lockServer.failRenewals.Store(true)
+	select {
+	case <-req.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The write should be cancelled when the lock can not be renewed")
+	}
+	if !objectWriteLockLost(req) {
+		t.Errorf("Expected the write to fail with the lost lock, got %v", context.Cause(req.Context()))
+	}
+}
@@ -0,0 +1,450 @@
+package s3api
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3inventory"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	inventoryLockName = "s3.inventory"
+	// inventoryRecordsPerFile caps the size of a data file, which is buffered before the upload
+	inventoryRecordsPerFile = 1000000
+)
+
+// startInventoryProcessor periodically writes the inventory reports that are due.
+// Only the gateway holding the distributed lock does the work, so several
+// gateways can share one filer.
+func (s3a *S3ApiServer) startInventoryProcessor(interval time.Duration) {
+	self := fmt.Sprintf("s3@%s:%d-%d", util.DetectedHostAddress(), s3a.option.Port, s3a.randomClientId)
+	lock := s3a.lockClient.StartLongLivedLock(inventoryLockName, self, func(newLockOwner string) {
+		glog.V(0).Infof("s3 inventory processor is now run by %s", newLockOwner)
+	})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if lock.LockOwner() != self {
+			continue
+		}
+		s3a.processInventories(time.Now())
+	}
+}
+
+// processInventories writes an inventory for every enabled configuration
+// whose last report is older than its frequency
+func (s3a *S3ApiServer) processInventories(now time.Time) {
+	buckets, _, err := s3a.list(s3a.option.BucketsPath, "", "", false, math.MaxInt32)
+	if err != nil {
+		glog.Errorf("inventory: list buckets: %v", err)
+		return
+	}
+	for _, bucketEntry := range buckets {
+		if !bucketEntry.IsDirectory || len(bucketEntry.Content) == 0 {
+			continue
+		}
+		var metadata s3_pb.BucketMetadata
+		if err := proto.Unmarshal(bucketEntry.Content, &metadata); err != nil {
+			glog.Warningf("inventory: bucket %s metadata: %v", bucketEntry.Name, err)
+			continue
+		}
+		if metadata.Inventory == nil {
+			continue
+		}
+		for _, config := range metadata.Inventory.Configurations {
+			if !config.Enabled {
+				continue
+			}
+			lastRun, err := s3a.lastInventoryRun(bucketEntry.Name, config)
+			if err != nil {
+				glog.Warningf("inventory %s of bucket %s: %v", config.Id, bucketEntry.Name, err)
+				continue
+			}
+			if !s3inventory.IsDue(config, lastRun, now) {
+				continue
+			}
+			if err := s3a.writeInventory(bucketEntry.Name, config, now); err != nil {
+				glog.Errorf("inventory %s of bucket %s: %v", config.Id, bucketEntry.Name, err)
+			}
+		}
+	}
+}
+
+// lastInventoryRun finds the latest report in the destination bucket. Each
+// report is a folder named after its creation time holding the manifest.
+func (s3a *S3ApiServer) lastInventoryRun(bucket string, config *s3_pb.InventoryConfiguration) (time.Time, error) {
+	destinationBucket, err := s3inventory.ParseDestination(config.DestinationBucket)
+	if err != nil {
+		return time.Time{}, err
+	}
+	reportDir := s3a.option.BucketsPath + "/" + destinationBucket + "/" + strings.TrimSuffix(s3inventory.ReportPrefix(config, bucket), "/")
+	entries, _, err := s3a.list(reportDir, "", "", false, math.MaxInt32)
+	if err != nil {
+		if err == filer_pb.ErrNotFound {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	var lastRun time.Time
+	for _, entry := range entries {
+		if t, ok := s3inventory.ParseRunTimestamp(entry.Name); ok && entry.IsDirectory && t.After(lastRun) {
+			lastRun = t
+		}
+	}
+	return lastRun, nil
+}
+
+// writeInventory lists the objects of the bucket into data files in the
+// destination bucket, then writes the manifest describing them
+func (s3a *S3ApiServer) writeInventory(bucket string, config *s3_pb.InventoryConfiguration, now time.Time) error {
+	destinationBucket, err := s3inventory.ParseDestination(config.DestinationBucket)
+	if err != nil {
+		return err
+	}
+	if _, err := s3a.getEntry(s3a.option.BucketsPath, destinationBucket); err != nil {
+		return fmt.Errorf("destination bucket %s: %w", destinationBucket, err)
+	}
+	fields := s3inventory.Fields(config.AllVersions, config.OptionalFields)
+	fileSchema, err := s3inventory.FileSchema(config.Format, fields)
+	if err != nil {
+		return err
+	}
+
+	reportPrefix := s3inventory.ReportPrefix(config, bucket)
+	output := &inventoryOutput{
+		s3a:        s3a,
+		bucket:     destinationBucket,
+		dataPrefix: reportPrefix + "data/",
+		format:     config.Format,
+		fields:     fields,
+	}
+	walker := &inventoryWalker{
+		bucket:      bucket,
+		bucketDir:   s3a.option.BucketsPath + "/" + bucket,
+		prefix:      config.Prefix,
+		allVersions: config.AllVersions,
+		emit:        output.write,
+	}
+	glog.V(1).Infof("inventory %s of bucket %s: start", config.Id, bucket)
+	if err := s3a.walkInventory(walker); err != nil {
+		output.abort()
+		return fmt.Errorf("list objects: %w", err)
+	}
+	if err := output.flush(); err != nil {
+		output.abort()
+		return err
+	}
+
+	manifest := s3inventory.Manifest{
+		SourceBucket:      bucket,
+		DestinationBucket: config.DestinationBucket,
+		Version:           s3inventory.ManifestVersion,
+		CreationTimestamp: strconv.FormatInt(now.UnixMilli(), 10),
+		FileFormat:        config.Format,
+		FileSchema:        fileSchema,
+		Files:             output.files,
+	}
+	if manifest.Files == nil {
+		manifest.Files = []s3inventory.ManifestFile{}
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	runPrefix := reportPrefix + now.UTC().Format(s3inventory.RunTimestampLayout) + "/"
+	if err := s3a.putFilerObject(destinationBucket, runPrefix+"manifest.json", manifestData, "application/json"); err != nil {
+		output.abort()
+		return err
+	}
+	checksum := md5.Sum(manifestData)
+	if err := s3a.putFilerObject(destinationBucket, runPrefix+"manifest.checksum", []byte(hex.EncodeToString(checksum[:])), "text/plain"); err != nil {
+		return err
+	}
+	glog.V(0).Infof("inventory %s of bucket %s: %d objects in %d files", config.Id, bucket, output.total, len(output.files))
+	return nil
+}
+
+// walkInventory streams the objects under the deepest folder of the filter
+// prefix, with the multipart uploads in progress left out
+func (s3a *S3ApiServer) walkInventory(walker *inventoryWalker) error {
+	startDir := walker.bucketDir
+	if i := strings.LastIndex(walker.prefix, "/"); i >= 0 {
+		startDir += "/" + walker.prefix[:i]
+		entry, err := s3a.getEntry(walker.bucketDir, walker.prefix[:i])
+		if err == filer_pb.ErrNotFound || err == nil && (entry == nil || !entry.IsDirectory) {
+			// nothing matches the prefix
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err := s3a.WithFilerClient(true, func(client filer_pb.SeaweedFilerClient) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := client.TraverseBfsMetadata(ctx, &filer_pb.TraverseBfsMetadataRequest{
+			Directory:        startDir,
+			ExcludedPrefixes: []string{walker.bucketDir + "/" + s3_constants.MultipartUploadsFolder + "/"},
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := walker.visit(resp.Directory, resp.Entry); err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return walker.finish()
+}
+
+// inventoryWalker turns the breadth first traversal of a bucket into inventory
+// records. The traversal sends the entries of a folder together and sorted by
+// name, and the entries of a folder after all entries of the folders
+// discovered before it. So a file and the versions folder of the same object
+// are close to each other, and the versions come after all folders seen so far.
+type inventoryWalker struct {
+	bucket      string
+	bucketDir   string
+	prefix      string
+	allVersions bool
+	emit        func(r *s3inventory.Record) error
+
+	// pending are files of the current folder that may still get a versions
+	// folder. Every name is a prefix of the next one.
+	pendingDir string
+	pending    []*filer_pb.Entry
+	// versionsDirs are the versions folders in traversal order whose
+	// entries have not been sent completely
+	versionsDirs []inventoryVersionsDir
+}
+
+type inventoryVersionsDir struct {
+	path            string
+	latestVersionId string
+}
+
+func (w *inventoryWalker) visit(dir string, entry *filer_pb.Entry) error {
+	if dir != w.bucketDir && !strings.HasPrefix(dir, w.bucketDir+"/") {
+		return nil
+	}
+	if dir != w.pendingDir {
+		if err := w.flushPending(""); err != nil {
+			return err
+		}
+		w.pendingDir = dir
+	}
+
+	if strings.HasSuffix(dir, s3_constants.VersionsFolder) {
+		return w.visitVersion(dir, entry)
+	}
+	if err := w.flushPending(entry.Name); err != nil {
+		return err
+	}
+
+	switch {
+	case !entry.IsDirectory:
+		w.pending = append(w.pending, entry)
+	case dir == w.bucketDir && entry.Name == s3_constants.MultipartUploadsFolder:
+	case strings.HasSuffix(entry.Name, s3_constants.VersionsFolder):
+		versionsDir := inventoryVersionsDir{
+			path:            dir + "/" + entry.Name,
+			latestVersionId: string(entry.Extended[s3_constants.ExtLatestVersionIdKey]),
+		}
+		w.versionsDirs = append(w.versionsDirs, versionsDir)
+		name := strings.TrimSuffix(entry.Name, s3_constants.VersionsFolder)
+		if last := len(w.pending) - 1; last >= 0 && w.pending[last].Name == name {
+			// the null version is only the latest if no version is newer
+			nullEntry := w.pending[last]
+			w.pending = w.pending[:last]
+			return w.emitObject(w.key(dir, name), "null", versionsDir.latestVersionId == "", nullEntry)
+		}
+	case entry.IsDirectoryKeyObject():
+		return w.emitObject(w.key(dir, entry.Name)+"/", "", true, entry)
+	}
+	return nil
+}
+
+func (w *inventoryWalker) visitVersion(dir string, entry *filer_pb.Entry) error {
+	for len(w.versionsDirs) > 0 && w.versionsDirs[0].path != dir {
+		w.versionsDirs = w.versionsDirs[1:]
+	}
+	versionId := string(entry.Extended[s3_constants.ExtVersionIdKey])
+	if entry.IsDirectory || versionId == "" || len(w.versionsDirs) == 0 {
+		return nil
+	}
+	key := strings.TrimSuffix(strings.TrimPrefix(dir, w.bucketDir+"/"), s3_constants.VersionsFolder)
+	return w.emitObject(key, versionId, versionId == w.versionsDirs[0].latestVersionId, entry)
+}
+
+// flushPending emits the pending files that can no longer get a versions
+// folder, because the entry name does not start with their name
+func (w *inventoryWalker) flushPending(name string) error {
+	for last := len(w.pending) - 1; last >= 0 && (name == "" || !strings.HasPrefix(name, w.pending[last].Name)); last-- {
+		entry := w.pending[last]
+		w.pending = w.pending[:last]
+		if err := w.emitObject(w.key(w.pendingDir, entry.Name), "", true, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *inventoryWalker) finish() error {
+	return w.flushPending("")
+}
+
+func (w *inventoryWalker) key(dir, name string) string {
+	if dir == w.bucketDir {
+		return name
+	}
+	return strings.TrimPrefix(dir, w.bucketDir+"/") + "/" + name
+}
+
+func (w *inventoryWalker) emitObject(key, versionId string, isLatest bool, entry *filer_pb.Entry) error {
+	if !strings.HasPrefix(key, w.prefix) {
+		return nil
+	}
+	record := inventoryRecord(w.bucket, key, entry)
+	if !w.allVersions && (!isLatest || record.IsDeleteMarker) {
+		return nil
+	}
+	record.VersionId = versionId
+	record.IsLatest = isLatest
+	return w.emit(record)
+}
+
+func inventoryRecord(bucket, key string, entry *filer_pb.Entry) *s3inventory.Record {
+	record := &s3inventory.Record{
+		Bucket:                    bucket,
+		Key:                       key,
+		IsDeleteMarker:            string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true",
+		Size:                      int64(filer.FileSize(entry)),
+		LastModified:              time.Unix(entry.Attributes.GetMtime(), 0),
+		ETag:                      filer.ETag(entry),
+		StorageClass:              "STANDARD",
+		ReplicationStatus:         string(entry.Extended[s3_constants.AmzReplicationStatus]),
+		EncryptionStatus:          "NOT-SSE",
+		ObjectLockMode:            string(entry.Extended[s3_constants.ExtObjectLockModeKey]),
+		ObjectLockLegalHoldStatus: s3_constants.LegalHoldOff,
+	}
+	if storageClass, found := entry.Extended[s3_constants.AmzStorageClass]; found {
+		record.StorageClass = string(storageClass)
+	}
+	_, record.IsMultipartUploaded = entry.Extended[s3_constants.SeaweedFSUploadId]
+	switch {
+	case entry.Extended[s3_constants.AmzServerSideEncryptionCustomerAlgorithm] != nil:
+		record.EncryptionStatus = "SSE-C"
+	case string(entry.Extended[s3_constants.AmzServerSideEncryption]) == "aws:kms" || entry.Extended[s3_constants.SeaweedFSSSEKMSKey] != nil:
+		record.EncryptionStatus = "SSE-KMS"
+	case entry.Extended[s3_constants.AmzServerSideEncryption] != nil || entry.Extended[s3_constants.SeaweedFSSSES3Key] != nil:
+		record.EncryptionStatus = "SSE-S3"
+	}
+	if until, err := strconv.ParseInt(string(entry.Extended[s3_constants.ExtRetentionUntilDateKey]), 10, 64); err == nil {
+		record.ObjectLockRetainUntilDate = time.Unix(until, 0)
+	}
+	if string(entry.Extended[s3_constants.ExtLegalHoldKey]) == s3_constants.LegalHoldOn {
+		record.ObjectLockLegalHoldStatus = s3_constants.LegalHoldOn
+	}
+	if algorithm, _ := storedChecksum(entry); algorithm != ChecksumAlgorithmNone {
+		record.ChecksumAlgorithm = algorithm.Name()
+	}
+	return record
+}
+
+// inventoryOutput splits the records into data files and uploads them
+type inventoryOutput struct {
+	s3a        *S3ApiServer
+	bucket     string
+	dataPrefix string
+	format     string
+	fields     []string
+
+	buf    bytes.Buffer
+	writer s3inventory.Writer
+	count  int
+	total  int64
+	files  []s3inventory.ManifestFile
+}
+
+func (o *inventoryOutput) write(record *s3inventory.Record) error {
+	if o.writer == nil {
+		o.buf.Reset()
+		writer, err := s3inventory.NewWriter(&o.buf, o.format, o.fields)
+		if err != nil {
+			return err
+		}
+		o.writer = writer
+		o.count = 0
+	}
+	if err := o.writer.Write(record); err != nil {
+		return err
+	}
+	o.count++
+	o.total++
+	if o.count >= inventoryRecordsPerFile {
+		return o.flush()
+	}
+	return nil
+}
+
+// flush closes and uploads the current data file
+func (o *inventoryOutput) flush() error {
+	if o.writer == nil {
+		return nil
+	}
+	if err := o.writer.Close(); err != nil {
+		return err
+	}
+	o.writer = nil
+	key := o.dataPrefix + uuid.NewString() + s3inventory.FileExtension(o.format)
+	data := o.buf.Bytes()
+	if err := o.s3a.putFilerObject(o.bucket, key, data, "application/octet-stream"); err != nil {
+		return err
+	}
+	checksum := md5.Sum(data)
+	o.files = append(o.files, s3inventory.ManifestFile{
+		Key:         key,
+		Size:        int64(len(data)),
+		MD5checksum: hex.EncodeToString(checksum[:]),
+	})
+	return nil
+}
+
+// abort removes the data files of a report that could not be completed
+func (o *inventoryOutput) abort() {
+	for _, file := range o.files {
+		dir, name := util.FullPath(o.s3a.option.BucketsPath + "/" + o.bucket + "/" + file.Key).DirAndName()
+		if err := o.s3a.rm(dir, name, true, false); err != nil {
+			glog.Warningf("inventory: remove %s/%s: %v", o.bucket, file.Key, err)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package s3api
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3lifecycle"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	lifecycleLockName     = "s3.lifecycle"
+	lifecycleListPageSize = 1024
+)
+
+// startLifecycleProcessor periodically applies the bucket lifecycle rules.
+// Only the gateway holding the distributed lock does the work, so several
+// gateways can share one filer.
+func (s3a *S3ApiServer) startLifecycleProcessor(interval time.Duration) {
+	self := fmt.Sprintf("s3@%s:%d-%d", util.DetectedHostAddress(), s3a.option.Port, s3a.randomClientId)
+	lock := s3a.lockClient.StartLongLivedLock(lifecycleLockName, self, func(newLockOwner string) {
+		glog.V(0).Infof("s3 lifecycle processor is now run by %s", newLockOwner)
+	})
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if lock.LockOwner() != self {
+			continue
+		}
+		s3a.processLifecycleRules(time.Now())
+	}
+}
+
+// processLifecycleRules evaluates the lifecycle configuration of every bucket
+func (s3a *S3ApiServer) processLifecycleRules(now time.Time) {
+	buckets, _, err := s3a.list(s3a.option.BucketsPath, "", "", false, math.MaxInt32)
+	if err != nil {
+		glog.Errorf("lifecycle: list buckets: %v", err)
+		return
+	}
+	for _, bucketEntry := range buckets {
+		if !bucketEntry.IsDirectory || len(bucketEntry.Content) == 0 {
+			continue
+		}
+		var metadata s3_pb.BucketMetadata
+		if err := proto.Unmarshal(bucketEntry.Content, &metadata); err != nil {
+			glog.Warningf("lifecycle: bucket %s metadata: %v", bucketEntry.Name, err)
+			continue
+		}
+		if metadata.Lifecycle == nil || len(metadata.Lifecycle.Rules) == 0 {
+			continue
+		}
+		versioningState := string(bucketEntry.Extended[s3_constants.ExtVersioningKey])
+		if err := s3a.processBucketLifecycle(bucketEntry.Name, versioningState, metadata.Lifecycle, now); err != nil {
+			glog.Errorf("lifecycle: bucket %s: %v", bucketEntry.Name, err)
+		}
+	}
+}
+
+type lifecycleBucketScan struct {
+	s3a             *S3ApiServer
+	bucket          string
+	bucketDir       string
+	versioningState string
+	rules           []*s3_pb.LifecycleRule
+	now             time.Time
+	// filerConf is read on the first transition, for the storage class placement
+	filerConf *filer.FilerConf
+}
+
+func (s3a *S3ApiServer) processBucketLifecycle(bucket, versioningState string, config *s3_pb.LifecycleConfiguration, now time.Time) error {
+	scan := &lifecycleBucketScan{
+		s3a:             s3a,
+		bucket:          bucket,
+		bucketDir:       s3a.option.BucketsPath + "/" + bucket,
+		versioningState: versioningState,
+		rules:           config.Rules,
+		now:             now,
+	}
+	if err := scan.abortIncompleteUploads(); err != nil {
+		return fmt.Errorf("abort incomplete uploads: %w", err)
+	}
+	if !s3lifecycle.HasObjectActions(config) {
+		return nil
+	}
+	return scan.walkDirectory(scan.bucketDir, "")
+}
+
+func (scan *lifecycleBucketScan) abortIncompleteUploads() error {
+	uploadsDir := scan.s3a.genUploadsFolder(scan.bucket)
+	return scan.listAll(uploadsDir, func(entry *filer_pb.Entry) error {
+		if !entry.IsDirectory || entry.Attributes == nil {
+			return nil
+		}
+		key := strings.TrimPrefix(string(entry.Extended["key"]), "/")
+		action := s3lifecycle.EvaluateMultipartUpload(scan.rules, key, time.Unix(entry.Attributes.Crtime, 0), scan.now)
+		if action.Type != s3lifecycle.ActionAbortMultipartUpload {
+			return nil
+		}
+		glog.V(1).Infof("lifecycle rule %q: abort upload %s of %s/%s", action.RuleID, entry.Name, scan.bucket, key)
+		if err := scan.s3a.rm(uploadsDir, entry.Name, true, true); err != nil {
+			glog.Warningf("lifecycle: abort upload %s/%s: %v", uploadsDir, entry.Name, err)
+		}
+		return nil
+	})
+}
+
+// listAll lists a directory page by page so that entries can be modified while iterating
+func (scan *lifecycleBucketScan) listAll(dir string, fn func(entry *filer_pb.Entry) error) error {
+	startFrom := ""
+	for {
+		entries, isLast, err := scan.s3a.list(dir, "", startFrom, false, lifecycleListPageSize)
+		if err != nil {
+			if err == filer_pb.ErrNotFound {
+				return nil
+			}
+			return err
+		}
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+			startFrom = entry.Name
+		}
+		if isLast || len(entries) < lifecycleListPageSize {
+			return nil
+		}
+	}
+}
+
+// mayMatch returns true if objects under the key prefix could be selected by any rule
+func (scan *lifecycleBucketScan) mayMatch(keyPrefix string) bool {
+	for _, rule := range scan.rules {
+		if !rule.Enabled {
+			continue
+		}
+		rulePrefix := ""
+		if rule.Filter != nil {
+			rulePrefix = rule.Filter.Prefix
+		}
+		if strings.HasPrefix(keyPrefix, rulePrefix) || strings.HasPrefix(rulePrefix, keyPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (scan *lifecycleBucketScan) walkDirectory(dir, keyPrefix string) error {
+	var files []*filer_pb.Entry
+	versioned := make(map[string]*filer_pb.Entry)
+	var subDirs []string
+
+	err := scan.listAll(dir, func(entry *filer_pb.Entry) error {
+		switch {
+		case keyPrefix == "" && entry.Name == s3_constants.MultipartUploadsFolder:
+		case entry.IsDirectory && strings.HasSuffix(entry.Name, s3_constants.VersionsFolder):
+			versioned[strings.TrimSuffix(entry.Name, s3_constants.VersionsFolder)] = entry
+		case entry.IsDirectory:
+			subDirs = append(subDirs, entry.Name)
+		default:
+			files = append(files, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("list %s: %w", dir, err)
+	}
+
+	for _, entry := range files {
+		if _, hasVersions := versioned[entry.Name]; hasVersions {
+			continue
+		}
+		scan.processUnversionedObject(dir, keyPrefix+entry.Name, entry)
+	}
+	for name := range versioned {
+		scan.processVersionedObject(dir, keyPrefix+name)
+	}
+	for _, name := range subDirs {
+		if !scan.mayMatch(keyPrefix + name + "/") {
+			continue
+		}
+		if err := scan.walkDirectory(dir+"/"+name, keyPrefix+name+"/"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (scan *lifecycleBucketScan) processUnversionedObject(dir, key string, entry *filer_pb.Entry) {
+	obj := lifecycleObjectFromEntry(key, entry)
+	obj.IsLatest = true
+	obj.NumVersions = 1
+
+	action := s3lifecycle.Evaluate(scan.rules, obj, scan.now)
+	if action.Type == s3lifecycle.ActionTransition {
+		scan.processTransition(dir, key, "", entry, action)
+		return
+	}
+	if action.Type != s3lifecycle.ActionExpireCurrent {
+		return
+	}
+	glog.V(1).Infof("lifecycle rule %q: expire %s/%s", action.RuleID, scan.bucket, key)
+	if scan.versioningState == s3_constants.VersioningEnabled {
+		if _, err := scan.s3a.createDeleteMarker(scan.bucket, key); err != nil {
+			glog.Warningf("lifecycle: create delete marker for %s/%s: %v", scan.bucket, key, err)
+		}
+		return
+	}
+	if isObjectLocked(entry, scan.now) {
+		return
+	}
+	if err := scan.s3a.rm(dir, entry.Name, true, false); err != nil {
+		glog.Warningf("lifecycle: delete %s/%s: %v", scan.bucket, key, err)
+	}
+}
+
+type lifecycleVersion struct {
+	versionId string
+	entry     *filer_pb.Entry
+	timeNs    int64
+}
+
+func (scan *lifecycleBucketScan) processVersionedObject(dir, key string) {
+	versionsDir := scan.bucketDir + "/" + key + s3_constants.VersionsFolder
+	var versions []lifecycleVersion
+	err := scan.listAll(versionsDir, func(entry *filer_pb.Entry) error {
+		versionId := string(entry.Extended[s3_constants.ExtVersionIdKey])
+		if versionId == "" || entry.IsDirectory {
+			return nil
+		}
+		versions = append(versions, lifecycleVersion{versionId: versionId, entry: entry, timeNs: versionTimeNs(versionId, entry)})
+		return nil
+	})
+	if err != nil {
+		glog.Warningf("lifecycle: list versions of %s/%s: %v", scan.bucket, key, err)
+		return
+	}
+	name := key[strings.LastIndex(key, "/")+1:]
+	if nullEntry, err := scan.s3a.getEntry(dir, name); err == nil && !nullEntry.IsDirectory {
+		versions = append(versions, lifecycleVersion{versionId: "null", entry: nullEntry, timeNs: versionTimeNs("null", nullEntry)})
+	}
+	if len(versions) == 0 {
+		return
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].timeNs > versions[j].timeNs
+	})
+
+	remaining := len(versions)
+	for i, version := range versions {
+		obj := lifecycleObjectFromEntry(key, version.entry)
+		obj.IsLatest = i == 0
+		obj.NumVersions = len(versions)
+		if i > 0 {
+			obj.NoncurrentSince = time.Unix(0, versions[i-1].timeNs)
+			obj.NewerNoncurrent = i - 1
+		}
+
+		action := s3lifecycle.Evaluate(scan.rules, obj, scan.now)
+		switch action.Type {
+		case s3lifecycle.ActionExpireCurrent:
+			glog.V(1).Infof("lifecycle rule %q: expire %s/%s", action.RuleID, scan.bucket, key)
+			if version.versionId == "null" && scan.versioningState == s3_constants.VersioningSuspended {
+				if isObjectLocked(version.entry, scan.now) {
+					continue
+				}
+				if err := scan.s3a.deleteSpecificObjectVersion(scan.bucket, key, "null", false); err != nil {
+					glog.Warningf("lifecycle: delete null version of %s/%s: %v", scan.bucket, key, err)
+					continue
+				}
+				remaining--
+			} else if _, err := scan.s3a.createDeleteMarker(scan.bucket, key); err != nil {
+				glog.Warningf("lifecycle: create delete marker for %s/%s: %v", scan.bucket, key, err)
+			}
+		case s3lifecycle.ActionDeleteNoncurrent, s3lifecycle.ActionDeleteExpiredMarker:
+			if isObjectLocked(version.entry, scan.now) {
+				continue
+			}
+			glog.V(1).Infof("lifecycle rule %q: delete version %s of %s/%s", action.RuleID, version.versionId, scan.bucket, key)
+			if err := scan.s3a.deleteSpecificObjectVersion(scan.bucket, key, version.versionId, false); err != nil {
+				glog.Warningf("lifecycle: delete version %s of %s/%s: %v", version.versionId, scan.bucket, key, err)
+				continue
+			}
+			remaining--
+		case s3lifecycle.ActionTransition:
+			versionDir := versionsDir
+			if version.versionId == "null" {
+				versionDir = dir
+			}
+			scan.processTransition(versionDir, key, version.versionId, version.entry, action)
+		}
+	}
+
+	if remaining == 0 {
+		if err := scan.s3a.rm(dir, name+s3_constants.VersionsFolder, true, true); err != nil {
+			glog.Warningf("lifecycle: remove empty %s: %v", versionsDir, err)
+		}
+	}
+}
+
+func (scan *lifecycleBucketScan) processTransition(dir, key, versionId string, entry *filer_pb.Entry, action s3lifecycle.Action) {
+	glog.V(1).Infof("lifecycle rule %q: transition %s/%s %s to %s", action.RuleID, scan.bucket, key, versionId, action.StorageClass)
+	if err := scan.transitionObject(dir, entry, action.StorageClass); err != nil {
+		glog.Warningf("lifecycle: transition %s/%s %s to %s: %v", scan.bucket, key, versionId, action.StorageClass, err)
+	}
+}
+
+func lifecycleObjectFromEntry(key string, entry *filer_pb.Entry) s3lifecycle.Object {
+	obj := s3lifecycle.Object{
+		Key:            key,
+		Size:           int64(entry.Attributes.GetFileSize()),
+		ModTime:        time.Unix(entry.Attributes.GetMtime(), 0),
+		IsDeleteMarker: string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true",
+		Tags:           objectTags(entry),
+		StorageClass:   string(entry.Extended[s3_constants.AmzStorageClass]),
+	}
+	return obj
+}
+
+// versionTimeNs returns the creation time of a version. Version ids start
+// with the hex encoded creation time in nanoseconds.
+func versionTimeNs(versionId string, entry *filer_pb.Entry) int64 {
+	if len(versionId) >= 16 {
+		if ts, err := strconv.ParseInt(versionId[:16], 16, 64); err == nil {
+			return ts
+		}
+	}
+	return time.Unix(entry.Attributes.GetMtime(), 0).UnixNano()
+}
+
+// isObjectLocked returns true if the version is protected by a retention period or a legal hold
+func isObjectLocked(entry *filer_pb.Entry, now time.Time) bool {
+	if string(entry.Extended[s3_constants.ExtLegalHoldKey]) == s3_constants.LegalHoldOn {
+		return true
+	}
+	if untilBytes, found := entry.Extended[s3_constants.ExtRetentionUntilDateKey]; found {
+		if until, err := strconv.ParseInt(string(untilBytes), 10, 64); err == nil && now.Before(time.Unix(until, 0)) {
+			return true
+		}
+	}
+	return false
+}
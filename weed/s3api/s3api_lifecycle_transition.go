@@ -0,0 +1,186 @@
+package s3api
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"google.golang.org/protobuf/proto"
+)
+
+const lifecycleTransitionConcurrency = 4
+
+// transitionObject moves an object version to another storage class.
+// If the storage class is mapped to a disk type or replication in filer.conf,
+// the chunks are rewritten onto volumes with that placement, and the old
+// chunks are garbage collected by the filer when the entry is updated.
+// Otherwise only the storage class of the object is changed.
+func (scan *lifecycleBucketScan) transitionObject(dir string, entry *filer_pb.Entry, storageClass string) error {
+	if scan.filerConf == nil {
+		fc, err := filer.ReadFilerConf(scan.s3a.option.Filer, scan.s3a.option.GrpcDialOption, nil)
+		if err != nil {
+			return fmt.Errorf("read filer conf: %w", err)
+		}
+		scan.filerConf = fc
+	}
+
+	newEntry := proto.Clone(entry).(*filer_pb.Entry)
+	if storageClassConf, found := scan.filerConf.GetStorageClassConf(storageClass); found && len(entry.GetChunks()) > 0 {
+		chunks, err := scan.s3a.rewriteChunks(entry, util.Join(dir, entry.Name), storageClassConf)
+		if err != nil {
+			return err
+		}
+		// the object may have been overwritten while its chunks were copied
+		latest, err := scan.s3a.getEntry(dir, entry.Name)
+		if err != nil {
+			return err
+		}
+		if !sameChunks(latest.GetChunks(), entry.GetChunks()) {
+			scan.s3a.deleteChunks(chunks)
+			return fmt.Errorf("object changed during the transition")
+		}
+		newEntry.Chunks = chunks
+	}
+	if newEntry.Extended == nil {
+		newEntry.Extended = make(map[string][]byte)
+	}
+	newEntry.Extended[s3_constants.AmzStorageClass] = []byte(storageClass)
+	return scan.s3a.updateEntry(dir, newEntry)
+}
+
+// rewriteChunks copies the data chunks of an entry onto volumes assigned with the placement of the storage class.
+// Chunk manifests are resolved, so the returned chunks are all data chunks.
+func (s3a *S3ApiServer) rewriteChunks(entry *filer_pb.Entry, path string, storageClassConf *filer_pb.FilerConf_StorageClassConf) ([]*filer_pb.FileChunk, error) {
+	dataChunks, _, err := filer.ResolveChunkManifest(context.Background(), filer.LookupFn(s3a), entry.GetChunks(), 0, math.MaxInt64)
+	if err != nil {
+		return nil, fmt.Errorf("resolve chunk manifest: %w", err)
+	}
+
+	dstChunks := make([]*filer_pb.FileChunk, len(dataChunks))
+	executor := util.NewLimitedConcurrentExecutor(lifecycleTransitionConcurrency)
+	errChan := make(chan error, len(dataChunks))
+	for i, chunk := range dataChunks {
+		chunkIndex, chunk := i, chunk
+		executor.Execute(func() {
+			dstChunk, err := s3a.rewriteChunk(chunk, path, storageClassConf)
+			if err != nil {
+				errChan <- fmt.Errorf("chunk %d: %v", chunkIndex, err)
+				return
+			}
+			dstChunks[chunkIndex] = dstChunk
+			errChan <- nil
+		})
+	}
+	var firstErr error
+	for range dataChunks {
+		if err := <-errChan; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		s3a.deleteChunks(dstChunks)
+		return nil, firstErr
+	}
+	return dstChunks, nil
+}
+
+func (s3a *S3ApiServer) rewriteChunk(chunk *filer_pb.FileChunk, path string, storageClassConf *filer_pb.FilerConf_StorageClassConf) (*filer_pb.FileChunk, error) {
+	var assignResult *filer_pb.AssignVolumeResponse
+	err := s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.AssignVolume(context.Background(), &filer_pb.AssignVolumeRequest{
+			Count:       1,
+			Replication: storageClassConf.Replication,
+			DiskType:    storageClassConf.DiskType,
+			DataCenter:  s3a.option.DataCenter,
+			Path:        path,
+		})
+		if err != nil {
+			return fmt.Errorf("assign volume: %w", err)
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("assign volume: %v", resp.Error)
+		}
+		assignResult = resp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	srcUrl, err := s3a.lookupVolumeUrl(chunk.GetFileIdString())
+	if err != nil {
+		return nil, fmt.Errorf("lookup source URL: %w", err)
+	}
+
+	dstChunk := s3a.createDestinationChunk(chunk, chunk.Offset, chunk.Size)
+	dstChunk.ModifiedTsNs = chunk.ModifiedTsNs
+	dstChunk.SseType = chunk.SseType
+	dstChunk.SseMetadata = chunk.SseMetadata
+	if err := s3a.setChunkFileId(dstChunk, assignResult); err != nil {
+		return nil, err
+	}
+	chunkData, err := s3a.downloadChunkData(srcUrl, 0, int64(chunk.Size))
+	if err != nil {
+		return nil, fmt.Errorf("download chunk data: %w", err)
+	}
+	if err := s3a.uploadChunkData(chunkData, assignResult); err != nil {
+		return nil, fmt.Errorf("upload chunk data: %w", err)
+	}
+	return dstChunk, nil
+}
+
+// deleteChunks removes chunks that were copied but are not referenced by any entry
+func (s3a *S3ApiServer) deleteChunks(chunks []*filer_pb.FileChunk) {
+	var fileIds []string
+	for _, chunk := range chunks {
+		if chunk != nil {
+			fileIds = append(fileIds, chunk.GetFileIdString())
+		}
+	}
+	if len(fileIds) == 0 {
+		return
+	}
+	lookupFunc := func(vids []string) (map[string]*operation.LookupResult, error) {
+		results := make(map[string]*operation.LookupResult)
+		err := s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+			resp, err := client.LookupVolume(context.Background(), &filer_pb.LookupVolumeRequest{VolumeIds: vids})
+			if err != nil {
+				return err
+			}
+			for vid, locations := range resp.LocationsMap {
+				result := &operation.LookupResult{VolumeOrFileId: vid}
+				for _, location := range locations.Locations {
+					result.Locations = append(result.Locations, operation.Location{
+						Url:        location.Url,
+						PublicUrl:  location.PublicUrl,
+						DataCenter: location.DataCenter,
+						GrpcPort:   int(location.GrpcPort),
+					})
+				}
+				results[vid] = result
+			}
+			return nil
+		})
+		return results, err
+	}
+	if _, err := operation.DeleteFileIdsWithLookupVolumeId(s3a.option.GrpcDialOption, fileIds, lookupFunc); err != nil {
+		glog.Warningf("lifecycle: delete %d copied chunks: %v", len(fileIds), err)
+	}
+}
+
+func sameChunks(a, b []*filer_pb.FileChunk) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].GetFileIdString() != b[i].GetFileIdString() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,178 @@
+package s3api
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/notification"
+	_ "github.com/seaweedfs/seaweedfs/weed/notification/aws_sqs"
+	_ "github.com/seaweedfs/seaweedfs/weed/notification/gocdk_pub_sub"
+	_ "github.com/seaweedfs/seaweedfs/weed/notification/google_pub_sub"
+	_ "github.com/seaweedfs/seaweedfs/weed/notification/kafka"
+	_ "github.com/seaweedfs/seaweedfs/weed/notification/log"
+	_ "github.com/seaweedfs/seaweedfs/weed/notification/webhook"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3notification"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	notificationEventsName    = "s3.notification"
+	notificationTargetsConfig = "s3.notification"
+)
+
+// loadNotificationTargets initializes the message queues configured in notification.toml,
+// which bucket notification configurations refer to by the name in their destination ARN, e.g.
+//
+//	[s3.notification.uploads]
+//	type = "kafka"
+//	hosts = ["localhost:9092"]
+//	topic = "uploads"
+func loadNotificationTargets(config *util.ViperProxy) map[string]notification.RawMessageQueue {
+	targets := make(map[string]notification.RawMessageQueue)
+	for name := range config.GetStringMap(notificationTargetsConfig) {
+		prefix := notificationTargetsConfig + "." + name + "."
+		queueType := config.GetString(prefix + "type")
+		queue := notification.NewMessageQueue(queueType)
+		if queue == nil {
+			glog.Warningf("notification target %s: unsupported type %q", name, queueType)
+			continue
+		}
+		rawQueue, ok := queue.(notification.RawMessageQueue)
+		if !ok {
+			glog.Warningf("notification target %s: %s can not send S3 event messages", name, queueType)
+			continue
+		}
+		if err := queue.Initialize(config, prefix); err != nil {
+			glog.Errorf("notification target %s: initialize %s: %v", name, queueType, err)
+			continue
+		}
+		targets[name] = rawQueue
+		glog.V(0).Infof("s3 notification target %s: %s", name, queueType)
+	}
+	return targets
+}
+
+// startNotificationProcessor follows the metadata changes under the buckets folder
+// and sends the S3 events selected by the bucket notification configurations.
+func (s3a *S3ApiServer) startNotificationProcessor() {
+	if len(s3a.notificationTargets) == 0 {
+		return
+	}
+	newBucketEventFollower(s3a, notificationEventsName, s3a.processNotificationEvent).run()
+}
+
+func (s3a *S3ApiServer) processNotificationEvent(resp *filer_pb.SubscribeMetadataResponse) {
+	message := resp.EventNotification
+	// replication status updates do not change the object
+	if slices.Contains(message.Signatures, replicationSignature) {
+		return
+	}
+	event, ok := s3a.parseBucketObjectEvent(resp)
+	if !ok {
+		return
+	}
+
+	metadata, err := s3a.GetBucketMetadata(event.bucket)
+	if err != nil {
+		glog.V(1).Infof("notification: bucket %s metadata: %v", event.bucket, err)
+		return
+	}
+	if !metadata.HasNotification() {
+		return
+	}
+
+	eventName := detectObjectEvent(message, event.isVersion)
+	if eventName == "" {
+		return
+	}
+	rules := s3notification.Match(metadata.Notification.Rules, eventName, event.key)
+	if len(rules) == 0 {
+		return
+	}
+
+	object := s3notification.ObjectInfo{
+		Bucket:    event.bucket,
+		Key:       event.key,
+		Principal: string(event.entry.Extended[s3_constants.ExtAmzOwnerKey]),
+	}
+	if event.isVersion {
+		object.VersionId = string(event.entry.Extended[s3_constants.ExtVersionIdKey])
+	}
+	if message.NewEntry != nil {
+		object.Size = int64(filer.FileSize(message.NewEntry))
+		object.ETag = s3a.getObjectETag(message.NewEntry)
+	}
+	if bucketConfig, errCode := s3a.getBucketConfig(event.bucket); errCode == s3err.ErrNone {
+		object.BucketOwner = bucketConfig.Owner
+	}
+
+	for _, rule := range rules {
+		_, name, _ := s3notification.ParseDestination(rule.DestinationArn)
+		queue, found := s3a.notificationTargets[name]
+		if !found {
+			glog.Warningf("notification %q of bucket %s: target %q is not configured", rule.Id, event.bucket, name)
+			continue
+		}
+		record := s3notification.NewRecord(eventName, rule.Id, object, time.Unix(0, resp.TsNs), resp.TsNs)
+		body, err := json.Marshal(s3notification.Event{Records: []s3notification.Record{record}})
+		if err != nil {
+			glog.Errorf("notification %q: marshal event: %v", rule.Id, err)
+			continue
+		}
+		if err := queue.SendRawMessage(event.bucket+"/"+event.key, body); err != nil {
+			glog.Errorf("notification %q of bucket %s: send %s of %s: %v", rule.Id, event.bucket, eventName, event.key, err)
+		}
+	}
+}
+
+// detectObjectEvent returns the S3 event type of a metadata change of an object,
+// or an empty string if the change is not notified, e.g. an ACL update
+func detectObjectEvent(message *filer_pb.EventNotification, isVersion bool) string {
+	oldEntry, newEntry := message.OldEntry, message.NewEntry
+	if newEntry == nil {
+		return s3notification.ObjectRemovedDelete
+	}
+	if string(newEntry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true" {
+		if oldEntry == nil {
+			return s3notification.ObjectRemovedDeleteMarkerCreated
+		}
+		return ""
+	}
+
+	created := oldEntry == nil ||
+		filer.ETag(oldEntry) != filer.ETag(newEntry) ||
+		oldEntry.Attributes.GetMtime() != newEntry.Attributes.GetMtime()
+	if isVersion {
+		// a new version is uploaded first and gets its version id in a follow-up update
+		if _, found := newEntry.Extended[s3_constants.ExtVersionIdKey]; !found {
+			return ""
+		}
+		if oldEntry != nil {
+			_, oldHasVersionId := oldEntry.Extended[s3_constants.ExtVersionIdKey]
+			created = !oldHasVersionId
+		}
+	}
+	if created {
+		if _, found := newEntry.Extended[s3_constants.SeaweedFSUploadId]; found {
+			return s3notification.ObjectCreatedCompleteMultipartUpload
+		}
+		return s3notification.ObjectCreatedPut
+	}
+
+	oldTags, newTags := objectTags(oldEntry), objectTags(newEntry)
+	switch {
+	case maps.Equal(oldTags, newTags):
+		return ""
+	case len(newTags) == 0:
+		return s3notification.ObjectTaggingDelete
+	default:
+		return s3notification.ObjectTaggingPut
+	}
+}
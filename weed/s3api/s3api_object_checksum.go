@@ -0,0 +1,353 @@
+package s3api
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// additional checksum algorithms in the order S3 lists them
+var checksumAlgorithms = []ChecksumAlgorithm{
+	ChecksumAlgorithmCRC32,
+	ChecksumAlgorithmCRC32C,
+	ChecksumAlgorithmCRC64NVMe,
+	ChecksumAlgorithmSHA1,
+	ChecksumAlgorithmSHA256,
+}
+
+// Name returns the name of the algorithm in the x-amz-checksum-algorithm header, e.g. CRC32C
+func (ca ChecksumAlgorithm) Name() string {
+	return strings.ToUpper(strings.TrimPrefix(ca.String(), "x-amz-checksum-"))
+}
+
+// Header returns the header carrying a checksum of the algorithm, which is also
+// the extended attribute key the checksum is stored with
+func (ca ChecksumAlgorithm) Header() string {
+	return http.CanonicalHeaderKey(ca.String())
+}
+
+// size returns the size of the raw checksum
+func (ca ChecksumAlgorithm) size() int {
+	switch ca {
+	case ChecksumAlgorithmCRC32, ChecksumAlgorithmCRC32C:
+		return 4
+	case ChecksumAlgorithmCRC64NVMe:
+		return 8
+	case ChecksumAlgorithmSHA1:
+		return 20
+	case ChecksumAlgorithmSHA256:
+		return 32
+	}
+	return 0
+}
+
+// isCRC tells whether checksums of the algorithm can be combined into a full object checksum
+func (ca ChecksumAlgorithm) isCRC() bool {
+	return ca == ChecksumAlgorithmCRC32 || ca == ChecksumAlgorithmCRC32C || ca == ChecksumAlgorithmCRC64NVMe
+}
+
+func checksumAlgorithmFromName(name string) (ChecksumAlgorithm, bool) {
+	for _, algorithm := range checksumAlgorithms {
+		if strings.EqualFold(name, algorithm.Name()) {
+			return algorithm, true
+		}
+	}
+	return ChecksumAlgorithmNone, false
+}
+
+// decodeChecksum decodes a base64 checksum value, checking its size
+func decodeChecksum(algorithm ChecksumAlgorithm, value string) ([]byte, bool) {
+	raw, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(raw) != algorithm.size() {
+		return nil, false
+	}
+	return raw, true
+}
+
+// objectChecksum computes the additional checksum of an uploaded object or part
+// and verifies it against the value sent by the client
+type objectChecksum struct {
+	algorithm ChecksumAlgorithm
+	expected  string // from the request header, empty when sent in a trailer, already verified by the chunked reader
+	hasher    hash.Hash
+	value     string
+}
+
+// newObjectChecksum selects the checksum of an upload from the x-amz-checksum-* headers, the x-amz-trailer header,
+// or the x-amz-sdk-checksum-algorithm header, in which case the server computes it.
+// Parts of a multipart upload use the algorithm of the upload by default.
+// It returns nil when no checksum is requested.
+func newObjectChecksum(r *http.Request, defaultAlgorithm ChecksumAlgorithm) (*objectChecksum, s3err.ErrorCode) {
+	algorithm, expected := ChecksumAlgorithmNone, ""
+	for _, candidate := range checksumAlgorithms {
+		value := r.Header.Get(candidate.Header())
+		if value == "" {
+			continue
+		}
+		if algorithm != ChecksumAlgorithmNone {
+			glog.V(2).Infof("more than one checksum header: %s and %s", algorithm.Header(), candidate.Header())
+			return nil, s3err.ErrInvalidRequest
+		}
+		if _, ok := decodeChecksum(candidate, value); !ok {
+			glog.V(2).Infof("invalid %s header %q", candidate.Header(), value)
+			return nil, s3err.ErrInvalidDigest
+		}
+		algorithm, expected = candidate, value
+	}
+
+	if trailer := r.Header.Get(s3_constants.AmzTrailer); trailer != "" {
+		trailerAlgorithm, err := extractChecksumAlgorithm(strings.ToLower(trailer))
+		if err != nil || (algorithm != ChecksumAlgorithmNone && algorithm != trailerAlgorithm) {
+			glog.V(2).Infof("unexpected %s header %q", s3_constants.AmzTrailer, trailer)
+			return nil, s3err.ErrInvalidRequest
+		}
+		algorithm = trailerAlgorithm
+	}
+
+	if name := r.Header.Get(s3_constants.AmzSdkChecksumAlgorithm); name != "" {
+		sdkAlgorithm, found := checksumAlgorithmFromName(name)
+		if !found || (algorithm != ChecksumAlgorithmNone && algorithm != sdkAlgorithm) {
+			glog.V(2).Infof("unexpected %s header %q", s3_constants.AmzSdkChecksumAlgorithm, name)
+			return nil, s3err.ErrInvalidRequest
+		}
+		algorithm = sdkAlgorithm
+	}
+
+	if defaultAlgorithm != ChecksumAlgorithmNone {
+		if algorithm == ChecksumAlgorithmNone {
+			algorithm = defaultAlgorithm
+		} else if algorithm != defaultAlgorithm {
+			glog.V(2).Infof("checksum type mismatch, expected %s, actual %s", defaultAlgorithm.Name(), algorithm.Name())
+			return nil, s3err.ErrInvalidRequest
+		}
+	}
+
+	if algorithm == ChecksumAlgorithmNone {
+		return nil, s3err.ErrNone
+	}
+	return &objectChecksum{
+		algorithm: algorithm,
+		expected:  expected,
+		hasher:    getCheckSumWriter(algorithm),
+	}, s3err.ErrNone
+}
+
+// reader computes the checksum of the data read through it. Once the data is read,
+// it fails if the checksum does not match the expected one, or sets the checksum in
+// the trailer, so that the filer saves it along with the entry.
+func (c *objectChecksum) reader(r io.Reader, trailer http.Header) io.Reader {
+	trailer[c.algorithm.Header()] = nil
+	trailer[s3_constants.AmzChecksumType] = nil
+	return &checksumReader{r: r, checksum: c, trailer: trailer}
+}
+
+func (c *objectChecksum) setResponseHeader(w http.ResponseWriter) {
+	if c != nil && c.value != "" {
+		w.Header().Set(c.algorithm.Header(), c.value)
+	}
+}
+
+type checksumReader struct {
+	r        io.Reader
+	checksum *objectChecksum
+	trailer  http.Header
+}
+
+func (cr *checksumReader) Read(p []byte) (n int, err error) {
+	n, err = cr.r.Read(p)
+	cr.checksum.hasher.Write(p[:n])
+	if err == io.EOF {
+		c := cr.checksum
+		c.value = base64.StdEncoding.EncodeToString(c.hasher.Sum(nil))
+		if c.expected != "" && c.expected != c.value {
+			glog.V(2).Infof("%s checksum %s does not match the computed %s", c.algorithm.Name(), c.expected, c.value)
+			return n, errors.New(s3err.ErrMsgPayloadChecksumMismatch)
+		}
+		cr.trailer.Set(c.algorithm.Header(), c.value)
+		cr.trailer.Set(s3_constants.AmzChecksumType, s3_constants.ChecksumTypeFullObject)
+	}
+	return n, err
+}
+
+// storedChecksum returns the additional checksum stored with an entry
+func storedChecksum(entry *filer_pb.Entry) (algorithm ChecksumAlgorithm, value string) {
+	for _, candidate := range checksumAlgorithms {
+		if value, found := entry.Extended[candidate.Header()]; found {
+			return candidate, string(value)
+		}
+	}
+	return ChecksumAlgorithmNone, ""
+}
+
+// parseMultipartChecksum reads the checksum algorithm and type of a multipart upload to create.
+// S3 only supports full object checksums of the CRC algorithms, and CRC64NVME only has them.
+func parseMultipartChecksum(header http.Header) (algorithm ChecksumAlgorithm, checksumType string, errCode s3err.ErrorCode) {
+	name, checksumType := header.Get(s3_constants.AmzChecksumAlgorithm), header.Get(s3_constants.AmzChecksumType)
+	if name == "" {
+		if checksumType != "" {
+			return ChecksumAlgorithmNone, "", s3err.ErrInvalidRequest
+		}
+		return ChecksumAlgorithmNone, "", s3err.ErrNone
+	}
+	algorithm, found := checksumAlgorithmFromName(name)
+	if !found {
+		return ChecksumAlgorithmNone, "", s3err.ErrInvalidRequest
+	}
+	switch checksumType {
+	case "":
+		checksumType = s3_constants.ChecksumTypeComposite
+		if algorithm == ChecksumAlgorithmCRC64NVMe {
+			checksumType = s3_constants.ChecksumTypeFullObject
+		}
+	case s3_constants.ChecksumTypeComposite:
+		if algorithm == ChecksumAlgorithmCRC64NVMe {
+			return ChecksumAlgorithmNone, "", s3err.ErrInvalidRequest
+		}
+	case s3_constants.ChecksumTypeFullObject:
+		if !algorithm.isCRC() {
+			return ChecksumAlgorithmNone, "", s3err.ErrInvalidRequest
+		}
+	default:
+		return ChecksumAlgorithmNone, "", s3err.ErrInvalidRequest
+	}
+	return algorithm, checksumType, s3err.ErrNone
+}
+
+// partChecksum is the checksum of a part of a multipart upload
+type partChecksum struct {
+	value string
+	size  int64
+}
+
+// multipartChecksum computes the checksum of a completed multipart upload from the checksums of its parts.
+// A composite checksum is the checksum of the concatenated raw part checksums, suffixed with the number of parts.
+// A full object checksum is the CRC of the whole object, combined from the part CRCs.
+func multipartChecksum(algorithm ChecksumAlgorithm, checksumType string, parts []partChecksum) (string, error) {
+	if checksumType == s3_constants.ChecksumTypeFullObject {
+		var crc uint64
+		for i, part := range parts {
+			raw, ok := decodeChecksum(algorithm, part.value)
+			if !ok {
+				return "", fmt.Errorf("part %d: invalid %s checksum %q", i+1, algorithm.Name(), part.value)
+			}
+			var partCrc uint64
+			if algorithm == ChecksumAlgorithmCRC64NVMe {
+				partCrc = binary.BigEndian.Uint64(raw)
+			} else {
+				partCrc = uint64(binary.BigEndian.Uint32(raw))
+			}
+			if i == 0 {
+				crc = partCrc
+			} else {
+				crc = crcCombine(crcPolynomial(algorithm), algorithm.size()*8, crc, partCrc, part.size)
+			}
+		}
+		raw := binary.BigEndian.AppendUint64(nil, crc)
+		return base64.StdEncoding.EncodeToString(raw[8-algorithm.size():]), nil
+	}
+
+	hasher := getCheckSumWriter(algorithm)
+	for i, part := range parts {
+		raw, ok := decodeChecksum(algorithm, part.value)
+		if !ok {
+			return "", fmt.Errorf("part %d: invalid %s checksum %q", i+1, algorithm.Name(), part.value)
+		}
+		hasher.Write(raw)
+	}
+	return base64.StdEncoding.EncodeToString(hasher.Sum(nil)) + "-" + strconv.Itoa(len(parts)), nil
+}
+
+// crcPolynomial returns the reversed polynomial of a CRC algorithm
+func crcPolynomial(algorithm ChecksumAlgorithm) uint64 {
+	switch algorithm {
+	case ChecksumAlgorithmCRC32:
+		return 0xedb88320
+	case ChecksumAlgorithmCRC32C:
+		return 0x82f63b78
+	case ChecksumAlgorithmCRC64NVMe:
+		return 0x9a6c9329ac4bc9b5
+	}
+	return 0
+}
+
+// crcCombine returns the CRC of two concatenated blocks from their CRCs and the length of the second block,
+// as zlib crc32_combine, for reflected CRCs of the given width and reversed polynomial.
+func crcCombine(poly uint64, width int, crc1, crc2 uint64, len2 int64) uint64 {
+	if len2 <= 0 {
+		return crc1
+	}
+	even := make([]uint64, width) // even-power-of-two zeros operator
+	odd := make([]uint64, width)  // odd-power-of-two zeros operator
+
+	// put operator for one zero bit in odd
+	odd[0] = poly
+	row := uint64(1)
+	for n := 1; n < width; n++ {
+		odd[n] = row
+		row <<= 1
+	}
+	gf2MatrixSquare(even, odd) // two zero bits
+	gf2MatrixSquare(odd, even) // four zero bits
+
+	// apply len2 zeros to crc1, the first square puts the operator for one zero byte in even
+	for {
+		gf2MatrixSquare(even, odd)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(even, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+		gf2MatrixSquare(odd, even)
+		if len2&1 != 0 {
+			crc1 = gf2MatrixTimes(odd, crc1)
+		}
+		len2 >>= 1
+		if len2 == 0 {
+			break
+		}
+	}
+	return crc1 ^ crc2
+}
+
+func gf2MatrixTimes(mat []uint64, vec uint64) (sum uint64) {
+	for i := 0; vec != 0; i, vec = i+1, vec>>1 {
+		if vec&1 != 0 {
+			sum ^= mat[i]
+		}
+	}
+	return sum
+}
+
+func gf2MatrixSquare(square, mat []uint64) {
+	for n := range mat {
+		square[n] = gf2MatrixTimes(mat, mat[n])
+	}
+}
+
+// filterChecksumHeaders removes the stored checksums from a GET or HEAD response,
+// unless the client asks for them with x-amz-checksum-mode: ENABLED.
+// Checksums cover the whole object, so they are not returned for ranges or parts.
+func filterChecksumHeaders(r *http.Request, resp *http.Response) {
+	if strings.EqualFold(r.Header.Get(s3_constants.AmzChecksumMode), s3_constants.ChecksumModeEnabled) &&
+		r.Header.Get("Range") == "" && r.URL.Query().Get("partNumber") == "" {
+		return
+	}
+	for header := range resp.Header {
+		if strings.HasPrefix(header, s3_constants.AmzChecksumPrefix) {
+			delete(resp.Header, header)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package s3api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+func checksumOf(algorithm ChecksumAlgorithm, data string) string {
+	hasher := getCheckSumWriter(algorithm)
+	hasher.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(hasher.Sum(nil))
+}
+
+func TestNewObjectChecksum(t *testing.T) {
+	crc32Value := checksumOf(ChecksumAlgorithmCRC32, "hello")
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		defaultAlg    ChecksumAlgorithm
+		wantAlgorithm ChecksumAlgorithm
+		wantExpected  string
+		wantErr       s3err.ErrorCode
+	}{
+		{name: "none"},
+		{name: "header", headers: map[string]string{"x-amz-checksum-crc32": crc32Value}, wantAlgorithm: ChecksumAlgorithmCRC32, wantExpected: crc32Value},
+		{name: "trailer", headers: map[string]string{"x-amz-trailer": "x-amz-checksum-sha256"}, wantAlgorithm: ChecksumAlgorithmSHA256},
+		{name: "sdk algorithm", headers: map[string]string{"x-amz-sdk-checksum-algorithm": "crc64nvme"}, wantAlgorithm: ChecksumAlgorithmCRC64NVMe},
+		{name: "upload algorithm", defaultAlg: ChecksumAlgorithmCRC32C, wantAlgorithm: ChecksumAlgorithmCRC32C},
+		{name: "invalid value", headers: map[string]string{"x-amz-checksum-crc32": "aGVsbG8gd29ybGQ="}, wantErr: s3err.ErrInvalidDigest},
+		{name: "two headers", headers: map[string]string{"x-amz-checksum-crc32": crc32Value, "x-amz-checksum-crc32c": crc32Value}, wantErr: s3err.ErrInvalidRequest},
+		{name: "trailer mismatch", headers: map[string]string{"x-amz-checksum-crc32": crc32Value, "x-amz-trailer": "x-amz-checksum-sha1"}, wantErr: s3err.ErrInvalidRequest},
+		{name: "sdk mismatch", headers: map[string]string{"x-amz-checksum-crc32": crc32Value, "x-amz-sdk-checksum-algorithm": "SHA1"}, wantErr: s3err.ErrInvalidRequest},
+		{name: "upload mismatch", headers: map[string]string{"x-amz-checksum-crc32": crc32Value}, defaultAlg: ChecksumAlgorithmSHA1, wantErr: s3err.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/bucket/object", nil)
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		checksum, errCode := newObjectChecksum(r, tt.defaultAlg)
+		if errCode != tt.wantErr {
+			t.Errorf("%s: error %v, want %v", tt.name, errCode, tt.wantErr)
+			continue
+		}
+		if errCode != s3err.ErrNone {
+			continue
+		}
+		if tt.wantAlgorithm == ChecksumAlgorithmNone {
+			if checksum != nil {
+				t.Errorf("%s: unexpected checksum %+v", tt.name, checksum)
+			}
+			continue
+		}
+		if checksum == nil || checksum.algorithm != tt.wantAlgorithm || checksum.expected != tt.wantExpected {
+			t.Errorf("%s: unexpected checksum %+v", tt.name, checksum)
+		}
+	}
+}
+
+func TestChecksumReaderTrailer(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		received = r.Trailer
+	}))
+	defer server.Close()
+
+	data := strings.Repeat("seaweedfs", 1000)
+	checksum := &objectChecksum{algorithm: ChecksumAlgorithmCRC32C, hasher: getCheckSumWriter(ChecksumAlgorithmCRC32C)}
+	trailer := http.Header{}
+	req, err := http.NewRequest(http.MethodPut, server.URL, checksum.reader(io.NopCloser(strings.NewReader(data)), trailer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Trailer = trailer
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	want := checksumOf(ChecksumAlgorithmCRC32C, data)
+	if checksum.value != want {
+		t.Errorf("computed %s, want %s", checksum.value, want)
+	}
+	if got := received.Get(s3_constants.AmzChecksumCRC32C); got != want {
+		t.Errorf("trailer %s = %q, want %q", s3_constants.AmzChecksumCRC32C, got, want)
+	}
+	if got := received.Get(s3_constants.AmzChecksumType); got != s3_constants.ChecksumTypeFullObject {
+		t.Errorf("trailer %s = %q", s3_constants.AmzChecksumType, got)
+	}
+
+	mismatch := &objectChecksum{algorithm: ChecksumAlgorithmSHA1, expected: checksumOf(ChecksumAlgorithmSHA1, "other"), hasher: getCheckSumWriter(ChecksumAlgorithmSHA1)}
+	if _, err := io.ReadAll(mismatch.reader(bytes.NewReader([]byte(data)), http.Header{})); err == nil || err.Error() != s3err.ErrMsgPayloadChecksumMismatch {
+		t.Errorf("expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestCrcCombine(t *testing.T) {
+	first, second := strings.Repeat("a", 1000), strings.Repeat("bc", 3333)
+	for _, algorithm := range []ChecksumAlgorithm{ChecksumAlgorithmCRC32, ChecksumAlgorithmCRC32C, ChecksumAlgorithmCRC64NVMe} {
+		whole := checksumOf(algorithm, first+second)
+		combined, err := multipartChecksum(algorithm, s3_constants.ChecksumTypeFullObject, []partChecksum{
+			{value: checksumOf(algorithm, first), size: int64(len(first))},
+			{value: checksumOf(algorithm, second), size: int64(len(second))},
+			{value: checksumOf(algorithm, ""), size: 0},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", algorithm.Name(), err)
+		}
+		if combined != whole {
+			t.Errorf("%s: combined %s, want %s", algorithm.Name(), combined, whole)
+		}
+	}
+}
+
+func TestCompositeChecksum(t *testing.T) {
+	parts := []string{"part one", "part two"}
+	var concatenated []byte
+	var checksums []partChecksum
+	for _, part := range parts {
+		value := checksumOf(ChecksumAlgorithmSHA256, part)
+		raw, _ := base64.StdEncoding.DecodeString(value)
+		concatenated = append(concatenated, raw...)
+		checksums = append(checksums, partChecksum{value: value, size: int64(len(part))})
+	}
+	got, err := multipartChecksum(ChecksumAlgorithmSHA256, s3_constants.ChecksumTypeComposite, checksums)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := checksumOf(ChecksumAlgorithmSHA256, string(concatenated)) + "-2"; got != want {
+		t.Errorf("composite checksum %s, want %s", got, want)
+	}
+
+	checksums[1].value = "invalid"
+	if _, err := multipartChecksum(ChecksumAlgorithmSHA256, s3_constants.ChecksumTypeComposite, checksums); err == nil {
+		t.Errorf("expected an error for an invalid part checksum")
+	}
+}
+
+func TestParseMultipartChecksum(t *testing.T) {
+	tests := []struct {
+		algorithm, checksumType string
+		wantAlgorithm           ChecksumAlgorithm
+		wantType                string
+		wantErr                 s3err.ErrorCode
+	}{
+		{},
+		{algorithm: "SHA256", wantAlgorithm: ChecksumAlgorithmSHA256, wantType: s3_constants.ChecksumTypeComposite},
+		{algorithm: "CRC64NVME", wantAlgorithm: ChecksumAlgorithmCRC64NVMe, wantType: s3_constants.ChecksumTypeFullObject},
+		{algorithm: "CRC32", checksumType: "FULL_OBJECT", wantAlgorithm: ChecksumAlgorithmCRC32, wantType: s3_constants.ChecksumTypeFullObject},
+		{algorithm: "SHA1", checksumType: "FULL_OBJECT", wantErr: s3err.ErrInvalidRequest},
+		{algorithm: "CRC64NVME", checksumType: "COMPOSITE", wantErr: s3err.ErrInvalidRequest},
+		{algorithm: "MD5", wantErr: s3err.ErrInvalidRequest},
+		{checksumType: "COMPOSITE", wantErr: s3err.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.algorithm != "" {
+			header.Set(s3_constants.AmzChecksumAlgorithm, tt.algorithm)
+		}
+		if tt.checksumType != "" {
+			header.Set(s3_constants.AmzChecksumType, tt.checksumType)
+		}
+		algorithm, checksumType, errCode := parseMultipartChecksum(header)
+		if errCode != tt.wantErr || algorithm != tt.wantAlgorithm || checksumType != tt.wantType {
+			t.Errorf("%s %s: got %s %s %v", tt.algorithm, tt.checksumType, algorithm.Name(), checksumType, errCode)
+		}
+	}
+}
+
+func TestFilterChecksumHeaders(t *testing.T) {
+	newResponse := func() *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set(s3_constants.AmzChecksumCRC32, "AAAAAA==")
+		resp.Header.Set(s3_constants.AmzChecksumType, s3_constants.ChecksumTypeFullObject)
+		return resp
+	}
+	tests := []struct {
+		name    string
+		url     string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "default", url: "/bucket/object"},
+		{name: "enabled", url: "/bucket/object", headers: map[string]string{"x-amz-checksum-mode": "ENABLED"}, want: true},
+		{name: "range", url: "/bucket/object", headers: map[string]string{"x-amz-checksum-mode": "ENABLED", "Range": "bytes=0-1"}},
+		{name: "part", url: "/bucket/object?partNumber=1", headers: map[string]string{"x-amz-checksum-mode": "ENABLED"}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+		for k, v := range tt.headers {
+			r.Header.Set(k, v)
+		}
+		resp := newResponse()
+		filterChecksumHeaders(r, resp)
+		if got := resp.Header.Get(s3_constants.AmzChecksumCRC32) != ""; got != tt.want {
+			t.Errorf("%s: checksum returned %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package s3api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	objectWriteLockPrefix = "s3.objectWrite:"
+	// short lived locks expire after 5 seconds
+	objectWriteLockTTL = 5 * time.Second
+)
+
+var (
+	// objectWriteLockTimeout gives up waiting for other writes to the same object, and lets the client retry
+	objectWriteLockTimeout = 15 * time.Second
+	// objectWriteLockRenew renews the lock well before it expires
+	objectWriteLockRenew = 2 * time.Second
+)
+
+// errObjectWriteLockLost cancels a write that lost the object lock, since the conditional headers may no longer hold
+var errObjectWriteLockLost = errors.New("object write lock lost")
+
+// lockObjectWrite serializes the conditional writes to the same object through the filer's distributed lock manager,
+// so the conditional headers checked while holding the lock still hold when the object is written.
+// Other writes only take the lock while a conditional write to the same object is in flight, so they are not
+// written between its check and its write.
+// The lock is renewed until unlocked, so slow uploads keep it. If it can not be renewed, the context of the
+// returned request is cancelled, and the write fails, see objectWriteLockLost.
+func (s3a *S3ApiServer) lockObjectWrite(r *http.Request, bucket, object string) (lockedReq *http.Request, unlock func(), errCode s3err.ErrorCode) {
+	if !strings.HasPrefix(object, "/") {
+		object = "/" + object
+	}
+	key := objectWriteLockPrefix + s3a.option.BucketsPath + "/" + bucket + object
+	if headers, _ := parseConditionalHeaders(r); !headers.isSet {
+		owner, err := s3a.lockClient.FindLockOwner(key)
+		if err != nil {
+			glog.Warningf("lockObjectWrite: find lock owner of %s: %v", key, err)
+		}
+		if err == nil && owner == "" {
+			return r, func() {}, s3err.ErrNone
+		}
+	}
+	self := fmt.Sprintf("s3@%s:%d-%d", util.DetectedHostAddress(), s3a.option.Port, s3a.randomClientId)
+	lock, err := s3a.lockClient.NewShortLivedLockWithTimeout(key, self, objectWriteLockTimeout)
+	if err != nil {
+		glog.Warningf("lockObjectWrite: %v", err)
+		return r, nil, s3err.ErrSlowDown
+	}
+	glog.V(3).Infof("lockObjectWrite: locked %s", key)
+
+	ctx, cancel := context.WithCancelCause(r.Context())
+	stopCh := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(objectWriteLockRenew)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+				if err := lock.AttemptToLock(objectWriteLockTTL); err != nil {
+					glog.Warningf("lockObjectWrite: renew lock %s: %v", key, err)
+					cancel(errObjectWriteLockLost)
+					return
+				}
+			}
+		}
+	}()
+
+	return r.WithContext(ctx), func() {
+		close(stopCh)
+		wg.Wait()
+		cancel(nil)
+		if err := lock.StopShortLivedLock(); err != nil {
+			glog.Warningf("lockObjectWrite: unlock %s: %v", key, err)
+		}
+	}, s3err.ErrNone
+}
+
+// objectWriteLockLost is true if the write lost the lock taken by lockObjectWrite, and must fail
+func objectWriteLockLost(r *http.Request) bool {
+	return errors.Is(context.Cause(r.Context()), errObjectWriteLockLost)
+}
+
+// conditionalEntryGetter resolves the current version of an object for conditional writes,
+// following the latest version of objects in versioned buckets
+type conditionalEntryGetter struct {
+	s3a    *S3ApiServer
+	bucket string
+}
+
+func (g *conditionalEntryGetter) getEntry(parentDirectoryPath, entryName string) (*filer_pb.Entry, error) {
+	versioningState, err := g.s3a.getVersioningState(g.bucket)
+	if err != nil || versioningState == "" {
+		return g.s3a.getEntry(parentDirectoryPath, entryName)
+	}
+	entry, err := g.s3a.getLatestObjectVersion(g.bucket, entryName)
+	if err != nil {
+		return nil, err
+	}
+	// a delete marker as the latest version means the object does not exist
+	if entry.Extended != nil && string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true" {
+		return nil, filer_pb.ErrNotFound
+	}
+	return entry, nil
+}
@@ -246,6 +246,11 @@ func (s3a *S3ApiServer) GetObjectHandler(w http.ResponseWriter, r *http.Request)
 		return // Directory object request was handled
 	}
 
+	if errCode := checkPartNumberRequest(r); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
 	// Check conditional headers for read operations
 	result := s3a.checkConditionalHeadersForReads(r, bucket, object)
 	if result.ErrorCode != s3err.ErrNone {
@@ -344,6 +349,23 @@ func (s3a *S3ApiServer) GetObjectHandler(w http.ResponseWriter, r *http.Request)
 		destUrl = s3a.toFilerUrl(bucket, object)
 	}
 
+	// Stream the object through the transformation of its prefix, as a whole
+	pipeline, transformObject, errCode := s3a.getObjectTransformation(r, bucket, object)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if pipeline != nil {
+		if r.URL.Query().Get("partNumber") != "" {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+			return
+		}
+		r.Header.Del("Range")
+		tw := newTransformResponseWriter(w, r, pipeline, transformObject)
+		defer tw.finish()
+		w = tw
+	}
+
 	// Check if this is a range request to an SSE object and modify the approach
 	originalRangeHeader := r.Header.Get("Range")
 	var sseObject = false
@@ -392,6 +414,11 @@ func (s3a *S3ApiServer) HeadObjectHandler(w http.ResponseWriter, r *http.Request
 		return // Directory object request was handled
 	}
 
+	if errCode := checkPartNumberRequest(r); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
 	// Check conditional headers for read operations
 	result := s3a.checkConditionalHeadersForReads(r, bucket, object)
 	if result.ErrorCode != s3err.ErrNone {
@@ -488,6 +515,16 @@ func (s3a *S3ApiServer) HeadObjectHandler(w http.ResponseWriter, r *http.Request
 		destUrl = s3a.toFilerUrl(bucket, object)
 	}
 
+	// The size and checksums of transformed objects are not known before they are transformed
+	pipeline, transformObject, errCode := s3a.getObjectTransformation(r, bucket, object)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if pipeline != nil {
+		w = newTransformResponseWriter(w, r, pipeline, transformObject)
+	}
+
 	s3a.proxyToFiler(w, r, destUrl, false, func(proxyResponse *http.Response, w http.ResponseWriter) (statusCode int, bytesTransferred int64) {
 		// Handle SSE validation (both SSE-C and SSE-KMS) for HEAD requests
 		return s3a.handleSSEResponse(r, proxyResponse, w)
@@ -582,7 +619,11 @@ func (s3a *S3ApiServer) proxyToFiler(w http.ResponseWriter, r *http.Request, des
 		resp_body, _ := io.ReadAll(resp.Body)
 		switch string(resp_body) {
 		case "InvalidPart":
-			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidPart)
+			if r.URL.Query().Get("partNumber") != "" {
+				s3err.WriteErrorResponse(w, r, s3err.ErrInvalidPartNumber)
+			} else {
+				s3err.WriteErrorResponse(w, r, s3err.ErrInvalidPart)
+			}
 		default:
 			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
 		}
@@ -591,6 +632,7 @@ func (s3a *S3ApiServer) proxyToFiler(w http.ResponseWriter, r *http.Request, des
 	}
 
 	setUserMetadataKeyToLowercase(resp)
+	filterChecksumHeaders(r, resp)
 
 	responseStatusCode, bytesTransferred := responseFn(resp, w)
 	BucketTrafficSent(bytesTransferred, r)
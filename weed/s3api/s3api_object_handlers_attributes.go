@@ -0,0 +1,231 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3parts"
+)
+
+// maxPartNumber is the largest part number of a multipart upload
+const maxPartNumber = 10000
+
+// object attributes that can be requested with x-amz-object-attributes
+const (
+	objectAttributeETag         = "ETag"
+	objectAttributeChecksum     = "Checksum"
+	objectAttributeObjectParts  = "ObjectParts"
+	objectAttributeStorageClass = "StorageClass"
+	objectAttributeObjectSize   = "ObjectSize"
+)
+
+// GetObjectAttributesResponse is the XML model of a GetObjectAttributes response
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectAttributes.html
+type GetObjectAttributesResponse struct {
+	XMLName      xml.Name                  `xml:"http://s3.amazonaws.com/doc/2006-03-01/ GetObjectAttributesResponse"`
+	ETag         string                    `xml:"ETag,omitempty"`
+	Checksum     *ObjectAttributesChecksum `xml:"Checksum,omitempty"`
+	ObjectParts  *ObjectAttributesParts    `xml:"ObjectParts,omitempty"`
+	StorageClass string                    `xml:"StorageClass,omitempty"`
+	ObjectSize   *int64                    `xml:"ObjectSize,omitempty"`
+}
+
+// ObjectAttributesChecksums holds the checksum of one algorithm, of the object or of a part
+type ObjectAttributesChecksums struct {
+	ChecksumCRC32     string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string `xml:"ChecksumSHA256,omitempty"`
+}
+
+type ObjectAttributesChecksum struct {
+	ObjectAttributesChecksums
+	ChecksumType string `xml:"ChecksumType,omitempty"`
+}
+
+type ObjectAttributesParts struct {
+	TotalPartsCount      int                    `xml:"PartsCount"`
+	PartNumberMarker     int                    `xml:"PartNumberMarker"`
+	NextPartNumberMarker int                    `xml:"NextPartNumberMarker"`
+	MaxParts             int                    `xml:"MaxParts"`
+	IsTruncated          bool                   `xml:"IsTruncated"`
+	Parts                []ObjectAttributesPart `xml:"Part"`
+}
+
+type ObjectAttributesPart struct {
+	PartNumber int   `xml:"PartNumber"`
+	Size       int64 `xml:"Size"`
+	ObjectAttributesChecksums
+}
+
+func (c *ObjectAttributesChecksums) set(algorithm ChecksumAlgorithm, value string) {
+	switch algorithm {
+	case ChecksumAlgorithmCRC32:
+		c.ChecksumCRC32 = value
+	case ChecksumAlgorithmCRC32C:
+		c.ChecksumCRC32C = value
+	case ChecksumAlgorithmCRC64NVMe:
+		c.ChecksumCRC64NVME = value
+	case ChecksumAlgorithmSHA1:
+		c.ChecksumSHA1 = value
+	case ChecksumAlgorithmSHA256:
+		c.ChecksumSHA256 = value
+	}
+}
+
+// parseObjectAttributes reads the attributes requested in the x-amz-object-attributes headers
+func parseObjectAttributes(values []string) (map[string]bool, s3err.ErrorCode) {
+	attributes := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			switch name {
+			case objectAttributeETag, objectAttributeChecksum, objectAttributeObjectParts, objectAttributeStorageClass, objectAttributeObjectSize:
+				attributes[name] = true
+			case "":
+			default:
+				return nil, s3err.ErrInvalidObjectAttributes
+			}
+		}
+	}
+	if len(attributes) == 0 {
+		return nil, s3err.ErrInvalidObjectAttributes
+	}
+	return attributes, s3err.ErrNone
+}
+
+// checkPartNumberRequest validates the partNumber of a GET or HEAD object request
+func checkPartNumberRequest(r *http.Request) s3err.ErrorCode {
+	value := r.URL.Query().Get("partNumber")
+	if value == "" {
+		return s3err.ErrNone
+	}
+	partNumber, err := strconv.Atoi(value)
+	if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+		return s3err.ErrInvalidPartNumberArgument
+	}
+	if r.Header.Get("Range") != "" {
+		return s3err.ErrInvalidRequest
+	}
+	return s3err.ErrNone
+}
+
+// objectAttributes builds the GetObjectAttributes response of an entry.
+// Parts are only listed for multipart objects whose part boundaries were recorded on completion.
+func objectAttributes(entry *filer_pb.Entry, attributes map[string]bool, partNumberMarker, maxParts int) (*GetObjectAttributesResponse, error) {
+	response := &GetObjectAttributesResponse{}
+	if attributes[objectAttributeETag] {
+		response.ETag = filer.ETag(entry)
+	}
+	algorithm, value := storedChecksum(entry)
+	if attributes[objectAttributeChecksum] && algorithm != ChecksumAlgorithmNone {
+		response.Checksum = &ObjectAttributesChecksum{
+			ChecksumType: string(entry.Extended[s3_constants.AmzChecksumType]),
+		}
+		if response.Checksum.ChecksumType == "" {
+			response.Checksum.ChecksumType = s3_constants.ChecksumTypeFullObject
+		}
+		response.Checksum.set(algorithm, value)
+	}
+	if encodedParts, found := entry.Extended[s3_constants.SeaweedFSMultipartParts]; attributes[objectAttributeObjectParts] && found {
+		parts, err := s3parts.Decode(encodedParts)
+		if err != nil {
+			return nil, err
+		}
+		page, isTruncated := s3parts.Page(parts, partNumberMarker, maxParts)
+		response.ObjectParts = &ObjectAttributesParts{
+			TotalPartsCount:  len(parts),
+			PartNumberMarker: partNumberMarker,
+			MaxParts:         maxParts,
+			IsTruncated:      isTruncated,
+		}
+		for _, part := range page {
+			attributesPart := ObjectAttributesPart{
+				PartNumber: part.PartNumber,
+				Size:       part.Size,
+			}
+			if part.Checksum != "" {
+				attributesPart.set(algorithm, part.Checksum)
+			}
+			response.ObjectParts.Parts = append(response.ObjectParts.Parts, attributesPart)
+			response.ObjectParts.NextPartNumberMarker = part.PartNumber
+		}
+	}
+	if attributes[objectAttributeStorageClass] {
+		response.StorageClass = "STANDARD"
+		if storageClass, found := entry.Extended[s3_constants.AmzStorageClass]; found {
+			response.StorageClass = string(storageClass)
+		}
+	}
+	if attributes[objectAttributeObjectSize] {
+		size := int64(filer.FileSize(entry))
+		response.ObjectSize = &size
+	}
+	return response, nil
+}
+
+// GetObjectAttributesHandler Get the attributes of an object without its data
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetObjectAttributes.html
+func (s3a *S3ApiServer) GetObjectAttributesHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, object := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetObjectAttributesHandler %s %s", bucket, object)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	attributes, errCode := parseObjectAttributes(r.Header.Values(s3_constants.AmzObjectAttributes))
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	maxParts := int(maxPartsList)
+	if value := r.Header.Get(s3_constants.AmzMaxParts); value != "" {
+		var err error
+		if maxParts, err = strconv.Atoi(value); err != nil || maxParts < 0 {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidMaxParts)
+			return
+		}
+		if maxParts > maxPartsList {
+			maxParts = maxPartsList
+		}
+	}
+	var partNumberMarker int
+	if value := r.Header.Get(s3_constants.AmzPartNumberMarker); value != "" {
+		var err error
+		if partNumberMarker, err = strconv.Atoi(value); err != nil || partNumberMarker < 0 {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidPartNumberMarker)
+			return
+		}
+	}
+
+	versionId := r.URL.Query().Get("versionId")
+	entry, err := s3a.getObjectEntry(bucket, object, versionId)
+	if err != nil || entry.IsDirectory || string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true" {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchKey)
+		return
+	}
+
+	response, err := objectAttributes(entry, attributes, partNumberMarker, maxParts)
+	if err != nil {
+		glog.Errorf("GetObjectAttributesHandler %s %s: %v", bucket, object, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+
+	if entryVersionId, found := entry.Extended[s3_constants.ExtVersionIdKey]; found {
+		w.Header().Set("x-amz-version-id", string(entryVersionId))
+	}
+	w.Header().Set("Last-Modified", time.Unix(entry.Attributes.GetMtime(), 0).UTC().Format(http.TimeFormat))
+	writeSuccessResponseXML(w, r, response)
+}
@@ -0,0 +1,97 @@
+package s3api
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3parts"
+)
+
+func TestParseObjectAttributes(t *testing.T) {
+	attributes, errCode := parseObjectAttributes([]string{"ETag, ObjectSize", "ObjectParts"})
+	if errCode != s3err.ErrNone || len(attributes) != 3 || !attributes["ETag"] || !attributes["ObjectSize"] || !attributes["ObjectParts"] {
+		t.Errorf("unexpected attributes %v %v", attributes, errCode)
+	}
+	if _, errCode := parseObjectAttributes(nil); errCode != s3err.ErrInvalidObjectAttributes {
+		t.Errorf("expected an error without attributes, got %v", errCode)
+	}
+	if _, errCode := parseObjectAttributes([]string{"ETag,Owner"}); errCode != s3err.ErrInvalidObjectAttributes {
+		t.Errorf("expected an error for an unknown attribute, got %v", errCode)
+	}
+}
+
+func TestCheckPartNumberRequest(t *testing.T) {
+	tests := []struct {
+		query string
+		rng   string
+		want  s3err.ErrorCode
+	}{
+		{"", "", s3err.ErrNone},
+		{"partNumber=2", "", s3err.ErrNone},
+		{"partNumber=0", "", s3err.ErrInvalidPartNumberArgument},
+		{"partNumber=10001", "", s3err.ErrInvalidPartNumberArgument},
+		{"partNumber=a", "", s3err.ErrInvalidPartNumberArgument},
+		{"partNumber=1", "bytes=0-9", s3err.ErrInvalidRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/key?"+tt.query, nil)
+		if tt.rng != "" {
+			r.Header.Set("Range", tt.rng)
+		}
+		if got := checkPartNumberRequest(r); got != tt.want {
+			t.Errorf("%q %q: got %v, want %v", tt.query, tt.rng, got, tt.want)
+		}
+	}
+}
+
+func TestObjectAttributes(t *testing.T) {
+	encodedParts, err := s3parts.Encode([]s3parts.Part{
+		{PartNumber: 1, Offset: 0, Size: 5, Checksum: "AAAAAQ=="},
+		{PartNumber: 2, Offset: 5, Size: 5, Checksum: "AAAAAg=="},
+		{PartNumber: 3, Offset: 10, Size: 2, Checksum: "AAAAAw=="},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &filer_pb.Entry{
+		Attributes: &filer_pb.FuseAttributes{FileSize: 12, Md5: []byte{0xab, 0xcd}},
+		Extended: map[string][]byte{
+			s3_constants.AmzChecksumCRC32:        []byte("AAAABA==-3"),
+			s3_constants.AmzChecksumType:         []byte(s3_constants.ChecksumTypeComposite),
+			s3_constants.SeaweedFSMultipartParts: encodedParts,
+		},
+	}
+	all := map[string]bool{"ETag": true, "Checksum": true, "ObjectParts": true, "StorageClass": true, "ObjectSize": true}
+	response, err := objectAttributes(entry, all, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := xml.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<GetObjectAttributesResponse xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><ETag>abcd</ETag>` +
+		`<Checksum><ChecksumCRC32>AAAABA==-3</ChecksumCRC32><ChecksumType>COMPOSITE</ChecksumType></Checksum>` +
+		`<ObjectParts><PartsCount>3</PartsCount><PartNumberMarker>1</PartNumberMarker><NextPartNumberMarker>2</NextPartNumberMarker>` +
+		`<MaxParts>1</MaxParts><IsTruncated>true</IsTruncated><Part><PartNumber>2</PartNumber><Size>5</Size><ChecksumCRC32>AAAAAg==</ChecksumCRC32></Part></ObjectParts>` +
+		`<StorageClass>STANDARD</StorageClass><ObjectSize>12</ObjectSize></GetObjectAttributesResponse>`
+	if string(out) != want {
+		t.Errorf("unexpected response\n%s\nwant\n%s", out, want)
+	}
+
+	// only the requested attributes are returned, and objects uploaded in one request have no parts
+	delete(entry.Extended, s3_constants.SeaweedFSMultipartParts)
+	response, err = objectAttributes(entry, map[string]bool{"ObjectParts": true, "ObjectSize": true}, 0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, _ = xml.Marshal(response); strings.Contains(string(out), "ObjectParts") || strings.Contains(string(out), "ETag") || !strings.Contains(string(out), "<ObjectSize>12</ObjectSize>") {
+		t.Errorf("unexpected response %s", out)
+	}
+}
@@ -61,6 +61,18 @@ func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if errCode := checkStorageClass(r.Header); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	r, unlock, errCode := s3a.lockObjectWrite(r, dstBucket, dstObject)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	defer unlock()
+
 	replaceMeta, replaceTagging := replaceDirective(r.Header)
 
 	if (srcBucket == dstBucket && srcObject == dstObject || cpSrcPath == "") && (replaceMeta || replaceTagging) {
@@ -83,6 +95,10 @@ func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request
 			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidCopySource)
 			return
 		}
+		if objectWriteLockLost(r) {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+			return
+		}
 		writeSuccessResponseXML(w, r, CopyObjectResult{
 			ETag:         fmt.Sprintf("%x", entry.Attributes.Md5),
 			LastModified: time.Now().UTC(),
@@ -326,6 +342,10 @@ func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request
 		}
 		etag = filer.ETagEntry(filerEntry)
 	}
+	if objectWriteLockLost(r) {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
 
 	setEtag(w, etag)
 
@@ -657,6 +677,13 @@ func processMetadataBytes(reqHeader http.Header, existing map[string][]byte, rep
 		}
 	}
 
+	// the content is copied as is, and so are its additional checksums
+	for k, v := range existing {
+		if strings.HasPrefix(k, s3_constants.AmzChecksumPrefix) {
+			metadata[k] = v
+		}
+	}
+
 	if replaceMeta {
 		for header, values := range reqHeader {
 			if strings.HasPrefix(header, s3_constants.AmzUserMetaPrefix) {
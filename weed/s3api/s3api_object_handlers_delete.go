@@ -66,7 +66,7 @@ func (s3a *S3ApiServer) DeleteObjectHandler(w http.ResponseWriter, r *http.Reque
 			}
 
 			// Delete specific version
-			err := s3a.deleteSpecificObjectVersion(bucket, object, versionId)
+			err := s3a.deleteSpecificObjectVersion(bucket, object, versionId, governanceBypassAllowed)
 			if err != nil {
 				glog.Errorf("Failed to delete specific version %s: %v", versionId, err)
 				s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
@@ -104,7 +104,7 @@ func (s3a *S3ApiServer) DeleteObjectHandler(w http.ResponseWriter, r *http.Reque
 				}
 
 				// Delete the "null" version (the regular file)
-				err := s3a.deleteSpecificObjectVersion(bucket, object, "null")
+				err := s3a.deleteSpecificObjectVersion(bucket, object, "null", governanceBypassAllowed)
 				if err != nil {
 					glog.Errorf("Failed to delete null version: %v", err)
 					s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
@@ -130,7 +130,7 @@ func (s3a *S3ApiServer) DeleteObjectHandler(w http.ResponseWriter, r *http.Reque
 
 		err := s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
 
-			if err := doDeleteEntry(client, dir, name, true, false); err != nil {
+			if err := doDeleteObjectEntry(client, dir, name, governanceBypassAllowed); err != nil {
 				return err
 			}
 
@@ -258,9 +258,10 @@ func (s3a *S3ApiServer) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *h
 			}
 
 			// Check object lock permissions before deletion (only for versioned buckets)
+			var governanceBypassAllowed bool
 			if versioningConfigured {
 				// Validate governance bypass for this specific object
-				governanceBypassAllowed := s3a.evaluateGovernanceBypassRequest(r, bucket, object.Key)
+				governanceBypassAllowed = s3a.evaluateGovernanceBypassRequest(r, bucket, object.Key)
 				if err := s3a.enforceObjectLockProtections(r, bucket, object.Key, object.VersionId, governanceBypassAllowed); err != nil {
 					glog.V(2).Infof("DeleteMultipleObjectsHandler: object lock check failed for %s/%s (version: %s): %v", bucket, object.Key, object.VersionId, err)
 					deleteErrors = append(deleteErrors, DeleteError{
@@ -280,7 +281,7 @@ func (s3a *S3ApiServer) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *h
 				// Handle versioned delete based on specific versioning state
 				if object.VersionId != "" {
 					// Delete specific version (same for both enabled and suspended)
-					err := s3a.deleteSpecificObjectVersion(bucket, object.Key, object.VersionId)
+					err := s3a.deleteSpecificObjectVersion(bucket, object.Key, object.VersionId, governanceBypassAllowed)
 					if err != nil {
 						deleteErrors = append(deleteErrors, DeleteError{
 							Code:      "",
@@ -311,7 +312,7 @@ func (s3a *S3ApiServer) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *h
 						// Suspended versioning: Actually delete the "null" version object
 						glog.V(2).Infof("DeleteMultipleObjectsHandler: deleting null version for suspended versioning %s/%s", bucket, object.Key)
 
-						err := s3a.deleteSpecificObjectVersion(bucket, object.Key, "null")
+						err := s3a.deleteSpecificObjectVersion(bucket, object.Key, "null", governanceBypassAllowed)
 						if err != nil {
 							deleteErrors = append(deleteErrors, DeleteError{
 								Code:      "",
@@ -60,11 +60,27 @@ func (s3a *S3ApiServer) NewMultipartUploadHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	if errCode := checkStorageClass(r.Header); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	checksumAlgorithm, checksumType, errCode := parseMultipartChecksum(r.Header)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
 	createMultipartUploadInput := &s3.CreateMultipartUploadInput{
 		Bucket:   aws.String(bucket),
 		Key:      objectKey(aws.String(object)),
 		Metadata: make(map[string]*string),
 	}
+	// the parts are checksummed with the algorithm of the upload
+	if checksumAlgorithm != ChecksumAlgorithmNone {
+		createMultipartUploadInput.Metadata[s3_constants.ExtChecksumAlgorithmKey] = aws.String(checksumAlgorithm.Name())
+		createMultipartUploadInput.Metadata[s3_constants.ExtChecksumTypeKey] = aws.String(checksumType)
+	}
 
 	metadata := weed_server.SaveAmzMetaData(r, nil, false)
 	for k, v := range metadata {
@@ -84,6 +100,11 @@ func (s3a *S3ApiServer) NewMultipartUploadHandler(w http.ResponseWriter, r *http
 		return
 	}
 
+	if checksumAlgorithm != ChecksumAlgorithmNone {
+		w.Header().Set(s3_constants.AmzChecksumAlgorithm, checksumAlgorithm.Name())
+		w.Header().Set(s3_constants.AmzChecksumType, checksumType)
+	}
+
 	writeSuccessResponseXML(w, r, response)
 
 }
@@ -116,6 +137,12 @@ func (s3a *S3ApiServer) CompleteMultipartUploadHandler(w http.ResponseWriter, r
 
 	// Check conditional headers before completing multipart upload
 	// This implements AWS S3 behavior where conditional headers apply to CompleteMultipartUpload
+	r, unlock, errCode := s3a.lockObjectWrite(r, bucket, object)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	defer unlock()
 	if errCode := s3a.checkConditionalHeaders(r, bucket, object); errCode != s3err.ErrNone {
 		glog.V(3).Infof("CompleteMultipartUploadHandler: Conditional header check failed for %s/%s", bucket, object)
 		s3err.WriteErrorResponse(w, r, errCode)
@@ -134,6 +161,10 @@ func (s3a *S3ApiServer) CompleteMultipartUploadHandler(w http.ResponseWriter, r
 		s3err.WriteErrorResponse(w, r, errCode)
 		return
 	}
+	if objectWriteLockLost(r) {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
 
 	// Set version ID in HTTP header if present
 	if response.VersionId != nil {
@@ -306,6 +337,24 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	glog.Infof("PutObjectPartHandler: attempting to retrieve upload entry for bucket %s, uploadID %s", bucket, uploadID)
+	uploadEntry, uploadEntryErr := s3a.getEntry(s3a.genUploadsFolder(bucket), uploadID)
+
+	// Parts are checksummed with the algorithm chosen when the upload was created
+	uploadChecksumAlgorithm := ChecksumAlgorithmNone
+	if uploadEntryErr == nil {
+		uploadChecksumAlgorithm, _ = checksumAlgorithmFromName(string(uploadEntry.Extended[s3_constants.ExtChecksumAlgorithmKey]))
+		// and placed according to the storage class of the upload
+		if storageClass, found := uploadEntry.Extended[s3_constants.AmzStorageClass]; found {
+			r.Header.Set(s3_constants.AmzStorageClass, string(storageClass))
+		}
+	}
+	checksum, s3ErrCode := newObjectChecksum(r, uploadChecksumAlgorithm)
+	if s3ErrCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, s3ErrCode)
+		return
+	}
+
 	dataReader, s3ErrCode := getRequestDataReader(s3a, r)
 	if s3ErrCode != s3err.ErrNone {
 		s3err.WriteErrorResponse(w, r, s3ErrCode)
@@ -322,8 +371,7 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 		// SSE-C part upload - headers are already present, let putToFiler handle it
 	} else {
 		// No SSE-C headers, check for SSE-KMS settings from upload directory
-		glog.Infof("PutObjectPartHandler: attempting to retrieve upload entry for bucket %s, uploadID %s", bucket, uploadID)
-		if uploadEntry, err := s3a.getEntry(s3a.genUploadsFolder(bucket), uploadID); err == nil {
+		if uploadEntryErr == nil {
 			glog.Infof("PutObjectPartHandler: upload entry found, Extended metadata: %v", uploadEntry.Extended != nil)
 			if uploadEntry.Extended != nil {
 				// Check if this upload uses SSE-KMS
@@ -396,7 +444,7 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 				}
 			}
 		} else {
-			glog.Infof("PutObjectPartHandler: failed to retrieve upload entry: %v", err)
+			glog.Infof("PutObjectPartHandler: failed to retrieve upload entry: %v", uploadEntryErr)
 		}
 	}
 
@@ -407,13 +455,14 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 	}
 	destination := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)
 
-	etag, errCode, _ := s3a.putToFiler(r, uploadUrl, dataReader, destination, bucket, partID)
+	etag, errCode, _ := s3a.putToFiler(r, uploadUrl, dataReader, destination, bucket, partID, checksum)
 	if errCode != s3err.ErrNone {
 		s3err.WriteErrorResponse(w, r, errCode)
 		return
 	}
 
 	setEtag(w, etag)
+	checksum.setResponseHeader(w)
 
 	writeSuccessResponseEmpty(w, r)
 
@@ -495,8 +544,30 @@ type CompleteMultipartUpload struct {
 	Parts []CompletedPart `xml:"Part"`
 }
 type CompletedPart struct {
-	ETag       string
-	PartNumber int
+	ETag              string
+	PartNumber        int
+	ChecksumCRC32     string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string `xml:"ChecksumSHA256,omitempty"`
+}
+
+// checksum returns the part checksum of the algorithm listed by the client, if any
+func (p CompletedPart) checksum(algorithm ChecksumAlgorithm) string {
+	switch algorithm {
+	case ChecksumAlgorithmCRC32:
+		return p.ChecksumCRC32
+	case ChecksumAlgorithmCRC32C:
+		return p.ChecksumCRC32C
+	case ChecksumAlgorithmCRC64NVMe:
+		return p.ChecksumCRC64NVME
+	case ChecksumAlgorithmSHA1:
+		return p.ChecksumSHA1
+	case ChecksumAlgorithmSHA256:
+		return p.ChecksumSHA256
+	}
+	return ""
 }
 
 // handleSSES3MultipartHeaders handles SSE-S3 multipart upload header setup to reduce nesting complexity
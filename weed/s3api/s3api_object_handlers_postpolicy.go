@@ -136,12 +136,23 @@ func (s3a *S3ApiServer) PostPolicyBucketHandler(w http.ResponseWriter, r *http.R
 		}
 	}
 
-	etag, errCode, _ := s3a.putToFiler(r, uploadUrl, fileBody, "", bucket, 1)
+	r, unlock, errCode := s3a.lockObjectWrite(r, bucket, object)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	defer unlock()
+
+	etag, errCode, _ := s3a.putToFiler(r, uploadUrl, fileBody, "", bucket, 1, nil)
 
 	if errCode != s3err.ErrNone {
 		s3err.WriteErrorResponse(w, r, errCode)
 		return
 	}
+	if objectWriteLockLost(r) {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
 
 	if successRedirect != "" {
 		// Replace raw query params..
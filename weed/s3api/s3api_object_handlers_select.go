@@ -0,0 +1,323 @@
+package s3api
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/query/s3select"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxSelectRequestSize limits the size of the SelectObjectContentRequest XML body
+const maxSelectRequestSize = 256 * 1024
+
+// SelectObjectContentRequest - https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+type SelectObjectContentRequest struct {
+	XMLName         xml.Name `xml:"SelectObjectContentRequest"`
+	Expression      string   `xml:"Expression"`
+	ExpressionType  string   `xml:"ExpressionType"`
+	RequestProgress struct {
+		Enabled bool `xml:"Enabled"`
+	} `xml:"RequestProgress"`
+	InputSerialization struct {
+		CompressionType string `xml:"CompressionType"`
+		CSV             *struct {
+			AllowQuotedRecordDelimiter bool   `xml:"AllowQuotedRecordDelimiter"`
+			Comments                   string `xml:"Comments"`
+			FieldDelimiter             string `xml:"FieldDelimiter"`
+			FileHeaderInfo             string `xml:"FileHeaderInfo"`
+			QuoteCharacter             string `xml:"QuoteCharacter"`
+			QuoteEscapeCharacter       string `xml:"QuoteEscapeCharacter"`
+			RecordDelimiter            string `xml:"RecordDelimiter"`
+		} `xml:"CSV"`
+		JSON *struct {
+			Type string `xml:"Type"`
+		} `xml:"JSON"`
+		Parquet *struct{} `xml:"Parquet"`
+	} `xml:"InputSerialization"`
+	OutputSerialization struct {
+		CSV *struct {
+			FieldDelimiter       string `xml:"FieldDelimiter"`
+			QuoteCharacter       string `xml:"QuoteCharacter"`
+			QuoteEscapeCharacter string `xml:"QuoteEscapeCharacter"`
+			QuoteFields          string `xml:"QuoteFields"`
+			RecordDelimiter      string `xml:"RecordDelimiter"`
+		} `xml:"CSV"`
+		JSON *struct {
+			RecordDelimiter string `xml:"RecordDelimiter"`
+		} `xml:"JSON"`
+	} `xml:"OutputSerialization"`
+	ScanRange *struct {
+		Start *int64 `xml:"Start"`
+		End   *int64 `xml:"End"`
+	} `xml:"ScanRange"`
+}
+
+// toQueryRequest converts the S3 request into the volume server query, which is also
+// what the local evaluation uses.
+func (req *SelectObjectContentRequest) toQueryRequest() *volume_server_pb.QueryRequest {
+	query := &volume_server_pb.QueryRequest{
+		Expression: req.Expression,
+		InputSerialization: &volume_server_pb.QueryRequest_InputSerialization{
+			CompressionType: req.InputSerialization.CompressionType,
+		},
+		OutputSerialization: &volume_server_pb.QueryRequest_OutputSerialization{},
+	}
+	input, output := query.InputSerialization, query.OutputSerialization
+	if csv := req.InputSerialization.CSV; csv != nil {
+		input.CsvInput = &volume_server_pb.QueryRequest_InputSerialization_CSVInput{
+			FileHeaderInfo:             csv.FileHeaderInfo,
+			RecordDelimiter:            csv.RecordDelimiter,
+			FieldDelimiter:             csv.FieldDelimiter,
+			QuoteCharacter:             csv.QuoteCharacter,
+			QuoteEscapeCharacter:       csv.QuoteEscapeCharacter,
+			Comments:                   csv.Comments,
+			AllowQuotedRecordDelimiter: csv.AllowQuotedRecordDelimiter,
+		}
+	}
+	if json := req.InputSerialization.JSON; json != nil {
+		input.JsonInput = &volume_server_pb.QueryRequest_InputSerialization_JSONInput{Type: json.Type}
+	}
+	if req.InputSerialization.Parquet != nil {
+		input.ParquetInput = &volume_server_pb.QueryRequest_InputSerialization_ParquetInput{}
+	}
+	if csv := req.OutputSerialization.CSV; csv != nil {
+		output.CsvOutput = &volume_server_pb.QueryRequest_OutputSerialization_CSVOutput{
+			QuoteFields:          csv.QuoteFields,
+			RecordDelimiter:      csv.RecordDelimiter,
+			FieldDelimiter:       csv.FieldDelimiter,
+			QuoteCharacter:       csv.QuoteCharacter,
+			QuoteEscapeCharacter: csv.QuoteEscapeCharacter,
+		}
+	}
+	if json := req.OutputSerialization.JSON; json != nil {
+		output.JsonOutput = &volume_server_pb.QueryRequest_OutputSerialization_JSONOutput{RecordDelimiter: json.RecordDelimiter}
+	}
+	return query
+}
+
+// SelectObjectContentHandler filters the content of an object with a SQL expression.
+// The query is pushed down to a volume server holding all chunks of the object if possible,
+// otherwise the chunks are streamed through the gateway and evaluated here.
+func (s3a *S3ApiServer) SelectObjectContentHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, object := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("SelectObjectContentHandler %s %s", bucket, object)
+
+	if r.URL.Query().Get("select-type") != "2" {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRequest)
+		return
+	}
+
+	var req SelectObjectContentRequest
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxSelectRequestSize)).Decode(&req); err != nil {
+		glog.V(1).Infof("SelectObjectContentHandler %s %s: %v", bucket, object, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedXML)
+		return
+	}
+	if !strings.EqualFold(req.ExpressionType, "SQL") {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidExpressionType)
+		return
+	}
+	if req.ScanRange != nil {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
+		return
+	}
+
+	query := req.toQueryRequest()
+	sel, err := s3select.NewSelect(query.Expression, query.InputSerialization, query.OutputSerialization)
+	if err != nil {
+		glog.V(1).Infof("SelectObjectContentHandler %s %s: %v", bucket, object, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidSelectExpression)
+		return
+	}
+
+	entry, err := s3a.getObjectEntry(bucket, object, r.URL.Query().Get("versionId"))
+	if err != nil || entry.IsDirectory {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchKey)
+		return
+	}
+	if entry.Extended != nil && string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true" {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchKey)
+		return
+	}
+	if sseType := s3a.detectPrimarySSEType(entry); sseType != "None" {
+		// the records would have to be decrypted with the request's keys before filtering
+		glog.V(1).Infof("SelectObjectContentHandler %s %s: %s encrypted objects are not supported", bucket, object, sseType)
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	events := newSelectEventStreamWriter(w)
+
+	stats, pushedDown, err := s3a.selectOnVolumeServer(entry, query, events)
+	if !pushedDown && err == nil {
+		stats, err = s3a.selectLocally(entry, sel, events)
+	}
+	if err != nil {
+		glog.V(1).Infof("SelectObjectContentHandler %s %s: %v", bucket, object, err)
+		code := "InternalError"
+		var evalErr *s3select.EvaluationError
+		if errors.As(err, &evalErr) || status.Code(err) == codes.InvalidArgument {
+			code = "EvaluatorInvalidArguments"
+		}
+		events.writeError(code, err.Error())
+		s3err.PostLog(r, http.StatusOK, s3err.ErrInternalError)
+		return
+	}
+
+	if req.RequestProgress.Enabled {
+		events.writeProgress(stats.BytesScanned, stats.BytesProcessed, stats.BytesReturned)
+	}
+	events.writeStats(stats.BytesScanned, stats.BytesProcessed, stats.BytesReturned)
+	events.writeEnd()
+	BucketTrafficSent(stats.BytesReturned, r)
+	s3err.PostLog(r, http.StatusOK, s3err.ErrNone)
+}
+
+// selectLocally evaluates the query in the gateway, reading the object chunks from the volume servers.
+func (s3a *S3ApiServer) selectLocally(entry *filer_pb.Entry, sel *s3select.Select, w io.Writer) (s3select.Stats, error) {
+	if len(entry.GetChunks()) == 0 {
+		return sel.RunAt(bytes.NewReader(entry.Content), int64(len(entry.Content)), w)
+	}
+	reader := filer.NewChunkStreamReader(s3a, entry.GetChunks())
+	defer reader.Close()
+	return sel.RunAt(reader, int64(filer.FileSize(entry)), w)
+}
+
+// selectOnVolumeServer runs the query on a volume server that has all chunks of the object,
+// so only matching records are sent back. It returns pushedDown=false if there is no such
+// server, or the chunks need processing in the gateway, e.g. manifests or encrypted chunks.
+func (s3a *S3ApiServer) selectOnVolumeServer(entry *filer_pb.Entry, query *volume_server_pb.QueryRequest, w io.Writer) (stats s3select.Stats, pushedDown bool, err error) {
+	fileIds, ok := selectPushDownFileIds(entry)
+	if !ok {
+		return
+	}
+	server, found, err := s3a.findVolumeServerWithAllChunks(fileIds)
+	if err != nil || !found {
+		glog.V(2).Infof("select push down is not possible for %s: %v", entry.Name, err)
+		return stats, false, nil
+	}
+	glog.V(3).Infof("select %s pushed down to %s", entry.Name, server)
+
+	query.FromFileIds = fileIds
+	err = operation.WithVolumeServerClient(true, server, s3a.option.GrpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
+		stream, queryErr := client.Query(context.Background(), query)
+		if queryErr != nil {
+			return queryErr
+		}
+		for {
+			stripe, recvErr := stream.Recv()
+			if recvErr == io.EOF {
+				return nil
+			}
+			if recvErr != nil {
+				return recvErr
+			}
+			if len(stripe.Records) > 0 {
+				n, writeErr := w.Write(stripe.Records)
+				stats.BytesReturned += int64(n)
+				if writeErr != nil {
+					return writeErr
+				}
+			}
+			if stripe.BytesScanned > 0 || stripe.BytesProcessed > 0 {
+				stats.BytesScanned, stats.BytesProcessed = stripe.BytesScanned, stripe.BytesProcessed
+			}
+		}
+	})
+	return stats, true, err
+}
+
+// selectPushDownFileIds returns the file ids of the object in order, if the chunks are plain
+// needles which together form the whole object without gaps or overlaps.
+func selectPushDownFileIds(entry *filer_pb.Entry) ([]string, bool) {
+	if len(entry.Content) > 0 || len(entry.GetChunks()) == 0 {
+		return nil, false
+	}
+	chunks := make([]*filer_pb.FileChunk, len(entry.GetChunks()))
+	copy(chunks, entry.GetChunks())
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].Offset < chunks[j].Offset
+	})
+
+	var fileIds []string
+	var offset int64
+	for _, chunk := range chunks {
+		if chunk.IsChunkManifest || len(chunk.CipherKey) > 0 || chunk.SseType != filer_pb.SSEType_NONE || chunk.Offset != offset {
+			return nil, false
+		}
+		fileIds = append(fileIds, chunk.GetFileIdString())
+		offset += int64(chunk.Size)
+	}
+	if offset != int64(filer.FileSize(entry)) {
+		return nil, false
+	}
+	return fileIds, true
+}
+
+// findVolumeServerWithAllChunks looks for a volume server that stores the volumes of all file ids
+func (s3a *S3ApiServer) findVolumeServerWithAllChunks(fileIds []string) (server pb.ServerAddress, found bool, err error) {
+	var volumeIds []string
+	seen := make(map[string]bool)
+	for _, fileId := range fileIds {
+		vid, _, parseErr := operation.ParseFileId(fileId)
+		if parseErr != nil {
+			return "", false, parseErr
+		}
+		if !seen[vid] {
+			seen[vid] = true
+			volumeIds = append(volumeIds, vid)
+		}
+	}
+
+	err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, lookupErr := client.LookupVolume(context.Background(), &filer_pb.LookupVolumeRequest{
+			VolumeIds: volumeIds,
+		})
+		if lookupErr != nil {
+			return fmt.Errorf("lookup volumes %v: %w", volumeIds, lookupErr)
+		}
+
+		counts := make(map[string]int)
+		var candidates []*filer_pb.Location
+		for i, vid := range volumeIds {
+			locations, ok := resp.LocationsMap[vid]
+			if !ok {
+				return nil
+			}
+			for _, location := range locations.Locations {
+				if counts[location.Url] == i {
+					counts[location.Url]++
+					if i == 0 {
+						candidates = append(candidates, location)
+					}
+				}
+			}
+		}
+		for _, location := range candidates {
+			if counts[location.Url] == len(volumeIds) {
+				server, found = pb.NewServerAddressWithGrpcPort(location.Url, int(location.GrpcPort)), true
+				return nil
+			}
+		}
+		return nil
+	})
+	return
+}
@@ -0,0 +1,134 @@
+package s3api
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/query/s3select"
+)
+
+type decodedEvent struct {
+	headers map[string]string
+	payload []byte
+}
+
+func decodeEventStream(t *testing.T, data []byte) []decodedEvent {
+	t.Helper()
+	var events []decodedEvent
+	for len(data) > 0 {
+		totalLength := binary.BigEndian.Uint32(data[0:4])
+		headersLength := binary.BigEndian.Uint32(data[4:8])
+		if crc32.ChecksumIEEE(data[0:8]) != binary.BigEndian.Uint32(data[8:12]) {
+			t.Fatalf("prelude crc mismatch")
+		}
+		message := data[:totalLength]
+		if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+			t.Fatalf("message crc mismatch")
+		}
+		event := decodedEvent{headers: make(map[string]string)}
+		headers := message[12 : 12+headersLength]
+		for len(headers) > 0 {
+			nameLength := int(headers[0])
+			name := string(headers[1 : 1+nameLength])
+			valueLength := int(binary.BigEndian.Uint16(headers[2+nameLength:]))
+			event.headers[name] = string(headers[4+nameLength : 4+nameLength+valueLength])
+			headers = headers[4+nameLength+valueLength:]
+		}
+		event.payload = message[12+headersLength : totalLength-4]
+		events = append(events, event)
+		data = data[totalLength:]
+	}
+	return events
+}
+
+func TestSelectEventStream(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	events := newSelectEventStreamWriter(recorder)
+	if _, err := events.Write([]byte("a,b\n")); err != nil {
+		t.Fatal(err)
+	}
+	events.writeStats(10, 20, 4)
+	events.writeEnd()
+
+	decoded := decodeEventStream(t, recorder.Body.Bytes())
+	if len(decoded) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(decoded))
+	}
+	if decoded[0].headers[":event-type"] != "Records" || string(decoded[0].payload) != "a,b\n" {
+		t.Errorf("unexpected records event %+v", decoded[0])
+	}
+	var stats struct {
+		BytesScanned   int64
+		BytesProcessed int64
+		BytesReturned  int64
+	}
+	if err := xml.Unmarshal(decoded[1].payload, &stats); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[1].headers[":event-type"] != "Stats" || stats.BytesScanned != 10 || stats.BytesProcessed != 20 || stats.BytesReturned != 4 {
+		t.Errorf("unexpected stats event %+v %+v", decoded[1].headers, stats)
+	}
+	if decoded[2].headers[":event-type"] != "End" || decoded[2].headers[":message-type"] != "event" {
+		t.Errorf("unexpected end event %+v", decoded[2])
+	}
+}
+
+func TestSelectObjectContentRequest(t *testing.T) {
+	body := `<SelectObjectContentRequest xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Expression>SELECT s._1 FROM S3Object s WHERE s._2 = 'error'</Expression>
+  <ExpressionType>SQL</ExpressionType>
+  <InputSerialization>
+    <CompressionType>GZIP</CompressionType>
+    <CSV><FileHeaderInfo>IGNORE</FileHeaderInfo><FieldDelimiter>|</FieldDelimiter></CSV>
+  </InputSerialization>
+  <OutputSerialization><JSON><RecordDelimiter>,</RecordDelimiter></JSON></OutputSerialization>
+</SelectObjectContentRequest>`
+	var req SelectObjectContentRequest
+	if err := xml.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatal(err)
+	}
+	query := req.toQueryRequest()
+	if query.InputSerialization.CompressionType != "GZIP" || query.InputSerialization.CsvInput.FieldDelimiter != "|" ||
+		query.InputSerialization.CsvInput.FileHeaderInfo != "IGNORE" || query.InputSerialization.JsonInput != nil {
+		t.Errorf("unexpected input serialization %v", query.InputSerialization)
+	}
+	if query.OutputSerialization.JsonOutput.GetRecordDelimiter() != "," || query.OutputSerialization.CsvOutput != nil {
+		t.Errorf("unexpected output serialization %v", query.OutputSerialization)
+	}
+	if _, err := s3select.NewSelect(query.Expression, query.InputSerialization, query.OutputSerialization); err != nil {
+		t.Errorf("NewSelect: %v", err)
+	}
+}
+
+func TestSelectPushDownFileIds(t *testing.T) {
+	entry := &filer_pb.Entry{
+		Attributes: &filer_pb.FuseAttributes{FileSize: 30},
+		Chunks: []*filer_pb.FileChunk{
+			{FileId: "3,02", Offset: 10, Size: 20},
+			{FileId: "3,01", Offset: 0, Size: 10},
+		},
+	}
+	fileIds, ok := selectPushDownFileIds(entry)
+	if !ok || len(fileIds) != 2 || fileIds[0] != "3,01" || fileIds[1] != "3,02" {
+		t.Errorf("expected ordered file ids, got %v %v", fileIds, ok)
+	}
+
+	entry.Chunks[0].Offset = 12
+	if _, ok := selectPushDownFileIds(entry); ok {
+		t.Errorf("chunks with a gap can not be pushed down")
+	}
+
+	entry.Chunks[0].Offset = 10
+	entry.Chunks[1].IsChunkManifest = true
+	if _, ok := selectPushDownFileIds(entry); ok {
+		t.Errorf("chunk manifests can not be pushed down")
+	}
+
+	if _, ok := selectPushDownFileIds(&filer_pb.Entry{Content: []byte("a,b")}); ok {
+		t.Errorf("inline content can not be pushed down")
+	}
+}
@@ -1,6 +1,7 @@
 package s3api
 
 import (
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -358,6 +359,7 @@ func (s3a *S3ApiServer) setObjectRetention(bucket, object, versionId string, ret
 	bucketDir := s3a.option.BucketsPath + "/" + bucket
 	return s3a.mkFile(bucketDir, entryPath, entry.Chunks, func(updatedEntry *filer_pb.Entry) {
 		updatedEntry.Extended = entry.Extended
+		updatedEntry.Content = entry.Content
 		updatedEntry.WormEnforcedAtTsNs = entry.WormEnforcedAtTsNs
 	})
 }
@@ -446,6 +448,7 @@ func (s3a *S3ApiServer) setObjectLegalHold(bucket, object, versionId string, leg
 	bucketDir := s3a.option.BucketsPath + "/" + bucket
 	return s3a.mkFile(bucketDir, entryPath, entry.Chunks, func(updatedEntry *filer_pb.Entry) {
 		updatedEntry.Extended = entry.Extended
+		updatedEntry.Content = entry.Content
 	})
 }
 
@@ -630,13 +633,26 @@ func (s3a *S3ApiServer) enforceObjectLockProtections(request *http.Request, buck
 				return ErrGovernanceModeActive
 			}
 			// Note: governanceBypassAllowed parameter is already validated by evaluateGovernanceBypassRequest()
-			// which checks both header presence and IAM permissions, so we trust it here
+			// which checks both header presence and IAM permissions, so we trust it here.
+			// The filer checks the retention again with the delete or overwrite, which passes the bypass.
 		}
 	}
 
 	return nil
 }
 
+type governanceBypassKey struct{}
+
+// withGovernanceBypass marks a permitted governance retention bypass on the request,
+// so the object is overwritten with the bypass, see putToFiler
+func withGovernanceBypass(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), governanceBypassKey{}, true))
+}
+
+func isGovernanceBypassed(r *http.Request) bool {
+	return r.Context().Value(governanceBypassKey{}) != nil
+}
+
 // ====================================================================
 // AVAILABILITY CHECKS
 // ====================================================================
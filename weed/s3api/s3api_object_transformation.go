@@ -0,0 +1,186 @@
+package s3api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3transform"
+)
+
+type accessPointKey struct{}
+
+// accessPointOf returns the access point the request was made through, "" for the bucket itself
+func accessPointOf(r *http.Request) string {
+	accessPoint, _ := r.Context().Value(accessPointKey{}).(string)
+	return accessPoint
+}
+
+// resolveAccessPointAlias serves requests to an access point alias, {bucket}--{access point}--ol-s3,
+// as read only requests to the bucket, with the transformations of the access point
+func (s3a *S3ApiServer) resolveAccessPointAlias(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		bucket, accessPoint, isAlias := s3transform.ParseAccessPointAlias(vars["bucket"])
+		if !isAlias {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			s3err.WriteErrorResponse(w, r, s3err.ErrMethodNotAllowed)
+			return
+		}
+		config, errCode := s3a.getBucketConfig(bucket)
+		if errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return
+		}
+		if !s3transform.HasAccessPoint(config.Transformation, accessPoint) {
+			s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchAccessPoint)
+			return
+		}
+		resolved := make(map[string]string, len(vars))
+		for k, v := range vars {
+			resolved[k] = v
+		}
+		resolved["bucket"] = bucket
+		r = mux.SetURLVars(r.WithContext(context.WithValue(r.Context(), accessPointKey{}, accessPoint)), resolved)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// transformOptions are the limits the gateway options set on transformations
+func (s3a *S3ApiServer) transformOptions() s3transform.Options {
+	return s3transform.Options{HttpEndpoints: s3a.option.TransformHttpEndpoints}
+}
+
+// getObjectTransformation returns the transformation of the object for the request, nil if none
+func (s3a *S3ApiServer) getObjectTransformation(r *http.Request, bucket, object string) (*s3transform.Pipeline, *s3transform.Object, s3err.ErrorCode) {
+	config, errCode := s3a.getBucketConfig(bucket)
+	if errCode != s3err.ErrNone {
+		return nil, nil, errCode
+	}
+	key := strings.TrimPrefix(object, "/")
+	rule := s3transform.MatchRule(config.Transformation, key, accessPointOf(r))
+	if rule == nil {
+		return nil, nil, s3err.ErrNone
+	}
+	pipeline, err := s3transform.NewPipeline(rule, s3a.transformOptions())
+	if err != nil {
+		glog.Errorf("transformation %s of bucket %s: %v", rule.Id, bucket, err)
+		return nil, nil, s3err.ErrObjectTransformationFailed
+	}
+	return pipeline, &s3transform.Object{
+		Bucket:      bucket,
+		Key:         key,
+		AccessPoint: accessPointOf(r),
+		Query:       r.URL.Query(),
+	}, s3err.ErrNone
+}
+
+// transformResponseWriter streams a successful GET response through a transformation.
+// Other responses are passed through. HEAD responses drop the headers of the original data.
+type transformResponseWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	pipeline    *s3transform.Pipeline
+	object      *s3transform.Object
+	wroteHeader bool
+	pipe        *io.PipeWriter
+	done        chan struct{}
+}
+
+func newTransformResponseWriter(w http.ResponseWriter, r *http.Request, pipeline *s3transform.Pipeline, object *s3transform.Object) *transformResponseWriter {
+	return &transformResponseWriter{ResponseWriter: w, r: r, pipeline: pipeline, object: object}
+}
+
+func (tw *transformResponseWriter) WriteHeader(statusCode int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	if statusCode != http.StatusOK {
+		tw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if tw.r.Method == http.MethodHead {
+		removeTransformedHeaders(tw.Header())
+		tw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	tw.object.ContentType = tw.Header().Get("Content-Type")
+	tw.object.ContentEncoding = tw.Header().Get("Content-Encoding")
+	pipeReader, pipeWriter := io.Pipe()
+	tw.pipe, tw.done = pipeWriter, make(chan struct{})
+	go tw.transform(pipeReader)
+}
+
+func (tw *transformResponseWriter) Write(p []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.pipe == nil {
+		return tw.ResponseWriter.Write(p)
+	}
+	return tw.pipe.Write(p)
+}
+
+func (tw *transformResponseWriter) Flush() {
+	if tw.pipe != nil {
+		return
+	}
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (tw *transformResponseWriter) transform(pipeReader *io.PipeReader) {
+	defer close(tw.done)
+	// the handler keeps writing the original data, even if the transformation does not read it all
+	defer io.Copy(io.Discard, pipeReader)
+
+	header := tw.Header()
+	removeTransformedHeaders(header)
+	out, err := tw.pipeline.Run(tw.r.Context(), pipeReader, tw.object)
+	if err != nil {
+		glog.Warningf("transform %s/%s: %v", tw.object.Bucket, tw.object.Key, err)
+		s3err.WriteErrorResponse(tw.ResponseWriter, tw.r, s3err.ErrObjectTransformationFailed)
+		return
+	}
+	defer out.Close()
+	if tw.object.ContentType != "" {
+		header.Set("Content-Type", tw.object.ContentType)
+	}
+	if tw.object.ContentEncoding != "" {
+		header.Set("Content-Encoding", tw.object.ContentEncoding)
+	}
+	tw.ResponseWriter.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(tw.ResponseWriter, out); err != nil {
+		glog.V(1).Infof("transform %s/%s: %v", tw.object.Bucket, tw.object.Key, err)
+	}
+}
+
+// finish waits for the transformation to send the response
+func (tw *transformResponseWriter) finish() {
+	if tw.pipe != nil {
+		tw.pipe.Close()
+		<-tw.done
+	}
+}
+
+// removeTransformedHeaders drops the headers describing the original data
+func removeTransformedHeaders(header http.Header) {
+	for name := range header {
+		if strings.HasPrefix(strings.ToLower(name), "x-amz-checksum-") {
+			header.Del(name)
+		}
+	}
+	for _, name := range []string{"Content-Length", "Content-Range", "Accept-Ranges", "Content-Md5", "Content-Encoding", "Etag"} {
+		header.Del(name)
+	}
+}
@@ -563,7 +563,8 @@ func (s3a *S3ApiServer) getSpecificObjectVersion(bucket, object, versionId strin
 }
 
 // deleteSpecificObjectVersion deletes a specific version of an object
-func (s3a *S3ApiServer) deleteSpecificObjectVersion(bucket, object, versionId string) error {
+// deleteSpecificObjectVersion deletes an object version, bypassing its governance mode retention if permitted
+func (s3a *S3ApiServer) deleteSpecificObjectVersion(bucket, object, versionId string, bypassGovernance bool) error {
 	if versionId == "" {
 		return fmt.Errorf("version ID is required for version-specific deletion")
 	}
@@ -582,7 +583,7 @@ func (s3a *S3ApiServer) deleteSpecificObjectVersion(bucket, object, versionId st
 		}
 
 		// Delete the regular file
-		deleteErr := s3a.rm(bucketDir, cleanObject, true, false)
+		deleteErr := s3a.rmObject(bucketDir, cleanObject, bypassGovernance)
 		if deleteErr != nil {
 			// Check if file was already deleted by another process
 			if _, checkErr := s3a.getEntry(bucketDir, cleanObject); checkErr != nil {
@@ -609,7 +610,7 @@ func (s3a *S3ApiServer) deleteSpecificObjectVersion(bucket, object, versionId st
 	// Attempt to delete the version file
 	// Note: We don't check if the file exists first to avoid race conditions
 	// The deletion operation should be idempotent
-	deleteErr := s3a.rm(versionsDir, versionFile, true, false)
+	deleteErr := s3a.rmObject(versionsDir, versionFile, bypassGovernance)
 	if deleteErr != nil {
 		// Check if file was already deleted by another process (race condition handling)
 		if _, checkErr := s3a.getEntry(versionsDir, versionFile); checkErr != nil {
@@ -28,7 +28,11 @@ type Rule struct {
 	Filter     Filter     `xml:"Filter,omitempty"`
 	Prefix     Prefix     `xml:"Prefix,omitempty"`
 	Expiration Expiration `xml:"Expiration,omitempty"`
-	Transition Transition `xml:"Transition,omitempty"`
+
+	Transitions                    []Transition                    `xml:"Transition,omitempty"`
+	NoncurrentVersionTransitions   []NoncurrentVersionTransition   `xml:"NoncurrentVersionTransition,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
 }
 
 // Filter - a filter for a lifecycle configuration Rule.
@@ -43,6 +47,9 @@ type Filter struct {
 
 	Tag    Tag
 	tagSet bool
+
+	ObjectSizeGreaterThan int64
+	ObjectSizeLessThan    int64
 }
 
 // Prefix holds the prefix xml tag in <Rule> and <Filter>
@@ -80,17 +87,66 @@ func (f Filter) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	if err := e.EncodeToken(start); err != nil {
 		return err
 	}
-	if err := e.EncodeElement(f.Prefix, xml.StartElement{Name: xml.Name{Local: "Prefix"}}); err != nil {
-		return err
+	switch {
+	case f.andSet:
+		if err := e.EncodeElement(f.And, xml.StartElement{Name: xml.Name{Local: "And"}}); err != nil {
+			return err
+		}
+	case f.tagSet:
+		if err := e.EncodeElement(f.Tag, xml.StartElement{Name: xml.Name{Local: "Tag"}}); err != nil {
+			return err
+		}
+	case f.ObjectSizeGreaterThan > 0:
+		if err := e.EncodeElement(f.ObjectSizeGreaterThan, xml.StartElement{Name: xml.Name{Local: "ObjectSizeGreaterThan"}}); err != nil {
+			return err
+		}
+	case f.ObjectSizeLessThan > 0:
+		if err := e.EncodeElement(f.ObjectSizeLessThan, xml.StartElement{Name: xml.Name{Local: "ObjectSizeLessThan"}}); err != nil {
+			return err
+		}
+	default:
+		if err := e.EncodeElement(f.Prefix, xml.StartElement{Name: xml.Name{Local: "Prefix"}}); err != nil {
+			return err
+		}
 	}
 	return e.EncodeToken(xml.EndElement{Name: start.Name})
 }
 
+// UnmarshalXML decodes Filter field from an XML form, remembering which elements were present.
+func (f *Filter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type filterWrapper struct {
+		Prefix                Prefix `xml:"Prefix"`
+		And                   *And   `xml:"And"`
+		Tag                   *Tag   `xml:"Tag"`
+		ObjectSizeGreaterThan int64  `xml:"ObjectSizeGreaterThan"`
+		ObjectSizeLessThan    int64  `xml:"ObjectSizeLessThan"`
+	}
+	var wrapper filterWrapper
+	if err := d.DecodeElement(&wrapper, &start); err != nil {
+		return err
+	}
+	*f = Filter{
+		set:                   true,
+		Prefix:                wrapper.Prefix,
+		ObjectSizeGreaterThan: wrapper.ObjectSizeGreaterThan,
+		ObjectSizeLessThan:    wrapper.ObjectSizeLessThan,
+	}
+	if wrapper.And != nil {
+		f.And, f.andSet = *wrapper.And, true
+	}
+	if wrapper.Tag != nil {
+		f.Tag, f.tagSet = *wrapper.Tag, true
+	}
+	return nil
+}
+
 // And - a tag to combine a prefix and multiple tags for lifecycle configuration rule.
 type And struct {
-	XMLName xml.Name `xml:"And"`
-	Prefix  Prefix   `xml:"Prefix,omitempty"`
-	Tags    []Tag    `xml:"Tag,omitempty"`
+	XMLName               xml.Name `xml:"And"`
+	Prefix                Prefix   `xml:"Prefix,omitempty"`
+	Tags                  []Tag    `xml:"Tag,omitempty"`
+	ObjectSizeGreaterThan int64    `xml:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    int64    `xml:"ObjectSizeLessThan,omitempty"`
 }
 
 // Expiration - expiration actions for a rule in lifecycle configuration.
@@ -112,6 +168,18 @@ func (e Expiration) MarshalXML(enc *xml.Encoder, startElement xml.StartElement)
 	return enc.EncodeElement(expirationWrapper(e), startElement)
 }
 
+// UnmarshalXML decodes expiration field from an XML form.
+func (e *Expiration) UnmarshalXML(d *xml.Decoder, startElement xml.StartElement) error {
+	type expirationWrapper Expiration
+	var wrapper expirationWrapper
+	if err := d.DecodeElement(&wrapper, &startElement); err != nil {
+		return err
+	}
+	*e = Expiration(wrapper)
+	e.set = true
+	return nil
+}
+
 // ExpireDeleteMarker represents value of ExpiredObjectDeleteMarker field in Expiration XML element.
 type ExpireDeleteMarker struct {
 	val bool
@@ -126,6 +194,16 @@ func (b ExpireDeleteMarker) MarshalXML(e *xml.Encoder, startElement xml.StartEle
 	return e.EncodeElement(b.val, startElement)
 }
 
+// UnmarshalXML decodes delete marker boolean from an XML form.
+func (b *ExpireDeleteMarker) UnmarshalXML(d *xml.Decoder, startElement xml.StartElement) error {
+	var val bool
+	if err := d.DecodeElement(&val, &startElement); err != nil {
+		return err
+	}
+	*b = ExpireDeleteMarker{val: val, set: true}
+	return nil
+}
+
 // ExpirationDate is a embedded type containing time.Time to unmarshal
 // Date in Expiration
 type ExpirationDate struct {
@@ -141,24 +219,27 @@ func (eDate ExpirationDate) MarshalXML(e *xml.Encoder, startElement xml.StartEle
 	return e.EncodeElement(eDate.Format(time.RFC3339), startElement)
 }
 
-// Transition - transition actions for a rule in lifecycle configuration.
+// Transition - moves current objects to another storage class.
 type Transition struct {
-	XMLName      xml.Name  `xml:"Transition"`
-	Days         int       `xml:"Days,omitempty"`
-	Date         time.Time `xml:"Date,omitempty"`
-	StorageClass string    `xml:"StorageClass,omitempty"`
+	Days         int            `xml:"Days,omitempty"`
+	Date         ExpirationDate `xml:"Date,omitempty"`
+	StorageClass string         `xml:"StorageClass"`
+}
 
-	set bool
+// NoncurrentVersionTransition - moves noncurrent object versions to another storage class.
+type NoncurrentVersionTransition struct {
+	NoncurrentDays          int    `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int    `xml:"NewerNoncurrentVersions,omitempty"`
+	StorageClass            string `xml:"StorageClass"`
 }
 
-// MarshalXML encodes transition field into an XML form.
-func (t Transition) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
-	if !t.set {
-		return nil
-	}
-	type transitionWrapper Transition
-	return enc.EncodeElement(transitionWrapper(t), start)
+// NoncurrentVersionExpiration - permanently removes noncurrent object versions.
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays          int `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty"`
 }
 
-// TransitionDays is a type alias to unmarshal Days in Transition
-type TransitionDays int
+// AbortIncompleteMultipartUpload - aborts multipart uploads that are not completed in time.
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
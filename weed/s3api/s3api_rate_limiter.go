@@ -0,0 +1,291 @@
+package s3api
+
+import (
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	stats_collect "github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+const (
+	rateLimitRequests = "requests"
+	rateLimitBytes    = "bytes"
+	// idle token buckets are dropped once they are full again, which makes them equal to new ones
+	rateLimitSweepInterval = time.Minute
+)
+
+// RateLimiter throttles requests with token buckets per access key, bucket and source ip.
+// Request bodies are charged before the handler runs and response bodies afterwards,
+// so a large transfer may leave a bucket in debt until it refills.
+// The source ip is the peer address, or the forwarded client address of requests from trusted proxies.
+type RateLimiter struct {
+	sync.Mutex
+	enabled        bool
+	limits         map[string]map[string]*s3_pb.S3RateLimit // scope -> key -> limit
+	buckets        map[string]*tokenBucket                  // scope:key:requests|bytes -> bucket
+	lastSweep      time.Time
+	now            func() time.Time
+	trustedProxies []*net.IPNet
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// rateLimitCharge is a token bucket charged for a request
+type rateLimitCharge struct {
+	limit  string
+	bucket *tokenBucket
+}
+
+// rateLimitedBody counts the bytes of a request body read by the handler
+type rateLimitedBody struct {
+	io.ReadCloser
+	bytesRead atomic.Int64
+}
+
+func (b *rateLimitedBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.bytesRead.Add(int64(n))
+	return
+}
+
+func NewRateLimiter(trustedProxies []string) *RateLimiter {
+	rl := &RateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			glog.Errorf("parse trusted proxy %s: %v", proxy, err)
+			continue
+		}
+		rl.trustedProxies = append(rl.trustedProxies, ipNet)
+	}
+	return rl
+}
+
+func (rl *RateLimiter) loadRateLimitConfig(cfg *s3_pb.S3RateLimitConfig) {
+	rl.Lock()
+	defer rl.Unlock()
+
+	rl.enabled = cfg != nil && cfg.Enabled
+	rl.limits = make(map[string]map[string]*s3_pb.S3RateLimit)
+	rl.buckets = make(map[string]*tokenBucket)
+	if !rl.enabled {
+		return
+	}
+	rl.limits[s3_constants.RateLimitAccessKey] = cfg.AccessKeys
+	rl.limits[s3_constants.RateLimitBucket] = cfg.Buckets
+	rl.limits[s3_constants.RateLimitSourceIp] = cfg.SourceIps
+}
+
+func (rl *RateLimiter) isEnabled() bool {
+	rl.Lock()
+	defer rl.Unlock()
+	return rl.enabled
+}
+
+// take charges the request to the token buckets of its access key, bucket and source ip.
+// Either all buckets are charged, or none and the request is throttled with ErrSlowDown.
+func (rl *RateLimiter) take(r *http.Request, bucket string) (charges []rateLimitCharge, errCode s3err.ErrorCode) {
+	requestBytes := float64(max(r.ContentLength, 0))
+	keys := map[string]string{
+		s3_constants.RateLimitAccessKey: requestAccessKey(r),
+		s3_constants.RateLimitBucket:    bucket,
+		s3_constants.RateLimitSourceIp:  rl.sourceIP(r),
+	}
+
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+	for _, scope := range []string{s3_constants.RateLimitAccessKey, s3_constants.RateLimitBucket, s3_constants.RateLimitSourceIp} {
+		limit := rl.findLimit(scope, keys[scope])
+		if limit == nil {
+			continue
+		}
+		if limit.RequestsPerSecond > 0 {
+			tb := rl.getBucket(now, scope, keys[scope], rateLimitRequests, limit.RequestsPerSecond, float64(limit.BurstRequests))
+			if !tb.allows(1) {
+				stats_collect.S3ThrottledRequestsCounter.WithLabelValues(scope, rateLimitRequests, bucket).Inc()
+				return nil, s3err.ErrSlowDown
+			}
+			charges = append(charges, rateLimitCharge{limit: rateLimitRequests, bucket: tb})
+		}
+		if limit.BytesPerSecond > 0 {
+			tb := rl.getBucket(now, scope, keys[scope], rateLimitBytes, float64(limit.BytesPerSecond), float64(limit.BurstBytes))
+			if !tb.allows(requestBytes) {
+				stats_collect.S3ThrottledRequestsCounter.WithLabelValues(scope, rateLimitBytes, bucket).Inc()
+				return nil, s3err.ErrSlowDown
+			}
+			charges = append(charges, rateLimitCharge{limit: rateLimitBytes, bucket: tb})
+		}
+	}
+
+	for _, charge := range charges {
+		if charge.limit == rateLimitRequests {
+			charge.bucket.tokens--
+		} else {
+			charge.bucket.tokens -= requestBytes
+		}
+	}
+	return charges, s3err.ErrNone
+}
+
+// chargeBytes charges the bytes transferred by the handler to the byte rate limits of a request:
+// the response bytes, and the request bytes read beyond the charged content length. Request
+// bytes charged but not read are given back.
+func (rl *RateLimiter) chargeBytes(charges []rateLimitCharge, bytes int64) {
+	if bytes == 0 {
+		return
+	}
+	rl.Lock()
+	defer rl.Unlock()
+	for _, charge := range charges {
+		if charge.limit == rateLimitBytes {
+			charge.bucket.tokens = math.Min(charge.bucket.burst, charge.bucket.tokens-float64(bytes))
+		}
+	}
+}
+
+// sourceIP is the address of the client. The forwarded addresses can be set by any client, so they are
+// only followed back through the trusted proxies, up to the first address not of a trusted proxy.
+func (rl *RateLimiter) sourceIP(r *http.Request) string {
+	ip := security.GetActualRemoteHost(r)
+	if !rl.isTrustedProxy(ip) {
+		return ip
+	}
+	forwardedFor := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwardedFor[i])
+		if hop == "" {
+			continue
+		}
+		ip = hop
+		if !rl.isTrustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+func (rl *RateLimiter) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range rl.trustedProxies {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rl *RateLimiter) findLimit(scope, key string) *s3_pb.S3RateLimit {
+	if key == "" {
+		return nil
+	}
+	limits := rl.limits[scope]
+	if limit, found := limits[key]; found {
+		return limit
+	}
+	return limits[s3_constants.RateLimitAll]
+}
+
+func (rl *RateLimiter) getBucket(now time.Time, scope, key, limit string, rate, burst float64) *tokenBucket {
+	name := s3_constants.Concat(scope, key, limit)
+	tb, found := rl.buckets[name]
+	if !found {
+		if burst <= 0 {
+			burst = math.Max(rate, 1)
+		}
+		tb = &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+		rl.buckets[name] = tb
+	}
+	tb.refill(now)
+	return tb
+}
+
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.lastSweep) < rateLimitSweepInterval {
+		return
+	}
+	rl.lastSweep = now
+	for name, tb := range rl.buckets {
+		tb.refill(now)
+		if tb.tokens >= tb.burst {
+			delete(rl.buckets, name)
+		}
+	}
+}
+
+func (tb *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(tb.last).Seconds(); elapsed > 0 {
+		tb.tokens = math.Min(tb.burst, tb.tokens+elapsed*tb.rate)
+		tb.last = now
+	}
+}
+
+// allows checks whether n tokens can be taken. Requests larger than the burst
+// only wait for a full bucket, otherwise they could never pass.
+func (tb *tokenBucket) allows(n float64) bool {
+	return tb.tokens >= math.Min(n, tb.burst)
+}
+
+// requestAccessKey returns the access key a request is signed with, without verifying the signature
+func requestAccessKey(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(authorization, signV4Algorithm):
+		_, credential, found := strings.Cut(authorization, "Credential=")
+		if !found {
+			return ""
+		}
+		accessKey, _, _ := strings.Cut(credential, "/")
+		return accessKey
+	case strings.HasPrefix(authorization, signV2Algorithm+" "):
+		accessKey, _, _ := strings.Cut(strings.TrimPrefix(authorization, signV2Algorithm+" "), ":")
+		return accessKey
+	}
+	query := r.URL.Query()
+	if credential := query.Get("X-Amz-Credential"); credential != "" {
+		accessKey, _, _ := strings.Cut(credential, "/")
+		return accessKey
+	}
+	return query.Get("AWSAccessKeyId")
+}
+
+// responseBodySize is the size of the response body, as announced in its header
+func responseBodySize(w http.ResponseWriter, r *http.Request) int64 {
+	if r.Method == http.MethodHead {
+		return 0
+	}
+	size, _ := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64)
+	return size
+}
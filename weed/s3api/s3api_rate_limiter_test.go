@@ -0,0 +1,244 @@
+package s3api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+func newRateLimitedRequest(method, bucket, accessKey, remoteAddr string, contentLength int64) *http.Request {
+	r := httptest.NewRequest(method, "/"+bucket+"/object", nil)
+	r.RemoteAddr = remoteAddr
+	r.ContentLength = contentLength
+	if accessKey != "" {
+		r.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/20250101/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc")
+	}
+	return r
+}
+
+func TestRateLimiterRequests(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rl := NewRateLimiter(nil)
+	rl.now = func() time.Time { return now }
+	rl.loadRateLimitConfig(&s3_pb.S3RateLimitConfig{
+		Enabled: true,
+		AccessKeys: map[string]*s3_pb.S3RateLimit{
+			"*":       {RequestsPerSecond: 2},
+			"AKIABIG": {RequestsPerSecond: 10, BurstRequests: 20},
+		},
+		SourceIps: map[string]*s3_pb.S3RateLimit{
+			"10.0.0.9": {RequestsPerSecond: 1},
+		},
+	})
+
+	allowed := func(accessKey, remoteAddr string, n int) (count int) {
+		for i := 0; i < n; i++ {
+			if _, errCode := rl.take(newRateLimitedRequest(http.MethodGet, "x", accessKey, remoteAddr, 0), "x"); errCode == s3err.ErrNone {
+				count++
+			} else if errCode != s3err.ErrSlowDown {
+				t.Fatalf("unexpected error code %v", errCode)
+			}
+		}
+		return
+	}
+
+	if count := allowed("AKIAONE", "10.0.0.1:1234", 5); count != 2 {
+		t.Errorf("expected the default burst of 2 requests, got %d", count)
+	}
+	if count := allowed("AKIATWO", "10.0.0.1:1234", 5); count != 2 {
+		t.Errorf("expected each access key to have its own bucket, got %d", count)
+	}
+	if count := allowed("AKIABIG", "10.0.0.1:1234", 30); count != 20 {
+		t.Errorf("expected the configured burst of 20 requests, got %d", count)
+	}
+	if count := allowed("", "10.0.0.9:1234", 5); count != 1 {
+		t.Errorf("expected the source ip limit, got %d", count)
+	}
+	if count := allowed("", "10.0.0.1:1234", 5); count != 5 {
+		t.Errorf("expected anonymous requests from other ips not to be limited, got %d", count)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if count := allowed("AKIAONE", "10.0.0.1:1234", 5); count != 1 {
+		t.Errorf("expected one request to be refilled, got %d", count)
+	}
+
+	// a throttled source ip must not consume the tokens of the access key
+	now = now.Add(10 * time.Second)
+	if count := allowed("AKIAONE", "10.0.0.9:1234", 3); count != 1 {
+		t.Errorf("expected the source ip limit, got %d", count)
+	}
+	if count := allowed("AKIAONE", "10.0.0.1:1234", 3); count != 1 {
+		t.Errorf("expected the access key to keep its remaining token, got %d", count)
+	}
+}
+
+func TestRateLimiterBytes(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	rl := NewRateLimiter(nil)
+	rl.now = func() time.Time { return now }
+	rl.loadRateLimitConfig(&s3_pb.S3RateLimitConfig{
+		Enabled: true,
+		Buckets: map[string]*s3_pb.S3RateLimit{
+			"x": {BytesPerSecond: 1000},
+		},
+	})
+
+	take := func(contentLength int64) s3err.ErrorCode {
+		_, errCode := rl.take(newRateLimitedRequest(http.MethodPut, "x", "", "10.0.0.1:1234", contentLength), "x")
+		return errCode
+	}
+
+	// an upload larger than the burst passes with a full bucket, and leaves it in debt
+	if errCode := take(3000); errCode != s3err.ErrNone {
+		t.Fatalf("expected a large upload to pass a full bucket, got %v", errCode)
+	}
+	now = now.Add(time.Second)
+	if errCode := take(0); errCode != s3err.ErrSlowDown {
+		t.Errorf("expected the bucket to be in debt, got %v", errCode)
+	}
+	now = now.Add(2 * time.Second)
+	if errCode := take(500); errCode != s3err.ErrNone {
+		t.Errorf("expected the debt to be paid, got %v", errCode)
+	}
+
+	charges, errCode := rl.take(newRateLimitedRequest(http.MethodGet, "x", "", "10.0.0.1:1234", 0), "x")
+	if errCode != s3err.ErrNone {
+		t.Fatalf("expected a download to pass, got %v", errCode)
+	}
+	rl.chargeBytes(charges, 2000)
+	now = now.Add(time.Second)
+	if errCode := take(0); errCode != s3err.ErrSlowDown {
+		t.Errorf("expected the response bytes to be charged, got %v", errCode)
+	}
+}
+
+func TestCircuitBreakerRateLimit(t *testing.T) {
+	cb := &CircuitBreaker{
+		counters:    make(map[string]*int64),
+		limitations: make(map[string]int64),
+	}
+	err := cb.loadCircuitBreakerConfig(&s3_pb.S3CircuitBreakerConfig{
+		RateLimits: &s3_pb.S3RateLimitConfig{
+			Enabled: true,
+			Buckets: map[string]*s3_pb.S3RateLimit{"*": {RequestsPerSecond: 1}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler, _ := cb.Limit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, s3_constants.ACTION_READ)
+
+	var statusCodes []int
+	for i := 0; i < 2; i++ {
+		r := mux.SetURLVars(newRateLimitedRequest(http.MethodGet, "x", "", "10.0.0.1:1234", 0), map[string]string{"bucket": "x"})
+		w := httptest.NewRecorder()
+		handler(w, r)
+		statusCodes = append(statusCodes, w.Code)
+	}
+	if statusCodes[0] != http.StatusOK || statusCodes[1] != http.StatusServiceUnavailable {
+		t.Errorf("expected the second request to slow down, got %v", statusCodes)
+	}
+}
+
+func TestRateLimiterSourceIP(t *testing.T) {
+	rl := NewRateLimiter([]string{"10.1.0.0/16", "192.168.0.1", "bad"})
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expected     string
+	}{
+		{"direct", "10.0.0.1:1234", nil, "10.0.0.1"},
+		{"spoofed by a client", "10.0.0.1:1234", []string{"1.2.3.4"}, "10.0.0.1"},
+		{"trusted proxy", "192.168.0.1:1234", []string{"1.2.3.4"}, "1.2.3.4"},
+		{"spoofed through a trusted proxy", "192.168.0.1:1234", []string{"5.6.7.8, 1.2.3.4"}, "1.2.3.4"},
+		{"chained trusted proxies", "192.168.0.1:1234", []string{"5.6.7.8, 1.2.3.4", "10.1.2.3"}, "1.2.3.4"},
+		{"trusted proxy without forwarding", "10.1.2.3:1234", nil, "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for _, forwardedFor := range tt.forwardedFor {
+			r.Header.Add("X-Forwarded-For", forwardedFor)
+		}
+		if ip := rl.sourceIP(r); ip != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, ip)
+		}
+	}
+}
+
+// TestCircuitBreakerChunkedUpload verifies that uploads without a content length are charged the bytes read
+func TestCircuitBreakerChunkedUpload(t *testing.T) {
+	cb := &CircuitBreaker{
+		counters:    make(map[string]*int64),
+		limitations: make(map[string]int64),
+	}
+	err := cb.loadCircuitBreakerConfig(&s3_pb.S3CircuitBreakerConfig{
+		RateLimits: &s3_pb.S3RateLimitConfig{
+			Enabled: true,
+			Buckets: map[string]*s3_pb.S3RateLimit{"*": {BytesPerSecond: 1000}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	cb.rateLimiter.now = func() time.Time { return now }
+
+	handler, _ := cb.Limit(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+	}, s3_constants.ACTION_WRITE)
+	upload := func(content string) int {
+		r := httptest.NewRequest(http.MethodPut, "/x/object", strings.NewReader(content))
+		r.ContentLength = -1
+		r = mux.SetURLVars(r, map[string]string{"bucket": "x"})
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	if code := upload(strings.Repeat("a", 3000)); code != http.StatusOK {
+		t.Fatalf("expected the chunked upload to pass a full bucket, got %d", code)
+	}
+	now = now.Add(time.Second)
+	if code := upload(""); code != http.StatusServiceUnavailable {
+		t.Errorf("expected the bytes read to be charged, got %d", code)
+	}
+}
+
+func TestRequestAccessKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		query    string
+		expected string
+	}{
+		{"v4 header", "AWS4-HMAC-SHA256 Credential=AKIAV4/20250101/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc", "", "AKIAV4"},
+		{"v2 header", "AWS AKIAV2:signature", "", "AKIAV2"},
+		{"v4 presigned", "", "X-Amz-Credential=AKIAPRE%2F20250101%2Fus-east-1%2Fs3%2Faws4_request", "AKIAPRE"},
+		{"v2 presigned", "", "AWSAccessKeyId=AKIAPRE2&Signature=abc", "AKIAPRE2"},
+		{"anonymous", "", "", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object?"+tt.query, nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		if accessKey := requestAccessKey(r); accessKey != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, accessKey)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package s3api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/replication/sink"
+	"github.com/seaweedfs/seaweedfs/weed/replication/sink/filersink"
+	S3Sink "github.com/seaweedfs/seaweedfs/weed/replication/sink/s3sink"
+	"github.com/seaweedfs/seaweedfs/weed/replication/source"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3replication"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	replicationEventsName    = "s3.replication"
+	replicationTargetsConfig = "s3.replication"
+)
+
+// replicationSignature marks the filer updates made by the replicator, so that
+// setting the replication status of an object does not replicate it again
+var replicationSignature = bucketEventSignature(replicationEventsName)
+
+// replicationTarget is a destination cluster configured in replication.toml, e.g.
+//
+//	[s3.replication.dr]
+//	type = "filer"
+//	grpcAddress = "remote-filer:18888"
+type replicationTarget struct {
+	name     string
+	sinkType string
+	prefix   string
+}
+
+func loadReplicationTargets(config *util.ViperProxy) map[string]*replicationTarget {
+	targets := make(map[string]*replicationTarget)
+	for name := range config.GetStringMap(replicationTargetsConfig) {
+		prefix := replicationTargetsConfig + "." + name + "."
+		sinkType := config.GetString(prefix + "type")
+		if sinkType != "filer" && sinkType != "s3" {
+			glog.Warningf("replication target %s: unsupported type %q", name, sinkType)
+			continue
+		}
+		targets[name] = &replicationTarget{name: name, sinkType: sinkType, prefix: prefix}
+		glog.V(0).Infof("s3 replication target %s: %s", name, sinkType)
+	}
+	return targets
+}
+
+// replicationTargetConfig overrides some keys of a replication target section
+type replicationTargetConfig struct {
+	util.Configuration
+	overrides map[string]string
+}
+
+func (c *replicationTargetConfig) GetString(key string) string {
+	if value, found := c.overrides[key]; found {
+		return value
+	}
+	return c.Configuration.GetString(key)
+}
+
+// startReplicationProcessor follows the metadata changes under the buckets folder
+// and replicates objects according to the bucket replication rules.
+func (s3a *S3ApiServer) startReplicationProcessor() {
+	replicator := &bucketReplicator{
+		s3a:   s3a,
+		sinks: make(map[string]sink.ReplicationSink),
+	}
+	newBucketEventFollower(s3a, replicationEventsName, replicator.processEvent).run()
+}
+
+type bucketReplicator struct {
+	s3a   *S3ApiServer
+	sinks map[string]sink.ReplicationSink
+}
+
+func (r *bucketReplicator) processEvent(resp *filer_pb.SubscribeMetadataResponse) {
+	message := resp.EventNotification
+	event, ok := r.s3a.parseBucketObjectEvent(resp)
+	if !ok {
+		return
+	}
+	dir, bucket, key, isVersion := event.dir, event.bucket, event.key, event.isVersion
+
+	metadata, err := r.s3a.GetBucketMetadata(bucket)
+	if err != nil {
+		glog.V(1).Infof("replication: bucket %s metadata: %v", bucket, err)
+		return
+	}
+	if !metadata.HasReplication() {
+		return
+	}
+
+	rules := s3replication.Match(metadata.Replication.Rules, s3replication.Object{Key: key, Tags: objectTags(event.entry)})
+	if len(rules) == 0 {
+		return
+	}
+
+	switch {
+	case message.NewEntry == nil:
+		// permanently deleting a version is not replicated
+		if isVersion || isReplica(message.OldEntry) {
+			return
+		}
+		r.replicateDelete(bucket, key, rules, message.Signatures)
+	case isReplica(message.NewEntry):
+		return
+	case string(message.NewEntry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true":
+		if message.OldEntry == nil {
+			r.replicateDelete(bucket, key, rules, message.Signatures)
+		}
+	case isVersion && !r.isVersionReady(dir, message):
+		return
+	default:
+		r.replicateObject(dir, bucket, key, isVersion, rules, message)
+	}
+}
+
+func (r *bucketReplicator) replicateObject(dir, bucket, key string, isVersion bool, rules []*s3_pb.ReplicationRule, message *filer_pb.EventNotification) {
+	r.setReplicationStatus(dir, message.NewEntry, s3_constants.ReplicationStatusPending)
+
+	// the destination only holds what was replicated before, which is another
+	// object than the previous state of a version that just got its version id
+	oldEntry := message.OldEntry
+	if isVersion && oldEntry != nil {
+		if _, found := oldEntry.Extended[s3_constants.ExtVersionIdKey]; !found {
+			oldEntry = nil
+		}
+	}
+
+	status := s3_constants.ReplicationStatusCompleted
+	for _, rule := range rules {
+		if err := r.replicateToDestination(rule, key, oldEntry, message); err != nil {
+			glog.Errorf("replication rule %q: replicate %s/%s: %v", rule.Id, bucket, key, err)
+			status = s3_constants.ReplicationStatusFailed
+			continue
+		}
+		glog.V(2).Infof("replication rule %q: replicated %s/%s to %s", rule.Id, bucket, key, rule.DestinationBucket)
+	}
+
+	r.setReplicationStatus(dir, message.NewEntry, status)
+}
+
+func (r *bucketReplicator) replicateToDestination(rule *s3_pb.ReplicationRule, key string, oldEntry *filer_pb.Entry, message *filer_pb.EventNotification) error {
+	dataSink, err := r.getSink(rule.DestinationBucket)
+	if err != nil {
+		return err
+	}
+	destKey := util.Join(dataSink.GetSinkToDirectory(), key)
+	newEntry := replicaEntry(message.NewEntry, rule, dataSink)
+	if oldEntry == nil {
+		return dataSink.CreateEntry(destKey, newEntry, message.Signatures)
+	}
+	destDir, _ := util.FullPath(destKey).DirAndName()
+	foundExisting, err := dataSink.UpdateEntry(destKey, oldEntry, destDir, newEntry, message.DeleteChunks, message.Signatures)
+	if foundExisting {
+		return err
+	}
+	return dataSink.CreateEntry(destKey, newEntry, message.Signatures)
+}
+
+func (r *bucketReplicator) replicateDelete(bucket, key string, rules []*s3_pb.ReplicationRule, signatures []int32) {
+	for _, rule := range rules {
+		if !rule.DeleteMarkerReplication {
+			continue
+		}
+		dataSink, err := r.getSink(rule.DestinationBucket)
+		if err == nil {
+			err = dataSink.DeleteEntry(util.Join(dataSink.GetSinkToDirectory(), key), false, true, signatures)
+		}
+		if err != nil {
+			glog.Errorf("replication rule %q: replicate delete of %s/%s: %v", rule.Id, bucket, key, err)
+			continue
+		}
+		glog.V(2).Infof("replication rule %q: replicated delete of %s/%s to %s", rule.Id, bucket, key, rule.DestinationBucket)
+	}
+}
+
+// getSink returns the sink writing into the destination bucket, creating it on first use
+func (r *bucketReplicator) getSink(destinationArn string) (sink.ReplicationSink, error) {
+	if dataSink, found := r.sinks[destinationArn]; found {
+		return dataSink, nil
+	}
+	destination, err := s3replication.ParseDestination(destinationArn)
+	if err != nil {
+		return nil, err
+	}
+	dataSink, err := r.s3a.newReplicationSink(destination)
+	if err != nil {
+		return nil, fmt.Errorf("destination %s: %w", destination, err)
+	}
+
+	filerSource := &source.FilerSource{}
+	filerSource.DoInitialize(r.s3a.option.Filer.ToHttpAddress(), r.s3a.option.Filer.ToGrpcAddress(), r.s3a.option.BucketsPath, false)
+	dataSink.SetSourceFiler(filerSource)
+
+	r.sinks[destinationArn] = dataSink
+	return dataSink, nil
+}
+
+func (s3a *S3ApiServer) newReplicationSink(destination s3replication.Destination) (sink.ReplicationSink, error) {
+	if destination.Target == "" {
+		filerSink := &filersink.FilerSink{}
+		err := filerSink.DoInitialize(s3a.option.Filer.ToHttpAddress(), s3a.option.Filer.ToGrpcAddress(),
+			s3a.option.BucketsPath+"/"+destination.Bucket, "", s3a.getCollectionName(destination.Bucket), 0, "",
+			s3a.option.GrpcDialOption, false)
+		return filerSink, err
+	}
+
+	target, found := s3a.replicationTargets[destination.Target]
+	if !found {
+		return nil, fmt.Errorf("replication target %q is not configured", destination.Target)
+	}
+	config := util.GetViper()
+	switch target.sinkType {
+	case "filer":
+		config.SetDefault(target.prefix+"directory", "/buckets")
+		filerSink := &filersink.FilerSink{}
+		err := filerSink.DoInitialize("", config.GetString(target.prefix+"grpcAddress"),
+			util.Join(config.GetString(target.prefix+"directory"), destination.Bucket),
+			config.GetString(target.prefix+"replication"), destination.Bucket, 0, config.GetString(target.prefix+"disk"),
+			security.LoadClientTLS(config, "grpc.client"), config.GetBool(target.prefix+"is_write_chunk_by_filer"))
+		return filerSink, err
+	case "s3":
+		s3Sink := &S3Sink.S3Sink{}
+		err := s3Sink.Initialize(&replicationTargetConfig{
+			Configuration: config,
+			overrides: map[string]string{
+				target.prefix + "bucket":    destination.Bucket,
+				target.prefix + "directory": "/",
+			},
+		}, target.prefix)
+		return s3Sink, err
+	}
+	return nil, fmt.Errorf("unsupported replication target type %q", target.sinkType)
+}
+
+// setReplicationStatus records the replication status on the source object,
+// unless the object has been changed in the meantime
+func (r *bucketReplicator) setReplicationStatus(dir string, entry *filer_pb.Entry, status string) {
+	err := r.s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := filer_pb.LookupEntry(context.Background(), client, &filer_pb.LookupDirectoryEntryRequest{
+			Directory: dir,
+			Name:      entry.Name,
+		})
+		if err != nil {
+			return err
+		}
+		current := resp.Entry
+		if filer.ETag(current) != filer.ETag(entry) || current.Attributes.GetMtime() != entry.Attributes.GetMtime() {
+			return nil
+		}
+		if current.Extended == nil {
+			current.Extended = make(map[string][]byte)
+		}
+		current.Extended[s3_constants.AmzReplicationStatus] = []byte(status)
+		_, err = client.UpdateEntry(context.Background(), &filer_pb.UpdateEntryRequest{
+			Directory:  dir,
+			Entry:      current,
+			Signatures: []int32{replicationSignature},
+		})
+		return err
+	})
+	if err != nil && !errors.Is(err, filer_pb.ErrNotFound) {
+		glog.Warningf("replication: set status of %s/%s: %v", dir, entry.Name, err)
+	}
+}
+
+// isVersionReady returns true if a version file event should be replicated.
+// A new version is uploaded first and gets its version id in a follow-up update,
+// so it is replicated once the version id is set. Later updates of a version,
+// e.g. tagging, are only replicated for the latest version.
+func (r *bucketReplicator) isVersionReady(versionsDir string, message *filer_pb.EventNotification) bool {
+	if _, found := message.NewEntry.Extended[s3_constants.ExtVersionIdKey]; !found {
+		return false
+	}
+	if message.OldEntry == nil {
+		return true
+	}
+	if _, found := message.OldEntry.Extended[s3_constants.ExtVersionIdKey]; !found {
+		return true
+	}
+	return r.isLatestVersion(versionsDir, message.NewEntry.Name)
+}
+
+// isLatestVersion returns true if the version file is the latest version of its object
+func (r *bucketReplicator) isLatestVersion(versionsDir, versionFileName string) bool {
+	dir, name := util.FullPath(versionsDir).DirAndName()
+	versionsEntry, err := r.s3a.getEntry(dir, name)
+	if err != nil {
+		return false
+	}
+	return string(versionsEntry.Extended[s3_constants.ExtLatestVersionFileNameKey]) == versionFileName
+}
+
+// replicaEntry prepares a copy of the source object for the destination, where
+// it becomes the current object regardless of the version it was on the source
+func replicaEntry(entry *filer_pb.Entry, rule *s3_pb.ReplicationRule, dataSink sink.ReplicationSink) *filer_pb.Entry {
+	replica := proto.Clone(entry).(*filer_pb.Entry)
+	if replica.Extended == nil {
+		replica.Extended = make(map[string][]byte)
+	}
+	delete(replica.Extended, s3_constants.ExtVersionIdKey)
+	delete(replica.Extended, s3_constants.ExtIsLatestKey)
+	delete(replica.Extended, s3_constants.AmzReplicationStatus)
+	if rule.DestinationStorageClass != "" {
+		replica.Extended[s3_constants.AmzStorageClass] = []byte(rule.DestinationStorageClass)
+	}
+	// objects in other S3 services are marked as replicas by the service itself
+	if dataSink.GetName() == "filer" {
+		replica.Extended[s3_constants.AmzReplicationStatus] = []byte(s3_constants.ReplicationStatusReplica)
+	}
+	return replica
+}
+
+func isReplica(entry *filer_pb.Entry) bool {
+	return string(entry.Extended[s3_constants.AmzReplicationStatus]) == s3_constants.ReplicationStatusReplica
+}
+
+func objectTags(entry *filer_pb.Entry) map[string]string {
+	var tags map[string]string
+	for k, v := range entry.Extended {
+		if strings.HasPrefix(k, S3TAG_PREFIX) {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[k[len(S3TAG_PREFIX):]] = string(v)
+		}
+	}
+	return tags
+}
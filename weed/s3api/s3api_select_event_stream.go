@@ -0,0 +1,106 @@
+package s3api
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+)
+
+// selectEventStreamWriter writes SelectObjectContent results in the AWS event stream framing:
+//
+//	total length (4) | headers length (4) | prelude crc (4) | headers | payload | message crc (4)
+type selectEventStreamWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// selectRecordsMaxPayload keeps Records events well below the limits of AWS SDK decoders
+const selectRecordsMaxPayload = 128 * 1024
+
+const eventStreamHeaderTypeString = 7
+
+func newSelectEventStreamWriter(w http.ResponseWriter) *selectEventStreamWriter {
+	flusher, _ := w.(http.Flusher)
+	return &selectEventStreamWriter{w: w, flusher: flusher}
+}
+
+// Write sends the serialized records as one or more Records events.
+func (e *selectEventStreamWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := len(p)
+		if n > selectRecordsMaxPayload {
+			n = selectRecordsMaxPayload
+		}
+		if err := e.writeEvent("Records", "application/octet-stream", p[:n]); err != nil {
+			return written, err
+		}
+		p, written = p[n:], written+n
+	}
+	return written, nil
+}
+
+func (e *selectEventStreamWriter) writeProgress(scanned, processed, returned int64) error {
+	return e.writeEvent("Progress", "text/xml", selectStatsXML("Progress", scanned, processed, returned))
+}
+
+func (e *selectEventStreamWriter) writeStats(scanned, processed, returned int64) error {
+	return e.writeEvent("Stats", "text/xml", selectStatsXML("Stats", scanned, processed, returned))
+}
+
+func (e *selectEventStreamWriter) writeEnd() error {
+	return e.writeEvent("End", "", nil)
+}
+
+func (e *selectEventStreamWriter) writeError(code, message string) error {
+	return e.writeMessage(encodeEventStreamMessage([][2]string{
+		{":error-code", code},
+		{":error-message", message},
+		{":message-type", "error"},
+	}, nil))
+}
+
+func (e *selectEventStreamWriter) writeEvent(eventType, contentType string, payload []byte) error {
+	headers := [][2]string{{":event-type", eventType}}
+	if contentType != "" {
+		headers = append(headers, [2]string{":content-type", contentType})
+	}
+	headers = append(headers, [2]string{":message-type", "event"})
+	return e.writeMessage(encodeEventStreamMessage(headers, payload))
+}
+
+func (e *selectEventStreamWriter) writeMessage(message []byte) error {
+	if _, err := e.w.Write(message); err != nil {
+		return err
+	}
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+	return nil
+}
+
+func selectStatsXML(name string, scanned, processed, returned int64) []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?><%s><BytesScanned>%d</BytesScanned><BytesProcessed>%d</BytesProcessed><BytesReturned>%d</BytesReturned></%s>`,
+		name, scanned, processed, returned, name))
+}
+
+func encodeEventStreamMessage(headers [][2]string, payload []byte) []byte {
+	var headerBytes []byte
+	for _, header := range headers {
+		headerBytes = append(headerBytes, byte(len(header[0])))
+		headerBytes = append(headerBytes, header[0]...)
+		headerBytes = append(headerBytes, eventStreamHeaderTypeString)
+		headerBytes = binary.BigEndian.AppendUint16(headerBytes, uint16(len(header[1])))
+		headerBytes = append(headerBytes, header[1]...)
+	}
+
+	totalLength := 12 + len(headerBytes) + len(payload) + 4
+	message := make([]byte, 0, totalLength)
+	message = binary.BigEndian.AppendUint32(message, uint32(totalLength))
+	message = binary.BigEndian.AppendUint32(message, uint32(len(headerBytes)))
+	message = binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+	message = append(message, headerBytes...)
+	message = append(message, payload...)
+	return binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+}
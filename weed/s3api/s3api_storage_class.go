@@ -0,0 +1,33 @@
+package s3api
+
+import (
+	"net/http"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// storageClasses are the values accepted in x-amz-storage-class.
+// Their placement is configured with storage class mappings in filer.conf.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html#AmazonS3-PutObject-request-header-StorageClass
+var storageClasses = map[string]bool{
+	"STANDARD":            true,
+	"REDUCED_REDUNDANCY":  true,
+	"STANDARD_IA":         true,
+	"ONEZONE_IA":          true,
+	"INTELLIGENT_TIERING": true,
+	"GLACIER":             true,
+	"DEEP_ARCHIVE":        true,
+	"OUTPOSTS":            true,
+	"GLACIER_IR":          true,
+	"SNOW":                true,
+	"EXPRESS_ONEZONE":     true,
+}
+
+// checkStorageClass validates the storage class requested for a new object
+func checkStorageClass(header http.Header) s3err.ErrorCode {
+	if storageClass := header.Get(s3_constants.AmzStorageClass); storageClass != "" && !storageClasses[storageClass] {
+		return s3err.ErrInvalidStorageClass
+	}
+	return s3err.ErrNone
+}
@@ -0,0 +1,77 @@
+package s3api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/sts"
+)
+
+// STSHandler serves AssumeRole and AssumeRoleWithWebIdentity on POST / of the
+// S3 endpoint, so clients can use it as their STS endpoint.
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRole.html
+// https://docs.aws.amazon.com/STS/latest/APIReference/API_AssumeRoleWithWebIdentity.html
+func (s3a *S3ApiServer) STSHandler(w http.ResponseWriter, r *http.Request) {
+	service := s3a.iam.getSTSService()
+	if service == nil {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
+		return
+	}
+
+	// the form is read from a copy of the body, the signature of AssumeRole covers it
+	body, err := io.ReadAll(io.LimitReader(r.Body, iamRequestBodyLimit))
+	if err != nil {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeSTSErrorResponse(w, r, &sts.Error{Code: "InvalidParameterValue", Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+	for k, v := range r.URL.Query() {
+		if _, found := values[k]; !found {
+			values[k] = v
+		}
+	}
+
+	switch action := values.Get("Action"); action {
+	case "AssumeRole":
+		identity, errCode := s3a.iam.reqSignatureV4Verify(r)
+		if errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return
+		}
+		if identity.isSession() {
+			writeSTSErrorResponse(w, r, &sts.Error{Code: "AccessDenied", Message: "temporary credentials can not assume a role", StatusCode: http.StatusForbidden})
+			return
+		}
+		resp, stsErr := service.AssumeRole(identity.Name, values)
+		if stsErr != nil {
+			writeSTSErrorResponse(w, r, stsErr)
+			return
+		}
+		glog.V(1).Infof("%s assumed role %s", identity.Name, resp.Result.AssumedRoleUser.Arn)
+		s3err.WriteXMLResponse(w, r, http.StatusOK, resp)
+	case "AssumeRoleWithWebIdentity":
+		resp, stsErr := service.AssumeRoleWithWebIdentity(values)
+		if stsErr != nil {
+			writeSTSErrorResponse(w, r, stsErr)
+			return
+		}
+		glog.V(1).Infof("web identity %s assumed role %s", resp.Result.SubjectFromWebIdentityToken, resp.Result.AssumedRoleUser.Arn)
+		s3err.WriteXMLResponse(w, r, http.StatusOK, resp)
+	default:
+		writeSTSErrorResponse(w, r, sts.ErrNotImplemented(action))
+	}
+}
+
+func writeSTSErrorResponse(w http.ResponseWriter, r *http.Request, stsErr *sts.Error) {
+	glog.V(2).Infof("sts %s: %v", r.RemoteAddr, stsErr)
+	s3err.WriteXMLResponse(w, r, stsErr.StatusCode, sts.NewErrorResponse(stsErr))
+}
@@ -0,0 +1,240 @@
+package s3api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/cluster"
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3usage"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	usageEventsName     = "s3.usage"
+	usageExportLockName = "s3.usage.export"
+)
+
+// startUsageAccounting saves the requests counted by this gateway every interval.
+// The stored data is counted from the metadata events, and the usage of every
+// finished day is saved and exported, by the gateway holding the lock.
+func (s3a *S3ApiServer) startUsageAccounting(interval time.Duration) {
+	self := fmt.Sprintf("s3@%s:%d-%d", util.DetectedHostAddress(), s3a.option.Port, s3a.randomClientId)
+	lock := s3a.lockClient.StartLongLivedLock(usageExportLockName, self, func(newLockOwner string) {
+		glog.V(0).Infof("s3 usage counter and exporter are now run by %s", newLockOwner)
+	})
+	counter := &usageCounter{s3a: s3a}
+	follower := newBucketEventFollower(s3a, usageEventsName, counter.processEvent)
+	follower.loadOffset, follower.saveOffset = counter.load, counter.save
+	// the exporter reports the stored data counted by the same gateway
+	follower.lock = lock
+	go follower.run()
+	go s3a.startUsageExporter(lock, self, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s3a.flushUsage()
+	}
+}
+
+// flushUsage saves the requests counted by this gateway on every day
+func (s3a *S3ApiServer) flushUsage() {
+	gateway := fmt.Sprintf("%s_%d_%d", util.DetectedHostAddress(), s3a.option.Port, s3a.randomClientId)
+	today := s3usage.Date(time.Now())
+	reports := s3a.usageRecorder.Snapshot()
+	err := s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		for _, report := range reports {
+			if err := s3usage.SaveRequests(client, gateway, report); err != nil {
+				return fmt.Errorf("save requests of %s: %w", report.Date, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		glog.Warningf("usage: %v", err)
+		return
+	}
+	s3a.usageRecorder.Forget(today)
+}
+
+// usageCounter maintains the stored data per bucket, owner and storage class
+// from the metadata events. The state is saved with the time of the last
+// event applied, which is where the events are resumed from.
+type usageCounter struct {
+	s3a   *S3ApiServer
+	state *s3usage.StorageState
+}
+
+func (c *usageCounter) load() (offsetTsNs int64, err error) {
+	err = c.s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		state, err := s3usage.LoadStorageState(client)
+		if err != nil {
+			return err
+		}
+		if state == nil {
+			if state, err = c.count(); err != nil {
+				return fmt.Errorf("count stored data: %w", err)
+			}
+			if err = s3usage.SaveStorageState(client, state); err != nil {
+				return err
+			}
+		}
+		c.state = state
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return c.state.TsNs, nil
+}
+
+func (c *usageCounter) save(offsetTsNs int64) error {
+	return c.s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return s3usage.SaveStorageState(client, c.state)
+	})
+}
+
+// count lists all objects once, when the stored data has not been counted before
+func (c *usageCounter) count() (*s3usage.StorageState, error) {
+	state := s3usage.NewStorageState()
+	state.TsNs = time.Now().UnixNano()
+	glog.V(0).Infof("usage: counting the stored data of all buckets")
+	err := c.s3a.WithFilerClient(true, func(client filer_pb.SeaweedFilerClient) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := client.TraverseBfsMetadata(ctx, &filer_pb.TraverseBfsMetadataRequest{
+			Directory: c.s3a.option.BucketsPath,
+		})
+		if err != nil {
+			return err
+		}
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			c.add(state, resp.Directory, resp.Entry, 1)
+		}
+	})
+	return state, err
+}
+
+func (c *usageCounter) processEvent(resp *filer_pb.SubscribeMetadataResponse) {
+	if resp.TsNs <= c.state.TsNs {
+		// applied before the state was saved
+		return
+	}
+	message := resp.EventNotification
+	if message.NewEntry == nil && message.OldEntry != nil && message.OldEntry.IsDirectory && resp.Directory == c.s3a.option.BucketsPath {
+		// the objects of a deleted bucket may be dropped without events
+		c.state.RemoveBucket(message.OldEntry.Name)
+	} else {
+		c.add(c.state, resp.Directory, message.OldEntry, -1)
+		newDir := resp.Directory
+		if message.NewParentPath != "" {
+			newDir = message.NewParentPath
+		}
+		c.add(c.state, newDir, message.NewEntry, 1)
+	}
+	c.state.TsNs = resp.TsNs
+}
+
+// add counts an object, or removes it with sign -1
+func (c *usageCounter) add(state *s3usage.StorageState, dir string, entry *filer_pb.Entry, sign int64) {
+	object, ok := c.s3a.locateBucketObject(dir, entry)
+	if !ok || entry.Extended != nil && string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true" {
+		return
+	}
+	owner, class := "", ""
+	if entry.Extended != nil {
+		owner = string(entry.Extended[s3_constants.ExtAmzOwnerKey])
+		class = string(entry.Extended[s3_constants.AmzStorageClass])
+	}
+	state.Add(object.bucket, owner, class, sign*int64(filer.FileSize(entry)), sign)
+}
+
+// startUsageExporter saves the usage of every finished day, and writes it
+// into the export bucket. Only the gateway holding the distributed lock does
+// the work, so several gateways can share one filer.
+func (s3a *S3ApiServer) startUsageExporter(lock *cluster.LiveLock, self string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if lock.LockOwner() != self {
+			continue
+		}
+		if err := s3a.exportUsage(time.Now(), interval); err != nil {
+			glog.Errorf("usage export: %v", err)
+		}
+	}
+}
+
+// exportUsage saves the usage of the previous day, once all gateways have
+// saved their requests of it, and exports the report if configured
+func (s3a *S3ApiServer) exportUsage(now time.Time, flushInterval time.Duration) error {
+	today, _ := time.Parse(s3usage.DateLayout, s3usage.Date(now))
+	if now.Sub(today) < 2*flushInterval {
+		return nil
+	}
+	date := s3usage.Date(today.AddDate(0, 0, -1))
+
+	var report *s3usage.Report
+	var config *s3usage.ExportConfig
+	err := s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) (err error) {
+		if report, err = s3usage.LoadDailyReport(client, date); err != nil || report != nil {
+			return err
+		}
+		// the stored data counted now is the stored data at the end of the day
+		if report, err = s3usage.LoadReport(client, date); err != nil {
+			return err
+		}
+		if err = s3usage.SaveDailyReport(client, report); err != nil {
+			return err
+		}
+		glog.V(0).Infof("usage: saved the usage of %s", date)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("usage of %s: %w", date, err)
+	}
+	err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) (err error) {
+		config, err = s3usage.LoadExportConfig(client)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("load export config: %w", err)
+	}
+	if config == nil || !config.Enabled {
+		return nil
+	}
+
+	key := config.Key(date)
+	if _, err := s3a.getEntry(s3a.option.BucketsPath+"/"+config.Bucket, key); err == nil {
+		return nil
+	} else if err != filer_pb.ErrNotFound {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := s3usage.Write(&buf, report, config.Format); err != nil {
+		return err
+	}
+	contentType := "text/csv"
+	if config.Format == s3usage.FormatJSON {
+		contentType = "application/json"
+	}
+	if err := s3a.putFilerObject(config.Bucket, key, buf.Bytes(), contentType); err != nil {
+		return err
+	}
+	glog.V(0).Infof("usage: exported the usage of %s to %s/%s", date, config.Bucket, key)
+	return nil
+}
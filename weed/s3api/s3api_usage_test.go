@@ -0,0 +1,59 @@
+package s3api
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3usage"
+)
+
+func TestUsageCounter(t *testing.T) {
+	s3a := &S3ApiServer{option: &S3ApiServerOption{BucketsPath: "/buckets"}}
+	counter := &usageCounter{s3a: s3a, state: s3usage.NewStorageState()}
+	counter.state.TsNs = 10
+
+	file := func(name string, size uint64, extended map[string][]byte) *filer_pb.Entry {
+		return &filer_pb.Entry{Name: name, Attributes: &filer_pb.FuseAttributes{FileSize: size}, Extended: extended}
+	}
+	owned := map[string][]byte{s3_constants.ExtAmzOwnerKey: []byte("alice")}
+	event := func(tsNs int64, dir string, oldEntry, newEntry *filer_pb.Entry) *filer_pb.SubscribeMetadataResponse {
+		return &filer_pb.SubscribeMetadataResponse{
+			Directory: dir,
+			TsNs:      tsNs,
+			EventNotification: &filer_pb.EventNotification{
+				OldEntry: oldEntry,
+				NewEntry: newEntry,
+			},
+		}
+	}
+
+	counter.processEvent(event(5, "/buckets/photos", nil, file("skipped", 1000, owned)))
+	counter.processEvent(event(11, "/buckets/photos/2025", nil, file("a.jpg", 100, owned)))
+	counter.processEvent(event(12, "/buckets/photos/a.jpg.versions", nil, file("v1", 30, owned)))
+	counter.processEvent(event(13, "/buckets/photos/a.jpg.versions", nil, file("v2", 0, map[string][]byte{s3_constants.ExtDeleteMarkerKey: []byte("true")})))
+	counter.processEvent(event(14, "/buckets/photos/.uploads/x", nil, file("0001.part", 500, owned)))
+	counter.processEvent(event(15, "/buckets/photos", nil, file("b.txt", 7, nil)))
+	// overwrite with a different storage class
+	counter.processEvent(event(16, "/buckets/photos/2025", file("a.jpg", 100, owned),
+		file("a.jpg", 120, map[string][]byte{s3_constants.ExtAmzOwnerKey: []byte("alice"), s3_constants.AmzStorageClass: []byte("STANDARD_IA")})))
+
+	alice := counter.state.Buckets["photos"]["alice"]
+	if u := alice[s3usage.DefaultStorageClass]; u == nil || u.Bytes != 30 || u.Objects != 1 {
+		t.Errorf("unexpected standard usage %+v", u)
+	}
+	if u := alice["STANDARD_IA"]; u == nil || u.Bytes != 120 || u.Objects != 1 {
+		t.Errorf("unexpected infrequent access usage %+v", u)
+	}
+	if u := counter.state.Buckets["photos"][s3usage.Anonymous][s3usage.DefaultStorageClass]; u == nil || u.Bytes != 7 {
+		t.Errorf("unexpected anonymous usage %+v", u)
+	}
+	if counter.state.TsNs != 16 {
+		t.Errorf("unexpected offset %d", counter.state.TsNs)
+	}
+
+	counter.processEvent(event(17, "/buckets", &filer_pb.Entry{Name: "photos", IsDirectory: true}, nil))
+	if len(counter.state.Buckets) != 0 {
+		t.Errorf("deleted bucket should be dropped: %+v", counter.state.Buckets)
+	}
+}
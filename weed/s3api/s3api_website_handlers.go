@@ -0,0 +1,194 @@
+package s3api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3website"
+)
+
+// WebsiteHandler serves the static website of a bucket on {bucket}.{websiteDomainName}.
+// Objects are read with the same public-read logic as anonymous GetObject requests.
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/WebsiteHosting.html
+func (s3a *S3ApiServer) WebsiteHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, object := s3_constants.GetBucketAndObject(r)
+	key := strings.TrimPrefix(object, "/")
+	glog.V(3).Infof("WebsiteHandler %s %s", bucket, key)
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeWebsiteError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.", key)
+		return
+	}
+	config, errCode := s3a.getBucketConfig(bucket)
+	if errCode != s3err.ErrNone {
+		apiError := s3err.GetAPIError(errCode)
+		writeWebsiteError(w, r, apiError.HTTPStatusCode, apiError.Code, apiError.Description, key)
+		return
+	}
+	website := config.Website
+	if website == nil {
+		apiError := s3err.GetAPIError(s3err.ErrNoSuchWebsiteConfiguration)
+		writeWebsiteError(w, r, apiError.HTTPStatusCode, apiError.Code, apiError.Description, key)
+		return
+	}
+
+	protocol := websiteRequestProtocol(r)
+	if website.RedirectAllRequestsTo != nil {
+		writeWebsiteRedirect(w, r, s3website.RedirectAll(website, protocol, key))
+		return
+	}
+	if rule := s3website.MatchRoutingRule(website, key, 0); rule != nil {
+		writeWebsiteRedirect(w, r, s3website.RuleRedirect(rule, protocol, r.Host, key))
+		return
+	}
+
+	status, errorCode := s3a.serveWebsiteObject(w, r, bucket, s3website.IndexKey(website, key), http.StatusOK)
+	if status < http.StatusBadRequest {
+		return
+	}
+	// a folder requested without the trailing slash
+	if status == http.StatusNotFound && key != "" && !strings.HasSuffix(key, "/") {
+		probe := r.Clone(r.Context())
+		probe.Method = http.MethodHead
+		if indexStatus, _ := s3a.serveWebsiteObject(discardResponseWriter{}, probe, bucket, key+"/"+website.IndexDocumentSuffix, http.StatusOK); indexStatus < http.StatusBadRequest {
+			writeWebsiteRedirect(w, r, s3website.Redirect{Location: "/" + key + "/", Code: http.StatusFound})
+			return
+		}
+	}
+	if rule := s3website.MatchRoutingRule(website, key, status); rule != nil {
+		writeWebsiteRedirect(w, r, s3website.RuleRedirect(rule, protocol, r.Host, key))
+		return
+	}
+	if website.ErrorDocumentKey != "" {
+		if errorDocumentStatus, _ := s3a.serveWebsiteObject(w, r, bucket, website.ErrorDocumentKey, status); errorDocumentStatus < http.StatusBadRequest {
+			return
+		}
+	}
+	if errorCode == "" {
+		errorCode = http.StatusText(status)
+	}
+	writeWebsiteError(w, r, status, errorCode, http.StatusText(status), key)
+}
+
+// serveWebsiteObject writes the object with the given status if it can be read,
+// and otherwise returns the failed status without writing anything
+func (s3a *S3ApiServer) serveWebsiteObject(w http.ResponseWriter, r *http.Request, bucket, key string, status int) (int, string) {
+	req := r.Clone(r.Context())
+	req.URL.RawQuery = ""
+	req = mux.SetURLVars(req, map[string]string{"bucket": bucket, "object": key})
+
+	objectHandler := s3a.GetObjectHandler
+	if r.Method == http.MethodHead {
+		objectHandler = s3a.HeadObjectHandler
+	}
+	handler := s3a.AuthWithPublicRead(func(w http.ResponseWriter, r *http.Request) {
+		limitedHandler, _ := s3a.cb.Limit(objectHandler, s3_constants.ACTION_READ)
+		limitedHandler(w, r)
+	}, s3_constants.ACTION_READ)
+
+	writer := &websiteObjectWriter{w: w, header: make(http.Header), status: status}
+	handler(writer, req)
+	if writer.failedStatus != 0 {
+		return writer.failedStatus, writer.errorCode
+	}
+	return http.StatusOK, ""
+}
+
+// websiteObjectWriter holds back the response of an object handler until it
+// is known to succeed, so that failures can be answered by the website rules
+type websiteObjectWriter struct {
+	w            http.ResponseWriter
+	header       http.Header
+	status       int
+	wroteHeader  bool
+	failedStatus int
+	errorCode    string
+}
+
+func (ww *websiteObjectWriter) Header() http.Header {
+	return ww.header
+}
+
+func (ww *websiteObjectWriter) WriteHeader(code int) {
+	if ww.wroteHeader {
+		return
+	}
+	ww.wroteHeader = true
+	if code >= http.StatusBadRequest {
+		ww.failedStatus = code
+		return
+	}
+	for k, v := range ww.header {
+		ww.w.Header()[k] = v
+	}
+	if code == http.StatusOK {
+		code = ww.status
+	}
+	ww.w.WriteHeader(code)
+}
+
+func (ww *websiteObjectWriter) Write(p []byte) (int, error) {
+	if !ww.wroteHeader {
+		ww.WriteHeader(http.StatusOK)
+	}
+	if ww.failedStatus != 0 {
+		return len(p), nil
+	}
+	return ww.w.Write(p)
+}
+
+func (ww *websiteObjectWriter) Flush() {
+	if ww.wroteHeader && ww.failedStatus == 0 {
+		if flusher, ok := ww.w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+}
+
+// RecordErrorCode implements s3err.ErrorCodeRecorder
+func (ww *websiteObjectWriter) RecordErrorCode(code string) {
+	ww.errorCode = code
+}
+
+type discardResponseWriter struct{}
+
+func (discardResponseWriter) Header() http.Header         { return make(http.Header) }
+func (discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardResponseWriter) WriteHeader(int)             {}
+
+func websiteRequestProtocol(r *http.Request) string {
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		return "https"
+	}
+	return "http"
+}
+
+func writeWebsiteRedirect(w http.ResponseWriter, r *http.Request, redirect s3website.Redirect) {
+	w.Header().Set("Location", redirect.Location)
+	w.WriteHeader(redirect.Code)
+}
+
+// writeWebsiteError writes the html error page of the website endpoint
+func writeWebsiteError(w http.ResponseWriter, r *http.Request, status int, code, message, key string) {
+	if recorder, ok := w.(s3err.ErrorCodeRecorder); ok {
+		recorder.RecordErrorCode(code)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	title := fmt.Sprintf("%d %s", status, http.StatusText(status))
+	fmt.Fprintf(w, "<html>\n<head><title>%s</title></head>\n<body>\n<h1>%s</h1>\n<ul>\n<li>Code: %s</li>\n<li>Message: %s</li>\n",
+		title, title, html.EscapeString(code), html.EscapeString(message))
+	if key != "" {
+		fmt.Fprintf(w, "<li>Key: %s</li>\n", html.EscapeString(key))
+	}
+	fmt.Fprintf(w, "</ul>\n<hr/>\n</body>\n</html>\n")
+}
@@ -33,6 +33,9 @@ func VerifyS3BucketName(name string) (err error) {
 	if strings.HasSuffix(name, "-s3alias") {
 		return fmt.Errorf("suffix -s3alias is reserved and not allowed in bucket suffix")
 	}
+	if strings.HasSuffix(name, "--ol-s3") {
+		return fmt.Errorf("suffix --ol-s3 is reserved for access point aliases and not allowed in bucket suffix")
+	}
 	if net.ParseIP(name) != nil {
 		return fmt.Errorf("bucket name cannot be ip addresses")
 	}
@@ -16,6 +16,7 @@ func Test_verifyBucketName(t *testing.T) {
 		"grehtrry-",
 		"----------",
 		"x@fdsgr032",
+		"photos--thumbs--ol-s3",
 	}
 	for _, invalidName := range invalidS3BucketNames {
 		err := VerifyS3BucketName(invalidName)
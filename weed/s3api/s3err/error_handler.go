@@ -40,6 +40,11 @@ func WriteEmptyResponse(w http.ResponseWriter, r *http.Request, statusCode int)
 	PostLog(r, statusCode, ErrNone)
 }
 
+// ErrorCodeRecorder is implemented by response writers that keep the S3 error code of the response
+type ErrorCodeRecorder interface {
+	RecordErrorCode(code string)
+}
+
 func WriteErrorResponse(w http.ResponseWriter, r *http.Request, errorCode ErrorCode) {
 	vars := mux.Vars(r)
 	bucket := vars["bucket"]
@@ -49,6 +54,9 @@ func WriteErrorResponse(w http.ResponseWriter, r *http.Request, errorCode ErrorC
 	}
 
 	apiError := GetAPIError(errorCode)
+	if recorder, ok := w.(ErrorCodeRecorder); ok {
+		recorder.RecordErrorCode(apiError.Code)
+	}
 	errorResponse := getRESTErrorResponse(apiError, r.URL.Path, bucket, object)
 	WriteXMLResponse(w, r, apiError.HTTPStatusCode, errorResponse)
 	PostLog(r, apiError.HTTPStatusCode, errorCode)
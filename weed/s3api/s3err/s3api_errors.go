@@ -64,6 +64,10 @@ const (
 	ErrInvalidMaxParts
 	ErrInvalidMaxDeleteObjects
 	ErrInvalidPartNumberMarker
+	ErrInvalidPartNumber
+	ErrInvalidPartNumberArgument
+	ErrInvalidObjectAttributes
+	ErrInvalidStorageClass
 	ErrInvalidPart
 	ErrInvalidRange
 	ErrInternalError
@@ -106,9 +110,11 @@ const (
 
 	ErrExistingObjectIsDirectory
 	ErrExistingObjectIsFile
+	ErrQuotaExceeded
 
 	ErrTooManyRequest
 	ErrRequestBytesExceed
+	ErrSlowDown
 
 	OwnershipControlsNotFoundError
 	ErrNoSuchTagSet
@@ -133,6 +139,34 @@ const (
 
 	// Bucket encryption errors
 	ErrNoSuchBucketEncryptionConfiguration
+
+	// S3 Select errors
+	ErrInvalidExpressionType
+	ErrInvalidSelectExpression
+
+	// Bucket replication errors
+	ErrNoSuchReplicationConfiguration
+
+	// Bucket inventory errors
+	ErrNoSuchConfiguration
+
+	// Additional checksum errors
+	ErrBadDigest
+
+	// STS session token errors
+	ErrInvalidToken
+	ErrExpiredToken
+
+	// Bucket logging errors
+	ErrInvalidTargetBucketForLogging
+
+	// Bucket website errors
+	ErrNoSuchWebsiteConfiguration
+
+	// Object transformation errors
+	ErrNoSuchTransformationConfiguration
+	ErrNoSuchAccessPoint
+	ErrObjectTransformationFailed
 )
 
 // Error message constants for checksum validation
@@ -210,6 +244,26 @@ var errorCodeResponse = map[ErrorCode]APIError{
 		Description:    "Argument partNumberMarker must be an integer.",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrInvalidPartNumber: {
+		Code:           "InvalidPartNumber",
+		Description:    "The requested partnumber is not satisfiable",
+		HTTPStatusCode: http.StatusRequestedRangeNotSatisfiable,
+	},
+	ErrInvalidPartNumberArgument: {
+		Code:           "InvalidArgument",
+		Description:    "Part number must be an integer between 1 and 10000, inclusive",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidObjectAttributes: {
+		Code:           "InvalidArgument",
+		Description:    "Invalid attribute name specified.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidStorageClass: {
+		Code:           "InvalidStorageClass",
+		Description:    "The storage class you specified is not valid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
 	ErrNoSuchBucket: {
 		Code:           "NoSuchBucket",
 		Description:    "The specified bucket does not exist",
@@ -467,6 +521,11 @@ var errorCodeResponse = map[ErrorCode]APIError{
 		Description:    "Existing Object is a file.",
 		HTTPStatusCode: http.StatusConflict,
 	},
+	ErrQuotaExceeded: {
+		Code:           "QuotaExceeded",
+		Description:    "The directory quota is exceeded.",
+		HTTPStatusCode: http.StatusForbidden,
+	},
 	ErrTooManyRequest: {
 		Code:           "ErrTooManyRequest",
 		Description:    "Too many simultaneous request count",
@@ -477,6 +536,11 @@ var errorCodeResponse = map[ErrorCode]APIError{
 		Description:    "Simultaneous request bytes exceed limitations",
 		HTTPStatusCode: http.StatusTooManyRequests,
 	},
+	ErrSlowDown: {
+		Code:           "SlowDown",
+		Description:    "Please reduce your request rate.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	},
 
 	OwnershipControlsNotFoundError: {
 		Code:           "OwnershipControlsNotFoundError",
@@ -549,6 +613,77 @@ var errorCodeResponse = map[ErrorCode]APIError{
 		Description:    "The server side encryption configuration was not found.",
 		HTTPStatusCode: http.StatusNotFound,
 	},
+
+	// S3 Select error responses
+	ErrInvalidExpressionType: {
+		Code:           "InvalidExpressionType",
+		Description:    "The ExpressionType is invalid. Only SQL expressions are supported.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrInvalidSelectExpression: {
+		Code:           "ParseSelectFailure",
+		Description:    "The SQL expression or its input and output serialization is invalid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+
+	// Bucket replication error responses
+	ErrNoSuchReplicationConfiguration: {
+		Code:           "ReplicationConfigurationNotFoundError",
+		Description:    "The replication configuration was not found.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+
+	// Bucket inventory error responses
+	ErrNoSuchConfiguration: {
+		Code:           "NoSuchConfiguration",
+		Description:    "The specified configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+
+	ErrBadDigest: {
+		Code:           "BadDigest",
+		Description:    "The Content-MD5 or checksum value you specified did not match what we received.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+
+	ErrInvalidToken: {
+		Code:           "InvalidToken",
+		Description:    "The provided token is malformed or otherwise invalid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+	ErrExpiredToken: {
+		Code:           "ExpiredToken",
+		Description:    "The provided token has expired.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+
+	ErrInvalidTargetBucketForLogging: {
+		Code:           "InvalidTargetBucketForLogging",
+		Description:    "The target bucket for logging does not exist.",
+		HTTPStatusCode: http.StatusBadRequest,
+	},
+
+	ErrNoSuchWebsiteConfiguration: {
+		Code:           "NoSuchWebsiteConfiguration",
+		Description:    "The specified bucket does not have a website configuration.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+
+	ErrNoSuchTransformationConfiguration: {
+		Code:           "NoSuchTransformationConfiguration",
+		Description:    "The specified bucket does not have a transformation configuration.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrNoSuchAccessPoint: {
+		Code:           "NoSuchAccessPoint",
+		Description:    "The specified access point does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	},
+	ErrObjectTransformationFailed: {
+		Code:           "ObjectTransformationFailed",
+		Description:    "The transformation of the object failed.",
+		HTTPStatusCode: http.StatusBadGateway,
+	},
 }
 
 // GetAPIError provides API Error for input API error code.
@@ -0,0 +1,115 @@
+package s3inventory
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+const (
+	FormatCSV     = "CSV"
+	FormatORC     = "ORC"
+	FormatParquet = "Parquet"
+
+	FrequencyDaily  = "Daily"
+	FrequencyWeekly = "Weekly"
+
+	// MaxConfigurations is the number of inventory configurations a bucket can have
+	MaxConfigurations = 1000
+
+	// RunTimestampLayout names the folder of one inventory run, e.g. 2024-05-01T00-00Z
+	RunTimestampLayout = "2006-01-02T15-04Z"
+
+	// ManifestVersion is the version of the manifest.json format
+	ManifestVersion = "2016-11-30"
+)
+
+var idPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+// ParseDestination returns the bucket of a destination bucket ARN of the form arn:<partition>:s3:::<bucket>
+func ParseDestination(arn string) (string, error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[1] == "" || parts[2] != "s3" || parts[3] != "" || parts[4] != "" {
+		return "", fmt.Errorf("invalid destination bucket ARN %q", arn)
+	}
+	bucket := parts[5]
+	if bucket == "" || strings.Contains(bucket, "/") {
+		return "", fmt.Errorf("invalid bucket in destination ARN %q", arn)
+	}
+	return bucket, nil
+}
+
+// Validate checks an inventory configuration against the AWS S3 constraints
+// and the formats this implementation can write
+func Validate(config *s3_pb.InventoryConfiguration) error {
+	if !idPattern.MatchString(config.Id) {
+		return fmt.Errorf("invalid inventory id %q", config.Id)
+	}
+	switch config.Format {
+	case FormatCSV, FormatParquet:
+	case FormatORC:
+		return fmt.Errorf("inventory format %s is not supported", config.Format)
+	default:
+		return fmt.Errorf("unknown inventory format %q", config.Format)
+	}
+	if _, err := Interval(config.Frequency); err != nil {
+		return err
+	}
+	if _, err := ParseDestination(config.DestinationBucket); err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, name := range config.OptionalFields {
+		if _, found := optionalFields[name]; !found {
+			return fmt.Errorf("unsupported inventory field %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate inventory field %q", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// Interval returns how long an inventory of the frequency stays current
+func Interval(frequency string) (time.Duration, error) {
+	switch frequency {
+	case FrequencyDaily:
+		return 24 * time.Hour, nil
+	case FrequencyWeekly:
+		return 7 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("unknown inventory frequency %q", frequency)
+}
+
+// IsDue returns true if the next inventory should be produced. lastRun is
+// zero if the configuration never produced an inventory.
+func IsDue(config *s3_pb.InventoryConfiguration, lastRun, now time.Time) bool {
+	if !config.Enabled {
+		return false
+	}
+	interval, err := Interval(config.Frequency)
+	if err != nil {
+		return false
+	}
+	return lastRun.IsZero() || !now.Before(lastRun.Add(interval))
+}
+
+// ReportPrefix is where the reports of a configuration are written in the
+// destination bucket: <prefix>/<source bucket>/<configuration id>/
+func ReportPrefix(config *s3_pb.InventoryConfiguration, sourceBucket string) string {
+	prefix := strings.Trim(config.DestinationPrefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return prefix + sourceBucket + "/" + config.Id + "/"
+}
+
+// ParseRunTimestamp parses the name of a run folder, see RunTimestampLayout
+func ParseRunTimestamp(name string) (time.Time, bool) {
+	t, err := time.Parse(RunTimestampLayout, name)
+	return t, err == nil
+}
@@ -0,0 +1,154 @@
+package s3inventory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *s3_pb.InventoryConfiguration {
+		return &s3_pb.InventoryConfiguration{
+			Id:                "daily-audit",
+			Enabled:           true,
+			DestinationBucket: "arn:aws:s3:::reports",
+			Format:            FormatParquet,
+			Frequency:         FrequencyDaily,
+			OptionalFields:    []string{"Size", "ETag"},
+		}
+	}
+	if err := Validate(valid()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	invalid := map[string]func(c *s3_pb.InventoryConfiguration){
+		"no id":             func(c *s3_pb.InventoryConfiguration) { c.Id = "" },
+		"orc":               func(c *s3_pb.InventoryConfiguration) { c.Format = FormatORC },
+		"hourly":            func(c *s3_pb.InventoryConfiguration) { c.Frequency = "Hourly" },
+		"bucket name":       func(c *s3_pb.InventoryConfiguration) { c.DestinationBucket = "reports" },
+		"unknown field":     func(c *s3_pb.InventoryConfiguration) { c.OptionalFields = []string{"Color"} },
+		"duplicate field":   func(c *s3_pb.InventoryConfiguration) { c.OptionalFields = []string{"Size", "Size"} },
+		"region in the arn": func(c *s3_pb.InventoryConfiguration) { c.DestinationBucket = "arn:aws:s3:us-east-1::reports" },
+	}
+	for name, modify := range invalid {
+		config := valid()
+		modify(config)
+		if err := Validate(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestIsDue(t *testing.T) {
+	now := time.Date(2024, 5, 8, 0, 30, 0, 0, time.UTC)
+	daily := &s3_pb.InventoryConfiguration{Enabled: true, Frequency: FrequencyDaily}
+	weekly := &s3_pb.InventoryConfiguration{Enabled: true, Frequency: FrequencyWeekly}
+	tests := []struct {
+		name    string
+		config  *s3_pb.InventoryConfiguration
+		lastRun time.Time
+		want    bool
+	}{
+		{"never run", daily, time.Time{}, true},
+		{"ran a day ago", daily, now.Add(-24 * time.Hour), true},
+		{"ran today", daily, now.Add(-time.Hour), false},
+		{"weekly ran a day ago", weekly, now.Add(-24 * time.Hour), false},
+		{"disabled", &s3_pb.InventoryConfiguration{Frequency: FrequencyDaily}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		if got := IsDue(tt.config, tt.lastRun, now); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	config := &s3_pb.InventoryConfiguration{Id: "audit", DestinationPrefix: "/inventory/"}
+	if got := ReportPrefix(config, "photos"); got != "inventory/photos/audit/" {
+		t.Errorf("unexpected report prefix %s", got)
+	}
+	if ts, ok := ParseRunTimestamp(now.Format(RunTimestampLayout)); !ok || !ts.Equal(now.Truncate(time.Minute)) {
+		t.Errorf("unexpected run timestamp %v", ts)
+	}
+}
+
+var testRecords = []*Record{
+	{Bucket: "photos", Key: "2024/a b.jpg", VersionId: "null", IsLatest: true, Size: 1024, LastModified: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), ETag: "d41d8cd98f00b204e9800998ecf8427e"},
+	{Bucket: "photos", Key: `say "cheese".jpg`, VersionId: "v2", IsDeleteMarker: true},
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatCSV, Fields(true, []string{"Size", "LastModifiedDate", "ETag"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range testRecords {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(gz)
+	want := `"photos","2024%2Fa+b.jpg","null","true","false","1024","2024-05-01T10:00:00.000Z","d41d8cd98f00b204e9800998ecf8427e"
+"photos","say+%22cheese%22.jpg","v2","false","true","0","",""
+`
+	if string(data) != want {
+		t.Errorf("unexpected csv:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	fields := Fields(false, []string{"Size", "LastModifiedDate", "EncryptionStatus"})
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatParquet, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range testRecords {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.NumRows() != int64(len(testRecords)) {
+		t.Fatalf("expected %d rows, got %d", len(testRecords), file.NumRows())
+	}
+	type row struct {
+		Bucket           string  `parquet:"bucket"`
+		Key              string  `parquet:"key"`
+		Size             int64   `parquet:"size"`
+		LastModifiedDate *int64  `parquet:"last_modified_date,optional"`
+		EncryptionStatus *string `parquet:"encryption_status,optional"`
+	}
+	rows, err := parquet.Read[row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Key != "2024/a b.jpg" || rows[0].Size != 1024 || rows[0].LastModifiedDate == nil || *rows[0].LastModifiedDate != testRecords[0].LastModified.UnixMilli() {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].Key != testRecords[1].Key || rows[1].LastModifiedDate != nil || rows[1].EncryptionStatus != nil {
+		t.Errorf("unexpected second row %+v", rows[1])
+	}
+
+	schema, err := FileSchema(FormatParquet, fields)
+	if err != nil || !bytes.Contains([]byte(schema), []byte("last_modified_date")) {
+		t.Errorf("unexpected schema %q: %v", schema, err)
+	}
+}
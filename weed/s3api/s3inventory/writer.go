@@ -0,0 +1,274 @@
+package s3inventory
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+	"github.com/seaweedfs/seaweedfs/weed/mq/schema"
+	"github.com/seaweedfs/seaweedfs/weed/pb/schema_pb"
+)
+
+// Record is one object version listed in an inventory
+type Record struct {
+	Bucket                    string
+	Key                       string
+	VersionId                 string
+	IsLatest                  bool
+	IsDeleteMarker            bool
+	Size                      int64
+	LastModified              time.Time
+	ETag                      string
+	StorageClass              string
+	IsMultipartUploaded       bool
+	ReplicationStatus         string
+	EncryptionStatus          string
+	ObjectLockRetainUntilDate time.Time
+	ObjectLockMode            string
+	ObjectLockLegalHoldStatus string
+	ChecksumAlgorithm         string
+}
+
+type field struct {
+	// column is the name of the field in Parquet files
+	column string
+	typ    *schema_pb.Type
+	value  func(r *Record) any
+}
+
+var (
+	requiredFields = map[string]field{
+		"Bucket":         {"bucket", schema.TypeString, func(r *Record) any { return r.Bucket }},
+		"Key":            {"key", schema.TypeString, func(r *Record) any { return r.Key }},
+		"VersionId":      {"version_id", schema.TypeString, func(r *Record) any { return r.VersionId }},
+		"IsLatest":       {"is_latest", schema.TypeBoolean, func(r *Record) any { return r.IsLatest }},
+		"IsDeleteMarker": {"is_delete_marker", schema.TypeBoolean, func(r *Record) any { return r.IsDeleteMarker }},
+	}
+	optionalFields = map[string]field{
+		"Size":                      {"size", schema.TypeInt64, func(r *Record) any { return r.Size }},
+		"LastModifiedDate":          {"last_modified_date", schema.TypeInt64, func(r *Record) any { return r.LastModified }},
+		"ETag":                      {"e_tag", schema.TypeString, func(r *Record) any { return r.ETag }},
+		"StorageClass":              {"storage_class", schema.TypeString, func(r *Record) any { return r.StorageClass }},
+		"IsMultipartUploaded":       {"is_multipart_uploaded", schema.TypeBoolean, func(r *Record) any { return r.IsMultipartUploaded }},
+		"ReplicationStatus":         {"replication_status", schema.TypeString, func(r *Record) any { return r.ReplicationStatus }},
+		"EncryptionStatus":          {"encryption_status", schema.TypeString, func(r *Record) any { return r.EncryptionStatus }},
+		"ObjectLockRetainUntilDate": {"object_lock_retain_until_date", schema.TypeInt64, func(r *Record) any { return r.ObjectLockRetainUntilDate }},
+		"ObjectLockMode":            {"object_lock_mode", schema.TypeString, func(r *Record) any { return r.ObjectLockMode }},
+		"ObjectLockLegalHoldStatus": {"object_lock_legal_hold_status", schema.TypeString, func(r *Record) any { return r.ObjectLockLegalHoldStatus }},
+		"ChecksumAlgorithm":         {"checksum_algorithm", schema.TypeString, func(r *Record) any { return r.ChecksumAlgorithm }},
+	}
+)
+
+// Fields returns the fields of the inventory in file order
+func Fields(allVersions bool, optional []string) []string {
+	fields := []string{"Bucket", "Key"}
+	if allVersions {
+		fields = append(fields, "VersionId", "IsLatest", "IsDeleteMarker")
+	}
+	return append(fields, optional...)
+}
+
+func lookupField(name string) (field, error) {
+	if f, found := requiredFields[name]; found {
+		return f, nil
+	}
+	if f, found := optionalFields[name]; found {
+		return f, nil
+	}
+	return field{}, fmt.Errorf("unknown inventory field %q", name)
+}
+
+// Writer writes the records of one inventory data file
+type Writer interface {
+	Write(r *Record) error
+	Close() error
+}
+
+// NewWriter creates a writer of data files in the format, with the fields as columns
+func NewWriter(w io.Writer, format string, fields []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, fields)
+	case FormatParquet:
+		return newParquetWriter(w, fields)
+	}
+	return nil, fmt.Errorf("unsupported inventory format %q", format)
+}
+
+// FileExtension returns the file name extension of data files in the format
+func FileExtension(format string) string {
+	if format == FormatParquet {
+		return ".parquet"
+	}
+	return ".csv.gz"
+}
+
+// FileSchema describes the columns of the data files for the manifest
+func FileSchema(format string, fields []string) (string, error) {
+	if format != FormatParquet {
+		return strings.Join(fields, ", "), nil
+	}
+	parquetSchema, _, err := toParquetSchema(fields)
+	if err != nil {
+		return "", err
+	}
+	return parquetSchema.String(), nil
+}
+
+// csvWriter writes gzip compressed CSV without a header, every value quoted
+// and keys URL encoded as S3 does
+type csvWriter struct {
+	gz     *gzip.Writer
+	fields []field
+	line   strings.Builder
+}
+
+func newCSVWriter(w io.Writer, names []string) (*csvWriter, error) {
+	cw := &csvWriter{gz: gzip.NewWriter(w)}
+	for _, name := range names {
+		f, err := lookupField(name)
+		if err != nil {
+			return nil, err
+		}
+		cw.fields = append(cw.fields, f)
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(r *Record) error {
+	cw.line.Reset()
+	for i, f := range cw.fields {
+		if i > 0 {
+			cw.line.WriteByte(',')
+		}
+		var value string
+		switch v := f.value(r).(type) {
+		case string:
+			value = v
+			if f.column == "key" {
+				value = url.QueryEscape(v)
+			}
+		case bool:
+			value = strconv.FormatBool(v)
+		case int64:
+			value = strconv.FormatInt(v, 10)
+		case time.Time:
+			if !v.IsZero() {
+				value = v.UTC().Format("2006-01-02T15:04:05.000Z")
+			}
+		}
+		cw.line.WriteByte('"')
+		cw.line.WriteString(strings.ReplaceAll(value, `"`, `""`))
+		cw.line.WriteByte('"')
+	}
+	cw.line.WriteByte('\n')
+	_, err := io.WriteString(cw.gz, cw.line.String())
+	return err
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.gz.Close()
+}
+
+// parquetWriter writes Parquet files with the schema writer of the message queue
+type parquetWriter struct {
+	writer     *parquet.Writer
+	rowBuilder *parquet.RowBuilder
+	recordType *schema_pb.RecordType
+	levels     *schema.ParquetLevels
+	fields     []field
+}
+
+func toParquetSchema(names []string) (*parquet.Schema, *schema_pb.RecordType, error) {
+	builder := schema.RecordTypeBegin()
+	for _, name := range names {
+		f, err := lookupField(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		builder.WithField(f.column, f.typ)
+	}
+	recordType := builder.RecordTypeEnd()
+	parquetSchema, err := schema.ToParquetSchema("inventory", recordType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parquetSchema, recordType, nil
+}
+
+func newParquetWriter(w io.Writer, names []string) (*parquetWriter, error) {
+	parquetSchema, recordType, err := toParquetSchema(names)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := schema.ToParquetLevels(recordType)
+	if err != nil {
+		return nil, err
+	}
+	pw := &parquetWriter{
+		writer:     parquet.NewWriter(w, parquetSchema, parquet.Compression(&zstd.Codec{Level: zstd.DefaultLevel})),
+		rowBuilder: parquet.NewRowBuilder(parquetSchema),
+		recordType: recordType,
+		levels:     levels,
+	}
+	for _, name := range names {
+		f, _ := lookupField(name)
+		pw.fields = append(pw.fields, f)
+	}
+	return pw, nil
+}
+
+func (pw *parquetWriter) Write(r *Record) error {
+	value := schema.RecordBegin()
+	for _, f := range pw.fields {
+		switch v := f.value(r).(type) {
+		case string:
+			if v != "" {
+				value.SetString(f.column, v)
+			}
+		case bool:
+			value.SetBool(f.column, v)
+		case int64:
+			value.SetInt64(f.column, v)
+		case time.Time:
+			// milliseconds since the epoch, left null if not set
+			if !v.IsZero() {
+				value.SetInt64(f.column, v.UnixMilli())
+			}
+		}
+	}
+	pw.rowBuilder.Reset()
+	if err := schema.AddRecordValue(pw.rowBuilder, pw.recordType, pw.levels, value.RecordEnd()); err != nil {
+		return err
+	}
+	_, err := pw.writer.WriteRows([]parquet.Row{pw.rowBuilder.Row()})
+	return err
+}
+
+func (pw *parquetWriter) Close() error {
+	return pw.writer.Close()
+}
+
+// Manifest lists the data files of one inventory run
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-inventory-location.html
+type Manifest struct {
+	SourceBucket      string         `json:"sourceBucket"`
+	DestinationBucket string         `json:"destinationBucket"`
+	Version           string         `json:"version"`
+	CreationTimestamp string         `json:"creationTimestamp"`
+	FileFormat        string         `json:"fileFormat"`
+	FileSchema        string         `json:"fileSchema"`
+	Files             []ManifestFile `json:"files"`
+}
+
+type ManifestFile struct {
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	MD5checksum string `json:"MD5checksum"`
+}
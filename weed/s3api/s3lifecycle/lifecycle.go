@@ -0,0 +1,354 @@
+package s3lifecycle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+const (
+	maxRules     = 1000
+	maxRuleIDLen = 255
+)
+
+// ActionType is the lifecycle action that applies to an object
+type ActionType int
+
+const (
+	ActionNone ActionType = iota
+	// ActionExpireCurrent expires the current version of an object. On a
+	// versioning-enabled bucket this creates a delete marker, otherwise the
+	// object is removed.
+	ActionExpireCurrent
+	// ActionDeleteNoncurrent permanently removes a noncurrent version
+	ActionDeleteNoncurrent
+	// ActionDeleteExpiredMarker removes a delete marker that has no other versions behind it
+	ActionDeleteExpiredMarker
+	// ActionAbortMultipartUpload aborts an incomplete multipart upload
+	ActionAbortMultipartUpload
+	// ActionTransition moves an object version to another storage class
+	ActionTransition
+)
+
+// DefaultStorageClass is the storage class of objects stored without one
+const DefaultStorageClass = "STANDARD"
+
+func (a ActionType) String() string {
+	switch a {
+	case ActionExpireCurrent:
+		return "ExpireCurrent"
+	case ActionDeleteNoncurrent:
+		return "DeleteNoncurrent"
+	case ActionDeleteExpiredMarker:
+		return "DeleteExpiredMarker"
+	case ActionAbortMultipartUpload:
+		return "AbortMultipartUpload"
+	case ActionTransition:
+		return "Transition"
+	}
+	return "None"
+}
+
+// Action is the result of evaluating the lifecycle rules against an object
+type Action struct {
+	Type   ActionType
+	RuleID string
+	// StorageClass is the target of a transition
+	StorageClass string
+}
+
+// Object describes one object version as seen by the lifecycle evaluator
+type Object struct {
+	Key            string
+	Size           int64
+	ModTime        time.Time
+	Tags           map[string]string
+	IsLatest       bool
+	IsDeleteMarker bool
+	StorageClass   string
+	// NoncurrentSince is the time the version became noncurrent, i.e. the
+	// modification time of its successor. Only meaningful if !IsLatest.
+	NoncurrentSince time.Time
+	// NewerNoncurrent is the number of noncurrent versions newer than this one.
+	NewerNoncurrent int
+	// NumVersions is the total number of versions of the key, including this one.
+	NumVersions int
+}
+
+// Evaluate returns the action to take on the object, or ActionNone.
+// Permanent deletions take precedence over expiring the current version,
+// which takes precedence over transitions. Of several due transitions, the
+// one due last wins, so objects move down a chain of storage classes.
+func Evaluate(rules []*s3_pb.LifecycleRule, obj Object, now time.Time) Action {
+	var result, transition Action
+	var transitionDue time.Time
+	for _, rule := range rules {
+		if !rule.Enabled || !matchesFilter(rule.Filter, obj) {
+			continue
+		}
+		switch action := evaluateRule(rule, obj, now); action {
+		case ActionNone:
+		case ActionExpireCurrent:
+			if result.Type == ActionNone {
+				result = Action{Type: action, RuleID: rule.Id}
+			}
+		default:
+			return Action{Type: action, RuleID: rule.Id}
+		}
+		if storageClass, due := evaluateTransitions(rule, obj, now); storageClass != "" {
+			if transition.Type == ActionNone || due.After(transitionDue) {
+				transition = Action{Type: ActionTransition, RuleID: rule.Id, StorageClass: storageClass}
+				transitionDue = due
+			}
+		}
+	}
+	if result.Type != ActionNone {
+		return result
+	}
+	currentStorageClass := obj.StorageClass
+	if currentStorageClass == "" {
+		currentStorageClass = DefaultStorageClass
+	}
+	if transition.StorageClass == currentStorageClass {
+		return Action{}
+	}
+	return transition
+}
+
+// evaluateTransitions returns the storage class of the rule transition that is due last
+func evaluateTransitions(rule *s3_pb.LifecycleRule, obj Object, now time.Time) (storageClass string, due time.Time) {
+	if obj.IsDeleteMarker {
+		return "", due
+	}
+	transitions, start := rule.Transitions, obj.ModTime
+	if !obj.IsLatest {
+		transitions, start = rule.NoncurrentVersionTransitions, obj.NoncurrentSince
+	}
+	for _, transition := range transitions {
+		if !obj.IsLatest && obj.NewerNoncurrent < int(transition.NewerNoncurrentVersions) {
+			continue
+		}
+		transitionTime := ExpirationTime(start, int(transition.Days))
+		if transition.Date > 0 {
+			transitionTime = time.Unix(transition.Date, 0)
+		}
+		if now.Before(transitionTime) {
+			continue
+		}
+		if storageClass == "" || transitionTime.After(due) {
+			storageClass, due = transition.StorageClass, transitionTime
+		}
+	}
+	return storageClass, due
+}
+
+func evaluateRule(rule *s3_pb.LifecycleRule, obj Object, now time.Time) ActionType {
+	if obj.IsLatest {
+		if obj.IsDeleteMarker {
+			if rule.ExpiredObjectDeleteMarker && obj.NumVersions == 1 {
+				return ActionDeleteExpiredMarker
+			}
+			return ActionNone
+		}
+		if rule.ExpirationDate > 0 && !now.Before(time.Unix(rule.ExpirationDate, 0)) {
+			return ActionExpireCurrent
+		}
+		if rule.ExpirationDays > 0 && !now.Before(ExpirationTime(obj.ModTime, int(rule.ExpirationDays))) {
+			return ActionExpireCurrent
+		}
+		return ActionNone
+	}
+
+	if rule.NoncurrentVersionExpirationDays <= 0 {
+		return ActionNone
+	}
+	if obj.NewerNoncurrent < int(rule.NewerNoncurrentVersions) {
+		return ActionNone
+	}
+	if now.Before(ExpirationTime(obj.NoncurrentSince, int(rule.NoncurrentVersionExpirationDays))) {
+		return ActionNone
+	}
+	return ActionDeleteNoncurrent
+}
+
+// EvaluateMultipartUpload returns ActionAbortMultipartUpload if an upload
+// for the key initiated at the given time should be aborted.
+func EvaluateMultipartUpload(rules []*s3_pb.LifecycleRule, key string, initiated time.Time, now time.Time) Action {
+	for _, rule := range rules {
+		if !rule.Enabled || rule.AbortIncompleteMultipartUploadDays <= 0 {
+			continue
+		}
+		if rule.Filter != nil && !strings.HasPrefix(key, rule.Filter.Prefix) {
+			continue
+		}
+		if !now.Before(ExpirationTime(initiated, int(rule.AbortIncompleteMultipartUploadDays))) {
+			return Action{Type: ActionAbortMultipartUpload, RuleID: rule.Id}
+		}
+	}
+	return Action{}
+}
+
+// ExpirationTime adds the number of days to the start time and rounds the
+// result up to the next midnight UTC, the same way AWS S3 does.
+func ExpirationTime(start time.Time, days int) time.Time {
+	t := start.UTC().Add(time.Duration(days) * 24 * time.Hour)
+	midnight := t.Truncate(24 * time.Hour)
+	if midnight.Equal(t) {
+		return t
+	}
+	return midnight.Add(24 * time.Hour)
+}
+
+func matchesFilter(filter *s3_pb.LifecycleFilter, obj Object) bool {
+	if filter == nil {
+		return true
+	}
+	if !strings.HasPrefix(obj.Key, filter.Prefix) {
+		return false
+	}
+	for k, v := range filter.Tags {
+		if tagValue, found := obj.Tags[k]; !found || tagValue != v {
+			return false
+		}
+	}
+	// size filters do not apply to delete markers
+	if obj.IsDeleteMarker {
+		return true
+	}
+	if filter.ObjectSizeGreaterThan > 0 && obj.Size <= filter.ObjectSizeGreaterThan {
+		return false
+	}
+	if filter.ObjectSizeLessThan > 0 && obj.Size >= filter.ObjectSizeLessThan {
+		return false
+	}
+	return true
+}
+
+// HasObjectActions returns true if any enabled rule acts on objects or versions,
+// as opposed to only aborting multipart uploads.
+func HasObjectActions(config *s3_pb.LifecycleConfiguration) bool {
+	if config == nil {
+		return false
+	}
+	for _, rule := range config.Rules {
+		if !rule.Enabled {
+			continue
+		}
+		if rule.ExpirationDays > 0 || rule.ExpirationDate > 0 || rule.ExpiredObjectDeleteMarker || rule.NoncurrentVersionExpirationDays > 0 {
+			return true
+		}
+		if len(rule.Transitions) > 0 || len(rule.NoncurrentVersionTransitions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a lifecycle configuration against the AWS S3 constraints
+func Validate(config *s3_pb.LifecycleConfiguration) error {
+	if config == nil || len(config.Rules) == 0 {
+		return fmt.Errorf("lifecycle configuration must have at least one rule")
+	}
+	if len(config.Rules) > maxRules {
+		return fmt.Errorf("lifecycle configuration cannot have more than %d rules", maxRules)
+	}
+	ids := make(map[string]bool)
+	for i, rule := range config.Rules {
+		if len(rule.Id) > maxRuleIDLen {
+			return fmt.Errorf("rule %d: ID cannot be longer than %d characters", i, maxRuleIDLen)
+		}
+		if rule.Id != "" {
+			if ids[rule.Id] {
+				return fmt.Errorf("rule %d: duplicate ID %q", i, rule.Id)
+			}
+			ids[rule.Id] = true
+		}
+		if err := validateRule(rule); err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func validateRule(rule *s3_pb.LifecycleRule) error {
+	if rule.ExpirationDays < 0 || rule.NoncurrentVersionExpirationDays < 0 || rule.AbortIncompleteMultipartUploadDays < 0 || rule.NewerNoncurrentVersions < 0 {
+		return fmt.Errorf("days must be a positive integer")
+	}
+	if rule.ExpirationDays > 0 && rule.ExpirationDate > 0 {
+		return fmt.Errorf("expiration cannot have both Days and Date")
+	}
+	if rule.ExpiredObjectDeleteMarker && (rule.ExpirationDays > 0 || rule.ExpirationDate > 0) {
+		return fmt.Errorf("ExpiredObjectDeleteMarker cannot be specified with Days or Date")
+	}
+	if rule.ExpirationDate > 0 {
+		if date := time.Unix(rule.ExpirationDate, 0).UTC(); !date.Equal(date.Truncate(24 * time.Hour)) {
+			return fmt.Errorf("expiration date must be at midnight UTC")
+		}
+	}
+	if rule.NewerNoncurrentVersions > 0 && rule.NoncurrentVersionExpirationDays == 0 {
+		return fmt.Errorf("NewerNoncurrentVersions requires NoncurrentDays")
+	}
+	if rule.ExpirationDays == 0 && rule.ExpirationDate == 0 && !rule.ExpiredObjectDeleteMarker &&
+		rule.NoncurrentVersionExpirationDays == 0 && rule.AbortIncompleteMultipartUploadDays == 0 &&
+		len(rule.Transitions) == 0 && len(rule.NoncurrentVersionTransitions) == 0 {
+		return fmt.Errorf("at least one action must be specified")
+	}
+	if err := validateTransitions(rule.Transitions, rule.ExpirationDays, false); err != nil {
+		return err
+	}
+	if err := validateTransitions(rule.NoncurrentVersionTransitions, rule.NoncurrentVersionExpirationDays, true); err != nil {
+		return err
+	}
+	if filter := rule.Filter; filter != nil {
+		if filter.ObjectSizeGreaterThan < 0 || filter.ObjectSizeLessThan < 0 {
+			return fmt.Errorf("object size filters must not be negative")
+		}
+		if filter.ObjectSizeGreaterThan > 0 && filter.ObjectSizeLessThan > 0 && filter.ObjectSizeGreaterThan >= filter.ObjectSizeLessThan {
+			return fmt.Errorf("ObjectSizeGreaterThan must be less than ObjectSizeLessThan")
+		}
+		if rule.AbortIncompleteMultipartUploadDays > 0 && len(filter.Tags) > 0 {
+			return fmt.Errorf("AbortIncompleteMultipartUpload cannot be specified with tag filters")
+		}
+		if rule.ExpiredObjectDeleteMarker && len(filter.Tags) > 0 {
+			return fmt.Errorf("ExpiredObjectDeleteMarker cannot be specified with tag filters")
+		}
+	}
+	return nil
+}
+
+func validateTransitions(transitions []*s3_pb.LifecycleTransition, expirationDays int32, noncurrent bool) error {
+	storageClasses := make(map[string]bool)
+	for _, transition := range transitions {
+		switch transition.StorageClass {
+		case "":
+			return fmt.Errorf("transition requires a StorageClass")
+		case DefaultStorageClass:
+			return fmt.Errorf("objects cannot be transitioned to %s", DefaultStorageClass)
+		}
+		if storageClasses[transition.StorageClass] {
+			return fmt.Errorf("more than one transition to %s", transition.StorageClass)
+		}
+		storageClasses[transition.StorageClass] = true
+		if transition.Days < 0 || transition.NewerNoncurrentVersions < 0 {
+			return fmt.Errorf("days must be a positive integer")
+		}
+		if noncurrent {
+			if transition.Date > 0 {
+				return fmt.Errorf("noncurrent version transitions cannot have a Date")
+			}
+		} else if transition.Days > 0 && transition.Date > 0 {
+			return fmt.Errorf("transition cannot have both Days and Date")
+		}
+		if transition.Date > 0 {
+			if date := time.Unix(transition.Date, 0).UTC(); !date.Equal(date.Truncate(24 * time.Hour)) {
+				return fmt.Errorf("transition date must be at midnight UTC")
+			}
+		}
+		if expirationDays > 0 && transition.Days >= expirationDays {
+			return fmt.Errorf("transition to %s must happen before the expiration", transition.StorageClass)
+		}
+	}
+	return nil
+}
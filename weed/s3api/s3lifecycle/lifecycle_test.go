@@ -0,0 +1,246 @@
+package s3lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func TestExpirationTime(t *testing.T) {
+	start := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+	if got, want := ExpirationTime(start, 1), time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ExpirationTime = %v, want %v", got, want)
+	}
+	midnight := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got, want := ExpirationTime(midnight, 2), time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("ExpirationTime = %v, want %v", got, want)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-40 * 24 * time.Hour)
+	recent := now.Add(-2 * 24 * time.Hour)
+
+	tests := []struct {
+		name  string
+		rules []*s3_pb.LifecycleRule
+		obj   Object
+		want  ActionType
+	}{
+		{
+			name:  "expire current by days",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 30}},
+			obj:   Object{Key: "logs/a.txt", ModTime: old, IsLatest: true, NumVersions: 1},
+			want:  ActionExpireCurrent,
+		},
+		{
+			name:  "not yet expired",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 30}},
+			obj:   Object{Key: "logs/a.txt", ModTime: recent, IsLatest: true, NumVersions: 1},
+			want:  ActionNone,
+		},
+		{
+			name:  "disabled rule",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: false, ExpirationDays: 1}},
+			obj:   Object{Key: "a", ModTime: old, IsLatest: true, NumVersions: 1},
+			want:  ActionNone,
+		},
+		{
+			name:  "expire by date",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Unix()}},
+			obj:   Object{Key: "a", ModTime: recent, IsLatest: true, NumVersions: 1},
+			want:  ActionExpireCurrent,
+		},
+		{
+			name:  "prefix mismatch",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 1, Filter: &s3_pb.LifecycleFilter{Prefix: "logs/"}}},
+			obj:   Object{Key: "data/a", ModTime: old, IsLatest: true, NumVersions: 1},
+			want:  ActionNone,
+		},
+		{
+			name: "tag match",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 1,
+				Filter: &s3_pb.LifecycleFilter{Tags: map[string]string{"class": "tmp"}}}},
+			obj:  Object{Key: "a", ModTime: old, IsLatest: true, NumVersions: 1, Tags: map[string]string{"class": "tmp", "x": "y"}},
+			want: ActionExpireCurrent,
+		},
+		{
+			name: "tag mismatch",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 1,
+				Filter: &s3_pb.LifecycleFilter{Tags: map[string]string{"class": "tmp"}}}},
+			obj:  Object{Key: "a", ModTime: old, IsLatest: true, NumVersions: 1, Tags: map[string]string{"class": "keep"}},
+			want: ActionNone,
+		},
+		{
+			name: "size range",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 1,
+				Filter: &s3_pb.LifecycleFilter{ObjectSizeGreaterThan: 100, ObjectSizeLessThan: 1000}}},
+			obj:  Object{Key: "a", Size: 500, ModTime: old, IsLatest: true, NumVersions: 1},
+			want: ActionExpireCurrent,
+		},
+		{
+			name: "size too small",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpirationDays: 1,
+				Filter: &s3_pb.LifecycleFilter{ObjectSizeGreaterThan: 100}}},
+			obj:  Object{Key: "a", Size: 100, ModTime: old, IsLatest: true, NumVersions: 1},
+			want: ActionNone,
+		},
+		{
+			name:  "noncurrent version expired",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, NoncurrentVersionExpirationDays: 7}},
+			obj:   Object{Key: "a", ModTime: old, NoncurrentSince: old, NumVersions: 2},
+			want:  ActionDeleteNoncurrent,
+		},
+		{
+			name:  "noncurrent version kept by newer noncurrent versions",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, NoncurrentVersionExpirationDays: 7, NewerNoncurrentVersions: 2}},
+			obj:   Object{Key: "a", ModTime: old, NoncurrentSince: old, NewerNoncurrent: 1, NumVersions: 3},
+			want:  ActionNone,
+		},
+		{
+			name:  "noncurrent version recently superseded",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, NoncurrentVersionExpirationDays: 7}},
+			obj:   Object{Key: "a", ModTime: old, NoncurrentSince: recent, NumVersions: 2},
+			want:  ActionNone,
+		},
+		{
+			name:  "expired delete marker",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpiredObjectDeleteMarker: true}},
+			obj:   Object{Key: "a", ModTime: recent, IsLatest: true, IsDeleteMarker: true, NumVersions: 1},
+			want:  ActionDeleteExpiredMarker,
+		},
+		{
+			name:  "delete marker with noncurrent versions",
+			rules: []*s3_pb.LifecycleRule{{Id: "r1", Enabled: true, ExpiredObjectDeleteMarker: true}},
+			obj:   Object{Key: "a", ModTime: recent, IsLatest: true, IsDeleteMarker: true, NumVersions: 2},
+			want:  ActionNone,
+		},
+		{
+			name: "permanent deletion wins",
+			rules: []*s3_pb.LifecycleRule{
+				{Id: "r1", Enabled: true, ExpirationDays: 1},
+				{Id: "r2", Enabled: true, NoncurrentVersionExpirationDays: 1},
+			},
+			obj:  Object{Key: "a", ModTime: old, NoncurrentSince: old, NumVersions: 2},
+			want: ActionDeleteNoncurrent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Evaluate(tt.rules, tt.obj, now); got.Type != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got.Type, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateTransition(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	rules := []*s3_pb.LifecycleRule{{
+		Id:      "tiering",
+		Enabled: true,
+		Transitions: []*s3_pb.LifecycleTransition{
+			{Days: 30, StorageClass: "STANDARD_IA"},
+			{Days: 90, StorageClass: "GLACIER"},
+		},
+		NoncurrentVersionTransitions: []*s3_pb.LifecycleTransition{
+			{Days: 7, StorageClass: "GLACIER", NewerNoncurrentVersions: 1},
+		},
+	}}
+
+	tests := []struct {
+		name string
+		obj  Object
+		want string
+	}{
+		{"too recent", Object{Key: "a", ModTime: now.Add(-10 * 24 * time.Hour), IsLatest: true}, ""},
+		{"first tier", Object{Key: "a", ModTime: now.Add(-40 * 24 * time.Hour), IsLatest: true}, "STANDARD_IA"},
+		{"last due tier wins", Object{Key: "a", ModTime: now.Add(-100 * 24 * time.Hour), IsLatest: true}, "GLACIER"},
+		{"already transitioned", Object{Key: "a", ModTime: now.Add(-40 * 24 * time.Hour), IsLatest: true, StorageClass: "STANDARD_IA"}, ""},
+		{"delete marker", Object{Key: "a", ModTime: now.Add(-100 * 24 * time.Hour), IsLatest: true, IsDeleteMarker: true}, ""},
+		{"noncurrent", Object{Key: "a", NoncurrentSince: now.Add(-10 * 24 * time.Hour), NewerNoncurrent: 1}, "GLACIER"},
+		{"noncurrent kept by newer versions", Object{Key: "a", NoncurrentSince: now.Add(-10 * 24 * time.Hour)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(rules, tt.obj, now)
+			if tt.want == "" {
+				if got.Type != ActionNone {
+					t.Errorf("Evaluate() = %v %s, want none", got.Type, got.StorageClass)
+				}
+				return
+			}
+			if got.Type != ActionTransition || got.StorageClass != tt.want {
+				t.Errorf("Evaluate() = %v %s, want transition to %s", got.Type, got.StorageClass, tt.want)
+			}
+		})
+	}
+
+	// expiration takes precedence over a due transition
+	expiring := []*s3_pb.LifecycleRule{rules[0], {Id: "expire", Enabled: true, ExpirationDays: 60}}
+	if got := Evaluate(expiring, Object{Key: "a", ModTime: now.Add(-100 * 24 * time.Hour), IsLatest: true}, now); got.Type != ActionExpireCurrent {
+		t.Errorf("expected expiration to win, got %v", got.Type)
+	}
+}
+
+func TestEvaluateMultipartUpload(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	rules := []*s3_pb.LifecycleRule{{Id: "mpu", Enabled: true, AbortIncompleteMultipartUploadDays: 3, Filter: &s3_pb.LifecycleFilter{Prefix: "uploads/"}}}
+
+	if got := EvaluateMultipartUpload(rules, "uploads/big.bin", now.Add(-5*24*time.Hour), now); got.Type != ActionAbortMultipartUpload {
+		t.Errorf("expected stale upload to be aborted, got %v", got.Type)
+	}
+	if got := EvaluateMultipartUpload(rules, "uploads/big.bin", now.Add(-time.Hour), now); got.Type != ActionNone {
+		t.Errorf("expected recent upload to be kept, got %v", got.Type)
+	}
+	if got := EvaluateMultipartUpload(rules, "other/big.bin", now.Add(-5*24*time.Hour), now); got.Type != ActionNone {
+		t.Errorf("expected upload outside prefix to be kept, got %v", got.Type)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	midnight := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Unix()
+	tests := []struct {
+		name    string
+		config  *s3_pb.LifecycleConfiguration
+		wantErr bool
+	}{
+		{"nil config", nil, true},
+		{"no rules", &s3_pb.LifecycleConfiguration{}, true},
+		{"valid", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", Enabled: true, ExpirationDays: 1}}}, false},
+		{"no action", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", Enabled: true}}}, true},
+		{"duplicate id", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{
+			{Id: "a", Enabled: true, ExpirationDays: 1},
+			{Id: "a", Enabled: true, ExpirationDays: 2},
+		}}, true},
+		{"days and date", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", ExpirationDays: 1, ExpirationDate: midnight}}}, true},
+		{"date not at midnight", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", ExpirationDate: midnight + 10}}}, true},
+		{"delete marker with days", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", ExpirationDays: 1, ExpiredObjectDeleteMarker: true}}}, true},
+		{"abort upload with tags", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", AbortIncompleteMultipartUploadDays: 1,
+			Filter: &s3_pb.LifecycleFilter{Tags: map[string]string{"k": "v"}}}}}, true},
+		{"inverted size range", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", ExpirationDays: 1,
+			Filter: &s3_pb.LifecycleFilter{ObjectSizeGreaterThan: 10, ObjectSizeLessThan: 5}}}}, true},
+		{"transition only", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a",
+			Transitions: []*s3_pb.LifecycleTransition{{Days: 30, StorageClass: "STANDARD_IA"}}}}}, false},
+		{"transition without class", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a",
+			Transitions: []*s3_pb.LifecycleTransition{{Days: 30}}}}}, true},
+		{"transition to standard", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a",
+			Transitions: []*s3_pb.LifecycleTransition{{Days: 30, StorageClass: "STANDARD"}}}}}, true},
+		{"transition days and date", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a",
+			Transitions: []*s3_pb.LifecycleTransition{{Days: 30, Date: midnight, StorageClass: "GLACIER"}}}}}, true},
+		{"transition after expiration", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a", ExpirationDays: 30,
+			Transitions: []*s3_pb.LifecycleTransition{{Days: 60, StorageClass: "GLACIER"}}}}}, true},
+		{"duplicate transition class", &s3_pb.LifecycleConfiguration{Rules: []*s3_pb.LifecycleRule{{Id: "a",
+			NoncurrentVersionTransitions: []*s3_pb.LifecycleTransition{{Days: 30, StorageClass: "GLACIER"}, {Days: 60, StorageClass: "GLACIER"}}}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package s3notification
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Event is the message sent to the notification destinations
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/notification-content-structure.html
+type Event struct {
+	Records []Record `json:"Records"`
+}
+
+type Record struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AwsRegion         string            `json:"awsRegion"`
+	EventTime         string            `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      Identity          `json:"userIdentity"`
+	RequestParameters RequestParameters `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                Entity            `json:"s3"`
+}
+
+type Identity struct {
+	PrincipalId string `json:"principalId"`
+}
+
+type RequestParameters struct {
+	SourceIPAddress string `json:"sourceIPAddress"`
+}
+
+type Entity struct {
+	SchemaVersion   string `json:"s3SchemaVersion"`
+	ConfigurationId string `json:"configurationId"`
+	Bucket          Bucket `json:"bucket"`
+	Object          Object `json:"object"`
+}
+
+type Bucket struct {
+	Name          string   `json:"name"`
+	OwnerIdentity Identity `json:"ownerIdentity"`
+	Arn           string   `json:"arn"`
+}
+
+type Object struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionId string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// ObjectInfo describes the object an event is about
+type ObjectInfo struct {
+	Bucket      string
+	BucketOwner string
+	Key         string
+	Size        int64
+	ETag        string
+	VersionId   string
+	Principal   string
+}
+
+// NewRecord creates the record of an event, e.g. s3:ObjectCreated:Put.
+// The sequencer orders the events of the same object key.
+func NewRecord(event, configurationId string, object ObjectInfo, eventTime time.Time, sequencer int64) Record {
+	return Record{
+		EventVersion:      "2.1",
+		EventSource:       "aws:s3",
+		EventTime:         eventTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+		EventName:         strings.TrimPrefix(event, "s3:"),
+		UserIdentity:      Identity{PrincipalId: object.Principal},
+		RequestParameters: RequestParameters{},
+		ResponseElements:  map[string]string{},
+		S3: Entity{
+			SchemaVersion:   "1.0",
+			ConfigurationId: configurationId,
+			Bucket: Bucket{
+				Name:          object.Bucket,
+				OwnerIdentity: Identity{PrincipalId: object.BucketOwner},
+				Arn:           "arn:aws:s3:::" + object.Bucket,
+			},
+			Object: Object{
+				Key:       encodeKey(object.Key),
+				Size:      object.Size,
+				ETag:      strings.Trim(object.ETag, `"`),
+				VersionId: object.VersionId,
+				Sequencer: fmt.Sprintf("%016X", sequencer),
+			},
+		},
+	}
+}
+
+// TestMessage is sent to the destinations when a notification configuration is saved
+type TestMessage struct {
+	Service   string `json:"Service"`
+	Event     string `json:"Event"`
+	Time      string `json:"Time"`
+	Bucket    string `json:"Bucket"`
+	RequestId string `json:"RequestId"`
+	HostId    string `json:"HostId"`
+}
+
+func NewTestMessage(bucket string, now time.Time) TestMessage {
+	return TestMessage{
+		Service:   "Amazon S3",
+		Event:     TestEvent,
+		Time:      now.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Bucket:    bucket,
+		RequestId: fmt.Sprintf("%d", now.UnixNano()),
+	}
+}
+
+// encodeKey URL encodes the object key the way S3 does in event messages,
+// keeping the slashes
+func encodeKey(key string) string {
+	return strings.ReplaceAll(url.QueryEscape(key), "%2F", "/")
+}
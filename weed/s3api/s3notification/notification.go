@@ -0,0 +1,126 @@
+package s3notification
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+// Event types of the bucket notification configuration.
+// Uploads by PUT, POST and copy can not be told apart from the metadata
+// changes, so they are all sent as s3:ObjectCreated:Put.
+const (
+	ObjectCreatedAll                     = "s3:ObjectCreated:*"
+	ObjectCreatedPut                     = "s3:ObjectCreated:Put"
+	ObjectCreatedPost                    = "s3:ObjectCreated:Post"
+	ObjectCreatedCopy                    = "s3:ObjectCreated:Copy"
+	ObjectCreatedCompleteMultipartUpload = "s3:ObjectCreated:CompleteMultipartUpload"
+	ObjectRemovedAll                     = "s3:ObjectRemoved:*"
+	ObjectRemovedDelete                  = "s3:ObjectRemoved:Delete"
+	ObjectRemovedDeleteMarkerCreated     = "s3:ObjectRemoved:DeleteMarkerCreated"
+	ObjectTaggingAll                     = "s3:ObjectTagging:*"
+	ObjectTaggingPut                     = "s3:ObjectTagging:Put"
+	ObjectTaggingDelete                  = "s3:ObjectTagging:Delete"
+	TestEvent                            = "s3:TestEvent"
+)
+
+var validEvents = []string{
+	ObjectCreatedAll,
+	ObjectCreatedPut,
+	ObjectCreatedPost,
+	ObjectCreatedCopy,
+	ObjectCreatedCompleteMultipartUpload,
+	ObjectRemovedAll,
+	ObjectRemovedDelete,
+	ObjectRemovedDeleteMarkerCreated,
+	ObjectTaggingAll,
+	ObjectTaggingPut,
+	ObjectTaggingDelete,
+}
+
+// Services of the destination ARNs, one per kind of notification configuration
+const (
+	ServiceQueue    = "sqs"
+	ServiceTopic    = "sns"
+	ServiceFunction = "lambda"
+)
+
+const (
+	maxConfigurations = 1000
+	maxIDLen          = 255
+)
+
+// ParseDestination returns the service and the name of a destination ARN,
+// e.g. arn:aws:sqs:us-east-1:123456789012:uploads has the name "uploads".
+// The name selects the message queue configured by the operator.
+func ParseDestination(arn string) (service, name string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid destination ARN %q", arn)
+	}
+	service = parts[2]
+	if service != ServiceQueue && service != ServiceTopic && service != ServiceFunction {
+		return "", "", fmt.Errorf("unsupported service %q in destination ARN %q", service, arn)
+	}
+	// lambda ARNs name the function as function:<name>
+	resource := parts[5]
+	name = resource[strings.LastIndex(resource, ":")+1:]
+	if name == "" {
+		return "", "", fmt.Errorf("missing name in destination ARN %q", arn)
+	}
+	return service, name, nil
+}
+
+// MatchEvent returns true if the configured event type, which may end with a
+// wildcard, covers the event
+func MatchEvent(pattern, event string) bool {
+	if prefix, found := strings.CutSuffix(pattern, "*"); found {
+		return strings.HasPrefix(event, prefix)
+	}
+	return pattern == event
+}
+
+// Match returns the configurations to notify about the event on the object key
+func Match(rules []*s3_pb.NotificationRule, event, key string) []*s3_pb.NotificationRule {
+	var matched []*s3_pb.NotificationRule
+	for _, rule := range rules {
+		if !strings.HasPrefix(key, rule.Prefix) || !strings.HasSuffix(key, rule.Suffix) {
+			continue
+		}
+		if slices.ContainsFunc(rule.Events, func(pattern string) bool { return MatchEvent(pattern, event) }) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+// Validate checks a bucket notification configuration
+func Validate(config *s3_pb.NotificationConfiguration) error {
+	if len(config.GetRules()) > maxConfigurations {
+		return fmt.Errorf("notification configuration cannot have more than %d destinations", maxConfigurations)
+	}
+	ids := make(map[string]bool)
+	for i, rule := range config.GetRules() {
+		if rule.Id == "" || len(rule.Id) > maxIDLen {
+			return fmt.Errorf("configuration %d: ID must have 1 to %d characters", i, maxIDLen)
+		}
+		if ids[rule.Id] {
+			return fmt.Errorf("configuration %d: duplicate ID %q", i, rule.Id)
+		}
+		ids[rule.Id] = true
+		if len(rule.Events) == 0 {
+			return fmt.Errorf("configuration %q: missing event", rule.Id)
+		}
+		for _, event := range rule.Events {
+			if !slices.Contains(validEvents, event) {
+				return fmt.Errorf("configuration %q: unsupported event %q", rule.Id, event)
+			}
+		}
+		if _, _, err := ParseDestination(rule.DestinationArn); err != nil {
+			return fmt.Errorf("configuration %q: %v", rule.Id, err)
+		}
+	}
+	return nil
+}
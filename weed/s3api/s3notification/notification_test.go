@@ -0,0 +1,118 @@
+package s3notification
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		arn         string
+		wantService string
+		wantName    string
+		wantErr     bool
+	}{
+		{arn: "arn:aws:sqs:us-east-1:123456789012:uploads", wantService: ServiceQueue, wantName: "uploads"},
+		{arn: "arn:aws:sns:us-east-1:123456789012:uploads", wantService: ServiceTopic, wantName: "uploads"},
+		{arn: "arn:aws:lambda:us-east-1:123456789012:function:thumbnails", wantService: ServiceFunction, wantName: "thumbnails"},
+		{arn: "arn:aws:s3:::bucket", wantErr: true},
+		{arn: "arn:aws:sqs:us-east-1:123456789012:", wantErr: true},
+		{arn: "uploads", wantErr: true},
+	}
+	for _, tt := range tests {
+		service, name, err := ParseDestination(tt.arn)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDestination(%q) error = %v, wantErr %v", tt.arn, err, tt.wantErr)
+			continue
+		}
+		if service != tt.wantService || name != tt.wantName {
+			t.Errorf("ParseDestination(%q) = %s %s, want %s %s", tt.arn, service, name, tt.wantService, tt.wantName)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []*s3_pb.NotificationRule{
+		{Id: "images", Events: []string{ObjectCreatedAll}, Prefix: "images/", Suffix: ".jpg"},
+		{Id: "deletes", Events: []string{ObjectRemovedDelete}},
+		{Id: "uploads", Events: []string{ObjectCreatedPut, ObjectCreatedCompleteMultipartUpload}},
+	}
+
+	matched := Match(rules, ObjectCreatedCompleteMultipartUpload, "images/cat.jpg")
+	if len(matched) != 2 || matched[0].Id != "images" || matched[1].Id != "uploads" {
+		t.Errorf("unexpected match %v", matched)
+	}
+	if matched = Match(rules, ObjectCreatedPut, "images/cat.png"); len(matched) != 1 || matched[0].Id != "uploads" {
+		t.Errorf("the suffix should not match, got %v", matched)
+	}
+	if matched = Match(rules, ObjectRemovedDeleteMarkerCreated, "images/cat.jpg"); len(matched) != 0 {
+		t.Errorf("no configuration covers delete markers, got %v", matched)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := &s3_pb.NotificationConfiguration{Rules: []*s3_pb.NotificationRule{
+		{Id: "a", Events: []string{ObjectCreatedAll}, DestinationArn: "arn:aws:sqs:us-east-1:123456789012:uploads"},
+	}}
+	if err := Validate(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := map[string]*s3_pb.NotificationConfiguration{
+		"no event":      {Rules: []*s3_pb.NotificationRule{{Id: "a", DestinationArn: "arn:aws:sqs:us-east-1:123456789012:uploads"}}},
+		"unknown event": {Rules: []*s3_pb.NotificationRule{{Id: "a", Events: []string{"s3:ObjectCreated:Rename"}, DestinationArn: "arn:aws:sqs:us-east-1:123456789012:uploads"}}},
+		"bad arn":       {Rules: []*s3_pb.NotificationRule{{Id: "a", Events: []string{ObjectCreatedAll}, DestinationArn: "uploads"}}},
+		"duplicate id": {Rules: []*s3_pb.NotificationRule{
+			{Id: "a", Events: []string{ObjectCreatedAll}, DestinationArn: "arn:aws:sqs:us-east-1:123456789012:uploads"},
+			{Id: "a", Events: []string{ObjectRemovedAll}, DestinationArn: "arn:aws:sqs:us-east-1:123456789012:uploads"},
+		}},
+	}
+	for name, config := range invalid {
+		if err := Validate(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	object := ObjectInfo{Bucket: "photos", Key: "2024/my cat+dog.jpg", Size: 1024, ETag: `"abc"`, VersionId: "v1"}
+	record := NewRecord(ObjectCreatedPut, "images", object, time.Unix(1700000000, 0), 255)
+
+	data, err := json.Marshal(Event{Records: []Record{record}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Records []struct {
+			EventName string `json:"eventName"`
+			EventTime string `json:"eventTime"`
+			S3        struct {
+				ConfigurationId string `json:"configurationId"`
+				Bucket          struct {
+					Arn string `json:"arn"`
+				} `json:"bucket"`
+				Object struct {
+					Key       string `json:"key"`
+					Size      int64  `json:"size"`
+					ETag      string `json:"eTag"`
+					VersionId string `json:"versionId"`
+					Sequencer string `json:"sequencer"`
+				} `json:"object"`
+			} `json:"s3"`
+		}
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	r := decoded.Records[0]
+	if r.EventName != "ObjectCreated:Put" || r.EventTime != "2023-11-14T22:13:20.000Z" || r.S3.ConfigurationId != "images" || r.S3.Bucket.Arn != "arn:aws:s3:::photos" {
+		t.Errorf("unexpected record %s", data)
+	}
+	if r.S3.Object.Key != "2024/my+cat%2Bdog.jpg" || r.S3.Object.Size != 1024 || r.S3.Object.ETag != "abc" ||
+		r.S3.Object.VersionId != "v1" || r.S3.Object.Sequencer != "00000000000000FF" {
+		t.Errorf("unexpected object %+v", r.S3.Object)
+	}
+}
@@ -0,0 +1,53 @@
+package s3parts
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Part is the boundary of a part of a completed multipart upload within the object
+type Part struct {
+	PartNumber int    `json:"n"`
+	Offset     int64  `json:"o"`
+	Size       int64  `json:"s"`
+	ETag       string `json:"e,omitempty"`
+	Checksum   string `json:"c,omitempty"`
+}
+
+// Encode serializes the parts of an object, which must be sorted by part number,
+// to be stored in the entry extended attributes
+func Encode(parts []Part) ([]byte, error) {
+	return json.Marshal(parts)
+}
+
+// Decode reads the parts stored with an object
+func Decode(data []byte) ([]Part, error) {
+	var parts []Part
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return nil, fmt.Errorf("decode parts: %w", err)
+	}
+	return parts, nil
+}
+
+// Get returns the n-th part of an object, counting from 1, the way GetObject
+// and HeadObject address parts up to the x-amz-mp-parts-count of the object
+func Get(parts []Part, n int) (Part, bool) {
+	if n < 1 || n > len(parts) {
+		return Part{}, false
+	}
+	return parts[n-1], true
+}
+
+// Page returns at most maxParts parts after the part number marker,
+// and whether more parts follow
+func Page(parts []Part, partNumberMarker, maxParts int) (page []Part, isTruncated bool) {
+	i := sort.Search(len(parts), func(i int) bool {
+		return parts[i].PartNumber > partNumberMarker
+	})
+	page = parts[i:]
+	if maxParts >= 0 && len(page) > maxParts {
+		return page[:maxParts], true
+	}
+	return page, false
+}
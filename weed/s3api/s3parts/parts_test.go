@@ -0,0 +1,49 @@
+package s3parts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParts(t *testing.T) {
+	parts := []Part{
+		{PartNumber: 1, Offset: 0, Size: 5 << 20, ETag: "a"},
+		{PartNumber: 3, Offset: 5 << 20, Size: 5 << 20, ETag: "b", Checksum: "AAAAAA=="},
+		{PartNumber: 4, Offset: 10 << 20, Size: 1024, ETag: "c"},
+	}
+	data, err := Encode(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, parts) {
+		t.Fatalf("round trip changed the parts: %v", decoded)
+	}
+	if _, err := Decode([]byte("not json")); err == nil {
+		t.Errorf("expected a decode error")
+	}
+
+	if part, found := Get(parts, 2); !found || part.PartNumber != 3 {
+		t.Errorf("unexpected second part %v", part)
+	}
+	for _, n := range []int{0, 4} {
+		if _, found := Get(parts, n); found {
+			t.Errorf("part %d should not be found", n)
+		}
+	}
+
+	page, isTruncated := Page(parts, 0, 2)
+	if len(page) != 2 || page[1].PartNumber != 3 || !isTruncated {
+		t.Errorf("unexpected first page %v %v", page, isTruncated)
+	}
+	page, isTruncated = Page(parts, 3, 2)
+	if len(page) != 1 || page[0].PartNumber != 4 || isTruncated {
+		t.Errorf("unexpected last page %v %v", page, isTruncated)
+	}
+	if page, _ = Page(parts, 2, 0); len(page) != 0 {
+		t.Errorf("expected an empty page, got %v", page)
+	}
+}
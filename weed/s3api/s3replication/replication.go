@@ -0,0 +1,128 @@
+package s3replication
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+const (
+	maxRules     = 1000
+	maxRuleIDLen = 255
+)
+
+// Destination is a parsed destination bucket ARN.
+//
+// The region part of the ARN names the replication target configured by the
+// operator, e.g. arn:aws:s3:dr::backup replicates into bucket "backup" of the
+// target "dr". An empty region replicates into a bucket of this cluster.
+type Destination struct {
+	Target string
+	Bucket string
+}
+
+// ParseDestination parses a destination bucket ARN of the form arn:<partition>:s3:<target>::<bucket>
+func ParseDestination(arn string) (Destination, error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[1] == "" || parts[2] != "s3" {
+		return Destination{}, fmt.Errorf("invalid destination bucket ARN %q", arn)
+	}
+	if parts[4] != "" {
+		return Destination{}, fmt.Errorf("destination bucket ARN %q must not have an account", arn)
+	}
+	bucket := parts[5]
+	if bucket == "" || strings.Contains(bucket, "/") {
+		return Destination{}, fmt.Errorf("invalid bucket in destination ARN %q", arn)
+	}
+	return Destination{Target: parts[3], Bucket: bucket}, nil
+}
+
+func (d Destination) String() string {
+	if d.Target == "" {
+		return d.Bucket
+	}
+	return d.Target + ":" + d.Bucket
+}
+
+// Object describes an object version as seen by the rule matcher
+type Object struct {
+	Key  string
+	Tags map[string]string
+}
+
+// Match returns the enabled rules that apply to the object. If several rules
+// replicate into the same destination, only the one with the highest priority
+// is returned. The result keeps the order of the configured rules.
+func Match(rules []*s3_pb.ReplicationRule, obj Object) []*s3_pb.ReplicationRule {
+	var matched []*s3_pb.ReplicationRule
+	byDestination := make(map[string]int)
+	for _, rule := range rules {
+		if !rule.Enabled || !matchesFilter(rule.Filter, obj) {
+			continue
+		}
+		if i, found := byDestination[rule.DestinationBucket]; found {
+			if rule.Priority > matched[i].Priority {
+				matched[i] = rule
+			}
+			continue
+		}
+		byDestination[rule.DestinationBucket] = len(matched)
+		matched = append(matched, rule)
+	}
+	return matched
+}
+
+func matchesFilter(filter *s3_pb.ReplicationFilter, obj Object) bool {
+	if filter == nil {
+		return true
+	}
+	if !strings.HasPrefix(obj.Key, filter.Prefix) {
+		return false
+	}
+	for k, v := range filter.Tags {
+		if tagValue, found := obj.Tags[k]; !found || tagValue != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks a replication configuration of the source bucket against the AWS S3 constraints
+func Validate(config *s3_pb.ReplicationConfiguration, sourceBucket string) error {
+	if config == nil || len(config.Rules) == 0 {
+		return fmt.Errorf("replication configuration must have at least one rule")
+	}
+	if len(config.Rules) > maxRules {
+		return fmt.Errorf("replication configuration cannot have more than %d rules", maxRules)
+	}
+	ids := make(map[string]bool)
+	priorities := make(map[string]bool)
+	for i, rule := range config.Rules {
+		if len(rule.Id) > maxRuleIDLen {
+			return fmt.Errorf("rule %d: ID cannot be longer than %d characters", i, maxRuleIDLen)
+		}
+		if rule.Id != "" {
+			if ids[rule.Id] {
+				return fmt.Errorf("rule %d: duplicate ID %q", i, rule.Id)
+			}
+			ids[rule.Id] = true
+		}
+		if rule.Priority < 0 {
+			return fmt.Errorf("rule %d: priority must not be negative", i)
+		}
+		destination, err := ParseDestination(rule.DestinationBucket)
+		if err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+		if destination.Target == "" && destination.Bucket == sourceBucket {
+			return fmt.Errorf("rule %d: destination bucket must differ from the source bucket", i)
+		}
+		priorityKey := fmt.Sprintf("%s/%d", rule.DestinationBucket, rule.Priority)
+		if priorities[priorityKey] {
+			return fmt.Errorf("rule %d: duplicate priority %d for destination %s", i, rule.Priority, rule.DestinationBucket)
+		}
+		priorities[priorityKey] = true
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package s3replication
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		arn     string
+		want    Destination
+		wantErr bool
+	}{
+		{arn: "arn:aws:s3:::backup", want: Destination{Bucket: "backup"}},
+		{arn: "arn:aws:s3:dr::backup", want: Destination{Target: "dr", Bucket: "backup"}},
+		{arn: "backup", wantErr: true},
+		{arn: "arn:aws:sqs:::backup", wantErr: true},
+		{arn: "arn:aws:s3:::", wantErr: true},
+		{arn: "arn:aws:s3:::backup/prefix", wantErr: true},
+		{arn: "arn:aws:s3::123456789012:backup", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDestination(tt.arn)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDestination(%q) error = %v, wantErr %v", tt.arn, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDestination(%q) = %+v, want %+v", tt.arn, got, tt.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []*s3_pb.ReplicationRule{
+		{Id: "logs", Enabled: true, Priority: 1, Filter: &s3_pb.ReplicationFilter{Prefix: "logs/"}, DestinationBucket: "arn:aws:s3:::a"},
+		{Id: "all", Enabled: true, Priority: 0, DestinationBucket: "arn:aws:s3:::a"},
+		{Id: "tagged", Enabled: true, Filter: &s3_pb.ReplicationFilter{Tags: map[string]string{"dr": "yes"}}, DestinationBucket: "arn:aws:s3:dr::b"},
+		{Id: "disabled", Enabled: false, DestinationBucket: "arn:aws:s3:::c"},
+	}
+
+	matched := Match(rules, Object{Key: "logs/1.txt"})
+	if len(matched) != 1 || matched[0].Id != "logs" {
+		t.Errorf("expected the higher priority rule, got %v", matched)
+	}
+
+	matched = Match(rules, Object{Key: "data/1.txt", Tags: map[string]string{"dr": "yes"}})
+	if len(matched) != 2 || matched[0].Id != "all" || matched[1].Id != "tagged" {
+		t.Errorf("expected one rule per destination, got %v", matched)
+	}
+
+	matched = Match(rules, Object{Key: "data/1.txt", Tags: map[string]string{"dr": "no"}})
+	if len(matched) != 1 || matched[0].Id != "all" {
+		t.Errorf("tag filter should not match, got %v", matched)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := &s3_pb.ReplicationConfiguration{Rules: []*s3_pb.ReplicationRule{
+		{Id: "a", Priority: 1, DestinationBucket: "arn:aws:s3:::dst"},
+		{Id: "b", Priority: 2, DestinationBucket: "arn:aws:s3:::dst"},
+		{Id: "c", Priority: 1, DestinationBucket: "arn:aws:s3:dr::src"},
+	}}
+	if err := Validate(valid, "src"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	invalid := map[string]*s3_pb.ReplicationConfiguration{
+		"no rules":           {},
+		"same bucket":        {Rules: []*s3_pb.ReplicationRule{{DestinationBucket: "arn:aws:s3:::src"}}},
+		"bad arn":            {Rules: []*s3_pb.ReplicationRule{{DestinationBucket: "dst"}}},
+		"duplicate id":       {Rules: []*s3_pb.ReplicationRule{{Id: "a", Priority: 1, DestinationBucket: "arn:aws:s3:::dst"}, {Id: "a", Priority: 2, DestinationBucket: "arn:aws:s3:::dst"}}},
+		"duplicate priority": {Rules: []*s3_pb.ReplicationRule{{Id: "a", DestinationBucket: "arn:aws:s3:::dst"}, {Id: "b", DestinationBucket: "arn:aws:s3:::dst"}}},
+	}
+	for name, config := range invalid {
+		if err := Validate(config, "src"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package s3transform
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/seaweedfs/seaweedfs/weed/images"
+)
+
+// intParameter reads a non-negative integer parameter, 0 if not set
+func intParameter(parameters map[string]string, name string) (int, error) {
+	value := parameters[name]
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+func queryInt(object *Object, name string, defaultValue int) int {
+	if object.Query == nil || object.Query.Get(name) == "" {
+		return defaultValue
+	}
+	if n, err := strconv.Atoi(object.Query.Get(name)); err == nil && n >= 0 {
+		return n
+	}
+	return defaultValue
+}
+
+func imageExt(key string) string {
+	return strings.ToLower(path.Ext(key))
+}
+
+// resizeStep resizes images like the volume server does for the width, height and mode
+// query parameters. With allowQuery, the GET request can set them.
+type resizeStep struct {
+	width, height int
+	mode          string
+	allowQuery    bool
+}
+
+func newResizeStep(parameters map[string]string) (s *resizeStep, err error) {
+	s = &resizeStep{mode: parameters["mode"], allowQuery: parameters["allowQuery"] == "true"}
+	if s.width, err = intParameter(parameters, "width"); err != nil {
+		return nil, err
+	}
+	if s.height, err = intParameter(parameters, "height"); err != nil {
+		return nil, err
+	}
+	switch s.mode {
+	case "", "fit", "fill":
+	default:
+		return nil, fmt.Errorf("invalid resize mode %q", s.mode)
+	}
+	if s.width == 0 && s.height == 0 && !s.allowQuery {
+		return nil, fmt.Errorf("resize requires a width or height")
+	}
+	return s, nil
+}
+
+func (s *resizeStep) Apply(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error) {
+	width, height, mode := s.width, s.height, s.mode
+	if s.allowQuery {
+		width, height = queryInt(object, "width", width), queryInt(object, "height", height)
+		if m := object.Query.Get("mode"); m == "fit" || m == "fill" {
+			mode = m
+		}
+	}
+	ext := imageExt(object.Key)
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+	default:
+		return io.NopCloser(in), nil
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	resized, _, _ := images.Resized(ext, bytes.NewReader(data), width, height, mode)
+	if ext == ".webp" && (width > 0 || height > 0) {
+		// there is no webp encoder
+		object.ContentType = "image/png"
+	}
+	return io.NopCloser(resized), nil
+}
+
+// cropStep crops images to the rectangle x1,y1 - x2,y2. With allowQuery, the GET
+// request can set it with crop_x1, crop_y1, crop_x2 and crop_y2 like on the volume server.
+type cropStep struct {
+	x1, y1, x2, y2 int
+	allowQuery     bool
+}
+
+func newCropStep(parameters map[string]string) (s *cropStep, err error) {
+	s = &cropStep{allowQuery: parameters["allowQuery"] == "true"}
+	for name, p := range map[string]*int{"x1": &s.x1, "y1": &s.y1, "x2": &s.x2, "y2": &s.y2} {
+		if *p, err = intParameter(parameters, name); err != nil {
+			return nil, err
+		}
+	}
+	if !s.allowQuery && (s.x2 <= s.x1 || s.y2 <= s.y1) {
+		return nil, fmt.Errorf("crop requires x2 > x1 and y2 > y1")
+	}
+	return s, nil
+}
+
+func (s *cropStep) Apply(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error) {
+	x1, y1, x2, y2 := s.x1, s.y1, s.x2, s.y2
+	if s.allowQuery {
+		x1, y1 = queryInt(object, "crop_x1", x1), queryInt(object, "crop_y1", y1)
+		x2, y2 = queryInt(object, "crop_x2", x2), queryInt(object, "crop_y2", y2)
+	}
+	ext := imageExt(object.Key)
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif":
+	default:
+		return io.NopCloser(in), nil
+	}
+	if x2 <= x1 || y2 <= y1 {
+		return io.NopCloser(in), nil
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	cropped, err := images.Cropped(ext, bytes.NewReader(data), x1, y1, x2, y2)
+	if err != nil {
+		return nil, fmt.Errorf("crop %s: %w", object.Key, err)
+	}
+	return io.NopCloser(cropped), nil
+}
+
+// decompressStep decompresses gzip or zstd data, detected by its magic number in the "auto" format
+type decompressStep struct {
+	format      string
+	contentType string
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+func newDecompressStep(parameters map[string]string) (*decompressStep, error) {
+	s := &decompressStep{format: parameters["format"], contentType: parameters["contentType"]}
+	switch s.format {
+	case "":
+		s.format = "auto"
+	case "auto", "gzip", "zstd":
+	default:
+		return nil, fmt.Errorf("invalid decompress format %q", s.format)
+	}
+	return s, nil
+}
+
+func (s *decompressStep) Apply(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(in)
+	format := s.format
+	if format == "auto" {
+		magic, _ := buffered.Peek(len(zstdMagic))
+		switch {
+		case bytes.HasPrefix(magic, gzipMagic):
+			format = "gzip"
+		case bytes.HasPrefix(magic, zstdMagic):
+			format = "zstd"
+		default:
+			return io.NopCloser(buffered), nil
+		}
+	}
+	var out io.ReadCloser
+	switch format {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("gzip %s: %w", object.Key, err)
+		}
+		out = gzipReader
+	case "zstd":
+		zstdReader, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("zstd %s: %w", object.Key, err)
+		}
+		out = zstdReader.IOReadCloser()
+	}
+	object.ContentEncoding = ""
+	switch {
+	case s.contentType != "":
+		object.ContentType = s.contentType
+	case object.ContentType == "application/gzip" || object.ContentType == "application/x-gzip" || object.ContentType == "application/zstd":
+		object.ContentType = "application/octet-stream"
+	}
+	return out, nil
+}
+
+// redactStep replaces the matches of a regular expression line by line,
+// the replacement can refer to submatches as $1
+type redactStep struct {
+	pattern     *regexp.Regexp
+	replacement []byte
+}
+
+func newRedactStep(parameters map[string]string) (*redactStep, error) {
+	if parameters["pattern"] == "" {
+		return nil, fmt.Errorf("redact requires a pattern")
+	}
+	pattern, err := regexp.Compile(parameters["pattern"])
+	if err != nil {
+		return nil, fmt.Errorf("redact pattern: %w", err)
+	}
+	replacement, found := parameters["replacement"]
+	if !found {
+		replacement = "[REDACTED]"
+	}
+	return &redactStep{pattern: pattern, replacement: []byte(replacement)}, nil
+}
+
+func (s *redactStep) Apply(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error) {
+	return io.NopCloser(&redactReader{step: s, reader: bufio.NewReader(in)}), nil
+}
+
+type redactReader struct {
+	step   *redactStep
+	reader *bufio.Reader
+	buf    []byte
+	err    error
+}
+
+func (r *redactReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 && r.err == nil {
+		var line []byte
+		line, r.err = r.reader.ReadBytes('\n')
+		r.buf = r.step.pattern.ReplaceAll(line, r.step.replacement)
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	if n == 0 {
+		return 0, r.err
+	}
+	return n, nil
+}
@@ -0,0 +1,138 @@
+package s3transform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const defaultHttpStepTimeout = time.Minute
+
+// httpStepTransport is shared by the http steps. It does not use proxies, so
+// that every connection goes through the check of the dialed address.
+var httpStepTransport = &http.Transport{
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkHttpStepAddress,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          100,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: time.Second,
+}
+
+// httpStep posts the object data to an external endpoint, which responds with the
+// transformed data. The object is described in X-Seaweedfs-* request headers.
+type httpStep struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHttpStep(parameters map[string]string, allowedEndpoints []string) (*httpStep, error) {
+	endpoint := parameters["endpoint"]
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return nil, fmt.Errorf("invalid endpoint %q", endpoint)
+	}
+	if !isAllowedEndpoint(u, allowedEndpoints) {
+		return nil, fmt.Errorf("endpoint %q is not allowed", endpoint)
+	}
+	timeout := defaultHttpStepTimeout
+	if value := parameters["timeout"]; value != "" {
+		if timeout, err = time.ParseDuration(value); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", value)
+		}
+	}
+	return &httpStep{
+		endpoint: endpoint,
+		client: &http.Client{
+			Transport: httpStepTransport,
+			Timeout:   timeout,
+			// a redirect could lead away from the allowed endpoints
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// isAllowedEndpoint is true if the endpoint has the scheme and the host of an
+// allowed endpoint, and its path starts with the path of the allowed endpoint
+func isAllowedEndpoint(u *url.URL, allowedEndpoints []string) bool {
+	endpointPath := u.Path
+	if endpointPath == "" {
+		endpointPath = "/"
+	}
+	for _, segment := range strings.Split(endpointPath, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for _, allowedEndpoint := range allowedEndpoints {
+		allowed, err := url.Parse(allowedEndpoint)
+		if err != nil || allowed.Host == "" {
+			continue
+		}
+		if strings.EqualFold(u.Scheme, allowed.Scheme) && strings.EqualFold(u.Host, allowed.Host) && strings.HasPrefix(endpointPath, allowed.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkHttpStepAddress refuses to connect to link-local addresses, where the
+// metadata services of clouds are, and to unspecified or multicast addresses.
+// An allowed host name could resolve to them.
+func checkHttpStepAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("refuse to connect to %s", address)
+	}
+	return nil
+}
+
+func (s *httpStep) Apply(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, in)
+	if err != nil {
+		return nil, err
+	}
+	if object.ContentType != "" {
+		req.Header.Set("Content-Type", object.ContentType)
+	}
+	if object.ContentEncoding != "" {
+		req.Header.Set("Content-Encoding", object.ContentEncoding)
+	}
+	req.Header.Set("X-Seaweedfs-Bucket", object.Bucket)
+	req.Header.Set("X-Seaweedfs-Key", object.Key)
+	if object.AccessPoint != "" {
+		req.Header.Set("X-Seaweedfs-Access-Point", object.AccessPoint)
+	}
+	if len(object.Query) > 0 {
+		req.Header.Set("X-Seaweedfs-Query", object.Query.Encode())
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("post to %s: %w", s.endpoint, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("post to %s: %s", s.endpoint, resp.Status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		object.ContentType = contentType
+	}
+	object.ContentEncoding = resp.Header.Get("Content-Encoding")
+	return resp.Body, nil
+}
@@ -0,0 +1,199 @@
+package s3transform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+const (
+	// MaxRules is the maximum number of transformation rules of a bucket
+	MaxRules = 100
+	// MaxSteps is the maximum number of steps of a transformation rule
+	MaxSteps = 10
+	// AccessPointAliasSuffix ends the alias of an access point, {bucket}--{access point}--ol-s3,
+	// which is used in place of the bucket name to GET objects through the access point
+	AccessPointAliasSuffix = "--ol-s3"
+)
+
+var accessPointNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,48}[a-z0-9])?$`)
+
+// Object describes the object being transformed. Steps update the content type and encoding.
+type Object struct {
+	Bucket          string
+	Key             string
+	AccessPoint     string
+	ContentType     string
+	ContentEncoding string
+	Query           url.Values // query parameters of the GET request
+}
+
+// Options are the limits the operator of the gateway sets on transformations
+type Options struct {
+	// HttpEndpoints are the URL prefixes http steps may post to, http steps are refused without any
+	HttpEndpoints []string
+}
+
+// Step transforms the data of an object
+type Step interface {
+	Apply(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error)
+}
+
+// Validate checks a transformation configuration before it is stored
+func Validate(config *s3_pb.TransformationConfiguration, options Options) error {
+	if len(config.Rules) > MaxRules {
+		return fmt.Errorf("at most %d transformation rules are allowed", MaxRules)
+	}
+	ids := make(map[string]bool)
+	for _, rule := range config.Rules {
+		if rule.Id == "" {
+			return fmt.Errorf("transformation rule requires an id")
+		}
+		if ids[rule.Id] {
+			return fmt.Errorf("duplicated transformation rule id %q", rule.Id)
+		}
+		ids[rule.Id] = true
+		if rule.AccessPoint != "" && (!accessPointNameRegexp.MatchString(rule.AccessPoint) || strings.Contains(rule.AccessPoint, "--")) {
+			return fmt.Errorf("rule %s: invalid access point name %q", rule.Id, rule.AccessPoint)
+		}
+		if _, err := NewPipeline(rule, options); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.Id, err)
+		}
+	}
+	return nil
+}
+
+// MatchRule returns the rule transforming GETs of the key through the access point,
+// the one with the longest prefix, or nil. Keys do not start with "/".
+func MatchRule(config *s3_pb.TransformationConfiguration, key, accessPoint string) (matched *s3_pb.TransformationRule) {
+	if config == nil {
+		return nil
+	}
+	for _, rule := range config.Rules {
+		if rule.AccessPoint != accessPoint || !strings.HasPrefix(key, rule.Prefix) {
+			continue
+		}
+		if matched == nil || len(rule.Prefix) > len(matched.Prefix) {
+			matched = rule
+		}
+	}
+	return matched
+}
+
+// HasHttpStep is true if a rule of the configuration posts the data to an external endpoint
+func HasHttpStep(config *s3_pb.TransformationConfiguration) bool {
+	for _, rule := range config.Rules {
+		for _, step := range rule.Steps {
+			if step.Type == "http" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasAccessPoint is true if a rule of the configuration applies to the access point
+func HasAccessPoint(config *s3_pb.TransformationConfiguration, accessPoint string) bool {
+	if config == nil {
+		return false
+	}
+	for _, rule := range config.Rules {
+		if rule.AccessPoint == accessPoint {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessPointAlias returns the name used in place of the bucket name to GET through the access point
+func AccessPointAlias(bucket, accessPoint string) string {
+	return bucket + "--" + accessPoint + AccessPointAliasSuffix
+}
+
+// ParseAccessPointAlias splits an access point alias into the bucket and the access point
+func ParseAccessPointAlias(name string) (bucket, accessPoint string, ok bool) {
+	name, found := strings.CutSuffix(name, AccessPointAliasSuffix)
+	if !found {
+		return "", "", false
+	}
+	i := strings.LastIndex(name, "--")
+	if i <= 0 || i+2 == len(name) {
+		return "", "", false
+	}
+	return name[:i], name[i+2:], true
+}
+
+// Pipeline runs the steps of a rule one after the other
+type Pipeline struct {
+	steps []Step
+}
+
+// NewPipeline creates the steps of a rule. The options are checked again,
+// so a stored rule stops working once the operator no longer allows it.
+func NewPipeline(rule *s3_pb.TransformationRule, options Options) (*Pipeline, error) {
+	if len(rule.Steps) == 0 {
+		return nil, fmt.Errorf("no transformation steps")
+	}
+	if len(rule.Steps) > MaxSteps {
+		return nil, fmt.Errorf("at most %d transformation steps are allowed", MaxSteps)
+	}
+	p := &Pipeline{}
+	for i, step := range rule.Steps {
+		s, err := newStep(step, options)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i+1, err)
+		}
+		p.steps = append(p.steps, s)
+	}
+	return p, nil
+}
+
+func newStep(step *s3_pb.TransformationStep, options Options) (Step, error) {
+	switch step.Type {
+	case "http":
+		return newHttpStep(step.Parameters, options.HttpEndpoints)
+	case "resize":
+		return newResizeStep(step.Parameters)
+	case "crop":
+		return newCropStep(step.Parameters)
+	case "decompress":
+		return newDecompressStep(step.Parameters)
+	case "redact":
+		return newRedactStep(step.Parameters)
+	}
+	return nil, fmt.Errorf("unknown transformation %q", step.Type)
+}
+
+// Run streams the data through the steps. Closing the result releases all steps.
+func (p *Pipeline) Run(ctx context.Context, in io.Reader, object *Object) (io.ReadCloser, error) {
+	out := &chainedReadCloser{Reader: in}
+	for _, step := range p.steps {
+		next, err := step.Apply(ctx, out.Reader, object)
+		if err != nil {
+			out.Close()
+			return nil, err
+		}
+		out.Reader = next
+		out.closers = append(out.closers, next)
+	}
+	return out, nil
+}
+
+type chainedReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (c *chainedReadCloser) Close() error {
+	var errs []error
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		errs = append(errs, c.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,208 @@
+package s3transform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func step(stepType string, parameters ...string) *s3_pb.TransformationStep {
+	s := &s3_pb.TransformationStep{Type: stepType, Parameters: make(map[string]string)}
+	for i := 0; i+1 < len(parameters); i += 2 {
+		s.Parameters[parameters[i]] = parameters[i+1]
+	}
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []*s3_pb.TransformationRule
+		wantErr bool
+	}{
+		{"redact", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("redact", "pattern", "[0-9]+")}}}, false},
+		{"missing id", []*s3_pb.TransformationRule{{Steps: []*s3_pb.TransformationStep{step("decompress")}}}, true},
+		{"duplicated id", []*s3_pb.TransformationRule{
+			{Id: "a", Steps: []*s3_pb.TransformationStep{step("decompress")}},
+			{Id: "a", Steps: []*s3_pb.TransformationStep{step("decompress")}},
+		}, true},
+		{"no steps", []*s3_pb.TransformationRule{{Id: "a"}}, true},
+		{"unknown step", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("encrypt")}}}, true},
+		{"bad pattern", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("redact", "pattern", "(")}}}, true},
+		{"bad endpoint", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", "ftp://host")}}}, true},
+		{"allowed endpoint", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", "https://transform.example.com/v1/upper")}}}, false},
+		{"endpoint of another host", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", "http://169.254.169.254/latest/meta-data")}}}, true},
+		{"endpoint outside the allowed path", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", "https://transform.example.com/admin")}}}, true},
+		{"endpoint leaving the allowed path", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", "https://transform.example.com/v1/../admin")}}}, true},
+		{"endpoint with another scheme", []*s3_pb.TransformationRule{{Id: "a", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", "http://transform.example.com/v1/upper")}}}, true},
+		{"access point", []*s3_pb.TransformationRule{{Id: "a", AccessPoint: "thumbs", Steps: []*s3_pb.TransformationStep{step("resize", "width", "100")}}}, false},
+		{"bad access point", []*s3_pb.TransformationRule{{Id: "a", AccessPoint: "a--b", Steps: []*s3_pb.TransformationStep{step("decompress")}}}, true},
+	}
+	for _, tt := range tests {
+		err := Validate(&s3_pb.TransformationConfiguration{Rules: tt.rules}, Options{HttpEndpoints: []string{"https://transform.example.com/v1/"}})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestMatchRule(t *testing.T) {
+	config := &s3_pb.TransformationConfiguration{Rules: []*s3_pb.TransformationRule{
+		{Id: "all", Prefix: ""},
+		{Id: "logs", Prefix: "logs/"},
+		{Id: "app-logs", Prefix: "logs/app/"},
+		{Id: "thumbs", Prefix: "photos/", AccessPoint: "thumbs"},
+	}}
+	tests := []struct {
+		key, accessPoint, want string
+	}{
+		{"readme.txt", "", "all"},
+		{"logs/system.log", "", "logs"},
+		{"logs/app/1.log", "", "app-logs"},
+		{"photos/a.jpg", "", "all"},
+		{"photos/a.jpg", "thumbs", "thumbs"},
+		{"docs/a.pdf", "thumbs", ""},
+		{"photos/a.jpg", "other", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if rule := MatchRule(config, tt.key, tt.accessPoint); rule != nil {
+			got = rule.Id
+		}
+		if got != tt.want {
+			t.Errorf("MatchRule(%q, %q) = %q, want %q", tt.key, tt.accessPoint, got, tt.want)
+		}
+	}
+	if MatchRule(nil, "a", "") != nil {
+		t.Errorf("nil configuration should not match")
+	}
+}
+
+func TestParseAccessPointAlias(t *testing.T) {
+	tests := []struct {
+		name, bucket, accessPoint string
+		ok                        bool
+	}{
+		{"photos--thumbs--ol-s3", "photos", "thumbs", true},
+		{"my-photos--small-thumbs--ol-s3", "my-photos", "small-thumbs", true},
+		{"photos", "", "", false},
+		{"photos--ol-s3", "", "", false},
+		{"--thumbs--ol-s3", "", "", false},
+		{"photos----ol-s3", "", "", false},
+	}
+	for _, tt := range tests {
+		bucket, accessPoint, ok := ParseAccessPointAlias(tt.name)
+		if bucket != tt.bucket || accessPoint != tt.accessPoint || ok != tt.ok {
+			t.Errorf("ParseAccessPointAlias(%q) = %q, %q, %v", tt.name, bucket, accessPoint, ok)
+		}
+	}
+	if alias := AccessPointAlias("photos", "thumbs"); alias != "photos--thumbs--ol-s3" {
+		t.Errorf("AccessPointAlias = %q", alias)
+	}
+}
+
+func runPipeline(t *testing.T, object *Object, in []byte, steps ...*s3_pb.TransformationStep) string {
+	return runPipelineWithOptions(t, Options{}, object, in, steps...)
+}
+
+func runPipelineWithOptions(t *testing.T, options Options, object *Object, in []byte, steps ...*s3_pb.TransformationStep) string {
+	p, err := NewPipeline(&s3_pb.TransformationRule{Id: "test", Steps: steps}, options)
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	out, err := p.Run(context.Background(), bytes.NewReader(in), object)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	defer out.Close()
+	data, err := io.ReadAll(out)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data)
+}
+
+func TestDecompressAndRedact(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("user alice card 4111111111111111\nuser bob card 5500000000000004\n"))
+	gz.Close()
+
+	object := &Object{Key: "logs/a.log.gz", ContentType: "application/gzip", ContentEncoding: "gzip"}
+	got := runPipeline(t, object, buf.Bytes(),
+		step("decompress", "contentType", "text/plain"),
+		step("redact", "pattern", "[0-9]{12,19}", "replacement", "****"))
+	if want := "user alice card ****\nuser bob card ****\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if object.ContentEncoding != "" || object.ContentType != "text/plain" {
+		t.Errorf("unexpected content type %q encoding %q", object.ContentType, object.ContentEncoding)
+	}
+}
+
+func TestHttpStep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Seaweedfs-Bucket") != "docs" || r.Header.Get("X-Seaweedfs-Key") != "a.txt" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/markdown")
+		w.Write([]byte(strings.ToUpper(string(data))))
+	}))
+	defer server.Close()
+
+	options := Options{HttpEndpoints: []string{server.URL + "/"}}
+	object := &Object{Bucket: "docs", Key: "a.txt", ContentType: "text/plain"}
+	if got := runPipelineWithOptions(t, options, object, []byte("hello"), step("http", "endpoint", server.URL)); got != "HELLO" {
+		t.Errorf("got %q", got)
+	}
+	if object.ContentType != "text/markdown" {
+		t.Errorf("content type %q", object.ContentType)
+	}
+
+	p, _ := NewPipeline(&s3_pb.TransformationRule{Id: "test", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", server.URL)}}, options)
+	if _, err := p.Run(context.Background(), strings.NewReader("hello"), &Object{Bucket: "other", Key: "a.txt"}); err == nil {
+		t.Errorf("expected an error for a failed transformation")
+	}
+}
+
+func TestHttpStepLimits(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	rule := &s3_pb.TransformationRule{Id: "test", Steps: []*s3_pb.TransformationStep{step("http", "endpoint", server.URL)}}
+	if _, err := NewPipeline(rule, Options{}); err == nil {
+		t.Errorf("http steps should be refused without allowed endpoints")
+	}
+	p, err := NewPipeline(rule, Options{HttpEndpoints: []string{server.URL}})
+	if err != nil {
+		t.Fatalf("NewPipeline: %v", err)
+	}
+	if _, err := p.Run(context.Background(), strings.NewReader("hello"), &Object{Bucket: "docs", Key: "a.txt"}); err == nil || redirected {
+		t.Errorf("redirects should not be followed, error %v", err)
+	}
+
+	for _, address := range []string{"169.254.169.254:80", "[fe80::1]:80", "0.0.0.0:80", "224.0.0.1:80"} {
+		if err := checkHttpStepAddress("tcp", address, nil); err == nil {
+			t.Errorf("connecting to %s should be refused", address)
+		}
+	}
+	if err := checkHttpStepAddress("tcp", "10.0.0.1:80", nil); err != nil {
+		t.Errorf("connecting to an allowed endpoint in the cluster: %v", err)
+	}
+}
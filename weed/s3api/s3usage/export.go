@@ -0,0 +1,103 @@
+package s3usage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// ExportConfig sets where the usage report of every day is written to
+type ExportConfig struct {
+	Enabled bool   `json:"enabled"`
+	Bucket  string `json:"bucket"`
+	Prefix  string `json:"prefix"`
+	Format  string `json:"format"`
+}
+
+func (c *ExportConfig) Validate() error {
+	if c.Bucket == "" {
+		return fmt.Errorf("missing export bucket")
+	}
+	if c.Format != FormatCSV && c.Format != FormatJSON {
+		return fmt.Errorf("unknown export format %q", c.Format)
+	}
+	return nil
+}
+
+// Key is the object key of the report of a day
+func (c *ExportConfig) Key(date string) string {
+	return strings.TrimPrefix(c.Prefix, "/") + date + "." + c.Format
+}
+
+// Write writes the report in the format
+func Write(w io.Writer, report *Report, format string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case FormatCSV:
+		return WriteCSV(w, report)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// WriteCSV writes one row per value, so new storage classes and request
+// types do not change the columns:
+//
+//	date,scope,name,metric,dimension,value
+//	2025-06-01,bucket,photos,storage_bytes,STANDARD,1048576
+func WriteCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"date", "scope", "name", "metric", "dimension", "value"}); err != nil {
+		return err
+	}
+	for _, scope := range []struct {
+		name   string
+		usages map[string]*Usage
+	}{{"bucket", report.Buckets}, {"identity", report.Identities}} {
+		for _, name := range SortedNames(scope.usages) {
+			for _, row := range usageRows(scope.usages[name]) {
+				if err := writer.Write(append([]string{report.Date, scope.name, name}, row...)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// usageRows returns the metric, dimension and value of every value of the usage
+func usageRows(u *Usage) (rows [][]string) {
+	for _, class := range sortedKeys(u.Storage) {
+		s := u.Storage[class]
+		rows = append(rows,
+			[]string{"storage_bytes", class, strconv.FormatInt(s.Bytes, 10)},
+			[]string{"storage_objects", class, strconv.FormatInt(s.Objects, 10)})
+	}
+	for _, requestType := range sortedKeys(u.Requests) {
+		rows = append(rows, []string{"requests", requestType, strconv.FormatInt(u.Requests[requestType], 10)})
+	}
+	return append(rows,
+		[]string{"ingress_bytes", "", strconv.FormatInt(u.IngressBytes, 10)},
+		[]string{"egress_bytes", "", strconv.FormatInt(u.EgressBytes, 10)})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
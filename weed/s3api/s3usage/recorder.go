@@ -0,0 +1,56 @@
+package s3usage
+
+import (
+	"sync"
+	"time"
+)
+
+// Recorder counts the requests served by one gateway per day
+type Recorder struct {
+	mu      sync.Mutex
+	reports map[string]*Report
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{reports: make(map[string]*Report)}
+}
+
+// Record counts one request with the bytes received and sent
+func (r *Recorder) Record(t time.Time, bucket, identity, requestType string, ingress, egress int64) {
+	if ingress < 0 {
+		ingress = 0
+	}
+	date := Date(t)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report, found := r.reports[date]
+	if !found {
+		report = NewReport(date)
+		r.reports[date] = report
+	}
+	report.AddRequests(bucket, identity, requestType, 1, ingress, egress)
+}
+
+// Snapshot returns a copy of the counts of every day with requests
+func (r *Recorder) Snapshot() []*Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var reports []*Report
+	for date, report := range r.reports {
+		snapshot := NewReport(date)
+		snapshot.Merge(report)
+		reports = append(reports, snapshot)
+	}
+	return reports
+}
+
+// Forget drops the counts of the days before today, once they are saved
+func (r *Recorder) Forget(today string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for date := range r.reports {
+		if date < today {
+			delete(r.reports, date)
+		}
+	}
+}
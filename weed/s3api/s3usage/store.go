@@ -0,0 +1,139 @@
+package s3usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+// The usage is kept in the filer:
+//
+//	/etc/s3/usage/storage.json                  stored data, maintained from the metadata events
+//	/etc/s3/usage/requests/<date>/<gateway>.json requests counted by each gateway
+//	/etc/s3/usage/daily/<date>.json             the usage of a finished day
+//	/etc/s3/usage/export.json                   where the daily reports are exported to
+const (
+	Dir              = "/etc/s3/usage"
+	StorageFile      = "storage.json"
+	RequestsDir      = Dir + "/requests"
+	DailyDir         = Dir + "/daily"
+	ExportConfigFile = "export.json"
+)
+
+func readJSON(client filer_pb.SeaweedFilerClient, dir, name string, v any) (found bool, err error) {
+	data, err := filer.ReadInsideFiler(client, dir, name)
+	if err == filer_pb.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("parse %s/%s: %w", dir, name, err)
+	}
+	return true, nil
+}
+
+func saveJSON(client filer_pb.SeaweedFilerClient, dir, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return filer.SaveInsideFiler(client, dir, name, data)
+}
+
+// LoadStorageState returns nil if the stored data has not been counted yet
+func LoadStorageState(client filer_pb.SeaweedFilerClient) (*StorageState, error) {
+	state := NewStorageState()
+	found, err := readJSON(client, Dir, StorageFile, state)
+	if err != nil || !found {
+		return nil, err
+	}
+	return state, nil
+}
+
+func SaveStorageState(client filer_pb.SeaweedFilerClient, state *StorageState) error {
+	return saveJSON(client, Dir, StorageFile, state)
+}
+
+// SaveRequests saves the requests a gateway has counted on the day of the report
+func SaveRequests(client filer_pb.SeaweedFilerClient, gateway string, report *Report) error {
+	return saveJSON(client, RequestsDir+"/"+report.Date, gateway+".json", report)
+}
+
+// LoadRequests adds up the requests counted by all gateways on a day
+func LoadRequests(client filer_pb.SeaweedFilerClient, date string) (*Report, error) {
+	report := NewReport(date)
+	dir := RequestsDir + "/" + date
+	var names []string
+	err := filer_pb.SeaweedList(context.Background(), client, dir, "", func(entry *filer_pb.Entry, isLast bool) error {
+		if !entry.IsDirectory && strings.HasSuffix(entry.Name, ".json") {
+			names = append(names, entry.Name)
+		}
+		return nil
+	}, "", false, math.MaxUint32)
+	if err != nil && err != filer_pb.ErrNotFound {
+		return nil, fmt.Errorf("list %s: %w", dir, err)
+	}
+	for _, name := range names {
+		gatewayReport := NewReport(date)
+		if _, err := readJSON(client, dir, name, gatewayReport); err != nil {
+			return nil, err
+		}
+		report.Merge(gatewayReport)
+	}
+	return report, nil
+}
+
+// LoadDailyReport returns nil if the usage of the day has not been saved
+func LoadDailyReport(client filer_pb.SeaweedFilerClient, date string) (*Report, error) {
+	report := NewReport(date)
+	found, err := readJSON(client, DailyDir, date+".json", report)
+	if err != nil || !found {
+		return nil, err
+	}
+	return report, nil
+}
+
+func SaveDailyReport(client filer_pb.SeaweedFilerClient, report *Report) error {
+	return saveJSON(client, DailyDir, report.Date+".json", report)
+}
+
+// LoadReport returns the usage of a day: the saved report of a finished day,
+// or the requests counted so far with the current stored data.
+func LoadReport(client filer_pb.SeaweedFilerClient, date string) (*Report, error) {
+	report, err := LoadDailyReport(client, date)
+	if err != nil || report != nil {
+		return report, err
+	}
+	if report, err = LoadRequests(client, date); err != nil {
+		return nil, err
+	}
+	state, err := LoadStorageState(client)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil {
+		report.AddStorage(state)
+	}
+	return report, nil
+}
+
+// LoadExportConfig returns nil if the export is not configured
+func LoadExportConfig(client filer_pb.SeaweedFilerClient) (*ExportConfig, error) {
+	config := &ExportConfig{}
+	found, err := readJSON(client, Dir, ExportConfigFile, config)
+	if err != nil || !found {
+		return nil, err
+	}
+	return config, nil
+}
+
+func SaveExportConfig(client filer_pb.SeaweedFilerClient, config *ExportConfig) error {
+	return saveJSON(client, Dir, ExportConfigFile, config)
+}
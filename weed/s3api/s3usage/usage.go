@@ -0,0 +1,214 @@
+package s3usage
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DateLayout names the days of the usage reports, in UTC
+	DateLayout = "2006-01-02"
+	// DefaultStorageClass is the storage class of objects stored without one
+	DefaultStorageClass = "STANDARD"
+	// Anonymous is the identity of requests without credentials and objects without owner
+	Anonymous = "anonymous"
+)
+
+// Date returns the usage day of a time
+func Date(t time.Time) string {
+	return t.UTC().Format(DateLayout)
+}
+
+// StorageUsage is the stored data of one storage class
+type StorageUsage struct {
+	Bytes   int64 `json:"bytes"`
+	Objects int64 `json:"objects"`
+}
+
+// Usage is what a bucket or an identity is billed for
+type Usage struct {
+	// Storage is keyed by the storage class
+	Storage map[string]*StorageUsage `json:"storage,omitempty"`
+	// Requests is keyed by the request type, e.g. PUT or LIST
+	Requests     map[string]int64 `json:"requests,omitempty"`
+	IngressBytes int64            `json:"ingressBytes"`
+	EgressBytes  int64            `json:"egressBytes"`
+}
+
+func (u *Usage) addStorage(class string, bytes, objects int64) {
+	if u.Storage == nil {
+		u.Storage = make(map[string]*StorageUsage)
+	}
+	s, found := u.Storage[class]
+	if !found {
+		s = &StorageUsage{}
+		u.Storage[class] = s
+	}
+	s.Bytes += bytes
+	s.Objects += objects
+}
+
+func (u *Usage) addRequests(requestType string, count, ingress, egress int64) {
+	if u.Requests == nil {
+		u.Requests = make(map[string]int64)
+	}
+	u.Requests[requestType] += count
+	u.IngressBytes += ingress
+	u.EgressBytes += egress
+}
+
+func (u *Usage) merge(other *Usage) {
+	for class, s := range other.Storage {
+		u.addStorage(class, s.Bytes, s.Objects)
+	}
+	for requestType, count := range other.Requests {
+		u.addRequests(requestType, count, 0, 0)
+	}
+	u.IngressBytes += other.IngressBytes
+	u.EgressBytes += other.EgressBytes
+}
+
+// Report is the usage of all buckets and identities on one day
+type Report struct {
+	Date       string            `json:"date"`
+	Buckets    map[string]*Usage `json:"buckets"`
+	Identities map[string]*Usage `json:"identities"`
+}
+
+func NewReport(date string) *Report {
+	return &Report{
+		Date:       date,
+		Buckets:    make(map[string]*Usage),
+		Identities: make(map[string]*Usage),
+	}
+}
+
+func usageOf(usages map[string]*Usage, name string) *Usage {
+	u, found := usages[name]
+	if !found {
+		u = &Usage{}
+		usages[name] = u
+	}
+	return u
+}
+
+// AddRequests counts requests of a bucket and identity. The bucket is empty
+// for requests about no bucket, e.g. listing the buckets.
+func (r *Report) AddRequests(bucket, identity, requestType string, count, ingress, egress int64) {
+	if bucket != "" {
+		usageOf(r.Buckets, bucket).addRequests(requestType, count, ingress, egress)
+	}
+	if identity == "" {
+		identity = Anonymous
+	}
+	usageOf(r.Identities, identity).addRequests(requestType, count, ingress, egress)
+}
+
+// Merge adds the usage of another report, e.g. the requests counted by another gateway
+func (r *Report) Merge(other *Report) {
+	for name, u := range other.Buckets {
+		usageOf(r.Buckets, name).merge(u)
+	}
+	for name, u := range other.Identities {
+		usageOf(r.Identities, name).merge(u)
+	}
+}
+
+// AddStorage adds the stored data of a storage state
+func (r *Report) AddStorage(state *StorageState) {
+	for bucket, owners := range state.Buckets {
+		for owner, classes := range owners {
+			for class, s := range classes {
+				usageOf(r.Buckets, bucket).addStorage(class, s.Bytes, s.Objects)
+				usageOf(r.Identities, owner).addStorage(class, s.Bytes, s.Objects)
+			}
+		}
+	}
+}
+
+// Filter keeps only the usage of a bucket and of an identity, if they are not empty
+func (r *Report) Filter(bucket, identity string) {
+	if bucket != "" {
+		for name := range r.Buckets {
+			if name != bucket {
+				delete(r.Buckets, name)
+			}
+		}
+		if identity == "" {
+			r.Identities = make(map[string]*Usage)
+		}
+	}
+	if identity != "" {
+		for name := range r.Identities {
+			if name != identity {
+				delete(r.Identities, name)
+			}
+		}
+		if bucket == "" {
+			r.Buckets = make(map[string]*Usage)
+		}
+	}
+}
+
+// StorageState is the stored data per bucket, object owner and storage class,
+// maintained from the filer metadata events up to TsNs
+type StorageState struct {
+	TsNs    int64                                          `json:"tsNs"`
+	Buckets map[string]map[string]map[string]*StorageUsage `json:"buckets"`
+}
+
+func NewStorageState() *StorageState {
+	return &StorageState{Buckets: make(map[string]map[string]map[string]*StorageUsage)}
+}
+
+// Add changes the stored data of a bucket, owner and storage class. Objects
+// that are removed or replaced are added with negative bytes and objects.
+func (s *StorageState) Add(bucket, owner, class string, bytes, objects int64) {
+	if owner == "" {
+		owner = Anonymous
+	}
+	if class == "" {
+		class = DefaultStorageClass
+	}
+	owners, found := s.Buckets[bucket]
+	if !found {
+		owners = make(map[string]map[string]*StorageUsage)
+		s.Buckets[bucket] = owners
+	}
+	classes, found := owners[owner]
+	if !found {
+		classes = make(map[string]*StorageUsage)
+		owners[owner] = classes
+	}
+	u, found := classes[class]
+	if !found {
+		u = &StorageUsage{}
+		classes[class] = u
+	}
+	u.Bytes += bytes
+	u.Objects += objects
+	if u.Objects <= 0 {
+		delete(classes, class)
+		if len(classes) == 0 {
+			delete(owners, owner)
+		}
+		if len(owners) == 0 {
+			delete(s.Buckets, bucket)
+		}
+	}
+}
+
+// RemoveBucket forgets a deleted bucket
+func (s *StorageState) RemoveBucket(bucket string) {
+	delete(s.Buckets, bucket)
+}
+
+// SortedNames returns the names of the usages in order
+func SortedNames(usages map[string]*Usage) []string {
+	var names []string
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
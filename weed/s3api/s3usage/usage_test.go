@@ -0,0 +1,124 @@
+package s3usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStorageState(t *testing.T) {
+	state := NewStorageState()
+	state.Add("photos", "alice", "", 100, 1)
+	state.Add("photos", "alice", "STANDARD_IA", 50, 1)
+	state.Add("photos", "", "", 10, 1)
+	state.Add("logs", "bob", "", 7, 1)
+
+	// replacing an object removes the old one and adds the new one
+	state.Add("photos", "alice", "", -100, -1)
+	state.Add("photos", "alice", "", 120, 1)
+
+	if u := state.Buckets["photos"]["alice"][DefaultStorageClass]; u.Bytes != 120 || u.Objects != 1 {
+		t.Errorf("unexpected usage %+v", u)
+	}
+	if u := state.Buckets["photos"][Anonymous][DefaultStorageClass]; u == nil || u.Bytes != 10 {
+		t.Errorf("objects without owner should be counted as anonymous: %+v", state.Buckets["photos"])
+	}
+
+	state.Add("logs", "bob", "", -7, -1)
+	if _, found := state.Buckets["logs"]; found {
+		t.Errorf("empty bucket should be dropped")
+	}
+	state.RemoveBucket("photos")
+	if len(state.Buckets) != 0 {
+		t.Errorf("unexpected buckets %+v", state.Buckets)
+	}
+}
+
+func TestReport(t *testing.T) {
+	day := time.Date(2025, 6, 1, 23, 59, 0, 0, time.UTC)
+	recorder := NewRecorder()
+	recorder.Record(day, "photos", "alice", "PUT", 1000, 0)
+	recorder.Record(day, "photos", "alice", "GET", -1, 300)
+	recorder.Record(day, "", "", "LIST", 0, 20)
+	recorder.Record(day.Add(time.Minute), "photos", "bob", "GET", 0, 5)
+
+	reports := recorder.Snapshot()
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(reports))
+	}
+	recorder.Forget("2025-06-02")
+	if reports = recorder.Snapshot(); len(reports) != 1 || reports[0].Date != "2025-06-02" {
+		t.Fatalf("unexpected reports after forgetting: %+v", reports)
+	}
+
+	report := NewReport("2025-06-01")
+	gateway := NewReport("2025-06-01")
+	gateway.AddRequests("photos", "alice", "PUT", 1, 1000, 0)
+	gateway.AddRequests("photos", "alice", "GET", 1, 0, 300)
+	report.Merge(gateway)
+	report.Merge(gateway)
+	state := NewStorageState()
+	state.Add("photos", "alice", "", 2000, 2)
+	report.AddStorage(state)
+
+	photos := report.Buckets["photos"]
+	if photos.Requests["PUT"] != 2 || photos.Requests["GET"] != 2 || photos.IngressBytes != 2000 || photos.EgressBytes != 600 {
+		t.Errorf("unexpected bucket usage %+v", photos)
+	}
+	if s := report.Identities["alice"].Storage[DefaultStorageClass]; s.Bytes != 2000 || s.Objects != 2 {
+		t.Errorf("unexpected identity storage %+v", s)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	want := `date,scope,name,metric,dimension,value
+2025-06-01,bucket,photos,storage_bytes,STANDARD,2000
+2025-06-01,bucket,photos,storage_objects,STANDARD,2
+2025-06-01,bucket,photos,requests,GET,2
+2025-06-01,bucket,photos,requests,PUT,2
+2025-06-01,bucket,photos,ingress_bytes,,2000
+2025-06-01,bucket,photos,egress_bytes,,600
+2025-06-01,identity,alice,storage_bytes,STANDARD,2000
+2025-06-01,identity,alice,storage_objects,STANDARD,2
+2025-06-01,identity,alice,requests,GET,2
+2025-06-01,identity,alice,requests,PUT,2
+2025-06-01,identity,alice,ingress_bytes,,2000
+2025-06-01,identity,alice,egress_bytes,,600
+`
+	if buf.String() != want {
+		t.Errorf("unexpected csv:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, report, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	parsed := NewReport("")
+	if err := json.Unmarshal(buf.Bytes(), parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Date != report.Date || parsed.Buckets["photos"].EgressBytes != 600 {
+		t.Errorf("unexpected json report %s", buf.String())
+	}
+
+	report.Filter("", "alice")
+	if len(report.Buckets) != 0 || len(report.Identities) != 1 {
+		t.Errorf("unexpected filtered report %+v", report)
+	}
+}
+
+func TestExportConfig(t *testing.T) {
+	config := &ExportConfig{Bucket: "billing", Prefix: "usage/", Format: FormatCSV}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if key := config.Key("2025-06-01"); key != "usage/2025-06-01.csv" {
+		t.Errorf("unexpected key %s", key)
+	}
+	if err := (&ExportConfig{Bucket: "billing", Format: "xml"}).Validate(); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,151 @@
+package s3website
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+// MaxRoutingRules is the maximum number of routing rules of a website configuration
+const MaxRoutingRules = 50
+
+const defaultRedirectCode = http.StatusMovedPermanently
+
+// Validate checks a website configuration before it is stored
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketWebsite.html
+func Validate(config *s3_pb.WebsiteConfiguration) error {
+	if config.RedirectAllRequestsTo != nil {
+		if config.IndexDocumentSuffix != "" || config.ErrorDocumentKey != "" || len(config.RoutingRules) > 0 {
+			return fmt.Errorf("RedirectAllRequestsTo can not be combined with other website settings")
+		}
+		if config.RedirectAllRequestsTo.HostName == "" {
+			return fmt.Errorf("RedirectAllRequestsTo requires a HostName")
+		}
+		return validateProtocol(config.RedirectAllRequestsTo.Protocol)
+	}
+	if config.IndexDocumentSuffix == "" {
+		return fmt.Errorf("IndexDocument is required")
+	}
+	if strings.Contains(config.IndexDocumentSuffix, "/") {
+		return fmt.Errorf("IndexDocument suffix %q can not contain a slash", config.IndexDocumentSuffix)
+	}
+	if len(config.RoutingRules) > MaxRoutingRules {
+		return fmt.Errorf("at most %d routing rules are allowed", MaxRoutingRules)
+	}
+	for _, rule := range config.RoutingRules {
+		if err := validateRoutingRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateRoutingRule(rule *s3_pb.WebsiteRoutingRule) error {
+	if rule.HttpErrorCodeReturnedEquals != "" {
+		code, err := strconv.Atoi(rule.HttpErrorCodeReturnedEquals)
+		if err != nil || code < 400 || code > 599 {
+			return fmt.Errorf("invalid HttpErrorCodeReturnedEquals %q", rule.HttpErrorCodeReturnedEquals)
+		}
+	}
+	redirect := rule.Redirect
+	if redirect == nil {
+		return fmt.Errorf("routing rule requires a Redirect")
+	}
+	if redirect.ReplaceKeyWith != "" && redirect.ReplaceKeyPrefixWith != "" {
+		return fmt.Errorf("ReplaceKeyWith and ReplaceKeyPrefixWith can not both be set")
+	}
+	if redirect.HttpRedirectCode != "" {
+		code, err := strconv.Atoi(redirect.HttpRedirectCode)
+		if err != nil || code < 300 || code > 399 {
+			return fmt.Errorf("invalid HttpRedirectCode %q", redirect.HttpRedirectCode)
+		}
+	}
+	return validateProtocol(redirect.Protocol)
+}
+
+func validateProtocol(protocol string) error {
+	switch protocol {
+	case "", "http", "https":
+		return nil
+	}
+	return fmt.Errorf("invalid Protocol %q", protocol)
+}
+
+// IndexKey returns the key of the object served for a requested key,
+// appending the index document suffix to folders
+func IndexKey(config *s3_pb.WebsiteConfiguration, key string) string {
+	if key == "" || strings.HasSuffix(key, "/") {
+		return key + config.IndexDocumentSuffix
+	}
+	return key
+}
+
+// MatchRoutingRule returns the first routing rule whose condition matches the key.
+// errorCode is 0 before the object is fetched, where only the rules without an
+// error code condition apply.
+func MatchRoutingRule(config *s3_pb.WebsiteConfiguration, key string, errorCode int) *s3_pb.WebsiteRoutingRule {
+	for _, rule := range config.RoutingRules {
+		if !strings.HasPrefix(key, rule.KeyPrefixEquals) {
+			continue
+		}
+		if rule.HttpErrorCodeReturnedEquals == "" {
+			if errorCode == 0 {
+				return rule
+			}
+			continue
+		}
+		if rule.HttpErrorCodeReturnedEquals == strconv.Itoa(errorCode) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Redirect is where a website request is redirected to
+type Redirect struct {
+	Location string
+	Code     int
+}
+
+// RedirectAll returns the redirect of a request when all requests are redirected to another host
+func RedirectAll(config *s3_pb.WebsiteConfiguration, requestProtocol, key string) Redirect {
+	target := config.RedirectAllRequestsTo
+	return Redirect{
+		Location: location(target.Protocol, requestProtocol, target.HostName, key),
+		Code:     defaultRedirectCode,
+	}
+}
+
+// RuleRedirect returns the redirect of a request that matched a routing rule
+func RuleRedirect(rule *s3_pb.WebsiteRoutingRule, requestProtocol, requestHost, key string) Redirect {
+	redirect := rule.Redirect
+	switch {
+	case redirect.ReplaceKeyWith != "":
+		key = redirect.ReplaceKeyWith
+	case redirect.ReplaceKeyPrefixWith != "":
+		key = redirect.ReplaceKeyPrefixWith + strings.TrimPrefix(key, rule.KeyPrefixEquals)
+	}
+	host := redirect.HostName
+	if host == "" {
+		host = requestHost
+	}
+	code := defaultRedirectCode
+	if redirect.HttpRedirectCode != "" {
+		code, _ = strconv.Atoi(redirect.HttpRedirectCode)
+	}
+	return Redirect{
+		Location: location(redirect.Protocol, requestProtocol, host, key),
+		Code:     code,
+	}
+}
+
+func location(protocol, requestProtocol, host, key string) string {
+	if protocol == "" {
+		protocol = requestProtocol
+	}
+	return protocol + "://" + host + "/" + strings.ReplaceAll(url.PathEscape(key), "%2F", "/")
+}
@@ -0,0 +1,92 @@
+package s3website
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func TestValidate(t *testing.T) {
+	valid := func() *s3_pb.WebsiteConfiguration {
+		return &s3_pb.WebsiteConfiguration{
+			IndexDocumentSuffix: "index.html",
+			ErrorDocumentKey:    "error.html",
+			RoutingRules: []*s3_pb.WebsiteRoutingRule{
+				{KeyPrefixEquals: "docs/", Redirect: &s3_pb.WebsiteRedirect{ReplaceKeyPrefixWith: "documents/"}},
+			},
+		}
+	}
+	if err := Validate(valid()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := Validate(&s3_pb.WebsiteConfiguration{RedirectAllRequestsTo: &s3_pb.WebsiteRedirect{HostName: "example.com"}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	invalid := map[string]func(c *s3_pb.WebsiteConfiguration){
+		"no index document":   func(c *s3_pb.WebsiteConfiguration) { c.IndexDocumentSuffix = "" },
+		"slash in the suffix": func(c *s3_pb.WebsiteConfiguration) { c.IndexDocumentSuffix = "a/index.html" },
+		"redirect all and index": func(c *s3_pb.WebsiteConfiguration) {
+			c.RedirectAllRequestsTo = &s3_pb.WebsiteRedirect{HostName: "example.com"}
+		},
+		"no redirect":       func(c *s3_pb.WebsiteConfiguration) { c.RoutingRules[0].Redirect = nil },
+		"both replacements": func(c *s3_pb.WebsiteConfiguration) { c.RoutingRules[0].Redirect.ReplaceKeyWith = "a.html" },
+		"redirect code":     func(c *s3_pb.WebsiteConfiguration) { c.RoutingRules[0].Redirect.HttpRedirectCode = "200" },
+		"error code":        func(c *s3_pb.WebsiteConfiguration) { c.RoutingRules[0].HttpErrorCodeReturnedEquals = "302" },
+		"protocol":          func(c *s3_pb.WebsiteConfiguration) { c.RoutingRules[0].Redirect.Protocol = "ftp" },
+	}
+	for name, modify := range invalid {
+		config := valid()
+		modify(config)
+		if err := Validate(config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRouting(t *testing.T) {
+	config := &s3_pb.WebsiteConfiguration{
+		IndexDocumentSuffix: "index.html",
+		RoutingRules: []*s3_pb.WebsiteRoutingRule{
+			{KeyPrefixEquals: "docs/", Redirect: &s3_pb.WebsiteRedirect{ReplaceKeyPrefixWith: "documents/"}},
+			{HttpErrorCodeReturnedEquals: "404", Redirect: &s3_pb.WebsiteRedirect{HostName: "example.com", Protocol: "https", ReplaceKeyWith: "not found.html", HttpRedirectCode: "302"}},
+		},
+	}
+	if got := IndexKey(config, ""); got != "index.html" {
+		t.Errorf("unexpected index key %s", got)
+	}
+	if got := IndexKey(config, "blog/"); got != "blog/index.html" {
+		t.Errorf("unexpected index key %s", got)
+	}
+	if got := IndexKey(config, "blog/a.html"); got != "blog/a.html" {
+		t.Errorf("unexpected index key %s", got)
+	}
+
+	rule := MatchRoutingRule(config, "docs/a.html", 0)
+	if rule != config.RoutingRules[0] {
+		t.Fatalf("expected the prefix rule, got %v", rule)
+	}
+	redirect := RuleRedirect(rule, "http", "site.example.org", "docs/a.html")
+	if redirect.Location != "http://site.example.org/documents/a.html" || redirect.Code != http.StatusMovedPermanently {
+		t.Errorf("unexpected redirect %+v", redirect)
+	}
+	if rule := MatchRoutingRule(config, "blog/a.html", 0); rule != nil {
+		t.Errorf("no rule should match before the object is read, got %v", rule)
+	}
+	if rule := MatchRoutingRule(config, "blog/a.html", http.StatusForbidden); rule != nil {
+		t.Errorf("no rule should match a 403, got %v", rule)
+	}
+	rule = MatchRoutingRule(config, "blog/a.html", http.StatusNotFound)
+	if rule != config.RoutingRules[1] {
+		t.Fatalf("expected the error code rule, got %v", rule)
+	}
+	redirect = RuleRedirect(rule, "http", "site.example.org", "blog/a.html")
+	if redirect.Location != "https://example.com/not%20found.html" || redirect.Code != http.StatusFound {
+		t.Errorf("unexpected redirect %+v", redirect)
+	}
+
+	redirectAll := &s3_pb.WebsiteConfiguration{RedirectAllRequestsTo: &s3_pb.WebsiteRedirect{HostName: "www.example.com"}}
+	if redirect := RedirectAll(redirectAll, "https", "a/b.html"); redirect.Location != "https://www.example.com/a/b.html" {
+		t.Errorf("unexpected redirect %+v", redirect)
+	}
+}
@@ -11,7 +11,7 @@ import (
 	stats_collect "github.com/seaweedfs/seaweedfs/weed/stats"
 )
 
-func track(f http.HandlerFunc, action string) http.HandlerFunc {
+func (s3a *S3ApiServer) track(f http.HandlerFunc, action string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		inFlightGauge := stats_collect.S3InFlightRequestsGauge.WithLabelValues(action)
 		inFlightGauge.Inc()
@@ -21,7 +21,22 @@ func track(f http.HandlerFunc, action string) http.HandlerFunc {
 		w.Header().Set("Server", "SeaweedFS "+version.VERSION)
 		recorder := stats_collect.NewStatusResponseWriter(w)
 		start := time.Now()
-		f(recorder, r)
+		if s3a.accessLogger != nil || s3a.usageRecorder != nil {
+			accessLogRecorder := &accessLogWriter{StatusRecorder: recorder}
+			f(accessLogRecorder, r)
+			if s3a.accessLogger != nil {
+				s3a.logAccess(r, accessLogRecorder, start)
+			}
+			if s3a.usageRecorder != nil {
+				usageBucket := bucket
+				if recorder.Status == http.StatusForbidden {
+					usageBucket = ""
+				}
+				s3a.usageRecorder.Record(start, usageBucket, r.Header.Get(s3_constants.AmzIdentityId), action, r.ContentLength, accessLogRecorder.bytesSent)
+			}
+		} else {
+			f(recorder, r)
+		}
 		if recorder.Status == http.StatusForbidden {
 			bucket = ""
 		}
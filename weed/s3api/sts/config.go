@@ -0,0 +1,125 @@
+package sts
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+)
+
+const (
+	defaultSessionDurationSeconds = 3600
+	minSessionDurationSeconds     = 900
+	maxSessionDurationSeconds     = 43200
+)
+
+// Config is the "sts" section of the S3 identity configuration file, e.g.
+//
+//	"sts": {
+//	  "signingKey": "<random secret shared by all s3 gateways>",
+//	  "providers": [
+//	    {"issuer": "https://kubernetes.default.svc", "audiences": ["seaweedfs"], "jwksFile": "/etc/seaweedfs/jwks.json"}
+//	  ],
+//	  "roles": [
+//	    {
+//	      "name": "ci",
+//	      "webIdentity": {"issuer": "https://kubernetes.default.svc", "subjects": ["system:serviceaccount:ci:*"]},
+//	      "policy": {"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": ["s3:GetObject", "s3:PutObject"], "Resource": ["arn:aws:s3:::artifacts/*"]}]}
+//	    }
+//	  ]
+//	}
+type Config struct {
+	// SigningKey signs the session tokens and derives their secret keys.
+	// All gateways behind the same endpoint need the same key.
+	SigningKey string `json:"signingKey"`
+	// AccountId is used in the role and assumed role ARNs.
+	AccountId string          `json:"accountId"`
+	Providers []*OIDCProvider `json:"providers"`
+	Roles     []*Role         `json:"roles"`
+}
+
+// OIDCProvider is an identity provider whose tokens are accepted by
+// AssumeRoleWithWebIdentity. The signing keys come from a JWKS document,
+// either a local file or a URL.
+type OIDCProvider struct {
+	Issuer string `json:"issuer"`
+	// Audiences restricts the "aud" claim, any audience is accepted if empty.
+	Audiences []string `json:"audiences"`
+	JWKSFile  string   `json:"jwksFile"`
+	JWKSURL   string   `json:"jwksUrl"`
+}
+
+// Role can be assumed by the listed identities with AssumeRole, or by the
+// holders of matching OIDC tokens with AssumeRoleWithWebIdentity.
+// The issued credentials are allowed what the role policy allows.
+type Role struct {
+	Name string `json:"name"`
+	// TrustedIdentities are the identity names allowed to call AssumeRole,
+	// "*" trusts all identities.
+	TrustedIdentities []string          `json:"trustedIdentities"`
+	WebIdentity       *WebIdentityTrust `json:"webIdentity"`
+	// MaxSessionDuration is in seconds, 3600 if not set.
+	MaxSessionDuration int64                        `json:"maxSessionDuration"`
+	Policy             policy_engine.PolicyDocument `json:"policy"`
+}
+
+// WebIdentityTrust selects the OIDC tokens that can assume a role.
+type WebIdentityTrust struct {
+	Issuer string `json:"issuer"`
+	// Audiences restricts the "aud" claim further than the provider does.
+	Audiences []string `json:"audiences"`
+	// Subjects are patterns of the "sub" claim, "*" and "?" are wildcards.
+	Subjects []string `json:"subjects"`
+}
+
+func (c *Config) validate() error {
+	issuers := make(map[string]bool)
+	for _, p := range c.Providers {
+		if p.Issuer == "" {
+			return fmt.Errorf("oidc provider without issuer")
+		}
+		if issuers[p.Issuer] {
+			return fmt.Errorf("duplicate oidc provider %s", p.Issuer)
+		}
+		if (p.JWKSFile == "") == (p.JWKSURL == "") {
+			return fmt.Errorf("oidc provider %s needs exactly one of jwksFile and jwksUrl", p.Issuer)
+		}
+		issuers[p.Issuer] = true
+	}
+	names := make(map[string]bool)
+	for _, role := range c.Roles {
+		if role.Name == "" || strings.ContainsAny(role.Name, "/:") {
+			return fmt.Errorf("invalid role name %q", role.Name)
+		}
+		if names[role.Name] {
+			return fmt.Errorf("duplicate role %s", role.Name)
+		}
+		names[role.Name] = true
+		if role.WebIdentity != nil && !issuers[role.WebIdentity.Issuer] {
+			return fmt.Errorf("role %s trusts unknown oidc provider %s", role.Name, role.WebIdentity.Issuer)
+		}
+		if role.MaxSessionDuration != 0 && (role.MaxSessionDuration < minSessionDurationSeconds || role.MaxSessionDuration > maxSessionDurationSeconds) {
+			return fmt.Errorf("role %s max session duration must be between %d and %d seconds", role.Name, minSessionDurationSeconds, maxSessionDurationSeconds)
+		}
+		if err := policy_engine.ValidatePolicy(&role.Policy); err != nil {
+			return fmt.Errorf("role %s policy: %w", role.Name, err)
+		}
+	}
+	return nil
+}
+
+func (role *Role) maxDuration() int64 {
+	if role.MaxSessionDuration == 0 {
+		return defaultSessionDurationSeconds
+	}
+	return role.MaxSessionDuration
+}
+
+func (role *Role) trustsIdentity(name string) bool {
+	for _, trusted := range role.TrustedIdentities {
+		if trusted == "*" || trusted == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package sts
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// keys of a provider are reloaded at most this often, when a token is
+// signed by an unknown key id
+const jwksRefreshInterval = time.Minute
+
+var webIdentitySigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// oidcVerifier validates the tokens of one OIDC provider
+type oidcVerifier struct {
+	provider   *OIDCProvider
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	keys     map[string]crypto.PublicKey
+	loadedAt time.Time
+}
+
+// WebIdentity holds the verified claims of an OIDC token
+type WebIdentity struct {
+	Issuer   string
+	Subject  string
+	Audience []string
+}
+
+func newOIDCVerifier(provider *OIDCProvider) *oidcVerifier {
+	return &oidcVerifier{
+		provider:   provider,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (v *oidcVerifier) verify(token string) (*WebIdentity, error) {
+	claims := jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, v.keyFunc,
+		jwt.WithValidMethods(webIdentitySigningMethods),
+		jwt.WithIssuer(v.provider.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	if len(v.provider.Audiences) > 0 && !containsAny(claims.Audience, v.provider.Audiences) {
+		return nil, fmt.Errorf("token audience %v is not accepted", claims.Audience)
+	}
+	return &WebIdentity{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		Audience: claims.Audience,
+	}, nil
+}
+
+func (v *oidcVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, found := v.lookupKey(kid); found {
+		return key, nil
+	}
+	if time.Since(v.loadedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if err := v.loadKeys(); err != nil {
+		glog.Warningf("load jwks of %s: %v", v.provider.Issuer, err)
+		return nil, fmt.Errorf("load signing keys: %w", err)
+	}
+	if key, found := v.lookupKey(kid); found {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds the key by id, a token without key id can only use
+// a key set with a single key
+func (v *oidcVerifier) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(v.keys) == 1 {
+			for _, key := range v.keys {
+				return key, true
+			}
+		}
+		return nil, false
+	}
+	key, found := v.keys[kid]
+	return key, found
+}
+
+func (v *oidcVerifier) loadKeys() error {
+	v.loadedAt = time.Now()
+	data, err := v.readKeySet()
+	if err != nil {
+		return err
+	}
+	keys, err := parseJSONWebKeySet(data)
+	if err != nil {
+		return err
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *oidcVerifier) readKeySet() ([]byte, error) {
+	if v.provider.JWKSFile != "" {
+		return os.ReadFile(v.provider.JWKSFile)
+	}
+	resp, err := v.httpClient.Get(v.provider.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", v.provider.JWKSURL, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func parseJSONWebKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	var keySet jsonWebKeySet
+	if err := json.Unmarshal(data, &keySet); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range keySet.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks has no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func containsAny(values, accepted []string) bool {
+	for _, value := range values {
+		if slices.Contains(accepted, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package sts
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type CommonResponse struct {
+	ResponseMetadata struct {
+		RequestId string `xml:"RequestId"`
+	} `xml:"ResponseMetadata"`
+}
+
+type CredentialsXML struct {
+	AccessKeyId     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey"`
+	SessionToken    string `xml:"SessionToken"`
+	Expiration      string `xml:"Expiration"`
+}
+
+type AssumedRoleUser struct {
+	AssumedRoleId string `xml:"AssumedRoleId"`
+	Arn           string `xml:"Arn"`
+}
+
+type AssumeRoleResponse struct {
+	CommonResponse
+	XMLName xml.Name `xml:"https://sts.amazonaws.com/doc/2011-06-15/ AssumeRoleResponse"`
+	Result  struct {
+		Credentials     CredentialsXML  `xml:"Credentials"`
+		AssumedRoleUser AssumedRoleUser `xml:"AssumedRoleUser"`
+	} `xml:"AssumeRoleResult"`
+}
+
+type AssumeRoleWithWebIdentityResponse struct {
+	CommonResponse
+	XMLName xml.Name `xml:"https://sts.amazonaws.com/doc/2011-06-15/ AssumeRoleWithWebIdentityResponse"`
+	Result  struct {
+		Credentials                 CredentialsXML  `xml:"Credentials"`
+		AssumedRoleUser             AssumedRoleUser `xml:"AssumedRoleUser"`
+		SubjectFromWebIdentityToken string          `xml:"SubjectFromWebIdentityToken"`
+		Provider                    string          `xml:"Provider"`
+		Audience                    string          `xml:"Audience,omitempty"`
+	} `xml:"AssumeRoleWithWebIdentityResult"`
+}
+
+type ErrorResponse struct {
+	XMLName xml.Name `xml:"https://sts.amazonaws.com/doc/2011-06-15/ ErrorResponse"`
+	Error   struct {
+		Type    string `xml:"Type"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+	RequestId string `xml:"RequestId"`
+}
+
+// NewErrorResponse returns the response body of an STS error
+func NewErrorResponse(err *Error) ErrorResponse {
+	resp := ErrorResponse{RequestId: newRequestId()}
+	resp.Error.Type = "Sender"
+	if err.StatusCode >= 500 {
+		resp.Error.Type = "Receiver"
+	}
+	resp.Error.Code = err.Code
+	resp.Error.Message = err.Message
+	return resp
+}
+
+func newCredentialsXML(c *Credentials) CredentialsXML {
+	return CredentialsXML{
+		AccessKeyId:     c.AccessKeyId,
+		SecretAccessKey: c.SecretAccessKey,
+		SessionToken:    c.SessionToken,
+		Expiration:      c.Expiration.UTC().Format(time.RFC3339),
+	}
+}
+
+func newRequestId() string {
+	return fmt.Sprintf("%d", time.Now().UnixNano())
+}
@@ -0,0 +1,141 @@
+package sts
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+)
+
+const (
+	sessionTokenIssuer = "seaweedfs-sts"
+	accessKeyIdPrefix  = "ASIA"
+	accessKeyIdChars   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	secretKeyLength    = 40
+)
+
+// ErrExpiredToken is returned by ParseSessionToken for tokens past their expiration
+var ErrExpiredToken = errors.New("session token has expired")
+
+// sessionClaims are carried by the session token. The secret key is not part
+// of the token, it is derived from the access key id with the signing key.
+type sessionClaims struct {
+	AccessKeyId   string                        `json:"akid"`
+	RoleArn       string                        `json:"role"`
+	SessionName   string                        `json:"session"`
+	Policy        *policy_engine.PolicyDocument `json:"policy"`
+	SessionPolicy *policy_engine.PolicyDocument `json:"sessionPolicy,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Credentials are temporary security credentials
+type Credentials struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Expiration      time.Time
+}
+
+// Session is the verified content of a session token
+type Session struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	RoleArn         string
+	SessionName     string
+	// Subject is the identity name for AssumeRole, or the token
+	// subject for AssumeRoleWithWebIdentity
+	Subject    string
+	Expiration time.Time
+	// Policy is the role policy, SessionPolicy the optional policy passed
+	// when assuming the role; requests need to be allowed by both.
+	Policy        *policy_engine.PolicyDocument
+	SessionPolicy *policy_engine.PolicyDocument
+}
+
+// AssumedRoleUser returns the name of the session principal,
+// e.g. assumed-role/ci/build-42
+func (s *Session) AssumedRoleUser() string {
+	return "assumed-role/" + roleNameFromArn(s.RoleArn) + "/" + s.SessionName
+}
+
+func (s *Service) newCredentials(role *Role, sessionName, subject string, sessionPolicy *policy_engine.PolicyDocument, duration time.Duration) (*Credentials, error) {
+	accessKeyId, err := newAccessKeyId()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	expiration := now.Add(duration).Truncate(time.Second)
+	claims := sessionClaims{
+		AccessKeyId:   accessKeyId,
+		RoleArn:       s.roleArn(role.Name),
+		SessionName:   sessionName,
+		Policy:        &role.Policy,
+		SessionPolicy: sessionPolicy,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    sessionTokenIssuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiration),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("sign session token: %w", err)
+	}
+	return &Credentials{
+		AccessKeyId:     accessKeyId,
+		SecretAccessKey: s.secretKey(accessKeyId),
+		SessionToken:    token,
+		Expiration:      expiration,
+	}, nil
+}
+
+// ParseSessionToken verifies a session token issued by this service
+func (s *Service) ParseSessionToken(token string) (*Session, error) {
+	claims := sessionClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.signingKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(sessionTokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, err
+	}
+	if claims.AccessKeyId == "" || claims.Policy == nil {
+		return nil, fmt.Errorf("incomplete session token")
+	}
+	return &Session{
+		AccessKeyId:     claims.AccessKeyId,
+		SecretAccessKey: s.secretKey(claims.AccessKeyId),
+		RoleArn:         claims.RoleArn,
+		SessionName:     claims.SessionName,
+		Subject:         claims.Subject,
+		Expiration:      claims.ExpiresAt.Time,
+		Policy:          claims.Policy,
+		SessionPolicy:   claims.SessionPolicy,
+	}, nil
+}
+
+func (s *Service) secretKey(accessKeyId string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("secret/" + accessKeyId))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))[:secretKeyLength]
+}
+
+func newAccessKeyId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = accessKeyIdChars[int(b[i])%len(accessKeyIdChars)]
+	}
+	return accessKeyIdPrefix + string(b), nil
+}
@@ -0,0 +1,289 @@
+package sts
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+)
+
+const defaultAccountId = "000000000000"
+
+var roleSessionNameRegexp = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+// Error is an STS error response
+type Error struct {
+	Code       string
+	Message    string
+	StatusCode int
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+func errAccessDenied(format string, args ...interface{}) *Error {
+	return &Error{Code: "AccessDenied", Message: fmt.Sprintf(format, args...), StatusCode: http.StatusForbidden}
+}
+
+func errValidation(format string, args ...interface{}) *Error {
+	return &Error{Code: "ValidationError", Message: fmt.Sprintf(format, args...), StatusCode: http.StatusBadRequest}
+}
+
+func errInvalidIdentityToken(format string, args ...interface{}) *Error {
+	return &Error{Code: "InvalidIdentityToken", Message: fmt.Sprintf(format, args...), StatusCode: http.StatusBadRequest}
+}
+
+// ErrNotImplemented is returned for STS actions other than the supported ones
+func ErrNotImplemented(action string) *Error {
+	return &Error{Code: "InvalidAction", Message: fmt.Sprintf("action %s is not supported", action), StatusCode: http.StatusBadRequest}
+}
+
+// Service issues temporary credentials for the configured roles and
+// verifies their session tokens. It keeps no state: everything needed to
+// authenticate a request is in the signed session token.
+type Service struct {
+	signingKey []byte
+	accountId  string
+	roles      map[string]*Role
+	verifiers  map[string]*oidcVerifier
+	// iamRoles are the roles created with the IAM API
+	iamRoles atomic.Pointer[map[string]*Role]
+}
+
+// NewService creates the STS service of a configuration
+func NewService(config *Config) (*Service, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	s := &Service{
+		signingKey: []byte(config.SigningKey),
+		accountId:  config.AccountId,
+		roles:      make(map[string]*Role),
+		verifiers:  make(map[string]*oidcVerifier),
+	}
+	if s.accountId == "" {
+		s.accountId = defaultAccountId
+	}
+	if len(s.signingKey) == 0 {
+		glog.Warningf("sts signingKey is not set, session tokens are only valid on this s3 gateway until it restarts")
+		s.signingKey = make([]byte, 32)
+		if _, err := rand.Read(s.signingKey); err != nil {
+			return nil, err
+		}
+	}
+	for _, role := range config.Roles {
+		s.roles[role.Name] = role
+	}
+	for _, provider := range config.Providers {
+		s.verifiers[provider.Issuer] = newOIDCVerifier(provider)
+	}
+	return s, nil
+}
+
+// SetIAMRoles replaces the roles created with the IAM API.
+// A role of the sts configuration wins over an IAM role of the same name.
+func (s *Service) SetIAMRoles(roles []*Role) {
+	iamRoles := make(map[string]*Role, len(roles))
+	for _, role := range roles {
+		iamRoles[role.Name] = role
+	}
+	s.iamRoles.Store(&iamRoles)
+}
+
+func (s *Service) role(name string) (*Role, bool) {
+	if role, found := s.roles[name]; found {
+		return role, true
+	}
+	if iamRoles := s.iamRoles.Load(); iamRoles != nil {
+		role, found := (*iamRoles)[name]
+		return role, found
+	}
+	return nil, false
+}
+
+// AssumeRole issues credentials of a role to an authenticated identity
+// trusted by the role.
+func (s *Service) AssumeRole(identityName string, values url.Values) (*AssumeRoleResponse, *Error) {
+	role, sessionName, sessionPolicy, duration, stsErr := s.parseAssumeRoleParams(values)
+	if stsErr != nil {
+		return nil, stsErr
+	}
+	if !role.trustsIdentity(identityName) {
+		return nil, errAccessDenied("%s is not authorized to assume role %s", identityName, role.Name)
+	}
+	credentials, err := s.newCredentials(role, sessionName, identityName, sessionPolicy, duration)
+	if err != nil {
+		glog.Errorf("assume role %s by %s: %v", role.Name, identityName, err)
+		return nil, &Error{Code: "InternalFailure", Message: "failed to issue credentials", StatusCode: http.StatusInternalServerError}
+	}
+	resp := &AssumeRoleResponse{}
+	resp.Result.Credentials = newCredentialsXML(credentials)
+	resp.Result.AssumedRoleUser = s.assumedRoleUser(role, sessionName)
+	resp.ResponseMetadata.RequestId = newRequestId()
+	return resp, nil
+}
+
+// AssumeRoleWithWebIdentity issues credentials of a role to the holder of
+// an OIDC token that the role trusts. The request itself is not signed.
+func (s *Service) AssumeRoleWithWebIdentity(values url.Values) (*AssumeRoleWithWebIdentityResponse, *Error) {
+	role, sessionName, sessionPolicy, duration, stsErr := s.parseAssumeRoleParams(values)
+	if stsErr != nil {
+		return nil, stsErr
+	}
+	token := values.Get("WebIdentityToken")
+	if token == "" {
+		return nil, errValidation("WebIdentityToken is required")
+	}
+	identity, stsErr := s.verifyWebIdentity(token)
+	if stsErr != nil {
+		return nil, stsErr
+	}
+	if !role.trustsWebIdentity(identity) {
+		return nil, errAccessDenied("not authorized to assume role %s with web identity %s", role.Name, identity.Subject)
+	}
+	credentials, err := s.newCredentials(role, sessionName, identity.Subject, sessionPolicy, duration)
+	if err != nil {
+		glog.Errorf("assume role %s with web identity %s: %v", role.Name, identity.Subject, err)
+		return nil, &Error{Code: "InternalFailure", Message: "failed to issue credentials", StatusCode: http.StatusInternalServerError}
+	}
+	resp := &AssumeRoleWithWebIdentityResponse{}
+	resp.Result.Credentials = newCredentialsXML(credentials)
+	resp.Result.AssumedRoleUser = s.assumedRoleUser(role, sessionName)
+	resp.Result.SubjectFromWebIdentityToken = identity.Subject
+	resp.Result.Provider = identity.Issuer
+	if len(identity.Audience) > 0 {
+		resp.Result.Audience = identity.Audience[0]
+	}
+	resp.ResponseMetadata.RequestId = newRequestId()
+	return resp, nil
+}
+
+func (s *Service) parseAssumeRoleParams(values url.Values) (role *Role, sessionName string, sessionPolicy *policy_engine.PolicyDocument, duration time.Duration, stsErr *Error) {
+	roleArn := values.Get("RoleArn")
+	if roleArn == "" {
+		return nil, "", nil, 0, errValidation("RoleArn is required")
+	}
+	role, found := s.role(roleNameFromArn(roleArn))
+	if !found || !strings.HasPrefix(roleArn, "arn:aws:iam::") {
+		return nil, "", nil, 0, errAccessDenied("role %s does not exist", roleArn)
+	}
+
+	sessionName = values.Get("RoleSessionName")
+	if !roleSessionNameRegexp.MatchString(sessionName) {
+		return nil, "", nil, 0, errValidation("RoleSessionName must be 2 to 64 characters of letters, digits and +=,.@-_")
+	}
+
+	seconds := min(int64(defaultSessionDurationSeconds), role.maxDuration())
+	if value := values.Get("DurationSeconds"); value != "" {
+		var err error
+		if seconds, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, "", nil, 0, errValidation("invalid DurationSeconds %q", value)
+		}
+	}
+	if seconds < minSessionDurationSeconds || seconds > role.maxDuration() {
+		return nil, "", nil, 0, errValidation("DurationSeconds must be between %d and %d", minSessionDurationSeconds, role.maxDuration())
+	}
+
+	if value := values.Get("Policy"); value != "" {
+		policy, err := policy_engine.ParsePolicy(value)
+		if err != nil {
+			return nil, "", nil, 0, &Error{Code: "MalformedPolicyDocument", Message: err.Error(), StatusCode: http.StatusBadRequest}
+		}
+		sessionPolicy = policy
+	}
+	return role, sessionName, sessionPolicy, time.Duration(seconds) * time.Second, nil
+}
+
+func (s *Service) verifyWebIdentity(token string) (*WebIdentity, *Error) {
+	issuer, err := unverifiedIssuer(token)
+	if err != nil {
+		return nil, errInvalidIdentityToken("%v", err)
+	}
+	verifier, found := s.verifiers[issuer]
+	if !found {
+		return nil, errInvalidIdentityToken("issuer %s is not a configured identity provider", issuer)
+	}
+	identity, err := verifier.verify(token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, &Error{Code: "ExpiredTokenException", Message: "the web identity token has expired", StatusCode: http.StatusBadRequest}
+		}
+		return nil, errInvalidIdentityToken("%v", err)
+	}
+	return identity, nil
+}
+
+func (role *Role) trustsWebIdentity(identity *WebIdentity) bool {
+	trust := role.WebIdentity
+	if trust == nil || trust.Issuer != identity.Issuer {
+		return false
+	}
+	if len(trust.Audiences) > 0 && !containsAny(identity.Audience, trust.Audiences) {
+		return false
+	}
+	for _, subject := range trust.Subjects {
+		if policy_engine.MatchesWildcard(subject, identity.Subject) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) roleArn(name string) string {
+	return "arn:aws:iam::" + s.accountId + ":role/" + name
+}
+
+func (s *Service) assumedRoleUser(role *Role, sessionName string) AssumedRoleUser {
+	sum := sha256.Sum256([]byte(s.roleArn(role.Name)))
+	return AssumedRoleUser{
+		AssumedRoleId: "AROA" + base32.StdEncoding.EncodeToString(sum[:])[:17] + ":" + sessionName,
+		Arn:           "arn:aws:sts::" + s.accountId + ":assumed-role/" + role.Name + "/" + sessionName,
+	}
+}
+
+// roleNameFromArn returns the last path element of arn:aws:iam::<account>:role/<path>/<name>
+func roleNameFromArn(arn string) string {
+	_, resource, found := strings.Cut(arn, ":role/")
+	if !found {
+		return ""
+	}
+	return resource[strings.LastIndex(resource, "/")+1:]
+}
+
+// unverifiedIssuer reads the "iss" claim to select the provider that
+// verifies the token
+func unverifiedIssuer(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return "", fmt.Errorf("decode token payload: %w", err)
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("parse token payload: %w", err)
+	}
+	if claims.Issuer == "" {
+		return "", errors.New("token has no issuer")
+	}
+	return claims.Issuer, nil
+}
@@ -0,0 +1,188 @@
+package sts
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/policy_engine"
+)
+
+const testIssuer = "https://kubernetes.default.svc"
+
+func newTestService(t *testing.T) (*Service, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "k1",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(jwksFile, jwks, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy := policy_engine.PolicyDocument{
+		Version: policy_engine.PolicyVersion2012_10_17,
+		Statement: []policy_engine.PolicyStatement{{
+			Effect:   policy_engine.PolicyEffectAllow,
+			Action:   policy_engine.NewStringOrStringSlice("s3:GetObject"),
+			Resource: policy_engine.NewStringOrStringSlice("arn:aws:s3:::artifacts/*"),
+		}},
+	}
+	service, err := NewService(&Config{
+		SigningKey: "secret",
+		Providers:  []*OIDCProvider{{Issuer: testIssuer, Audiences: []string{"seaweedfs"}, JWKSFile: jwksFile}},
+		Roles: []*Role{
+			{Name: "ci", WebIdentity: &WebIdentityTrust{Issuer: testIssuer, Subjects: []string{"system:serviceaccount:ci:*"}}, Policy: policy},
+			{Name: "backup", TrustedIdentities: []string{"admin"}, MaxSessionDuration: 7200, Policy: policy},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service, key
+}
+
+func signWebIdentityToken(t *testing.T, key *rsa.PrivateKey, claims jwt.RegisteredClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAssumeRoleWithWebIdentity(t *testing.T) {
+	service, key := newTestService(t)
+	validClaims := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Subject:   "system:serviceaccount:ci:builder",
+			Audience:  jwt.ClaimStrings{"seaweedfs"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}
+	}
+	request := func(token string) url.Values {
+		return url.Values{
+			"RoleArn":          {"arn:aws:iam::000000000000:role/ci"},
+			"RoleSessionName":  {"build-42"},
+			"WebIdentityToken": {token},
+		}
+	}
+
+	resp, stsErr := service.AssumeRoleWithWebIdentity(request(signWebIdentityToken(t, key, validClaims())))
+	if stsErr != nil {
+		t.Fatal(stsErr)
+	}
+	if resp.Result.SubjectFromWebIdentityToken != "system:serviceaccount:ci:builder" || resp.Result.AssumedRoleUser.Arn != "arn:aws:sts::000000000000:assumed-role/ci/build-42" {
+		t.Errorf("unexpected result %+v", resp.Result)
+	}
+	credentials := resp.Result.Credentials
+	if !strings.HasPrefix(credentials.AccessKeyId, accessKeyIdPrefix) || len(credentials.SecretAccessKey) != secretKeyLength {
+		t.Errorf("unexpected credentials %+v", credentials)
+	}
+	session, err := service.ParseSessionToken(credentials.SessionToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.AccessKeyId != credentials.AccessKeyId || session.SecretAccessKey != credentials.SecretAccessKey ||
+		session.AssumedRoleUser() != "assumed-role/ci/build-42" || session.Policy == nil {
+		t.Errorf("unexpected session %+v", session)
+	}
+
+	invalid := map[string]func(*jwt.RegisteredClaims){
+		"other namespace": func(c *jwt.RegisteredClaims) { c.Subject = "system:serviceaccount:default:builder" },
+		"other audience":  func(c *jwt.RegisteredClaims) { c.Audience = jwt.ClaimStrings{"other"} },
+		"unknown issuer":  func(c *jwt.RegisteredClaims) { c.Issuer = "https://accounts.example.com" },
+		"expired":         func(c *jwt.RegisteredClaims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) },
+		"no expiration":   func(c *jwt.RegisteredClaims) { c.ExpiresAt = nil },
+	}
+	for name, modify := range invalid {
+		claims := validClaims()
+		modify(&claims)
+		if _, stsErr := service.AssumeRoleWithWebIdentity(request(signWebIdentityToken(t, key, claims))); stsErr == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, stsErr := service.AssumeRoleWithWebIdentity(request(signWebIdentityToken(t, otherKey, validClaims()))); stsErr == nil || stsErr.Code != "InvalidIdentityToken" {
+		t.Errorf("a token signed by another key should be rejected, got %v", stsErr)
+	}
+}
+
+func TestAssumeRole(t *testing.T) {
+	service, _ := newTestService(t)
+	values := url.Values{
+		"RoleArn":         {"arn:aws:iam::000000000000:role/backup"},
+		"RoleSessionName": {"nightly"},
+		"DurationSeconds": {"7200"},
+	}
+	resp, stsErr := service.AssumeRole("admin", values)
+	if stsErr != nil {
+		t.Fatal(stsErr)
+	}
+	expiration, err := time.Parse(time.RFC3339, resp.Result.Credentials.Expiration)
+	if err != nil || time.Until(expiration) < 119*time.Minute {
+		t.Errorf("unexpected expiration %s", resp.Result.Credentials.Expiration)
+	}
+
+	if _, stsErr := service.AssumeRole("guest", values); stsErr == nil || stsErr.Code != "AccessDenied" {
+		t.Errorf("an untrusted identity should be denied, got %v", stsErr)
+	}
+	values.Set("DurationSeconds", "7201")
+	if _, stsErr := service.AssumeRole("admin", values); stsErr == nil || stsErr.Code != "ValidationError" {
+		t.Errorf("a duration over the maximum should be rejected, got %v", stsErr)
+	}
+	values.Set("DurationSeconds", "900")
+	values.Set("RoleArn", "arn:aws:iam::000000000000:role/ci")
+	if _, stsErr := service.AssumeRole("admin", values); stsErr == nil || stsErr.Code != "AccessDenied" {
+		t.Errorf("a role without trusted identities should be denied, got %v", stsErr)
+	}
+}
+
+func TestParseSessionToken(t *testing.T) {
+	service, _ := newTestService(t)
+	role := service.roles["backup"]
+
+	expired, err := service.newCredentials(role, "old", "admin", nil, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ParseSessionToken(expired.SessionToken); err != ErrExpiredToken {
+		t.Errorf("expected an expired token error, got %v", err)
+	}
+
+	credentials, err := service.newCredentials(role, "new", "admin", nil, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(credentials.SessionToken, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	tampered := strings.Replace(string(payload), `"session":"new"`, `"session":"forged"`, 1)
+	parts[1] = base64.RawURLEncoding.EncodeToString([]byte(tampered))
+	if _, err := service.ParseSessionToken(strings.Join(parts, ".")); err == nil {
+		t.Errorf("a tampered token should be rejected")
+	}
+
+	other, _ := NewService(&Config{SigningKey: "other"})
+	if _, err := other.ParseSessionToken(credentials.SessionToken); err == nil {
+		t.Errorf("a token signed by another key should be rejected")
+	}
+}
@@ -21,12 +21,69 @@ type SeaweedFileIdClaims struct {
 }
 
 // SeaweedFilerClaims is created e.g. by S3 proxy server and consumed by Filer server.
-// Right now, it only contains the standard claims; but this might be extended later
-// for more fine-grained permissions.
+// Without a scope, it grants access to the whole Filer API.
 type SeaweedFilerClaims struct {
+	Scope *FilerAccessScope `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// FilerAccessScope restricts a filer JWT to some requests, e.g. for signed URLs
+// handed to web frontends uploading or downloading single files. Empty fields
+// do not restrict the requests.
+type FilerAccessScope struct {
+	// PathPrefix is a file path, or a folder path ending with "/"
+	PathPrefix string   `json:"prefix,omitempty"`
+	Methods    []string `json:"methods,omitempty"`
+	// MaxSize limits the size of uploads in bytes
+	MaxSize int64 `json:"maxSize,omitempty"`
+	// ContentTypes of uploads, as "image/png" or "image/*"
+	ContentTypes []string `json:"contentTypes,omitempty"`
+}
+
+// AllowsPath checks a cleaned request path against the path prefix
+func (s *FilerAccessScope) AllowsPath(p string) bool {
+	if s.PathPrefix == "" {
+		return true
+	}
+	if strings.HasSuffix(s.PathPrefix, "/") {
+		return strings.HasPrefix(p, s.PathPrefix) || p+"/" == s.PathPrefix
+	}
+	return p == s.PathPrefix
+}
+
+// AllowsMethod checks the request method, where GET also allows HEAD
+func (s *FilerAccessScope) AllowsMethod(method string) bool {
+	if len(s.Methods) == 0 {
+		return true
+	}
+	for _, m := range s.Methods {
+		m = strings.ToUpper(m)
+		if m == method || m == http.MethodGet && method == http.MethodHead {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsContentType checks the media type of an upload, without its parameters
+func (s *FilerAccessScope) AllowsContentType(contentType string) bool {
+	if len(s.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		return false
+	}
+	for _, t := range s.ContentTypes {
+		t = strings.ToLower(t)
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
 func GenJwtForVolumeServer(signingKey SigningKey, expiresAfterSec int, fileId string) EncodedJwt {
 	if len(signingKey) == 0 {
 		return ""
@@ -51,12 +108,18 @@ func GenJwtForVolumeServer(signingKey SigningKey, expiresAfterSec int, fileId st
 // GenJwtForFilerServer creates a JSON-web-token for using the authenticated Filer API. Used f.e. inside
 // the S3 API
 func GenJwtForFilerServer(signingKey SigningKey, expiresAfterSec int) EncodedJwt {
+	return GenScopedJwtForFilerServer(signingKey, expiresAfterSec, nil)
+}
+
+// GenScopedJwtForFilerServer creates a JSON-web-token only granting the requests in the scope,
+// e.g. for a signed URL. The Filer verifies it with its signing key, without asking other servers.
+func GenScopedJwtForFilerServer(signingKey SigningKey, expiresAfterSec int, scope *FilerAccessScope) EncodedJwt {
 	if len(signingKey) == 0 {
 		return ""
 	}
 
 	claims := SeaweedFilerClaims{
-		jwt.RegisteredClaims{},
+		Scope: scope,
 	}
 	if expiresAfterSec > 0 {
 		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(expiresAfterSec)))
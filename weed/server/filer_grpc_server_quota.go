@@ -0,0 +1,26 @@
+package weed_server
+
+import (
+	"context"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// GetDirectoryQuotas lists the directory quotas, with the usage counted by this filer
+func (fs *FilerServer) GetDirectoryQuotas(ctx context.Context, req *filer_pb.GetDirectoryQuotasRequest) (*filer_pb.GetDirectoryQuotasResponse, error) {
+
+	resp := &filer_pb.GetDirectoryQuotasResponse{}
+	for _, q := range fs.filer.GetDirectoryQuotas(util.FullPath(req.Directory)) {
+		resp.Quotas = append(resp.Quotas, &filer_pb.DirectoryQuota{
+			Directory:  string(q.Directory),
+			MaxBytes:   q.MaxBytes,
+			MaxInodes:  q.MaxInodes,
+			UsedBytes:  q.UsedBytes,
+			UsedInodes: q.UsedInodes,
+			IsCounted:  q.IsCounted(),
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,25 @@
+package weed_server
+
+import (
+	"context"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// CreateSnapshot takes a read-only snapshot of a directory tree, browsable at {directory}/.snapshots/{name}
+func (fs *FilerServer) CreateSnapshot(ctx context.Context, req *filer_pb.CreateSnapshotRequest) (*filer_pb.CreateSnapshotResponse, error) {
+
+	glog.V(0).InfofCtx(ctx, "CreateSnapshot %s of %s", req.Name, req.Directory)
+
+	snapshotTime, entryCount, err := fs.filer.CreateSnapshot(ctx, util.FullPath(req.Directory), req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filer_pb.CreateSnapshotResponse{
+		EntryCount: entryCount,
+		TsNs:       snapshotTime.UnixNano(),
+	}, nil
+}
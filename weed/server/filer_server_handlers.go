@@ -74,10 +74,18 @@ func (fs *FilerServer) filerHandler(w http.ResponseWriter, r *http.Request) {
 	}(&requestMethod)
 
 	isReadHttpCall := r.Method == http.MethodGet || r.Method == http.MethodHead
-	if !fs.maybeCheckJwtAuthorization(r, !isReadHttpCall) {
+	scope, authorized := fs.maybeCheckJwtAuthorization(r, !isReadHttpCall)
+	if !authorized {
 		writeJsonError(w, r, http.StatusUnauthorized, errors.New("wrong jwt"))
 		return
 	}
+	if scope != nil {
+		if status, err := checkFilerAccessScope(w, r, scope); err != nil {
+			writeJsonError(w, r, status, err)
+			return
+		}
+		r = r.WithContext(withFilerAccessScope(r.Context(), scope))
+	}
 
 	w.Header().Set("Server", "SeaweedFS "+version.VERSION)
 
@@ -162,10 +170,17 @@ func (fs *FilerServer) readonlyFilerHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if !fs.maybeCheckJwtAuthorization(r, false) {
+	scope, authorized := fs.maybeCheckJwtAuthorization(r, false)
+	if !authorized {
 		writeJsonError(w, r, http.StatusUnauthorized, errors.New("wrong jwt"))
 		return
 	}
+	if scope != nil {
+		if status, err := checkFilerAccessScope(w, r, scope); err != nil {
+			writeJsonError(w, r, status, err)
+			return
+		}
+	}
 
 	w.Header().Set("Server", "SeaweedFS "+version.VERSION)
 
@@ -189,20 +204,21 @@ func OptionsHandler(w http.ResponseWriter, r *http.Request, isReadOnly bool) {
 	w.Header().Set("Access-Control-Allow-Credentials", "true")
 }
 
-// maybeCheckJwtAuthorization returns true if access should be granted, false if it should be denied
-func (fs *FilerServer) maybeCheckJwtAuthorization(r *http.Request, isWrite bool) bool {
+// maybeCheckJwtAuthorization returns true if access should be granted, false if it should be denied.
+// A granted JWT may restrict the access to its scope.
+func (fs *FilerServer) maybeCheckJwtAuthorization(r *http.Request, isWrite bool) (*security.FilerAccessScope, bool) {
 
 	var signingKey security.SigningKey
 
 	if isWrite {
 		if len(fs.filerGuard.SigningKey) == 0 {
-			return true
+			return nil, true
 		} else {
 			signingKey = fs.filerGuard.SigningKey
 		}
 	} else {
 		if len(fs.filerGuard.ReadSigningKey) == 0 {
-			return true
+			return nil, true
 		} else {
 			signingKey = fs.filerGuard.ReadSigningKey
 		}
@@ -211,19 +227,20 @@ func (fs *FilerServer) maybeCheckJwtAuthorization(r *http.Request, isWrite bool)
 	tokenStr := security.GetJwt(r)
 	if tokenStr == "" {
 		glog.V(1).Infof("missing jwt from %s", r.RemoteAddr)
-		return false
+		return nil, false
 	}
 
-	token, err := security.DecodeJwt(signingKey, tokenStr, &security.SeaweedFilerClaims{})
+	claims := &security.SeaweedFilerClaims{}
+	token, err := security.DecodeJwt(signingKey, tokenStr, claims)
 	if err != nil {
 		glog.V(1).Infof("jwt verification error from %s: %v", r.RemoteAddr, err)
-		return false
+		return nil, false
 	}
 	if !token.Valid {
 		glog.V(1).Infof("jwt invalid from %s: %v", r.RemoteAddr, tokenStr)
-		return false
+		return nil, false
 	} else {
-		return true
+		return claims.Scope, true
 	}
 }
 
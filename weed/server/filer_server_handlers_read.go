@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3parts"
 	"github.com/seaweedfs/seaweedfs/weed/security"
 
 	"github.com/seaweedfs/seaweedfs/weed/filer"
@@ -160,18 +161,48 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	var etag string
+	var partSize int64 = -1
 	if partNumber, errNum := strconv.Atoi(r.Header.Get(s3_constants.SeaweedFSPartNumber)); errNum == nil {
-		if len(entry.Chunks) < partNumber {
-			stats.FilerHandlerCounter.WithLabelValues(stats.ErrorReadChunk).Inc()
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("InvalidPart"))
-			return
+		etag = filer.ETagEntry(entry)
+		if encodedParts, found := entry.Extended[s3_constants.SeaweedFSMultipartParts]; found {
+			// part boundaries recorded when the multipart upload was completed
+			parts, err := s3parts.Decode(encodedParts)
+			if err != nil {
+				glog.ErrorfCtx(ctx, "%s: %v", entry.FullPath, err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			part, found := s3parts.Get(parts, partNumber)
+			if !found {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("InvalidPart"))
+				return
+			}
+			w.Header().Set(s3_constants.AmzMpPartsCount, strconv.Itoa(len(parts)))
+			partSize = part.Size
+			r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", part.Offset, part.Offset+part.Size-1))
+		} else if !strings.Contains(etag, "-") {
+			// an object uploaded in a single request is its only part
+			if partNumber != 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("InvalidPart"))
+				return
+			}
+		} else {
+			// multipart objects completed before part boundaries were recorded
+			if partNumber < 1 || len(entry.Chunks) < partNumber {
+				stats.FilerHandlerCounter.WithLabelValues(stats.ErrorReadChunk).Inc()
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("InvalidPart"))
+				return
+			}
+			w.Header().Set(s3_constants.AmzMpPartsCount, strconv.Itoa(len(entry.Chunks)))
+			partChunk := entry.GetChunks()[partNumber-1]
+			md5, _ := base64.StdEncoding.DecodeString(partChunk.ETag)
+			etag = hex.EncodeToString(md5)
+			partSize = int64(partChunk.Size)
+			r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", partChunk.Offset, uint64(partChunk.Offset)+partChunk.Size-1))
 		}
-		w.Header().Set(s3_constants.AmzMpPartsCount, strconv.Itoa(len(entry.Chunks)))
-		partChunk := entry.GetChunks()[partNumber-1]
-		md5, _ := base64.StdEncoding.DecodeString(partChunk.ETag)
-		etag = hex.EncodeToString(md5)
-		r.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", partChunk.Offset, uint64(partChunk.Offset)+partChunk.Size-1))
 	} else {
 		etag = filer.ETagEntry(entry)
 	}
@@ -252,7 +283,11 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request)
 	totalSize := int64(entry.FileSize)
 
 	if r.Method == http.MethodHead {
-		w.Header().Set("Content-Length", strconv.FormatInt(totalSize, 10))
+		if partSize >= 0 {
+			w.Header().Set("Content-Length", strconv.FormatInt(partSize, 10))
+		} else {
+			w.Header().Set("Content-Length", strconv.FormatInt(totalSize, 10))
+		}
 		return
 	}
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/operation"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -71,6 +72,9 @@ func (fs *FilerServer) assignNewFileInfo(ctx context.Context, so *operation.Stor
 
 func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request, contentLength int64) {
 	ctx := r.Context()
+	if r.Header.Get(s3_constants.SeaweedFSBypassGovernanceHeader) == "true" {
+		ctx = filer.WithGovernanceBypass(ctx)
+	}
 
 	destination := r.RequestURI
 	if finalDestination := r.Header.Get(s3_constants.SeaweedStorageDestinationHeader); finalDestination != "" {
@@ -88,6 +92,7 @@ func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request, conte
 		query.Get("rack"),
 		query.Get("dataNode"),
 		query.Get("saveInside"),
+		r.Header.Get(s3_constants.AmzStorageClass),
 	)
 	if err != nil {
 		if err == ErrReadOnly {
@@ -173,6 +178,11 @@ func (fs *FilerServer) move(ctx context.Context, w http.ResponseWriter, r *http.
 		return
 	}
 
+	if err = fs.filer.CheckObjectLockTree(ctx, srcEntry); err != nil {
+		writeJsonError(w, r, http.StatusForbidden, err)
+		return
+	}
+
 	oldDir, oldName := srcPath.DirAndName()
 	newDir, newName := dstPath.DirAndName()
 	newName = util.Nvl(newName, oldName)
@@ -233,6 +243,10 @@ func (fs *FilerServer) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	err = fs.filer.DeleteEntryMetaAndData(context.Background(), util.FullPath(objectPath), isRecursive, ignoreRecursiveError, !skipChunkDeletion, false, nil, 0)
+	if errors.Is(err, filer.ErrObjectLocked) {
+		writeJsonError(w, r, http.StatusForbidden, err)
+		return
+	}
 	if err != nil && err != filer_pb.ErrNotFound {
 		glog.V(1).Infoln("deleting", objectPath, ":", err.Error())
 		writeJsonError(w, r, http.StatusInternalServerError, err)
@@ -279,10 +293,18 @@ func (fs *FilerServer) detectStorageOption(ctx context.Context, requestURI, qCol
 		Fsync:             rule.Fsync,
 		VolumeGrowthCount: rule.VolumeGrowthCount,
 		MaxFileNameLength: rule.MaxFileNameLength,
+		// chunks with a ttl expire regardless of the files sharing them
+		Dedup: rule.Dedup && ttlSeconds == 0,
 	}, nil
 }
 
-func (fs *FilerServer) detectStorageOption0(ctx context.Context, requestURI, qCollection, qReplication string, qTtl string, diskType string, fsync string, dataCenter, rack, dataNode, saveInside string) (*operation.StorageOption, error) {
+func (fs *FilerServer) detectStorageOption0(ctx context.Context, requestURI, qCollection, qReplication string, qTtl string, diskType string, fsync string, dataCenter, rack, dataNode, saveInside, storageClass string) (*operation.StorageOption, error) {
+
+	// the placement of an S3 storage class applies unless overridden by the query
+	if storageClassConf, found := fs.filer.FilerConf.GetStorageClassConf(storageClass); found {
+		qReplication = util.Nvl(qReplication, storageClassConf.Replication)
+		diskType = util.Nvl(diskType, storageClassConf.DiskType)
+	}
 
 	ttl, err := needle.ReadTTL(qTtl)
 	if err != nil {
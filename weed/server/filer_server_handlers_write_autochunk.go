@@ -50,12 +50,14 @@ func (fs *FilerServer) autoChunk(ctx context.Context, w http.ResponseWriter, r *
 		reply, md5bytes, err = fs.doPutAutoChunk(ctx, w, r, chunkSize, contentLength, so)
 	}
 	if err != nil {
-		if err.Error() == "operation not permitted" {
+		if err.Error() == "operation not permitted" || errors.Is(err, errSignedUrlDenied) {
 			writeJsonError(w, r, http.StatusForbidden, err)
 		} else if strings.HasPrefix(err.Error(), "read input:") || err.Error() == io.ErrUnexpectedEOF.Error() {
 			writeJsonError(w, r, util.HttpStatusCancelled, err)
 		} else if strings.HasSuffix(err.Error(), "is a file") || strings.HasSuffix(err.Error(), "already exists") {
 			writeJsonError(w, r, http.StatusConflict, err)
+		} else if errors.Is(err, filer.ErrQuotaExceeded) {
+			writeJsonError(w, r, http.StatusInsufficientStorage, err)
 		} else {
 			writeJsonError(w, r, http.StatusInternalServerError, err)
 		}
@@ -84,6 +86,9 @@ func (fs *FilerServer) doPostAutoChunk(ctx context.Context, w http.ResponseWrite
 		fileName = path.Base(fileName)
 	}
 	contentType := part1.Header.Get("Content-Type")
+	if scope := filerAccessScopeOf(ctx); scope != nil && !scope.AllowsContentType(contentType) {
+		return nil, nil, fmt.Errorf("content type %q: %w", contentType, errSignedUrlDenied)
+	}
 	if contentType == "application/octet-stream" {
 		contentType = ""
 	}
@@ -174,6 +179,11 @@ func (fs *FilerServer) checkPermissions(ctx context.Context, r *http.Request, fi
 		return errors.New("operation not permitted")
 	}
 
+	// you cannot change a file under s3 object lock
+	if entry, findErr := fs.filer.FindEntry(ctx, util.FullPath(fullPath)); findErr == nil {
+		return filer.CheckObjectLock(ctx, entry)
+	}
+
 	return nil
 }
 
@@ -520,6 +530,13 @@ func SaveAmzMetaData(r *http.Request, existing map[string][]byte, isReplace bool
 		}
 	}
 
+	// the S3 gateway sends the additional checksums in the trailer, as they are known once the body is read
+	for header, values := range r.Trailer {
+		if strings.HasPrefix(header, s3_constants.AmzChecksumPrefix) && len(values) > 0 {
+			metadata[header] = []byte(values[0])
+		}
+	}
+
 	// Handle SSE-C headers
 	if algorithm := r.Header.Get(s3_constants.AmzServerSideEncryptionCustomerAlgorithm); algorithm != "" {
 		metadata[s3_constants.AmzServerSideEncryptionCustomerAlgorithm] = []byte(algorithm)
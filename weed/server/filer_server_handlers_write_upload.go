@@ -17,6 +17,7 @@ import (
 
 	"encoding/json"
 
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/operation"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -24,6 +25,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/security"
 	"github.com/seaweedfs/seaweedfs/weed/stats"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/util/cdc"
 )
 
 var bufPool = sync.Pool{
@@ -64,6 +66,16 @@ func (fs *FilerServer) uploadReaderToChunks(ctx context.Context, r *http.Request
 	bytesBufferLimitChan := make(chan struct{}, bytesBufferCounter)
 	var fileChunksLock sync.Mutex
 	var uploadErrLock sync.Mutex
+
+	// content-defined chunks are cut at the same content, and shared by other files with the same chunks
+	var chunker *cdc.Chunker
+	if so.Dedup && !hasSSEHeaders(r) {
+		if err := fs.filer.CanShareChunks(); err != nil {
+			glog.V(1).InfofCtx(ctx, "skip deduplication of %s: %v", fileName, err)
+		} else {
+			chunker = cdc.NewChunker(partReader, int(chunkSize))
+		}
+	}
 	for {
 
 		// need to throttle used byte buffer
@@ -81,11 +93,21 @@ func (fs *FilerServer) uploadReaderToChunks(ctx context.Context, r *http.Request
 
 		bytesBuffer := bufPool.Get().(*bytes.Buffer)
 
-		limitedReader := io.LimitReader(partReader, int64(chunkSize))
-
 		bytesBuffer.Reset()
 
-		dataSize, err := bytesBuffer.ReadFrom(limitedReader)
+		var dataSize int64
+		var isLast bool
+		var err error
+		if chunker != nil {
+			var n int
+			n, isLast, err = chunker.Next(bytesBuffer)
+			dataSize = int64(n)
+		} else {
+			limitedReader := io.LimitReader(partReader, int64(chunkSize))
+			dataSize, err = bytesBuffer.ReadFrom(limitedReader)
+			// if last chunk was not at full chunk size, but already exhausted the reader
+			isLast = dataSize < int64(chunkSize)
+		}
 
 		// data, err := io.ReadAll(limitedReader)
 		if err != nil || dataSize == 0 {
@@ -101,7 +123,7 @@ func (fs *FilerServer) uploadReaderToChunks(ctx context.Context, r *http.Request
 			break
 		}
 		if chunkOffset == 0 && !isAppend {
-			if dataSize < fs.option.SaveToFilerLimit {
+			if isLast && dataSize < fs.option.SaveToFilerLimit {
 				chunkOffset += dataSize
 				smallContent = make([]byte, dataSize)
 				bytesBuffer.Read(smallContent)
@@ -122,7 +144,13 @@ func (fs *FilerServer) uploadReaderToChunks(ctx context.Context, r *http.Request
 				wg.Done()
 			}()
 
-			chunks, toChunkErr := fs.dataToChunkWithSSE(ctx, r, fileName, contentType, buf.Bytes(), offset, so)
+			var chunks []*filer_pb.FileChunk
+			var toChunkErr error
+			if chunker != nil {
+				chunks, toChunkErr = fs.dedupDataToChunk(ctx, fileName, contentType, buf.Bytes(), offset, so)
+			} else {
+				chunks, toChunkErr = fs.dataToChunkWithSSE(ctx, r, fileName, contentType, buf.Bytes(), offset, so)
+			}
 			if toChunkErr != nil {
 				uploadErrLock.Lock()
 				if uploadErr == nil {
@@ -144,8 +172,7 @@ func (fs *FilerServer) uploadReaderToChunks(ctx context.Context, r *http.Request
 		// reset variables for the next chunk
 		chunkOffset = chunkOffset + dataSize
 
-		// if last chunk was not at full chunk size, but already exhausted the reader
-		if dataSize < int64(chunkSize) {
+		if isLast {
 			break
 		}
 	}
@@ -323,3 +350,31 @@ func (fs *FilerServer) dataToChunkWithSSE(ctx context.Context, r *http.Request,
 
 	return []*filer_pb.FileChunk{chunk}, nil
 }
+
+// dedupDataToChunk shares a stored chunk with the same content, or uploads and indexes a new chunk.
+// Both are marked as deduplicated, so that deleting them releases their reference instead.
+func (fs *FilerServer) dedupDataToChunk(ctx context.Context, fileName, contentType string, data []byte, chunkOffset int64, so *operation.StorageOption) ([]*filer_pb.FileChunk, error) {
+	fingerprint := filer.ChunkFingerprint(so.Collection, so.Replication, so.DiskType, data)
+	if chunk, found := fs.filer.FindDuplicateChunk(ctx, fingerprint); found {
+		stats.FilerHandlerCounter.WithLabelValues(stats.ChunkDedup).Inc()
+		chunk.Offset = chunkOffset
+		chunk.ModifiedTsNs = time.Now().UnixNano()
+		return []*filer_pb.FileChunk{chunk}, nil
+	}
+	chunks, err := fs.dataToChunk(ctx, fileName, contentType, data, chunkOffset, so)
+	if err != nil || len(chunks) != 1 {
+		return chunks, err
+	}
+	if err := fs.filer.RegisterDedupChunk(ctx, fingerprint, chunks[0]); err != nil {
+		// the chunk is still written, only not shared
+		glog.ErrorfCtx(ctx, "index chunk %s of %s: %v", chunks[0].GetFileIdString(), fileName, err)
+	}
+	return chunks, nil
+}
+
+// hasSSEHeaders is true if the chunks are encrypted with keys of the request, and can not be shared
+func hasSSEHeaders(r *http.Request) bool {
+	return r != nil && (r.Header.Get(s3_constants.SeaweedFSSSEKMSKeyHeader) != "" ||
+		r.Header.Get(s3_constants.AmzServerSideEncryptionCustomerAlgorithm) != "" ||
+		r.Header.Get(s3_constants.SeaweedFSSSES3Key) != "")
+}
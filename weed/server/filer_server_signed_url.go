@@ -0,0 +1,67 @@
+package weed_server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/security"
+)
+
+// errSignedUrlDenied is returned for requests outside of the scope of a signed URL
+var errSignedUrlDenied = errors.New("not permitted by the signed url")
+
+type filerAccessScopeKey struct{}
+
+func withFilerAccessScope(ctx context.Context, scope *security.FilerAccessScope) context.Context {
+	return context.WithValue(ctx, filerAccessScopeKey{}, scope)
+}
+
+// filerAccessScopeOf returns the scope of the JWT authorizing the request, nil without restrictions
+func filerAccessScopeOf(ctx context.Context) *security.FilerAccessScope {
+	scope, _ := ctx.Value(filerAccessScopeKey{}).(*security.FilerAccessScope)
+	return scope
+}
+
+// checkFilerAccessScope checks a request authorized by a scoped JWT, and limits
+// the size of its body. The content type of multipart uploads is checked when
+// the file part is read.
+func checkFilerAccessScope(w http.ResponseWriter, r *http.Request, scope *security.FilerAccessScope) (int, error) {
+	urlPath := r.URL.Path
+	cleanPath := path.Clean(urlPath)
+	if cleanPath != strings.TrimSuffix(urlPath, "/") && urlPath != "/" {
+		return http.StatusBadRequest, fmt.Errorf("path %s: %w", urlPath, errSignedUrlDenied)
+	}
+	if !scope.AllowsPath(cleanPath) {
+		return http.StatusForbidden, fmt.Errorf("path %s: %w", urlPath, errSignedUrlDenied)
+	}
+	if !scope.AllowsMethod(r.Method) {
+		return http.StatusForbidden, fmt.Errorf("method %s: %w", r.Method, errSignedUrlDenied)
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		return http.StatusOK, nil
+	}
+
+	query := r.URL.Query()
+	if query.Has("mv.from") || query.Has("cp.from") {
+		return http.StatusForbidden, fmt.Errorf("move or copy: %w", errSignedUrlDenied)
+	}
+	if scope.MaxSize > 0 {
+		if r.ContentLength > scope.MaxSize {
+			return http.StatusRequestEntityTooLarge, fmt.Errorf("size %d over %d: %w", r.ContentLength, scope.MaxSize, errSignedUrlDenied)
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, scope.MaxSize)
+	}
+	contentType := r.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); r.Method == http.MethodPost && mediaType == "multipart/form-data" {
+		return http.StatusOK, nil
+	}
+	if !scope.AllowsContentType(contentType) {
+		return http.StatusForbidden, fmt.Errorf("content type %q: %w", contentType, errSignedUrlDenied)
+	}
+	return http.StatusOK, nil
+}
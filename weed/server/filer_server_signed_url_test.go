@@ -0,0 +1,78 @@
+package weed_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/security"
+)
+
+func TestCheckFilerAccessScope(t *testing.T) {
+	signingKey := security.SigningKey("secret")
+	scope := &security.FilerAccessScope{
+		PathPrefix:   "/uploads/user1/",
+		Methods:      []string{"PUT", "POST", "GET"},
+		MaxSize:      10,
+		ContentTypes: []string{"image/*", "text/plain"},
+	}
+	encodedJwt := security.GenScopedJwtForFilerServer(signingKey, 60, scope)
+
+	claims := &security.SeaweedFilerClaims{}
+	if _, err := security.DecodeJwt(signingKey, encodedJwt, claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Scope == nil || claims.Scope.PathPrefix != scope.PathPrefix || claims.Scope.MaxSize != 10 {
+		t.Fatalf("unexpected scope %+v", claims.Scope)
+	}
+	if _, err := security.DecodeJwt(security.SigningKey("other"), encodedJwt, &security.SeaweedFilerClaims{}); err == nil {
+		t.Fatalf("expected a signature error")
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"upload", http.MethodPut, "/uploads/user1/a.png", "image/png", "png", http.StatusOK},
+		{"upload with parameters", http.MethodPut, "/uploads/user1/a.txt", "text/plain; charset=utf-8", "txt", http.StatusOK},
+		{"folder itself", http.MethodPost, "/uploads/user1/", "multipart/form-data; boundary=x", "", http.StatusOK},
+		{"download", http.MethodHead, "/uploads/user1/a.png", "", "", http.StatusOK},
+		{"other folder", http.MethodPut, "/uploads/user10/a.png", "image/png", "png", http.StatusForbidden},
+		{"dot dot", http.MethodPut, "/uploads/user1/../user2/a.png", "image/png", "png", http.StatusBadRequest},
+		{"method", http.MethodDelete, "/uploads/user1/a.png", "", "", http.StatusForbidden},
+		{"content type", http.MethodPut, "/uploads/user1/a.pdf", "application/pdf", "pdf", http.StatusForbidden},
+		{"no content type", http.MethodPut, "/uploads/user1/a", "", "x", http.StatusForbidden},
+		{"too large", http.MethodPut, "/uploads/user1/a.png", "image/png", "12345678901", http.StatusRequestEntityTooLarge},
+		{"move", http.MethodPost, "/uploads/user1/a.png?mv.from=/etc/passwd", "image/png", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			status, err := checkFilerAccessScope(httptest.NewRecorder(), r, scope)
+			if err == nil {
+				status = http.StatusOK
+			}
+			if status != tt.status {
+				t.Errorf("expected status %d, got %d: %v", tt.status, status, err)
+			}
+		})
+	}
+}
+
+func TestFilerAccessScopeFilePath(t *testing.T) {
+	scope := &security.FilerAccessScope{PathPrefix: "/reports/2025-06.pdf"}
+	if !scope.AllowsPath("/reports/2025-06.pdf") {
+		t.Errorf("expected the file to be allowed")
+	}
+	if scope.AllowsPath("/reports/2025-06.pdf.bak") || scope.AllowsPath("/reports") {
+		t.Errorf("expected only the file to be allowed")
+	}
+}
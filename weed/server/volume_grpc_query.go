@@ -1,36 +1,32 @@
 package weed_server
 
 import (
+	"errors"
+	"fmt"
+	"io"
+
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/operation"
 	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
 	"github.com/seaweedfs/seaweedfs/weed/query/json"
+	"github.com/seaweedfs/seaweedfs/weed/query/s3select"
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/util"
 	"github.com/tidwall/gjson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func (vs *VolumeServer) Query(req *volume_server_pb.QueryRequest, stream volume_server_pb.VolumeServer_QueryServer) error {
 
+	if req.Expression != "" {
+		return vs.queryExpression(req, stream)
+	}
+
 	for _, fid := range req.FromFileIds {
 
-		vid, id_cookie, err := operation.ParseFileId(fid)
+		data, err := vs.readQueryNeedle(fid)
 		if err != nil {
-			glog.V(0).Infof("volume query failed to parse fid %s: %v", fid, err)
-			return err
-		}
-
-		n := new(needle.Needle)
-		volumeId, _ := needle.NewVolumeId(vid)
-		n.ParsePath(id_cookie)
-
-		cookie := n.Cookie
-		if _, err := vs.store.ReadVolumeNeedle(volumeId, n, nil, nil); err != nil {
-			glog.V(0).Infof("volume query failed to read fid %s: %v", fid, err)
-			return err
-		}
-
-		if n.Cookie != cookie {
-			glog.V(0).Infof("volume query failed to read fid cookie %s: %v", fid, err)
 			return err
 		}
 
@@ -49,7 +45,7 @@ func (vs *VolumeServer) Query(req *volume_server_pb.QueryRequest, stream volume_
 				Op:    req.Filter.Operand,
 				Value: req.Filter.Value,
 			}
-			gjson.ForEachLine(string(n.Data), func(line gjson.Result) bool {
+			gjson.ForEachLine(string(data), func(line gjson.Result) bool {
 				passedFilter, values := json.QueryJson(line.Raw, req.Selections, filter)
 				if !passedFilter {
 					return true
@@ -67,3 +63,95 @@ func (vs *VolumeServer) Query(req *volume_server_pb.QueryRequest, stream volume_
 
 	return nil
 }
+
+// queryExpression runs an S3 Select expression over the file ids, which are read in order
+// as consecutive parts of one object, so records may span chunk boundaries.
+func (vs *VolumeServer) queryExpression(req *volume_server_pb.QueryRequest, stream volume_server_pb.VolumeServer_QueryServer) error {
+
+	sel, err := s3select.NewSelect(req.Expression, req.InputSerialization, req.OutputSerialization)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	content := &queryNeedleReader{vs: vs, fids: req.FromFileIds}
+	stats, err := sel.Run(content, &queryStripeWriter{stream: stream})
+	if err != nil {
+		glog.V(1).Infof("volume query %v: %v", req.FromFileIds, err)
+		var evalErr *s3select.EvaluationError
+		if errors.As(err, &evalErr) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return err
+	}
+
+	return stream.Send(&volume_server_pb.QueriedStripe{
+		BytesScanned:   stats.BytesScanned,
+		BytesProcessed: stats.BytesProcessed,
+	})
+}
+
+func (vs *VolumeServer) readQueryNeedle(fid string) ([]byte, error) {
+
+	vid, id_cookie, err := operation.ParseFileId(fid)
+	if err != nil {
+		glog.V(0).Infof("volume query failed to parse fid %s: %v", fid, err)
+		return nil, err
+	}
+
+	n := new(needle.Needle)
+	volumeId, _ := needle.NewVolumeId(vid)
+	if err = n.ParsePath(id_cookie); err != nil {
+		return nil, err
+	}
+
+	cookie := n.Cookie
+	if _, err := vs.store.ReadVolumeNeedle(volumeId, n, nil, nil); err != nil {
+		glog.V(0).Infof("volume query failed to read fid %s: %v", fid, err)
+		return nil, err
+	}
+
+	if n.Cookie != cookie {
+		glog.V(0).Infof("volume query failed to read fid cookie %s", fid)
+		return nil, fmt.Errorf("unexpected cookie for %s", fid)
+	}
+
+	if n.IsCompressed() {
+		return util.DecompressData(n.Data)
+	}
+	return n.Data, nil
+}
+
+// queryNeedleReader reads the needles one after another, keeping only one in memory
+type queryNeedleReader struct {
+	vs      *VolumeServer
+	fids    []string
+	current []byte
+}
+
+func (r *queryNeedleReader) Read(p []byte) (int, error) {
+	for len(r.current) == 0 {
+		if len(r.fids) == 0 {
+			return 0, io.EOF
+		}
+		data, err := r.vs.readQueryNeedle(r.fids[0])
+		if err != nil {
+			return 0, err
+		}
+		r.fids, r.current = r.fids[1:], data
+	}
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+// queryStripeWriter sends each batch of serialized records as one stripe
+type queryStripeWriter struct {
+	stream volume_server_pb.VolumeServer_QueryServer
+}
+
+func (w *queryStripeWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&volume_server_pb.QueriedStripe{Records: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
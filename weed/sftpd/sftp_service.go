@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/pkg/sftp"
+	"github.com/seaweedfs/seaweedfs/weed/credential"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb"
 	"github.com/seaweedfs/seaweedfs/weed/sftpd/auth"
@@ -60,12 +61,44 @@ func NewSFTPService(options *SFTPServiceOptions) *SFTPService {
 	}
 	service.userStore = userStore
 
+	// Check passwords with the credential store, such as LDAP, when it supports passwords
+	if authenticator := passwordAuthenticator(); authenticator != nil {
+		glog.V(0).Infof("SFTP password authentication uses the credential store")
+		service.userStore = user.NewDirectoryStore(userStore, authenticator)
+	}
+
 	// Initialize auth manager
-	service.authManager = auth.NewManager(userStore, options.AuthMethods)
+	service.authManager = auth.NewManager(service.userStore, options.AuthMethods)
 
 	return &service
 }
 
+// passwordAuthenticator returns the store configured in credential.toml if it can verify passwords
+func passwordAuthenticator() credential.PasswordAuthenticator {
+	credConfig, err := credential.LoadCredentialConfiguration()
+	if err != nil {
+		glog.Warningf("failed to load credential configuration: %v", err)
+		return nil
+	}
+	if credConfig == nil {
+		return nil
+	}
+	supported := false
+	for _, store := range credential.Stores {
+		if _, ok := store.(credential.PasswordAuthenticator); ok && string(store.GetName()) == credConfig.Store {
+			supported = true
+		}
+	}
+	if !supported {
+		return nil
+	}
+	credentialManager, err := credential.NewCredentialManager(credential.CredentialStoreTypeName(credConfig.Store), credConfig.Config, credConfig.Prefix)
+	if err != nil {
+		glog.Fatalf("Failed to initialize credential store: %v", err)
+	}
+	return credentialManager.GetStore().(credential.PasswordAuthenticator)
+}
+
 // Serve accepts incoming connections on the provided listener and handles them.
 func (s *SFTPService) Serve(listener net.Listener) error {
 	// Build SSH server config
@@ -0,0 +1,78 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+
+	"github.com/seaweedfs/seaweedfs/weed/credential"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// DirectoryStore checks passwords with a credential store such as LDAP.
+// Users in the wrapped store keep their home directories, permissions and
+// public keys; directory users without such an entry get a home directory
+// after their first successful login.
+type DirectoryStore struct {
+	Store
+	authenticator  credential.PasswordAuthenticator
+	directoryUsers map[string]*User
+	mu             sync.RWMutex
+}
+
+// NewDirectoryStore wraps a user store to authenticate passwords with a credential store
+func NewDirectoryStore(store Store, authenticator credential.PasswordAuthenticator) *DirectoryStore {
+	return &DirectoryStore{
+		Store:          store,
+		authenticator:  authenticator,
+		directoryUsers: make(map[string]*User),
+	}
+}
+
+// GetUser returns a user of the wrapped store, or a directory user that has logged in
+func (s *DirectoryStore) GetUser(username string) (*User, error) {
+	if user, err := s.Store.GetUser(username); err == nil {
+		return user, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if user, ok := s.directoryUsers[username]; ok {
+		return user, nil
+	}
+	return nil, &UserNotFoundError{Username: username}
+}
+
+// ValidatePassword accepts the password of the wrapped store, or binds to the directory as the user
+func (s *DirectoryStore) ValidatePassword(username string, password []byte) bool {
+	if s.Store.ValidatePassword(username, password) {
+		return true
+	}
+
+	_, err := s.authenticator.Authenticate(context.Background(), username, string(password))
+	if err != nil {
+		if !errors.Is(err, credential.ErrInvalidPassword) {
+			glog.Errorf("failed to authenticate %s: %v", username, err)
+		}
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.directoryUsers[username]; !ok {
+		s.directoryUsers[username] = newDirectoryUser(username)
+	}
+	return true
+}
+
+// newDirectoryUser derives the uid from the name, so files keep their owner across restarts
+func newDirectoryUser(username string) *User {
+	user := NewUser(username)
+	hash := fnv.New32a()
+	hash.Write([]byte(username))
+	user.Uid = 1000 + hash.Sum32()%59000
+	user.Gid = user.Uid
+	user.Permissions[user.HomeDir] = []string{"all"}
+	return user
+}
@@ -43,6 +43,26 @@ func (c *commandFsConfigure) Help() string {
 	# delete the changes
 	fs.configure -locationPrefix=/my/folder -delete -apply
 
+	# keep deleted entries in the trash for 3 days, see "fs.trash.list"
+	fs.configure -locationPrefix=/my/folder -trashRetention=72h -apply
+
+	# split uploads into content-defined chunks, and share the chunks with the same content, e.g. for build artifacts
+	fs.configure -locationPrefix=/buckets/artifacts -dedup -apply
+
+	# place S3 objects by their storage class, e.g. hot data on ssd and cold data on hdd
+	fs.configure -storageClass=STANDARD -disk=ssd -apply
+	fs.configure -storageClass=STANDARD_IA -disk=hdd -replication=000 -apply
+
+	# delete a storage class placement
+	fs.configure -storageClass=STANDARD_IA -delete -apply
+
+	The storage class placement takes precedence over the locationPrefix configuration.
+	Lifecycle Transition rules move existing objects onto the placement of their new storage class.
+	Deduplication applies to the files written through the filer http api and S3, except with a ttl or SSE.
+	Files written by mount keep the fixed-size chunks, and are not deduplicated.
+	Written chunks stay shared after the deduplication is turned off.
+	The chunk references are counted in the filer store, so all filers need to share the same filer store.
+
 `
 }
 
@@ -54,6 +74,7 @@ func (c *commandFsConfigure) Do(args []string, commandEnv *CommandEnv, writer io
 
 	fsConfigureCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
 	locationPrefix := fsConfigureCommand.String("locationPrefix", "", "path prefix, required to update the path-specific configuration")
+	storageClass := fsConfigureCommand.String("storageClass", "", "S3 storage class, e.g. STANDARD or STANDARD_IA, to update its replication and disk type")
 	collection := fsConfigureCommand.String("collection", "", "assign writes to this collection")
 	replication := fsConfigureCommand.String("replication", "", "assign writes with this replication")
 	ttl := fsConfigureCommand.String("ttl", "", "assign writes with this ttl (e.g., 1m, 1h, 1d, 1w, 1y)")
@@ -63,12 +84,14 @@ func (c *commandFsConfigure) Do(args []string, commandEnv *CommandEnv, writer io
 	worm := fsConfigureCommand.Bool("worm", false, "write-once-read-many, written files are readonly")
 	wormGracePeriod := fsConfigureCommand.Uint64("wormGracePeriod", 0, "grace period before worm is enforced, in seconds")
 	wormRetentionTime := fsConfigureCommand.Uint64("wormRetentionTime", 0, "retention time for a worm enforced file, in seconds")
+	dedup := fsConfigureCommand.Bool("dedup", false, "split writes into content-defined chunks, shared by files with the same chunks")
+	trashRetention := fsConfigureCommand.Duration("trashRetention", 0, "keep deleted entries in the trash for this long before deleting their data, e.g. 72h")
 	maxFileNameLength := fsConfigureCommand.Uint("maxFileNameLength", 0, "file name length limits in bytes for compatibility with Unix-based systems")
 	dataCenter := fsConfigureCommand.String("dataCenter", "", "assign writes to this dataCenter")
 	rack := fsConfigureCommand.String("rack", "", "assign writes to this rack")
 	dataNode := fsConfigureCommand.String("dataNode", "", "assign writes to this dataNode")
 	volumeGrowthCount := fsConfigureCommand.Int("volumeGrowthCount", 0, "the number of physical volumes to add if no writable volumes")
-	isDelete := fsConfigureCommand.Bool("delete", false, "delete the configuration by locationPrefix")
+	isDelete := fsConfigureCommand.Bool("delete", false, "delete the configuration by locationPrefix or storageClass")
 	apply := fsConfigureCommand.Bool("apply", false, "update and apply filer configuration")
 	if err = fsConfigureCommand.Parse(args); err != nil {
 		return nil
@@ -97,6 +120,8 @@ func (c *commandFsConfigure) Do(args []string, commandEnv *CommandEnv, writer io
 			Worm:                     *worm,
 			WormGracePeriodSeconds:   *wormGracePeriod,
 			WormRetentionTimeSeconds: *wormRetentionTime,
+			TrashRetentionSeconds:    uint64(trashRetention.Seconds()),
+			Dedup:                    *dedup,
 		}
 
 		// check collection
@@ -133,6 +158,29 @@ func (c *commandFsConfigure) Do(args []string, commandEnv *CommandEnv, writer io
 		}
 	}
 
+	if *storageClass != "" {
+		if *locationPrefix != "" {
+			return fmt.Errorf("-storageClass and -locationPrefix can not be configured together")
+		}
+		infoAboutSimulationMode(writer, *apply, "-apply")
+
+		if *replication != "" {
+			if _, err := super_block.NewReplicaPlacementFromString(*replication); err != nil {
+				return fmt.Errorf("parse replication %s: %v", *replication, err)
+			}
+		}
+
+		if *isDelete {
+			fc.DeleteStorageClassConf(*storageClass)
+		} else {
+			fc.SetStorageClassConf(&filer_pb.FilerConf_StorageClassConf{
+				StorageClass: *storageClass,
+				Replication:  *replication,
+				DiskType:     *diskType,
+			})
+		}
+	}
+
 	var buf2 bytes.Buffer
 	fc.ToText(&buf2)
 
@@ -0,0 +1,87 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsQuotaGet{})
+}
+
+type commandFsQuotaGet struct {
+}
+
+func (c *commandFsQuotaGet) Name() string {
+	return "fs.quota.get"
+}
+
+func (c *commandFsQuotaGet) Help() string {
+	return `show the quota and usage of a directory
+
+	fs.quota.get /my/folder
+`
+}
+
+func (c *commandFsQuotaGet) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsQuotaGet) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	getCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	if err = getCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	path, err := commandEnv.parseUrl(findInputDirectory(getCommand.Args()))
+	if err != nil {
+		return err
+	}
+	dir, name := util.FullPath(path).DirAndName()
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		ctx := context.Background()
+		lookupResp, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: dir, Name: name})
+		if err != nil {
+			return fmt.Errorf("find %s: %w", path, err)
+		}
+		maxBytes, maxInodes := filer.QuotaLimits(filer.FromPbEntry(dir, lookupResp.Entry))
+		if maxBytes == 0 && maxInodes == 0 {
+			fmt.Fprintf(writer, "%s has no quota\n", path)
+			return nil
+		}
+		fmt.Fprintf(writer, "%s quota: %s\n", path, formatQuotaLimits(maxBytes, maxInodes))
+
+		resp, err := client.GetDirectoryQuotas(ctx, &filer_pb.GetDirectoryQuotasRequest{Directory: path})
+		if err != nil {
+			return fmt.Errorf("get quota usage: %w", err)
+		}
+		for _, q := range resp.Quotas {
+			if q.Directory == path {
+				fmt.Fprintf(writer, "%s usage: %s\n", path, formatQuotaUsage(q))
+			}
+		}
+		return nil
+	})
+}
+
+func formatQuotaUsage(q *filer_pb.DirectoryQuota) string {
+	if !q.IsCounted {
+		return "counting"
+	}
+	size := util.BytesToHumanReadable(uint64(max(q.UsedBytes, 0)))
+	if q.MaxBytes > 0 {
+		size = fmt.Sprintf("%s(%.1f%%)", size, float64(q.UsedBytes)*100/float64(q.MaxBytes))
+	}
+	count := fmt.Sprintf("%d", q.UsedInodes)
+	if q.MaxInodes > 0 {
+		count = fmt.Sprintf("%s(%.1f%%)", count, float64(q.UsedInodes)*100/float64(q.MaxInodes))
+	}
+	return fmt.Sprintf("size:%s inodes:%s", size, count)
+}
@@ -0,0 +1,82 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsQuotaReport{})
+}
+
+type commandFsQuotaReport struct {
+}
+
+func (c *commandFsQuotaReport) Name() string {
+	return "fs.quota.report"
+}
+
+func (c *commandFsQuotaReport) Help() string {
+	return `report the usage of all directory quotas
+
+	# report all quotas
+	fs.quota.report
+
+	# report the quotas at or under a folder
+	fs.quota.report /buckets
+
+	# only report the quotas using more than 90% of a limit
+	fs.quota.report -overPercent=90
+`
+}
+
+func (c *commandFsQuotaReport) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsQuotaReport) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	reportCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	overPercent := reportCommand.Float64("overPercent", 0, "only report the quotas using more than this percentage of a limit")
+	if err = reportCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	path := ""
+	if reportCommand.NArg() > 0 {
+		if path, err = commandEnv.parseUrl(reportCommand.Arg(0)); err != nil {
+			return err
+		}
+	}
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.GetDirectoryQuotas(context.Background(), &filer_pb.GetDirectoryQuotasRequest{Directory: path})
+		if err != nil {
+			return fmt.Errorf("get directory quotas: %w", err)
+		}
+		count := 0
+		for _, q := range resp.Quotas {
+			if *overPercent > 0 && quotaUsagePercent(q) <= *overPercent {
+				continue
+			}
+			count++
+			fmt.Fprintf(writer, "%s quota: %s usage: %s\n", q.Directory, formatQuotaLimits(q.MaxBytes, q.MaxInodes), formatQuotaUsage(q))
+		}
+		fmt.Fprintf(writer, "%d directory quotas\n", count)
+		return nil
+	})
+}
+
+// quotaUsagePercent is the highest usage percentage of the limits
+func quotaUsagePercent(q *filer_pb.DirectoryQuota) (percent float64) {
+	if q.MaxBytes > 0 {
+		percent = float64(q.UsedBytes) * 100 / float64(q.MaxBytes)
+	}
+	if q.MaxInodes > 0 {
+		percent = max(percent, float64(q.UsedInodes)*100/float64(q.MaxInodes))
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsQuotaSet{})
+}
+
+type commandFsQuotaSet struct {
+}
+
+func (c *commandFsQuotaSet) Name() string {
+	return "fs.quota.set"
+}
+
+func (c *commandFsQuotaSet) Help() string {
+	return `set the quota of a directory
+
+	# limit the size of all files under a folder to 10GiB, and the number of files and folders to 1 million
+	fs.quota.set -sizeMB=10240 -inodes=1000000 /my/folder
+
+	# remove the size limit, and keep the inodes limit
+	fs.quota.set -sizeMB=0 /my/folder
+
+	Each filer counts the usage of the directories with quotas, and rejects the writes
+	from all clients that would exceed the limits. Quotas can be nested.
+	The limits are soft: each filer only reserves the usage of its own writes, so writes
+	made at the same time through several filers can exceed a limit.
+
+	The size limit is the same as the bucket quota of "s3.bucket.quota". The size limits
+	of buckets are only enforced by filers started with -quota.enforceBuckets.
+`
+}
+
+func (c *commandFsQuotaSet) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsQuotaSet) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	setCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	sizeMB := setCommand.Int64("sizeMB", -1, "the size limit in MiB, 0 to remove the limit")
+	inodes := setCommand.Int64("inodes", -1, "the limit of files and folders, 0 to remove the limit")
+	if err = setCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *sizeMB < 0 && *inodes < 0 {
+		return fmt.Errorf("need -sizeMB or -inodes")
+	}
+
+	path, err := commandEnv.parseUrl(findInputDirectory(setCommand.Args()))
+	if err != nil {
+		return err
+	}
+	dir, name := util.FullPath(path).DirAndName()
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		ctx := context.Background()
+		resp, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: dir, Name: name})
+		if err != nil {
+			return fmt.Errorf("find %s: %w", path, err)
+		}
+		entry := resp.Entry
+		if !entry.IsDirectory {
+			return fmt.Errorf("%s is not a directory", path)
+		}
+
+		if *sizeMB >= 0 {
+			entry.Quota = *sizeMB * 1024 * 1024
+		}
+		if *inodes > 0 {
+			if entry.Extended == nil {
+				entry.Extended = make(map[string][]byte)
+			}
+			entry.Extended[filer.QuotaInodesKey] = []byte(strconv.FormatInt(*inodes, 10))
+		} else if *inodes == 0 {
+			delete(entry.Extended, filer.QuotaInodesKey)
+		}
+
+		if err := filer_pb.UpdateEntry(ctx, client, &filer_pb.UpdateEntryRequest{Directory: dir, Entry: entry}); err != nil {
+			return fmt.Errorf("update %s: %w", path, err)
+		}
+		maxBytes, maxInodes := filer.QuotaLimits(filer.FromPbEntry(dir, entry))
+		fmt.Fprintf(writer, "%s quota: %s\n", path, formatQuotaLimits(maxBytes, maxInodes))
+		return nil
+	})
+}
+
+func formatQuotaLimits(maxBytes, maxInodes int64) string {
+	size, count := "unlimited", "unlimited"
+	if maxBytes > 0 {
+		size = util.BytesToHumanReadable(uint64(maxBytes))
+	}
+	if maxInodes > 0 {
+		count = strconv.FormatInt(maxInodes, 10)
+	}
+	return fmt.Sprintf("size:%s inodes:%s", size, count)
+}
@@ -0,0 +1,328 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	util_http "github.com/seaweedfs/seaweedfs/weed/util/http"
+	"google.golang.org/protobuf/proto"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsRestore{})
+}
+
+type commandFsRestore struct {
+}
+
+func (c *commandFsRestore) Name() string {
+	return "fs.restore"
+}
+
+func (c *commandFsRestore) Help() string {
+	return `restore a directory tree as of a past time, into a new folder
+
+	# show what would be restored
+	fs.restore -time=2026-10-16T12:00:00Z /buckets/data
+
+	# restore into /buckets/data-restored-20261016-120000
+	fs.restore -time=2026-10-16T12:00:00Z -apply /buckets/data
+
+	# only restore the files deleted or changed since then, into a chosen folder
+	fs.restore -time=2026-10-16T12:00:00Z -changedOnly -to=/buckets/undo -apply /buckets/data
+
+	The tree is rebuilt from its current state, by undoing the metadata changes logged since the time.
+	The file content is copied into new chunks, so the restored files do not depend on the current files.
+	Chunks of deleted files can be copied until the volumes are vacuumed, or longer with a trash retention,
+	e.g. "fs.configure -locationPrefix=/buckets/data -trashRetention=72h -apply".
+	Files whose chunks are gone are skipped and reported.
+
+	The metadata log must cover the time range, and buckets deleted as a whole can not be restored.
+`
+}
+
+func (c *commandFsRestore) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsRestore) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	restoreCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	timeStr := restoreCommand.String("time", "", "restore the tree as of this time, in RFC3339 format, e.g. 2026-10-16T12:00:00Z")
+	target := restoreCommand.String("to", "", "the new folder to restore into, default to <folder>-restored-<time>")
+	changedOnly := restoreCommand.Bool("changedOnly", false, "only restore the entries deleted or changed since the time")
+	apply := restoreCommand.Bool("apply", false, "restore the entries, instead of only listing them")
+	verbose := restoreCommand.Bool("v", false, "print out each restored entry")
+	if err = restoreCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *timeStr == "" {
+		return fmt.Errorf("need -time")
+	}
+	restoreTime, err := time.Parse(time.RFC3339, *timeStr)
+	if err != nil {
+		return fmt.Errorf("parse -time %s: %w", *timeStr, err)
+	}
+	if restoreTime.After(time.Now()) {
+		return fmt.Errorf("-time %s is in the future", *timeStr)
+	}
+
+	path, err := commandEnv.parseUrl(findInputDirectory(restoreCommand.Args()))
+	if err != nil {
+		return err
+	}
+	dir := util.FullPath(path)
+	targetDir := util.FullPath(strings.TrimSuffix(string(dir), "/") + "-restored-" + restoreTime.UTC().Format("20060102-150405"))
+	if *target != "" {
+		if targetPath, err := commandEnv.parseUrl(*target); err != nil {
+			return err
+		} else {
+			targetDir = util.FullPath(targetPath)
+		}
+	}
+	if isUnderPath(targetDir, string(dir)) {
+		return fmt.Errorf("can not restore %s into %s", dir, targetDir)
+	}
+
+	ctx := context.Background()
+	current, err := readTreeEntries(ctx, commandEnv, dir)
+	if err != nil {
+		return err
+	}
+	stopTsNs := time.Now().UnixNano()
+
+	var events []*filer_pb.SubscribeMetadataResponse
+	if err = pb.FollowMetadata(commandEnv.option.FilerAddress, commandEnv.option.GrpcDialOption, &pb.MetadataFollowOption{
+		ClientName:     "shell_restore",
+		ClientId:       util.RandomInt32(),
+		PathPrefix:     string(dir),
+		StartTsNs:      restoreTime.UnixNano(),
+		StopTsNs:       stopTsNs,
+		EventErrorType: pb.DontLogError,
+	}, func(resp *filer_pb.SubscribeMetadataResponse) error {
+		events = append(events, resp)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("read metadata log of %s: %w", dir, err)
+	}
+
+	restored := make(map[util.FullPath]*filer_pb.Entry, len(current))
+	for p, entry := range current {
+		restored[p] = entry
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		undoMetadataEvent(restored, dir, events[i])
+	}
+	if *changedOnly {
+		for p, entry := range restored {
+			if currentEntry, found := current[p]; found && proto.Equal(currentEntry, entry) {
+				delete(restored, p)
+			}
+		}
+	}
+	fmt.Fprintf(writer, "undo %d changes of %s since %s: %d entries to restore\n", len(events), dir, restoreTime.Format(time.RFC3339), len(restored))
+
+	paths := make([]util.FullPath, 0, len(restored))
+	for p := range restored {
+		paths = append(paths, p)
+	}
+	// parent folders before their entries
+	sort.Slice(paths, func(i, j int) bool {
+		return paths[i] < paths[j]
+	})
+
+	if !*apply {
+		if *verbose {
+			for _, p := range paths {
+				fmt.Fprintf(writer, "%s => %s\n", p, restoredPath(p, dir, targetDir))
+			}
+		}
+		fmt.Fprintf(writer, "use -apply to restore into %s\n", targetDir)
+		return nil
+	}
+
+	if err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		targetParent, targetName := targetDir.DirAndName()
+		if _, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: targetParent, Name: targetName}); err == nil {
+			return fmt.Errorf("%s already exists", targetDir)
+		} else if err != filer_pb.ErrNotFound {
+			return fmt.Errorf("find %s: %w", targetDir, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var restoredCount, missingCount int
+	for _, p := range paths {
+		newPath := restoredPath(p, dir, targetDir)
+		if err := restoreEntry(ctx, commandEnv, restored[p], newPath); err != nil {
+			missingCount++
+			fmt.Fprintf(writer, "skip %s: %v\n", p, err)
+			continue
+		}
+		restoredCount++
+		if *verbose {
+			fmt.Fprintf(writer, "restored %s => %s\n", p, newPath)
+		}
+	}
+	fmt.Fprintf(writer, "restored %d entries into %s, skipped %d entries\n", restoredCount, targetDir, missingCount)
+	return nil
+}
+
+// readTreeEntries reads the current entries of the directory tree, including the directory itself
+func readTreeEntries(ctx context.Context, commandEnv *CommandEnv, dir util.FullPath) (entries map[util.FullPath]*filer_pb.Entry, err error) {
+	entries = make(map[util.FullPath]*filer_pb.Entry)
+	parent, name := dir.DirAndName()
+	err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := filer_pb.LookupEntry(ctx, client, &filer_pb.LookupDirectoryEntryRequest{Directory: parent, Name: name})
+		if err != nil {
+			return err
+		}
+		entries[dir] = resp.Entry
+		return nil
+	})
+	if err == filer_pb.ErrNotFound {
+		// deleted since the time, to be restored from the metadata log
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", dir, err)
+	}
+	if !entries[dir].IsDirectory {
+		return entries, nil
+	}
+	err = filer_pb.TraverseBfs(commandEnv, dir, func(parentPath util.FullPath, entry *filer_pb.Entry) {
+		entries[parentPath.Child(entry.Name)] = entry
+	})
+	if err != nil {
+		return nil, fmt.Errorf("traverse %s: %w", dir, err)
+	}
+	return entries, nil
+}
+
+// undoMetadataEvent reverts the change of one metadata event to the entries of the directory tree
+func undoMetadataEvent(entries map[util.FullPath]*filer_pb.Entry, dir util.FullPath, event *filer_pb.SubscribeMetadataResponse) {
+	message := event.EventNotification
+	if message.NewEntry != nil {
+		newParentPath := message.NewParentPath
+		if newParentPath == "" {
+			newParentPath = event.Directory
+		}
+		newPath := util.NewFullPath(newParentPath, message.NewEntry.Name)
+		if isUnderPath(newPath, string(dir)) {
+			delete(entries, newPath)
+		}
+	}
+	if message.OldEntry != nil {
+		oldPath := util.NewFullPath(event.Directory, message.OldEntry.Name)
+		if isUnderPath(oldPath, string(dir)) {
+			entries[oldPath] = message.OldEntry
+		}
+	}
+}
+
+func restoredPath(p, dir, targetDir util.FullPath) util.FullPath {
+	return targetDir + p[len(dir):]
+}
+
+// restoreEntry creates the entry at the new path, with a copy of its content
+func restoreEntry(ctx context.Context, commandEnv *CommandEnv, entry *filer_pb.Entry, newPath util.FullPath) error {
+	newEntry := proto.Clone(entry).(*filer_pb.Entry)
+	newDir, newName := newPath.DirAndName()
+	newEntry.Name = newName
+	// hard links are restored as regular files
+	newEntry.HardLinkId = nil
+	newEntry.HardLinkCounter = 0
+	if len(entry.GetChunks()) > 0 {
+		chunks, err := copyChunks(ctx, commandEnv, entry.GetChunks(), newPath)
+		if err != nil {
+			return err
+		}
+		newEntry.Chunks = chunks
+	}
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer_pb.CreateEntry(ctx, client, &filer_pb.CreateEntryRequest{
+			Directory: newDir,
+			Entry:     newEntry,
+			OExcl:     true,
+		})
+	})
+}
+
+// copyChunks copies the data chunks into new chunks, reading the chunks deleted but not vacuumed yet
+func copyChunks(ctx context.Context, commandEnv *CommandEnv, chunks []*filer_pb.FileChunk, newPath util.FullPath) (copied []*filer_pb.FileChunk, err error) {
+	dataChunks, _, err := filer.ResolveChunkManifest(ctx, commandEnv.MasterClient.GetLookupFileIdFunction(), chunks, 0, math.MaxInt64)
+	if err != nil {
+		return nil, fmt.Errorf("resolve chunk manifest: %w", err)
+	}
+	uploader, err := operation.NewUploader()
+	if err != nil {
+		return nil, err
+	}
+	for _, chunk := range dataChunks {
+		fileId, err := copyChunk(ctx, commandEnv, uploader, chunk.GetFileIdString(), newPath)
+		if err != nil {
+			return nil, fmt.Errorf("copy chunk %s: %w", chunk.GetFileIdString(), err)
+		}
+		copied = append(copied, &filer_pb.FileChunk{
+			FileId:       fileId,
+			Offset:       chunk.Offset,
+			Size:         chunk.Size,
+			ModifiedTsNs: chunk.ModifiedTsNs,
+			ETag:         chunk.ETag,
+			CipherKey:    chunk.CipherKey,
+			IsCompressed: chunk.IsCompressed,
+		})
+	}
+	return copied, nil
+}
+
+func copyChunk(ctx context.Context, commandEnv *CommandEnv, uploader *operation.Uploader, sourceFileId string, newPath util.FullPath) (fileId string, err error) {
+	fileUrls, err := commandEnv.MasterClient.GetLookupFileIdFunction()(ctx, sourceFileId)
+	if err != nil {
+		return "", err
+	}
+	for _, fileUrl := range fileUrls {
+		filename, header, resp, downloadErr := util_http.DownloadFile(fileUrl+"?readDeleted=true", "")
+		if downloadErr != nil {
+			err = downloadErr
+			continue
+		}
+		var uploadResult *operation.UploadResult
+		fileId, uploadResult, err, _ = uploader.UploadWithRetry(
+			commandEnv,
+			&filer_pb.AssignVolumeRequest{
+				Count: 1,
+				Path:  string(newPath),
+			},
+			&operation.UploadOption{
+				Filename:          filename,
+				IsInputCompressed: "gzip" == header.Get("Content-Encoding"),
+				MimeType:          header.Get("Content-Type"),
+			},
+			func(host, fileId string) string {
+				return fmt.Sprintf("http://%s/%s", host, fileId)
+			},
+			resp.Body,
+		)
+		util_http.CloseResponse(resp)
+		if err == nil && uploadResult.Error != "" {
+			err = fmt.Errorf("upload result: %v", uploadResult.Error)
+		}
+		if err == nil {
+			return fileId, nil
+		}
+	}
+	return "", err
+}
@@ -0,0 +1,55 @@
+package shell
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestUndoMetadataEvent(t *testing.T) {
+	entries := map[util.FullPath]*filer_pb.Entry{
+		"/data":       {Name: "data", IsDirectory: true},
+		"/data/b.txt": {Name: "b.txt", Attributes: &filer_pb.FuseAttributes{FileSize: 2}},
+		"/data/c.txt": {Name: "c.txt"},
+	}
+	events := []*filer_pb.SubscribeMetadataResponse{
+		// delete a.txt
+		{Directory: "/data", EventNotification: &filer_pb.EventNotification{OldEntry: &filer_pb.Entry{Name: "a.txt"}}},
+		// update b.txt
+		{Directory: "/data", EventNotification: &filer_pb.EventNotification{
+			OldEntry: &filer_pb.Entry{Name: "b.txt", Attributes: &filer_pb.FuseAttributes{FileSize: 1}},
+			NewEntry: &filer_pb.Entry{Name: "b.txt", Attributes: &filer_pb.FuseAttributes{FileSize: 2}},
+		}},
+		// move d.txt out of the folder
+		{Directory: "/data", EventNotification: &filer_pb.EventNotification{
+			OldEntry:      &filer_pb.Entry{Name: "d.txt"},
+			NewEntry:      &filer_pb.Entry{Name: "d.txt"},
+			NewParentPath: "/other",
+		}},
+		// create c.txt
+		{Directory: "/data", EventNotification: &filer_pb.EventNotification{NewEntry: &filer_pb.Entry{Name: "c.txt"}}},
+		// outside of the folder
+		{Directory: "/database", EventNotification: &filer_pb.EventNotification{OldEntry: &filer_pb.Entry{Name: "e.txt"}}},
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		undoMetadataEvent(entries, "/data", events[i])
+	}
+
+	for _, p := range []util.FullPath{"/data", "/data/a.txt", "/data/b.txt", "/data/d.txt"} {
+		if _, found := entries[p]; !found {
+			t.Errorf("%s should be restored", p)
+		}
+	}
+	for _, p := range []util.FullPath{"/data/c.txt", "/other/d.txt", "/database/e.txt"} {
+		if _, found := entries[p]; found {
+			t.Errorf("%s should not be restored", p)
+		}
+	}
+	if size := entries["/data/b.txt"].Attributes.FileSize; size != 1 {
+		t.Errorf("b.txt should be restored to size 1, got %d", size)
+	}
+	if restored := restoredPath("/data/a.txt", "/data", "/data-restored"); restored != "/data-restored/a.txt" {
+		t.Errorf("unexpected restored path %s", restored)
+	}
+}
@@ -0,0 +1,129 @@
+package shell
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSignUrl{})
+}
+
+type commandFsSignUrl struct {
+}
+
+func (c *commandFsSignUrl) Name() string {
+	return "fs.signUrl"
+}
+
+func (c *commandFsSignUrl) Help() string {
+	return `create an expiring signed url to upload or download files with the filer http api
+
+	The url carries a jwt signed with the filer signing key of security.toml, scoped
+	to a file, or to a folder if the path ends with "/", to the http methods, and to
+	the size and content types of uploads. The filer checks it with its own key,
+	without asking other servers. Urls only allowing GET or HEAD are signed with the read key.
+	Reads and writes need separate urls if the read key differs from the write key.
+
+	Web frontends can upload with PUT to the url, or with a browser form posting
+	multipart/form-data to it, which stores the file under its own name in a folder:
+		<form method="POST" enctype="multipart/form-data" action="<signed url>">
+
+	Backends can create the urls with security.GenScopedJwtForFilerServer().
+
+	# examples
+	# let a browser upload images up to 10MB into /uploads/user1/ within 15 minutes
+	fs.signUrl -methods POST,PUT -maxSize 10485760 -contentTypes 'image/*' -expires 15m /uploads/user1/
+
+	# download one file within an hour
+	fs.signUrl -methods GET -expires 1h /reports/2025-06.pdf
+`
+}
+
+func (c *commandFsSignUrl) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsSignUrl) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	signCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	methods := signCommand.String("methods", "PUT", "comma separated http methods allowed by the url")
+	maxSize := signCommand.Int64("maxSize", 0, "max upload size in bytes, 0 for no limit")
+	contentTypes := signCommand.String("contentTypes", "", "comma separated content types of uploads, e.g. image/png,image/*")
+	expires := signCommand.Duration("expires", 15*time.Minute, "how long the url is valid")
+	if err = signCommand.Parse(args); err != nil {
+		return nil
+	}
+	if signCommand.NArg() != 1 {
+		return fmt.Errorf("need exactly one file or folder path")
+	}
+	if *expires <= 0 {
+		return fmt.Errorf("invalid expiration %v", *expires)
+	}
+
+	input := signCommand.Arg(0)
+	p, err := commandEnv.parseUrl(input)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(input, "/") && !strings.HasSuffix(p, "/") {
+		p += "/"
+	}
+
+	scope := &security.FilerAccessScope{
+		PathPrefix: p,
+		MaxSize:    *maxSize,
+	}
+	isRead, hasRead := true, false
+	for _, method := range strings.Split(*methods, ",") {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		switch method {
+		case http.MethodGet, http.MethodHead:
+			hasRead = true
+		case http.MethodPut, http.MethodPost, http.MethodDelete:
+			isRead = false
+		default:
+			return fmt.Errorf("unsupported method %q", method)
+		}
+		scope.Methods = append(scope.Methods, method)
+	}
+	if *contentTypes != "" {
+		for _, contentType := range strings.Split(*contentTypes, ",") {
+			scope.ContentTypes = append(scope.ContentTypes, strings.TrimSpace(contentType))
+		}
+	}
+
+	keyName := "jwt.filer_signing.key"
+	if isRead {
+		keyName = "jwt.filer_signing.read.key"
+	}
+	signingKey := util.GetViper().GetString(keyName)
+	if signingKey == "" {
+		return fmt.Errorf("%s is not set in security.toml, the filer allows these requests without signature", keyName)
+	}
+	// the filer checks reads with the read key, and writes with the write key
+	if readSigningKey := util.GetViper().GetString("jwt.filer_signing.read.key"); hasRead && !isRead && readSigningKey != "" && readSigningKey != signingKey {
+		return fmt.Errorf("GET and HEAD are checked with jwt.filer_signing.read.key, sign a separate url for them")
+	}
+
+	encodedJwt := security.GenScopedJwtForFilerServer(security.SigningKey(signingKey), int(expires.Seconds()), scope)
+	if encodedJwt == "" {
+		return fmt.Errorf("failed to sign the url")
+	}
+	signedUrl := url.URL{
+		Scheme:   "http",
+		Host:     commandEnv.option.FilerAddress.ToHttpAddress(),
+		Path:     p,
+		RawQuery: url.Values{"jwt": {string(encodedJwt)}}.Encode(),
+	}
+	fmt.Fprintf(writer, "%s\n", signedUrl.String())
+	fmt.Fprintf(writer, "methods: %s, expires at %s\n", strings.Join(scope.Methods, ","), time.Now().Add(*expires).UTC().Format(time.RFC3339))
+	return nil
+}
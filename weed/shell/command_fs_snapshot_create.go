@@ -0,0 +1,75 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSnapshotCreate{})
+}
+
+type commandFsSnapshotCreate struct {
+}
+
+func (c *commandFsSnapshotCreate) Name() string {
+	return "fs.snapshot.create"
+}
+
+func (c *commandFsSnapshotCreate) Help() string {
+	return `take a read-only snapshot of a directory tree
+
+	# snapshot a folder, named after the current time
+	fs.snapshot.create /buckets/data
+
+	# snapshot a folder with a name
+	fs.snapshot.create -name before-migration /buckets/data
+
+	The snapshot copies the meta data, and shares the file chunks with the folder.
+	The chunks are kept while any snapshot references them.
+	The chunk references are counted in the filer store, so all filers need to share the same filer store.
+
+	Snapshots are browsable under the virtual ".snapshots" folder, on mount, S3 and the filer http api:
+	/buckets/data/.snapshots/before-migration
+`
+}
+
+func (c *commandFsSnapshotCreate) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsSnapshotCreate) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	createCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	name := createCommand.String("name", "", "the snapshot name, default to the current time")
+	if err = createCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	path, err := commandEnv.parseUrl(findInputDirectory(createCommand.Args()))
+	if err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = time.Now().UTC().Format("20060102-150405")
+	}
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.CreateSnapshot(context.Background(), &filer_pb.CreateSnapshotRequest{
+			Directory: path,
+			Name:      *name,
+		})
+		if err != nil {
+			return fmt.Errorf("snapshot %s: %w", path, err)
+		}
+		snapshot := &filer.Snapshot{Directory: util.FullPath(path), Name: *name}
+		fmt.Fprintf(writer, "created snapshot %s with %d entries\n", snapshot.BrowsePath(), resp.EntryCount)
+		return nil
+	})
+}
@@ -0,0 +1,60 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSnapshotDelete{})
+}
+
+type commandFsSnapshotDelete struct {
+}
+
+func (c *commandFsSnapshotDelete) Name() string {
+	return "fs.snapshot.delete"
+}
+
+func (c *commandFsSnapshotDelete) Help() string {
+	return `delete a snapshot taken by "fs.snapshot.create"
+
+	fs.snapshot.delete -name before-migration /buckets/data
+
+	The file chunks only referenced by the deleted snapshot are deleted.
+`
+}
+
+func (c *commandFsSnapshotDelete) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsSnapshotDelete) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	deleteCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	name := deleteCommand.String("name", "", "the snapshot to delete")
+	if err = deleteCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *name == "" {
+		return fmt.Errorf("need -name")
+	}
+
+	path, err := commandEnv.parseUrl(findInputDirectory(deleteCommand.Args()))
+	if err != nil {
+		return err
+	}
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		if err := filer.DeleteSnapshot(context.Background(), client, util.FullPath(path), *name); err != nil {
+			return fmt.Errorf("delete snapshot %s of %s: %w", *name, path, err)
+		}
+		fmt.Fprintf(writer, "deleted snapshot %s of %s\n", *name, path)
+		return nil
+	})
+}
@@ -0,0 +1,69 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsSnapshotList{})
+}
+
+type commandFsSnapshotList struct {
+}
+
+func (c *commandFsSnapshotList) Name() string {
+	return "fs.snapshot.list"
+}
+
+func (c *commandFsSnapshotList) Help() string {
+	return `list the snapshots taken by "fs.snapshot.create"
+
+	# list the snapshots of all folders
+	fs.snapshot.list
+
+	# list the snapshots of a folder
+	fs.snapshot.list /buckets/data
+`
+}
+
+func (c *commandFsSnapshotList) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsSnapshotList) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	listCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	if err = listCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	var dir util.FullPath
+	if listCommand.NArg() > 0 {
+		path, err := commandEnv.parseUrl(listCommand.Arg(0))
+		if err != nil {
+			return err
+		}
+		dir = util.FullPath(path)
+	}
+
+	count := 0
+	err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer.ListSnapshots(context.Background(), client, dir, func(snapshot *filer.Snapshot) error {
+			count++
+			fmt.Fprintf(writer, "%s created:%s %s\n", snapshot.Name, snapshot.CreatedAt.UTC().Format(time.RFC3339), snapshot.BrowsePath())
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("list snapshots: %w", err)
+	}
+	fmt.Fprintf(writer, "%d snapshots\n", count)
+	return nil
+}
@@ -0,0 +1,87 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsTrashList{})
+}
+
+type commandFsTrashList struct {
+}
+
+func (c *commandFsTrashList) Name() string {
+	return "fs.trash.list"
+}
+
+func (c *commandFsTrashList) Help() string {
+	return `list the deleted entries kept in the trash
+
+	Entries deleted under a location configured with a trash retention, e.g.
+	"fs.configure -locationPrefix=/my/folder -trashRetention=72h -apply",
+	are moved into the trash. Their data is deleted when the retention expires.
+
+	# list all trash items, in the order they were deleted
+	fs.trash.list
+
+	# list the trash items deleted under a folder
+	fs.trash.list -path /my/folder
+`
+}
+
+func (c *commandFsTrashList) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsTrashList) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	listCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	path := listCommand.String("path", "", "only list the items deleted under this path")
+	if err = listCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	pathPrefix := ""
+	if *path != "" {
+		if pathPrefix, err = commandEnv.parseUrl(*path); err != nil {
+			return err
+		}
+	}
+
+	count := 0
+	err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer.ListTrash(context.Background(), client, func(item *filer.TrashItem) error {
+			if !isUnderPath(item.Path, pathPrefix) {
+				return nil
+			}
+			count++
+			size := "dir"
+			if !item.IsDirectory {
+				size = util.BytesToHumanReadable(item.FileSize)
+			}
+			fmt.Fprintf(writer, "%s %s deleted:%s expires:%s %s\n", item.Id, size,
+				item.DeletedAt.UTC().Format(time.RFC3339), item.ExpiresAt.UTC().Format(time.RFC3339), item.Path)
+			return nil
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("list %s: %w", filer.TrashDir, err)
+	}
+	fmt.Fprintf(writer, "%d trash items\n", count)
+	return nil
+}
+
+// isUnderPath is true if p is the path prefix, or under it. An empty prefix matches all paths.
+func isUnderPath(p util.FullPath, pathPrefix string) bool {
+	pathPrefix = strings.TrimSuffix(pathPrefix, "/")
+	return pathPrefix == "" || string(p) == pathPrefix || strings.HasPrefix(string(p), pathPrefix+"/")
+}
@@ -0,0 +1,96 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsTrashPurge{})
+}
+
+type commandFsTrashPurge struct {
+}
+
+func (c *commandFsTrashPurge) Name() string {
+	return "fs.trash.purge"
+}
+
+func (c *commandFsTrashPurge) Help() string {
+	return `delete trash items and their data before their retention expires
+
+	# delete one trash item, as listed by "fs.trash.list"
+	fs.trash.purge -id 1760680000000000000-file.txt
+
+	# delete the trash items deleted under a folder more than one day ago
+	fs.trash.purge -path /my/folder -olderThan 24h
+
+	# empty the trash
+	fs.trash.purge -all
+
+	Purged items can not be restored.
+`
+}
+
+func (c *commandFsTrashPurge) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsTrashPurge) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	purgeCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	id := purgeCommand.String("id", "", "the trash item to delete")
+	path := purgeCommand.String("path", "", "delete the items deleted at or under this path")
+	olderThan := purgeCommand.Duration("olderThan", 0, "only delete the items deleted longer ago than this")
+	all := purgeCommand.Bool("all", false, "delete all trash items")
+	if err = purgeCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *id == "" && *path == "" && !*all {
+		return fmt.Errorf("need -id, -path or -all")
+	}
+
+	pathPrefix := ""
+	if *path != "" {
+		if pathPrefix, err = commandEnv.parseUrl(*path); err != nil {
+			return err
+		}
+	}
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		ctx := context.Background()
+		if *id != "" {
+			if err := filer.PurgeTrash(ctx, client, *id); err != nil {
+				return err
+			}
+			fmt.Fprintf(writer, "purged %s\n", *id)
+			return nil
+		}
+
+		var ids []string
+		deletedBefore := time.Now().Add(-*olderThan)
+		if err := filer.ListTrash(ctx, client, func(item *filer.TrashItem) error {
+			if isUnderPath(item.Path, pathPrefix) && !item.DeletedAt.After(deletedBefore) {
+				ids = append(ids, item.Id)
+			}
+			return nil
+		}); err != nil {
+			return fmt.Errorf("list %s: %w", filer.TrashDir, err)
+		}
+		purged := 0
+		for _, itemId := range ids {
+			if err := filer.PurgeTrash(ctx, client, itemId); err != nil {
+				fmt.Fprintf(writer, "purge %s: %v\n", itemId, err)
+				continue
+			}
+			purged++
+		}
+		fmt.Fprintf(writer, "purged %d trash items\n", purged)
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFsTrashRestore{})
+}
+
+type commandFsTrashRestore struct {
+}
+
+func (c *commandFsTrashRestore) Name() string {
+	return "fs.trash.restore"
+}
+
+func (c *commandFsTrashRestore) Help() string {
+	return `restore deleted entries from the trash
+
+	# restore a trash item, as listed by "fs.trash.list", to where it was deleted
+	fs.trash.restore -id 1760680000000000000-file.txt
+
+	# restore a trash item to another path
+	fs.trash.restore -id 1760680000000000000-file.txt -to /my/folder/file.restored.txt
+
+	# restore the last deleted version of each entry deleted under a folder
+	fs.trash.restore -path /my/folder
+
+	An entry is not restored over an existing entry.
+`
+}
+
+func (c *commandFsTrashRestore) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandFsTrashRestore) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	restoreCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	id := restoreCommand.String("id", "", "the trash item to restore")
+	to := restoreCommand.String("to", "", "restore the trash item to this path instead of where it was deleted")
+	path := restoreCommand.String("path", "", "restore the items deleted at or under this path")
+	if err = restoreCommand.Parse(args); err != nil {
+		return nil
+	}
+	if (*id == "") == (*path == "") {
+		return fmt.Errorf("need either -id or -path")
+	}
+	if *to != "" && *id == "" {
+		return fmt.Errorf("-to requires -id")
+	}
+
+	var target, pathPrefix string
+	if *to != "" {
+		if target, err = commandEnv.parseUrl(*to); err != nil {
+			return err
+		}
+	}
+	if *path != "" {
+		if pathPrefix, err = commandEnv.parseUrl(*path); err != nil {
+			return err
+		}
+	}
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		ctx := context.Background()
+		if *id != "" {
+			restored, err := filer.RestoreTrash(ctx, client, *id, util.FullPath(target))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(writer, "restored %s\n", restored)
+			return nil
+		}
+
+		// the items are listed in the order they were deleted, so the last one of each path wins
+		latest := make(map[util.FullPath]string)
+		var paths []util.FullPath
+		if err := filer.ListTrash(ctx, client, func(item *filer.TrashItem) error {
+			if !isUnderPath(item.Path, pathPrefix) {
+				return nil
+			}
+			if _, found := latest[item.Path]; !found {
+				paths = append(paths, item.Path)
+			}
+			latest[item.Path] = item.Id
+			return nil
+		}); err != nil {
+			return fmt.Errorf("list %s: %w", filer.TrashDir, err)
+		}
+		for _, p := range paths {
+			if _, err := filer.RestoreTrash(ctx, client, latest[p], ""); err != nil {
+				fmt.Fprintf(writer, "restore %s: %v\n", p, err)
+				continue
+			}
+			fmt.Fprintf(writer, "restored %s\n", p)
+		}
+		return nil
+	})
+}
@@ -25,6 +25,9 @@ func (c *commandS3BucketQuota) Help() string {
 
 	Example:
 		s3.bucket.quota -name=<bucket_name> -op=set -sizeMB=1024
+
+	The quota is enforced by "s3.bucket.quota.enforce", which makes the buckets over the limit read only.
+	Filers started with -quota.enforceBuckets also reject the writes over the limit, see "fs.quota.set".
 `
 }
 
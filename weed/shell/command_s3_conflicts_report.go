@@ -0,0 +1,102 @@
+package shell
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+func init() {
+	Commands = append(Commands, &commandS3ConflictsReport{})
+}
+
+type commandS3ConflictsReport struct {
+}
+
+func (c *commandS3ConflictsReport) Name() string {
+	return "s3.conflicts.report"
+}
+
+func (c *commandS3ConflictsReport) Help() string {
+	return `list the conflicts resolved by multi-site replication of s3 buckets
+
+	"weed filer.sync -multiSite" resolves objects changed concurrently on both sites:
+	the change with the latest site clock wins, and the other change is kept as an
+	older version in versioned buckets, or as a conflict copy in other buckets.
+	Each resolved conflict is recorded on the site that resolved it.
+
+	# examples
+	# list the conflicts of the last day
+	s3.conflicts.report -since 24h
+
+	# list the conflicts of bucket x
+	s3.conflicts.report -bucket x
+	`
+}
+
+func (c *commandS3ConflictsReport) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandS3ConflictsReport) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	reportCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	bucket := reportCommand.String("bucket", "", "only list conflicts of this bucket")
+	since := reportCommand.Duration("since", 0, "only list conflicts resolved within this duration, 0 for all")
+	if err = reportCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	bucketPrefix := ""
+	if *bucket != "" {
+		bucketsPath, err := readFilerBucketsPath(commandEnv)
+		if err != nil {
+			return fmt.Errorf("read buckets path: %w", err)
+		}
+		bucketPrefix = bucketsPath + "/" + *bucket + "/"
+	}
+	var sinceNs int64
+	if *since > 0 {
+		sinceNs = time.Now().Add(-*since).UnixNano()
+	}
+
+	var conflicts []*filer.SiteConflict
+	err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer_pb.SeaweedList(context.Background(), client, filer.SiteConflictsDir, "", func(entry *filer_pb.Entry, isLast bool) error {
+			conflict := &filer.SiteConflict{}
+			if err := json.Unmarshal(entry.Content, conflict); err != nil {
+				fmt.Fprintf(writer, "skip %s/%s: %v\n", filer.SiteConflictsDir, entry.Name, err)
+				return nil
+			}
+			if !strings.HasPrefix(conflict.Path, bucketPrefix) || conflict.DetectedNs < sinceNs {
+				return nil
+			}
+			conflicts = append(conflicts, conflict)
+			return nil
+		}, "", false, 0)
+	})
+	if err != nil && err != filer_pb.ErrNotFound {
+		return fmt.Errorf("list %s: %w", filer.SiteConflictsDir, err)
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].DetectedNs < conflicts[j].DetectedNs
+	})
+	for _, conflict := range conflicts {
+		fmt.Fprintf(writer, "%s %s\n", time.Unix(0, conflict.DetectedNs).UTC().Format(time.RFC3339), conflict.Path)
+		fmt.Fprintf(writer, "    winner: %s\n", conflict.Winner)
+		fmt.Fprintf(writer, "    loser:  %s, %s\n", conflict.Loser, conflict.Resolution)
+		if conflict.CopyPath != "" {
+			fmt.Fprintf(writer, "    copy:   %s\n", conflict.CopyPath)
+		}
+	}
+	fmt.Fprintf(writer, "%d conflicts\n", len(conflicts))
+	return nil
+}
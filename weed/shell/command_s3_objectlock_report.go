@@ -0,0 +1,185 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandS3ObjectLockReport{})
+}
+
+type commandS3ObjectLockReport struct {
+}
+
+func (c *commandS3ObjectLockReport) Name() string {
+	return "s3.objectLock.report"
+}
+
+func (c *commandS3ObjectLockReport) Help() string {
+	return `report the objects protected by s3 object lock retention or legal hold
+
+	The filer refuses to delete, move or overwrite these objects through any
+	protocol, until the retention date has passed and the legal hold is released.
+
+	# examples
+	# audit the objects in compliance mode of all buckets
+	s3.objectLock.report -mode COMPLIANCE
+
+	# list the objects under legal hold in bucket x
+	s3.objectLock.report -bucket x -legalHold
+
+	# also list objects whose retention has expired
+	s3.objectLock.report -bucket x -includeExpired
+	`
+}
+
+func (c *commandS3ObjectLockReport) HasTag(CommandTag) bool {
+	return false
+}
+
+type objectLockReportRecord struct {
+	bucket     string
+	key        string
+	versionId  string
+	size       uint64
+	protection filer.ObjectLockProtection
+}
+
+func (c *commandS3ObjectLockReport) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	reportCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	bucketName := reportCommand.String("bucket", "", "only report this bucket")
+	mode := reportCommand.String("mode", "", "only report retentions in this mode, GOVERNANCE or COMPLIANCE")
+	legalHold := reportCommand.Bool("legalHold", false, "only report objects under legal hold")
+	includeExpired := reportCommand.Bool("includeExpired", false, "also report objects whose retention has expired")
+	if err = reportCommand.Parse(args); err != nil {
+		return nil
+	}
+	*mode = strings.ToUpper(*mode)
+	if *mode != "" && *mode != s3_constants.RetentionModeGovernance && *mode != s3_constants.RetentionModeCompliance {
+		return fmt.Errorf("unknown retention mode %s", *mode)
+	}
+
+	filerBucketsPath, err := readFilerBucketsPath(commandEnv)
+	if err != nil {
+		return fmt.Errorf("read buckets: %w", err)
+	}
+
+	var buckets []string
+	if *bucketName != "" {
+		buckets = append(buckets, *bucketName)
+	} else {
+		err = filer_pb.List(context.Background(), commandEnv, filerBucketsPath, "", func(entry *filer_pb.Entry, isLast bool) error {
+			if entry.IsDirectory {
+				buckets = append(buckets, entry.Name)
+			}
+			return nil
+		}, "", false, math.MaxUint32)
+		if err != nil {
+			return fmt.Errorf("list buckets under %v: %w", filerBucketsPath, err)
+		}
+	}
+
+	now := time.Now()
+	var records []objectLockReportRecord
+	var recordsLock sync.Mutex
+	for _, bucket := range buckets {
+		bucketDir := util.FullPath(filerBucketsPath).Child(bucket)
+		err = filer_pb.TraverseBfs(commandEnv, bucketDir, func(parentPath util.FullPath, entry *filer_pb.Entry) {
+			if entry.IsDirectory {
+				return
+			}
+			p := filer.GetObjectLockProtection(entry.Extended, now)
+			if p.Mode == "" && !p.LegalHold {
+				return
+			}
+			if *mode != "" && p.Mode != *mode || *legalHold && !p.LegalHold {
+				return
+			}
+			if !*includeExpired && !p.IsProtected() {
+				return
+			}
+			key, versionId := objectLockReportKey(bucketDir, parentPath, entry)
+			recordsLock.Lock()
+			records = append(records, objectLockReportRecord{
+				bucket:     bucket,
+				key:        key,
+				versionId:  versionId,
+				size:       filer.FileSize(entry),
+				protection: p,
+			})
+			recordsLock.Unlock()
+		})
+		if err != nil {
+			return fmt.Errorf("traverse bucket %s: %w", bucket, err)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].bucket != records[j].bucket {
+			return records[i].bucket < records[j].bucket
+		}
+		if records[i].key != records[j].key {
+			return records[i].key < records[j].key
+		}
+		return records[i].versionId < records[j].versionId
+	})
+
+	var protectedCount, legalHoldCount int
+	var protectedSize uint64
+	modeCounts := make(map[string]int)
+	for _, record := range records {
+		p := record.protection
+		retainUntil, status := "-", "expired"
+		if p.Mode != "" {
+			retainUntil = p.RetainUntil.UTC().Format(time.RFC3339)
+			modeCounts[p.Mode]++
+		}
+		if p.IsProtected() {
+			status = "protected"
+			protectedCount++
+			protectedSize += record.size
+		}
+		legalHoldStatus := s3_constants.LegalHoldOff
+		if p.LegalHold {
+			legalHoldStatus = s3_constants.LegalHoldOn
+			legalHoldCount++
+		}
+		fmt.Fprintf(writer, "%s\t%s\tversion:%s\tmode:%s\tretainUntil:%s\tlegalHold:%s\tsize:%d\t%s\n",
+			record.bucket, record.key, util.Nvl(record.versionId, "null"), util.Nvl(p.Mode, "-"), retainUntil, legalHoldStatus, record.size, status)
+	}
+
+	fmt.Fprintf(writer, "total %d objects, %d protected with %d bytes, %d in %s mode, %d in %s mode, %d under legal hold\n",
+		len(records), protectedCount, protectedSize,
+		modeCounts[s3_constants.RetentionModeCompliance], s3_constants.RetentionModeCompliance,
+		modeCounts[s3_constants.RetentionModeGovernance], s3_constants.RetentionModeGovernance,
+		legalHoldCount)
+
+	return nil
+}
+
+// objectLockReportKey returns the object key and version id of an entry in a bucket
+func objectLockReportKey(bucketDir, parentPath util.FullPath, entry *filer_pb.Entry) (key, versionId string) {
+	dir := strings.TrimPrefix(string(parentPath), string(bucketDir))
+	if strings.HasSuffix(dir, s3_constants.VersionsFolder) {
+		key = strings.TrimPrefix(strings.TrimSuffix(dir, s3_constants.VersionsFolder), "/")
+	} else {
+		key = strings.TrimPrefix(dir+"/"+entry.Name, "/")
+	}
+	if entry.Extended != nil {
+		versionId = string(entry.Extended[s3_constants.ExtVersionIdKey])
+	}
+	return
+}
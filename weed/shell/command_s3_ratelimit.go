@@ -0,0 +1,180 @@
+package shell
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+)
+
+func init() {
+	Commands = append(Commands, &commandS3RateLimit{})
+}
+
+type commandS3RateLimit struct {
+}
+
+func (c *commandS3RateLimit) Name() string {
+	return "s3.rateLimit"
+}
+
+func (c *commandS3RateLimit) Help() string {
+	return `configure and apply s3 request rate limits per access key, bucket or source ip
+
+	Rate limits are token buckets refilled at the given rate. Requests over the limit
+	are rejected with a 503 SlowDown error. The key "*" applies the limit to each
+	access key, bucket or source ip without a limit of its own.
+	The source ip is the peer address of the request. Behind proxies, list them in the
+	-trustedProxies option of the s3 gateway to limit the forwarded client addresses.
+
+	# examples
+	# limit every access key to 100 requests and 50MB per second
+	s3.rateLimit -accessKeys '*' -requestsPerSecond 100 -mbPerSecond 50 -apply
+
+	# allow bursts of 500 requests for bucket x
+	s3.rateLimit -buckets x -requestsPerSecond 200 -burstRequests 500 -apply
+
+	# limit a source ip
+	s3.rateLimit -sourceIps 10.0.0.7 -requestsPerSecond 20 -apply
+
+	# temporarily disable or enable all rate limits
+	s3.rateLimit -disable -apply
+	s3.rateLimit -enable -apply
+
+	# delete the rate limit of bucket x
+	s3.rateLimit -buckets x -delete -apply
+
+	# clear all rate limits
+	s3.rateLimit -delete -apply
+	`
+}
+
+func (c *commandS3RateLimit) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandS3RateLimit) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	dir := s3_constants.CircuitBreakerConfigDir
+	file := s3_constants.CircuitBreakerConfigFile
+
+	s3RateLimitCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	accessKeys := s3RateLimitCommand.String("accessKeys", "", "the access key(s) to configure, or '*' for each access key, eg: -accessKeys key1,key2")
+	buckets := s3RateLimitCommand.String("buckets", "", "the bucket name(s) to configure, or '*' for each bucket, eg: -buckets x,y,z")
+	sourceIps := s3RateLimitCommand.String("sourceIps", "", "the source ip(s) to configure, or '*' for each source ip")
+
+	requestsPerSecond := s3RateLimitCommand.Float64("requestsPerSecond", 0, "requests per second, 0 for no limit")
+	mbPerSecond := s3RateLimitCommand.Float64("mbPerSecond", 0, "MB of request and response bodies per second, 0 for no limit")
+	burstRequests := s3RateLimitCommand.Int64("burstRequests", 0, "requests allowed in a burst, defaults to one second of requests")
+	burstMB := s3RateLimitCommand.Int64("burstMB", 0, "MB allowed in a burst, defaults to one second of MB")
+
+	enabled := s3RateLimitCommand.Bool("enable", false, "enable all rate limits")
+	disabled := s3RateLimitCommand.Bool("disable", false, "disable all rate limits")
+	deleted := s3RateLimitCommand.Bool("delete", false, "delete the rate limits of the given keys, or all rate limits")
+
+	apply := s3RateLimitCommand.Bool("apply", false, "update and apply current configuration")
+
+	if err = s3RateLimitCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *enabled && *disabled {
+		return fmt.Errorf("only one of -enable and -disable can be specified")
+	}
+
+	var buf bytes.Buffer
+	if err = LoadConfig(commandEnv, dir, file, &buf); err != nil {
+		return err
+	}
+
+	cbCfg := &s3_pb.S3CircuitBreakerConfig{}
+	if buf.Len() > 0 {
+		if err = filer.ParseS3ConfigurationFromBytes(buf.Bytes(), cbCfg); err != nil {
+			return err
+		}
+	}
+	if cbCfg.RateLimits == nil {
+		cbCfg.RateLimits = &s3_pb.S3RateLimitConfig{}
+	}
+	rateLimits := cbCfg.RateLimits
+
+	scopes := []struct {
+		keys   []string
+		limits *map[string]*s3_pb.S3RateLimit
+	}{
+		{splitKeys(*accessKeys), &rateLimits.AccessKeys},
+		{splitKeys(*buckets), &rateLimits.Buckets},
+		{splitKeys(*sourceIps), &rateLimits.SourceIps},
+	}
+	var hasKeys bool
+	for _, scope := range scopes {
+		hasKeys = hasKeys || len(scope.keys) > 0
+	}
+
+	if *deleted {
+		if !hasKeys {
+			cbCfg.RateLimits = nil
+		}
+		for _, scope := range scopes {
+			for _, key := range scope.keys {
+				delete(*scope.limits, key)
+			}
+		}
+	} else if hasKeys {
+		if *requestsPerSecond <= 0 && *mbPerSecond <= 0 {
+			return fmt.Errorf("one of -requestsPerSecond and -mbPerSecond must be positive")
+		}
+		for _, scope := range scopes {
+			for _, key := range scope.keys {
+				if *scope.limits == nil {
+					*scope.limits = make(map[string]*s3_pb.S3RateLimit)
+				}
+				(*scope.limits)[key] = &s3_pb.S3RateLimit{
+					RequestsPerSecond: *requestsPerSecond,
+					BytesPerSecond:    int64(*mbPerSecond * 1024 * 1024),
+					BurstRequests:     *burstRequests,
+					BurstBytes:        *burstMB * 1024 * 1024,
+				}
+			}
+		}
+		rateLimits.Enabled = !*disabled
+	}
+
+	if *enabled || *disabled {
+		rateLimits.Enabled = *enabled
+	}
+	if len(rateLimits.AccessKeys) == 0 && len(rateLimits.Buckets) == 0 && len(rateLimits.SourceIps) == 0 {
+		cbCfg.RateLimits = nil
+	}
+
+	buf.Reset()
+	if err = filer.ProtoToText(&buf, cbCfg); err != nil {
+		return err
+	}
+
+	fmt.Fprint(writer, buf.String())
+	fmt.Fprintln(writer)
+
+	if *apply {
+		if err := commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+			return filer.SaveInsideFiler(client, dir, file, buf.Bytes())
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func splitKeys(keys string) (result []string) {
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			result = append(result, key)
+		}
+	}
+	return
+}
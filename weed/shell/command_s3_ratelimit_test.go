@@ -0,0 +1,70 @@
+package shell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/s3_pb"
+)
+
+func TestRateLimitShell(t *testing.T) {
+	var saved bytes.Buffer
+	LoadConfig = func(commandEnv *CommandEnv, dir string, file string, buf *bytes.Buffer) error {
+		_, err := buf.Write(saved.Bytes())
+		return err
+	}
+	defer func() { LoadConfig = loadConfig }()
+
+	cmd := &commandS3RateLimit{}
+	run := func(args string) *s3_pb.S3CircuitBreakerConfig {
+		var output bytes.Buffer
+		if err := cmd.Do(strings.Split(args, " "), nil, &output); err != nil {
+			t.Fatalf("s3.rateLimit %s: %v", args, err)
+		}
+		saved.Reset()
+		saved.Write(output.Bytes())
+		cfg := &s3_pb.S3CircuitBreakerConfig{}
+		if err := filer.ParseS3ConfigurationFromBytes(output.Bytes(), cfg); err != nil {
+			t.Fatalf("s3.rateLimit %s: %v", args, err)
+		}
+		return cfg
+	}
+
+	saved.WriteString(`{"global": {"enabled": true, "actions": {"Read:Count": "500"}}}`)
+
+	cfg := run("-accessKeys * -requestsPerSecond 100 -mbPerSecond 2")
+	if !cfg.RateLimits.GetEnabled() || cfg.Global.Actions["Read:Count"] != 500 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if limit := cfg.RateLimits.AccessKeys["*"]; limit.RequestsPerSecond != 100 || limit.BytesPerSecond != 2*1024*1024 {
+		t.Errorf("unexpected access key limit: %+v", limit)
+	}
+
+	cfg = run("-buckets x,y -sourceIps 10.0.0.7 -requestsPerSecond 10 -burstRequests 50")
+	if len(cfg.RateLimits.Buckets) != 2 || cfg.RateLimits.Buckets["y"].BurstRequests != 50 || cfg.RateLimits.SourceIps["10.0.0.7"] == nil {
+		t.Errorf("unexpected rate limits: %+v", cfg.RateLimits)
+	}
+
+	if cfg = run("-disable"); cfg.RateLimits.GetEnabled() || len(cfg.RateLimits.AccessKeys) != 1 {
+		t.Errorf("expected rate limits to be kept but disabled: %+v", cfg.RateLimits)
+	}
+	if cfg = run("-enable"); !cfg.RateLimits.GetEnabled() {
+		t.Errorf("expected rate limits to be enabled: %+v", cfg.RateLimits)
+	}
+
+	cfg = run("-buckets x -delete")
+	if _, found := cfg.RateLimits.Buckets["x"]; found || len(cfg.RateLimits.Buckets) != 1 {
+		t.Errorf("expected the limit of bucket x to be deleted: %+v", cfg.RateLimits)
+	}
+
+	if cfg = run("-delete"); cfg.RateLimits != nil || cfg.Global == nil {
+		t.Errorf("expected only the rate limits to be cleared: %+v", cfg)
+	}
+
+	var output bytes.Buffer
+	if err := cmd.Do(strings.Split("-buckets x", " "), nil, &output); err == nil {
+		t.Errorf("expected a limit to be required")
+	}
+}
@@ -0,0 +1,183 @@
+package shell
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3usage"
+)
+
+func init() {
+	Commands = append(Commands, &commandS3Usage{})
+	Commands = append(Commands, &commandS3UsageExport{})
+}
+
+type commandS3Usage struct {
+}
+
+func (c *commandS3Usage) Name() string {
+	return "s3.usage"
+}
+
+func (c *commandS3Usage) Help() string {
+	return `show the usage of s3 buckets and identities on a day
+
+	The s3 gateways count the stored bytes and objects per storage class from the
+	filer metadata events, and the requests, ingress and egress bytes per request
+	type as they serve them. Stored data is attributed to the owner of each object.
+	Today's usage is the usage so far, with the stored data as of now.
+
+	# examples
+	# show the usage of all buckets and identities today
+	s3.usage
+
+	# show the usage of bucket x on a past day
+	s3.usage -date 2025-06-01 -bucket x
+
+	# output the usage of identity alice as csv
+	s3.usage -identity alice -format csv
+	`
+}
+
+func (c *commandS3Usage) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandS3Usage) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	usageCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	date := usageCommand.String("date", s3usage.Date(time.Now()), "the day in UTC, as yyyy-mm-dd")
+	bucket := usageCommand.String("bucket", "", "only show this bucket")
+	identity := usageCommand.String("identity", "", "only show this identity")
+	format := usageCommand.String("format", "table", "table, csv or json")
+	if err = usageCommand.Parse(args); err != nil {
+		return nil
+	}
+	if _, err = time.Parse(s3usage.DateLayout, *date); err != nil {
+		return fmt.Errorf("invalid date %s: %w", *date, err)
+	}
+
+	var report *s3usage.Report
+	err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) (err error) {
+		report, err = s3usage.LoadReport(client, *date)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("load usage of %s: %w", *date, err)
+	}
+	report.Filter(*bucket, *identity)
+
+	if *format != "table" {
+		return s3usage.Write(writer, report, *format)
+	}
+	printUsages(writer, "bucket", report.Buckets)
+	printUsages(writer, "identity", report.Identities)
+	return nil
+}
+
+func printUsages(writer io.Writer, scope string, usages map[string]*s3usage.Usage) {
+	for _, name := range s3usage.SortedNames(usages) {
+		u := usages[name]
+		var storage, requests []string
+		var totalRequests int64
+		for class, s := range u.Storage {
+			storage = append(storage, fmt.Sprintf("%s:%d bytes/%d objects", class, s.Bytes, s.Objects))
+		}
+		for requestType, count := range u.Requests {
+			requests = append(requests, fmt.Sprintf("%s:%d", requestType, count))
+			totalRequests += count
+		}
+		sort.Strings(storage)
+		sort.Strings(requests)
+		fmt.Fprintf(writer, "%s %s\n", scope, name)
+		fmt.Fprintf(writer, "\tstorage: %s\n", strings.Join(storage, " "))
+		fmt.Fprintf(writer, "\trequests: %d %s\n", totalRequests, strings.Join(requests, " "))
+		fmt.Fprintf(writer, "\tingress: %d bytes\tegress: %d bytes\n", u.IngressBytes, u.EgressBytes)
+	}
+}
+
+type commandS3UsageExport struct {
+}
+
+func (c *commandS3UsageExport) Name() string {
+	return "s3.usage.export"
+}
+
+func (c *commandS3UsageExport) Help() string {
+	return `configure the daily export of the s3 usage into a bucket
+
+	After each day in UTC, the usage of all buckets and identities is written
+	as <prefix><yyyy-mm-dd>.<format> into the export bucket, with one row per
+	value in csv: date,scope,name,metric,dimension,value
+
+	# examples
+	# show the current configuration
+	s3.usage.export
+
+	# export csv reports into bucket billing, under usage/
+	s3.usage.export -bucket billing -prefix usage/ -format csv -enable -apply
+
+	# stop exporting
+	s3.usage.export -disable -apply
+	`
+}
+
+func (c *commandS3UsageExport) HasTag(CommandTag) bool {
+	return false
+}
+
+func (c *commandS3UsageExport) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	exportCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	bucket := exportCommand.String("bucket", "", "the bucket to write the reports into")
+	prefix := exportCommand.String("prefix", "", "the key prefix of the reports")
+	format := exportCommand.String("format", "", "csv or json")
+	enabled := exportCommand.Bool("enable", false, "enable the export")
+	disabled := exportCommand.Bool("disable", false, "disable the export")
+	apply := exportCommand.Bool("apply", false, "update the configuration")
+	if err = exportCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *enabled && *disabled {
+		return fmt.Errorf("-enable and -disable can not be used together")
+	}
+
+	return commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		config, err := s3usage.LoadExportConfig(client)
+		if err != nil {
+			return fmt.Errorf("load export config: %w", err)
+		}
+		if config == nil {
+			config = &s3usage.ExportConfig{Prefix: "usage/", Format: s3usage.FormatCSV}
+		}
+		if *bucket != "" {
+			config.Bucket = *bucket
+		}
+		if *prefix != "" {
+			config.Prefix = *prefix
+		}
+		if *format != "" {
+			config.Format = strings.ToLower(*format)
+		}
+		if *enabled {
+			config.Enabled = true
+		}
+		if *disabled {
+			config.Enabled = false
+		}
+
+		fmt.Fprintf(writer, "enabled: %v\nbucket: %s\nprefix: %s\nformat: %s\n", config.Enabled, config.Bucket, config.Prefix, config.Format)
+		if !*apply {
+			return nil
+		}
+		if config.Enabled {
+			if err := config.Validate(); err != nil {
+				return err
+			}
+		}
+		return s3usage.SaveExportConfig(client, config)
+	})
+}
@@ -382,6 +382,14 @@ var (
 			Name:      "uploaded_objects",
 			Help:      "Number of objects uploaded in each bucket.",
 		}, []string{"bucket"})
+
+	S3ThrottledRequestsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "s3",
+			Name:      "throttled_requests_total",
+			Help:      "Counter of s3 requests rejected by rate limits, by the scope and type of the exceeded limit.",
+		}, []string{"scope", "limit", "bucket"})
 )
 
 func init() {
@@ -433,6 +441,7 @@ func init() {
 	Gather.MustRegister(S3BucketTrafficSentBytesCounter)
 	Gather.MustRegister(S3DeletedObjectsCounter)
 	Gather.MustRegister(S3UploadedObjectsCounter)
+	Gather.MustRegister(S3ThrottledRequestsCounter)
 
 	go bucketMetricTTLControl()
 }
@@ -518,6 +527,7 @@ func bucketMetricTTLControl() {
 				c += S3BucketTrafficSentBytesCounter.DeletePartialMatch(labels)
 				c += S3DeletedObjectsCounter.DeletePartialMatch(labels)
 				c += S3UploadedObjectsCounter.DeletePartialMatch(labels)
+				c += S3ThrottledRequestsCounter.DeletePartialMatch(labels)
 				glog.V(0).Infof("delete inactive bucket metrics, %s: %d", bucket, c)
 			}
 		}
@@ -41,6 +41,7 @@ const (
 	ChunkAssign        = "chunkAssign"
 	ChunkUpload        = "chunkUpload"
 	ChunkMerge         = "chunkMerge"
+	ChunkDedup         = "chunkDedup"
 
 	ChunkDoUploadRetry       = "chunkDoUploadRetry"
 	ChunkUploadRetry         = "chunkUploadRetry"
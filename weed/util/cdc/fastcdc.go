@@ -0,0 +1,119 @@
+// Package cdc splits a stream into content-defined chunks with FastCDC,
+// so that inserting or deleting some bytes only changes the chunks around them.
+package cdc
+
+import (
+	"io"
+	"math/bits"
+)
+
+// gear is generated from a fixed seed, and must not change:
+// the boundaries of the stored chunks, and so their deduplication, depend on it.
+var gear [256]uint64
+
+func init() {
+	seed := uint64(0x5eaeed)
+	for i := range gear {
+		// splitmix64
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		gear[i] = z ^ (z >> 31)
+	}
+}
+
+type Chunker struct {
+	reader  io.Reader
+	buf     []byte
+	start   int
+	end     int
+	eof     bool
+	minSize int
+	avgSize int
+	maxSize int
+	maskS   uint64
+	maskL   uint64
+}
+
+// NewChunker cuts chunks of at most maxSize bytes, averaging a quarter of it.
+func NewChunker(reader io.Reader, maxSize int) *Chunker {
+	avgSize := max(maxSize/4, 64)
+	avgBits := bits.Len(uint(avgSize)) - 1
+	return &Chunker{
+		reader:  reader,
+		buf:     make([]byte, max(maxSize, avgSize)),
+		minSize: avgSize / 4,
+		avgSize: avgSize,
+		maxSize: max(maxSize, avgSize),
+		// normalized chunking: harder to cut before the average size, easier after it
+		maskS: highBits(avgBits + 2),
+		maskL: highBits(avgBits - 2),
+	}
+}
+
+func highBits(n int) uint64 {
+	return ((uint64(1) << n) - 1) << (64 - n)
+}
+
+// Next writes the next chunk to the writer, and reports whether it is the last one.
+// At the end of the stream, it writes nothing and returns 0.
+func (c *Chunker) Next(w io.Writer) (n int, isLast bool, err error) {
+	if err = c.fill(); err != nil {
+		return 0, false, err
+	}
+	data := c.buf[c.start:c.end]
+	if len(data) == 0 {
+		return 0, true, nil
+	}
+	n = c.cutPoint(data)
+	if _, err = w.Write(data[:n]); err != nil {
+		return 0, false, err
+	}
+	c.start += n
+	return n, c.eof && c.start == c.end, nil
+}
+
+// fill reads until a full chunk is buffered, or the end of the stream
+func (c *Chunker) fill() error {
+	if c.eof || c.end-c.start >= c.maxSize {
+		return nil
+	}
+	c.end = copy(c.buf, c.buf[c.start:c.end])
+	c.start = 0
+	for c.end < len(c.buf) {
+		n, err := c.reader.Read(c.buf[c.end:])
+		c.end += n
+		if err == io.EOF {
+			c.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Chunker) cutPoint(data []byte) int {
+	n := min(len(data), c.maxSize)
+	if n <= c.minSize {
+		return n
+	}
+	normalSize := min(c.avgSize, n)
+	var fp uint64
+	i := c.minSize
+	for ; i < normalSize; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		fp = (fp << 1) + gear[data[i]]
+		if fp&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
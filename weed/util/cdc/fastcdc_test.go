@@ -0,0 +1,76 @@
+package cdc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+)
+
+func splitChunks(t *testing.T, data []byte, maxSize int) (chunks [][]byte) {
+	chunker := NewChunker(bytes.NewReader(data), maxSize)
+	for {
+		var buf bytes.Buffer
+		n, isLast, err := chunker.Next(&buf)
+		if err != nil {
+			t.Fatalf("next chunk: %v", err)
+		}
+		if n == 0 {
+			return
+		}
+		chunks = append(chunks, buf.Bytes())
+		if isLast {
+			return
+		}
+	}
+}
+
+func TestChunkSizes(t *testing.T) {
+	data := make([]byte, 8*1024*1024)
+	rand.New(rand.NewSource(1)).Read(data)
+	maxSize := 256 * 1024
+
+	chunks := splitChunks(t, data, maxSize)
+	if !bytes.Equal(bytes.Join(chunks, nil), data) {
+		t.Fatalf("chunks do not add up to the data")
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > maxSize || (i < len(chunks)-1 && len(chunk) < maxSize/16) {
+			t.Errorf("chunk %d has %d bytes", i, len(chunk))
+		}
+	}
+	if averageSize := len(data) / len(chunks); averageSize < maxSize/8 || averageSize > maxSize/2 {
+		t.Errorf("average chunk size %d", averageSize)
+	}
+}
+
+func TestChunkBoundariesAfterInsert(t *testing.T) {
+	data := make([]byte, 8*1024*1024)
+	rand.New(rand.NewSource(2)).Read(data)
+	edited := append(append(append([]byte{}, data[:1024*1024]...), []byte("some inserted bytes")...), data[1024*1024:]...)
+
+	fingerprints := make(map[[32]byte]bool)
+	for _, chunk := range splitChunks(t, data, 256*1024) {
+		fingerprints[sha256.Sum256(chunk)] = true
+	}
+	editedChunks := splitChunks(t, edited, 256*1024)
+	var sharedCount int
+	for _, chunk := range editedChunks {
+		if fingerprints[sha256.Sum256(chunk)] {
+			sharedCount++
+		}
+	}
+	if sharedCount < len(editedChunks)-2 {
+		t.Errorf("only %d of %d chunks are unchanged", sharedCount, len(editedChunks))
+	}
+}
+
+func TestSmallInput(t *testing.T) {
+	chunks := splitChunks(t, []byte("hello"), 256*1024)
+	if len(chunks) != 1 || string(chunks[0]) != "hello" {
+		t.Errorf("unexpected chunks %q", chunks)
+	}
+	if chunks := splitChunks(t, nil, 256*1024); len(chunks) != 0 {
+		t.Errorf("unexpected chunks of empty input %q", chunks)
+	}
+}